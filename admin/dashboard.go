@@ -0,0 +1,135 @@
+package admin
+
+import (
+	"fmt"
+	"html/template"
+	"net/http"
+
+	"com.moguyn/mcp-go-search/search"
+	"com.moguyn/mcp-go-search/stats"
+)
+
+// Dashboard is the data rendered on the status page
+type Dashboard struct {
+	Search    stats.Snapshot
+	Cache     *search.CacheStats
+	RateLimit *search.RateLimitStats
+	Providers map[string]bool
+}
+
+// chartBar is one bar of the latency chart
+type chartBar struct {
+	X      int
+	Y      int
+	Height int
+	Failed bool
+	Title  string
+}
+
+// Latency chart geometry, in SVG units
+const (
+	chartHeight   = 120
+	chartBarWidth = 10
+)
+
+// dashboardTemplate renders the read-only status page
+var dashboardTemplate = template.Must(template.New("dashboard").Funcs(template.FuncMap{
+	"hitRate":      hitRate,
+	"latencyChart": latencyChart,
+	"chartWidth":   func(n int) int { return n * chartBarWidth },
+	"chartHeight":  func() int { return chartHeight },
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta http-equiv="refresh" content="10">
+<title>Search Server Status</title>
+<style>
+body { font-family: sans-serif; margin: 2em; color: #222; }
+table { border-collapse: collapse; margin-bottom: 2em; }
+th, td { border: 1px solid #ccc; padding: 4px 10px; text-align: left; }
+.ok { color: #2a7d2a; } .bad { color: #b22222; }
+rect.ok { fill: #4a90d9; } rect.bad { fill: #b22222; }
+</style>
+</head>
+<body>
+<h1>Search Server Status</h1>
+<p>Up {{.Search.Uptime}} &middot; {{.Search.Queries}} upstream queries &middot; {{.Search.Errors}} errors</p>
+
+<h2>Providers</h2>
+<table>
+<tr><th>Provider</th><th>State</th><th>Queries</th><th>Errors</th><th>Avg latency</th><th>Score</th><th>Last error</th></tr>
+{{range $name, $enabled := .Providers}}<tr><td>{{$name}}</td><td class="{{if $enabled}}ok{{else}}bad{{end}}">{{if $enabled}}enabled{{else}}disabled{{end}}</td>
+{{range $.Search.Providers}}{{if eq .Name $name}}<td>{{.Queries}}</td><td>{{.Errors}}</td><td>{{printf "%.0f" .AverageLatencyMs}} ms</td><td>{{printf "%.2f" .Score}}</td><td>{{if .LastError}}{{.LastError.Format "2006-01-02 15:04:05"}}{{else}}-{{end}}</td>{{end}}{{end}}</tr>
+{{end}}</table>
+
+<h2>Cache</h2>
+{{if .Cache}}<p>{{.Cache.Entries}} entries &middot; {{.Cache.Hits}} hits &middot; {{.Cache.Misses}} misses &middot; hit rate {{hitRate .Cache}}</p>
+{{else}}<p>Caching is disabled.</p>{{end}}
+
+<h2>Quota</h2>
+{{if .RateLimit}}<p>Rate limit {{printf "%.0f" .RateLimit.Limit}}/s, burst {{.RateLimit.Burst}} &middot; {{printf "%.1f" .RateLimit.Available}} requests available now</p>
+{{else}}<p>The active provider does not report quota usage.</p>{{end}}
+
+<h2>Recent latency</h2>
+{{with latencyChart .Search.Recent}}<svg width="{{chartWidth (len .)}}" height="{{chartHeight}}" role="img" aria-label="Latency of recent queries">
+{{range .}}<rect class="{{if .Failed}}bad{{else}}ok{{end}}" x="{{.X}}" y="{{.Y}}" width="8" height="{{.Height}}"><title>{{.Title}}</title></rect>
+{{end}}</svg>{{else}}<p>No queries yet.</p>{{end}}
+
+<h2>Recent queries</h2>
+<table>
+<tr><th>Time</th><th>Provider</th><th>Query</th><th>Latency</th><th>Result</th></tr>
+{{range .Search.Recent}}<tr><td>{{.Time.Format "15:04:05"}}</td><td>{{.Provider}}</td><td>{{.Query}}</td><td>{{.LatencyMs}} ms</td><td class="{{if .Failed}}bad{{else}}ok{{end}}">{{if .Failed}}error{{else}}ok{{end}}</td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
+
+// handleDashboard renders the status page
+func (s *Server) handleDashboard(w http.ResponseWriter, _ *http.Request) {
+	if s.controls.Dashboard == nil {
+		writeError(w, http.StatusNotImplemented, "the dashboard is not available")
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Content-Security-Policy", "default-src 'none'; style-src 'unsafe-inline'")
+	if err := dashboardTemplate.Execute(w, s.controls.Dashboard()); err != nil {
+		http.Error(w, "failed to render dashboard", http.StatusInternalServerError)
+	}
+}
+
+// hitRate formats the cache hit rate as a percentage
+func hitRate(cache *search.CacheStats) string {
+	total := cache.Hits + cache.Misses
+	if total == 0 {
+		return "n/a"
+	}
+	return fmt.Sprintf("%.1f%%", float64(cache.Hits)*100/float64(total))
+}
+
+// latencyChart scales recent query latencies into chart bars
+func latencyChart(recent []stats.QueryRecord) []chartBar {
+	var maxLatency int64 = 1
+	for _, record := range recent {
+		if record.LatencyMs > maxLatency {
+			maxLatency = record.LatencyMs
+		}
+	}
+
+	bars := make([]chartBar, 0, len(recent))
+	for i, record := range recent {
+		height := int(record.LatencyMs * chartHeight / maxLatency)
+		if height < 1 {
+			height = 1
+		}
+		bars = append(bars, chartBar{
+			X:      i * chartBarWidth,
+			Y:      chartHeight - height,
+			Height: height,
+			Failed: record.Failed,
+			Title:  fmt.Sprintf("%s: %d ms", record.Time.Format("15:04:05"), record.LatencyMs),
+		})
+	}
+	return bars
+}
@@ -0,0 +1,83 @@
+package admin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"com.moguyn/mcp-go-search/search"
+	"com.moguyn/mcp-go-search/stats"
+)
+
+func TestDashboard(t *testing.T) {
+	collector := stats.NewCollector()
+	collector.Record(stats.Call{Provider: "bocha", Query: "secret project", Latency: 120 * time.Millisecond})
+
+	h := NewServer("", testToken, Controls{
+		Dashboard: func() Dashboard {
+			return Dashboard{
+				Search:    collector.Snapshot(),
+				Cache:     &search.CacheStats{Entries: 1, Hits: 3, Misses: 1},
+				RateLimit: &search.RateLimitStats{Limit: 10, Burst: 20, Available: 19},
+				Providers: map[string]bool{"bocha": true},
+			}
+		},
+	}).Handler()
+
+	// Browsers authenticate with basic auth
+	req := httptest.NewRequest("GET", "/admin/dashboard", nil)
+	req.SetBasicAuth("admin", testToken)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", rec.Code)
+	}
+	body := rec.Body.String()
+	for _, expected := range []string{"bocha", "hit rate 75.0%", "s***** p******", "<svg", "120 ms"} {
+		if !strings.Contains(body, expected) {
+			t.Errorf("Expected dashboard to contain %q", expected)
+		}
+	}
+	if strings.Contains(body, "secret project") {
+		t.Error("Expected query to be redacted on the dashboard")
+	}
+
+	// Unauthenticated requests are challenged
+	rec = doRequest(t, h, "GET", "/admin/dashboard", "", "")
+	if rec.Code != http.StatusUnauthorized || rec.Header().Get("WWW-Authenticate") == "" {
+		t.Errorf("Expected basic auth challenge, got %d", rec.Code)
+	}
+}
+
+func TestHitRate(t *testing.T) {
+	if rate := hitRate(&search.CacheStats{}); rate != "n/a" {
+		t.Errorf("Expected n/a for an unused cache, got %s", rate)
+	}
+	if rate := hitRate(&search.CacheStats{Hits: 1, Misses: 3}); rate != "25.0%" {
+		t.Errorf("Expected 25.0%%, got %s", rate)
+	}
+}
+
+func TestLatencyChart(t *testing.T) {
+	bars := latencyChart([]stats.QueryRecord{
+		{LatencyMs: 50},
+		{LatencyMs: 100, Failed: true},
+		{LatencyMs: 0},
+	})
+
+	if len(bars) != 3 {
+		t.Fatalf("Expected 3 bars, got %d", len(bars))
+	}
+	if bars[1].Height != chartHeight || bars[0].Height != chartHeight/2 {
+		t.Errorf("Expected bars scaled to the slowest query, got %d and %d", bars[0].Height, bars[1].Height)
+	}
+	if bars[2].Height != 1 {
+		t.Errorf("Expected minimum bar height of 1, got %d", bars[2].Height)
+	}
+	if !bars[1].Failed || bars[1].X != chartBarWidth {
+		t.Errorf("Unexpected second bar: %+v", bars[1])
+	}
+}
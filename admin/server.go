@@ -0,0 +1,220 @@
+// Package admin provides an authenticated HTTP API for runtime operations
+package admin
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// maxRequestBodySize bounds admin request bodies
+const maxRequestBodySize = 64 * 1024
+
+// Controls are the runtime operations exposed by the admin API.
+// A nil control makes the matching endpoint respond with 501 Not Implemented.
+type Controls struct {
+	// SetLogLevel changes the server log level
+	SetLogLevel func(level string) error
+	// RotateAPIKey replaces the upstream API key
+	RotateAPIKey func(apiKey string) error
+	// FlushCache empties the search cache and returns the number of entries removed
+	FlushCache func() int
+	// Stats returns the current statistics, encoded as JSON
+	Stats func() interface{}
+	// Providers returns the enabled state of every provider
+	Providers func() map[string]bool
+	// SetProviderEnabled enables or disables the named provider
+	SetProviderEnabled func(name string, enabled bool) error
+	// Dashboard returns the data shown on the status page
+	Dashboard func() Dashboard
+}
+
+// Server serves the admin API on its own listener, separate from the MCP transport
+type Server struct {
+	token    string
+	controls Controls
+	srv      *http.Server
+}
+
+// NewServer creates a new admin server listening on addr. Every request must
+// carry the admin token.
+func NewServer(addr, token string, controls Controls) *Server {
+	s := &Server{
+		token:    token,
+		controls: controls,
+	}
+	s.srv = &http.Server{
+		Addr:              addr,
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	return s
+}
+
+// Handler returns the admin API HTTP handler
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /admin/stats", s.handleStats)
+	mux.HandleFunc("PUT /admin/log-level", s.handleLogLevel)
+	mux.HandleFunc("PUT /admin/api-key", s.handleAPIKey)
+	mux.HandleFunc("POST /admin/cache/flush", s.handleFlushCache)
+	mux.HandleFunc("GET /admin/providers", s.handleProviders)
+	mux.HandleFunc("PUT /admin/providers/{name}", s.handleSetProvider)
+	mux.HandleFunc("GET /admin/dashboard", s.handleDashboard)
+	return s.authenticate(mux)
+}
+
+// Start begins serving the admin API; it blocks until the server stops
+func (s *Server) Start() error {
+	if err := s.srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
+
+// Shutdown gracefully stops the admin server
+func (s *Server) Shutdown(ctx context.Context) error {
+	return s.srv.Shutdown(ctx)
+}
+
+// authenticate rejects requests without the admin token. The token is accepted
+// as a bearer credential, or as the basic auth password so browsers can open the dashboard.
+func (s *Server) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if _, password, ok := r.BasicAuth(); ok {
+			token = password
+		}
+		if s.token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Basic realm="admin"`)
+			writeError(w, http.StatusUnauthorized, "unauthorized")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// handleStats returns the current statistics
+func (s *Server) handleStats(w http.ResponseWriter, _ *http.Request) {
+	if s.controls.Stats == nil {
+		writeError(w, http.StatusNotImplemented, "stats are not available")
+		return
+	}
+	writeJSON(w, http.StatusOK, s.controls.Stats())
+}
+
+// handleLogLevel changes the log level
+func (s *Server) handleLogLevel(w http.ResponseWriter, r *http.Request) {
+	if s.controls.SetLogLevel == nil {
+		writeError(w, http.StatusNotImplemented, "changing the log level is not supported")
+		return
+	}
+
+	var body struct {
+		Level string `json:"level"`
+	}
+	if err := decodeBody(r, &body); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := s.controls.SetLogLevel(body.Level); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"level": body.Level})
+}
+
+// handleAPIKey rotates the upstream API key
+func (s *Server) handleAPIKey(w http.ResponseWriter, r *http.Request) {
+	if s.controls.RotateAPIKey == nil {
+		writeError(w, http.StatusNotImplemented, "the active provider does not use an API key")
+		return
+	}
+
+	var body struct {
+		APIKey string `json:"api_key"`
+	}
+	if err := decodeBody(r, &body); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := s.controls.RotateAPIKey(body.APIKey); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "rotated"})
+}
+
+// handleFlushCache empties the search cache
+func (s *Server) handleFlushCache(w http.ResponseWriter, _ *http.Request) {
+	if s.controls.FlushCache == nil {
+		writeError(w, http.StatusNotImplemented, "caching is not enabled")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]int{"flushed": s.controls.FlushCache()})
+}
+
+// handleProviders lists the providers and whether they are enabled
+func (s *Server) handleProviders(w http.ResponseWriter, _ *http.Request) {
+	if s.controls.Providers == nil {
+		writeError(w, http.StatusNotImplemented, "providers are not available")
+		return
+	}
+	writeJSON(w, http.StatusOK, s.controls.Providers())
+}
+
+// handleSetProvider enables or disables a provider
+func (s *Server) handleSetProvider(w http.ResponseWriter, r *http.Request) {
+	if s.controls.SetProviderEnabled == nil {
+		writeError(w, http.StatusNotImplemented, "toggling providers is not supported")
+		return
+	}
+
+	var body struct {
+		Enabled *bool `json:"enabled"`
+	}
+	if err := decodeBody(r, &body); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if body.Enabled == nil {
+		writeError(w, http.StatusBadRequest, "enabled is required")
+		return
+	}
+
+	name := r.PathValue("name")
+	if err := s.controls.SetProviderEnabled(name, *body.Enabled); err != nil {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"name": name, "enabled": *body.Enabled})
+}
+
+// decodeBody decodes a size-limited JSON request body into v
+func decodeBody(r *http.Request, v interface{}) error {
+	data, err := io.ReadAll(io.LimitReader(r.Body, maxRequestBodySize))
+	if err != nil {
+		return fmt.Errorf("failed to read request body: %w", err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("invalid JSON body: %w", err)
+	}
+	return nil
+}
+
+// writeJSON writes v as a JSON response
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes a JSON error response
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, map[string]string{"error": msg})
+}
@@ -0,0 +1,121 @@
+package admin
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const testToken = "test-admin-token-0123456789"
+
+// doRequest sends a request to the admin handler and returns the recorder
+func doRequest(t *testing.T, h http.Handler, method, path, token, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestAuthentication(t *testing.T) {
+	h := NewServer("", testToken, Controls{Stats: func() interface{} { return "ok" }}).Handler()
+
+	if rec := doRequest(t, h, "GET", "/admin/stats", "", ""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 without token, got %d", rec.Code)
+	}
+	if rec := doRequest(t, h, "GET", "/admin/stats", "wrong-token", ""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 with wrong token, got %d", rec.Code)
+	}
+	if rec := doRequest(t, h, "GET", "/admin/stats", testToken, ""); rec.Code != http.StatusOK {
+		t.Errorf("Expected 200 with valid token, got %d", rec.Code)
+	}
+
+	// An empty configured token never authenticates
+	h = NewServer("", "", Controls{}).Handler()
+	if rec := doRequest(t, h, "GET", "/admin/stats", "", ""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 with no configured token, got %d", rec.Code)
+	}
+}
+
+func TestControls(t *testing.T) {
+	var level, apiKey string
+	flushed := false
+	providers := map[string]bool{"bocha": true}
+
+	h := NewServer("", testToken, Controls{
+		SetLogLevel: func(l string) error {
+			if l == "verbose" {
+				return errors.New("invalid level")
+			}
+			level = l
+			return nil
+		},
+		RotateAPIKey: func(k string) error {
+			apiKey = k
+			return nil
+		},
+		FlushCache: func() int {
+			flushed = true
+			return 3
+		},
+		Providers: func() map[string]bool { return providers },
+		SetProviderEnabled: func(name string, enabled bool) error {
+			if _, ok := providers[name]; !ok {
+				return errors.New("unknown provider")
+			}
+			providers[name] = enabled
+			return nil
+		},
+	}).Handler()
+
+	if rec := doRequest(t, h, "PUT", "/admin/log-level", testToken, `{"level":"debug"}`); rec.Code != http.StatusOK || level != "debug" {
+		t.Errorf("Expected log level change, got %d and %q", rec.Code, level)
+	}
+	if rec := doRequest(t, h, "PUT", "/admin/log-level", testToken, `{"level":"verbose"}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for invalid level, got %d", rec.Code)
+	}
+	if rec := doRequest(t, h, "PUT", "/admin/api-key", testToken, `{"api_key":"new-key"}`); rec.Code != http.StatusOK || apiKey != "new-key" {
+		t.Errorf("Expected API key rotation, got %d and %q", rec.Code, apiKey)
+	}
+	if rec := doRequest(t, h, "POST", "/admin/cache/flush", testToken, ""); rec.Code != http.StatusOK || !flushed || !strings.Contains(rec.Body.String(), `"flushed":3`) {
+		t.Errorf("Expected cache flush, got %d %s", rec.Code, rec.Body.String())
+	}
+	if rec := doRequest(t, h, "PUT", "/admin/providers/bocha", testToken, `{"enabled":false}`); rec.Code != http.StatusOK || providers["bocha"] {
+		t.Errorf("Expected provider to be disabled, got %d", rec.Code)
+	}
+	if rec := doRequest(t, h, "PUT", "/admin/providers/missing", testToken, `{"enabled":false}`); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for unknown provider, got %d", rec.Code)
+	}
+	if rec := doRequest(t, h, "PUT", "/admin/providers/bocha", testToken, `{}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 without enabled field, got %d", rec.Code)
+	}
+	if rec := doRequest(t, h, "GET", "/admin/providers", testToken, ""); rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"bocha":false`) {
+		t.Errorf("Expected provider listing, got %d %s", rec.Code, rec.Body.String())
+	}
+	if rec := doRequest(t, h, "PUT", "/admin/log-level", testToken, `not json`); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for invalid JSON, got %d", rec.Code)
+	}
+}
+
+func TestMissingControls(t *testing.T) {
+	h := NewServer("", testToken, Controls{}).Handler()
+
+	for _, tc := range []struct{ method, path string }{
+		{"GET", "/admin/stats"},
+		{"PUT", "/admin/log-level"},
+		{"PUT", "/admin/api-key"},
+		{"POST", "/admin/cache/flush"},
+		{"GET", "/admin/providers"},
+		{"PUT", "/admin/providers/bocha"},
+		{"GET", "/admin/dashboard"},
+	} {
+		if rec := doRequest(t, h, tc.method, tc.path, testToken, "{}"); rec.Code != http.StatusNotImplemented {
+			t.Errorf("%s %s: expected 501, got %d", tc.method, tc.path, rec.Code)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// Operations of the Bocha API, each served at its own path below BochaEndpoint
+const (
+	// BochaWebSearch is the web search used for every search
+	BochaWebSearch = "web-search"
+	// BochaWebSearchPro is the web search adding answers and rich snippets
+	BochaWebSearchPro = "web-search-pro"
+	// BochaAISearch is the AI search, answering with a written response
+	BochaAISearch = "ai-search"
+	// BochaAgentSearch is the agent search, handing the query to a vertical
+	// agent such as the scholar or company lookup
+	BochaAgentSearch = "agent-search"
+	// BochaImages is the image search
+	BochaImages = "images"
+	// BochaRerank is the semantic reranking of documents against a query
+	BochaRerank = "rerank"
+)
+
+// DefaultBochaEndpoint is the base URL the operations' paths are joined to
+const DefaultBochaEndpoint = "https://api.bochaai.com/v1"
+
+// DefaultBochaPaths are the paths of the operations below the endpoint
+var DefaultBochaPaths = map[string]string{
+	BochaWebSearch:    "/web-search",
+	BochaWebSearchPro: "/web-search-pro",
+	BochaAISearch:     "/ai-search",
+	BochaAgentSearch:  "/agent-search",
+	BochaImages:       "/image-search",
+	BochaRerank:       "/rerank",
+}
+
+// DefaultBochaAgents are the agents an agent search can invoke: academic
+// papers, company records and documents
+var DefaultBochaAgents = []string{"bocha-scholar-agent", "bocha-company-agent", "bocha-document-agent"}
+
+// BochaURL returns the URL of an operation: its path from BochaPaths, or the
+// default path, joined to BochaEndpoint. A path that is a full URL is used as
+// it is, for operations served by another host.
+func (c *Config) BochaURL(operation string) string {
+	return c.BochaRegionURL(c.BochaEndpoint, operation)
+}
+
+// BochaRegionURL returns the URL of an operation below endpoint, one of
+// BochaRegions, in the same way as BochaURL
+func (c *Config) BochaRegionURL(endpoint, operation string) string {
+	path, ok := c.BochaPaths[operation]
+	if !ok {
+		path = DefaultBochaPaths[operation]
+	}
+	if u, err := url.Parse(path); err == nil && u.IsAbs() {
+		return path
+	}
+	if endpoint == "" {
+		endpoint = DefaultBochaEndpoint
+	}
+	return strings.TrimSuffix(endpoint, "/") + "/" + strings.TrimPrefix(path, "/")
+}
+
+// validateBochaPaths checks that BochaPaths only names known operations
+func (c *Config) validateBochaPaths() error {
+	for operation := range c.BochaPaths {
+		if _, ok := DefaultBochaPaths[operation]; !ok {
+			operations := make([]string, 0, len(DefaultBochaPaths))
+			for known := range DefaultBochaPaths {
+				operations = append(operations, known)
+			}
+			sort.Strings(operations)
+			return fmt.Errorf("unknown operation %q in BOCHA_PATHS, must be one of: %s", operation, strings.Join(operations, ", "))
+		}
+	}
+	return nil
+}
+
+// validateBochaRegions checks that every regional endpoint is an upstream the
+// API key may be sent to
+func (c *Config) validateBochaRegions() error {
+	for _, endpoint := range c.BochaRegions {
+		for _, operation := range []string{BochaWebSearch, BochaWebSearchPro, BochaAISearch, BochaAgentSearch} {
+			if err := CheckUpstreamURL(c.BochaRegionURL(endpoint, operation), c.UpstreamAllowlist, c.AllowInsecureHTTP); err != nil {
+				return fmt.Errorf("invalid BOCHA_REGIONS entry %q: %w", endpoint, err)
+			}
+		}
+	}
+	return nil
+}
+
+// validateBochaAgents checks that every agent has a name
+func (c *Config) validateBochaAgents() error {
+	for _, agent := range c.BochaAgents {
+		if strings.TrimSpace(agent) == "" || strings.ContainsAny(agent, " \t,") {
+			return fmt.Errorf("invalid BOCHA_AGENTS entry %q", agent)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBochaURL(t *testing.T) {
+	cfg := &Config{
+		BochaEndpoint: "https://search-proxy.corp.example/bocha/v1/",
+		BochaPaths: map[string]string{
+			BochaWebSearchPro: "pro/web-search",
+			BochaRerank:       "https://rerank.corp.example/v1/rerank",
+		},
+	}
+	tests := map[string]string{
+		BochaWebSearch:    "https://search-proxy.corp.example/bocha/v1/web-search",
+		BochaWebSearchPro: "https://search-proxy.corp.example/bocha/v1/pro/web-search",
+		BochaImages:       "https://search-proxy.corp.example/bocha/v1/image-search",
+		BochaRerank:       "https://rerank.corp.example/v1/rerank",
+	}
+	for operation, expected := range tests {
+		if got := cfg.BochaURL(operation); got != expected {
+			t.Errorf("Expected %s for %s, got %s", expected, operation, got)
+		}
+	}
+
+	if got := (&Config{}).BochaURL(BochaAISearch); got != "https://api.bochaai.com/v1/ai-search" {
+		t.Errorf("Expected the default endpoint and path, got %s", got)
+	}
+}
+
+func TestBochaEndpointConfig(t *testing.T) {
+	t.Setenv("CONFIG_FILE", "")
+	t.Setenv("SEARCH_PROVIDER", "")
+	t.Setenv("BOCHA_API_KEY", "test-key")
+	t.Setenv("BOCHA_API_BASE_URL", "")
+	t.Setenv("BOCHA_PRO_URL", "")
+	t.Setenv("BOCHA_ENDPOINT", "https://eu.api.bochaai.com/v1")
+	t.Setenv("BOCHA_PATHS", "web-search-pro=/search/pro")
+	cfg := New()
+	if cfg.BochaAPIBaseURL != "https://eu.api.bochaai.com/v1/web-search" {
+		t.Errorf("Expected the web search below the endpoint, got %s", cfg.BochaAPIBaseURL)
+	}
+	if cfg.BochaProURL != "https://eu.api.bochaai.com/v1/search/pro" {
+		t.Errorf("Expected the configured pro path, got %s", cfg.BochaProURL)
+	}
+
+	// A full URL still wins over the endpoint
+	t.Setenv("BOCHA_API_BASE_URL", "https://api.bochaai.com/v1/web-search")
+	if cfg = New(); cfg.BochaAPIBaseURL != "https://api.bochaai.com/v1/web-search" {
+		t.Errorf("Expected BOCHA_API_BASE_URL to be used as it is, got %s", cfg.BochaAPIBaseURL)
+	}
+
+	t.Setenv("BOCHA_PATHS", "search=/search")
+	err := New().Validate()
+	if err == nil || !strings.Contains(err.Error(), `unknown operation "search" in BOCHA_PATHS`) {
+		t.Errorf("Expected error for an unknown operation, got %v", err)
+	}
+}
+
+func TestBochaEndpointConfig_File(t *testing.T) {
+	t.Setenv("SEARCH_PROVIDER", "")
+	t.Setenv("BOCHA_API_KEY", "test-key")
+	t.Setenv("BOCHA_API_BASE_URL", "")
+	t.Setenv("BOCHA_PRO_URL", "")
+	t.Setenv("BOCHA_ENDPOINT", "")
+	t.Setenv("BOCHA_PATHS", "")
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	data := "bocha_endpoint: \"https://search-proxy.corp.example/v1\"\nbocha_paths:\n  web-search: \"/search\"\n"
+	if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	t.Setenv("CONFIG_FILE", path)
+	cfg := New()
+	if cfg.BochaAPIBaseURL != "https://search-proxy.corp.example/v1/search" {
+		t.Errorf("Expected the file's endpoint and path, got %s", cfg.BochaAPIBaseURL)
+	}
+	if cfg.BochaProURL != "https://search-proxy.corp.example/v1/web-search-pro" {
+		t.Errorf("Expected the default pro path below the file's endpoint, got %s", cfg.BochaProURL)
+	}
+}
+
+func TestBochaRegionsConfig(t *testing.T) {
+	t.Setenv("CONFIG_FILE", "")
+	t.Setenv("SEARCH_PROVIDER", "")
+	t.Setenv("BOCHA_API_KEY", "test-key")
+	t.Setenv("BOCHA_API_BASE_URL", "")
+	t.Setenv("BOCHA_PRO_URL", "")
+	t.Setenv("BOCHA_ENDPOINT", "")
+	t.Setenv("BOCHA_PATHS", "")
+	t.Setenv("UPSTREAM_ALLOWLIST", "eu.search-proxy.example")
+	t.Setenv("BOCHA_REGIONS", "https://api.bochaai.com/v1, https://eu.search-proxy.example/v1")
+	cfg := New()
+	if len(cfg.BochaRegions) != 2 || cfg.BochaRegions[1] != "https://eu.search-proxy.example/v1" {
+		t.Fatalf("Expected both regions, got %v", cfg.BochaRegions)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected no error for allowed regions, got %v", err)
+	}
+
+	cfg.BochaRegions = append(cfg.BochaRegions, "https://ap.search-proxy.example/v1")
+	err := cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(), `invalid BOCHA_REGIONS entry "https://ap.search-proxy.example/v1"`) {
+		t.Errorf("Expected error for a region on an unknown host, got %v", err)
+	}
+}
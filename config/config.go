@@ -3,39 +3,715 @@ package config
 import (
 	"fmt"
 	"log"
+	"net/netip"
+	"net/url"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strconv"
 	"strings"
 	"time"
 
 	"gopkg.in/yaml.v3"
+
+	"com.moguyn/mcp-go-search/params"
+	"com.moguyn/mcp-go-search/pool"
+	"com.moguyn/mcp-go-search/privacy"
+	"com.moguyn/mcp-go-search/store"
+)
+
+// Supported values for SearchProvider
+const (
+	// ProviderBocha selects the built-in Bocha Web Search API backend
+	ProviderBocha = "bocha"
+	// ProviderPlugin selects an external plugin subprocess as the backend
+	ProviderPlugin = "plugin"
+	// ProviderBrave selects the Brave Search API backend
+	ProviderBrave = "brave"
+	// ProviderGoogle selects the Google Programmable Search (Custom Search JSON API) backend
+	ProviderGoogle = "google"
+	// ProviderBaidu selects Baidu web search through the Qianfan AI Search API
+	ProviderBaidu = "baidu"
+	// ProviderJina selects the Jina Search API, whose results carry page content as markdown
+	ProviderJina = "jina"
+	// ProviderArxiv selects the arXiv API, which searches research papers
+	ProviderArxiv = "arxiv"
+	// ProviderPubMed selects PubMed through the NCBI E-utilities API, which
+	// searches the biomedical literature
+	ProviderPubMed = "pubmed"
+	// ProviderStackExchange selects the Stack Exchange API, which searches the
+	// questions of Stack Overflow or another Stack Exchange site
+	ProviderStackExchange = "stackexchange"
+	// ProviderGeneric selects a self-hosted or otherwise unsupported JSON
+	// search API, whose response GenericMapping maps onto results
+	ProviderGeneric = "generic"
+	// ProviderCommand runs a search command once per search and reads its
+	// results as JSON from its stdout
+	ProviderCommand = "command"
+	// ProviderGRPC calls an internal search service over gRPC, see
+	// proto/mcpsearch/v1/search.proto
+	ProviderGRPC = "grpc"
+)
+
+// Supported values for StartupCheck
+const (
+	// StartupCheckOff skips the startup readiness check
+	StartupCheckOff = "off"
+	// StartupCheckFail exits at startup if the readiness check fails
+	StartupCheckFail = "fail"
+	// StartupCheckGate withholds tools until a readiness check succeeds
+	StartupCheckGate = "gate"
+)
+
+// Supported values for AggregateMerge
+const (
+	// AggregateMergeInterleave takes each provider's results in turn
+	AggregateMergeInterleave = "interleave"
+	// AggregateMergeRRF ranks results by reciprocal rank fusion, merging duplicates
+	AggregateMergeRRF = "rrf"
+)
+
+// Result pipeline stages, see ResultPipeline
+const (
+	// StageDedupe drops results whose URL repeats an earlier one
+	StageDedupe = "dedupe"
+	// StageFilter drops denylisted results and removes or flags unsafe ones
+	StageFilter = "filter"
+	// StageRerank pins, boosts and demotes results by domain
+	StageRerank = "rerank"
+	// StageTruncate caps the results at the requested count
+	StageTruncate = "truncate"
+	// StageFormat strips markup and extra whitespace from titles and snippets
+	StageFormat = "format"
+	// StageNone disables every stage
+	StageNone = "none"
 )
 
+// DefaultResultPipeline is the order results are processed in unless configured
+var DefaultResultPipeline = []string{StageDedupe, StageFilter, StageRerank, StageTruncate, StageFormat}
+
+// ContainerConfigPaths are the conventional locations checked for a mounted
+// configuration file when CONFIG_FILE is not set, in order of preference
+var ContainerConfigPaths = []string{
+	"/etc/mcp-search/config.yaml",
+	"/etc/mcp-search/config.yml",
+	"/etc/mcp-search/config.json",
+}
+
 // Config holds the application configuration
 type Config struct {
 	// API configuration
 	BochaAPIKey     string        `yaml:"bocha_api_key" json:"bocha_api_key"`
+	BochaAPIKeyFile string        `yaml:"bocha_api_key_file" json:"bocha_api_key_file"`
 	BochaAPIBaseURL string        `yaml:"bocha_api_base_url" json:"bocha_api_base_url"`
 	HTTPTimeout     time.Duration `yaml:"-" json:"-"` // Custom handling for YAML/JSON
+	// BochaProURL is Bocha's pro endpoint, whose responses add an answer and
+	// rich snippets; BochaPro sends every search there unless a call sets pro=false
+	BochaProURL string `yaml:"bocha_pro_url" json:"bocha_pro_url"`
+	BochaPro    bool   `yaml:"bocha_pro" json:"bocha_pro"`
+	// BochaAISearchURL is Bocha's AI search, whose responses add a written
+	// answer and modal cards; a search goes there when a call sets ai_search
+	BochaAISearchURL string `yaml:"bocha_ai_search_url" json:"bocha_ai_search_url"`
+	// BochaAgentSearchURL is Bocha's agent search, which hands a query to one
+	// of BochaAgents when a call names it in agent
+	BochaAgentSearchURL string   `yaml:"bocha_agent_search_url" json:"bocha_agent_search_url"`
+	BochaAgents         []string `yaml:"bocha_agents" json:"bocha_agents"`
+	// BochaEndpoint is the base URL of the Bocha API and BochaPaths overrides
+	// the path of each operation below it, see BochaURL. BochaAPIBaseURL and
+	// BochaProURL, when set, are used as they are instead.
+	BochaEndpoint string            `yaml:"bocha_endpoint" json:"bocha_endpoint"`
+	BochaPaths    map[string]string `yaml:"bocha_paths" json:"bocha_paths"`
+	// BochaRegions lists regional endpoints used instead of BochaEndpoint. They
+	// are probed at startup and searches go to the fastest, failing over to
+	// the others.
+	BochaRegions []string `yaml:"bocha_regions" json:"bocha_regions"`
+	// Brave Search API configuration, used when SearchProvider is brave
+	BraveAPIKey     string `yaml:"brave_api_key" json:"brave_api_key"`
+	BraveAPIKeyFile string `yaml:"brave_api_key_file" json:"brave_api_key_file"`
+	BraveAPIBaseURL string `yaml:"brave_api_base_url" json:"brave_api_base_url"`
+	// Google Programmable Search configuration, used when SearchProvider is
+	// google: an API key and the search engine ID (cx)
+	GoogleAPIKey     string `yaml:"google_api_key" json:"google_api_key"`
+	GoogleAPIKeyFile string `yaml:"google_api_key_file" json:"google_api_key_file"`
+	GoogleCX         string `yaml:"google_cx" json:"google_cx"`
+	GoogleAPIBaseURL string `yaml:"google_api_base_url" json:"google_api_base_url"`
+	// Baidu web search configuration, used when SearchProvider is baidu: a
+	// Qianfan API key
+	BaiduAPIKey     string `yaml:"baidu_api_key" json:"baidu_api_key"`
+	BaiduAPIKeyFile string `yaml:"baidu_api_key_file" json:"baidu_api_key_file"`
+	BaiduAPIBaseURL string `yaml:"baidu_api_base_url" json:"baidu_api_base_url"`
+	// Jina configuration, used when SearchProvider is jina: the search endpoint,
+	// and the reader endpoint that reads pages the search could not
+	JinaAPIKey     string `yaml:"jina_api_key" json:"jina_api_key"`
+	JinaAPIKeyFile string `yaml:"jina_api_key_file" json:"jina_api_key_file"`
+	JinaSearchURL  string `yaml:"jina_search_url" json:"jina_search_url"`
+	JinaReaderURL  string `yaml:"jina_reader_url" json:"jina_reader_url"`
+
+	// arXiv configuration, used when SearchProvider is arxiv. The API needs no key.
+	ArxivAPIURL string `yaml:"arxiv_api_url" json:"arxiv_api_url"`
+
+	// PubMed configuration, used when SearchProvider is pubmed. The NCBI key is
+	// optional and raises the rate limit.
+	PubMedAPIKey     string `yaml:"pubmed_api_key" json:"pubmed_api_key"`
+	PubMedAPIKeyFile string `yaml:"pubmed_api_key_file" json:"pubmed_api_key_file"`
+	PubMedAPIBaseURL string `yaml:"pubmed_api_base_url" json:"pubmed_api_base_url"`
+
+	// Stack Exchange configuration, used when SearchProvider is stackexchange.
+	// The key is optional and raises the daily quota; StackExchangeSite is the
+	// site searched, such as stackoverflow or serverfault.
+	StackExchangeKey     string `yaml:"stackexchange_key" json:"stackexchange_key"`
+	StackExchangeKeyFile string `yaml:"stackexchange_key_file" json:"stackexchange_key_file"`
+	StackExchangeSite    string `yaml:"stackexchange_site" json:"stackexchange_site"`
+	StackExchangeAPIURL  string `yaml:"stackexchange_api_url" json:"stackexchange_api_url"`
+
+	// Generic provider configuration, used when SearchProvider is generic.
+	// Settings left empty are derived from the GenericOpenAPIOperation of the
+	// OpenAPI document at GenericOpenAPISpec, if any, or take their defaults;
+	// see GenericEndpoint.
+	GenericAPIURL           string         `yaml:"generic_api_url" json:"generic_api_url"`
+	GenericMethod           string         `yaml:"generic_method" json:"generic_method"`
+	GenericAPIKey           string         `yaml:"generic_api_key" json:"generic_api_key"`
+	GenericAPIKeyFile       string         `yaml:"generic_api_key_file" json:"generic_api_key_file"`
+	GenericAuthHeader       string         `yaml:"generic_auth_header" json:"generic_auth_header"`
+	GenericAuthParam        string         `yaml:"generic_auth_param" json:"generic_auth_param"`
+	GenericQueryParam       string         `yaml:"generic_query_param" json:"generic_query_param"`
+	GenericCountParam       string         `yaml:"generic_count_param" json:"generic_count_param"`
+	GenericMapping          GenericMapping `yaml:"generic_mapping" json:"generic_mapping"`
+	GenericOpenAPISpec      string         `yaml:"generic_openapi_spec" json:"generic_openapi_spec"`
+	GenericOpenAPIOperation string         `yaml:"generic_openapi_operation" json:"generic_openapi_operation"`
+
+	// Command provider configuration, used when SearchProvider is command.
+	// CommandArgs may contain the {query}, {count} and {freshness}
+	// placeholders; without {query} the query is written to stdin. The command
+	// sees only CommandEnv of the server's environment, runs in CommandDir and
+	// is killed after CommandTimeout or once it writes more than
+	// CommandMaxOutput bytes. At most CommandMaxProcs run at once.
+	CommandPath      string         `yaml:"command_path" json:"command_path"`
+	CommandArgs      []string       `yaml:"command_args" json:"command_args"`
+	CommandEnv       []string       `yaml:"command_env" json:"command_env"`
+	CommandDir       string         `yaml:"command_dir" json:"command_dir"`
+	CommandTimeout   time.Duration  `yaml:"-" json:"-"` // Custom handling for YAML/JSON
+	CommandMaxOutput int            `yaml:"command_max_output" json:"command_max_output"`
+	CommandMaxProcs  int            `yaml:"command_max_procs" json:"command_max_procs"`
+	CommandMapping   GenericMapping `yaml:"command_mapping" json:"command_mapping"`
+
+	// gRPC provider configuration, used when SearchProvider is grpc. GRPCTarget
+	// is the host:port of the service. The connection uses TLS unless
+	// GRPCPlaintext is set, trusting GRPCCAFile instead of the system roots when
+	// set and presenting GRPCCertFile and GRPCKeyFile to services that require a
+	// client certificate. GRPCAPIKey is sent as a bearer token in the
+	// authorization metadata, alongside GRPCMetadata.
+	GRPCTarget     string            `yaml:"grpc_target" json:"grpc_target"`
+	GRPCAPIKey     string            `yaml:"grpc_api_key" json:"grpc_api_key"`
+	GRPCAPIKeyFile string            `yaml:"grpc_api_key_file" json:"grpc_api_key_file"`
+	GRPCMetadata   map[string]string `yaml:"grpc_metadata" json:"grpc_metadata"`
+	GRPCCAFile     string            `yaml:"grpc_ca_file" json:"grpc_ca_file"`
+	GRPCCertFile   string            `yaml:"grpc_cert_file" json:"grpc_cert_file"`
+	GRPCKeyFile    string            `yaml:"grpc_key_file" json:"grpc_key_file"`
+	GRPCServerName string            `yaml:"grpc_server_name" json:"grpc_server_name"`
+	GRPCPlaintext  bool              `yaml:"grpc_plaintext" json:"grpc_plaintext"`
+	// UpstreamAllowlist lists hosts the base URL may point at besides the known
+	// API hosts, e.g. a corporate proxy; see CheckUpstreamURL
+	UpstreamAllowlist []string `yaml:"upstream_allowlist" json:"upstream_allowlist"`
+	// AllowInsecureHTTP permits a plain http base URL, e.g. for local testing
+	AllowInsecureHTTP bool `yaml:"allow_insecure_http" json:"allow_insecure_http"`
+	// TLSMinVersion is the lowest TLS version used with the upstream API, 1.2 or
+	// 1.3; TLSCipherSuites restricts the TLS 1.2 cipher suites by name
+	TLSMinVersion   string   `yaml:"tls_min_version" json:"tls_min_version"`
+	TLSCipherSuites []string `yaml:"tls_cipher_suites" json:"tls_cipher_suites"`
+	// DialIPFamily limits or orders the IP families used to reach the upstream
+	// API; DialLocalAddress binds its connections to a local IP address or
+	// network interface. See DialContext.
+	DialIPFamily     string `yaml:"dial_ip_family" json:"dial_ip_family"`
+	DialLocalAddress string `yaml:"dial_local_address" json:"dial_local_address"`
+	// RequestTemplates replaces the JSON body of a provider's search request
+	// with a Go template over the normalized parameters, for gateways whose API
+	// differs slightly; see RequestTemplate. Only set in the configuration file.
+	RequestTemplates map[string]string `yaml:"request_templates" json:"request_templates"`
+	// WorkerPoolSize bounds the concurrent upstream calls made by background and
+	// batched work; JobTimeout cancels each call that runs longer
+	WorkerPoolSize int           `yaml:"worker_pool_size" json:"worker_pool_size"`
+	JobTimeout     time.Duration `yaml:"-" json:"-"` // Custom handling for YAML/JSON
+	// WarmStart connects to the providers' APIs at startup, and again every
+	// WarmStartInterval, so the first search after starting or idling doesn't
+	// wait for the DNS lookup and TLS handshake. A zero interval warms once.
+	WarmStart         bool          `yaml:"warm_start" json:"warm_start"`
+	WarmStartInterval time.Duration `yaml:"-" json:"-"` // Custom handling for YAML/JSON
 
 	// Server configuration
 	ServerName    string `yaml:"server_name" json:"server_name"`
 	ServerVersion string `yaml:"server_version" json:"server_version"`
 
+	// Provider configuration
+	SearchProvider string   `yaml:"search_provider" json:"search_provider"`
+	PluginCommand  string   `yaml:"plugin_command" json:"plugin_command"`
+	PluginArgs     []string `yaml:"plugin_args" json:"plugin_args"`
+	// ProviderOverrides lists further providers a search may select with the
+	// search tool's provider argument, each configured as if it were SearchProvider
+	ProviderOverrides []string `yaml:"provider_overrides" json:"provider_overrides"`
+	// AggregateProviders lists providers queried alongside SearchProvider for
+	// every search; ProviderTimeout bounds each provider's share of the call
+	AggregateProviders []string      `yaml:"aggregate_providers" json:"aggregate_providers"`
+	ProviderTimeout    time.Duration `yaml:"-" json:"-"` // Custom handling for YAML/JSON
+	// AggregateAutoWeight ranks aggregated results by the providers' scores
+	// instead of in configuration order
+	AggregateAutoWeight bool `yaml:"aggregate_auto_weight" json:"aggregate_auto_weight"`
+	// AggregateMerge controls how aggregated results are combined: interleave or rrf
+	AggregateMerge string `yaml:"aggregate_merge" json:"aggregate_merge"`
+
+	// Query rewriting rules, applied in order before dispatching to the provider
+	RewriteRules []RewriteRule `yaml:"rewrite_rules" json:"rewrite_rules"`
+
+	// Result boosting rules, applied to the results of matching queries
+	BoostRules []BoostRule `yaml:"boost_rules" json:"boost_rules"`
+
+	// ResultPipeline lists the post-processing stages applied to results, in order;
+	// stages left out are disabled
+	ResultPipeline []string `yaml:"result_pipeline" json:"result_pipeline"`
+
+	// ToolFreshness maps tool names to the freshness used when a call does not
+	// set one, e.g. search: week; other tools search without a time limit
+	ToolFreshness map[string]string `yaml:"tool_freshness" json:"tool_freshness"`
+
+	// SearchPresets are named combinations of search parameters a call can
+	// select with the preset argument
+	SearchPresets map[string]SearchPreset `yaml:"search_presets" json:"search_presets"`
+
+	// Tool exposure profiles: profile name -> tool names, and client token -> profile name
+	ToolProfiles       map[string][]string `yaml:"tool_profiles" json:"tool_profiles"`
+	ClientProfiles     map[string]string   `yaml:"client_profiles" json:"client_profiles"`
+	DefaultToolProfile string              `yaml:"default_tool_profile" json:"default_tool_profile"`
+	ClientToken        string              `yaml:"-" json:"-"` // Only from the environment
+
+	// StartupCheck controls the upstream readiness check at startup: off, fail or gate
+	StartupCheck string `yaml:"startup_check" json:"startup_check"`
+
+	// Logging configuration
+	LogLevel string `yaml:"log_level" json:"log_level"`
+
+	// Timezone is the IANA zone, such as Asia/Shanghai, result dates are shown
+	// in; dates are shown as the provider returns them when it is empty
+	Timezone string `yaml:"timezone" json:"timezone"`
+
+	// Admin API configuration
+	AdminAddr  string `yaml:"admin_addr" json:"admin_addr"`
+	AdminToken string `yaml:"admin_token" json:"admin_token"`
+
+	// SSEAddr serves MCP over SSE on this address instead of stdio, to clients
+	// sending SSEToken as a bearer token; each session is a worker pool tenant
+	SSEAddr  string `yaml:"sse_addr" json:"sse_addr"`
+	SSEToken string `yaml:"sse_token" json:"sse_token"`
+
+	// QueryLogPolicy controls how queries appear in recent query lists: full, redact, hash or hide
+	QueryLogPolicy string `yaml:"query_log_policy" json:"query_log_policy"`
+	// PIIScrub lists the kinds of personal data (email, phone, id, or none)
+	// removed from queries before they are recorded; PIIPatterns adds regular
+	// expressions for organization-specific data
+	PIIScrub    []string `yaml:"pii_scrub" json:"pii_scrub"`
+	PIIPatterns []string `yaml:"pii_patterns" json:"pii_patterns"`
+
+	// Cache configuration
+	CacheTTL        time.Duration `yaml:"-" json:"-"` // Custom handling for YAML/JSON
+	CacheMaxEntries int           `yaml:"cache_max_entries" json:"cache_max_entries"`
+
+	// Semantic answer cache: summary searches reuse the answer to a previous
+	// question at least this similar (0-1). A zero threshold disables it.
+	SemanticCacheThreshold  float64       `yaml:"semantic_cache_threshold" json:"semantic_cache_threshold"`
+	SemanticCacheTTL        time.Duration `yaml:"-" json:"-"` // Custom handling for YAML/JSON
+	SemanticCacheMaxEntries int           `yaml:"semantic_cache_max_entries" json:"semantic_cache_max_entries"`
+	// SemanticCacheEmbeddingURL is an OpenAI-compatible embeddings endpoint
+	// questions are embedded with, using SemanticCacheEmbeddingModel and sending
+	// SemanticCacheEmbeddingToken as a bearer token. Without one the built-in
+	// lexical embedder is used, which needs MinHashSemanticCacheThreshold.
+	SemanticCacheEmbeddingURL   string `yaml:"semantic_cache_embedding_url" json:"semantic_cache_embedding_url"`
+	SemanticCacheEmbeddingModel string `yaml:"semantic_cache_embedding_model" json:"semantic_cache_embedding_model"`
+	SemanticCacheEmbeddingToken string `yaml:"semantic_cache_embedding_token" json:"semantic_cache_embedding_token"`
+
+	// Denylist blocks results by domain (example.com, which includes subdomains) or
+	// URL prefix (example.com/path). DenylistFeedURL is a remote list in the same
+	// format, one entry per line, re-downloaded every DenylistRefresh.
+	Denylist        []string      `yaml:"denylist" json:"denylist"`
+	DenylistFeedURL string        `yaml:"denylist_feed_url" json:"denylist_feed_url"`
+	DenylistRefresh time.Duration `yaml:"-" json:"-"` // Custom handling for YAML/JSON
+
+	// Content safety filter: off, flag or remove. Results are checked against a
+	// built-in keyword list extended by SafetyKeywords and, when set, a
+	// classification endpoint.
+	SafetyMode          string   `yaml:"safety_mode" json:"safety_mode"`
+	SafetyKeywords      []string `yaml:"safety_keywords" json:"safety_keywords"`
+	SafetyClassifierURL string   `yaml:"safety_classifier_url" json:"safety_classifier_url"`
+
+	// HideResultFooter leaves out the provider, cache status, latency and cost
+	// footer of search results; SearchCost is the approximate cost of a live search
+	HideResultFooter bool    `yaml:"hide_result_footer" json:"hide_result_footer"`
+	SearchCost       float64 `yaml:"search_cost" json:"search_cost"`
+	// HideImages leaves image results out of search results unless a call sets
+	// include_images, for text-only agents
+	HideImages bool `yaml:"hide_images" json:"hide_images"`
+	// StructuredSchemaVersion pins the schema of the structured data attached
+	// to search results, for clients written against an older version; zero
+	// means the current version
+	StructuredSchemaVersion int `yaml:"structured_schema_version" json:"structured_schema_version"`
+
+	// Page fetching configuration. Fetch tools are only exposed when enabled;
+	// the budgets cap outbound fetches across all clients.
+	FetchEnabled           bool          `yaml:"fetch_enabled" json:"fetch_enabled"`
+	FetchTimeout           time.Duration `yaml:"-" json:"-"` // Custom handling for YAML/JSON
+	FetchMaxPagesPerMinute int           `yaml:"fetch_max_pages_per_minute" json:"fetch_max_pages_per_minute"`
+	FetchMaxBytesPerMinute int           `yaml:"fetch_max_bytes_per_minute" json:"fetch_max_bytes_per_minute"`
+	// FetchMaxPageBytes bounds a single page; FetchContentTypes lists the media types returned (type/* wildcards allowed)
+	FetchMaxPageBytes int      `yaml:"fetch_max_page_bytes" json:"fetch_max_page_bytes"`
+	FetchContentTypes []string `yaml:"fetch_content_types" json:"fetch_content_types"`
+	// FetchPageMaxChars bounds the readable text the fetch_page tool returns
+	FetchPageMaxChars int `yaml:"fetch_page_max_chars" json:"fetch_page_max_chars"`
+	// FetchAllowlist lists IP addresses and CIDR ranges that may be fetched even though they are internal
+	FetchAllowlist []string `yaml:"fetch_allowlist" json:"fetch_allowlist"`
+	// FetchArchiveFallback looks up the latest Wayback Machine snapshot of a
+	// page that is gone or refused, through the availability API at FetchArchiveURL
+	FetchArchiveFallback bool   `yaml:"fetch_archive_fallback" json:"fetch_archive_fallback"`
+	FetchArchiveURL      string `yaml:"fetch_archive_url" json:"fetch_archive_url"`
+	// DownloadDir is the directory the download tool saves documents in, which
+	// is only exposed when it is set and fetching is enabled. DownloadMaxBytes
+	// bounds a single document and DownloadMaxTotalBytes everything in the
+	// directory; DownloadContentTypes lists the media types saved.
+	DownloadDir           string   `yaml:"download_dir" json:"download_dir"`
+	DownloadMaxBytes      int      `yaml:"download_max_bytes" json:"download_max_bytes"`
+	DownloadMaxTotalBytes int      `yaml:"download_max_total_bytes" json:"download_max_total_bytes"`
+	DownloadContentTypes  []string `yaml:"download_content_types" json:"download_content_types"`
+
+	// HNSearchEnabled exposes the hn_search tool, which searches Hacker News
+	// through the Algolia API at HNAPIURL
+	HNSearchEnabled bool   `yaml:"hn_search_enabled" json:"hn_search_enabled"`
+	HNAPIURL        string `yaml:"hn_api_url" json:"hn_api_url"`
+
+	// ScreenshotURL is an external renderer, such as a headless Chrome
+	// service, that the screenshot tool asks to capture pages. It is sent
+	// ScreenshotToken as a bearer token when set; ScreenshotMaxBytes bounds an image.
+	ScreenshotURL      string        `yaml:"screenshot_url" json:"screenshot_url"`
+	ScreenshotToken    string        `yaml:"screenshot_token" json:"screenshot_token"`
+	ScreenshotTimeout  time.Duration `yaml:"-" json:"-"` // Custom handling for YAML/JSON
+	ScreenshotMaxBytes int           `yaml:"screenshot_max_bytes" json:"screenshot_max_bytes"`
+
+	// Standing queries, re-run in the background; new results are posted to the webhook
+	Monitors []StandingQuery `yaml:"monitors" json:"monitors"`
+	// Webhook configuration. WebhookFormat is generic (JSON) or slack; payloads
+	// are signed with WebhookSecret when it is set.
+	WebhookURL        string `yaml:"webhook_url" json:"webhook_url"`
+	WebhookFormat     string `yaml:"webhook_format" json:"webhook_format"`
+	WebhookSecret     string `yaml:"webhook_secret" json:"webhook_secret"`
+	WebhookMaxRetries int    `yaml:"webhook_max_retries" json:"webhook_max_retries"`
+
+	// DataDir holds local state such as saved results
+	DataDir string `yaml:"data_dir" json:"data_dir"`
+	// DataKey encrypts local state at rest: 32 bytes as base64 or hex. DataKeyFile
+	// names a file holding the key instead, e.g. one provided by a secret store.
+	DataKey     string `yaml:"data_key" json:"data_key"`
+	DataKeyFile string `yaml:"data_key_file" json:"data_key_file"`
+
+	// DailyQuotas maps provider names to the API calls their plan allows per
+	// day, to estimate what is left; calls are counted under DataDir, by day
+	// in Timezone or UTC
+	DailyQuotas map[string]int `yaml:"daily_quotas" json:"daily_quotas"`
+
+	// Data retention. Session history and saved results older than their max age
+	// or beyond their max entries are dropped; zero means no limit. The cache is
+	// bounded by CacheTTL and CacheMaxEntries. PurgeOnShutdown erases history,
+	// saved results and cached responses when the server stops, and
+	// PurgeToolEnabled exposes the purge_data tool that erases them on demand.
+	HistoryMaxAge     time.Duration `yaml:"-" json:"-"` // Custom handling for YAML/JSON
+	HistoryMaxEntries int           `yaml:"history_max_entries" json:"history_max_entries"`
+	SavedMaxAge       time.Duration `yaml:"-" json:"-"` // Custom handling for YAML/JSON
+	SavedMaxEntries   int           `yaml:"saved_max_entries" json:"saved_max_entries"`
+	PurgeOnShutdown   bool          `yaml:"purge_on_shutdown" json:"purge_on_shutdown"`
+	PurgeToolEnabled  bool          `yaml:"purge_tool_enabled" json:"purge_tool_enabled"`
+	// Incognito keeps searches out of history, query logs and the cache unless
+	// a call sets incognito=false
+	Incognito bool `yaml:"incognito" json:"incognito"`
+
+	// Source is the configuration file that was loaded, if any
+	Source string `yaml:"-" json:"-"`
+
+	// loadErr records a fatal error loading the container configuration file
+	loadErr error
+
 	// Internal fields not for YAML/JSON
-	HTTPTimeoutStr string `yaml:"http_timeout" json:"http_timeout"`
+	HTTPTimeoutStr       string `yaml:"http_timeout" json:"http_timeout"`
+	JobTimeoutStr        string `yaml:"job_timeout" json:"job_timeout"`
+	WarmStartIntervalStr string `yaml:"warm_start_interval" json:"warm_start_interval"`
+	ProviderTimeoutStr   string `yaml:"provider_timeout" json:"provider_timeout"`
+	CommandTimeoutStr    string `yaml:"command_timeout" json:"command_timeout"`
+	CacheTTLStr          string `yaml:"cache_ttl" json:"cache_ttl"`
+	SemanticCacheTTLStr  string `yaml:"semantic_cache_ttl" json:"semantic_cache_ttl"`
+	FetchTimeoutStr      string `yaml:"fetch_timeout" json:"fetch_timeout"`
+	ScreenshotTimeoutStr string `yaml:"screenshot_timeout" json:"screenshot_timeout"`
+	DenylistRefreshStr   string `yaml:"denylist_refresh" json:"denylist_refresh"`
+	HistoryMaxAgeStr     string `yaml:"history_max_age" json:"history_max_age"`
+	SavedMaxAgeStr       string `yaml:"saved_max_age" json:"saved_max_age"`
+}
+
+// RewriteRule replaces every match of Pattern in a query with Replacement.
+// Replacement may reference capture groups using $1 or ${name} syntax.
+type RewriteRule struct {
+	Pattern     string `yaml:"pattern" json:"pattern"`
+	Replacement string `yaml:"replacement" json:"replacement"`
+}
+
+// BoostRule reorders the results of queries matching Pattern by domain.
+// Results from Pin domains come first and are fetched if missing, Boost domains
+// rank above the rest, and Demote domains rank last. Subdomains match too.
+type BoostRule struct {
+	Pattern string   `yaml:"pattern" json:"pattern"`
+	Pin     []string `yaml:"pin" json:"pin"`
+	Boost   []string `yaml:"boost" json:"boost"`
+	Demote  []string `yaml:"demote" json:"demote"`
+}
+
+// SearchPreset is a named set of search tool arguments. Unset fields are left
+// to the call; arguments the call sets override the preset.
+type SearchPreset struct {
+	Freshness     string `yaml:"freshness" json:"freshness"`
+	Count         int    `yaml:"count" json:"count"`
+	Summary       *bool  `yaml:"summary" json:"summary"`
+	GroupByDate   *bool  `yaml:"group_by_date" json:"group_by_date"`
+	IncludeImages *bool  `yaml:"include_images" json:"include_images"`
+	Format        string `yaml:"format" json:"format"`
+}
+
+// Arguments returns the preset as search tool arguments, with numbers as
+// float64 like arguments decoded from JSON
+func (p SearchPreset) Arguments() map[string]interface{} {
+	args := make(map[string]interface{})
+	if p.Freshness != "" {
+		args["freshness"] = p.Freshness
+	}
+	if p.Count > 0 {
+		args["count"] = float64(p.Count)
+	}
+	if p.Summary != nil {
+		args["summary"] = *p.Summary
+	}
+	if p.GroupByDate != nil {
+		args["group_by_date"] = *p.GroupByDate
+	}
+	if p.IncludeImages != nil {
+		args["include_images"] = *p.IncludeImages
+	}
+	if p.Format != "" {
+		args["format"] = p.Format
+	}
+	return args
+}
+
+// Supported values for WebhookFormat
+const (
+	// WebhookFormatGeneric posts the hit as a JSON document
+	WebhookFormatGeneric = "generic"
+	// WebhookFormatSlack posts a Slack incoming webhook message
+	WebhookFormatSlack = "slack"
+)
+
+// Supported values for SafetyMode
+const (
+	// SafetyOff disables the content safety filter
+	SafetyOff = "off"
+	// SafetyFlag keeps unsafe results but marks them with their category
+	SafetyFlag = "flag"
+	// SafetyRemove drops unsafe results
+	SafetyRemove = "remove"
+)
+
+// MinDenylistRefresh is the shortest interval the denylist feed may be refreshed at
+const MinDenylistRefresh = time.Minute
+
+// MinHashSemanticCacheThreshold is the loosest semantic cache threshold the
+// built-in lexical embedder is trusted with
+const MinHashSemanticCacheThreshold = 0.95
+
+// MinMonitorInterval is the shortest interval a standing query may run at
+const MinMonitorInterval = time.Minute
+
+// StandingQuery is a search re-run every Interval (default 1h) to detect new results
+type StandingQuery struct {
+	Name      string `yaml:"name" json:"name"`
+	Query     string `yaml:"query" json:"query"`
+	Freshness string `yaml:"freshness" json:"freshness"`
+	Count     int    `yaml:"count" json:"count"`
+	Interval  string `yaml:"interval" json:"interval"`
+}
+
+// Every returns how often the query runs
+func (q StandingQuery) Every() (time.Duration, error) {
+	if q.Interval == "" {
+		return time.Hour, nil
+	}
+	interval, err := time.ParseDuration(q.Interval)
+	if err != nil {
+		return 0, err
+	}
+	if interval < MinMonitorInterval {
+		return 0, fmt.Errorf("interval must be at least %s", MinMonitorInterval)
+	}
+	return interval, nil
 }
 
 // New creates a new configuration with values from environment variables
 func New() *Config {
 	config := &Config{
 		// Default values
-		BochaAPIKey:     os.Getenv("BOCHA_API_KEY"),
-		BochaAPIBaseURL: getEnvWithDefault("BOCHA_API_BASE_URL", "https://api.bochaai.com/v1/web-search"),
-		HTTPTimeout:     getEnvDurationWithDefault("HTTP_TIMEOUT", 15*time.Second),
-		ServerName:      getEnvWithDefault("SERVER_NAME", "Bocha AI Search Server"),
-		ServerVersion:   getEnvWithDefault("SERVER_VERSION", "0.0.1"),
+		BochaAPIKey:             os.Getenv("BOCHA_API_KEY"),
+		BochaAPIKeyFile:         os.Getenv("BOCHA_API_KEY_FILE"),
+		BochaAPIBaseURL:         os.Getenv("BOCHA_API_BASE_URL"),
+		BochaProURL:             os.Getenv("BOCHA_PRO_URL"),
+		BochaAISearchURL:        os.Getenv("BOCHA_AI_SEARCH_URL"),
+		BochaAgentSearchURL:     os.Getenv("BOCHA_AGENT_SEARCH_URL"),
+		BochaAgents:             getEnvListWithDefault("BOCHA_AGENTS", DefaultBochaAgents),
+		BochaEndpoint:           getEnvWithDefault("BOCHA_ENDPOINT", DefaultBochaEndpoint),
+		BochaPaths:              getEnvMapWithDefault("BOCHA_PATHS", nil),
+		BochaRegions:            getEnvListWithDefault("BOCHA_REGIONS", nil),
+		BochaPro:                getEnvBoolWithDefault("BOCHA_PRO", false),
+		BraveAPIKey:             os.Getenv("BRAVE_API_KEY"),
+		BraveAPIKeyFile:         os.Getenv("BRAVE_API_KEY_FILE"),
+		BraveAPIBaseURL:         getEnvWithDefault("BRAVE_API_BASE_URL", "https://api.search.brave.com/res/v1/web/search"),
+		GoogleAPIKey:            os.Getenv("GOOGLE_API_KEY"),
+		GoogleAPIKeyFile:        os.Getenv("GOOGLE_API_KEY_FILE"),
+		GoogleCX:                os.Getenv("GOOGLE_CX"),
+		GoogleAPIBaseURL:        getEnvWithDefault("GOOGLE_API_BASE_URL", "https://www.googleapis.com/customsearch/v1"),
+		BaiduAPIKey:             os.Getenv("BAIDU_API_KEY"),
+		BaiduAPIKeyFile:         os.Getenv("BAIDU_API_KEY_FILE"),
+		BaiduAPIBaseURL:         getEnvWithDefault("BAIDU_API_BASE_URL", "https://qianfan.baidubce.com/v2/ai_search/web_search"),
+		JinaAPIKey:              os.Getenv("JINA_API_KEY"),
+		JinaAPIKeyFile:          os.Getenv("JINA_API_KEY_FILE"),
+		JinaSearchURL:           getEnvWithDefault("JINA_SEARCH_URL", "https://s.jina.ai/"),
+		JinaReaderURL:           getEnvWithDefault("JINA_READER_URL", "https://r.jina.ai/"),
+		ArxivAPIURL:             getEnvWithDefault("ARXIV_API_URL", "https://export.arxiv.org/api/query"),
+		PubMedAPIKey:            os.Getenv("PUBMED_API_KEY"),
+		PubMedAPIKeyFile:        os.Getenv("PUBMED_API_KEY_FILE"),
+		PubMedAPIBaseURL:        getEnvWithDefault("PUBMED_API_BASE_URL", "https://eutils.ncbi.nlm.nih.gov/entrez/eutils"),
+		StackExchangeKey:        os.Getenv("STACKEXCHANGE_KEY"),
+		StackExchangeKeyFile:    os.Getenv("STACKEXCHANGE_KEY_FILE"),
+		StackExchangeSite:       getEnvWithDefault("STACKEXCHANGE_SITE", "stackoverflow"),
+		StackExchangeAPIURL:     getEnvWithDefault("STACKEXCHANGE_API_URL", "https://api.stackexchange.com/2.3"),
+		GenericAPIURL:           os.Getenv("GENERIC_API_URL"),
+		GenericAPIKey:           os.Getenv("GENERIC_API_KEY"),
+		GenericAPIKeyFile:       os.Getenv("GENERIC_API_KEY_FILE"),
+		GenericMethod:           os.Getenv("GENERIC_METHOD"),
+		GenericAuthHeader:       os.Getenv("GENERIC_AUTH_HEADER"),
+		GenericAuthParam:        os.Getenv("GENERIC_AUTH_PARAM"),
+		GenericQueryParam:       os.Getenv("GENERIC_QUERY_PARAM"),
+		GenericCountParam:       os.Getenv("GENERIC_COUNT_PARAM"),
+		GenericOpenAPISpec:      os.Getenv("GENERIC_OPENAPI_SPEC"),
+		GenericOpenAPIOperation: os.Getenv("GENERIC_OPENAPI_OPERATION"),
+		CommandPath:             os.Getenv("COMMAND_PATH"),
+		CommandArgs:             getEnvListWithDefault("COMMAND_ARGS", nil),
+		CommandEnv:              getEnvListWithDefault("COMMAND_ENV", nil),
+		CommandDir:              os.Getenv("COMMAND_DIR"),
+		CommandTimeout:          getEnvDurationWithDefault("COMMAND_TIMEOUT", 10*time.Second),
+		CommandMaxOutput:        getEnvIntWithDefault("COMMAND_MAX_OUTPUT", 1024*1024),
+		CommandMaxProcs:         getEnvIntWithDefault("COMMAND_MAX_PROCS", 4),
+		CommandMapping:          DefaultGenericMapping,
+		GRPCTarget:              os.Getenv("GRPC_TARGET"),
+		GRPCAPIKey:              os.Getenv("GRPC_API_KEY"),
+		GRPCAPIKeyFile:          os.Getenv("GRPC_API_KEY_FILE"),
+		GRPCMetadata:            getEnvMapWithDefault("GRPC_METADATA", nil),
+		GRPCCAFile:              os.Getenv("GRPC_CA_FILE"),
+		GRPCCertFile:            os.Getenv("GRPC_CERT_FILE"),
+		GRPCKeyFile:             os.Getenv("GRPC_KEY_FILE"),
+		GRPCServerName:          os.Getenv("GRPC_SERVER_NAME"),
+		GRPCPlaintext:           getEnvBoolWithDefault("GRPC_PLAINTEXT", false),
+		UpstreamAllowlist:       getEnvListWithDefault("UPSTREAM_ALLOWLIST", nil),
+		AllowInsecureHTTP:       getEnvBoolWithDefault("ALLOW_INSECURE_HTTP", false),
+		TLSMinVersion:           getEnvWithDefault("TLS_MIN_VERSION", TLSVersion12),
+		DialIPFamily:            os.Getenv("DIAL_IP_FAMILY"),
+		DialLocalAddress:        os.Getenv("DIAL_LOCAL_ADDRESS"),
+		TLSCipherSuites:         getEnvListWithDefault("TLS_CIPHER_SUITES", nil),
+		HTTPTimeout:             getEnvDurationWithDefault("HTTP_TIMEOUT", 15*time.Second),
+		WorkerPoolSize:          getEnvIntWithDefault("WORKER_POOL_SIZE", pool.DefaultSize),
+		JobTimeout:              getEnvDurationWithDefault("JOB_TIMEOUT", 30*time.Second),
+		WarmStart:               getEnvBoolWithDefault("WARM_START", false),
+		WarmStartInterval:       getEnvDurationWithDefault("WARM_START_INTERVAL", time.Minute),
+		ServerName:              getEnvWithDefault("SERVER_NAME", "Bocha AI Search Server"),
+		ServerVersion:           getEnvWithDefault("SERVER_VERSION", "0.0.1"),
+		SearchProvider:          getEnvWithDefault("SEARCH_PROVIDER", ProviderBocha),
+		PluginCommand:           os.Getenv("PLUGIN_COMMAND"),
+		ProviderOverrides:       getEnvListWithDefault("PROVIDER_OVERRIDES", nil),
+		AggregateProviders:      getEnvListWithDefault("AGGREGATE_PROVIDERS", nil),
+		ProviderTimeout:         getEnvDurationWithDefault("PROVIDER_TIMEOUT", 10*time.Second),
+		AggregateAutoWeight:     getEnvBoolWithDefault("AGGREGATE_AUTO_WEIGHT", false),
+		AggregateMerge:          getEnvWithDefault("AGGREGATE_MERGE", AggregateMergeInterleave),
+		ClientToken:             os.Getenv("MCP_CLIENT_TOKEN"),
+		StartupCheck:            getEnvWithDefault("STARTUP_CHECK", StartupCheckOff),
+		LogLevel:                getEnvWithDefault("LOG_LEVEL", "info"),
+		Timezone:                os.Getenv("TIMEZONE"),
+		AdminAddr:               os.Getenv("ADMIN_ADDR"),
+		AdminToken:              os.Getenv("ADMIN_TOKEN"),
+		SSEAddr:                 os.Getenv("SSE_ADDR"),
+		SSEToken:                os.Getenv("SSE_TOKEN"),
+		QueryLogPolicy:          getEnvWithDefault("QUERY_LOG_POLICY", "redact"),
+		PIIScrub:                getEnvListWithDefault("PII_SCRUB", append([]string(nil), privacy.Kinds...)),
+		ResultPipeline:          getEnvListWithDefault("RESULT_PIPELINE", append([]string(nil), DefaultResultPipeline...)),
+		ToolFreshness:           getEnvMapWithDefault("TOOL_FRESHNESS", nil),
+		CacheTTL:                getEnvDurationWithDefault("CACHE_TTL", 0),
+		CacheMaxEntries:         getEnvIntWithDefault("CACHE_MAX_ENTRIES", 1000),
+
+		SemanticCacheThreshold:      getEnvFloatWithDefault("SEMANTIC_CACHE_THRESHOLD", 0),
+		SemanticCacheTTL:            getEnvDurationWithDefault("SEMANTIC_CACHE_TTL", time.Hour),
+		SemanticCacheMaxEntries:     getEnvIntWithDefault("SEMANTIC_CACHE_MAX_ENTRIES", 500),
+		SemanticCacheEmbeddingURL:   os.Getenv("SEMANTIC_CACHE_EMBEDDING_URL"),
+		SemanticCacheEmbeddingModel: os.Getenv("SEMANTIC_CACHE_EMBEDDING_MODEL"),
+		SemanticCacheEmbeddingToken: os.Getenv("SEMANTIC_CACHE_EMBEDDING_TOKEN"),
+
+		Denylist:        getEnvListWithDefault("DENYLIST", nil),
+		DenylistFeedURL: os.Getenv("DENYLIST_FEED_URL"),
+		DenylistRefresh: getEnvDurationWithDefault("DENYLIST_REFRESH", 15*time.Minute),
+
+		SafetyMode:          getEnvWithDefault("SAFETY_MODE", SafetyOff),
+		SafetyKeywords:      getEnvListWithDefault("SAFETY_KEYWORDS", nil),
+		SafetyClassifierURL: os.Getenv("SAFETY_CLASSIFIER_URL"),
+
+		HideResultFooter: getEnvBoolWithDefault("HIDE_RESULT_FOOTER", false),
+		SearchCost:       getEnvFloatWithDefault("SEARCH_COST", 0),
+		HideImages:       getEnvBoolWithDefault("HIDE_IMAGES", false),
+
+		StructuredSchemaVersion: getEnvIntWithDefault("STRUCTURED_SCHEMA_VERSION", 0),
+
+		FetchEnabled:           getEnvBoolWithDefault("FETCH_ENABLED", false),
+		FetchTimeout:           getEnvDurationWithDefault("FETCH_TIMEOUT", 15*time.Second),
+		FetchMaxPagesPerMinute: getEnvIntWithDefault("FETCH_MAX_PAGES_PER_MINUTE", 30),
+		FetchMaxBytesPerMinute: getEnvIntWithDefault("FETCH_MAX_BYTES_PER_MINUTE", 20*1024*1024),
+		FetchMaxPageBytes:      getEnvIntWithDefault("FETCH_MAX_PAGE_BYTES", 2*1024*1024),
+		FetchPageMaxChars:      getEnvIntWithDefault("FETCH_PAGE_MAX_CHARS", 20000),
+		FetchContentTypes:      getEnvListWithDefault("FETCH_CONTENT_TYPES", nil),
+		FetchAllowlist:         getEnvListWithDefault("FETCH_ALLOWLIST", nil),
+		FetchArchiveFallback:   getEnvBoolWithDefault("FETCH_ARCHIVE_FALLBACK", false),
+		FetchArchiveURL:        getEnvWithDefault("FETCH_ARCHIVE_URL", "https://archive.org/wayback/available"),
+		DownloadDir:            os.Getenv("DOWNLOAD_DIR"),
+		DownloadMaxBytes:       getEnvIntWithDefault("DOWNLOAD_MAX_BYTES", 10*1024*1024),
+		DownloadMaxTotalBytes:  getEnvIntWithDefault("DOWNLOAD_MAX_TOTAL_BYTES", 200*1024*1024),
+		DownloadContentTypes:   getEnvListWithDefault("DOWNLOAD_CONTENT_TYPES", nil),
+		HNSearchEnabled:        getEnvBoolWithDefault("HN_SEARCH_ENABLED", false),
+		HNAPIURL:               getEnvWithDefault("HN_API_URL", "https://hn.algolia.com/api/v1"),
+		ScreenshotURL:          os.Getenv("SCREENSHOT_URL"),
+		ScreenshotToken:        os.Getenv("SCREENSHOT_TOKEN"),
+		ScreenshotTimeout:      getEnvDurationWithDefault("SCREENSHOT_TIMEOUT", 30*time.Second),
+		ScreenshotMaxBytes:     getEnvIntWithDefault("SCREENSHOT_MAX_BYTES", 5*1024*1024),
+
+		WebhookURL:        os.Getenv("WEBHOOK_URL"),
+		WebhookFormat:     getEnvWithDefault("WEBHOOK_FORMAT", WebhookFormatGeneric),
+		WebhookSecret:     os.Getenv("WEBHOOK_SECRET"),
+		WebhookMaxRetries: getEnvIntWithDefault("WEBHOOK_MAX_RETRIES", 3),
+
+		DataDir:     getEnvWithDefault("DATA_DIR", defaultDataDir()),
+		DataKey:     os.Getenv("DATA_KEY"),
+		DataKeyFile: os.Getenv("DATA_KEY_FILE"),
+		DailyQuotas: getEnvIntMapWithDefault("DAILY_QUOTAS", nil),
+
+		HistoryMaxAge:     getEnvDurationWithDefault("HISTORY_MAX_AGE", 0),
+		HistoryMaxEntries: getEnvIntWithDefault("HISTORY_MAX_ENTRIES", 500),
+		SavedMaxAge:       getEnvDurationWithDefault("SAVED_MAX_AGE", 0),
+		SavedMaxEntries:   getEnvIntWithDefault("SAVED_MAX_ENTRIES", 0),
+		PurgeOnShutdown:   getEnvBoolWithDefault("PURGE_ON_SHUTDOWN", false),
+		PurgeToolEnabled:  getEnvBoolWithDefault("PURGE_TOOL_ENABLED", false),
+		Incognito:         getEnvBoolWithDefault("INCOGNITO", false),
 	}
 
 	// Check if a config file path is provided
@@ -44,29 +720,508 @@ func New() *Config {
 		if err := config.LoadFromFile(configPath); err != nil {
 			log.Printf("Warning: Failed to load config from file %s: %v", configPath, err)
 		} else {
+			config.Source = configPath
 			log.Printf("Warning: Using configuration file for sensitive data like API keys is not recommended for production environments")
 		}
+	} else if containerPath := findContainerConfig(); containerPath != "" {
+		// Container mode: a file mounted at the conventional path is the whole
+		// configuration, so failing to load it is fatal rather than a warning
+		if err := config.LoadFromFile(containerPath); err != nil {
+			config.loadErr = fmt.Errorf("failed to load container config %s: %w", containerPath, err)
+		} else {
+			config.Source = containerPath
+		}
 	}
 
 	// Environment variables take precedence over config file
 	if envAPIKey := os.Getenv("BOCHA_API_KEY"); envAPIKey != "" {
 		config.BochaAPIKey = envAPIKey
 	}
+	if envAPIKeyFile := os.Getenv("BOCHA_API_KEY_FILE"); envAPIKeyFile != "" {
+		config.BochaAPIKeyFile = envAPIKeyFile
+	}
 	if envAPIBaseURL := os.Getenv("BOCHA_API_BASE_URL"); envAPIBaseURL != "" {
 		config.BochaAPIBaseURL = envAPIBaseURL
 	}
+	if envProURL := os.Getenv("BOCHA_PRO_URL"); envProURL != "" {
+		config.BochaProURL = envProURL
+	}
+	if envAISearchURL := os.Getenv("BOCHA_AI_SEARCH_URL"); envAISearchURL != "" {
+		config.BochaAISearchURL = envAISearchURL
+	}
+	if envAgentSearchURL := os.Getenv("BOCHA_AGENT_SEARCH_URL"); envAgentSearchURL != "" {
+		config.BochaAgentSearchURL = envAgentSearchURL
+	}
+	if envAgents := os.Getenv("BOCHA_AGENTS"); envAgents != "" {
+		config.BochaAgents = getEnvListWithDefault("BOCHA_AGENTS", config.BochaAgents)
+	}
+	if envEndpoint := os.Getenv("BOCHA_ENDPOINT"); envEndpoint != "" {
+		config.BochaEndpoint = envEndpoint
+	}
+	if envPaths := os.Getenv("BOCHA_PATHS"); envPaths != "" {
+		config.BochaPaths = getEnvMapWithDefault("BOCHA_PATHS", config.BochaPaths)
+	}
+	if envRegions := os.Getenv("BOCHA_REGIONS"); envRegions != "" {
+		config.BochaRegions = getEnvListWithDefault("BOCHA_REGIONS", config.BochaRegions)
+	}
+	if envPro := os.Getenv("BOCHA_PRO"); envPro != "" {
+		config.BochaPro = getEnvBoolWithDefault("BOCHA_PRO", config.BochaPro)
+	}
+	if envBraveAPIKey := os.Getenv("BRAVE_API_KEY"); envBraveAPIKey != "" {
+		config.BraveAPIKey = envBraveAPIKey
+	}
+	if envBraveAPIKeyFile := os.Getenv("BRAVE_API_KEY_FILE"); envBraveAPIKeyFile != "" {
+		config.BraveAPIKeyFile = envBraveAPIKeyFile
+	}
+	if envBraveAPIBaseURL := os.Getenv("BRAVE_API_BASE_URL"); envBraveAPIBaseURL != "" {
+		config.BraveAPIBaseURL = envBraveAPIBaseURL
+	}
+	if envGoogleAPIKey := os.Getenv("GOOGLE_API_KEY"); envGoogleAPIKey != "" {
+		config.GoogleAPIKey = envGoogleAPIKey
+	}
+	if envGoogleAPIKeyFile := os.Getenv("GOOGLE_API_KEY_FILE"); envGoogleAPIKeyFile != "" {
+		config.GoogleAPIKeyFile = envGoogleAPIKeyFile
+	}
+	if envGoogleCX := os.Getenv("GOOGLE_CX"); envGoogleCX != "" {
+		config.GoogleCX = envGoogleCX
+	}
+	if envGoogleAPIBaseURL := os.Getenv("GOOGLE_API_BASE_URL"); envGoogleAPIBaseURL != "" {
+		config.GoogleAPIBaseURL = envGoogleAPIBaseURL
+	}
+	if envBaiduAPIKey := os.Getenv("BAIDU_API_KEY"); envBaiduAPIKey != "" {
+		config.BaiduAPIKey = envBaiduAPIKey
+	}
+	if envBaiduAPIKeyFile := os.Getenv("BAIDU_API_KEY_FILE"); envBaiduAPIKeyFile != "" {
+		config.BaiduAPIKeyFile = envBaiduAPIKeyFile
+	}
+	if envBaiduAPIBaseURL := os.Getenv("BAIDU_API_BASE_URL"); envBaiduAPIBaseURL != "" {
+		config.BaiduAPIBaseURL = envBaiduAPIBaseURL
+	}
+	if envJinaAPIKey := os.Getenv("JINA_API_KEY"); envJinaAPIKey != "" {
+		config.JinaAPIKey = envJinaAPIKey
+	}
+	if envJinaAPIKeyFile := os.Getenv("JINA_API_KEY_FILE"); envJinaAPIKeyFile != "" {
+		config.JinaAPIKeyFile = envJinaAPIKeyFile
+	}
+	if envJinaSearchURL := os.Getenv("JINA_SEARCH_URL"); envJinaSearchURL != "" {
+		config.JinaSearchURL = envJinaSearchURL
+	}
+	if envJinaReaderURL := os.Getenv("JINA_READER_URL"); envJinaReaderURL != "" {
+		config.JinaReaderURL = envJinaReaderURL
+	}
+	if envArxivAPIURL := os.Getenv("ARXIV_API_URL"); envArxivAPIURL != "" {
+		config.ArxivAPIURL = envArxivAPIURL
+	}
+	if envPubMedAPIKey := os.Getenv("PUBMED_API_KEY"); envPubMedAPIKey != "" {
+		config.PubMedAPIKey = envPubMedAPIKey
+	}
+	if envPubMedAPIKeyFile := os.Getenv("PUBMED_API_KEY_FILE"); envPubMedAPIKeyFile != "" {
+		config.PubMedAPIKeyFile = envPubMedAPIKeyFile
+	}
+	if envPubMedAPIBaseURL := os.Getenv("PUBMED_API_BASE_URL"); envPubMedAPIBaseURL != "" {
+		config.PubMedAPIBaseURL = envPubMedAPIBaseURL
+	}
+	if envStackExchangeKey := os.Getenv("STACKEXCHANGE_KEY"); envStackExchangeKey != "" {
+		config.StackExchangeKey = envStackExchangeKey
+	}
+	if envStackExchangeKeyFile := os.Getenv("STACKEXCHANGE_KEY_FILE"); envStackExchangeKeyFile != "" {
+		config.StackExchangeKeyFile = envStackExchangeKeyFile
+	}
+	if envStackExchangeSite := os.Getenv("STACKEXCHANGE_SITE"); envStackExchangeSite != "" {
+		config.StackExchangeSite = envStackExchangeSite
+	}
+	if envStackExchangeAPIURL := os.Getenv("STACKEXCHANGE_API_URL"); envStackExchangeAPIURL != "" {
+		config.StackExchangeAPIURL = envStackExchangeAPIURL
+	}
+	if envGenericAPIURL := os.Getenv("GENERIC_API_URL"); envGenericAPIURL != "" {
+		config.GenericAPIURL = envGenericAPIURL
+	}
+	if envGenericMethod := os.Getenv("GENERIC_METHOD"); envGenericMethod != "" {
+		config.GenericMethod = envGenericMethod
+	}
+	if envGenericAPIKey := os.Getenv("GENERIC_API_KEY"); envGenericAPIKey != "" {
+		config.GenericAPIKey = envGenericAPIKey
+	}
+	if envGenericAPIKeyFile := os.Getenv("GENERIC_API_KEY_FILE"); envGenericAPIKeyFile != "" {
+		config.GenericAPIKeyFile = envGenericAPIKeyFile
+	}
+	if envGenericAuthHeader := os.Getenv("GENERIC_AUTH_HEADER"); envGenericAuthHeader != "" {
+		config.GenericAuthHeader = envGenericAuthHeader
+	}
+	if envGenericAuthParam := os.Getenv("GENERIC_AUTH_PARAM"); envGenericAuthParam != "" {
+		config.GenericAuthParam = envGenericAuthParam
+	}
+	if envGenericQueryParam := os.Getenv("GENERIC_QUERY_PARAM"); envGenericQueryParam != "" {
+		config.GenericQueryParam = envGenericQueryParam
+	}
+	if envGenericCountParam := os.Getenv("GENERIC_COUNT_PARAM"); envGenericCountParam != "" {
+		config.GenericCountParam = envGenericCountParam
+	}
+	if envGenericOpenAPISpec := os.Getenv("GENERIC_OPENAPI_SPEC"); envGenericOpenAPISpec != "" {
+		config.GenericOpenAPISpec = envGenericOpenAPISpec
+	}
+	if envGenericOpenAPIOperation := os.Getenv("GENERIC_OPENAPI_OPERATION"); envGenericOpenAPIOperation != "" {
+		config.GenericOpenAPIOperation = envGenericOpenAPIOperation
+	}
+	if envCommandPath := os.Getenv("COMMAND_PATH"); envCommandPath != "" {
+		config.CommandPath = envCommandPath
+	}
+	if envCommandArgs := os.Getenv("COMMAND_ARGS"); envCommandArgs != "" {
+		config.CommandArgs = getEnvListWithDefault("COMMAND_ARGS", config.CommandArgs)
+	}
+	if envCommandEnv := os.Getenv("COMMAND_ENV"); envCommandEnv != "" {
+		config.CommandEnv = getEnvListWithDefault("COMMAND_ENV", config.CommandEnv)
+	}
+	if envCommandDir := os.Getenv("COMMAND_DIR"); envCommandDir != "" {
+		config.CommandDir = envCommandDir
+	}
+	if envCommandTimeout := os.Getenv("COMMAND_TIMEOUT"); envCommandTimeout != "" {
+		config.CommandTimeout = getEnvDurationWithDefault("COMMAND_TIMEOUT", config.CommandTimeout)
+	}
+	if envCommandMaxOutput := os.Getenv("COMMAND_MAX_OUTPUT"); envCommandMaxOutput != "" {
+		config.CommandMaxOutput = getEnvIntWithDefault("COMMAND_MAX_OUTPUT", config.CommandMaxOutput)
+	}
+	if envCommandMaxProcs := os.Getenv("COMMAND_MAX_PROCS"); envCommandMaxProcs != "" {
+		config.CommandMaxProcs = getEnvIntWithDefault("COMMAND_MAX_PROCS", config.CommandMaxProcs)
+	}
+	if envGRPCTarget := os.Getenv("GRPC_TARGET"); envGRPCTarget != "" {
+		config.GRPCTarget = envGRPCTarget
+	}
+	if envGRPCAPIKey := os.Getenv("GRPC_API_KEY"); envGRPCAPIKey != "" {
+		config.GRPCAPIKey = envGRPCAPIKey
+	}
+	if envGRPCAPIKeyFile := os.Getenv("GRPC_API_KEY_FILE"); envGRPCAPIKeyFile != "" {
+		config.GRPCAPIKeyFile = envGRPCAPIKeyFile
+	}
+	if envGRPCMetadata := os.Getenv("GRPC_METADATA"); envGRPCMetadata != "" {
+		config.GRPCMetadata = getEnvMapWithDefault("GRPC_METADATA", config.GRPCMetadata)
+	}
+	if envGRPCCAFile := os.Getenv("GRPC_CA_FILE"); envGRPCCAFile != "" {
+		config.GRPCCAFile = envGRPCCAFile
+	}
+	if envGRPCCertFile := os.Getenv("GRPC_CERT_FILE"); envGRPCCertFile != "" {
+		config.GRPCCertFile = envGRPCCertFile
+	}
+	if envGRPCKeyFile := os.Getenv("GRPC_KEY_FILE"); envGRPCKeyFile != "" {
+		config.GRPCKeyFile = envGRPCKeyFile
+	}
+	if envGRPCServerName := os.Getenv("GRPC_SERVER_NAME"); envGRPCServerName != "" {
+		config.GRPCServerName = envGRPCServerName
+	}
+	if envGRPCPlaintext := os.Getenv("GRPC_PLAINTEXT"); envGRPCPlaintext != "" {
+		config.GRPCPlaintext = getEnvBoolWithDefault("GRPC_PLAINTEXT", config.GRPCPlaintext)
+	}
+	if envUpstreamAllowlist := os.Getenv("UPSTREAM_ALLOWLIST"); envUpstreamAllowlist != "" {
+		config.UpstreamAllowlist = getEnvListWithDefault("UPSTREAM_ALLOWLIST", config.UpstreamAllowlist)
+	}
+	if envAllowInsecureHTTP := os.Getenv("ALLOW_INSECURE_HTTP"); envAllowInsecureHTTP != "" {
+		config.AllowInsecureHTTP = getEnvBoolWithDefault("ALLOW_INSECURE_HTTP", config.AllowInsecureHTTP)
+	}
+	if envTLSMinVersion := os.Getenv("TLS_MIN_VERSION"); envTLSMinVersion != "" {
+		config.TLSMinVersion = envTLSMinVersion
+	}
+	if envDialIPFamily := os.Getenv("DIAL_IP_FAMILY"); envDialIPFamily != "" {
+		config.DialIPFamily = envDialIPFamily
+	}
+	if envDialLocalAddress := os.Getenv("DIAL_LOCAL_ADDRESS"); envDialLocalAddress != "" {
+		config.DialLocalAddress = envDialLocalAddress
+	}
+	if envTLSCipherSuites := os.Getenv("TLS_CIPHER_SUITES"); envTLSCipherSuites != "" {
+		config.TLSCipherSuites = getEnvListWithDefault("TLS_CIPHER_SUITES", config.TLSCipherSuites)
+	}
 	if envHTTPTimeout := os.Getenv("HTTP_TIMEOUT"); envHTTPTimeout != "" {
 		config.HTTPTimeout = getEnvDurationWithDefault("HTTP_TIMEOUT", config.HTTPTimeout)
 	}
+	if envWorkerPoolSize := os.Getenv("WORKER_POOL_SIZE"); envWorkerPoolSize != "" {
+		config.WorkerPoolSize = getEnvIntWithDefault("WORKER_POOL_SIZE", config.WorkerPoolSize)
+	}
+	if envJobTimeout := os.Getenv("JOB_TIMEOUT"); envJobTimeout != "" {
+		config.JobTimeout = getEnvDurationWithDefault("JOB_TIMEOUT", config.JobTimeout)
+	}
+	if envWarmStart := os.Getenv("WARM_START"); envWarmStart != "" {
+		config.WarmStart = getEnvBoolWithDefault("WARM_START", config.WarmStart)
+	}
+	if envWarmStartInterval := os.Getenv("WARM_START_INTERVAL"); envWarmStartInterval != "" {
+		config.WarmStartInterval = getEnvDurationWithDefault("WARM_START_INTERVAL", config.WarmStartInterval)
+	}
 	if envServerName := os.Getenv("SERVER_NAME"); envServerName != "" {
 		config.ServerName = envServerName
 	}
 	if envServerVersion := os.Getenv("SERVER_VERSION"); envServerVersion != "" {
 		config.ServerVersion = envServerVersion
 	}
+	if envSearchProvider := os.Getenv("SEARCH_PROVIDER"); envSearchProvider != "" {
+		config.SearchProvider = envSearchProvider
+	}
+	if envPluginCommand := os.Getenv("PLUGIN_COMMAND"); envPluginCommand != "" {
+		config.PluginCommand = envPluginCommand
+	}
+	if envProviderOverrides := os.Getenv("PROVIDER_OVERRIDES"); envProviderOverrides != "" {
+		config.ProviderOverrides = getEnvListWithDefault("PROVIDER_OVERRIDES", config.ProviderOverrides)
+	}
+	if envAggregateProviders := os.Getenv("AGGREGATE_PROVIDERS"); envAggregateProviders != "" {
+		config.AggregateProviders = getEnvListWithDefault("AGGREGATE_PROVIDERS", config.AggregateProviders)
+	}
+	if envProviderTimeout := os.Getenv("PROVIDER_TIMEOUT"); envProviderTimeout != "" {
+		config.ProviderTimeout = getEnvDurationWithDefault("PROVIDER_TIMEOUT", config.ProviderTimeout)
+	}
+	if envAggregateAutoWeight := os.Getenv("AGGREGATE_AUTO_WEIGHT"); envAggregateAutoWeight != "" {
+		config.AggregateAutoWeight = getEnvBoolWithDefault("AGGREGATE_AUTO_WEIGHT", config.AggregateAutoWeight)
+	}
+	if envAggregateMerge := os.Getenv("AGGREGATE_MERGE"); envAggregateMerge != "" {
+		config.AggregateMerge = envAggregateMerge
+	}
+	if envStartupCheck := os.Getenv("STARTUP_CHECK"); envStartupCheck != "" {
+		config.StartupCheck = envStartupCheck
+	}
+	if envLogLevel := os.Getenv("LOG_LEVEL"); envLogLevel != "" {
+		config.LogLevel = envLogLevel
+	}
+	if envTimezone := os.Getenv("TIMEZONE"); envTimezone != "" {
+		config.Timezone = envTimezone
+	}
+	if envAdminAddr := os.Getenv("ADMIN_ADDR"); envAdminAddr != "" {
+		config.AdminAddr = envAdminAddr
+	}
+	if envAdminToken := os.Getenv("ADMIN_TOKEN"); envAdminToken != "" {
+		config.AdminToken = envAdminToken
+	}
+	if envSSEAddr := os.Getenv("SSE_ADDR"); envSSEAddr != "" {
+		config.SSEAddr = envSSEAddr
+	}
+	if envSSEToken := os.Getenv("SSE_TOKEN"); envSSEToken != "" {
+		config.SSEToken = envSSEToken
+	}
+	if envQueryLogPolicy := os.Getenv("QUERY_LOG_POLICY"); envQueryLogPolicy != "" {
+		config.QueryLogPolicy = envQueryLogPolicy
+	}
+	if envPIIScrub := os.Getenv("PII_SCRUB"); envPIIScrub != "" {
+		config.PIIScrub = getEnvListWithDefault("PII_SCRUB", config.PIIScrub)
+	}
+	if envResultPipeline := os.Getenv("RESULT_PIPELINE"); envResultPipeline != "" {
+		config.ResultPipeline = getEnvListWithDefault("RESULT_PIPELINE", config.ResultPipeline)
+	}
+	if envToolFreshness := os.Getenv("TOOL_FRESHNESS"); envToolFreshness != "" {
+		config.ToolFreshness = getEnvMapWithDefault("TOOL_FRESHNESS", config.ToolFreshness)
+	}
+	if envCacheTTL := os.Getenv("CACHE_TTL"); envCacheTTL != "" {
+		config.CacheTTL = getEnvDurationWithDefault("CACHE_TTL", config.CacheTTL)
+	}
+	if envCacheMaxEntries := os.Getenv("CACHE_MAX_ENTRIES"); envCacheMaxEntries != "" {
+		config.CacheMaxEntries = getEnvIntWithDefault("CACHE_MAX_ENTRIES", config.CacheMaxEntries)
+	}
+	if envSemanticThreshold := os.Getenv("SEMANTIC_CACHE_THRESHOLD"); envSemanticThreshold != "" {
+		config.SemanticCacheThreshold = getEnvFloatWithDefault("SEMANTIC_CACHE_THRESHOLD", config.SemanticCacheThreshold)
+	}
+	if envSemanticTTL := os.Getenv("SEMANTIC_CACHE_TTL"); envSemanticTTL != "" {
+		config.SemanticCacheTTL = getEnvDurationWithDefault("SEMANTIC_CACHE_TTL", config.SemanticCacheTTL)
+	}
+	if envSemanticMaxEntries := os.Getenv("SEMANTIC_CACHE_MAX_ENTRIES"); envSemanticMaxEntries != "" {
+		config.SemanticCacheMaxEntries = getEnvIntWithDefault("SEMANTIC_CACHE_MAX_ENTRIES", config.SemanticCacheMaxEntries)
+	}
+	if envEmbeddingURL := os.Getenv("SEMANTIC_CACHE_EMBEDDING_URL"); envEmbeddingURL != "" {
+		config.SemanticCacheEmbeddingURL = envEmbeddingURL
+	}
+	if envEmbeddingModel := os.Getenv("SEMANTIC_CACHE_EMBEDDING_MODEL"); envEmbeddingModel != "" {
+		config.SemanticCacheEmbeddingModel = envEmbeddingModel
+	}
+	if envEmbeddingToken := os.Getenv("SEMANTIC_CACHE_EMBEDDING_TOKEN"); envEmbeddingToken != "" {
+		config.SemanticCacheEmbeddingToken = envEmbeddingToken
+	}
+
+	if envDenylist := os.Getenv("DENYLIST"); envDenylist != "" {
+		config.Denylist = getEnvListWithDefault("DENYLIST", config.Denylist)
+	}
+	if envDenylistFeed := os.Getenv("DENYLIST_FEED_URL"); envDenylistFeed != "" {
+		config.DenylistFeedURL = envDenylistFeed
+	}
+	if envDenylistRefresh := os.Getenv("DENYLIST_REFRESH"); envDenylistRefresh != "" {
+		config.DenylistRefresh = getEnvDurationWithDefault("DENYLIST_REFRESH", config.DenylistRefresh)
+	}
+	if envSafetyMode := os.Getenv("SAFETY_MODE"); envSafetyMode != "" {
+		config.SafetyMode = envSafetyMode
+	}
+	if envSafetyKeywords := os.Getenv("SAFETY_KEYWORDS"); envSafetyKeywords != "" {
+		config.SafetyKeywords = getEnvListWithDefault("SAFETY_KEYWORDS", config.SafetyKeywords)
+	}
+	if envSafetyClassifier := os.Getenv("SAFETY_CLASSIFIER_URL"); envSafetyClassifier != "" {
+		config.SafetyClassifierURL = envSafetyClassifier
+	}
+	if envHideFooter := os.Getenv("HIDE_RESULT_FOOTER"); envHideFooter != "" {
+		config.HideResultFooter = getEnvBoolWithDefault("HIDE_RESULT_FOOTER", config.HideResultFooter)
+	}
+	if envHideImages := os.Getenv("HIDE_IMAGES"); envHideImages != "" {
+		config.HideImages = getEnvBoolWithDefault("HIDE_IMAGES", config.HideImages)
+	}
+	if envSchemaVersion := os.Getenv("STRUCTURED_SCHEMA_VERSION"); envSchemaVersion != "" {
+		config.StructuredSchemaVersion = getEnvIntWithDefault("STRUCTURED_SCHEMA_VERSION", config.StructuredSchemaVersion)
+	}
+	if envSearchCost := os.Getenv("SEARCH_COST"); envSearchCost != "" {
+		config.SearchCost = getEnvFloatWithDefault("SEARCH_COST", config.SearchCost)
+	}
+
+	if envFetchEnabled := os.Getenv("FETCH_ENABLED"); envFetchEnabled != "" {
+		config.FetchEnabled = getEnvBoolWithDefault("FETCH_ENABLED", config.FetchEnabled)
+	}
+	if envFetchTimeout := os.Getenv("FETCH_TIMEOUT"); envFetchTimeout != "" {
+		config.FetchTimeout = getEnvDurationWithDefault("FETCH_TIMEOUT", config.FetchTimeout)
+	}
+	if envFetchMaxPages := os.Getenv("FETCH_MAX_PAGES_PER_MINUTE"); envFetchMaxPages != "" {
+		config.FetchMaxPagesPerMinute = getEnvIntWithDefault("FETCH_MAX_PAGES_PER_MINUTE", config.FetchMaxPagesPerMinute)
+	}
+	if envFetchMaxBytes := os.Getenv("FETCH_MAX_BYTES_PER_MINUTE"); envFetchMaxBytes != "" {
+		config.FetchMaxBytesPerMinute = getEnvIntWithDefault("FETCH_MAX_BYTES_PER_MINUTE", config.FetchMaxBytesPerMinute)
+	}
+	if envFetchMaxPageBytes := os.Getenv("FETCH_MAX_PAGE_BYTES"); envFetchMaxPageBytes != "" {
+		config.FetchMaxPageBytes = getEnvIntWithDefault("FETCH_MAX_PAGE_BYTES", config.FetchMaxPageBytes)
+	}
+	if envFetchPageMaxChars := os.Getenv("FETCH_PAGE_MAX_CHARS"); envFetchPageMaxChars != "" {
+		config.FetchPageMaxChars = getEnvIntWithDefault("FETCH_PAGE_MAX_CHARS", config.FetchPageMaxChars)
+	}
+	if envFetchContentTypes := os.Getenv("FETCH_CONTENT_TYPES"); envFetchContentTypes != "" {
+		config.FetchContentTypes = getEnvListWithDefault("FETCH_CONTENT_TYPES", config.FetchContentTypes)
+	}
+	if envFetchAllowlist := os.Getenv("FETCH_ALLOWLIST"); envFetchAllowlist != "" {
+		config.FetchAllowlist = getEnvListWithDefault("FETCH_ALLOWLIST", config.FetchAllowlist)
+	}
+	if envFetchArchiveFallback := os.Getenv("FETCH_ARCHIVE_FALLBACK"); envFetchArchiveFallback != "" {
+		config.FetchArchiveFallback = getEnvBoolWithDefault("FETCH_ARCHIVE_FALLBACK", config.FetchArchiveFallback)
+	}
+	if envFetchArchiveURL := os.Getenv("FETCH_ARCHIVE_URL"); envFetchArchiveURL != "" {
+		config.FetchArchiveURL = envFetchArchiveURL
+	}
+	if envDownloadDir := os.Getenv("DOWNLOAD_DIR"); envDownloadDir != "" {
+		config.DownloadDir = envDownloadDir
+	}
+	if envDownloadMaxBytes := os.Getenv("DOWNLOAD_MAX_BYTES"); envDownloadMaxBytes != "" {
+		config.DownloadMaxBytes = getEnvIntWithDefault("DOWNLOAD_MAX_BYTES", config.DownloadMaxBytes)
+	}
+	if envDownloadMaxTotalBytes := os.Getenv("DOWNLOAD_MAX_TOTAL_BYTES"); envDownloadMaxTotalBytes != "" {
+		config.DownloadMaxTotalBytes = getEnvIntWithDefault("DOWNLOAD_MAX_TOTAL_BYTES", config.DownloadMaxTotalBytes)
+	}
+	if envDownloadContentTypes := os.Getenv("DOWNLOAD_CONTENT_TYPES"); envDownloadContentTypes != "" {
+		config.DownloadContentTypes = getEnvListWithDefault("DOWNLOAD_CONTENT_TYPES", config.DownloadContentTypes)
+	}
+	if envHNSearchEnabled := os.Getenv("HN_SEARCH_ENABLED"); envHNSearchEnabled != "" {
+		config.HNSearchEnabled = getEnvBoolWithDefault("HN_SEARCH_ENABLED", config.HNSearchEnabled)
+	}
+	if envHNAPIURL := os.Getenv("HN_API_URL"); envHNAPIURL != "" {
+		config.HNAPIURL = envHNAPIURL
+	}
+	if envScreenshotURL := os.Getenv("SCREENSHOT_URL"); envScreenshotURL != "" {
+		config.ScreenshotURL = envScreenshotURL
+	}
+	if envScreenshotToken := os.Getenv("SCREENSHOT_TOKEN"); envScreenshotToken != "" {
+		config.ScreenshotToken = envScreenshotToken
+	}
+	if envScreenshotTimeout := os.Getenv("SCREENSHOT_TIMEOUT"); envScreenshotTimeout != "" {
+		config.ScreenshotTimeout = getEnvDurationWithDefault("SCREENSHOT_TIMEOUT", config.ScreenshotTimeout)
+	}
+	if envScreenshotMaxBytes := os.Getenv("SCREENSHOT_MAX_BYTES"); envScreenshotMaxBytes != "" {
+		config.ScreenshotMaxBytes = getEnvIntWithDefault("SCREENSHOT_MAX_BYTES", config.ScreenshotMaxBytes)
+	}
+	if envWebhookURL := os.Getenv("WEBHOOK_URL"); envWebhookURL != "" {
+		config.WebhookURL = envWebhookURL
+	}
+	if envWebhookFormat := os.Getenv("WEBHOOK_FORMAT"); envWebhookFormat != "" {
+		config.WebhookFormat = envWebhookFormat
+	}
+	if envWebhookSecret := os.Getenv("WEBHOOK_SECRET"); envWebhookSecret != "" {
+		config.WebhookSecret = envWebhookSecret
+	}
+	if envWebhookMaxRetries := os.Getenv("WEBHOOK_MAX_RETRIES"); envWebhookMaxRetries != "" {
+		config.WebhookMaxRetries = getEnvIntWithDefault("WEBHOOK_MAX_RETRIES", config.WebhookMaxRetries)
+	}
+	if envDataDir := os.Getenv("DATA_DIR"); envDataDir != "" {
+		config.DataDir = envDataDir
+	}
+	if envDailyQuotas := os.Getenv("DAILY_QUOTAS"); envDailyQuotas != "" {
+		config.DailyQuotas = getEnvIntMapWithDefault("DAILY_QUOTAS", config.DailyQuotas)
+	}
+	if envDataKey := os.Getenv("DATA_KEY"); envDataKey != "" {
+		config.DataKey = envDataKey
+	}
+	if envDataKeyFile := os.Getenv("DATA_KEY_FILE"); envDataKeyFile != "" {
+		config.DataKeyFile = envDataKeyFile
+	}
+	if envHistoryMaxAge := os.Getenv("HISTORY_MAX_AGE"); envHistoryMaxAge != "" {
+		config.HistoryMaxAge = getEnvDurationWithDefault("HISTORY_MAX_AGE", config.HistoryMaxAge)
+	}
+	if envHistoryMaxEntries := os.Getenv("HISTORY_MAX_ENTRIES"); envHistoryMaxEntries != "" {
+		config.HistoryMaxEntries = getEnvIntWithDefault("HISTORY_MAX_ENTRIES", config.HistoryMaxEntries)
+	}
+	if envSavedMaxAge := os.Getenv("SAVED_MAX_AGE"); envSavedMaxAge != "" {
+		config.SavedMaxAge = getEnvDurationWithDefault("SAVED_MAX_AGE", config.SavedMaxAge)
+	}
+	if envSavedMaxEntries := os.Getenv("SAVED_MAX_ENTRIES"); envSavedMaxEntries != "" {
+		config.SavedMaxEntries = getEnvIntWithDefault("SAVED_MAX_ENTRIES", config.SavedMaxEntries)
+	}
+	if envPurgeOnShutdown := os.Getenv("PURGE_ON_SHUTDOWN"); envPurgeOnShutdown != "" {
+		config.PurgeOnShutdown = getEnvBoolWithDefault("PURGE_ON_SHUTDOWN", config.PurgeOnShutdown)
+	}
+	if envPurgeToolEnabled := os.Getenv("PURGE_TOOL_ENABLED"); envPurgeToolEnabled != "" {
+		config.PurgeToolEnabled = getEnvBoolWithDefault("PURGE_TOOL_ENABLED", config.PurgeToolEnabled)
+	}
+	if envIncognito := os.Getenv("INCOGNITO"); envIncognito != "" {
+		config.Incognito = getEnvBoolWithDefault("INCOGNITO", config.Incognito)
+	}
+
+	// A key file takes precedence so rotated secrets are picked up on reload
+	for _, secret := range []struct {
+		file string
+		key  *string
+	}{
+		{config.BochaAPIKeyFile, &config.BochaAPIKey},
+		{config.BraveAPIKeyFile, &config.BraveAPIKey},
+		{config.GoogleAPIKeyFile, &config.GoogleAPIKey},
+		{config.BaiduAPIKeyFile, &config.BaiduAPIKey},
+		{config.JinaAPIKeyFile, &config.JinaAPIKey},
+		{config.PubMedAPIKeyFile, &config.PubMedAPIKey},
+		{config.StackExchangeKeyFile, &config.StackExchangeKey},
+		{config.GenericAPIKeyFile, &config.GenericAPIKey},
+		{config.GRPCAPIKeyFile, &config.GRPCAPIKey},
+	} {
+		if secret.file == "" {
+			continue
+		}
+		if key, err := readSecretFile(secret.file); err != nil {
+			log.Printf("Warning: Failed to read API key file %s: %v", secret.file, err)
+		} else {
+			*secret.key = key
+		}
+	}
+
+	// Bocha operations without a URL of their own are served below the endpoint
+	if config.BochaAPIBaseURL == "" {
+		config.BochaAPIBaseURL = config.BochaURL(BochaWebSearch)
+	}
+	if config.BochaProURL == "" {
+		config.BochaProURL = config.BochaURL(BochaWebSearchPro)
+	}
+	if config.BochaAISearchURL == "" {
+		config.BochaAISearchURL = config.BochaURL(BochaAISearch)
+	}
+	if config.BochaAgentSearchURL == "" {
+		config.BochaAgentSearchURL = config.BochaURL(BochaAgentSearch)
+	}
+
+	// A Brave key, a Google key and engine ID, a Baidu key or a Jina key is
+	// enough to run the server without a Bocha key
+	if config.SearchProvider == ProviderBocha && config.BochaAPIKey == "" {
+		switch {
+		case config.BraveAPIKey != "":
+			config.SearchProvider = ProviderBrave
+		case config.GoogleAPIKey != "" && config.GoogleCX != "":
+			config.SearchProvider = ProviderGoogle
+		case config.BaiduAPIKey != "":
+			config.SearchProvider = ProviderBaidu
+		case config.JinaAPIKey != "":
+			config.SearchProvider = ProviderJina
+		}
+	}
 
 	// Validate required configuration
-	if config.BochaAPIKey == "" {
+	if config.SearchProvider == ProviderBocha && config.BochaAPIKey == "" {
 		log.Println("Warning: BOCHA_API_KEY environment variable not set. The search service will not work without an API key.")
 	}
 
@@ -132,47 +1287,1268 @@ func (c *Config) LoadFromFile(path string) error {
 	if fileConfig.BochaAPIKey != "" {
 		c.BochaAPIKey = fileConfig.BochaAPIKey
 	}
+	if fileConfig.BochaAPIKeyFile != "" {
+		c.BochaAPIKeyFile = fileConfig.BochaAPIKeyFile
+	}
 	if fileConfig.BochaAPIBaseURL != "" {
 		c.BochaAPIBaseURL = fileConfig.BochaAPIBaseURL
 	}
-	if fileConfig.HTTPTimeoutStr != "" {
-		duration, err := time.ParseDuration(fileConfig.HTTPTimeoutStr)
-		if err == nil {
-			c.HTTPTimeout = duration
-		} else {
-			log.Printf("Warning: Invalid HTTP timeout in config file: %s", fileConfig.HTTPTimeoutStr)
-		}
+	if fileConfig.BochaProURL != "" {
+		c.BochaProURL = fileConfig.BochaProURL
 	}
-	if fileConfig.ServerName != "" {
-		c.ServerName = fileConfig.ServerName
+	if fileConfig.BochaPro {
+		c.BochaPro = true
 	}
-	if fileConfig.ServerVersion != "" {
-		c.ServerVersion = fileConfig.ServerVersion
+	if fileConfig.BochaAISearchURL != "" {
+		c.BochaAISearchURL = fileConfig.BochaAISearchURL
 	}
-
-	return nil
-}
-
-// Validate performs additional validation on the configuration
-// and returns an error if the configuration is invalid
+	if fileConfig.BochaAgentSearchURL != "" {
+		c.BochaAgentSearchURL = fileConfig.BochaAgentSearchURL
+	}
+	if len(fileConfig.BochaAgents) > 0 {
+		c.BochaAgents = fileConfig.BochaAgents
+	}
+	if fileConfig.BochaEndpoint != "" {
+		c.BochaEndpoint = fileConfig.BochaEndpoint
+	}
+	if len(fileConfig.BochaPaths) > 0 {
+		c.BochaPaths = fileConfig.BochaPaths
+	}
+	if len(fileConfig.BochaRegions) > 0 {
+		c.BochaRegions = fileConfig.BochaRegions
+	}
+	if fileConfig.BraveAPIKey != "" {
+		c.BraveAPIKey = fileConfig.BraveAPIKey
+	}
+	if fileConfig.BraveAPIKeyFile != "" {
+		c.BraveAPIKeyFile = fileConfig.BraveAPIKeyFile
+	}
+	if fileConfig.BraveAPIBaseURL != "" {
+		c.BraveAPIBaseURL = fileConfig.BraveAPIBaseURL
+	}
+	if fileConfig.GoogleAPIKey != "" {
+		c.GoogleAPIKey = fileConfig.GoogleAPIKey
+	}
+	if fileConfig.GoogleAPIKeyFile != "" {
+		c.GoogleAPIKeyFile = fileConfig.GoogleAPIKeyFile
+	}
+	if fileConfig.GoogleCX != "" {
+		c.GoogleCX = fileConfig.GoogleCX
+	}
+	if fileConfig.GoogleAPIBaseURL != "" {
+		c.GoogleAPIBaseURL = fileConfig.GoogleAPIBaseURL
+	}
+	if fileConfig.BaiduAPIKey != "" {
+		c.BaiduAPIKey = fileConfig.BaiduAPIKey
+	}
+	if fileConfig.BaiduAPIKeyFile != "" {
+		c.BaiduAPIKeyFile = fileConfig.BaiduAPIKeyFile
+	}
+	if fileConfig.BaiduAPIBaseURL != "" {
+		c.BaiduAPIBaseURL = fileConfig.BaiduAPIBaseURL
+	}
+	if fileConfig.JinaAPIKey != "" {
+		c.JinaAPIKey = fileConfig.JinaAPIKey
+	}
+	if fileConfig.JinaAPIKeyFile != "" {
+		c.JinaAPIKeyFile = fileConfig.JinaAPIKeyFile
+	}
+	if fileConfig.JinaSearchURL != "" {
+		c.JinaSearchURL = fileConfig.JinaSearchURL
+	}
+	if fileConfig.JinaReaderURL != "" {
+		c.JinaReaderURL = fileConfig.JinaReaderURL
+	}
+	if fileConfig.ArxivAPIURL != "" {
+		c.ArxivAPIURL = fileConfig.ArxivAPIURL
+	}
+	if fileConfig.PubMedAPIKey != "" {
+		c.PubMedAPIKey = fileConfig.PubMedAPIKey
+	}
+	if fileConfig.PubMedAPIKeyFile != "" {
+		c.PubMedAPIKeyFile = fileConfig.PubMedAPIKeyFile
+	}
+	if fileConfig.PubMedAPIBaseURL != "" {
+		c.PubMedAPIBaseURL = fileConfig.PubMedAPIBaseURL
+	}
+	if fileConfig.StackExchangeKey != "" {
+		c.StackExchangeKey = fileConfig.StackExchangeKey
+	}
+	if fileConfig.StackExchangeKeyFile != "" {
+		c.StackExchangeKeyFile = fileConfig.StackExchangeKeyFile
+	}
+	if fileConfig.StackExchangeSite != "" {
+		c.StackExchangeSite = fileConfig.StackExchangeSite
+	}
+	if fileConfig.StackExchangeAPIURL != "" {
+		c.StackExchangeAPIURL = fileConfig.StackExchangeAPIURL
+	}
+	if fileConfig.GenericAPIURL != "" {
+		c.GenericAPIURL = fileConfig.GenericAPIURL
+	}
+	if fileConfig.GenericMethod != "" {
+		c.GenericMethod = fileConfig.GenericMethod
+	}
+	if fileConfig.GenericAPIKey != "" {
+		c.GenericAPIKey = fileConfig.GenericAPIKey
+	}
+	if fileConfig.GenericAPIKeyFile != "" {
+		c.GenericAPIKeyFile = fileConfig.GenericAPIKeyFile
+	}
+	if fileConfig.GenericAuthHeader != "" {
+		c.GenericAuthHeader = fileConfig.GenericAuthHeader
+	}
+	if fileConfig.GenericAuthParam != "" {
+		c.GenericAuthParam = fileConfig.GenericAuthParam
+	}
+	if fileConfig.GenericQueryParam != "" {
+		c.GenericQueryParam = fileConfig.GenericQueryParam
+	}
+	if fileConfig.GenericCountParam != "" {
+		c.GenericCountParam = fileConfig.GenericCountParam
+	}
+	c.GenericMapping.merge(fileConfig.GenericMapping)
+	if fileConfig.GenericOpenAPISpec != "" {
+		c.GenericOpenAPISpec = fileConfig.GenericOpenAPISpec
+	}
+	if fileConfig.GenericOpenAPIOperation != "" {
+		c.GenericOpenAPIOperation = fileConfig.GenericOpenAPIOperation
+	}
+	if fileConfig.CommandPath != "" {
+		c.CommandPath = fileConfig.CommandPath
+	}
+	if len(fileConfig.CommandArgs) > 0 {
+		c.CommandArgs = fileConfig.CommandArgs
+	}
+	if len(fileConfig.CommandEnv) > 0 {
+		c.CommandEnv = fileConfig.CommandEnv
+	}
+	if fileConfig.CommandDir != "" {
+		c.CommandDir = fileConfig.CommandDir
+	}
+	if fileConfig.CommandTimeoutStr != "" {
+		duration, err := time.ParseDuration(fileConfig.CommandTimeoutStr)
+		if err == nil {
+			c.CommandTimeout = duration
+		} else {
+			log.Printf("Warning: Invalid command timeout in config file: %s", fileConfig.CommandTimeoutStr)
+		}
+	}
+	if fileConfig.CommandMaxOutput > 0 {
+		c.CommandMaxOutput = fileConfig.CommandMaxOutput
+	}
+	if fileConfig.CommandMaxProcs > 0 {
+		c.CommandMaxProcs = fileConfig.CommandMaxProcs
+	}
+	c.CommandMapping.merge(fileConfig.CommandMapping)
+	if fileConfig.GRPCTarget != "" {
+		c.GRPCTarget = fileConfig.GRPCTarget
+	}
+	if fileConfig.GRPCAPIKey != "" {
+		c.GRPCAPIKey = fileConfig.GRPCAPIKey
+	}
+	if fileConfig.GRPCAPIKeyFile != "" {
+		c.GRPCAPIKeyFile = fileConfig.GRPCAPIKeyFile
+	}
+	if len(fileConfig.GRPCMetadata) > 0 {
+		c.GRPCMetadata = fileConfig.GRPCMetadata
+	}
+	if fileConfig.GRPCCAFile != "" {
+		c.GRPCCAFile = fileConfig.GRPCCAFile
+	}
+	if fileConfig.GRPCCertFile != "" {
+		c.GRPCCertFile = fileConfig.GRPCCertFile
+	}
+	if fileConfig.GRPCKeyFile != "" {
+		c.GRPCKeyFile = fileConfig.GRPCKeyFile
+	}
+	if fileConfig.GRPCServerName != "" {
+		c.GRPCServerName = fileConfig.GRPCServerName
+	}
+	if fileConfig.GRPCPlaintext {
+		c.GRPCPlaintext = true
+	}
+	if len(fileConfig.UpstreamAllowlist) > 0 {
+		c.UpstreamAllowlist = fileConfig.UpstreamAllowlist
+	}
+	if fileConfig.AllowInsecureHTTP {
+		c.AllowInsecureHTTP = true
+	}
+	if fileConfig.TLSMinVersion != "" {
+		c.TLSMinVersion = fileConfig.TLSMinVersion
+	}
+	if fileConfig.DialIPFamily != "" {
+		c.DialIPFamily = fileConfig.DialIPFamily
+	}
+	if fileConfig.DialLocalAddress != "" {
+		c.DialLocalAddress = fileConfig.DialLocalAddress
+	}
+	if len(fileConfig.RequestTemplates) > 0 {
+		c.RequestTemplates = fileConfig.RequestTemplates
+	}
+	if len(fileConfig.TLSCipherSuites) > 0 {
+		c.TLSCipherSuites = fileConfig.TLSCipherSuites
+	}
+	if fileConfig.HTTPTimeoutStr != "" {
+		duration, err := time.ParseDuration(fileConfig.HTTPTimeoutStr)
+		if err == nil {
+			c.HTTPTimeout = duration
+		} else {
+			log.Printf("Warning: Invalid HTTP timeout in config file: %s", fileConfig.HTTPTimeoutStr)
+		}
+	}
+	if fileConfig.WorkerPoolSize > 0 {
+		c.WorkerPoolSize = fileConfig.WorkerPoolSize
+	}
+	if fileConfig.JobTimeoutStr != "" {
+		duration, err := time.ParseDuration(fileConfig.JobTimeoutStr)
+		if err == nil {
+			c.JobTimeout = duration
+		} else {
+			log.Printf("Warning: Invalid job timeout in config file: %s", fileConfig.JobTimeoutStr)
+		}
+	}
+	if fileConfig.WarmStart {
+		c.WarmStart = true
+	}
+	if fileConfig.WarmStartIntervalStr != "" {
+		duration, err := time.ParseDuration(fileConfig.WarmStartIntervalStr)
+		if err == nil {
+			c.WarmStartInterval = duration
+		} else {
+			log.Printf("Warning: Invalid warm start interval in config file: %s", fileConfig.WarmStartIntervalStr)
+		}
+	}
+	if fileConfig.ServerName != "" {
+		c.ServerName = fileConfig.ServerName
+	}
+	if fileConfig.ServerVersion != "" {
+		c.ServerVersion = fileConfig.ServerVersion
+	}
+	if fileConfig.SearchProvider != "" {
+		c.SearchProvider = fileConfig.SearchProvider
+	}
+	if fileConfig.PluginCommand != "" {
+		c.PluginCommand = fileConfig.PluginCommand
+	}
+	if len(fileConfig.ProviderOverrides) > 0 {
+		c.ProviderOverrides = fileConfig.ProviderOverrides
+	}
+	if len(fileConfig.AggregateProviders) > 0 {
+		c.AggregateProviders = fileConfig.AggregateProviders
+	}
+	if fileConfig.AggregateAutoWeight {
+		c.AggregateAutoWeight = true
+	}
+	if fileConfig.AggregateMerge != "" {
+		c.AggregateMerge = fileConfig.AggregateMerge
+	}
+	if fileConfig.ProviderTimeoutStr != "" {
+		duration, err := time.ParseDuration(fileConfig.ProviderTimeoutStr)
+		if err == nil {
+			c.ProviderTimeout = duration
+		} else {
+			log.Printf("Warning: Invalid provider timeout in config file: %s", fileConfig.ProviderTimeoutStr)
+		}
+	}
+	if len(fileConfig.PluginArgs) > 0 {
+		c.PluginArgs = fileConfig.PluginArgs
+	}
+	if len(fileConfig.RewriteRules) > 0 {
+		c.RewriteRules = fileConfig.RewriteRules
+	}
+	if len(fileConfig.BoostRules) > 0 {
+		c.BoostRules = fileConfig.BoostRules
+	}
+	if len(fileConfig.ResultPipeline) > 0 {
+		c.ResultPipeline = fileConfig.ResultPipeline
+	}
+	if len(fileConfig.ToolFreshness) > 0 {
+		c.ToolFreshness = fileConfig.ToolFreshness
+	}
+	if len(fileConfig.SearchPresets) > 0 {
+		c.SearchPresets = fileConfig.SearchPresets
+	}
+	if len(fileConfig.ToolProfiles) > 0 {
+		c.ToolProfiles = fileConfig.ToolProfiles
+	}
+	if len(fileConfig.ClientProfiles) > 0 {
+		c.ClientProfiles = fileConfig.ClientProfiles
+	}
+	if fileConfig.DefaultToolProfile != "" {
+		c.DefaultToolProfile = fileConfig.DefaultToolProfile
+	}
+	if fileConfig.StartupCheck != "" {
+		c.StartupCheck = fileConfig.StartupCheck
+	}
+	if fileConfig.LogLevel != "" {
+		c.LogLevel = fileConfig.LogLevel
+	}
+	if fileConfig.Timezone != "" {
+		c.Timezone = fileConfig.Timezone
+	}
+	if fileConfig.AdminAddr != "" {
+		c.AdminAddr = fileConfig.AdminAddr
+	}
+	if fileConfig.AdminToken != "" {
+		c.AdminToken = fileConfig.AdminToken
+	}
+	if fileConfig.SSEAddr != "" {
+		c.SSEAddr = fileConfig.SSEAddr
+	}
+	if fileConfig.SSEToken != "" {
+		c.SSEToken = fileConfig.SSEToken
+	}
+	if fileConfig.QueryLogPolicy != "" {
+		c.QueryLogPolicy = fileConfig.QueryLogPolicy
+	}
+	if len(fileConfig.PIIScrub) > 0 {
+		c.PIIScrub = fileConfig.PIIScrub
+	}
+	if len(fileConfig.PIIPatterns) > 0 {
+		c.PIIPatterns = fileConfig.PIIPatterns
+	}
+	if fileConfig.CacheTTLStr != "" {
+		duration, err := time.ParseDuration(fileConfig.CacheTTLStr)
+		if err == nil {
+			c.CacheTTL = duration
+		} else {
+			log.Printf("Warning: Invalid cache TTL in config file: %s", fileConfig.CacheTTLStr)
+		}
+	}
+	if fileConfig.CacheMaxEntries > 0 {
+		c.CacheMaxEntries = fileConfig.CacheMaxEntries
+	}
+	if fileConfig.SemanticCacheThreshold > 0 {
+		c.SemanticCacheThreshold = fileConfig.SemanticCacheThreshold
+	}
+	if fileConfig.SemanticCacheTTLStr != "" {
+		duration, err := time.ParseDuration(fileConfig.SemanticCacheTTLStr)
+		if err == nil {
+			c.SemanticCacheTTL = duration
+		} else {
+			log.Printf("Warning: Invalid semantic cache TTL in config file: %s", fileConfig.SemanticCacheTTLStr)
+		}
+	}
+	if fileConfig.SemanticCacheMaxEntries > 0 {
+		c.SemanticCacheMaxEntries = fileConfig.SemanticCacheMaxEntries
+	}
+	if fileConfig.SemanticCacheEmbeddingURL != "" {
+		c.SemanticCacheEmbeddingURL = fileConfig.SemanticCacheEmbeddingURL
+	}
+	if fileConfig.SemanticCacheEmbeddingModel != "" {
+		c.SemanticCacheEmbeddingModel = fileConfig.SemanticCacheEmbeddingModel
+	}
+	if fileConfig.SemanticCacheEmbeddingToken != "" {
+		c.SemanticCacheEmbeddingToken = fileConfig.SemanticCacheEmbeddingToken
+	}
+	if len(fileConfig.Denylist) > 0 {
+		c.Denylist = fileConfig.Denylist
+	}
+	if fileConfig.DenylistFeedURL != "" {
+		c.DenylistFeedURL = fileConfig.DenylistFeedURL
+	}
+	if fileConfig.DenylistRefreshStr != "" {
+		duration, err := time.ParseDuration(fileConfig.DenylistRefreshStr)
+		if err == nil {
+			c.DenylistRefresh = duration
+		} else {
+			log.Printf("Warning: Invalid denylist refresh interval in config file: %s", fileConfig.DenylistRefreshStr)
+		}
+	}
+	if fileConfig.SafetyMode != "" {
+		c.SafetyMode = fileConfig.SafetyMode
+	}
+	if len(fileConfig.SafetyKeywords) > 0 {
+		c.SafetyKeywords = fileConfig.SafetyKeywords
+	}
+	if fileConfig.SafetyClassifierURL != "" {
+		c.SafetyClassifierURL = fileConfig.SafetyClassifierURL
+	}
+	if fileConfig.HideResultFooter {
+		c.HideResultFooter = true
+	}
+	if fileConfig.HideImages {
+		c.HideImages = true
+	}
+	if fileConfig.StructuredSchemaVersion > 0 {
+		c.StructuredSchemaVersion = fileConfig.StructuredSchemaVersion
+	}
+	if fileConfig.SearchCost > 0 {
+		c.SearchCost = fileConfig.SearchCost
+	}
+	if fileConfig.FetchEnabled {
+		c.FetchEnabled = true
+	}
+	if fileConfig.FetchTimeoutStr != "" {
+		duration, err := time.ParseDuration(fileConfig.FetchTimeoutStr)
+		if err == nil {
+			c.FetchTimeout = duration
+		} else {
+			log.Printf("Warning: Invalid fetch timeout in config file: %s", fileConfig.FetchTimeoutStr)
+		}
+	}
+	if fileConfig.FetchMaxPagesPerMinute > 0 {
+		c.FetchMaxPagesPerMinute = fileConfig.FetchMaxPagesPerMinute
+	}
+	if fileConfig.FetchMaxBytesPerMinute > 0 {
+		c.FetchMaxBytesPerMinute = fileConfig.FetchMaxBytesPerMinute
+	}
+	if fileConfig.FetchMaxPageBytes > 0 {
+		c.FetchMaxPageBytes = fileConfig.FetchMaxPageBytes
+	}
+	if fileConfig.FetchPageMaxChars > 0 {
+		c.FetchPageMaxChars = fileConfig.FetchPageMaxChars
+	}
+	if len(fileConfig.FetchContentTypes) > 0 {
+		c.FetchContentTypes = fileConfig.FetchContentTypes
+	}
+	if len(fileConfig.FetchAllowlist) > 0 {
+		c.FetchAllowlist = fileConfig.FetchAllowlist
+	}
+	if fileConfig.FetchArchiveFallback {
+		c.FetchArchiveFallback = true
+	}
+	if fileConfig.FetchArchiveURL != "" {
+		c.FetchArchiveURL = fileConfig.FetchArchiveURL
+	}
+	if fileConfig.DownloadDir != "" {
+		c.DownloadDir = fileConfig.DownloadDir
+	}
+	if fileConfig.DownloadMaxBytes > 0 {
+		c.DownloadMaxBytes = fileConfig.DownloadMaxBytes
+	}
+	if fileConfig.DownloadMaxTotalBytes > 0 {
+		c.DownloadMaxTotalBytes = fileConfig.DownloadMaxTotalBytes
+	}
+	if len(fileConfig.DownloadContentTypes) > 0 {
+		c.DownloadContentTypes = fileConfig.DownloadContentTypes
+	}
+	if fileConfig.HNSearchEnabled {
+		c.HNSearchEnabled = true
+	}
+	if fileConfig.HNAPIURL != "" {
+		c.HNAPIURL = fileConfig.HNAPIURL
+	}
+	if fileConfig.ScreenshotURL != "" {
+		c.ScreenshotURL = fileConfig.ScreenshotURL
+	}
+	if fileConfig.ScreenshotToken != "" {
+		c.ScreenshotToken = fileConfig.ScreenshotToken
+	}
+	if fileConfig.ScreenshotTimeoutStr != "" {
+		duration, err := time.ParseDuration(fileConfig.ScreenshotTimeoutStr)
+		if err == nil {
+			c.ScreenshotTimeout = duration
+		} else {
+			log.Printf("Warning: Invalid screenshot timeout in config file: %s", fileConfig.ScreenshotTimeoutStr)
+		}
+	}
+	if fileConfig.ScreenshotMaxBytes > 0 {
+		c.ScreenshotMaxBytes = fileConfig.ScreenshotMaxBytes
+	}
+	if len(fileConfig.Monitors) > 0 {
+		c.Monitors = fileConfig.Monitors
+	}
+	if fileConfig.WebhookURL != "" {
+		c.WebhookURL = fileConfig.WebhookURL
+	}
+	if fileConfig.WebhookFormat != "" {
+		c.WebhookFormat = fileConfig.WebhookFormat
+	}
+	if fileConfig.WebhookSecret != "" {
+		c.WebhookSecret = fileConfig.WebhookSecret
+	}
+	if fileConfig.WebhookMaxRetries > 0 {
+		c.WebhookMaxRetries = fileConfig.WebhookMaxRetries
+	}
+	if fileConfig.DataDir != "" {
+		c.DataDir = fileConfig.DataDir
+	}
+	if len(fileConfig.DailyQuotas) > 0 {
+		c.DailyQuotas = fileConfig.DailyQuotas
+	}
+	if fileConfig.DataKey != "" {
+		c.DataKey = fileConfig.DataKey
+	}
+	if fileConfig.DataKeyFile != "" {
+		c.DataKeyFile = fileConfig.DataKeyFile
+	}
+	if fileConfig.HistoryMaxAgeStr != "" {
+		duration, err := time.ParseDuration(fileConfig.HistoryMaxAgeStr)
+		if err == nil {
+			c.HistoryMaxAge = duration
+		} else {
+			log.Printf("Warning: Invalid history max age in config file: %s", fileConfig.HistoryMaxAgeStr)
+		}
+	}
+	if fileConfig.HistoryMaxEntries > 0 {
+		c.HistoryMaxEntries = fileConfig.HistoryMaxEntries
+	}
+	if fileConfig.SavedMaxAgeStr != "" {
+		duration, err := time.ParseDuration(fileConfig.SavedMaxAgeStr)
+		if err == nil {
+			c.SavedMaxAge = duration
+		} else {
+			log.Printf("Warning: Invalid saved results max age in config file: %s", fileConfig.SavedMaxAgeStr)
+		}
+	}
+	if fileConfig.SavedMaxEntries > 0 {
+		c.SavedMaxEntries = fileConfig.SavedMaxEntries
+	}
+	if fileConfig.PurgeOnShutdown {
+		c.PurgeOnShutdown = true
+	}
+	if fileConfig.PurgeToolEnabled {
+		c.PurgeToolEnabled = true
+	}
+	if fileConfig.Incognito {
+		c.Incognito = true
+	}
+
+	return nil
+}
+
+// Validate performs additional validation on the configuration
+// and returns an error if the configuration is invalid
 func (c *Config) Validate() error {
-	if c.BochaAPIKey == "" {
-		return fmt.Errorf("BOCHA_API_KEY environment variable is required")
+	if c.loadErr != nil {
+		return c.loadErr
+	}
+
+	for i, rule := range c.RewriteRules {
+		if _, err := regexp.Compile(rule.Pattern); err != nil {
+			return fmt.Errorf("invalid rewrite rule %d pattern %q: %w", i, rule.Pattern, err)
+		}
+	}
+
+	for i, rule := range c.BoostRules {
+		if _, err := regexp.Compile(rule.Pattern); err != nil {
+			return fmt.Errorf("invalid boost rule %d pattern %q: %w", i, rule.Pattern, err)
+		}
+	}
+
+	stages := make(map[string]bool)
+	for _, stage := range c.ResultPipeline {
+		switch stage {
+		case StageDedupe, StageFilter, StageRerank, StageTruncate, StageFormat:
+		case StageNone:
+			if len(c.ResultPipeline) > 1 {
+				return fmt.Errorf("RESULT_PIPELINE stage %q cannot be combined with other stages", StageNone)
+			}
+		default:
+			return fmt.Errorf("unknown RESULT_PIPELINE stage %q, must be one of: %s", stage, strings.Join(DefaultResultPipeline, ", "))
+		}
+		if stages[stage] {
+			return fmt.Errorf("RESULT_PIPELINE stage %q is listed more than once", stage)
+		}
+		stages[stage] = true
+	}
+
+	switch c.LogLevel {
+	case "", "debug", "info", "error":
+	default:
+		return fmt.Errorf("invalid LOG_LEVEL %q, must be one of: debug, info, error", c.LogLevel)
 	}
 
-	if c.BochaAPIBaseURL == "" {
-		return fmt.Errorf("BOCHA_API_BASE_URL cannot be empty")
+	if _, err := c.Location(); err != nil {
+		return err
+	}
+
+	switch c.StartupCheck {
+	case "", StartupCheckOff, StartupCheckFail, StartupCheckGate:
+	default:
+		return fmt.Errorf("invalid STARTUP_CHECK %q, must be one of: off, fail, gate", c.StartupCheck)
+	}
+
+	switch c.QueryLogPolicy {
+	case "", "full", "redact", "hash", "hide":
+	default:
+		return fmt.Errorf("invalid QUERY_LOG_POLICY %q, must be one of: full, redact, hash, hide", c.QueryLogPolicy)
+	}
+	if _, err := privacy.NewScrubber(c.PIIScrub, c.PIIPatterns); err != nil {
+		return err
+	}
+
+	names := make(map[string]bool)
+	for i, monitor := range c.Monitors {
+		if monitor.Name == "" || strings.TrimSpace(monitor.Query) == "" {
+			return fmt.Errorf("monitor %d must have a name and a query", i)
+		}
+		if names[monitor.Name] {
+			return fmt.Errorf("duplicate monitor name %q", monitor.Name)
+		}
+		names[monitor.Name] = true
+		if _, err := monitor.Every(); err != nil {
+			return fmt.Errorf("invalid monitor %q interval %q: %w", monitor.Name, monitor.Interval, err)
+		}
+	}
+
+	switch c.WebhookFormat {
+	case "", WebhookFormatGeneric, WebhookFormatSlack:
+	default:
+		return fmt.Errorf("invalid WEBHOOK_FORMAT %q, must be one of: generic, slack", c.WebhookFormat)
+	}
+	if c.WebhookURL != "" {
+		if err := CheckUpstreamURL(c.WebhookURL, c.UpstreamAllowlist, c.AllowInsecureHTTP); err != nil {
+			return fmt.Errorf("invalid WEBHOOK_URL: %w", err)
+		}
+	}
+
+	if c.SemanticCacheThreshold < 0 || c.SemanticCacheThreshold > 1 {
+		return fmt.Errorf("invalid SEMANTIC_CACHE_THRESHOLD %v, must be between 0 and 1", c.SemanticCacheThreshold)
+	}
+	if c.SemanticCacheEmbeddingURL != "" {
+		if err := CheckUpstreamURL(c.SemanticCacheEmbeddingURL, c.UpstreamAllowlist, c.AllowInsecureHTTP); err != nil {
+			return fmt.Errorf("invalid SEMANTIC_CACHE_EMBEDDING_URL: %w", err)
+		}
+	} else if c.SemanticCacheThreshold > 0 && c.SemanticCacheThreshold < MinHashSemanticCacheThreshold {
+		// Lexical similarity can't tell "python 3.11" from "python 3.12"
+		return fmt.Errorf("SEMANTIC_CACHE_THRESHOLD %v is too loose for the built-in embedder, must be at least %v or use SEMANTIC_CACHE_EMBEDDING_URL",
+			c.SemanticCacheThreshold, MinHashSemanticCacheThreshold)
+	}
+
+	if c.DenylistFeedURL != "" {
+		if err := CheckUpstreamURL(c.DenylistFeedURL, c.UpstreamAllowlist, c.AllowInsecureHTTP); err != nil {
+			return fmt.Errorf("invalid DENYLIST_FEED_URL: %w", err)
+		}
+		if c.DenylistRefresh < MinDenylistRefresh {
+			return fmt.Errorf("invalid DENYLIST_REFRESH %s, must be at least %s", c.DenylistRefresh, MinDenylistRefresh)
+		}
+	}
+
+	switch c.SafetyMode {
+	case "", SafetyOff, SafetyFlag, SafetyRemove:
+	default:
+		return fmt.Errorf("invalid SAFETY_MODE %q, must be one of: off, flag, remove", c.SafetyMode)
+	}
+	if c.SafetyClassifierURL != "" {
+		if err := CheckUpstreamURL(c.SafetyClassifierURL, c.UpstreamAllowlist, c.AllowInsecureHTTP); err != nil {
+			return fmt.Errorf("invalid SAFETY_CLASSIFIER_URL: %w", err)
+		}
+	}
+
+	if c.SearchCost < 0 {
+		return fmt.Errorf("invalid SEARCH_COST %v, must not be negative", c.SearchCost)
+	}
+
+	if c.FetchEnabled && (c.FetchMaxPagesPerMinute < 1 || c.FetchMaxBytesPerMinute < 1 || c.FetchMaxPageBytes < 1 || c.FetchPageMaxChars < 1) {
+		return fmt.Errorf("FETCH_MAX_PAGES_PER_MINUTE, FETCH_MAX_BYTES_PER_MINUTE, FETCH_MAX_PAGE_BYTES and FETCH_PAGE_MAX_CHARS must be positive when fetching is enabled")
+	}
+	for _, entry := range c.FetchAllowlist {
+		if _, err := netip.ParsePrefix(entry); err == nil {
+			continue
+		}
+		if _, err := netip.ParseAddr(entry); err != nil {
+			return fmt.Errorf("invalid FETCH_ALLOWLIST entry %q, must be an IP address or CIDR range", entry)
+		}
+	}
+	if c.FetchArchiveFallback && !strings.HasPrefix(c.FetchArchiveURL, "https://") && !strings.HasPrefix(c.FetchArchiveURL, "http://") {
+		return fmt.Errorf("invalid FETCH_ARCHIVE_URL %q, must be an http or https URL", c.FetchArchiveURL)
+	}
+	if c.DownloadDir != "" {
+		if !c.FetchEnabled {
+			return fmt.Errorf("DOWNLOAD_DIR needs FETCH_ENABLED, whose budget and address checks downloads go through")
+		}
+		if !filepath.IsAbs(c.DownloadDir) {
+			return fmt.Errorf("invalid DOWNLOAD_DIR %q, must be an absolute path", c.DownloadDir)
+		}
+		if c.DownloadMaxBytes < 1 || c.DownloadMaxTotalBytes < c.DownloadMaxBytes {
+			return fmt.Errorf("DOWNLOAD_MAX_BYTES must be positive and no larger than DOWNLOAD_MAX_TOTAL_BYTES")
+		}
+	}
+
+	if c.HNSearchEnabled {
+		if err := CheckUpstreamURL(c.HNAPIURL, c.UpstreamAllowlist, c.AllowInsecureHTTP); err != nil {
+			return fmt.Errorf("invalid HN_API_URL: %w", err)
+		}
+	}
+	if c.ScreenshotURL != "" {
+		if err := CheckUpstreamURL(c.ScreenshotURL, c.UpstreamAllowlist, c.AllowInsecureHTTP); err != nil {
+			return fmt.Errorf("invalid SCREENSHOT_URL: %w", err)
+		}
+		if c.ScreenshotTimeout <= 0 || c.ScreenshotMaxBytes < 1 {
+			return fmt.Errorf("SCREENSHOT_TIMEOUT and SCREENSHOT_MAX_BYTES must be positive")
+		}
+	}
+
+	for provider, quota := range c.DailyQuotas {
+		if quota < 1 {
+			return fmt.Errorf("DAILY_QUOTAS for provider %s must be positive, got %d", provider, quota)
+		}
+	}
+
+	for tool, freshness := range c.ToolFreshness {
+		if !isFreshness(freshness) {
+			return fmt.Errorf("invalid TOOL_FRESHNESS %q for tool %s, must be one of: %s", freshness, tool, strings.Join(params.Freshness, ", "))
+		}
+	}
+
+	for name, preset := range c.SearchPresets {
+		if strings.TrimSpace(name) == "" {
+			return fmt.Errorf("search preset names must not be empty")
+		}
+		if preset.Freshness != "" && !isFreshness(preset.Freshness) {
+			return fmt.Errorf("invalid freshness %q in search preset %q, must be one of: %s", preset.Freshness, name, strings.Join(params.Freshness, ", "))
+		}
+		if preset.Count < 0 || preset.Count > params.MaxCount {
+			return fmt.Errorf("invalid count %d in search preset %q, must be between 1 and %d", preset.Count, name, params.MaxCount)
+		}
+		switch preset.Format {
+		case "", "text", "table":
+		default:
+			return fmt.Errorf("invalid format %q in search preset %q, must be one of: text, table", preset.Format, name)
+		}
+	}
+
+	if _, err := c.TLSConfig(); err != nil {
+		return err
+	}
+	if c.TLSMinVersion == TLSVersion13 && len(c.TLSCipherSuites) > 0 {
+		return fmt.Errorf("TLS_CIPHER_SUITES has no effect with TLS_MIN_VERSION %s, whose cipher suites are fixed", TLSVersion13)
+	}
+	if _, err := c.DialContext(); err != nil {
+		return err
+	}
+	if err := c.validateRequestTemplates(); err != nil {
+		return err
+	}
+
+	if c.WorkerPoolSize < 0 || c.JobTimeout < 0 {
+		return fmt.Errorf("WORKER_POOL_SIZE and JOB_TIMEOUT must not be negative")
+	}
+	if c.WarmStartInterval < 0 {
+		return fmt.Errorf("WARM_START_INTERVAL must not be negative")
+	}
+
+	if c.DataKey != "" && c.DataKeyFile != "" {
+		return fmt.Errorf("set only one of DATA_KEY and DATA_KEY_FILE")
+	}
+	if _, err := c.EncryptionKey(); err != nil {
+		return err
+	}
+
+	if c.HistoryMaxAge < 0 || c.SavedMaxAge < 0 {
+		return fmt.Errorf("HISTORY_MAX_AGE and SAVED_MAX_AGE must not be negative")
+	}
+	if c.HistoryMaxEntries < 0 || c.SavedMaxEntries < 0 {
+		return fmt.Errorf("HISTORY_MAX_ENTRIES and SAVED_MAX_ENTRIES must not be negative")
+	}
+
+	if c.StructuredSchemaVersion < 0 || c.StructuredSchemaVersion > 2 {
+		return fmt.Errorf("STRUCTURED_SCHEMA_VERSION must be 1 or 2, got %d", c.StructuredSchemaVersion)
+	}
+
+	if c.AdminAddr != "" && len(c.AdminToken) < 16 {
+		return fmt.Errorf("ADMIN_TOKEN of at least 16 characters is required when ADMIN_ADDR is set")
+	}
+	if c.SSEAddr != "" && len(c.SSEToken) < 16 {
+		return fmt.Errorf("SSE_TOKEN of at least 16 characters is required when SSE_ADDR is set")
+	}
+
+	if c.DefaultToolProfile != "" {
+		if _, ok := c.ToolProfiles[c.DefaultToolProfile]; !ok {
+			return fmt.Errorf("default tool profile %q is not defined in tool_profiles", c.DefaultToolProfile)
+		}
+	}
+	for token, profile := range c.ClientProfiles {
+		if _, ok := c.ToolProfiles[profile]; !ok {
+			return fmt.Errorf("client profile %q is not defined in tool_profiles (client %s)", profile, maskSecret(token))
+		}
+	}
+
+	if err := c.validateProvider(c.SearchProvider); err != nil {
+		return err
+	}
+	for _, name := range c.ProviderOverrides {
+		if err := c.validateProvider(name); err != nil {
+			return fmt.Errorf("invalid provider override %q: %w", name, err)
+		}
+	}
+	for _, name := range c.AggregateProviders {
+		if err := c.validateProvider(name); err != nil {
+			return fmt.Errorf("invalid aggregate provider %q: %w", name, err)
+		}
+	}
+	if c.ProviderTimeout < 0 {
+		return fmt.Errorf("PROVIDER_TIMEOUT must not be negative")
+	}
+	switch c.AggregateMerge {
+	case "", AggregateMergeInterleave, AggregateMergeRRF:
+	default:
+		return fmt.Errorf("invalid AGGREGATE_MERGE %q, must be one of: interleave, rrf", c.AggregateMerge)
 	}
 
 	// Log a masked version of the API key for debugging
-	if len(c.BochaAPIKey) > 8 {
-		maskedKey := c.BochaAPIKey[:4] + "..." + c.BochaAPIKey[len(c.BochaAPIKey)-4:]
-		log.Printf("Using Bocha API key: %s", maskedKey)
+	if (c.SearchProvider == "" || c.SearchProvider == ProviderBocha) && len(c.BochaAPIKey) > 8 {
+		log.Printf("Using Bocha API key: %s", maskSecret(c.BochaAPIKey))
 	}
 
 	return nil
 }
 
+// validateProvider checks the settings a provider needs, either the
+// configured SEARCH_PROVIDER or one of the provider overrides
+func (c *Config) validateProvider(name string) error {
+	switch name {
+	case "", ProviderBocha:
+		if c.BochaAPIKey == "" {
+			return fmt.Errorf("BOCHA_API_KEY environment variable is required")
+		}
+
+		if c.BochaAPIBaseURL == "" {
+			return fmt.Errorf("BOCHA_API_BASE_URL cannot be empty")
+		}
+		if err := c.validateBochaPaths(); err != nil {
+			return err
+		}
+		if err := c.validateBochaRegions(); err != nil {
+			return err
+		}
+		if err := CheckUpstreamURL(c.BochaAPIBaseURL, c.UpstreamAllowlist, c.AllowInsecureHTTP); err != nil {
+			return fmt.Errorf("invalid BOCHA_API_BASE_URL: %w", err)
+		}
+		if c.BochaProURL != "" {
+			if err := CheckUpstreamURL(c.BochaProURL, c.UpstreamAllowlist, c.AllowInsecureHTTP); err != nil {
+				return fmt.Errorf("invalid BOCHA_PRO_URL: %w", err)
+			}
+		}
+		if c.BochaAISearchURL != "" {
+			if err := CheckUpstreamURL(c.BochaAISearchURL, c.UpstreamAllowlist, c.AllowInsecureHTTP); err != nil {
+				return fmt.Errorf("invalid BOCHA_AI_SEARCH_URL: %w", err)
+			}
+		}
+		if c.BochaAgentSearchURL != "" {
+			if err := CheckUpstreamURL(c.BochaAgentSearchURL, c.UpstreamAllowlist, c.AllowInsecureHTTP); err != nil {
+				return fmt.Errorf("invalid BOCHA_AGENT_SEARCH_URL: %w", err)
+			}
+		}
+		if err := c.validateBochaAgents(); err != nil {
+			return err
+		}
+		return nil
+	case ProviderBrave:
+		if c.BraveAPIKey == "" {
+			return fmt.Errorf("BRAVE_API_KEY is required when SEARCH_PROVIDER is %q", ProviderBrave)
+		}
+		if err := CheckUpstreamURL(c.BraveAPIBaseURL, c.UpstreamAllowlist, c.AllowInsecureHTTP); err != nil {
+			return fmt.Errorf("invalid BRAVE_API_BASE_URL: %w", err)
+		}
+		return nil
+	case ProviderGoogle:
+		if c.GoogleAPIKey == "" || c.GoogleCX == "" {
+			return fmt.Errorf("GOOGLE_API_KEY and GOOGLE_CX are required when SEARCH_PROVIDER is %q", ProviderGoogle)
+		}
+		if err := CheckUpstreamURL(c.GoogleAPIBaseURL, c.UpstreamAllowlist, c.AllowInsecureHTTP); err != nil {
+			return fmt.Errorf("invalid GOOGLE_API_BASE_URL: %w", err)
+		}
+		return nil
+	case ProviderBaidu:
+		if c.BaiduAPIKey == "" {
+			return fmt.Errorf("BAIDU_API_KEY is required when SEARCH_PROVIDER is %q", ProviderBaidu)
+		}
+		if err := CheckUpstreamURL(c.BaiduAPIBaseURL, c.UpstreamAllowlist, c.AllowInsecureHTTP); err != nil {
+			return fmt.Errorf("invalid BAIDU_API_BASE_URL: %w", err)
+		}
+		return nil
+	case ProviderJina:
+		if c.JinaAPIKey == "" {
+			return fmt.Errorf("JINA_API_KEY is required when SEARCH_PROVIDER is %q", ProviderJina)
+		}
+		if err := CheckUpstreamURL(c.JinaSearchURL, c.UpstreamAllowlist, c.AllowInsecureHTTP); err != nil {
+			return fmt.Errorf("invalid JINA_SEARCH_URL: %w", err)
+		}
+		if err := CheckUpstreamURL(c.JinaReaderURL, c.UpstreamAllowlist, c.AllowInsecureHTTP); err != nil {
+			return fmt.Errorf("invalid JINA_READER_URL: %w", err)
+		}
+		return nil
+	case ProviderArxiv:
+		if err := CheckUpstreamURL(c.ArxivAPIURL, c.UpstreamAllowlist, c.AllowInsecureHTTP); err != nil {
+			return fmt.Errorf("invalid ARXIV_API_URL: %w", err)
+		}
+		return nil
+	case ProviderPubMed:
+		if err := CheckUpstreamURL(c.PubMedAPIBaseURL, c.UpstreamAllowlist, c.AllowInsecureHTTP); err != nil {
+			return fmt.Errorf("invalid PUBMED_API_BASE_URL: %w", err)
+		}
+		return nil
+	case ProviderStackExchange:
+		if c.StackExchangeSite == "" {
+			return fmt.Errorf("STACKEXCHANGE_SITE cannot be empty")
+		}
+		if err := CheckUpstreamURL(c.StackExchangeAPIURL, c.UpstreamAllowlist, c.AllowInsecureHTTP); err != nil {
+			return fmt.Errorf("invalid STACKEXCHANGE_API_URL: %w", err)
+		}
+		return nil
+	case ProviderGeneric:
+		return c.validateGeneric()
+	case ProviderGRPC:
+		return c.validateGRPC()
+	case ProviderCommand:
+		if c.CommandPath == "" {
+			return fmt.Errorf("COMMAND_PATH is required when SEARCH_PROVIDER is %q", ProviderCommand)
+		}
+		if c.CommandTimeout <= 0 || c.CommandMaxOutput < 1 || c.CommandMaxProcs < 1 {
+			return fmt.Errorf("COMMAND_TIMEOUT, COMMAND_MAX_OUTPUT and COMMAND_MAX_PROCS must be positive")
+		}
+		if _, err := c.CommandMapping.Paths(); err != nil {
+			return fmt.Errorf("invalid command_mapping: %w", err)
+		}
+		return nil
+	case ProviderPlugin:
+		if c.PluginCommand == "" {
+			return fmt.Errorf("PLUGIN_COMMAND is required when SEARCH_PROVIDER is %q", ProviderPlugin)
+		}
+		return nil
+	default:
+		// Other providers are registered with the search package, which
+		// rejects unknown names when the provider is created
+		return nil
+	}
+}
+
+// APIKey returns the API key configured for a provider, and false for
+// providers that take no key
+func (c *Config) APIKey(provider string) (string, bool) {
+	switch provider {
+	case "", ProviderBocha:
+		return c.BochaAPIKey, true
+	case ProviderBrave:
+		return c.BraveAPIKey, true
+	case ProviderGoogle:
+		return c.GoogleAPIKey, true
+	case ProviderBaidu:
+		return c.BaiduAPIKey, true
+	case ProviderJina:
+		return c.JinaAPIKey, true
+	case ProviderPubMed:
+		return c.PubMedAPIKey, true
+	case ProviderStackExchange:
+		return c.StackExchangeKey, true
+	case ProviderGeneric:
+		return c.GenericAPIKey, true
+	case ProviderGRPC:
+		return c.GRPCAPIKey, true
+	default:
+		return "", false
+	}
+}
+
+// ToolProfile returns the name and tool list of the profile that applies to
+// the connecting client. A nil tool list means every tool is exposed.
+// The client is identified by the token in MCP_CLIENT_TOKEN; clients without
+// a token get the default profile.
+func (c *Config) ToolProfile() (string, []string, error) {
+	name := c.DefaultToolProfile
+	if c.ClientToken != "" {
+		profile, ok := c.ClientProfiles[c.ClientToken]
+		if !ok {
+			return "", nil, fmt.Errorf("unknown client token %s", maskSecret(c.ClientToken))
+		}
+		name = profile
+	}
+
+	if name == "" {
+		return "", nil, nil
+	}
+
+	tools, ok := c.ToolProfiles[name]
+	if !ok {
+		return "", nil, fmt.Errorf("tool profile %q is not defined", name)
+	}
+	return name, tools, nil
+}
+
+// Pipeline returns the result pipeline stages to apply, in order: the default
+// pipeline when none is configured, and no stages for "none"
+func (c *Config) Pipeline() []string {
+	switch {
+	case len(c.ResultPipeline) == 0:
+		return DefaultResultPipeline
+	case len(c.ResultPipeline) == 1 && c.ResultPipeline[0] == StageNone:
+		return nil
+	}
+	return c.ResultPipeline
+}
+
+// DefaultFreshness returns the freshness tool searches with when a call does
+// not set one
+func (c *Config) DefaultFreshness(tool string) string {
+	if freshness := c.ToolFreshness[tool]; freshness != "" {
+		return freshness
+	}
+	return params.DefaultFreshness
+}
+
+// isFreshness reports whether freshness is a value the server understands
+func isFreshness(freshness string) bool {
+	for _, f := range params.Freshness {
+		if f == freshness {
+			return true
+		}
+	}
+	return false
+}
+
+// Location returns the zone result dates are shown in, or nil when Timezone
+// is not set
+func (c *Config) Location() (*time.Location, error) {
+	if c.Timezone == "" {
+		return nil, nil
+	}
+	loc, err := time.LoadLocation(c.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid TIMEZONE %q: %w", c.Timezone, err)
+	}
+	return loc, nil
+}
+
+// EncryptionKey returns the key local state is encrypted with, from DataKey or
+// DataKeyFile, or nil when encryption is not configured
+func (c *Config) EncryptionKey() ([]byte, error) {
+	encoded := c.DataKey
+	if c.DataKeyFile != "" {
+		raw, err := os.ReadFile(c.DataKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read DATA_KEY_FILE: %w", err)
+		}
+		encoded = string(raw)
+	}
+	if strings.TrimSpace(encoded) == "" {
+		return nil, nil
+	}
+	key, err := store.ParseKey(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("invalid DATA_KEY: %w", err)
+	}
+	return key, nil
+}
+
+// Summary returns the effective configuration with secrets redacted,
+// suitable for logging at startup
+func (c *Config) Summary() map[string]interface{} {
+	summary := map[string]interface{}{
+		"source":                c.Source,
+		"search_provider":       c.SearchProvider,
+		"api_key":               "unset",
+		"http_timeout":          c.HTTPTimeout.String(),
+		"tls_min_version":       c.TLSMinVersion,
+		"worker_pool_size":      c.WorkerPoolSize,
+		"job_timeout":           c.JobTimeout.String(),
+		"warm_start":            c.WarmStart,
+		"warm_start_interval":   c.WarmStartInterval.String(),
+		"server_name":           c.ServerName,
+		"server_version":        c.ServerVersion,
+		"log_level":             c.LogLevel,
+		"timezone":              c.Timezone,
+		"rewrite_rules":         len(c.RewriteRules),
+		"boost_rules":           len(c.BoostRules),
+		"result_pipeline":       strings.Join(c.Pipeline(), ","),
+		"tool_freshness":        c.ToolFreshness,
+		"tool_profiles":         len(c.ToolProfiles),
+		"search_presets":        len(c.SearchPresets),
+		"provider_overrides":    strings.Join(c.ProviderOverrides, ","),
+		"aggregate_providers":   strings.Join(c.AggregateProviders, ","),
+		"provider_timeout":      c.ProviderTimeout.String(),
+		"aggregate_auto_weight": c.AggregateAutoWeight,
+		"aggregate_merge":       c.AggregateMerge,
+		"cache_ttl":             c.CacheTTL.String(),
+		"semantic_cache":        "disabled",
+		"startup_check":         c.StartupCheck,
+		"admin_api":             "disabled",
+		"fetch":                 "disabled",
+		"data_dir":              c.DataDir,
+		"daily_quotas":          c.DailyQuotas,
+		"purge_on_shutdown":     c.PurgeOnShutdown,
+		"purge_tool":            c.PurgeToolEnabled,
+		"encryption":            "disabled",
+		"incognito":             c.Incognito,
+		"monitors":              len(c.Monitors),
+		"webhook":               "disabled",
+	}
+	if c.Source == "" {
+		summary["source"] = "environment"
+	}
+	switch c.SearchProvider {
+	case ProviderPlugin:
+		summary["plugin_command"] = c.PluginCommand
+	case ProviderBrave:
+		if c.BraveAPIKey != "" {
+			summary["api_key"] = maskSecret(c.BraveAPIKey)
+		}
+		summary["api_base_url"] = c.BraveAPIBaseURL
+	case ProviderGoogle:
+		if c.GoogleAPIKey != "" {
+			summary["api_key"] = maskSecret(c.GoogleAPIKey)
+		}
+		summary["api_base_url"] = c.GoogleAPIBaseURL
+		summary["google_cx"] = c.GoogleCX
+	case ProviderBaidu:
+		if c.BaiduAPIKey != "" {
+			summary["api_key"] = maskSecret(c.BaiduAPIKey)
+		}
+		summary["api_base_url"] = c.BaiduAPIBaseURL
+	case ProviderJina:
+		if c.JinaAPIKey != "" {
+			summary["api_key"] = maskSecret(c.JinaAPIKey)
+		}
+		summary["api_base_url"] = c.JinaSearchURL
+		summary["jina_reader_url"] = c.JinaReaderURL
+	case ProviderArxiv:
+		summary["api_base_url"] = c.ArxivAPIURL
+	case ProviderPubMed:
+		if c.PubMedAPIKey != "" {
+			summary["api_key"] = maskSecret(c.PubMedAPIKey)
+		}
+		summary["api_base_url"] = c.PubMedAPIBaseURL
+	case ProviderStackExchange:
+		if c.StackExchangeKey != "" {
+			summary["api_key"] = maskSecret(c.StackExchangeKey)
+		}
+		summary["api_base_url"] = c.StackExchangeAPIURL
+		summary["stackexchange_site"] = c.StackExchangeSite
+	case ProviderGeneric:
+		if c.GenericAPIKey != "" {
+			summary["api_key"] = maskSecret(c.GenericAPIKey)
+		}
+		if endpoint, err := c.GenericEndpoint(); err == nil {
+			summary["api_base_url"] = endpoint.URL
+			summary["generic_method"] = endpoint.Method
+			summary["generic_results"] = endpoint.Mapping.Results
+		}
+		if c.GenericOpenAPISpec != "" {
+			summary["generic_openapi_operation"] = c.GenericOpenAPIOperation
+		}
+	case ProviderGRPC:
+		summary["grpc_target"] = c.GRPCTarget
+		summary["grpc_plaintext"] = c.GRPCPlaintext
+		if c.GRPCAPIKey != "" {
+			summary["grpc_api_key"] = maskSecret(c.GRPCAPIKey)
+		}
+	case ProviderCommand:
+		summary["command_path"] = c.CommandPath
+		summary["command_timeout"] = c.CommandTimeout.String()
+		summary["command_max_procs"] = c.CommandMaxProcs
+	default:
+		if c.BochaAPIKey != "" {
+			summary["api_key"] = maskSecret(c.BochaAPIKey)
+		}
+		summary["api_base_url"] = c.BochaAPIBaseURL
+		if c.BochaPro {
+			summary["bocha_pro_url"] = c.BochaProURL
+		}
+		if len(c.BochaRegions) > 0 {
+			summary["bocha_regions"] = strings.Join(c.BochaRegions, ",")
+		}
+	}
+	if c.AdminAddr != "" {
+		summary["admin_api"] = c.AdminAddr
+	}
+	if c.SSEAddr != "" {
+		summary["sse_addr"] = c.SSEAddr
+	}
+	if c.DataKey != "" || c.DataKeyFile != "" {
+		summary["encryption"] = "aes-256-gcm"
+	}
+	if c.SemanticCacheThreshold > 0 {
+		embedder := "built-in"
+		if c.SemanticCacheEmbeddingURL != "" {
+			embedder = urlHost(c.SemanticCacheEmbeddingURL)
+		}
+		summary["semantic_cache"] = fmt.Sprintf("threshold %.2f, ttl %s, embedder %s", c.SemanticCacheThreshold, c.SemanticCacheTTL, embedder)
+	}
+	if len(c.Denylist) > 0 || c.DenylistFeedURL != "" {
+		denylist := fmt.Sprintf("%d entries", len(c.Denylist))
+		if c.DenylistFeedURL != "" {
+			// Like webhooks, feed URLs may embed a token
+			denylist += ", feed " + urlHost(c.DenylistFeedURL)
+		}
+		summary["denylist"] = denylist
+	}
+	if c.SafetyMode != "" && c.SafetyMode != SafetyOff {
+		safety := c.SafetyMode
+		if c.SafetyClassifierURL != "" {
+			safety += ", classifier " + urlHost(c.SafetyClassifierURL)
+		}
+		summary["safety"] = safety
+	}
+	if c.WebhookURL != "" {
+		// Webhook URLs often embed a secret token, so only the host is logged
+		summary["webhook"] = c.WebhookFormat + " " + urlHost(c.WebhookURL)
+	}
+	if c.FetchEnabled {
+		summary["fetch"] = fmt.Sprintf("%d pages/min, %d bytes/min", c.FetchMaxPagesPerMinute, c.FetchMaxBytesPerMinute)
+		if c.DownloadDir != "" {
+			summary["download_dir"] = c.DownloadDir
+		}
+		if c.FetchArchiveFallback {
+			summary["fetch_archive"] = urlHost(c.FetchArchiveURL)
+		}
+	}
+	if c.HNSearchEnabled {
+		summary["hn_search"] = c.HNAPIURL
+	}
+	if c.ScreenshotURL != "" {
+		summary["screenshot"] = urlHost(c.ScreenshotURL)
+	}
+	if c.DialIPFamily != "" {
+		summary["dial_ip_family"] = c.DialIPFamily
+	}
+	if c.DialLocalAddress != "" {
+		summary["dial_local_address"] = c.DialLocalAddress
+	}
+	return summary
+}
+
+// findContainerConfig returns the first conventional config path that exists
+func findContainerConfig() string {
+	for _, path := range ContainerConfigPaths {
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path
+		}
+	}
+	return ""
+}
+
+// defaultDataDir returns the default directory for local state, ~/.mcp-search
+func defaultDataDir() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".mcp-search")
+}
+
+// readSecretFile reads a secret such as an API key from a file, trimming surrounding whitespace
+func readSecretFile(path string) (string, error) {
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return "", err
+	}
+	secret := strings.TrimSpace(string(data))
+	if secret == "" {
+		return "", fmt.Errorf("file is empty")
+	}
+	return secret, nil
+}
+
+// urlHost returns the host of a webhook or feed URL, which is safe to log
+func urlHost(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return "(invalid)"
+	}
+	return u.Host
+}
+
+// maskSecret returns a version of a secret that is safe to log
+func maskSecret(secret string) string {
+	if len(secret) <= 8 {
+		return "****"
+	}
+	return secret[:4] + "..." + secret[len(secret)-4:]
+}
+
 // getEnvWithDefault returns the value of the environment variable or the default value if not set
 func getEnvWithDefault(key, defaultValue string) string {
 	value := os.Getenv(key)
@@ -182,6 +2558,107 @@ func getEnvWithDefault(key, defaultValue string) string {
 	return value
 }
 
+// getEnvIntWithDefault returns the integer from the environment variable or the default value if not set
+func getEnvIntWithDefault(key string, defaultValue int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		log.Printf("Warning: Could not parse %s as integer, using default of %d", key, defaultValue)
+		return defaultValue
+	}
+	return n
+}
+
+// getEnvListWithDefault returns the comma-separated list from the environment variable or the default value if not set
+func getEnvListWithDefault(key string, defaultValue []string) []string {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	var list []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// getEnvMapWithDefault returns the key=value pairs of a comma-separated
+// environment variable or the default value if not set
+func getEnvMapWithDefault(key string, defaultValue map[string]string) map[string]string {
+	list := getEnvListWithDefault(key, nil)
+	if list == nil {
+		return defaultValue
+	}
+
+	m := make(map[string]string, len(list))
+	for _, item := range list {
+		k, v, ok := strings.Cut(item, "=")
+		if !ok {
+			log.Printf("Warning: Ignoring %s entry %q, expected key=value", key, item)
+			continue
+		}
+		m[strings.TrimSpace(k)] = strings.TrimSpace(v)
+	}
+	return m
+}
+
+// getEnvIntMapWithDefault returns the key=number pairs of a comma-separated
+// environment variable or the default value if not set
+func getEnvIntMapWithDefault(key string, defaultValue map[string]int) map[string]int {
+	pairs := getEnvMapWithDefault(key, nil)
+	if pairs == nil {
+		return defaultValue
+	}
+
+	m := make(map[string]int, len(pairs))
+	for k, v := range pairs {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			log.Printf("Warning: Ignoring %s entry %s=%s, expected a whole number", key, k, v)
+			continue
+		}
+		m[k] = n
+	}
+	return m
+}
+
+// getEnvBoolWithDefault returns the boolean from the environment variable or the default value if not set
+func getEnvBoolWithDefault(key string, defaultValue bool) bool {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		log.Printf("Warning: Could not parse %s as boolean, using default of %t", key, defaultValue)
+		return defaultValue
+	}
+	return b
+}
+
+// getEnvFloatWithDefault returns the number from the environment variable or the default value if not set
+func getEnvFloatWithDefault(key string, defaultValue float64) float64 {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		log.Printf("Warning: Could not parse %s as number, using default of %v", key, defaultValue)
+		return defaultValue
+	}
+	return f
+}
+
 // getEnvDurationWithDefault returns the duration from the environment variable or the default value if not set
 func getEnvDurationWithDefault(key string, defaultValue time.Duration) time.Duration {
 	value := os.Getenv(key)
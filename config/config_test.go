@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 )
@@ -79,7 +80,7 @@ func TestValidate(t *testing.T) {
 	// Test with valid configuration
 	cfg := &Config{
 		BochaAPIKey:     "test-api-key",
-		BochaAPIBaseURL: "https://test.api.com",
+		BochaAPIBaseURL: "https://api.bochaai.com/v1/web-search",
 		HTTPTimeout:     10 * time.Second,
 		ServerName:      "Test Server",
 		ServerVersion:   "0.0.1",
@@ -251,3 +252,1205 @@ http_timeout: "invalid-duration"
 		t.Errorf("Expected HTTPTimeout to remain %s, got %s", originalTimeout, cfg.HTTPTimeout)
 	}
 }
+
+func TestValidateSearchProvider(t *testing.T) {
+	// Plugin provider does not need a Bocha API key, but needs a command
+	cfg := &Config{SearchProvider: ProviderPlugin}
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected error for plugin provider without command, got nil")
+	}
+
+	cfg.PluginCommand = "/usr/local/bin/search-plugin"
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected no error for plugin provider with command, got %v", err)
+	}
+
+	// Other providers are validated by the search provider registry
+	cfg.SearchProvider = "custom-engine"
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected no error for a registered provider name, got %v", err)
+	}
+}
+
+func TestBraveProvider(t *testing.T) {
+	t.Setenv("BOCHA_API_KEY", "")
+	t.Setenv("BOCHA_API_KEY_FILE", "")
+	t.Setenv("SEARCH_PROVIDER", "")
+	t.Setenv("CONFIG_FILE", "")
+	t.Setenv("BRAVE_API_KEY", "test-brave-key")
+	cfg := New()
+	if cfg.SearchProvider != ProviderBrave {
+		t.Errorf("Expected a Brave key alone to select %q, got %q", ProviderBrave, cfg.SearchProvider)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected no error without a Bocha key, got %v", err)
+	}
+	if cfg.Summary()["api_base_url"] != "https://api.search.brave.com/res/v1/web/search" {
+		t.Errorf("Expected the Brave base URL in the summary, got %v", cfg.Summary()["api_base_url"])
+	}
+
+	cfg.BraveAPIKey = ""
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected error for Brave provider without BRAVE_API_KEY, got nil")
+	}
+}
+
+func TestGoogleProvider(t *testing.T) {
+	t.Setenv("BOCHA_API_KEY", "")
+	t.Setenv("BOCHA_API_KEY_FILE", "")
+	t.Setenv("BRAVE_API_KEY", "")
+	t.Setenv("SEARCH_PROVIDER", "")
+	t.Setenv("CONFIG_FILE", "")
+	t.Setenv("GOOGLE_API_KEY", "test-google-key")
+	t.Setenv("GOOGLE_CX", "0123456789abcdef")
+	cfg := New()
+	if cfg.SearchProvider != ProviderGoogle {
+		t.Errorf("Expected a Google key and engine ID to select %q, got %q", ProviderGoogle, cfg.SearchProvider)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected no error without a Bocha key, got %v", err)
+	}
+
+	cfg.GoogleCX = ""
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected error for Google provider without GOOGLE_CX, got nil")
+	}
+}
+
+func TestBaiduProvider(t *testing.T) {
+	t.Setenv("BOCHA_API_KEY", "")
+	t.Setenv("BOCHA_API_KEY_FILE", "")
+	t.Setenv("BRAVE_API_KEY", "")
+	t.Setenv("GOOGLE_API_KEY", "")
+	t.Setenv("SEARCH_PROVIDER", "")
+	t.Setenv("CONFIG_FILE", "")
+	t.Setenv("BAIDU_API_KEY", "test-baidu-key")
+	cfg := New()
+	if cfg.SearchProvider != ProviderBaidu {
+		t.Errorf("Expected a Baidu key to select %q, got %q", ProviderBaidu, cfg.SearchProvider)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected no error without a Bocha key, got %v", err)
+	}
+
+	cfg.BaiduAPIKey = ""
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected error for Baidu provider without BAIDU_API_KEY, got nil")
+	}
+}
+
+func TestJinaProvider(t *testing.T) {
+	t.Setenv("BOCHA_API_KEY", "")
+	t.Setenv("BOCHA_API_KEY_FILE", "")
+	t.Setenv("BRAVE_API_KEY", "")
+	t.Setenv("GOOGLE_API_KEY", "")
+	t.Setenv("BAIDU_API_KEY", "")
+	t.Setenv("SEARCH_PROVIDER", "")
+	t.Setenv("CONFIG_FILE", "")
+	t.Setenv("JINA_API_KEY", "test-jina-key")
+	cfg := New()
+	if cfg.SearchProvider != ProviderJina {
+		t.Errorf("Expected a Jina key to select %q, got %q", ProviderJina, cfg.SearchProvider)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected no error without a Bocha key, got %v", err)
+	}
+
+	cfg.JinaReaderURL = "https://reader.example.com/"
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "invalid JINA_READER_URL") {
+		t.Errorf("Expected error for a reader URL on an unknown host, got %v", err)
+	}
+}
+
+func TestArxivProvider(t *testing.T) {
+	t.Setenv("CONFIG_FILE", "")
+	t.Setenv("SEARCH_PROVIDER", "arxiv")
+	t.Setenv("BOCHA_API_KEY", "")
+	t.Setenv("BOCHA_API_KEY_FILE", "")
+	t.Setenv("ARXIV_API_URL", "")
+	cfg := New()
+	if cfg.ArxivAPIURL != "https://export.arxiv.org/api/query" {
+		t.Errorf("Expected the default arXiv API URL, got %q", cfg.ArxivAPIURL)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected no error without any key, got %v", err)
+	}
+
+	cfg.ArxivAPIURL = "https://arxiv.example.com/api/query"
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "invalid ARXIV_API_URL") {
+		t.Errorf("Expected error for an API URL on an unknown host, got %v", err)
+	}
+}
+
+func TestPubMedProvider(t *testing.T) {
+	t.Setenv("CONFIG_FILE", "")
+	t.Setenv("SEARCH_PROVIDER", "pubmed")
+	t.Setenv("BOCHA_API_KEY", "")
+	t.Setenv("BOCHA_API_KEY_FILE", "")
+	t.Setenv("PUBMED_API_BASE_URL", "")
+	keyFile := filepath.Join(t.TempDir(), "ncbi_api_key")
+	if err := os.WriteFile(keyFile, []byte("test-ncbi-key\n"), 0600); err != nil {
+		t.Fatalf("Failed to write key file: %v", err)
+	}
+	t.Setenv("PUBMED_API_KEY_FILE", keyFile)
+	cfg := New()
+	if cfg.PubMedAPIKey != "test-ncbi-key" {
+		t.Errorf("Expected the key from the key file, got %q", cfg.PubMedAPIKey)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected no error for PubMed, got %v", err)
+	}
+
+	cfg.PubMedAPIBaseURL = "https://eutils.example.com/entrez/eutils"
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "invalid PUBMED_API_BASE_URL") {
+		t.Errorf("Expected error for a base URL on an unknown host, got %v", err)
+	}
+}
+
+func TestStackExchangeProvider(t *testing.T) {
+	t.Setenv("CONFIG_FILE", "")
+	t.Setenv("SEARCH_PROVIDER", "stackexchange")
+	t.Setenv("BOCHA_API_KEY", "")
+	t.Setenv("BOCHA_API_KEY_FILE", "")
+	t.Setenv("STACKEXCHANGE_KEY", "")
+	t.Setenv("STACKEXCHANGE_KEY_FILE", "")
+	t.Setenv("STACKEXCHANGE_SITE", "")
+	t.Setenv("STACKEXCHANGE_API_URL", "")
+	cfg := New()
+	if cfg.StackExchangeSite != "stackoverflow" || cfg.StackExchangeAPIURL != "https://api.stackexchange.com/2.3" {
+		t.Errorf("Expected Stack Overflow by default, got %q at %q", cfg.StackExchangeSite, cfg.StackExchangeAPIURL)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected no error for Stack Exchange without a key, got %v", err)
+	}
+
+	cfg.StackExchangeSite = ""
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "STACKEXCHANGE_SITE cannot be empty") {
+		t.Errorf("Expected error for an empty site, got %v", err)
+	}
+	cfg.StackExchangeSite = "serverfault"
+	cfg.StackExchangeAPIURL = "https://api.stackexchange.example.com/2.3"
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "invalid STACKEXCHANGE_API_URL") {
+		t.Errorf("Expected error for an API URL on an unknown host, got %v", err)
+	}
+}
+
+func TestCommandProvider(t *testing.T) {
+	t.Setenv("SEARCH_PROVIDER", "command")
+	t.Setenv("BOCHA_API_KEY", "")
+	t.Setenv("BOCHA_API_KEY_FILE", "")
+	t.Setenv("COMMAND_PATH", "/usr/local/bin/intranet-search")
+	t.Setenv("COMMAND_ARGS", "--json,--limit,{count},--,{query}")
+	t.Setenv("COMMAND_ENV", "")
+	t.Setenv("COMMAND_DIR", "")
+	t.Setenv("COMMAND_TIMEOUT", "")
+	t.Setenv("COMMAND_MAX_OUTPUT", "")
+	t.Setenv("COMMAND_MAX_PROCS", "")
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	data := "command_timeout: \"3s\"\ncommand_mapping:\n  results: \"hits\"\n  snippet: \"excerpt\"\n"
+	if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	t.Setenv("CONFIG_FILE", path)
+	cfg := New()
+	if len(cfg.CommandArgs) != 5 || cfg.CommandArgs[4] != "{query}" {
+		t.Errorf("Expected the arguments from COMMAND_ARGS, got %v", cfg.CommandArgs)
+	}
+	if cfg.CommandTimeout != 3*time.Second || cfg.CommandMaxOutput != 1024*1024 || cfg.CommandMaxProcs != 4 {
+		t.Errorf("Unexpected limits: %s, %d bytes, %d processes", cfg.CommandTimeout, cfg.CommandMaxOutput, cfg.CommandMaxProcs)
+	}
+	expected := GenericMapping{Results: "hits", Title: "title", URL: "url", Snippet: "excerpt"}
+	if cfg.CommandMapping != expected {
+		t.Errorf("Expected the file mapping over the defaults, got %+v", cfg.CommandMapping)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	cfg.CommandMaxProcs = 0
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "must be positive") {
+		t.Errorf("Expected error for no processes, got %v", err)
+	}
+	cfg.CommandMaxProcs = 4
+	cfg.CommandMapping.URL = "links["
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "invalid command_mapping") {
+		t.Errorf("Expected error for an invalid mapping, got %v", err)
+	}
+	cfg.CommandPath = ""
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "COMMAND_PATH is required") {
+		t.Errorf("Expected error without a command, got %v", err)
+	}
+}
+
+func TestHNSearchConfig(t *testing.T) {
+	t.Setenv("CONFIG_FILE", "")
+	t.Setenv("SEARCH_PROVIDER", "")
+	t.Setenv("BOCHA_API_KEY", "test-key")
+	t.Setenv("HN_SEARCH_ENABLED", "")
+	t.Setenv("HN_API_URL", "")
+	cfg := New()
+	if cfg.HNSearchEnabled || cfg.HNAPIURL != "https://hn.algolia.com/api/v1" {
+		t.Errorf("Expected Hacker News search off by default, got %v at %q", cfg.HNSearchEnabled, cfg.HNAPIURL)
+	}
+
+	t.Setenv("HN_SEARCH_ENABLED", "true")
+	t.Setenv("HN_API_URL", "https://hn.example.com/api/v1")
+	cfg = New()
+	if !cfg.HNSearchEnabled {
+		t.Error("Expected HN_SEARCH_ENABLED to enable the tool")
+	}
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "invalid HN_API_URL") {
+		t.Errorf("Expected error for an API URL on an unknown host, got %v", err)
+	}
+}
+
+func TestPurgeToolConfig(t *testing.T) {
+	t.Setenv("CONFIG_FILE", "")
+	t.Setenv("SEARCH_PROVIDER", "")
+	t.Setenv("BOCHA_API_KEY", "test-key")
+	t.Setenv("PURGE_TOOL_ENABLED", "")
+	if New().PurgeToolEnabled {
+		t.Error("Expected the purge tool off by default")
+	}
+
+	t.Setenv("PURGE_TOOL_ENABLED", "true")
+	if !New().PurgeToolEnabled {
+		t.Error("Expected PURGE_TOOL_ENABLED to enable the tool")
+	}
+}
+
+func TestBochaProConfig(t *testing.T) {
+	t.Setenv("CONFIG_FILE", "")
+	t.Setenv("SEARCH_PROVIDER", "")
+	t.Setenv("BOCHA_API_KEY", "test-key")
+	t.Setenv("BOCHA_PRO", "")
+	t.Setenv("BOCHA_PRO_URL", "")
+	cfg := New()
+	if cfg.BochaPro || cfg.BochaProURL != "https://api.bochaai.com/v1/web-search-pro" {
+		t.Errorf("Expected the standard endpoint by default, got pro=%v at %q", cfg.BochaPro, cfg.BochaProURL)
+	}
+
+	t.Setenv("BOCHA_PRO", "true")
+	t.Setenv("BOCHA_PRO_URL", "https://search-proxy.example.com/pro")
+	cfg = New()
+	if !cfg.BochaPro {
+		t.Error("Expected BOCHA_PRO to select the pro endpoint")
+	}
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "invalid BOCHA_PRO_URL") {
+		t.Errorf("Expected error for a pro URL on an unknown host, got %v", err)
+	}
+}
+
+func TestBochaAISearchConfig(t *testing.T) {
+	t.Setenv("CONFIG_FILE", "")
+	t.Setenv("SEARCH_PROVIDER", "")
+	t.Setenv("BOCHA_API_KEY", "test-key")
+	t.Setenv("BOCHA_ENDPOINT", "")
+	t.Setenv("BOCHA_PATHS", "")
+	t.Setenv("BOCHA_AI_SEARCH_URL", "")
+	if cfg := New(); cfg.BochaAISearchURL != "https://api.bochaai.com/v1/ai-search" {
+		t.Errorf("Expected the AI search below the default endpoint, got %q", cfg.BochaAISearchURL)
+	}
+
+	t.Setenv("BOCHA_AI_SEARCH_URL", "https://search-proxy.example.com/ai")
+	if err := New().Validate(); err == nil || !strings.Contains(err.Error(), "invalid BOCHA_AI_SEARCH_URL") {
+		t.Errorf("Expected error for an AI search URL on an unknown host, got %v", err)
+	}
+}
+
+func TestBochaAgentSearchConfig(t *testing.T) {
+	t.Setenv("CONFIG_FILE", "")
+	t.Setenv("SEARCH_PROVIDER", "")
+	t.Setenv("BOCHA_API_KEY", "test-key")
+	t.Setenv("BOCHA_ENDPOINT", "")
+	t.Setenv("BOCHA_PATHS", "")
+	t.Setenv("BOCHA_AGENT_SEARCH_URL", "")
+	t.Setenv("BOCHA_AGENTS", "")
+	cfg := New()
+	if cfg.BochaAgentSearchURL != "https://api.bochaai.com/v1/agent-search" {
+		t.Errorf("Expected the agent search below the default endpoint, got %q", cfg.BochaAgentSearchURL)
+	}
+	if len(cfg.BochaAgents) != len(DefaultBochaAgents) {
+		t.Errorf("Expected the default agents, got %v", cfg.BochaAgents)
+	}
+
+	t.Setenv("BOCHA_AGENTS", "bocha-scholar-agent, intranet-people-agent")
+	cfg = New()
+	if len(cfg.BochaAgents) != 2 || cfg.BochaAgents[1] != "intranet-people-agent" {
+		t.Errorf("Expected the agents from BOCHA_AGENTS, got %v", cfg.BochaAgents)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	cfg.BochaAgents = []string{"bocha scholar"}
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "invalid BOCHA_AGENTS entry") {
+		t.Errorf("Expected error for an agent name with a space, got %v", err)
+	}
+
+	t.Setenv("BOCHA_AGENT_SEARCH_URL", "https://search-proxy.example.com/agent")
+	if err := New().Validate(); err == nil || !strings.Contains(err.Error(), "invalid BOCHA_AGENT_SEARCH_URL") {
+		t.Errorf("Expected error for an agent search URL on an unknown host, got %v", err)
+	}
+}
+
+func TestProviderOverrides(t *testing.T) {
+	t.Setenv("CONFIG_FILE", "")
+	t.Setenv("SEARCH_PROVIDER", "")
+	t.Setenv("BOCHA_API_KEY", "test-key")
+	t.Setenv("BRAVE_API_KEY", "")
+	t.Setenv("PROVIDER_OVERRIDES", "brave, baidu")
+	t.Setenv("BAIDU_API_KEY", "test-baidu-key")
+	cfg := New()
+	if len(cfg.ProviderOverrides) != 2 || cfg.ProviderOverrides[0] != ProviderBrave || cfg.ProviderOverrides[1] != ProviderBaidu {
+		t.Fatalf("Expected the brave and baidu overrides, got %v", cfg.ProviderOverrides)
+	}
+	err := cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(), `invalid provider override "brave": BRAVE_API_KEY is required`) {
+		t.Errorf("Expected error for an override without its key, got %v", err)
+	}
+
+	cfg.BraveAPIKey = "test-brave-key"
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected no error with every override configured, got %v", err)
+	}
+}
+
+func TestAggregateProviders(t *testing.T) {
+	t.Setenv("CONFIG_FILE", "")
+	t.Setenv("SEARCH_PROVIDER", "")
+	t.Setenv("BOCHA_API_KEY", "test-key")
+	t.Setenv("GOOGLE_API_KEY", "")
+	t.Setenv("AGGREGATE_PROVIDERS", "google")
+	t.Setenv("PROVIDER_TIMEOUT", "")
+	t.Setenv("AGGREGATE_MERGE", "")
+	cfg := New()
+	if len(cfg.AggregateProviders) != 1 || cfg.ProviderTimeout != 10*time.Second {
+		t.Fatalf("Expected the google aggregate provider and the default timeout, got %v and %s", cfg.AggregateProviders, cfg.ProviderTimeout)
+	}
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), `invalid aggregate provider "google"`) {
+		t.Errorf("Expected error for an aggregate provider without its key, got %v", err)
+	}
+
+	cfg.GoogleAPIKey, cfg.GoogleCX = "test-google-key", "0123456789abcdef"
+	cfg.ProviderTimeout = -time.Second
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected error for a negative provider timeout, got nil")
+	}
+
+	cfg.ProviderTimeout = time.Second
+	if cfg.AggregateMerge != AggregateMergeInterleave {
+		t.Errorf("Expected the interleave merge by default, got %q", cfg.AggregateMerge)
+	}
+	cfg.AggregateMerge = AggregateMergeRRF
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected no error for the rrf merge, got %v", err)
+	}
+	cfg.AggregateMerge = "round-robin"
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "AGGREGATE_MERGE") {
+		t.Errorf("Expected error for an unknown merge, got %v", err)
+	}
+}
+
+func TestValidateRewriteRules(t *testing.T) {
+	cfg := &Config{
+		BochaAPIKey:     "test-api-key",
+		BochaAPIBaseURL: "https://api.bochaai.com/v1/web-search",
+		RewriteRules:    []RewriteRule{{Pattern: `\bk8s\b`, Replacement: "kubernetes"}},
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected no error for valid rewrite rules, got %v", err)
+	}
+
+	cfg.RewriteRules = append(cfg.RewriteRules, RewriteRule{Pattern: "(unclosed"})
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected error for invalid rewrite rule pattern, got nil")
+	}
+}
+
+func TestValidatePIIScrub(t *testing.T) {
+	cfg := &Config{
+		BochaAPIKey:     "test-api-key",
+		BochaAPIBaseURL: "https://api.bochaai.com/v1/web-search",
+		PIIScrub:        []string{"email", "phone"},
+		PIIPatterns:     []string{`EMP-\d{6}`},
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected no error for valid PII settings, got %v", err)
+	}
+
+	cfg.PIIScrub = []string{"passport"}
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected error for unknown PII kind, got nil")
+	}
+
+	cfg.PIIScrub = []string{"none"}
+	cfg.PIIPatterns = []string{"(unclosed"}
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected error for invalid PII pattern, got nil")
+	}
+}
+
+func TestValidateRetention(t *testing.T) {
+	cfg := &Config{
+		BochaAPIKey:       "test-api-key",
+		BochaAPIBaseURL:   "https://api.bochaai.com/v1/web-search",
+		HistoryMaxAge:     time.Hour,
+		HistoryMaxEntries: 100,
+		SavedMaxAge:       30 * 24 * time.Hour,
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected no error for valid retention, got %v", err)
+	}
+
+	cfg.SavedMaxEntries = -1
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected error for negative SAVED_MAX_ENTRIES, got nil")
+	}
+}
+
+func TestResultPipeline(t *testing.T) {
+	cfg := &Config{
+		BochaAPIKey:     "test-api-key",
+		BochaAPIBaseURL: "https://api.bochaai.com/v1/web-search",
+	}
+	if got := strings.Join(cfg.Pipeline(), ","); got != "dedupe,filter,rerank,truncate,format" {
+		t.Errorf("Expected the default pipeline, got %q", got)
+	}
+
+	cfg.ResultPipeline = []string{"rerank", "dedupe"}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected no error for a reordered pipeline, got %v", err)
+	}
+	if got := strings.Join(cfg.Pipeline(), ","); got != "rerank,dedupe" {
+		t.Errorf("Expected the configured pipeline, got %q", got)
+	}
+
+	cfg.ResultPipeline = []string{"none"}
+	if len(cfg.Pipeline()) != 0 {
+		t.Errorf("Expected no stages, got %v", cfg.Pipeline())
+	}
+
+	for _, pipeline := range [][]string{{"dedupe", "sort"}, {"dedupe", "dedupe"}, {"none", "format"}} {
+		cfg.ResultPipeline = pipeline
+		if err := cfg.Validate(); err == nil {
+			t.Errorf("Expected error for pipeline %v, got nil", pipeline)
+		}
+	}
+}
+
+func TestToolFreshness(t *testing.T) {
+	t.Setenv("CONFIG_FILE", "")
+	t.Setenv("TOOL_FRESHNESS", "search=week, news = day, broken")
+	cfg := New()
+	if cfg.DefaultFreshness("search") != "week" || cfg.DefaultFreshness("news") != "day" {
+		t.Errorf("Expected per-tool defaults from TOOL_FRESHNESS, got %v", cfg.ToolFreshness)
+	}
+	if cfg.DefaultFreshness("search_saved") != "noLimit" {
+		t.Errorf("Expected noLimit for unconfigured tools, got %q", cfg.DefaultFreshness("search_saved"))
+	}
+
+	cfg.BochaAPIKey = "test-api-key"
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	cfg.ToolFreshness["search"] = "fortnight"
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected error for an unknown freshness, got nil")
+	}
+}
+
+func TestDailyQuotas(t *testing.T) {
+	t.Setenv("CONFIG_FILE", "")
+	t.Setenv("DAILY_QUOTAS", "bocha=1000, brave = 2000, google=lots")
+	cfg := New()
+	if len(cfg.DailyQuotas) != 2 || cfg.DailyQuotas["bocha"] != 1000 || cfg.DailyQuotas["brave"] != 2000 {
+		t.Errorf("Expected quotas from DAILY_QUOTAS, got %v", cfg.DailyQuotas)
+	}
+
+	cfg.BochaAPIKey = "test-api-key"
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	cfg.DailyQuotas["bocha"] = 0
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected error for a zero quota, got nil")
+	}
+}
+
+func TestSearchPresets(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	content := `search_presets:
+  news-scan:
+    freshness: day
+    count: 20
+    summary: true
+    include_images: false
+`
+	if err := os.WriteFile(configPath, []byte(content), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	cfg := &Config{BochaAPIKey: "test-api-key", BochaAPIBaseURL: "https://api.bochaai.com/v1/web-search"}
+	if err := cfg.LoadFromFile(configPath); err != nil {
+		t.Fatalf("LoadFromFile returned an error: %v", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	args := cfg.SearchPresets["news-scan"].Arguments()
+	if args["freshness"] != "day" || args["count"] != float64(20) || args["summary"] != true || args["include_images"] != false {
+		t.Errorf("Unexpected preset arguments: %v", args)
+	}
+	if _, ok := args["group_by_date"]; ok {
+		t.Error("Expected unset fields to be left to the call")
+	}
+
+	for _, preset := range []SearchPreset{{Freshness: "hourly"}, {Count: 100}, {Format: "html"}} {
+		cfg.SearchPresets = map[string]SearchPreset{"bad": preset}
+		if err := cfg.Validate(); err == nil {
+			t.Errorf("Expected error for preset %+v, got nil", preset)
+		}
+	}
+}
+
+func TestTimezone(t *testing.T) {
+	cfg := &Config{
+		BochaAPIKey:     "test-api-key",
+		BochaAPIBaseURL: "https://api.bochaai.com/v1/web-search",
+	}
+	if loc, err := cfg.Location(); err != nil || loc != nil {
+		t.Errorf("Expected no location when unset, got %v (%v)", loc, err)
+	}
+
+	t.Setenv("TIMEZONE", "Asia/Shanghai")
+	cfg = New()
+	loc, err := cfg.Location()
+	if err != nil || loc == nil || loc.String() != "Asia/Shanghai" {
+		t.Errorf("Expected Asia/Shanghai, got %v (%v)", loc, err)
+	}
+
+	cfg.Timezone = "Mars/Olympus_Mons"
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected error for an unknown timezone, got nil")
+	}
+}
+
+func TestWorkerPoolConfig(t *testing.T) {
+	t.Setenv("WORKER_POOL_SIZE", "4")
+	t.Setenv("JOB_TIMEOUT", "5s")
+	cfg := New()
+	if cfg.WorkerPoolSize != 4 {
+		t.Errorf("Expected WorkerPoolSize 4, got %d", cfg.WorkerPoolSize)
+	}
+	if cfg.JobTimeout != 5*time.Second {
+		t.Errorf("Expected JobTimeout 5s, got %v", cfg.JobTimeout)
+	}
+
+	cfg = &Config{
+		BochaAPIKey:     "test-api-key",
+		BochaAPIBaseURL: "https://api.bochaai.com/v1/web-search",
+		WorkerPoolSize:  -1,
+	}
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected error for negative WORKER_POOL_SIZE, got nil")
+	}
+}
+
+func TestWarmStartConfig(t *testing.T) {
+	if cfg := New(); cfg.WarmStart || cfg.WarmStartInterval != time.Minute {
+		t.Errorf("Expected warm start off with a 1m interval by default, got %v, %v", cfg.WarmStart, cfg.WarmStartInterval)
+	}
+
+	t.Setenv("WARM_START", "true")
+	t.Setenv("WARM_START_INTERVAL", "0")
+	cfg := New()
+	if !cfg.WarmStart || cfg.WarmStartInterval != 0 {
+		t.Errorf("Expected warm start once, got %v, %v", cfg.WarmStart, cfg.WarmStartInterval)
+	}
+
+	cfg = &Config{
+		BochaAPIKey:       "test-api-key",
+		BochaAPIBaseURL:   "https://api.bochaai.com/v1/web-search",
+		WarmStartInterval: -time.Second,
+	}
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected error for negative WARM_START_INTERVAL, got nil")
+	}
+}
+
+func TestAPIKey(t *testing.T) {
+	cfg := &Config{BochaAPIKey: "bocha-key", BraveAPIKey: "brave-key", GRPCAPIKey: "grpc-key"}
+	for provider, expected := range map[string]string{"": "bocha-key", ProviderBrave: "brave-key", ProviderGRPC: "grpc-key", ProviderGoogle: ""} {
+		if key, ok := cfg.APIKey(provider); !ok || key != expected {
+			t.Errorf("Expected key %q for %q, got %q, %v", expected, provider, key, ok)
+		}
+	}
+	if _, ok := cfg.APIKey(ProviderArxiv); ok {
+		t.Error("Expected arXiv to take no key")
+	}
+}
+
+func TestSSEConfig(t *testing.T) {
+	t.Setenv("SSE_ADDR", "127.0.0.1:8080")
+	t.Setenv("SSE_TOKEN", "short")
+	cfg := New()
+	if cfg.SSEAddr != "127.0.0.1:8080" || cfg.SSEToken != "short" {
+		t.Errorf("Expected the SSE address and token from the environment, got %q, %q", cfg.SSEAddr, cfg.SSEToken)
+	}
+
+	cfg = &Config{
+		BochaAPIKey:     "test-api-key",
+		BochaAPIBaseURL: "https://api.bochaai.com/v1/web-search",
+		SSEAddr:         "127.0.0.1:8080",
+		SSEToken:        "short",
+	}
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "SSE_TOKEN") {
+		t.Errorf("Expected an error for a short SSE_TOKEN, got %v", err)
+	}
+	cfg.SSEToken = "at-least-16-characters"
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected a valid SSE configuration, got %v", err)
+	}
+}
+
+func TestEncryptionKey(t *testing.T) {
+	cfg := &Config{
+		BochaAPIKey:     "test-api-key",
+		BochaAPIBaseURL: "https://api.bochaai.com/v1/web-search",
+	}
+	if key, err := cfg.EncryptionKey(); key != nil || err != nil {
+		t.Errorf("Expected no key by default, got %v, %v", key, err)
+	}
+
+	keyFile := filepath.Join(t.TempDir(), "data.key")
+	if err := os.WriteFile(keyFile, []byte(strings.Repeat("0f", 32)+"\n"), 0o600); err != nil {
+		t.Fatalf("Failed to write key file: %v", err)
+	}
+	cfg.DataKeyFile = keyFile
+	if key, err := cfg.EncryptionKey(); len(key) != 32 || err != nil {
+		t.Errorf("Expected a key from the file, got %d bytes, %v", len(key), err)
+	}
+
+	cfg.DataKey = "MDEyMzQ1Njc4OTAxMjM0NTY3ODkwMTIzNDU2Nzg5MDE="
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected error when both DATA_KEY and DATA_KEY_FILE are set, got nil")
+	}
+
+	cfg.DataKeyFile = ""
+	cfg.DataKey = "not-a-key"
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected error for an invalid DATA_KEY, got nil")
+	}
+}
+
+func TestValidateBoostRules(t *testing.T) {
+	cfg := &Config{
+		BochaAPIKey:     "test-api-key",
+		BochaAPIBaseURL: "https://api.bochaai.com/v1/web-search",
+		BoostRules:      []BoostRule{{Pattern: `(?i)\bpython\b`, Pin: []string{"docs.python.org"}}},
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected no error for valid boost rules, got %v", err)
+	}
+
+	cfg.BoostRules = append(cfg.BoostRules, BoostRule{Pattern: "(unclosed"})
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected error for invalid boost rule pattern, got nil")
+	}
+}
+
+func TestToolProfile(t *testing.T) {
+	cfg := &Config{
+		ToolProfiles: map[string][]string{
+			"intern":     {"search"},
+			"researcher": {"search", "fetch_url", "deep_search"},
+		},
+		ClientProfiles: map[string]string{
+			"researcher-token-1234": "researcher",
+		},
+	}
+
+	// No default and no token: everything is exposed
+	name, tools, err := cfg.ToolProfile()
+	if err != nil || name != "" || tools != nil {
+		t.Errorf("Expected no restriction, got %q %v %v", name, tools, err)
+	}
+
+	// Default profile
+	cfg.DefaultToolProfile = "intern"
+	name, tools, err = cfg.ToolProfile()
+	if err != nil || name != "intern" || len(tools) != 1 {
+		t.Errorf("Expected intern profile, got %q %v %v", name, tools, err)
+	}
+
+	// Authenticated client
+	cfg.ClientToken = "researcher-token-1234"
+	name, tools, err = cfg.ToolProfile()
+	if err != nil || name != "researcher" || len(tools) != 3 {
+		t.Errorf("Expected researcher profile, got %q %v %v", name, tools, err)
+	}
+
+	// Unknown client token
+	cfg.ClientToken = "bogus-token-5678"
+	if _, _, err = cfg.ToolProfile(); err == nil {
+		t.Error("Expected error for unknown client token, got nil")
+	}
+}
+
+func TestValidateToolProfiles(t *testing.T) {
+	cfg := &Config{
+		BochaAPIKey:        "test-api-key",
+		BochaAPIBaseURL:    "https://api.bochaai.com/v1/web-search",
+		ToolProfiles:       map[string][]string{"intern": {"search"}},
+		DefaultToolProfile: "intern",
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	cfg.ClientProfiles = map[string]string{"token": "missing"}
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected error for client mapped to undefined profile, got nil")
+	}
+
+	cfg.ClientProfiles = nil
+	cfg.DefaultToolProfile = "missing"
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected error for undefined default profile, got nil")
+	}
+}
+
+func TestAPIKeyFile(t *testing.T) {
+	keyFile := filepath.Join(t.TempDir(), "bocha.key")
+	if err := os.WriteFile(keyFile, []byte("  key-from-file-1234\n"), 0600); err != nil {
+		t.Fatalf("Failed to write key file: %v", err)
+	}
+
+	t.Setenv("CONFIG_FILE", "")
+	t.Setenv("BOCHA_API_KEY", "key-from-env")
+	t.Setenv("BOCHA_API_KEY_FILE", keyFile)
+
+	cfg := New()
+	if cfg.BochaAPIKey != "key-from-file-1234" {
+		t.Errorf("Expected key file to take precedence, got %q", cfg.BochaAPIKey)
+	}
+
+	// A missing key file falls back to the environment
+	t.Setenv("BOCHA_API_KEY_FILE", filepath.Join(t.TempDir(), "missing.key"))
+	cfg = New()
+	if cfg.BochaAPIKey != "key-from-env" {
+		t.Errorf("Expected key from environment, got %q", cfg.BochaAPIKey)
+	}
+
+	// The other providers read key files the same way
+	t.Setenv("BRAVE_API_KEY_FILE", keyFile)
+	cfg = New()
+	if cfg.BraveAPIKey != "key-from-file-1234" {
+		t.Errorf("Expected Brave key from file, got %q", cfg.BraveAPIKey)
+	}
+}
+
+func TestContainerConfig(t *testing.T) {
+	tempDir := t.TempDir()
+	containerPath := filepath.Join(tempDir, "config.yaml")
+
+	origPaths := ContainerConfigPaths
+	defer func() { ContainerConfigPaths = origPaths }()
+	ContainerConfigPaths = []string{filepath.Join(tempDir, "missing.yaml"), containerPath}
+
+	t.Setenv("CONFIG_FILE", "")
+	t.Setenv("BOCHA_API_KEY", "")
+	t.Setenv("BOCHA_API_KEY_FILE", "")
+	t.Setenv("SERVER_NAME", "")
+
+	// No mounted file: environment only
+	cfg := New()
+	if cfg.Source != "" {
+		t.Errorf("Expected no config source, got %q", cfg.Source)
+	}
+
+	// Mounted file is picked up automatically
+	content := `
+bocha_api_key: "container-api-key-1234"
+server_name: "Container Server"
+`
+	if err := os.WriteFile(containerPath, []byte(content), 0600); err != nil {
+		t.Fatalf("Failed to write container config: %v", err)
+	}
+	cfg = New()
+	if cfg.Source != containerPath {
+		t.Errorf("Expected source %q, got %q", containerPath, cfg.Source)
+	}
+	if cfg.ServerName != "Container Server" {
+		t.Errorf("Expected server name from container config, got %q", cfg.ServerName)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected valid container config, got %v", err)
+	}
+
+	summary := cfg.Summary()
+	if summary["api_key"] != "cont...1234" {
+		t.Errorf("Expected masked API key in summary, got %v", summary["api_key"])
+	}
+	if summary["source"] != containerPath {
+		t.Errorf("Expected source in summary, got %v", summary["source"])
+	}
+
+	// A broken mounted file fails validation
+	if err := os.WriteFile(containerPath, []byte("invalid: yaml: content: - -"), 0600); err != nil {
+		t.Fatalf("Failed to write container config: %v", err)
+	}
+	cfg = New()
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected error for broken container config, got nil")
+	}
+}
+
+func TestFetchConfig(t *testing.T) {
+	t.Setenv("CONFIG_FILE", "")
+	t.Setenv("BOCHA_API_KEY", "test-api-key")
+	t.Setenv("FETCH_ENABLED", "")
+	t.Setenv("FETCH_MAX_PAGES_PER_MINUTE", "")
+
+	cfg := New()
+	if cfg.FetchEnabled {
+		t.Error("Expected fetching to be disabled by default")
+	}
+	if cfg.FetchMaxPagesPerMinute != 30 || cfg.FetchTimeout != 15*time.Second || cfg.FetchPageMaxChars != 20000 {
+		t.Errorf("Unexpected fetch defaults: %d pages, %s, %d characters", cfg.FetchMaxPagesPerMinute, cfg.FetchTimeout, cfg.FetchPageMaxChars)
+	}
+
+	t.Setenv("FETCH_ENABLED", "true")
+	t.Setenv("FETCH_MAX_PAGES_PER_MINUTE", "5")
+	cfg = New()
+	if !cfg.FetchEnabled || cfg.FetchMaxPagesPerMinute != 5 {
+		t.Errorf("Expected fetching enabled with 5 pages per minute, got %v and %d", cfg.FetchEnabled, cfg.FetchMaxPagesPerMinute)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected valid fetch config, got %v", err)
+	}
+
+	cfg.FetchMaxBytesPerMinute = 0
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected error for a zero byte budget, got nil")
+	}
+
+	cfg = New()
+	cfg.FetchPageMaxChars = 0
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected error for a zero fetch_page text limit, got nil")
+	}
+
+	cfg = New()
+	if cfg.FetchArchiveFallback || cfg.FetchArchiveURL != "https://archive.org/wayback/available" {
+		t.Errorf("Unexpected archive defaults: %v, %q", cfg.FetchArchiveFallback, cfg.FetchArchiveURL)
+	}
+	cfg.FetchArchiveFallback = true
+	cfg.FetchArchiveURL = "archive.org/wayback/available"
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected error for an archive URL without a scheme, got nil")
+	}
+}
+
+func TestDownloadConfig(t *testing.T) {
+	t.Setenv("CONFIG_FILE", "")
+	t.Setenv("BOCHA_API_KEY", "test-api-key")
+	t.Setenv("FETCH_ENABLED", "true")
+	t.Setenv("DOWNLOAD_DIR", "/var/lib/mcp-search/downloads")
+	t.Setenv("DOWNLOAD_MAX_BYTES", "")
+
+	cfg := New()
+	if cfg.DownloadDir != "/var/lib/mcp-search/downloads" || cfg.DownloadMaxBytes != 10*1024*1024 {
+		t.Errorf("Unexpected download settings: %q, %d bytes", cfg.DownloadDir, cfg.DownloadMaxBytes)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected valid download config, got %v", err)
+	}
+
+	cfg.DownloadMaxBytes = cfg.DownloadMaxTotalBytes + 1
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected error for a download limit above the directory limit, got nil")
+	}
+
+	cfg = New()
+	cfg.DownloadDir = "downloads"
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected error for a relative download directory, got nil")
+	}
+
+	cfg = New()
+	cfg.FetchEnabled = false
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected error for downloads without fetching, got nil")
+	}
+}
+
+func TestScreenshotConfig(t *testing.T) {
+	t.Setenv("CONFIG_FILE", "")
+	t.Setenv("BOCHA_API_KEY", "test-api-key")
+	t.Setenv("SCREENSHOT_URL", "https://localhost:3000/screenshot")
+	t.Setenv("SCREENSHOT_TIMEOUT", "")
+
+	cfg := New()
+	if cfg.ScreenshotURL != "https://localhost:3000/screenshot" || cfg.ScreenshotTimeout != 30*time.Second {
+		t.Errorf("Unexpected screenshot settings: %q, %s", cfg.ScreenshotURL, cfg.ScreenshotTimeout)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected valid screenshot config, got %v", err)
+	}
+
+	cfg.ScreenshotURL = "https://renderer.internal.example/screenshot"
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected error for a renderer host missing from the upstream allowlist, got nil")
+	}
+	cfg.UpstreamAllowlist = []string{"renderer.internal.example"}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected an allowlisted renderer to be valid, got %v", err)
+	}
+
+	cfg.ScreenshotMaxBytes = 0
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected error for a zero image limit, got nil")
+	}
+}
+
+func TestFetchAllowlist(t *testing.T) {
+	t.Setenv("CONFIG_FILE", "")
+	t.Setenv("BOCHA_API_KEY", "test-api-key")
+	t.Setenv("FETCH_ALLOWLIST", "10.0.5.0/24, 192.168.1.20")
+
+	cfg := New()
+	if len(cfg.FetchAllowlist) != 2 || cfg.FetchAllowlist[1] != "192.168.1.20" {
+		t.Errorf("Expected 2 allowlist entries, got %v", cfg.FetchAllowlist)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected valid allowlist, got %v", err)
+	}
+
+	cfg.FetchAllowlist = []string{"intranet.example.com"}
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected error for a hostname in the allowlist, got nil")
+	}
+}
+
+func TestSemanticCacheConfig(t *testing.T) {
+	t.Setenv("CONFIG_FILE", "")
+	t.Setenv("BOCHA_API_KEY", "test-api-key")
+	t.Setenv("SEMANTIC_CACHE_THRESHOLD", "")
+
+	cfg := New()
+	if cfg.SemanticCacheThreshold != 0 || cfg.SemanticCacheTTL != time.Hour {
+		t.Errorf("Unexpected semantic cache defaults: threshold %v, ttl %s", cfg.SemanticCacheThreshold, cfg.SemanticCacheTTL)
+	}
+
+	t.Setenv("SEMANTIC_CACHE_THRESHOLD", "0.95")
+	cfg = New()
+	if cfg.SemanticCacheThreshold != 0.95 {
+		t.Errorf("Expected threshold 0.95, got %v", cfg.SemanticCacheThreshold)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected valid semantic cache config, got %v", err)
+	}
+
+	// Looser thresholds need a real embedding model
+	cfg.SemanticCacheThreshold = 0.85
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected error for a loose threshold with the built-in embedder, got nil")
+	}
+	t.Setenv("SEMANTIC_CACHE_EMBEDDING_URL", "https://127.0.0.1:8080/v1/embeddings")
+	t.Setenv("SEMANTIC_CACHE_EMBEDDING_MODEL", "text-embedding-3-small")
+	cfg = New()
+	cfg.SemanticCacheThreshold = 0.85
+	if cfg.SemanticCacheEmbeddingModel != "text-embedding-3-small" {
+		t.Errorf("Expected the embedding model from the environment, got %q", cfg.SemanticCacheEmbeddingModel)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected a loose threshold to be valid with an embedding endpoint, got %v", err)
+	}
+	cfg.SemanticCacheEmbeddingURL = "ftp://embeddings.example.com"
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected error for an invalid embedding URL, got nil")
+	}
+
+	cfg.SemanticCacheThreshold = 1.5
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected error for a threshold above 1, got nil")
+	}
+}
+
+func TestResultFooterConfig(t *testing.T) {
+	t.Setenv("CONFIG_FILE", "")
+	t.Setenv("BOCHA_API_KEY", "test-api-key")
+	t.Setenv("HIDE_RESULT_FOOTER", "")
+	t.Setenv("SEARCH_COST", "")
+
+	cfg := New()
+	if cfg.HideResultFooter || cfg.SearchCost != 0 {
+		t.Errorf("Expected the footer shown without a cost by default, got hide %v, cost %v", cfg.HideResultFooter, cfg.SearchCost)
+	}
+
+	t.Setenv("HIDE_RESULT_FOOTER", "true")
+	t.Setenv("SEARCH_COST", "0.01")
+	cfg = New()
+	if !cfg.HideResultFooter || cfg.SearchCost != 0.01 {
+		t.Errorf("Expected hidden footer and cost 0.01, got hide %v, cost %v", cfg.HideResultFooter, cfg.SearchCost)
+	}
+
+	cfg.SearchCost = -1
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected error for a negative search cost, got nil")
+	}
+}
+
+func TestHideImagesConfig(t *testing.T) {
+	t.Setenv("CONFIG_FILE", "")
+	t.Setenv("HIDE_IMAGES", "")
+	if New().HideImages {
+		t.Error("Expected images to be included by default")
+	}
+
+	t.Setenv("HIDE_IMAGES", "true")
+	if !New().HideImages {
+		t.Error("Expected HIDE_IMAGES to hide images")
+	}
+}
+
+func TestStructuredSchemaVersionConfig(t *testing.T) {
+	t.Setenv("CONFIG_FILE", "")
+	t.Setenv("STRUCTURED_SCHEMA_VERSION", "")
+	if version := New().StructuredSchemaVersion; version != 0 {
+		t.Errorf("Expected the current schema by default, got %d", version)
+	}
+
+	t.Setenv("STRUCTURED_SCHEMA_VERSION", "1")
+	cfg := New()
+	if cfg.StructuredSchemaVersion != 1 {
+		t.Errorf("Expected schema version 1, got %d", cfg.StructuredSchemaVersion)
+	}
+
+	cfg.StructuredSchemaVersion = 3
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected error for an unknown schema version, got nil")
+	}
+}
+
+func TestDenylistConfig(t *testing.T) {
+	t.Setenv("CONFIG_FILE", "")
+	t.Setenv("BOCHA_API_KEY", "test-api-key")
+	t.Setenv("DENYLIST", "spam.example, content-farm.test")
+	t.Setenv("DENYLIST_FEED_URL", "https://lists.example.com/denylist.txt?token=secret")
+	t.Setenv("DENYLIST_REFRESH", "")
+	t.Setenv("UPSTREAM_ALLOWLIST", "lists.example.com")
+
+	cfg := New()
+	if len(cfg.Denylist) != 2 || cfg.DenylistRefresh != 15*time.Minute {
+		t.Errorf("Unexpected denylist config: %v, refresh %s", cfg.Denylist, cfg.DenylistRefresh)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected valid denylist config, got %v", err)
+	}
+	if summary := cfg.Summary(); summary["denylist"] != "2 entries, feed lists.example.com" {
+		t.Errorf("Expected the feed host only in the summary, got %v", summary["denylist"])
+	}
+
+	cfg.DenylistRefresh = time.Second
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected error for a refresh interval under a minute, got nil")
+	}
+	cfg.DenylistRefresh = time.Hour
+	cfg.DenylistFeedURL = "ftp://lists.example.com/denylist.txt"
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected error for a non-http feed URL, got nil")
+	}
+
+	// A tampered feed could hide any result, so it is held to the upstream rules
+	cfg.DenylistFeedURL = "http://lists.example.com/denylist.txt"
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "ALLOW_INSECURE_HTTP") {
+		t.Errorf("Expected error for a plain http feed URL, got %v", err)
+	}
+	cfg.AllowInsecureHTTP = true
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected a plain http feed URL to be allowed with ALLOW_INSECURE_HTTP, got %v", err)
+	}
+	cfg.DenylistFeedURL = "https://lists.other.example/denylist.txt"
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "UPSTREAM_ALLOWLIST") {
+		t.Errorf("Expected error for a feed host that is not allowlisted, got %v", err)
+	}
+}
+
+func TestValidateSafety(t *testing.T) {
+	cfg := &Config{
+		BochaAPIKey:         "test-api-key",
+		BochaAPIBaseURL:     "https://api.bochaai.com/v1/web-search",
+		SafetyMode:          SafetyRemove,
+		SafetyClassifierURL: "https://classifier.internal/v1/classify",
+		UpstreamAllowlist:   []string{"classifier.internal"},
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected no error for a valid safety config, got %v", err)
+	}
+	if summary := cfg.Summary(); summary["safety"] != "remove, classifier classifier.internal" {
+		t.Errorf("Unexpected safety summary: %v", summary["safety"])
+	}
+
+	cfg.SafetyMode = "block"
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected error for an unknown safety mode, got nil")
+	}
+	cfg.SafetyMode = SafetyFlag
+	cfg.SafetyClassifierURL = "classifier.internal"
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected error for a classifier URL without a scheme, got nil")
+	}
+
+	// Result snippets are sent to the classifier, so it is held to the upstream rules
+	cfg.SafetyClassifierURL = "http://classifier.internal/v1/classify"
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "ALLOW_INSECURE_HTTP") {
+		t.Errorf("Expected error for a plain http classifier URL, got %v", err)
+	}
+	cfg.SafetyClassifierURL = "https://classifier.other.example/v1/classify"
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "UPSTREAM_ALLOWLIST") {
+		t.Errorf("Expected error for a classifier host that is not allowlisted, got %v", err)
+	}
+}
+
+func TestValidateMonitors(t *testing.T) {
+	cfg := &Config{
+		BochaAPIKey:       "test-api-key",
+		BochaAPIBaseURL:   "https://api.bochaai.com/v1/web-search",
+		Monitors:          []StandingQuery{{Name: "releases", Query: "go release", Interval: "30m"}},
+		WebhookURL:        "https://hooks.slack.com/services/T000/B000/XXXX",
+		WebhookFormat:     WebhookFormatSlack,
+		UpstreamAllowlist: []string{"hooks.slack.com"},
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected no error for valid monitors, got %v", err)
+	}
+	if summary := cfg.Summary(); summary["webhook"] != "slack hooks.slack.com" {
+		t.Errorf("Expected the webhook host only in the summary, got %v", summary["webhook"])
+	}
+
+	testCases := []struct {
+		name   string
+		mutate func(c *Config)
+	}{
+		{"missing query", func(c *Config) { c.Monitors = append(c.Monitors, StandingQuery{Name: "empty"}) }},
+		{"duplicate name", func(c *Config) { c.Monitors = append(c.Monitors, StandingQuery{Name: "releases", Query: "q"}) }},
+		{"short interval", func(c *Config) { c.Monitors[0].Interval = "10s" }},
+		{"bad interval", func(c *Config) { c.Monitors[0].Interval = "often" }},
+		{"bad format", func(c *Config) { c.WebhookFormat = "teams" }},
+		{"bad url", func(c *Config) { c.WebhookURL = "ftp://example.com" }},
+		{"plain http url", func(c *Config) { c.WebhookURL = "http://hooks.slack.com/services/T000/B000/XXXX" }},
+		{"url not allowlisted", func(c *Config) { c.WebhookURL = "https://hooks.other.example/notify" }},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := *cfg
+			c.Monitors = append([]StandingQuery(nil), cfg.Monitors...)
+			tc.mutate(&c)
+			if err := c.Validate(); err == nil {
+				t.Error("Expected error, got nil")
+			}
+		})
+	}
+}
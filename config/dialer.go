@@ -0,0 +1,152 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+)
+
+// Supported values for DialIPFamily
+const (
+	// IPFamilyIPv4 only connects to IPv4 addresses
+	IPFamilyIPv4 = "ipv4"
+	// IPFamilyIPv6 only connects to IPv6 addresses
+	IPFamilyIPv6 = "ipv6"
+	// IPFamilyPreferIPv4 tries IPv4 addresses first and IPv6 ones after them
+	IPFamilyPreferIPv4 = "prefer-ipv4"
+)
+
+// DialFunc dials a network connection, as http.Transport.DialContext does
+type DialFunc func(ctx context.Context, network, address string) (net.Conn, error)
+
+// DialContext returns the function the upstream API client connects with, or
+// nil for the default dialer when neither DialIPFamily nor DialLocalAddress is
+// set. DialLocalAddress is an IP address or the name of a network interface,
+// whose addresses are used.
+func (c *Config) DialContext() (DialFunc, error) {
+	switch c.DialIPFamily {
+	case "", IPFamilyIPv4, IPFamilyIPv6, IPFamilyPreferIPv4:
+	default:
+		return nil, fmt.Errorf("invalid DIAL_IP_FAMILY %q, must be one of: %s, %s, %s", c.DialIPFamily, IPFamilyIPv4, IPFamilyIPv6, IPFamilyPreferIPv4)
+	}
+	if c.DialIPFamily == "" && c.DialLocalAddress == "" {
+		return nil, nil
+	}
+
+	d := &dialer{family: c.DialIPFamily, resolver: net.DefaultResolver}
+	if c.DialLocalAddress != "" {
+		local, err := localAddresses(c.DialLocalAddress)
+		if err != nil {
+			return nil, err
+		}
+		d.local = local
+	}
+	return d.DialContext, nil
+}
+
+// localAddresses returns the addresses named by DialLocalAddress: the IP
+// address itself, or the addresses of the interface with that name
+func localAddresses(name string) ([]net.IP, error) {
+	if ip := net.ParseIP(name); ip != nil {
+		return []net.IP{ip}, nil
+	}
+	iface, err := net.InterfaceByName(name)
+	if err != nil {
+		return nil, fmt.Errorf("invalid DIAL_LOCAL_ADDRESS %q, must be an IP address or a network interface: %w", name, err)
+	}
+	addrs, err := iface.Addrs()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the addresses of interface %s: %w", name, err)
+	}
+	var local []net.IP
+	for _, addr := range addrs {
+		// Link-local addresses cannot reach the API
+		if ipNet, ok := addr.(*net.IPNet); ok && !ipNet.IP.IsLinkLocalUnicast() {
+			local = append(local, ipNet.IP)
+		}
+	}
+	if len(local) == 0 {
+		return nil, fmt.Errorf("invalid DIAL_LOCAL_ADDRESS %q: the interface has no usable address", name)
+	}
+	return local, nil
+}
+
+// dialer connects to the addresses of a host in the order of its IP family
+// preference, from a local address of the same family when local is set
+type dialer struct {
+	family   string
+	local    []net.IP
+	resolver *net.Resolver
+}
+
+// DialContext connects to address, trying each of its IP addresses in turn
+func (d *dialer) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+	addrs, err := d.resolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+
+	var firstErr error
+	for _, ip := range d.order(addrs) {
+		nd := net.Dialer{Timeout: 10 * time.Second, KeepAlive: 30 * time.Second}
+		if d.local != nil {
+			local := d.localFor(ip)
+			if local == nil {
+				continue
+			}
+			nd.LocalAddr = &net.TCPAddr{IP: local}
+		}
+		conn, err := nd.DialContext(ctx, network, net.JoinHostPort(ip.String(), port))
+		if err == nil {
+			return conn, nil
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	if firstErr == nil {
+		firstErr = fmt.Errorf("no address of %s matches the IP family or local address configured", host)
+	}
+	return nil, firstErr
+}
+
+// order returns the addresses to try, leaving out those of an excluded family
+// and putting IPv4 first when it is preferred
+func (d *dialer) order(addrs []net.IPAddr) []net.IP {
+	var v4, v6 []net.IP
+	for _, addr := range addrs {
+		if addr.IP.To4() != nil {
+			v4 = append(v4, addr.IP)
+		} else {
+			v6 = append(v6, addr.IP)
+		}
+	}
+	switch d.family {
+	case IPFamilyIPv4:
+		return v4
+	case IPFamilyIPv6:
+		return v6
+	case IPFamilyPreferIPv4:
+		return append(v4, v6...)
+	}
+	ordered := make([]net.IP, 0, len(addrs))
+	for _, addr := range addrs {
+		ordered = append(ordered, addr.IP)
+	}
+	return ordered
+}
+
+// localFor returns the local address of the same IP family as remote
+func (d *dialer) localFor(remote net.IP) net.IP {
+	for _, local := range d.local {
+		if (local.To4() != nil) == (remote.To4() != nil) {
+			return local
+		}
+	}
+	return nil
+}
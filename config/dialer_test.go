@@ -0,0 +1,104 @@
+package config
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDialContext(t *testing.T) {
+	dial, err := (&Config{}).DialContext()
+	if err != nil || dial != nil {
+		t.Errorf("Expected the default dialer without options, got %v", err)
+	}
+
+	if _, err := (&Config{DialIPFamily: "ipv5"}).DialContext(); err == nil || !strings.Contains(err.Error(), "invalid DIAL_IP_FAMILY") {
+		t.Errorf("Expected error for an unknown IP family, got %v", err)
+	}
+	if _, err := (&Config{DialLocalAddress: "no-such-interface0"}).DialContext(); err == nil || !strings.Contains(err.Error(), "invalid DIAL_LOCAL_ADDRESS") {
+		t.Errorf("Expected error for an unknown interface, got %v", err)
+	}
+}
+
+func TestDialContext_Connect(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {}))
+	defer server.Close()
+	address := server.Listener.Addr().String()
+
+	dial, err := (&Config{DialIPFamily: IPFamilyIPv4, DialLocalAddress: "127.0.0.1"}).DialContext()
+	if err != nil {
+		t.Fatalf("DialContext returned an error: %v", err)
+	}
+	conn, err := dial(context.Background(), "tcp", address)
+	if err != nil {
+		t.Fatalf("Expected a connection over IPv4, got %v", err)
+	}
+	if local := conn.LocalAddr().(*net.TCPAddr); !local.IP.Equal(net.IPv4(127, 0, 0, 1)) {
+		t.Errorf("Expected the connection from 127.0.0.1, got %s", local)
+	}
+	conn.Close()
+
+	dial, _ = (&Config{DialIPFamily: IPFamilyIPv6}).DialContext()
+	if _, err := dial(context.Background(), "tcp", address); err == nil || !strings.Contains(err.Error(), "no address of 127.0.0.1") {
+		t.Errorf("Expected no IPv6 address to connect to, got %v", err)
+	}
+}
+
+func TestDialer_Order(t *testing.T) {
+	addrs := []net.IPAddr{{IP: net.ParseIP("2001:db8::1")}, {IP: net.ParseIP("192.0.2.1")}, {IP: net.ParseIP("2001:db8::2")}}
+	tests := []struct {
+		family   string
+		expected string
+	}{
+		{"", "2001:db8::1,192.0.2.1,2001:db8::2"},
+		{IPFamilyIPv4, "192.0.2.1"},
+		{IPFamilyIPv6, "2001:db8::1,2001:db8::2"},
+		{IPFamilyPreferIPv4, "192.0.2.1,2001:db8::1,2001:db8::2"},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, ip := range (&dialer{family: tt.family}).order(addrs) {
+			got = append(got, ip.String())
+		}
+		if strings.Join(got, ",") != tt.expected {
+			t.Errorf("Expected %s for %q, got %v", tt.expected, tt.family, got)
+		}
+	}
+}
+
+func TestDialer_LocalFor(t *testing.T) {
+	d := &dialer{local: []net.IP{net.ParseIP("2001:db8::10"), net.ParseIP("192.0.2.10")}}
+	if local := d.localFor(net.ParseIP("198.51.100.1")); !local.Equal(net.ParseIP("192.0.2.10")) {
+		t.Errorf("Expected the IPv4 local address, got %s", local)
+	}
+	if local := d.localFor(net.ParseIP("2001:db8::1")); !local.Equal(net.ParseIP("2001:db8::10")) {
+		t.Errorf("Expected the IPv6 local address, got %s", local)
+	}
+	if local := (&dialer{local: []net.IP{net.ParseIP("192.0.2.10")}}).localFor(net.ParseIP("2001:db8::1")); local != nil {
+		t.Errorf("Expected no local address of the other family, got %s", local)
+	}
+}
+
+func TestLocalAddresses_Interface(t *testing.T) {
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		t.Skipf("Cannot list interfaces: %v", err)
+	}
+	for _, iface := range ifaces {
+		if iface.Flags&net.FlagLoopback == 0 {
+			continue
+		}
+		local, err := localAddresses(iface.Name)
+		if err != nil {
+			t.Fatalf("localAddresses returned an error for %s: %v", iface.Name, err)
+		}
+		if len(local) == 0 || !local[0].IsLoopback() {
+			t.Errorf("Expected the loopback addresses of %s, got %v", iface.Name, local)
+		}
+		return
+	}
+	t.Skip("No loopback interface")
+}
@@ -0,0 +1,193 @@
+package config
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"com.moguyn/mcp-go-search/jsonpath"
+)
+
+// GenericMapping maps a generic provider's JSON response onto search results.
+// Results is the path of the array of results in the response; the other
+// paths are looked up in each result, except Total, which is looked up in the
+// response. Empty optional paths leave their field empty.
+type GenericMapping struct {
+	Results  string `yaml:"results" json:"results"`
+	Title    string `yaml:"title" json:"title"`
+	URL      string `yaml:"url" json:"url"`
+	Snippet  string `yaml:"snippet" json:"snippet"`
+	Date     string `yaml:"date" json:"date"`
+	SiteName string `yaml:"site_name" json:"site_name"`
+	Total    string `yaml:"total" json:"total"`
+}
+
+// DefaultGenericMapping is the mapping of a response shaped like
+// {"results": [{"title": ..., "url": ..., "snippet": ...}]}
+var DefaultGenericMapping = GenericMapping{
+	Results: "results",
+	Title:   "title",
+	URL:     "url",
+	Snippet: "snippet",
+}
+
+// merge overrides the paths that other sets
+func (m *GenericMapping) merge(other GenericMapping) {
+	m.combine(other, true)
+}
+
+// fill sets the paths m leaves empty from other
+func (m *GenericMapping) fill(other GenericMapping) {
+	m.combine(other, false)
+}
+
+// combine takes the paths other sets, either all of them or only those m
+// leaves empty
+func (m *GenericMapping) combine(other GenericMapping, override bool) {
+	for _, field := range []struct {
+		value *string
+		other string
+	}{
+		{&m.Results, other.Results},
+		{&m.Title, other.Title},
+		{&m.URL, other.URL},
+		{&m.Snippet, other.Snippet},
+		{&m.Date, other.Date},
+		{&m.SiteName, other.SiteName},
+		{&m.Total, other.Total},
+	} {
+		if field.other != "" && (override || *field.value == "") {
+			*field.value = field.other
+		}
+	}
+}
+
+// Paths returns the compiled paths of the mapping, keyed by field name as
+// written in the configuration file
+func (m GenericMapping) Paths() (map[string]jsonpath.Path, error) {
+	paths := make(map[string]jsonpath.Path, 7)
+	for _, field := range []struct {
+		name string
+		expr string
+	}{
+		{"results", m.Results},
+		{"title", m.Title},
+		{"url", m.URL},
+		{"snippet", m.Snippet},
+		{"date", m.Date},
+		{"site_name", m.SiteName},
+		{"total", m.Total},
+	} {
+		if field.expr == "" {
+			continue
+		}
+		path, err := jsonpath.Compile(field.expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid generic_mapping %s: %w", field.name, err)
+		}
+		paths[field.name] = path
+	}
+	return paths, nil
+}
+
+// GenericEndpoint is how the generic provider calls its API and reads the
+// response. The key goes in the AuthParam query parameter when there is one,
+// otherwise in AuthHeader, as a bearer token when that is Authorization.
+// CountParam is empty when the count is not sent.
+type GenericEndpoint struct {
+	Method     string
+	URL        string
+	AuthHeader string
+	AuthParam  string
+	QueryParam string
+	CountParam string
+	Mapping    GenericMapping
+}
+
+// GenericEndpoint returns the generic provider's settings: those configured,
+// completed from the OpenAPI operation when there is one, then the defaults
+func (c *Config) GenericEndpoint() (GenericEndpoint, error) {
+	e := GenericEndpoint{
+		Method:     strings.ToUpper(c.GenericMethod),
+		URL:        c.GenericAPIURL,
+		AuthHeader: c.GenericAuthHeader,
+		AuthParam:  c.GenericAuthParam,
+		QueryParam: c.GenericQueryParam,
+		CountParam: c.GenericCountParam,
+		Mapping:    c.GenericMapping,
+	}
+	if c.GenericOpenAPISpec != "" {
+		derived, err := loadOpenAPIEndpoint(c.GenericOpenAPISpec, c.GenericOpenAPIOperation)
+		if err != nil {
+			return GenericEndpoint{}, err
+		}
+		e.fill(derived)
+	}
+
+	// A request template is a body, which needs a POST
+	defaults := GenericEndpoint{
+		Method:     http.MethodGet,
+		AuthHeader: "Authorization",
+		QueryParam: "q",
+		CountParam: "count",
+		Mapping:    DefaultGenericMapping,
+	}
+	if _, ok := c.RequestTemplates[ProviderGeneric]; ok {
+		defaults.Method = http.MethodPost
+	}
+	e.fill(defaults)
+	if e.CountParam == "-" {
+		e.CountParam = ""
+	}
+	return e, nil
+}
+
+// fill sets the settings e leaves empty from other. The key's header and
+// parameter are taken together, so a configured one isn't joined by another.
+func (e *GenericEndpoint) fill(other GenericEndpoint) {
+	for _, field := range []struct {
+		value *string
+		other string
+	}{
+		{&e.Method, other.Method},
+		{&e.URL, other.URL},
+		{&e.QueryParam, other.QueryParam},
+		{&e.CountParam, other.CountParam},
+	} {
+		if *field.value == "" {
+			*field.value = field.other
+		}
+	}
+	if e.AuthHeader == "" && e.AuthParam == "" {
+		e.AuthHeader, e.AuthParam = other.AuthHeader, other.AuthParam
+	}
+	e.Mapping.fill(other.Mapping)
+}
+
+// validateGeneric checks the generic provider's resolved settings
+func (c *Config) validateGeneric() error {
+	e, err := c.GenericEndpoint()
+	if err != nil {
+		return err
+	}
+	if e.URL == "" {
+		return fmt.Errorf("GENERIC_API_URL is required when SEARCH_PROVIDER is %q", ProviderGeneric)
+	}
+	if err := CheckUpstreamURL(e.URL, c.UpstreamAllowlist, c.AllowInsecureHTTP); err != nil {
+		return fmt.Errorf("invalid GENERIC_API_URL: %w", err)
+	}
+	switch e.Method {
+	case http.MethodGet:
+		if _, ok := c.RequestTemplates[ProviderGeneric]; ok {
+			return fmt.Errorf("the request template for %s needs GENERIC_METHOD=POST", ProviderGeneric)
+		}
+	case http.MethodPost:
+	default:
+		return fmt.Errorf("invalid GENERIC_METHOD %q, must be one of: GET, POST", e.Method)
+	}
+	if e.QueryParam == "" {
+		return fmt.Errorf("GENERIC_QUERY_PARAM cannot be empty")
+	}
+	_, err = e.Mapping.Paths()
+	return err
+}
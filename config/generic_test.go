@@ -0,0 +1,69 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenericProviderConfig(t *testing.T) {
+	t.Setenv("SEARCH_PROVIDER", "generic")
+	t.Setenv("BOCHA_API_KEY", "")
+	t.Setenv("BOCHA_API_KEY_FILE", "")
+	t.Setenv("GENERIC_API_URL", "https://search.corp.example/api/search")
+	t.Setenv("GENERIC_API_KEY", "")
+	t.Setenv("GENERIC_API_KEY_FILE", "")
+	t.Setenv("GENERIC_METHOD", "")
+	t.Setenv("GENERIC_AUTH_HEADER", "")
+	t.Setenv("GENERIC_AUTH_PARAM", "")
+	t.Setenv("GENERIC_QUERY_PARAM", "")
+	t.Setenv("GENERIC_COUNT_PARAM", "-")
+	t.Setenv("GENERIC_OPENAPI_SPEC", "")
+	t.Setenv("GENERIC_OPENAPI_OPERATION", "")
+	t.Setenv("UPSTREAM_ALLOWLIST", "search.corp.example")
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	data := "generic_mapping:\n  results: \"$.hits.hits\"\n  url: \"_source.link\"\n  date: \"_source.published\"\n"
+	if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	t.Setenv("CONFIG_FILE", path)
+	cfg := New()
+	endpoint, err := cfg.GenericEndpoint()
+	if err != nil {
+		t.Fatalf("GenericEndpoint returned an error: %v", err)
+	}
+	if endpoint.Method != "GET" || endpoint.QueryParam != "q" || endpoint.CountParam != "" || endpoint.AuthHeader != "Authorization" {
+		t.Errorf("Unexpected defaults: %+v", endpoint)
+	}
+	expected := GenericMapping{Results: "$.hits.hits", Title: "title", URL: "_source.link", Snippet: "snippet", Date: "_source.published"}
+	if endpoint.Mapping != expected {
+		t.Errorf("Expected the file's paths over the defaults, got %+v", endpoint.Mapping)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected no error for a valid generic provider, got %v", err)
+	}
+
+	cfg.RequestTemplates = map[string]string{ProviderGeneric: `{"q": {{json .Query}}}`}
+	if endpoint, _ := cfg.GenericEndpoint(); endpoint.Method != "POST" {
+		t.Errorf("Expected a POST with a request template, got %s", endpoint.Method)
+	}
+	cfg.GenericMethod = "get"
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "needs GENERIC_METHOD=POST") {
+		t.Errorf("Expected error for a GET with a request template, got %v", err)
+	}
+	cfg.GenericMethod, cfg.RequestTemplates = "DELETE", nil
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "invalid GENERIC_METHOD") {
+		t.Errorf("Expected error for an unsupported method, got %v", err)
+	}
+	cfg.GenericMethod = ""
+
+	cfg.GenericMapping.Snippet = "_source.body["
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "invalid generic_mapping snippet") {
+		t.Errorf("Expected error for an invalid path, got %v", err)
+	}
+	cfg.GenericAPIURL = ""
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "GENERIC_API_URL is required") {
+		t.Errorf("Expected error without an API URL, got %v", err)
+	}
+}
@@ -0,0 +1,80 @@
+package config
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+)
+
+// GRPCTLSConfig returns the TLS settings of the gRPC provider's connection:
+// the upstream TLS policy of TLSConfig, with GRPCCAFile as the trusted roots,
+// GRPCCertFile and GRPCKeyFile as the client certificate and GRPCServerName
+// as the name the service's certificate is checked against, when they are set
+func (c *Config) GRPCTLSConfig() (*tls.Config, error) {
+	tlsConfig, err := c.TLSConfig()
+	if err != nil {
+		return nil, err
+	}
+	tlsConfig.ServerName = c.GRPCServerName
+	if c.GRPCCAFile != "" {
+		pem, err := os.ReadFile(c.GRPCCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read GRPC_CA_FILE: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("GRPC_CA_FILE %s contains no PEM certificates", c.GRPCCAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if c.GRPCCertFile != "" || c.GRPCKeyFile != "" {
+		if c.GRPCCertFile == "" || c.GRPCKeyFile == "" {
+			return nil, fmt.Errorf("GRPC_CERT_FILE and GRPC_KEY_FILE must be set together")
+		}
+		cert, err := tls.LoadX509KeyPair(c.GRPCCertFile, c.GRPCKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load the gRPC client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
+}
+
+// validateGRPC checks the gRPC provider's target, TLS settings and metadata
+func (c *Config) validateGRPC() error {
+	if c.GRPCTarget == "" {
+		return fmt.Errorf("GRPC_TARGET is required when SEARCH_PROVIDER is %q", ProviderGRPC)
+	}
+	host, port, err := net.SplitHostPort(c.GRPCTarget)
+	if err != nil || host == "" || port == "" || strings.Contains(c.GRPCTarget, "/") {
+		return fmt.Errorf("invalid GRPC_TARGET %q, must be host:port", c.GRPCTarget)
+	}
+
+	// The key goes to the target as it would to an API URL, so the same hosts
+	// are allowed, and plaintext needs the same opt-in as plain http
+	scheme := "https"
+	if c.GRPCPlaintext {
+		scheme = "http"
+	}
+	if err := CheckUpstreamURL(scheme+"://"+c.GRPCTarget, c.UpstreamAllowlist, c.AllowInsecureHTTP); err != nil {
+		return fmt.Errorf("invalid GRPC_TARGET: %w", err)
+	}
+	if !c.GRPCPlaintext {
+		if _, err := c.GRPCTLSConfig(); err != nil {
+			return err
+		}
+	}
+
+	for key := range c.GRPCMetadata {
+		if key == "" {
+			return fmt.Errorf("GRPC_METADATA keys cannot be empty")
+		}
+		if strings.HasPrefix(strings.ToLower(key), "grpc-") {
+			return fmt.Errorf("invalid GRPC_METADATA key %q, grpc- keys are reserved", key)
+		}
+	}
+	return nil
+}
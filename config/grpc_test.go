@@ -0,0 +1,102 @@
+package config
+
+import (
+	"crypto/tls"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGRPCProvider(t *testing.T) {
+	t.Setenv("CONFIG_FILE", "")
+	t.Setenv("SEARCH_PROVIDER", "grpc")
+	t.Setenv("BOCHA_API_KEY", "")
+	t.Setenv("BOCHA_API_KEY_FILE", "")
+	t.Setenv("GRPC_TARGET", "search.internal:443")
+	t.Setenv("GRPC_API_KEY", "grpc-key")
+	t.Setenv("GRPC_API_KEY_FILE", "")
+	t.Setenv("GRPC_METADATA", "x-tenant=search, x-team=web")
+	t.Setenv("GRPC_CA_FILE", "")
+	t.Setenv("GRPC_CERT_FILE", "")
+	t.Setenv("GRPC_KEY_FILE", "")
+	t.Setenv("GRPC_SERVER_NAME", "")
+	t.Setenv("GRPC_PLAINTEXT", "")
+	t.Setenv("UPSTREAM_ALLOWLIST", "search.internal")
+	cfg := New()
+	if cfg.GRPCTarget != "search.internal:443" || cfg.GRPCAPIKey != "grpc-key" || cfg.GRPCPlaintext {
+		t.Errorf("Unexpected gRPC settings: %q, %q, plaintext %v", cfg.GRPCTarget, cfg.GRPCAPIKey, cfg.GRPCPlaintext)
+	}
+	if len(cfg.GRPCMetadata) != 2 || cfg.GRPCMetadata["x-team"] != "web" {
+		t.Errorf("Expected the metadata from GRPC_METADATA, got %v", cfg.GRPCMetadata)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	cfg.GRPCMetadata["grpc-timeout"] = "1S"
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "reserved") {
+		t.Errorf("Expected error for a reserved metadata key, got %v", err)
+	}
+	delete(cfg.GRPCMetadata, "grpc-timeout")
+
+	cfg.GRPCTarget = "https://search.internal"
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "host:port") {
+		t.Errorf("Expected error for a URL target, got %v", err)
+	}
+	cfg.GRPCTarget = ""
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "GRPC_TARGET is required") {
+		t.Errorf("Expected error without a target, got %v", err)
+	}
+}
+
+func TestGRPCPlaintext(t *testing.T) {
+	cfg := &Config{GRPCTarget: "127.0.0.1:9090", GRPCPlaintext: true}
+	if err := cfg.validateGRPC(); err == nil || !strings.Contains(err.Error(), "ALLOW_INSECURE_HTTP") {
+		t.Errorf("Expected plaintext to need ALLOW_INSECURE_HTTP, got %v", err)
+	}
+	cfg.AllowInsecureHTTP = true
+	if err := cfg.validateGRPC(); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	cfg.GRPCTarget = "10.0.0.5:9090"
+	if err := cfg.validateGRPC(); err == nil || !strings.Contains(err.Error(), "UPSTREAM_ALLOWLIST") {
+		t.Error("Expected error for a target outside the allowlist, got nil")
+	}
+}
+
+func TestGRPCTLSConfig(t *testing.T) {
+	dir := t.TempDir()
+	caFile := filepath.Join(dir, "ca.pem")
+	if err := os.WriteFile(caFile, []byte("not a certificate"), 0600); err != nil {
+		t.Fatalf("Failed to write CA file: %v", err)
+	}
+
+	cfg := &Config{GRPCServerName: "search.internal", TLSMinVersion: TLSVersion13}
+	tlsConfig, err := cfg.GRPCTLSConfig()
+	if err != nil {
+		t.Fatalf("GRPCTLSConfig returned an error: %v", err)
+	}
+	if tlsConfig.ServerName != "search.internal" || tlsConfig.RootCAs != nil {
+		t.Errorf("Expected the server name and system roots, got %+v", tlsConfig)
+	}
+	if tlsConfig.MinVersion != tls.VersionTLS13 {
+		t.Errorf("Expected the upstream TLS policy, got minimum version %x", tlsConfig.MinVersion)
+	}
+
+	cfg.GRPCCAFile = caFile
+	if _, err := cfg.GRPCTLSConfig(); err == nil || !strings.Contains(err.Error(), "no PEM certificates") {
+		t.Errorf("Expected error for a CA file without certificates, got %v", err)
+	}
+	cfg.GRPCCAFile = filepath.Join(dir, "missing.pem")
+	if _, err := cfg.GRPCTLSConfig(); err == nil {
+		t.Error("Expected error for a missing CA file, got nil")
+	}
+
+	cfg.GRPCCAFile = ""
+	cfg.GRPCCertFile = filepath.Join(dir, "client.pem")
+	if _, err := cfg.GRPCTLSConfig(); err == nil || !strings.Contains(err.Error(), "set together") {
+		t.Errorf("Expected error for a certificate without a key, got %v", err)
+	}
+}
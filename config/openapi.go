@@ -0,0 +1,282 @@
+package config
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Names an OpenAPI operation's fields are recognized by, most likely first
+var (
+	openAPIQueryNames    = []string{"q", "query", "question", "search", "term", "keywords", "text"}
+	openAPICountNames    = []string{"count", "limit", "size", "num", "per_page", "page_size", "pagesize", "max_results", "top_k"}
+	openAPITitleNames    = []string{"title", "name", "headline"}
+	openAPIURLNames      = []string{"url", "link", "href", "uri"}
+	openAPISnippetNames  = []string{"snippet", "description", "summary", "content", "abstract", "text"}
+	openAPIDateNames     = []string{"date", "published", "published_at", "publishedDate", "created_at", "updated_at", "timestamp"}
+	openAPISiteNameNames = []string{"site_name", "siteName", "site", "source", "domain"}
+	openAPITotalNames    = []string{"total", "total_results", "totalResults", "total_count", "totalCount", "found"}
+)
+
+// openAPIDocument is the part of an OpenAPI 3 document used to derive the
+// generic provider's settings
+type openAPIDocument struct {
+	Servers    []openAPIServer            `yaml:"servers"`
+	Paths      map[string]openAPIPathItem `yaml:"paths"`
+	Security   []map[string][]string      `yaml:"security"`
+	Components struct {
+		Schemas         map[string]*openAPISchema        `yaml:"schemas"`
+		Parameters      map[string]openAPIParameter      `yaml:"parameters"`
+		SecuritySchemes map[string]openAPISecurityScheme `yaml:"securitySchemes"`
+	} `yaml:"components"`
+}
+
+// openAPIServer is a base URL of the API
+type openAPIServer struct {
+	URL string `yaml:"url"`
+}
+
+// openAPIPathItem holds the operations of a path the provider can call
+type openAPIPathItem struct {
+	Servers    []openAPIServer    `yaml:"servers"`
+	Parameters []openAPIParameter `yaml:"parameters"`
+	Get        *openAPIOperation  `yaml:"get"`
+	Post       *openAPIOperation  `yaml:"post"`
+}
+
+// openAPIOperation is a single API operation
+type openAPIOperation struct {
+	OperationID string                 `yaml:"operationId"`
+	Servers     []openAPIServer        `yaml:"servers"`
+	Parameters  []openAPIParameter     `yaml:"parameters"`
+	Security    *[]map[string][]string `yaml:"security"`
+	RequestBody *struct {
+		Content map[string]openAPIMediaType `yaml:"content"`
+	} `yaml:"requestBody"`
+	Responses map[string]struct {
+		Content map[string]openAPIMediaType `yaml:"content"`
+	} `yaml:"responses"`
+}
+
+// openAPIParameter is a parameter of an operation, or a reference to one
+type openAPIParameter struct {
+	Ref  string `yaml:"$ref"`
+	Name string `yaml:"name"`
+	In   string `yaml:"in"`
+}
+
+// openAPIMediaType is the schema of a request or response body
+type openAPIMediaType struct {
+	Schema *openAPISchema `yaml:"schema"`
+}
+
+// openAPISchema is the part of a JSON schema used to find fields, or a
+// reference to one
+type openAPISchema struct {
+	Ref        string                    `yaml:"$ref"`
+	Type       string                    `yaml:"type"`
+	Properties map[string]*openAPISchema `yaml:"properties"`
+	Items      *openAPISchema            `yaml:"items"`
+}
+
+// openAPISecurityScheme is how the API expects its key
+type openAPISecurityScheme struct {
+	Type   string `yaml:"type"`
+	Scheme string `yaml:"scheme"`
+	Name   string `yaml:"name"`
+	In     string `yaml:"in"`
+}
+
+// loadOpenAPIEndpoint derives the generic provider's settings from an
+// operation of the OpenAPI document at path. Settings it cannot derive are
+// left empty.
+func loadOpenAPIEndpoint(path, operationID string) (GenericEndpoint, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return GenericEndpoint{}, fmt.Errorf("failed to read OpenAPI document: %w", err)
+	}
+	// YAML is a superset of JSON, so this reads both
+	var doc openAPIDocument
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return GenericEndpoint{}, fmt.Errorf("failed to parse OpenAPI document %s: %w", path, err)
+	}
+	return doc.endpoint(operationID)
+}
+
+// endpoint derives the settings of the operation with the given ID
+func (d *openAPIDocument) endpoint(operationID string) (GenericEndpoint, error) {
+	if operationID == "" {
+		return GenericEndpoint{}, fmt.Errorf("GENERIC_OPENAPI_OPERATION is required with an OpenAPI document")
+	}
+	for route, item := range d.Paths {
+		for _, candidate := range []struct {
+			method    string
+			operation *openAPIOperation
+		}{
+			{http.MethodGet, item.Get},
+			{http.MethodPost, item.Post},
+		} {
+			if candidate.operation == nil || candidate.operation.OperationID != operationID {
+				continue
+			}
+			if strings.Contains(route, "{") {
+				return GenericEndpoint{}, fmt.Errorf("OpenAPI operation %q has path parameters, which are not supported", operationID)
+			}
+			return d.operationEndpoint(route, item, candidate.method, candidate.operation), nil
+		}
+	}
+	return GenericEndpoint{}, fmt.Errorf("OpenAPI operation %q not found", operationID)
+}
+
+// operationEndpoint derives the settings of an operation
+func (d *openAPIDocument) operationEndpoint(route string, item openAPIPathItem, method string, op *openAPIOperation) GenericEndpoint {
+	e := GenericEndpoint{Method: method}
+
+	// The most specific absolute server URL applies; a relative one can't be called
+	for _, servers := range [][]openAPIServer{op.Servers, item.Servers, d.Servers} {
+		if len(servers) > 0 {
+			if base := servers[0].URL; strings.HasPrefix(base, "https://") || strings.HasPrefix(base, "http://") {
+				e.URL = strings.TrimSuffix(base, "/") + route
+			}
+			break
+		}
+	}
+
+	// The query and count are query parameters of a GET and body fields of a POST
+	var fields []string
+	if method == http.MethodGet {
+		for _, param := range append(item.Parameters, op.Parameters...) {
+			if param.Ref != "" {
+				param = d.Components.Parameters[strings.TrimPrefix(param.Ref, "#/components/parameters/")]
+			}
+			if param.In == "query" {
+				fields = append(fields, param.Name)
+			}
+		}
+	} else if op.RequestBody != nil {
+		if body := d.resolve(op.RequestBody.Content["application/json"].Schema); body != nil {
+			for name := range body.Properties {
+				fields = append(fields, name)
+			}
+		}
+	}
+	e.QueryParam = pickName(fields, openAPIQueryNames)
+	e.CountParam = pickName(fields, openAPICountNames)
+
+	security := d.Security
+	if op.Security != nil {
+		security = *op.Security
+	}
+	for _, requirement := range security {
+		for name := range requirement {
+			scheme := d.Components.SecuritySchemes[name]
+			switch {
+			case scheme.Type == "apiKey" && scheme.In == "header":
+				e.AuthHeader = scheme.Name
+			case scheme.Type == "apiKey" && scheme.In == "query":
+				e.AuthParam = scheme.Name
+			case scheme.Type == "http" && strings.EqualFold(scheme.Scheme, "bearer"):
+				e.AuthHeader = "Authorization"
+			}
+		}
+		if e.AuthHeader != "" || e.AuthParam != "" {
+			break
+		}
+	}
+
+	for _, status := range []string{"200", "2XX", "default"} {
+		if response, ok := op.Responses[status]; ok {
+			e.Mapping = d.responseMapping(d.resolve(response.Content["application/json"].Schema))
+			break
+		}
+	}
+	return e
+}
+
+// responseMapping finds the result list in a response schema, the first array
+// of objects within three levels, and the fields of its items
+func (d *openAPIDocument) responseMapping(schema *openAPISchema) GenericMapping {
+	var m GenericMapping
+	if schema == nil {
+		return m
+	}
+
+	var item *openAPISchema
+	if schema.Type == "array" {
+		m.Results, item = "$", d.resolve(schema.Items)
+	} else {
+		m.Results, item = d.findArray(schema, "", 3)
+		var totalFields []string
+		for name, property := range schema.Properties {
+			if property = d.resolve(property); property != nil && (property.Type == "integer" || property.Type == "number") {
+				totalFields = append(totalFields, name)
+			}
+		}
+		m.Total = pickName(totalFields, openAPITotalNames)
+	}
+	if item == nil {
+		return m
+	}
+
+	var itemFields []string
+	for name := range item.Properties {
+		itemFields = append(itemFields, name)
+	}
+	m.Title = pickName(itemFields, openAPITitleNames)
+	m.URL = pickName(itemFields, openAPIURLNames)
+	m.Snippet = pickName(itemFields, openAPISnippetNames)
+	m.Date = pickName(itemFields, openAPIDateNames)
+	m.SiteName = pickName(itemFields, openAPISiteNameNames)
+	return m
+}
+
+// findArray returns the path of the first array of objects below schema, in
+// property name order, and the schema of its items
+func (d *openAPIDocument) findArray(schema *openAPISchema, prefix string, depth int) (string, *openAPISchema) {
+	if depth == 0 {
+		return "", nil
+	}
+	names := make([]string, 0, len(schema.Properties))
+	for name := range schema.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		property := d.resolve(schema.Properties[name])
+		if property == nil {
+			continue
+		}
+		if item := d.resolve(property.Items); property.Type == "array" && item != nil && len(item.Properties) > 0 {
+			return prefix + name, item
+		}
+		if path, item := d.findArray(property, prefix+name+".", depth-1); item != nil {
+			return path, item
+		}
+	}
+	return "", nil
+}
+
+// resolve follows a schema's reference to the document's components
+func (d *openAPIDocument) resolve(schema *openAPISchema) *openAPISchema {
+	for i := 0; schema != nil && schema.Ref != "" && i < 10; i++ {
+		schema = d.Components.Schemas[strings.TrimPrefix(schema.Ref, "#/components/schemas/")]
+	}
+	return schema
+}
+
+// pickName returns the first of the recognized names that is among fields, or
+// "" when none is
+func pickName(fields []string, recognized []string) string {
+	for _, name := range recognized {
+		for _, field := range fields {
+			if strings.EqualFold(field, name) {
+				return field
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,147 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const openAPISpec = `openapi: 3.0.3
+info: {title: Intranet search, version: "1.0"}
+servers:
+  - url: https://search.corp.example/api/v2
+security:
+  - apiKey: []
+paths:
+  /documents/search:
+    get:
+      operationId: searchDocuments
+      parameters:
+        - $ref: "#/components/parameters/Query"
+        - {name: limit, in: query, schema: {type: integer}}
+        - {name: X-Tenant, in: header, schema: {type: string}}
+      responses:
+        "200":
+          content:
+            application/json:
+              schema: {$ref: "#/components/schemas/SearchResponse"}
+  /documents/{id}:
+    get:
+      operationId: getDocument
+      responses: {"200": {description: A document}}
+  /ask:
+    post:
+      operationId: ask
+      security:
+        - bearer: []
+      requestBody:
+        content:
+          application/json:
+            schema:
+              type: object
+              properties:
+                question: {type: string}
+                text: {type: string}
+                top_k: {type: integer}
+      responses:
+        "200":
+          content:
+            application/json:
+              schema:
+                type: array
+                items:
+                  type: object
+                  properties:
+                    name: {type: string}
+                    href: {type: string}
+components:
+  parameters:
+    Query: {name: query, in: query, required: true, schema: {type: string}}
+  securitySchemes:
+    apiKey: {type: apiKey, in: query, name: api_key}
+    bearer: {type: http, scheme: bearer}
+  schemas:
+    SearchResponse:
+      type: object
+      properties:
+        total_results: {type: integer}
+        data:
+          type: object
+          properties:
+            documents:
+              type: array
+              items: {$ref: "#/components/schemas/Document"}
+    Document:
+      type: object
+      properties:
+        title: {type: string}
+        link: {type: string}
+        abstract: {type: string}
+        published_at: {type: string}
+`
+
+func TestGenericEndpoint_OpenAPI(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "openapi.yaml")
+	if err := os.WriteFile(path, []byte(openAPISpec), 0600); err != nil {
+		t.Fatalf("Failed to write OpenAPI document: %v", err)
+	}
+	cfg := &Config{
+		SearchProvider:          ProviderGeneric,
+		GenericOpenAPISpec:      path,
+		GenericOpenAPIOperation: "searchDocuments",
+		UpstreamAllowlist:       []string{"search.corp.example"},
+	}
+	endpoint, err := cfg.GenericEndpoint()
+	if err != nil {
+		t.Fatalf("GenericEndpoint returned an error: %v", err)
+	}
+	expected := GenericEndpoint{
+		Method:     "GET",
+		URL:        "https://search.corp.example/api/v2/documents/search",
+		AuthParam:  "api_key",
+		QueryParam: "query",
+		CountParam: "limit",
+		Mapping: GenericMapping{
+			Results: "data.documents",
+			Title:   "title",
+			URL:     "link",
+			Snippet: "abstract",
+			Date:    "published_at",
+			Total:   "total_results",
+		},
+	}
+	if endpoint != expected {
+		t.Errorf("Expected %+v, got %+v", expected, endpoint)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected no error for a derived endpoint, got %v", err)
+	}
+
+	// Configured settings win over derived ones
+	cfg.GenericOpenAPIOperation, cfg.GenericAuthHeader, cfg.GenericMapping.Title = "ask", "X-Api-Key", "label"
+	endpoint, err = cfg.GenericEndpoint()
+	if err != nil {
+		t.Fatalf("GenericEndpoint returned an error: %v", err)
+	}
+	if endpoint.Method != "POST" || endpoint.URL != "https://search.corp.example/api/v2/ask" || endpoint.QueryParam != "question" || endpoint.CountParam != "top_k" {
+		t.Errorf("Unexpected request settings: %+v", endpoint)
+	}
+	if endpoint.AuthHeader != "X-Api-Key" || endpoint.AuthParam != "" {
+		t.Errorf("Expected the configured auth header alone, got %+v", endpoint)
+	}
+	if m := endpoint.Mapping; m.Results != "$" || m.Title != "label" || m.URL != "href" || m.Snippet != "snippet" {
+		t.Errorf("Unexpected mapping: %+v", m)
+	}
+
+	for operation, message := range map[string]string{
+		"getDocument":  "has path parameters",
+		"listProjects": `OpenAPI operation "listProjects" not found`,
+		"":             "GENERIC_OPENAPI_OPERATION is required",
+	} {
+		cfg.GenericOpenAPIOperation = operation
+		if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), message) {
+			t.Errorf("Expected error containing %q for %q, got %v", message, operation, err)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package config
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+
+	"com.moguyn/mcp-go-search/params"
+	"com.moguyn/mcp-go-search/privacy"
+)
+
+// durationPattern matches Go duration strings such as "15s" or "1h30m"
+const durationPattern = `^([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+$`
+
+// schemaEnums lists the allowed values of fields that take one of a fixed set
+var schemaEnums = map[string][]string{
+	"startup_check":    {StartupCheckOff, StartupCheckFail, StartupCheckGate},
+	"log_level":        {"debug", "info", "error"},
+	"query_log_policy": {"full", "redact", "hash", "hide"},
+	"tls_min_version":  {TLSVersion12, TLSVersion13},
+	"safety_mode":      {SafetyOff, SafetyFlag, SafetyRemove},
+	"webhook_format":   {WebhookFormatGeneric, WebhookFormatSlack},
+	"freshness":        params.Freshness,
+	"format":           {"text", "table"},
+}
+
+// schemaItemEnums lists the allowed values of list fields' items
+var schemaItemEnums = map[string][]string{
+	"result_pipeline": append(append([]string(nil), DefaultResultPipeline...), StageNone),
+	"pii_scrub":       append(append([]string(nil), privacy.Kinds...), "none"),
+}
+
+// Schema returns a JSON Schema describing the configuration file, generated
+// from the yaml tags of Config. Editors use it to validate and complete
+// configuration files.
+func Schema() map[string]interface{} {
+	schema := objectSchema(reflect.TypeOf(Config{}))
+	schema["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	schema["title"] = "mcp-go-search configuration"
+	return schema
+}
+
+// SchemaJSON returns Schema as indented JSON
+func SchemaJSON() ([]byte, error) {
+	return json.MarshalIndent(Schema(), "", "  ")
+}
+
+// objectSchema describes a struct, with one property per yaml-tagged field
+func objectSchema(t reflect.Type) map[string]interface{} {
+	properties := make(map[string]interface{})
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		if !field.IsExported() || name == "" || name == "-" {
+			continue
+		}
+		property := typeSchema(field.Type)
+		switch {
+		case strings.HasSuffix(field.Name, "Str"), name == "interval":
+			// Durations are written as strings such as "15s"
+			property["pattern"] = durationPattern
+		case schemaEnums[name] != nil:
+			property["enum"] = schemaEnums[name]
+		case schemaItemEnums[name] != nil:
+			property["items"] = map[string]interface{}{"type": "string", "enum": schemaItemEnums[name]}
+		}
+		properties[name] = property
+	}
+	return map[string]interface{}{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+}
+
+// typeSchema describes a Go type
+func typeSchema(t reflect.Type) map[string]interface{} {
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.Slice:
+		return map[string]interface{}{"type": "array", "items": typeSchema(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": typeSchema(t.Elem())}
+	case reflect.Struct:
+		return objectSchema(t)
+	case reflect.Pointer:
+		return typeSchema(t.Elem())
+	default:
+		return map[string]interface{}{}
+	}
+}
@@ -0,0 +1,69 @@
+package config
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestSchema(t *testing.T) {
+	schema := Schema()
+	if schema["$schema"] == nil {
+		t.Error("Expected the schema to declare its dialect")
+	}
+	properties := schema["properties"].(map[string]interface{})
+
+	tests := []struct {
+		name     string
+		wantType string
+	}{
+		{"bocha_api_key", "string"},
+		{"allow_insecure_http", "boolean"},
+		{"cache_max_entries", "integer"},
+		{"semantic_cache_threshold", "number"},
+		{"denylist", "array"},
+		{"tool_profiles", "object"},
+		{"monitors", "array"},
+		{"http_timeout", "string"},
+	}
+	for _, tt := range tests {
+		property, ok := properties[tt.name].(map[string]interface{})
+		if !ok {
+			t.Errorf("Expected property %s", tt.name)
+			continue
+		}
+		if property["type"] != tt.wantType {
+			t.Errorf("Expected %s to be %s, got %v", tt.name, tt.wantType, property["type"])
+		}
+	}
+
+	for _, hidden := range []string{"-", "Source", "ClientToken", "loadErr"} {
+		if _, ok := properties[hidden]; ok {
+			t.Errorf("Expected %s not to be in the schema", hidden)
+		}
+	}
+	if properties["http_timeout"].(map[string]interface{})["pattern"] != durationPattern {
+		t.Error("Expected http_timeout to have the duration pattern")
+	}
+	if enum := properties["log_level"].(map[string]interface{})["enum"]; len(enum.([]string)) != 3 {
+		t.Errorf("Expected 3 log levels, got %v", enum)
+	}
+
+	monitors := properties["monitors"].(map[string]interface{})["items"].(map[string]interface{})
+	if _, ok := monitors["properties"].(map[string]interface{})["interval"]; !ok {
+		t.Error("Expected standing query items to describe interval")
+	}
+}
+
+func TestSchemaJSON(t *testing.T) {
+	data, err := SchemaJSON()
+	if err != nil {
+		t.Fatalf("SchemaJSON returned an error: %v", err)
+	}
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Expected valid JSON, got %v", err)
+	}
+	if decoded["additionalProperties"] != false {
+		t.Error("Expected unknown top-level keys to be rejected")
+	}
+}
@@ -0,0 +1,95 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"text/template"
+
+	"com.moguyn/mcp-go-search/params"
+)
+
+// TemplateProviders are the providers whose search request body can be
+// replaced with a template, those sending a JSON body
+var TemplateProviders = []string{ProviderBaidu, ProviderBocha, ProviderGeneric, ProviderJina}
+
+// RequestTemplateData is what a request body template is executed with: the
+// normalized search parameters, whether the pro endpoint or the AI search was
+// asked for and the agent an agent search was asked of
+type RequestTemplateData struct {
+	params.Search
+	Pro      bool
+	AISearch bool
+	Agent    string
+}
+
+// templateFuncs are the functions request body templates can call besides the
+// built-in ones; json writes a value as JSON, quoting and escaping strings
+var templateFuncs = template.FuncMap{
+	"json": func(v any) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+}
+
+// RequestTemplate returns the parsed request body template of a provider, or
+// nil when RequestTemplates has none for it
+func (c *Config) RequestTemplate(provider string) (*template.Template, error) {
+	text, ok := c.RequestTemplates[provider]
+	if !ok {
+		return nil, nil
+	}
+	tmpl, err := template.New(provider).Funcs(templateFuncs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid request template for %s: %w", provider, err)
+	}
+	return tmpl, nil
+}
+
+// RenderRequestBody executes a request body template and checks that the
+// result is valid JSON
+func RenderRequestBody(tmpl *template.Template, data RequestTemplateData) ([]byte, error) {
+	var b bytes.Buffer
+	if err := tmpl.Execute(&b, data); err != nil {
+		return nil, fmt.Errorf("failed to render request template for %s: %w", tmpl.Name(), err)
+	}
+	if !json.Valid(b.Bytes()) {
+		return nil, fmt.Errorf("request template for %s did not produce valid JSON", tmpl.Name())
+	}
+	return b.Bytes(), nil
+}
+
+// validateRequestTemplates checks that every template belongs to a provider
+// with a JSON request body and renders valid JSON for a sample search
+func (c *Config) validateRequestTemplates() error {
+	providers := make([]string, 0, len(c.RequestTemplates))
+	for provider := range c.RequestTemplates {
+		providers = append(providers, provider)
+	}
+	sort.Strings(providers)
+
+	sample := RequestTemplateData{Search: params.Search{
+		Query:     `example "query"`,
+		Freshness: params.DefaultFreshness,
+		Count:     params.DefaultCount,
+	}}
+	for _, provider := range providers {
+		known := false
+		for _, name := range TemplateProviders {
+			known = known || name == provider
+		}
+		if !known {
+			return fmt.Errorf("invalid request template for %q, must be one of: %s", provider, strings.Join(TemplateProviders, ", "))
+		}
+		tmpl, err := c.RequestTemplate(provider)
+		if err != nil {
+			return err
+		}
+		if _, err := RenderRequestBody(tmpl, sample); err != nil {
+			return err
+		}
+	}
+	return nil
+}
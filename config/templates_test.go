@@ -0,0 +1,49 @@
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"com.moguyn/mcp-go-search/params"
+)
+
+func TestRequestTemplate(t *testing.T) {
+	cfg := &Config{RequestTemplates: map[string]string{
+		ProviderBocha: `{"q": {{json .Query}}, "size": {{.Count}}{{if ne .Freshness "noLimit"}}, "since": {{json .Freshness}}{{end}}, "pro": {{.Pro}}}`,
+	}}
+	if tmpl, err := cfg.RequestTemplate(ProviderJina); tmpl != nil || err != nil {
+		t.Errorf("Expected no template for jina, got %v (%v)", tmpl, err)
+	}
+	tmpl, err := cfg.RequestTemplate(ProviderBocha)
+	if err != nil {
+		t.Fatalf("RequestTemplate returned an error: %v", err)
+	}
+
+	body, err := RenderRequestBody(tmpl, RequestTemplateData{Search: params.Search{Query: `say "hi"`, Freshness: "week", Count: 5}, Pro: true})
+	if err != nil {
+		t.Fatalf("RenderRequestBody returned an error: %v", err)
+	}
+	expected := `{"q": "say \"hi\"", "size": 5, "since": "week", "pro": true}`
+	if string(body) != expected {
+		t.Errorf("Expected %s, got %s", expected, body)
+	}
+}
+
+func TestRequestTemplate_Errors(t *testing.T) {
+	tests := []struct {
+		provider string
+		template string
+		expected string
+	}{
+		{ProviderBrave, `{}`, `invalid request template for "brave"`},
+		{ProviderBocha, `{"q": {{json .Query}`, "invalid request template for bocha"},
+		{ProviderBocha, `{"q": {{json .Question}}}`, "failed to render request template for bocha"},
+		{ProviderBaidu, `{"q": {{.Query}}}`, "request template for baidu did not produce valid JSON"},
+	}
+	for _, tt := range tests {
+		cfg := &Config{RequestTemplates: map[string]string{tt.provider: tt.template}}
+		if err := cfg.validateRequestTemplates(); err == nil || !strings.Contains(err.Error(), tt.expected) {
+			t.Errorf("Expected %q for %s, got %v", tt.expected, tt.template, err)
+		}
+	}
+}
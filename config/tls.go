@@ -0,0 +1,47 @@
+package config
+
+import (
+	"crypto/tls"
+	"fmt"
+	"strings"
+)
+
+// Supported values for TLSMinVersion
+const (
+	TLSVersion12 = "1.2"
+	TLSVersion13 = "1.3"
+)
+
+// TLSConfig returns the TLS settings for the upstream API client: the minimum
+// version (TLS 1.2 unless TLSMinVersion says otherwise) and, when
+// TLSCipherSuites is set, the allowed TLS 1.2 cipher suites
+func (c *Config) TLSConfig() (*tls.Config, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	switch c.TLSMinVersion {
+	case "", TLSVersion12:
+	case TLSVersion13:
+		tlsConfig.MinVersion = tls.VersionTLS13
+	default:
+		return nil, fmt.Errorf("invalid TLS_MIN_VERSION %q, must be one of: %s, %s", c.TLSMinVersion, TLSVersion12, TLSVersion13)
+	}
+
+	for _, name := range c.TLSCipherSuites {
+		id, ok := cipherSuiteID(strings.TrimSpace(name))
+		if !ok {
+			return nil, fmt.Errorf("unknown or insecure TLS cipher suite %q", name)
+		}
+		tlsConfig.CipherSuites = append(tlsConfig.CipherSuites, id)
+	}
+	return tlsConfig, nil
+}
+
+// cipherSuiteID looks up a secure cipher suite by its standard name, such as
+// TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384
+func cipherSuiteID(name string) (uint16, bool) {
+	for _, suite := range tls.CipherSuites() {
+		if strings.EqualFold(suite.Name, name) {
+			return suite.ID, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,56 @@
+package config
+
+import (
+	"crypto/tls"
+	"testing"
+)
+
+func TestTLSConfig(t *testing.T) {
+	cfg := &Config{}
+	tlsConfig, err := cfg.TLSConfig()
+	if err != nil {
+		t.Fatalf("TLSConfig returned an error: %v", err)
+	}
+	if tlsConfig.MinVersion != tls.VersionTLS12 || tlsConfig.CipherSuites != nil {
+		t.Errorf("Expected TLS 1.2 with default cipher suites, got %+v", tlsConfig)
+	}
+
+	cfg.TLSCipherSuites = []string{"TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384", " tls_ecdhe_rsa_with_aes_256_gcm_sha384 "}
+	tlsConfig, err = cfg.TLSConfig()
+	if err != nil {
+		t.Fatalf("TLSConfig returned an error: %v", err)
+	}
+	if len(tlsConfig.CipherSuites) != 2 || tlsConfig.CipherSuites[1] != tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384 {
+		t.Errorf("Expected the two cipher suites, got %v", tlsConfig.CipherSuites)
+	}
+
+	cfg.TLSCipherSuites = []string{"TLS_RSA_WITH_RC4_128_SHA"}
+	if _, err := cfg.TLSConfig(); err == nil {
+		t.Error("Expected error for an insecure cipher suite, got nil")
+	}
+
+	cfg = &Config{TLSMinVersion: TLSVersion13}
+	if tlsConfig, err := cfg.TLSConfig(); err != nil || tlsConfig.MinVersion != tls.VersionTLS13 {
+		t.Errorf("Expected TLS 1.3 minimum, got %+v, %v", tlsConfig, err)
+	}
+	cfg.TLSMinVersion = "1.0"
+	if _, err := cfg.TLSConfig(); err == nil {
+		t.Error("Expected error for TLS 1.0, got nil")
+	}
+}
+
+func TestValidateTLS(t *testing.T) {
+	cfg := &Config{
+		BochaAPIKey:     "test-api-key",
+		BochaAPIBaseURL: "https://api.bochaai.com/v1/web-search",
+		TLSMinVersion:   TLSVersion13,
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected no error for TLS 1.3, got %v", err)
+	}
+
+	cfg.TLSCipherSuites = []string{"TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384"}
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected error for cipher suites with TLS 1.3, got nil")
+	}
+}
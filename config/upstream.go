@@ -0,0 +1,69 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+)
+
+// KnownUpstreamHosts are the hosts provider base URLs may point at without
+// being listed in UpstreamAllowlist
+var KnownUpstreamHosts = []string{"api.bochaai.com", "api.search.brave.com", "www.googleapis.com", "qianfan.baidubce.com", "s.jina.ai", "r.jina.ai", "export.arxiv.org", "eutils.ncbi.nlm.nih.gov", "api.stackexchange.com", "hn.algolia.com"}
+
+// CheckUpstreamURL returns an error unless rawURL is an https URL on a known
+// host or a host matching allowlist. Allowlist entries are host names, where
+// "*.example.com" matches subdomains. Loopback hosts need no allowlist entry,
+// so a local fake API works. Plain http is refused unless allowInsecure is set,
+// since the API key would be sent unencrypted.
+func CheckUpstreamURL(rawURL string, allowlist []string, allowInsecure bool) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("failed to parse %q: %w", rawURL, err)
+	}
+	switch {
+	case u.Scheme == "http" && !allowInsecure:
+		return fmt.Errorf("%q uses plain http, which would send the API key unencrypted; use https or set ALLOW_INSECURE_HTTP=1", u.Redacted())
+	case u.Scheme != "https" && u.Scheme != "http":
+		return fmt.Errorf("%q must be an https URL", u.Redacted())
+	}
+	if u.User != nil {
+		return fmt.Errorf("%q must not contain credentials", u.Redacted())
+	}
+	host := strings.ToLower(u.Hostname())
+	if host == "" {
+		return fmt.Errorf("%q has no host", u.Redacted())
+	}
+	if isLoopback(host) {
+		return nil
+	}
+
+	for _, known := range KnownUpstreamHosts {
+		if host == known {
+			return nil
+		}
+	}
+	for _, entry := range allowlist {
+		if matchesHost(host, strings.ToLower(strings.TrimSpace(entry))) {
+			return nil
+		}
+	}
+	return fmt.Errorf("host %q is not a known API host; add it to UPSTREAM_ALLOWLIST if it is trusted", host)
+}
+
+// matchesHost reports whether host matches an allowlist entry
+func matchesHost(host, entry string) bool {
+	if wildcard, ok := strings.CutPrefix(entry, "*."); ok {
+		return strings.HasSuffix(host, "."+wildcard)
+	}
+	return host == entry
+}
+
+// isLoopback reports whether host names the local machine
+func isLoopback(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"com.moguyn/mcp-go-search/fixtures"
+)
+
+// newFakeServer builds the HTTP server for the fakeserver subcommand from its arguments
+func newFakeServer(args []string, output io.Writer) (*http.Server, error) {
+	flags := flag.NewFlagSet("fakeserver", flag.ContinueOnError)
+	flags.SetOutput(output)
+	addr := flags.String("addr", "127.0.0.1:8089", "address to listen on")
+	latency := flags.Duration("latency", 0, "delay added to every response")
+	apiKey := flags.String("api-key", "", "API key required as the bearer token (any key is accepted when empty)")
+	flags.Usage = func() {
+		fmt.Fprintf(output, "Usage: %s fakeserver [flags]\n\n", "mcp-search-server")
+		fmt.Fprintln(output, "Emulates the Bocha Web Search API for offline testing. The query selects the response:")
+		fmt.Fprintln(output, "a fixture name returns that fixture, \"slow:<duration> <query>\" delays the response,")
+		fmt.Fprintln(output, "\"status:<code> <query>\" fails with that status, and anything else returns results.")
+		fmt.Fprintf(output, "Fixtures: %v\n\n", fixtures.Names())
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+
+	return &http.Server{
+		Addr: *addr,
+		Handler: &fixtures.API{
+			Latency: *latency,
+			APIKey:  *apiKey,
+		},
+		ReadHeaderTimeout: 5 * time.Second,
+	}, nil
+}
+
+// runFakeServer serves the emulated Bocha API until the process is stopped
+func runFakeServer(args []string) error {
+	logger := NewLogger("fakeserver")
+
+	srv, err := newFakeServer(args, flag.CommandLine.Output())
+	if errors.Is(err, flag.ErrHelp) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	logger.Info("Fake Bocha API listening", map[string]interface{}{
+		"addr":     srv.Addr,
+		"base_url": "http://" + srv.Addr,
+	})
+	return srv.ListenAndServe()
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"com.moguyn/mcp-go-search/fixtures"
+)
+
+func TestNewFakeServer(t *testing.T) {
+	srv, err := newFakeServer([]string{"-addr", "127.0.0.1:0", "-latency", "10ms", "-api-key", "secret"}, io.Discard)
+	if err != nil {
+		t.Fatalf("newFakeServer returned an error: %v", err)
+	}
+	if srv.Addr != "127.0.0.1:0" {
+		t.Errorf("Expected addr 127.0.0.1:0, got %s", srv.Addr)
+	}
+	api, ok := srv.Handler.(*fixtures.API)
+	if !ok || api.APIKey != "secret" || api.Latency.Milliseconds() != 10 {
+		t.Errorf("Unexpected handler configuration: %+v", srv.Handler)
+	}
+
+	if _, err := newFakeServer([]string{"-unknown"}, io.Discard); err == nil {
+		t.Error("Expected error for an unknown flag, got nil")
+	}
+}
+
+// TestEndToEndAgainstFakeServer runs the full MCP server against the emulated
+// API and calls the search tool through the MCP protocol
+func TestEndToEndAgainstFakeServer(t *testing.T) {
+	api := &fixtures.API{APIKey: "fake-api-key-for-testing"}
+	upstream := httptest.NewServer(api)
+	defer upstream.Close()
+
+	for key, value := range map[string]string{
+		"BOCHA_API_KEY":       "fake-api-key-for-testing",
+		"BOCHA_API_BASE_URL":  upstream.URL,
+		"ALLOW_INSECURE_HTTP": "1",
+		"HTTP_TIMEOUT":        "5s",
+		"CONFIG_FILE":         "",
+		"DATA_DIR":            t.TempDir(),
+	} {
+		t.Setenv(key, value)
+	}
+
+	testCases := []struct {
+		query    string
+		isError  bool
+		contains string
+	}{
+		{"golang generics", false, "Tutorial: Getting started with generics"},
+		{fixtures.Empty, false, "Results: 0"},
+		{fixtures.SchemaExtra, false, "Result with unknown fields"},
+		{"status:503 golang", true, "status 503"},
+	}
+
+	origServeStdio := serveStdio
+	defer func() { serveStdio = origServeStdio }()
+	serveStdio = func(s *server.MCPServer) error {
+		for i, tc := range testCases {
+			request, _ := json.Marshal(map[string]interface{}{
+				"jsonrpc": "2.0",
+				"id":      i + 1,
+				"method":  "tools/call",
+				"params": map[string]interface{}{
+					"name":      "search",
+					"arguments": map[string]interface{}{"query": tc.query},
+				},
+			})
+			response, ok := s.HandleMessage(t.Context(), request).(mcp.JSONRPCResponse)
+			if !ok {
+				t.Errorf("Expected a JSON-RPC response for %q", tc.query)
+				continue
+			}
+			result, ok := response.Result.(*mcp.CallToolResult)
+			if !ok {
+				t.Errorf("Expected a tool result for %q, got %T", tc.query, response.Result)
+				continue
+			}
+			text := result.Content[0].(mcp.TextContent).Text
+			if result.IsError != tc.isError || !strings.Contains(text, tc.contains) {
+				t.Errorf("Query %q: expected error=%v containing %q, got error=%v: %s", tc.query, tc.isError, tc.contains, result.IsError, text)
+			}
+		}
+		return nil
+	}
+
+	if err := runServer(); err != nil {
+		t.Fatalf("runServer returned an error: %v", err)
+	}
+	if n := len(api.Requests()); n != len(testCases) {
+		t.Errorf("Expected %d upstream requests, got %d", len(testCases), n)
+	}
+}
+
+func TestFakeServerUsage(t *testing.T) {
+	var output bytes.Buffer
+	if _, err := newFakeServer([]string{"-h"}, &output); err == nil {
+		t.Error("Expected flag.ErrHelp for -h, got nil")
+	}
+	if !strings.Contains(output.String(), fixtures.Empty) {
+		t.Errorf("Expected usage to list the fixtures, got %s", output.String())
+	}
+}
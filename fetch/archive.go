@@ -0,0 +1,152 @@
+package fetch
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// waybackTimestamp is the layout of Wayback Machine snapshot times
+const waybackTimestamp = "20060102150405"
+
+// ErrNoSnapshot is returned when the archive holds no copy of a page
+var ErrNoSnapshot = errors.New("no archived snapshot found")
+
+// Snapshot is an archived copy of a page
+type Snapshot struct {
+	URL       string
+	Timestamp time.Time
+}
+
+// ArchiveEnabled reports whether pages that are unavailable may be fetched
+// from the archive instead
+func (f *Fetcher) ArchiveEnabled() bool {
+	return f.archiveURL != ""
+}
+
+// Statuses a CDN returns when the site behind it is down or unreachable
+const (
+	statusOriginDown        = 521
+	statusOriginTimeout     = 522
+	statusOriginUnreachable = 523
+)
+
+// Unavailable reports whether a fetched page is gone, refused or gated, or
+// its host is down behind a proxy, so an archived copy is worth trying
+func Unavailable(page *Page) bool {
+	if page.Gate != "" {
+		return true
+	}
+	switch page.StatusCode {
+	case http.StatusUnauthorized, http.StatusPaymentRequired, http.StatusForbidden,
+		http.StatusNotFound, http.StatusGone, http.StatusUnavailableForLegalReasons,
+		http.StatusBadGateway, http.StatusServiceUnavailable,
+		statusOriginDown, statusOriginTimeout, statusOriginUnreachable:
+		return true
+	}
+	return false
+}
+
+// Unreachable reports whether a fetch failed because the page's host couldn't
+// be reached, such as a failed DNS lookup, a refused connection or a TLS
+// failure, so an archived copy is worth trying. Blocked addresses, exhausted
+// budgets and canceled fetches don't count.
+func Unreachable(err error) bool {
+	var blockedErr *BlockedError
+	var budgetErr *BudgetExceededError
+	if err == nil || errors.As(err, &blockedErr) || errors.As(err, &budgetErr) || errors.Is(err, context.Canceled) {
+		return false
+	}
+	var dnsErr *net.DNSError
+	var opErr *net.OpError
+	var certErr *tls.CertificateVerificationError
+	var recordErr tls.RecordHeaderError
+	var alertErr tls.AlertError
+	return errors.As(err, &dnsErr) || errors.As(err, &opErr) || errors.As(err, &certErr) ||
+		errors.As(err, &recordErr) || errors.As(err, &alertErr)
+}
+
+// LatestSnapshot looks up the most recent snapshot of rawURL in the Wayback
+// Machine. The lookup goes through the guarded client but, being small and
+// always to the same host, is not charged to the budget.
+func (f *Fetcher) LatestSnapshot(ctx context.Context, rawURL string) (*Snapshot, error) {
+	if !f.ArchiveEnabled() {
+		return nil, fmt.Errorf("archive fallback is not enabled")
+	}
+	u, err := parseURL(f.archiveURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid archive URL: %w", err)
+	}
+	u.RawQuery = url.Values{"url": {rawURL}}.Encode()
+
+	resp, err := f.get(ctx, u)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query the archive: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("archive returned status code %d", resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read archive response body: %w", err)
+	}
+
+	var availability struct {
+		ArchivedSnapshots struct {
+			Closest struct {
+				Available bool   `json:"available"`
+				URL       string `json:"url"`
+				Timestamp string `json:"timestamp"`
+				Status    string `json:"status"`
+			} `json:"closest"`
+		} `json:"archived_snapshots"`
+	}
+	if err := json.Unmarshal(body, &availability); err != nil {
+		return nil, fmt.Errorf("failed to parse archive response: %w", err)
+	}
+	closest := availability.ArchivedSnapshots.Closest
+	// A snapshot of an error page is no better than the live one
+	if !closest.Available || closest.URL == "" || (closest.Status != "" && closest.Status != "200") {
+		return nil, ErrNoSnapshot
+	}
+	timestamp, err := time.Parse(waybackTimestamp, closest.Timestamp)
+	if err != nil {
+		return nil, fmt.Errorf("invalid snapshot timestamp %q", closest.Timestamp)
+	}
+	return &Snapshot{URL: rawSnapshotURL(closest.URL, closest.Timestamp), Timestamp: timestamp}, nil
+}
+
+// FetchArchived fetches the latest snapshot of rawURL, charged to the budget
+// like any page. The page keeps rawURL as its URL and records when the
+// snapshot was taken in ArchivedAt.
+func (f *Fetcher) FetchArchived(ctx context.Context, rawURL string) (*Page, error) {
+	snapshot, err := f.LatestSnapshot(ctx, rawURL)
+	if err != nil {
+		return nil, err
+	}
+	page, err := f.Fetch(ctx, snapshot.URL)
+	if err != nil {
+		return nil, err
+	}
+	page.URL = rawURL
+	page.ArchivedAt = snapshot.Timestamp
+	return page, nil
+}
+
+// rawSnapshotURL returns the address of a snapshot's original content, without
+// the toolbar and rewritten links the Wayback Machine adds to its pages
+func rawSnapshotURL(snapshotURL, timestamp string) string {
+	if strings.HasPrefix(snapshotURL, "http://web.archive.org/") {
+		snapshotURL = "https://" + strings.TrimPrefix(snapshotURL, "http://")
+	}
+	return strings.Replace(snapshotURL, "/web/"+timestamp+"/", "/web/"+timestamp+"id_/", 1)
+}
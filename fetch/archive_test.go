@@ -0,0 +1,151 @@
+package fetch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"com.moguyn/mcp-go-search/config"
+)
+
+// newArchiveServer serves the availability API and snapshots of /article;
+// every other page has no snapshot
+func newArchiveServer(t *testing.T) *httptest.Server {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/wayback/available":
+			if r.URL.Query().Get("url") != server.URL+"/article" {
+				_, _ = w.Write([]byte(`{"url": "missing", "archived_snapshots": {}}`))
+				return
+			}
+			fmt.Fprintf(w, `{"archived_snapshots": {"closest": {"status": "200", "available": true, "url": "%s/web/20240102030405/%s/article", "timestamp": "20240102030405"}}}`, server.URL, server.URL)
+		case "/web/20240102030405id_/" + server.URL + "/article":
+			w.Header().Set("Content-Type", "text/html")
+			_, _ = w.Write([]byte("<p>archived article</p>"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// archiveFetcher returns a fetcher falling back to the archive at server
+func archiveFetcher(t *testing.T, server *httptest.Server) *Fetcher {
+	fetcher, err := NewFetcher(&config.Config{
+		FetchTimeout:           5 * time.Second,
+		FetchMaxPagesPerMinute: 10,
+		FetchMaxBytesPerMinute: 1024,
+		FetchAllowlist:         []string{"127.0.0.1"},
+		FetchArchiveFallback:   true,
+		FetchArchiveURL:        server.URL + "/wayback/available",
+	})
+	if err != nil {
+		t.Fatalf("NewFetcher returned an error: %v", err)
+	}
+	return fetcher
+}
+
+func TestFetcher_FetchArchived(t *testing.T) {
+	server := newArchiveServer(t)
+	fetcher := archiveFetcher(t, server)
+
+	page, err := fetcher.Fetch(context.Background(), server.URL+"/article")
+	if err != nil {
+		t.Fatalf("Fetch returned an error: %v", err)
+	}
+	if !Unavailable(page) {
+		t.Fatalf("Expected the live page to be unavailable, got status %d", page.StatusCode)
+	}
+
+	page, err = fetcher.FetchArchived(context.Background(), server.URL+"/article")
+	if err != nil {
+		t.Fatalf("FetchArchived returned an error: %v", err)
+	}
+	if page.URL != server.URL+"/article" || string(page.Body) != "<p>archived article</p>" {
+		t.Errorf("Unexpected archived page: %+v", page)
+	}
+	if !page.ArchivedAt.Equal(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)) {
+		t.Errorf("Unexpected snapshot time %s", page.ArchivedAt)
+	}
+
+	if _, err := fetcher.FetchArchived(context.Background(), server.URL+"/other"); !errors.Is(err, ErrNoSnapshot) {
+		t.Errorf("Expected ErrNoSnapshot, got %v", err)
+	}
+}
+
+func TestUnavailable(t *testing.T) {
+	for _, status := range []int{http.StatusNotFound, http.StatusBadGateway, http.StatusServiceUnavailable, 521} {
+		if !Unavailable(&Page{StatusCode: status}) {
+			t.Errorf("Expected status %d to be unavailable", status)
+		}
+	}
+	for _, status := range []int{http.StatusOK, http.StatusInternalServerError, http.StatusTooManyRequests} {
+		if Unavailable(&Page{StatusCode: status}) {
+			t.Errorf("Expected status %d not to be unavailable", status)
+		}
+	}
+}
+
+func TestUnreachable(t *testing.T) {
+	fetcher, err := NewFetcher(&config.Config{
+		FetchTimeout:           5 * time.Second,
+		FetchMaxPagesPerMinute: 10,
+		FetchMaxBytesPerMinute: 1024,
+	})
+	if err != nil {
+		t.Fatalf("NewFetcher returned an error: %v", err)
+	}
+
+	// A failed DNS lookup and a refused connection are worth an archive lookup
+	_, err = fetcher.Fetch(context.Background(), "http://unresolvable.invalid/")
+	if !Unreachable(err) {
+		t.Errorf("Expected a DNS failure to be unreachable, got %v", err)
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen returned an error: %v", err)
+	}
+	addr := listener.Addr().String()
+	_ = listener.Close()
+	if _, err := net.Dial("tcp", addr); !Unreachable(err) {
+		t.Errorf("Expected a refused connection to be unreachable, got %v", err)
+	}
+
+	// An internal address is blocked, not unreachable
+	_, err = fetcher.Fetch(context.Background(), "http://"+addr+"/")
+	if Unreachable(err) {
+		t.Errorf("Expected a blocked address not to be unreachable, got %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := fetcher.Fetch(ctx, "http://unresolvable.invalid/"); Unreachable(err) {
+		t.Errorf("Expected a canceled fetch not to be unreachable, got %v", err)
+	}
+	if Unreachable(nil) || Unreachable(&BudgetExceededError{}) {
+		t.Error("Expected no error and a budget error not to be unreachable")
+	}
+}
+
+func TestFetcher_ArchiveDisabled(t *testing.T) {
+	fetcher := testFetcher(t, 10, 1024)
+	if fetcher.ArchiveEnabled() {
+		t.Error("Expected the archive fallback to be disabled by default")
+	}
+	if _, err := fetcher.LatestSnapshot(context.Background(), "https://example.com/"); err == nil {
+		t.Error("Expected an error without the archive fallback, got nil")
+	}
+}
+
+func TestRawSnapshotURL(t *testing.T) {
+	got := rawSnapshotURL("http://web.archive.org/web/20130919044612/http://example.com/", "20130919044612")
+	if got != "https://web.archive.org/web/20130919044612id_/http://example.com/" {
+		t.Errorf("Unexpected raw snapshot URL %q", got)
+	}
+}
@@ -0,0 +1,135 @@
+package fetch
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// budgetWindow is the period the page and byte budgets apply to
+const budgetWindow = time.Minute
+
+// usage is one fetch counted against the budget
+type usage struct {
+	at    time.Time
+	bytes int64
+}
+
+// Budget is a global outbound allowance for page fetching, counted over a
+// sliding one-minute window. It is independent of the search API rate limiter.
+type Budget struct {
+	maxPages int
+	maxBytes int64
+
+	mu   sync.Mutex
+	used []*usage
+	now  func() time.Time
+}
+
+// BudgetExceededError is returned when a fetch would exceed the budget
+type BudgetExceededError struct {
+	Reason     string
+	RetryAfter time.Duration
+}
+
+// Error implements the error interface
+func (e *BudgetExceededError) Error() string {
+	return fmt.Sprintf("fetch budget exceeded (%s), retry in %s", e.Reason, e.RetryAfter.Round(time.Second))
+}
+
+// NewBudget creates a budget allowing maxPages fetches and maxBytes downloaded per minute
+func NewBudget(maxPages int, maxBytes int64) *Budget {
+	return &Budget{
+		maxPages: maxPages,
+		maxBytes: maxBytes,
+		now:      time.Now,
+	}
+}
+
+// Reserve claims one page and up to maxBytes from the budget, or every byte
+// left if maxBytes is 0. The claimed bytes are deducted at once, so concurrent
+// fetches can't overspend the budget between them; call Release with the bytes
+// actually read when done to give back the rest.
+func (b *Budget) Reserve(maxBytes int64) (*Reservation, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	b.expire(now)
+
+	if len(b.used) >= b.maxPages {
+		return nil, &BudgetExceededError{
+			Reason:     fmt.Sprintf("%d pages per minute", b.maxPages),
+			RetryAfter: b.retryAfter(now),
+		}
+	}
+
+	var bytesUsed int64
+	for _, u := range b.used {
+		bytesUsed += u.bytes
+	}
+	if bytesUsed >= b.maxBytes {
+		return nil, &BudgetExceededError{
+			Reason:     fmt.Sprintf("%d bytes per minute", b.maxBytes),
+			RetryAfter: b.retryAfter(now),
+		}
+	}
+
+	claimed := b.maxBytes - bytesUsed
+	if maxBytes > 0 && maxBytes < claimed {
+		claimed = maxBytes
+	}
+	u := &usage{at: now, bytes: claimed}
+	b.used = append(b.used, u)
+	return &Reservation{budget: b, usage: u, Bytes: claimed}, nil
+}
+
+// Remaining returns the pages and bytes still available in the current window
+func (b *Budget) Remaining() (pages int, bytes int64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.expire(b.now())
+	bytes = b.maxBytes
+	for _, u := range b.used {
+		bytes -= u.bytes
+	}
+	if bytes < 0 {
+		bytes = 0
+	}
+	return b.maxPages - len(b.used), bytes
+}
+
+// retryAfter returns how long until the oldest usage expires, or the whole
+// window when nothing is counted because a limit is zero. The caller must hold b.mu.
+func (b *Budget) retryAfter(now time.Time) time.Duration {
+	if len(b.used) == 0 {
+		return budgetWindow
+	}
+	return b.used[0].at.Add(budgetWindow).Sub(now)
+}
+
+// expire drops usage older than the window. The caller must hold b.mu.
+func (b *Budget) expire(now time.Time) {
+	i := 0
+	for i < len(b.used) && !now.Before(b.used[i].at.Add(budgetWindow)) {
+		i++
+	}
+	b.used = b.used[i:]
+}
+
+// Reservation is a page claimed from a Budget
+type Reservation struct {
+	budget *Budget
+	usage  *usage
+	// Bytes is the most the fetch may download without exceeding the byte budget
+	Bytes int64
+}
+
+// Release records the bytes actually downloaded by the fetch, returning the
+// rest of the reservation to the budget
+func (r *Reservation) Release(bytes int64) {
+	r.budget.mu.Lock()
+	defer r.budget.mu.Unlock()
+	r.usage.bytes = bytes
+}
@@ -0,0 +1,131 @@
+package fetch
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBudget_Pages(t *testing.T) {
+	budget := NewBudget(2, 1000)
+	now := time.Now()
+	budget.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		if _, err := budget.Reserve(100); err != nil {
+			t.Fatalf("Reserve %d returned an error: %v", i, err)
+		}
+	}
+
+	_, err := budget.Reserve(100)
+	var budgetErr *BudgetExceededError
+	if !errors.As(err, &budgetErr) {
+		t.Fatalf("Expected BudgetExceededError, got %v", err)
+	}
+	if budgetErr.RetryAfter != time.Minute {
+		t.Errorf("Expected retry after 1m, got %s", budgetErr.RetryAfter)
+	}
+
+	// The window slides
+	now = now.Add(time.Minute)
+	if _, err := budget.Reserve(100); err != nil {
+		t.Errorf("Expected budget to recover after a minute, got %v", err)
+	}
+}
+
+func TestBudget_Bytes(t *testing.T) {
+	budget := NewBudget(10, 1000)
+	now := time.Now()
+	budget.now = func() time.Time { return now }
+
+	r, err := budget.Reserve(0)
+	if err != nil {
+		t.Fatalf("Reserve returned an error: %v", err)
+	}
+	if r.Bytes != 1000 {
+		t.Errorf("Expected 1000 bytes available, got %d", r.Bytes)
+	}
+	r.Release(600)
+
+	r, err = budget.Reserve(0)
+	if err != nil {
+		t.Fatalf("Reserve returned an error: %v", err)
+	}
+	if r.Bytes != 400 {
+		t.Errorf("Expected 400 bytes available, got %d", r.Bytes)
+	}
+	r.Release(400)
+
+	if _, err := budget.Reserve(0); err == nil {
+		t.Error("Expected the byte budget to be exhausted, got nil")
+	}
+
+	pages, bytes := budget.Remaining()
+	if pages != 8 || bytes != 0 {
+		t.Errorf("Expected 8 pages and 0 bytes remaining, got %d and %d", pages, bytes)
+	}
+}
+
+func TestBudget_ReserveDeducts(t *testing.T) {
+	budget := NewBudget(10, 1000)
+
+	// Reserved bytes count until released, then only the bytes read do
+	r, err := budget.Reserve(300)
+	if err != nil {
+		t.Fatalf("Reserve returned an error: %v", err)
+	}
+	if _, bytes := budget.Remaining(); r.Bytes != 300 || bytes != 700 {
+		t.Errorf("Expected 300 bytes reserved and 700 remaining, got %d and %d", r.Bytes, bytes)
+	}
+	r.Release(100)
+	if _, bytes := budget.Remaining(); bytes != 900 {
+		t.Errorf("Expected the unused bytes back, got %d remaining", bytes)
+	}
+
+	// Concurrent fetches never reserve more than the budget between them
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	var reserved int64
+	var reservations []*Reservation
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if r, err := budget.Reserve(300); err == nil {
+				mu.Lock()
+				reserved += r.Bytes
+				reservations = append(reservations, r)
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if reserved != 900 {
+		t.Errorf("Expected exactly the 900 remaining bytes to be reserved, got %d", reserved)
+	}
+	for _, r := range reservations {
+		r.Release(0)
+	}
+	if pages, bytes := budget.Remaining(); bytes != 900 || pages != 9-len(reservations) {
+		t.Errorf("Expected 900 bytes back, got %d bytes and %d pages", bytes, pages)
+	}
+}
+
+func TestBudget_ZeroLimit(t *testing.T) {
+	for name, budget := range map[string]*Budget{
+		"zero pages": NewBudget(0, 1000),
+		"zero bytes": NewBudget(10, 0),
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := budget.Reserve(100)
+			var budgetErr *BudgetExceededError
+			if !errors.As(err, &budgetErr) {
+				t.Fatalf("Expected BudgetExceededError, got %v", err)
+			}
+			if budgetErr.RetryAfter != time.Minute {
+				t.Errorf("Expected retry after 1m, got %s", budgetErr.RetryAfter)
+			}
+		})
+	}
+}
@@ -0,0 +1,292 @@
+package fetch
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"com.moguyn/mcp-go-search/config"
+)
+
+// DefaultDownloadContentTypes are the media types saved when none are configured
+var DefaultDownloadContentTypes = []string{
+	"application/pdf",
+	"text/html",
+	"application/xhtml+xml",
+	"text/plain",
+}
+
+// downloadExtensions are the file extensions of common document types, which
+// mime's table may not know or may list in an unhelpful order
+var downloadExtensions = map[string]string{
+	"application/pdf":       ".pdf",
+	"text/html":             ".html",
+	"application/xhtml+xml": ".xhtml",
+	"text/plain":            ".txt",
+	"application/json":      ".json",
+	"text/csv":              ".csv",
+	"text/markdown":         ".md",
+}
+
+// ErrDownloadTooLarge is returned for a document larger than the limit of a
+// single download or the space left in the directory
+var ErrDownloadTooLarge = errors.New("document exceeds the download size limit")
+
+// Download is a document saved in the download directory
+type Download struct {
+	URL         string `json:"url"`
+	FinalURL    string `json:"final_url"`
+	ContentType string `json:"content_type"`
+	// Name is the file's name in the directory, and Path its absolute path
+	Name string `json:"name"`
+	Path string `json:"path"`
+	Size int64  `json:"size"`
+}
+
+// Downloader saves documents into a single directory, charging every download
+// against the fetcher's budget. Files are only ever created under names it
+// makes up, and read back through a root that can't be escaped.
+type Downloader struct {
+	fetcher       *Fetcher
+	dir           string
+	root          *os.Root
+	maxBytes      int64
+	maxTotalBytes int64
+	contentTypes  []string
+
+	// mu guards pending, the space claimed by downloads in progress
+	mu      sync.Mutex
+	pending int64
+}
+
+// NewDownloader creates a downloader saving into cfg.DownloadDir, which is
+// created if missing
+func NewDownloader(fetcher *Fetcher, cfg *config.Config) (*Downloader, error) {
+	dir, err := filepath.Abs(cfg.DownloadDir)
+	if err != nil {
+		return nil, fmt.Errorf("invalid download directory: %w", err)
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create download directory: %w", err)
+	}
+	root, err := os.OpenRoot(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open download directory: %w", err)
+	}
+	contentTypes := cfg.DownloadContentTypes
+	if len(contentTypes) == 0 {
+		contentTypes = DefaultDownloadContentTypes
+	}
+	return &Downloader{
+		fetcher:       fetcher,
+		dir:           dir,
+		root:          root,
+		maxBytes:      int64(cfg.DownloadMaxBytes),
+		maxTotalBytes: int64(cfg.DownloadMaxTotalBytes),
+		contentTypes:  contentTypes,
+	}, nil
+}
+
+// Close closes the download directory
+func (d *Downloader) Close() error {
+	return d.root.Close()
+}
+
+// Download saves the document at rawURL. A document of a type that isn't
+// allowed, or larger than the limits, is not saved.
+func (d *Downloader) Download(ctx context.Context, rawURL string) (*Download, error) {
+	u, err := parseURL(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	reservation, err := d.fetcher.budget.Reserve(d.maxBytes)
+	if err != nil {
+		return nil, err
+	}
+	var read int64
+	defer func() { reservation.Release(read) }()
+
+	limit, err := d.claim(reservation.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	defer d.unclaim(limit)
+
+	resp, err := d.fetcher.get(ctx, u)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("server returned status code %d", resp.StatusCode)
+	}
+	if resp.ContentLength > limit {
+		return nil, ErrDownloadTooLarge
+	}
+
+	// Without a declared type, sniff the start of the content
+	body := io.Reader(resp.Body)
+	mt := mediaType(resp.Header.Get("Content-Type"))
+	if mt == "" {
+		head := make([]byte, 512)
+		n, _ := io.ReadFull(resp.Body, head)
+		mt = sniffType(head[:n])
+		body = io.MultiReader(bytes.NewReader(head[:n]), resp.Body)
+	}
+	if !allowedType(mt, d.contentTypes) {
+		return nil, fmt.Errorf("content type %q is not allowed for downloads", mt)
+	}
+
+	download := &Download{
+		URL:         rawURL,
+		FinalURL:    resp.Request.URL.String(),
+		ContentType: mt,
+		Name:        fileName(resp.Request.URL.Path, resp.Request.URL.String(), mt),
+	}
+	download.Path = filepath.Join(d.dir, download.Name)
+	download.Size, err = d.save(download.Name, body, limit)
+	read = download.Size
+	if err != nil {
+		return nil, err
+	}
+	return download, nil
+}
+
+// save writes body to the named file, through a temporary file so a partial
+// download never replaces a complete one. It returns the bytes written.
+func (d *Downloader) save(name string, body io.Reader, limit int64) (int64, error) {
+	tmp, err := os.CreateTemp(d.dir, ".download-*")
+	if err != nil {
+		return 0, fmt.Errorf("failed to create file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	// Copy at most one byte past the limit to detect an oversized body
+	written, err := io.Copy(tmp, io.LimitReader(body, limit+1))
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return written, fmt.Errorf("failed to save document: %w", err)
+	}
+	if written > limit {
+		return written, ErrDownloadTooLarge
+	}
+	if err := os.Rename(tmp.Name(), filepath.Join(d.dir, name)); err != nil {
+		return written, fmt.Errorf("failed to save document: %w", err)
+	}
+	return written, nil
+}
+
+// claim reserves up to want bytes of the space left in the directory for a
+// download, returning how much it got
+func (d *Downloader) claim(want int64) (int64, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	used, err := d.usage()
+	if err != nil {
+		return 0, err
+	}
+	limit := min(want, d.maxTotalBytes-used-d.pending)
+	if limit <= 0 {
+		return 0, fmt.Errorf("download directory is full (%d bytes); remove files from %s to make room", d.maxTotalBytes, d.dir)
+	}
+	d.pending += limit
+	return limit, nil
+}
+
+// unclaim returns space claimed for a download once it is done
+func (d *Downloader) unclaim(limit int64) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.pending -= limit
+}
+
+// usage returns the bytes used by the saved files, leaving out temporary
+// files, which are counted as claims while they are written
+func (d *Downloader) usage() (int64, error) {
+	entries, err := fs.ReadDir(d.root.FS(), ".")
+	if err != nil {
+		return 0, fmt.Errorf("failed to read download directory: %w", err)
+	}
+	var used int64
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		if info, err := entry.Info(); err == nil {
+			used += info.Size()
+		}
+	}
+	return used, nil
+}
+
+// Open opens a saved file by name for reading. Names that would lead outside
+// the directory are refused.
+func (d *Downloader) Open(name string) (*os.File, error) {
+	if name == "" || strings.HasPrefix(name, ".") || strings.ContainsAny(name, `/\`) {
+		return nil, fmt.Errorf("invalid download name %q", name)
+	}
+	return d.root.Open(name)
+}
+
+// ContentType returns the media type of a saved file, from its extension
+func ContentType(name string) string {
+	ext := strings.ToLower(path.Ext(name))
+	for mt, known := range downloadExtensions {
+		if ext == known {
+			return mt
+		}
+	}
+	if mt := mime.TypeByExtension(ext); mt != "" {
+		return mediaType(mt)
+	}
+	return "application/octet-stream"
+}
+
+// fileName makes up the name a document is saved under: the last segment of
+// its URL path, reduced to safe characters, a hash of the URL so different
+// documents don't collide, and an extension for its media type
+func fileName(urlPath, rawURL, mt string) string {
+	base := path.Base(urlPath)
+	base = strings.TrimSuffix(base, path.Ext(base))
+	base = strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_':
+			return r
+		default:
+			return '_'
+		}
+	}, base)
+	base = strings.Trim(base, "_")
+	if len(base) > 60 {
+		base = base[:60]
+	}
+	if base == "" {
+		base = "download"
+	}
+
+	sum := sha256.Sum256([]byte(rawURL))
+	ext, ok := downloadExtensions[mt]
+	if !ok {
+		if exts, _ := mime.ExtensionsByType(mt); len(exts) > 0 {
+			ext = exts[0]
+		} else {
+			ext = ".bin"
+		}
+	}
+	return base + "-" + hex.EncodeToString(sum[:4]) + ext
+}
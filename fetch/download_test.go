@@ -0,0 +1,132 @@
+package fetch
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"com.moguyn/mcp-go-search/config"
+)
+
+// testDownloader returns a downloader saving into a temporary directory
+func testDownloader(t *testing.T, maxBytes, maxTotalBytes int) *Downloader {
+	downloader, err := NewDownloader(testFetcher(t, 10, 1<<20), &config.Config{
+		DownloadDir:           filepath.Join(t.TempDir(), "downloads"),
+		DownloadMaxBytes:      maxBytes,
+		DownloadMaxTotalBytes: maxTotalBytes,
+	})
+	if err != nil {
+		t.Fatalf("NewDownloader returned an error: %v", err)
+	}
+	t.Cleanup(func() { _ = downloader.Close() })
+	return downloader
+}
+
+func TestDownloader_Download(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/papers/attention is all you need.pdf":
+			w.Header().Set("Content-Type", "application/pdf")
+			_, _ = w.Write([]byte("%PDF-1.7 paper"))
+		case "/image.png":
+			w.Header().Set("Content-Type", "image/png")
+			_, _ = w.Write([]byte("\x89PNG"))
+		case "/big":
+			w.Header().Set("Content-Type", "text/html")
+			_, _ = w.Write([]byte(strings.Repeat("a", 200)))
+		default:
+			// No declared type, so the content is sniffed
+			_, _ = w.Write([]byte("<html><body>page</body></html>"))
+		}
+	}))
+	defer server.Close()
+
+	downloader := testDownloader(t, 100, 1000)
+	download, err := downloader.Download(context.Background(), server.URL+"/papers/attention%20is%20all%20you%20need.pdf")
+	if err != nil {
+		t.Fatalf("Download returned an error: %v", err)
+	}
+	if download.ContentType != "application/pdf" || download.Size != 14 ||
+		!strings.HasPrefix(download.Name, "attention_is_all_you_need-") || !strings.HasSuffix(download.Name, ".pdf") {
+		t.Errorf("Unexpected download: %+v", download)
+	}
+	data, err := os.ReadFile(download.Path)
+	if err != nil || string(data) != "%PDF-1.7 paper" {
+		t.Errorf("Expected the document at %s, got %q and %v", download.Path, data, err)
+	}
+
+	file, err := downloader.Open(download.Name)
+	if err != nil {
+		t.Fatalf("Open returned an error: %v", err)
+	}
+	data, _ = io.ReadAll(file)
+	file.Close()
+	if string(data) != "%PDF-1.7 paper" {
+		t.Errorf("Expected to read the document back, got %q", data)
+	}
+
+	download, err = downloader.Download(context.Background(), server.URL+"/")
+	if err != nil {
+		t.Fatalf("Download returned an error: %v", err)
+	}
+	if download.ContentType != "text/html" || !strings.HasPrefix(download.Name, "download-") || !strings.HasSuffix(download.Name, ".html") {
+		t.Errorf("Expected a sniffed HTML download, got %+v", download)
+	}
+
+	if _, err := downloader.Download(context.Background(), server.URL+"/image.png"); err == nil {
+		t.Error("Expected an image to be refused, got nil")
+	}
+	if _, err := downloader.Download(context.Background(), server.URL+"/big"); !errors.Is(err, ErrDownloadTooLarge) {
+		t.Errorf("Expected ErrDownloadTooLarge, got %v", err)
+	}
+	entries, _ := os.ReadDir(downloader.dir)
+	if len(entries) != 2 {
+		t.Errorf("Expected only the two saved documents in the directory, got %d entries", len(entries))
+	}
+}
+
+func TestDownloader_DirectoryFull(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = w.Write([]byte(strings.Repeat("a", 60)))
+	}))
+	defer server.Close()
+
+	downloader := testDownloader(t, 100, 100)
+	if _, err := downloader.Download(context.Background(), server.URL+"/one"); err != nil {
+		t.Fatalf("Download returned an error: %v", err)
+	}
+	// Only 40 bytes are left in the directory
+	if _, err := downloader.Download(context.Background(), server.URL+"/two"); !errors.Is(err, ErrDownloadTooLarge) {
+		t.Errorf("Expected ErrDownloadTooLarge, got %v", err)
+	}
+}
+
+func TestDownloader_Open(t *testing.T) {
+	downloader := testDownloader(t, 100, 100)
+	for _, name := range []string{"", "../secret", ".download-123", "a/b", `a\b`} {
+		if _, err := downloader.Open(name); err == nil {
+			t.Errorf("Expected %q to be refused, got nil", name)
+		}
+	}
+}
+
+func TestContentType(t *testing.T) {
+	tests := map[string]string{
+		"paper-1234.pdf": "application/pdf",
+		"page-1234.html": "text/html",
+		"notes.TXT":      "text/plain",
+		"blob":           "application/octet-stream",
+	}
+	for name, expected := range tests {
+		if got := ContentType(name); got != expected {
+			t.Errorf("ContentType(%q) = %q, expected %q", name, got, expected)
+		}
+	}
+}
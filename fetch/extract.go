@@ -0,0 +1,163 @@
+package fetch
+
+import (
+	"html"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Article is the readable content of a page, without navigation, ads and
+// other boilerplate
+type Article struct {
+	Title string `json:"title,omitempty"`
+	Text  string `json:"text"`
+}
+
+var (
+	htmlComment = regexp.MustCompile(`(?s)<!--.*?-->`)
+	htmlTitle   = regexp.MustCompile(`(?is)<title\b[^>]*>(.*?)</title\s*>`)
+	htmlBody    = regexp.MustCompile(`(?is)<body\b[^>]*>(.*)</body\s*>`)
+	htmlBreak   = regexp.MustCompile(`(?i)<br\s*/?>`)
+	htmlItem    = regexp.MustCompile(`(?i)<li\b[^>]*>`)
+	htmlBlock   = regexp.MustCompile(`(?i)</?(?:p|div|section|article|main|h[1-6]|ul|ol|dl|dt|dd|tr|table|blockquote|pre|figure|figcaption|hr)\b[^>]*>`)
+	blankLines  = regexp.MustCompile(`\n{3,}`)
+	// loneItemMarker matches a list marker followed by a blank line
+	loneItemMarker = regexp.MustCompile(`(?m)^(-|\d+\.)\n\n`)
+)
+
+// boilerplateTags hold navigation, page furniture and widgets rather than content
+var boilerplateTags = []string{"nav", "header", "footer", "aside", "form", "button", "select", "iframe", "svg", "dialog"}
+
+// boilerplate matches the elements of boilerplateTags, one pattern per tag
+// since the closing tag must match the opening one
+var boilerplate = func() []*regexp.Regexp {
+	patterns := make([]*regexp.Regexp, 0, len(boilerplateTags))
+	for _, tag := range boilerplateTags {
+		patterns = append(patterns, regexp.MustCompile(`(?is)<`+tag+`\b[^>]*>.*?</`+tag+`\s*>`))
+	}
+	return patterns
+}()
+
+// mainContent matches the elements pages put their main content in, in order
+// of preference
+var mainContent = []*regexp.Regexp{
+	regexp.MustCompile(`(?is)<article\b[^>]*>(.*?)</article\s*>`),
+	regexp.MustCompile(`(?is)<main\b[^>]*>(.*?)</main\s*>`),
+}
+
+// Formats the readable content of a page can be returned in
+const (
+	FormatText     = "text"
+	FormatMarkdown = "markdown"
+	FormatHTML     = "html"
+)
+
+// Formats lists the formats Extract accepts
+var Formats = []string{FormatText, FormatMarkdown, FormatHTML}
+
+// Extract returns the readable content of a fetched page in format, one of
+// Formats. HTML is reduced to its main content: plain text with one paragraph
+// per line, Markdown, or the cleaned HTML itself. Other text is returned as it is.
+func Extract(page *Page, format string) *Article {
+	mt := mediaType(page.ContentType)
+	if mt == "" {
+		mt = sniffType(page.Body)
+	}
+	if mt != "text/html" && mt != "application/xhtml+xml" {
+		return &Article{Text: strings.ToValidUTF8(string(page.Body), "")}
+	}
+	article, content := readableHTML(page.Body)
+	switch format {
+	case FormatMarkdown:
+		base, _ := url.Parse(page.FinalURL)
+		article.Text = htmlToMarkdown(content, base)
+	case FormatHTML:
+		article.Text = strings.TrimSpace(strings.ToValidUTF8(string(content), ""))
+	default:
+		article.Text = htmlToText(content)
+	}
+	return article
+}
+
+// readableHTML returns the title of an HTML page and the markup of its main
+// content, without scripts, comments and boilerplate
+func readableHTML(body []byte) (*Article, []byte) {
+	article := &Article{}
+	if m := htmlTitle.FindSubmatch(body); m != nil {
+		article.Title = strings.Join(strings.Fields(html.UnescapeString(string(m[1]))), " ")
+	}
+	for _, meta := range metaTags(body) {
+		if meta["property"] == "og:title" && article.Title == "" {
+			article.Title = strings.Join(strings.Fields(meta["content"]), " ")
+		}
+	}
+
+	content := htmlComment.ReplaceAll(body, nil)
+	content = htmlHidden.ReplaceAll(content, nil)
+	if m := htmlBody.FindSubmatch(content); m != nil {
+		content = m[1]
+	}
+	for _, pattern := range boilerplate {
+		content = pattern.ReplaceAll(content, nil)
+	}
+	// The longest article or main element holds the content; pages list
+	// related stories in smaller ones
+	for _, pattern := range mainContent {
+		var longest []byte
+		for _, m := range pattern.FindAllSubmatch(content, -1) {
+			if len(m[1]) > len(longest) {
+				longest = m[1]
+			}
+		}
+		if len(strings.TrimSpace(visibleText(longest))) > 0 {
+			content = longest
+			break
+		}
+	}
+	return article, content
+}
+
+// extractHTML returns the title and main text of an HTML page
+func extractHTML(body []byte) *Article {
+	article, content := readableHTML(body)
+	article.Text = htmlToText(content)
+	return article
+}
+
+// htmlToText reduces HTML to its text, one paragraph per line
+func htmlToText(content []byte) string {
+	content = htmlItem.ReplaceAll(content, []byte("\n- "))
+	content = htmlBreak.ReplaceAll(content, []byte("\n"))
+	content = htmlBlock.ReplaceAll(content, []byte("\n\n"))
+	content = htmlTag.ReplaceAll(content, nil)
+	return tidy(html.UnescapeString(string(content)))
+}
+
+// tidy collapses the whitespace of each line and runs of blank lines
+func tidy(text string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.Join(strings.Fields(line), " ")
+	}
+	text = blankLines.ReplaceAllString(strings.Join(lines, "\n"), "\n\n")
+	// A list item's marker is left alone on its line when the item starts with a block
+	text = loneItemMarker.ReplaceAllString(text, "$1 ")
+	return strings.TrimSpace(text)
+}
+
+// Cut shortens text to at most maxChars characters, ending at a paragraph or
+// word boundary when one is near. It reports whether text was shortened.
+func Cut(text string, maxChars int) (string, bool) {
+	runes := []rune(text)
+	if len(runes) <= maxChars {
+		return text, false
+	}
+	cut := string(runes[:maxChars])
+	if i := strings.LastIndex(cut, "\n\n"); i > len(cut)*3/4 {
+		cut = cut[:i]
+	} else if i := strings.LastIndexAny(cut, " \n"); i > len(cut)*3/4 {
+		cut = cut[:i]
+	}
+	return strings.TrimSpace(cut), true
+}
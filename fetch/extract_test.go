@@ -0,0 +1,59 @@
+package fetch
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExtractHTML(t *testing.T) {
+	body := []byte(`<html><head><title>Go &amp; you</title><style>p { color: red }</style></head><body>
+<header><a href="/">Home</a> <a href="/about">About</a></header>
+<aside><article><p>A related story</p></article></aside>
+<main>
+<article><h1>Hello   world</h1>
+<p>First para.</p>
+<ul><li>one</li><li><p>two</p></li></ul>
+<p>Line<br>break</p>
+<script>track()</script>
+</article>
+</main>
+<footer>Copyright</footer>
+</body></html>`)
+
+	article := extractHTML(body)
+	if article.Title != "Go & you" {
+		t.Errorf("Expected the decoded title, got %q", article.Title)
+	}
+	expected := "Hello world\n\nFirst para.\n\n- one\n- two\n\nLine\nbreak"
+	if article.Text != expected {
+		t.Errorf("Expected:\n%q\ngot:\n%q", expected, article.Text)
+	}
+}
+
+func TestExtract_PlainText(t *testing.T) {
+	article := Extract(&Page{ContentType: "text/plain", Body: []byte("just <b>text</b>")}, FormatMarkdown)
+	if article.Title != "" || article.Text != "just <b>text</b>" {
+		t.Errorf("Expected plain text to be returned as it is, got %+v", article)
+	}
+}
+
+func TestCut(t *testing.T) {
+	if text, cut := Cut("short", 10); text != "short" || cut {
+		t.Errorf("Expected short text to be kept, got %q, %v", text, cut)
+	}
+
+	text, cut := Cut("first paragraph\n\nsecond paragraph", 20)
+	if text != "first paragraph" || !cut {
+		t.Errorf("Expected a cut at the paragraph break, got %q, %v", text, cut)
+	}
+
+	text, cut = Cut(strings.Repeat("word ", 10), 23)
+	if text != "word word word word" || !cut {
+		t.Errorf("Expected a cut at a word boundary, got %q, %v", text, cut)
+	}
+
+	text, _ = Cut("日本語の文章です", 3)
+	if text != "日本語" {
+		t.Errorf("Expected the cut to count characters, got %q", text)
+	}
+}
@@ -0,0 +1,212 @@
+// Package fetch downloads web pages on behalf of the fetch tools, within a
+// global outbound budget and never from internal addresses
+package fetch
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"com.moguyn/mcp-go-search/config"
+)
+
+// userAgent identifies the fetcher to the sites it visits
+const userAgent = "BochaWebSearchMCPServer/1.0 (+https://github.com/moguyn/mcp-go-search)"
+
+// Page is a fetched web page
+type Page struct {
+	URL         string `json:"url"`
+	FinalURL    string `json:"final_url"`
+	StatusCode  int    `json:"status_code"`
+	ContentType string `json:"content_type"`
+	Body        []byte `json:"-"`
+	// Truncated reports that the body was cut short by the page or budget byte limit
+	Truncated bool `json:"truncated"`
+	// Skipped explains why the body was not downloaded, e.g. SkippedBinary
+	Skipped string `json:"skipped,omitempty"`
+	// Gate is the kind of wall the page hides its content behind, e.g.
+	// GatePaywall. The body of a gated page is dropped, since it is at best
+	// a fragment, and Teaser holds the text shown in front of the wall.
+	Gate   string `json:"gate,omitempty"`
+	Teaser string `json:"teaser,omitempty"`
+	// Metrics describe the amount and language of the page's text, for text
+	// and HTML pages that were returned
+	Metrics *Metrics `json:"metrics,omitempty"`
+	// ArchivedAt is when the snapshot was taken, for a page fetched from the archive
+	ArchivedAt time.Time `json:"archived_at,omitempty"`
+}
+
+// Fetcher downloads pages, charging every fetch against a shared budget
+type Fetcher struct {
+	client       *http.Client
+	budget       *Budget
+	maxPageBytes int64
+	contentTypes []string
+	// archiveURL is the Wayback Machine availability API, empty when the
+	// archive fallback is disabled
+	archiveURL string
+}
+
+// NewFetcher creates a new fetcher with the provided configuration.
+// Connections to internal addresses are refused unless allowlisted.
+func NewFetcher(cfg *config.Config) (*Fetcher, error) {
+	guard, err := NewAddressGuard(cfg.FetchAllowlist)
+	if err != nil {
+		return nil, err
+	}
+	contentTypes := cfg.FetchContentTypes
+	if len(contentTypes) == 0 {
+		contentTypes = DefaultContentTypes
+	}
+
+	dialer := &net.Dialer{
+		Timeout: 10 * time.Second,
+		Control: guard.Control,
+	}
+
+	transport := &http.Transport{
+		// Never route fetches through a proxy, which would hide the real target from the guard
+		Proxy:       nil,
+		DialContext: dialer.DialContext,
+		TLSClientConfig: &tls.Config{
+			MinVersion: tls.VersionTLS12,
+		},
+		ForceAttemptHTTP2: true,
+		MaxIdleConns:      20,
+		IdleConnTimeout:   90 * time.Second,
+	}
+
+	var archiveURL string
+	if cfg.FetchArchiveFallback {
+		archiveURL = cfg.FetchArchiveURL
+	}
+
+	return &Fetcher{
+		client: &http.Client{
+			Timeout:   cfg.FetchTimeout,
+			Transport: transport,
+		},
+		budget:       NewBudget(cfg.FetchMaxPagesPerMinute, int64(cfg.FetchMaxBytesPerMinute)),
+		maxPageBytes: int64(cfg.FetchMaxPageBytes),
+		contentTypes: contentTypes,
+		archiveURL:   archiveURL,
+	}, nil
+}
+
+// Budget returns the fetcher's outbound budget
+func (f *Fetcher) Budget() *Budget {
+	return f.budget
+}
+
+// parseURL checks that rawURL is an http or https URL with a host
+func parseURL(rawURL string) (*url.URL, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("unsupported URL scheme %q, must be http or https", u.Scheme)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("URL has no host")
+	}
+	return u, nil
+}
+
+// get sends a GET request for u through the guarded client
+func (f *Fetcher) get(ctx context.Context, u *url.URL) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTTP request: %w", err)
+	}
+	req.Header.Set("User-Agent", userAgent)
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch page: %w", err)
+	}
+	return resp, nil
+}
+
+// Fetch downloads the page at rawURL
+func (f *Fetcher) Fetch(ctx context.Context, rawURL string) (*Page, error) {
+	u, err := parseURL(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	reservation, err := f.budget.Reserve(f.maxPageBytes)
+	if err != nil {
+		return nil, err
+	}
+	var read int64
+	defer func() { reservation.Release(read) }()
+
+	resp, err := f.get(ctx, u)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	page := &Page{
+		URL:         rawURL,
+		FinalURL:    resp.Request.URL.String(),
+		StatusCode:  resp.StatusCode,
+		ContentType: resp.Header.Get("Content-Type"),
+	}
+
+	// Screen on the headers first so unwanted bodies are never downloaded
+	mt := mediaType(page.ContentType)
+	if mt != "" {
+		if page.Skipped = skipReason(mt, f.contentTypes); page.Skipped != "" {
+			return page, nil
+		}
+	}
+	if f.maxPageBytes > 0 && resp.ContentLength > f.maxPageBytes {
+		page.Skipped = SkippedTooLarge
+		return page, nil
+	}
+
+	limit := reservation.Bytes
+	// Stream at most one byte past the limit to detect truncation
+	body, err := io.ReadAll(io.LimitReader(resp.Body, limit+1))
+	read = int64(len(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read page body: %w", err)
+	}
+
+	// Without a declared type, sniff the content so binary data is still caught
+	if mt == "" {
+		mt = sniffType(body)
+		if page.Skipped = skipReason(mt, f.contentTypes); page.Skipped != "" {
+			return page, nil
+		}
+	}
+
+	page.Body = body
+	if int64(len(body)) > limit {
+		page.Body = body[:limit]
+		page.Truncated = true
+	}
+
+	contentLanguage := resp.Header.Get("Content-Language")
+	switch {
+	case mt == "text/html" || mt == "application/xhtml+xml":
+		text := visibleText(page.Body)
+		if page.Gate = detectGate(page.Body, text); page.Gate != "" {
+			page.Teaser = teaser(page.Body)
+			page.Body = nil
+			return page, nil
+		}
+		page.Metrics = measure(text, declaredLanguage(page.Body, contentLanguage))
+	case strings.HasPrefix(mt, "text/"):
+		page.Metrics = measure(string(page.Body), declaredLanguage(nil, contentLanguage))
+	}
+	return page, nil
+}
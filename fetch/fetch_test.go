@@ -0,0 +1,178 @@
+package fetch
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"com.moguyn/mcp-go-search/config"
+)
+
+// testFetcher returns a fetcher allowed to reach the loopback test servers
+func testFetcher(t *testing.T, maxPages, maxBytes int) *Fetcher {
+	fetcher, err := NewFetcher(&config.Config{
+		FetchTimeout:           5 * time.Second,
+		FetchMaxPagesPerMinute: maxPages,
+		FetchMaxBytesPerMinute: maxBytes,
+		FetchAllowlist:         []string{"127.0.0.1"},
+	})
+	if err != nil {
+		t.Fatalf("NewFetcher returned an error: %v", err)
+	}
+	return fetcher
+}
+
+func TestFetcher_Fetch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("User-Agent") == "" {
+			t.Error("Expected a User-Agent header")
+		}
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = w.Write([]byte(strings.Repeat("a", 100)))
+	}))
+	defer server.Close()
+
+	fetcher := testFetcher(t, 10, 150)
+	page, err := fetcher.Fetch(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("Fetch returned an error: %v", err)
+	}
+	if page.StatusCode != http.StatusOK || page.ContentType != "text/plain" || len(page.Body) != 100 || page.Truncated {
+		t.Errorf("Unexpected page: %+v", page)
+	}
+
+	// Only 50 bytes of the budget remain
+	page, err = fetcher.Fetch(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("Fetch returned an error: %v", err)
+	}
+	if len(page.Body) != 50 || !page.Truncated {
+		t.Errorf("Expected the body to be truncated to 50 bytes, got %d", len(page.Body))
+	}
+
+	if _, err := fetcher.Fetch(context.Background(), server.URL); err == nil {
+		t.Error("Expected the budget to be exhausted, got nil")
+	}
+}
+
+func TestFetcher_InvalidURL(t *testing.T) {
+	fetcher := testFetcher(t, 10, 1000)
+	for _, rawURL := range []string{"ftp://example.com/file", "file:///etc/passwd", "http://", "::"} {
+		if _, err := fetcher.Fetch(context.Background(), rawURL); err == nil {
+			t.Errorf("Expected error for %q, got nil", rawURL)
+		}
+	}
+	if pages, _ := fetcher.Budget().Remaining(); pages != 10 {
+		t.Errorf("Expected invalid URLs not to use the budget, got %d pages remaining", pages)
+	}
+}
+
+func TestFetcher_BlocksInternalAddresses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, _ *http.Request) {
+		t.Error("Expected the request never to reach the internal server")
+	}))
+	defer server.Close()
+
+	fetcher, err := NewFetcher(&config.Config{
+		FetchTimeout:           5 * time.Second,
+		FetchMaxPagesPerMinute: 10,
+		FetchMaxBytesPerMinute: 1000,
+	})
+	if err != nil {
+		t.Fatalf("NewFetcher returned an error: %v", err)
+	}
+
+	// Both the literal address and a name resolving to it are refused
+	for _, rawURL := range []string{server.URL, strings.Replace(server.URL, "127.0.0.1", "localhost", 1)} {
+		_, err := fetcher.Fetch(context.Background(), rawURL)
+		if err == nil || !strings.Contains(err.Error(), "blocked") {
+			t.Errorf("Expected %s to be blocked, got %v", rawURL, err)
+		}
+	}
+
+	// Redirects to internal addresses are refused too
+	redirect := httptest.NewServer(http.RedirectHandler(server.URL, http.StatusFound))
+	defer redirect.Close()
+	allowRedirector, _ := NewFetcher(&config.Config{
+		FetchTimeout:           5 * time.Second,
+		FetchMaxPagesPerMinute: 10,
+		FetchMaxBytesPerMinute: 1000,
+		FetchAllowlist:         []string{"127.0.0.2"},
+	})
+	if _, err := allowRedirector.Fetch(context.Background(), redirect.URL); err == nil {
+		t.Error("Expected redirect target to be blocked, got nil")
+	}
+}
+
+func TestNewFetcher_InvalidAllowlist(t *testing.T) {
+	if _, err := NewFetcher(&config.Config{FetchAllowlist: []string{"not-an-ip"}}); err == nil {
+		t.Error("Expected error for an invalid allowlist, got nil")
+	}
+}
+
+func TestFetcher_ContentScreening(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/report.pdf":
+			w.Header().Set("Content-Type", "application/pdf")
+			_, _ = w.Write([]byte("%PDF-1.7 binary data"))
+		case "/huge":
+			w.Header().Set("Content-Type", "text/plain")
+			w.Header().Set("Content-Length", "5000")
+			_, _ = w.Write([]byte(strings.Repeat("a", 5000)))
+		case "/stream":
+			// No Content-Length: the read is aborted at the page limit
+			w.Header().Set("Content-Type", "text/plain")
+			for i := 0; i < 50; i++ {
+				_, _ = w.Write([]byte(strings.Repeat("b", 100)))
+				w.(http.Flusher).Flush()
+			}
+		case "/untyped":
+			w.Header()["Content-Type"] = nil
+			_, _ = w.Write([]byte("\x89PNG\r\n\x1a\n binary image"))
+		}
+	}))
+	defer server.Close()
+
+	fetcher, err := NewFetcher(&config.Config{
+		FetchTimeout:           5 * time.Second,
+		FetchMaxPagesPerMinute: 10,
+		FetchMaxBytesPerMinute: 1024 * 1024,
+		FetchMaxPageBytes:      1000,
+		FetchAllowlist:         []string{"127.0.0.1"},
+	})
+	if err != nil {
+		t.Fatalf("NewFetcher returned an error: %v", err)
+	}
+
+	testCases := []struct {
+		path      string
+		skipped   string
+		bodyLen   int
+		truncated bool
+	}{
+		{"/report.pdf", SkippedBinary, 0, false},
+		{"/huge", SkippedTooLarge, 0, false},
+		{"/stream", "", 1000, true},
+		{"/untyped", SkippedBinary, 0, false},
+	}
+
+	for _, tc := range testCases {
+		page, err := fetcher.Fetch(context.Background(), server.URL+tc.path)
+		if err != nil {
+			t.Fatalf("Fetch %s returned an error: %v", tc.path, err)
+		}
+		if page.Skipped != tc.skipped || len(page.Body) != tc.bodyLen || page.Truncated != tc.truncated {
+			t.Errorf("%s: expected skipped=%q body=%d truncated=%v, got skipped=%q body=%d truncated=%v",
+				tc.path, tc.skipped, tc.bodyLen, tc.truncated, page.Skipped, len(page.Body), page.Truncated)
+		}
+	}
+
+	// Skipped bodies are not charged against the byte budget
+	if _, bytes := fetcher.Budget().Remaining(); bytes != int64(1024*1024-1001-len("\x89PNG\r\n\x1a\n binary image")) {
+		t.Errorf("Unexpected bytes remaining: %d", bytes)
+	}
+}
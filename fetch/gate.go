@@ -0,0 +1,164 @@
+package fetch
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+// Kinds of wall a page may hide its content behind
+const (
+	// GatePaywall marks content reserved for subscribers
+	GatePaywall = "paywall"
+	// GateLogin marks content that needs an account to see
+	GateLogin = "login"
+)
+
+// maxTeaserRunes bounds the teaser text kept from a gated page
+const maxTeaserRunes = 500
+
+// maxGatedTextLength is the most visible text a page matching a gate phrase
+// may have to be considered gated. Longer pages carry their content, and the
+// phrase is more likely a footer or a sidebar.
+const maxGatedTextLength = 3000
+
+var (
+	htmlTag         = regexp.MustCompile(`<[^>]*>`)
+	htmlHidden      = regexp.MustCompile(`(?is)<(script|style|noscript|template)\b[^>]*>.*?</(script|style|noscript|template)\s*>`)
+	htmlMeta        = regexp.MustCompile(`(?i)<meta\b[^>]*>`)
+	htmlAttr        = regexp.MustCompile(`(?i)([a-z][a-z0-9:_-]*)\s*=\s*(?:"([^"]*)"|'([^']*)')`)
+	htmlParagraph   = regexp.MustCompile(`(?is)<p\b[^>]*>(.*?)</p\s*>`)
+	passwordInput   = regexp.MustCompile(`(?i)<input\b[^>]*type\s*=\s*["']?password`)
+	notFreeToAccess = regexp.MustCompile(`(?i)"isAccessibleForFree"\s*:\s*"?false"?`)
+)
+
+// paywallPhrases are shown in front of content reserved for subscribers
+var paywallPhrases = []string{
+	"subscribe to continue reading",
+	"subscribe to keep reading",
+	"subscribe to read the full",
+	"subscribe now to continue",
+	"this article is for subscribers",
+	"this content is for subscribers",
+	"this article is reserved for subscribers",
+	"available to subscribers only",
+	"exclusive to subscribers",
+	"you have reached your free article limit",
+	"you've reached your free article limit",
+	"you have reached your article limit",
+	"to continue reading, subscribe",
+}
+
+// loginPhrases are shown in front of content that needs an account
+var loginPhrases = []string{
+	"sign in to continue",
+	"log in to continue",
+	"login to continue",
+	"sign in to read",
+	"log in to read",
+	"sign in to view",
+	"log in to view",
+	"please sign in to",
+	"please log in to",
+	"create a free account to continue",
+	"register to continue reading",
+}
+
+// detectGate returns the kind of wall an HTML page hides its content behind,
+// or "" when it shows its content. Paywall markup publishers add for search
+// engines is trusted on its own; phrases only count on pages with little text.
+func detectGate(body []byte, text string) string {
+	if notFreeToAccess.Match(body) {
+		return GatePaywall
+	}
+	for _, meta := range metaTags(body) {
+		if meta["property"] == "article:content_tier" || meta["name"] == "article:content_tier" {
+			if tier := strings.ToLower(meta["content"]); tier == "locked" || tier == "metered" {
+				return GatePaywall
+			}
+		}
+	}
+
+	if len(text) > maxGatedTextLength {
+		return ""
+	}
+	lower := strings.ToLower(text)
+	for _, phrase := range paywallPhrases {
+		if strings.Contains(lower, phrase) {
+			return GatePaywall
+		}
+	}
+	for _, phrase := range loginPhrases {
+		if strings.Contains(lower, phrase) {
+			return GateLogin
+		}
+	}
+	if passwordInput.Match(body) && len(text) < maxGatedTextLength/3 {
+		return GateLogin
+	}
+	return ""
+}
+
+// teaser returns the text a gated page shows in front of its wall: its
+// description, or else its first paragraphs, cut to maxTeaserRunes
+func teaser(body []byte) string {
+	var description string
+	for _, meta := range metaTags(body) {
+		switch {
+		case meta["property"] == "og:description":
+			description = meta["content"]
+		case meta["name"] == "description" && description == "":
+			description = meta["content"]
+		}
+	}
+	text := strings.Join(strings.Fields(description), " ")
+	if text == "" {
+		var paragraphs []string
+		for _, m := range htmlParagraph.FindAllSubmatch(body, -1) {
+			// Markup inside a paragraph is inline, so it is removed without a space
+			p := html.UnescapeString(string(htmlTag.ReplaceAll(m[1], nil)))
+			if p = strings.Join(strings.Fields(p), " "); p != "" {
+				paragraphs = append(paragraphs, p)
+			}
+			if len(strings.Join(paragraphs, " ")) > maxTeaserRunes*4 {
+				break
+			}
+		}
+		text = strings.Join(paragraphs, " ")
+	}
+	if runes := []rune(text); len(runes) > maxTeaserRunes {
+		text = strings.TrimSpace(string(runes[:maxTeaserRunes])) + "…"
+	}
+	return text
+}
+
+// visibleText returns the text of an HTML fragment without scripts, styles
+// and markup, with entities decoded and whitespace collapsed
+func visibleText(body []byte) string {
+	text := htmlTag.ReplaceAll(htmlHidden.ReplaceAll(body, nil), []byte(" "))
+	return strings.Join(strings.Fields(html.UnescapeString(string(text))), " ")
+}
+
+// metaTags returns the attributes of every meta tag, with lower-case names
+// and decoded values
+func metaTags(body []byte) []map[string]string {
+	var tags []map[string]string
+	for _, tag := range htmlMeta.FindAll(body, -1) {
+		tags = append(tags, attributes(tag))
+	}
+	return tags
+}
+
+// attributes returns the attributes of an HTML tag with lower-case names and
+// decoded values
+func attributes(tag []byte) map[string]string {
+	attrs := make(map[string]string)
+	for _, m := range htmlAttr.FindAllSubmatch(tag, -1) {
+		value := string(m[2])
+		if len(m[3]) > 0 {
+			value = string(m[3])
+		}
+		attrs[strings.ToLower(string(m[1]))] = html.UnescapeString(value)
+	}
+	return attrs
+}
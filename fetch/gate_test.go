@@ -0,0 +1,67 @@
+package fetch
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDetectGate(t *testing.T) {
+	article := "<p>" + strings.Repeat("The full story goes on. ", 200) + "</p>"
+	testCases := []struct {
+		name string
+		body string
+		gate string
+	}{
+		{"open article", "<html><body>" + article + "</body></html>", ""},
+		{"structured paywall", `<script type="application/ld+json">{"@type":"NewsArticle","isAccessibleForFree":"False"}</script>` + article, GatePaywall},
+		{"content tier", `<meta content="metered" property="article:content_tier">` + article, GatePaywall},
+		{"free content tier", `<meta property="article:content_tier" content="free">` + article, ""},
+		{"paywall phrase", "<p>The opening paragraph.</p><div>Subscribe to continue reading</div>", GatePaywall},
+		{"login phrase", "<p>Members only.</p><a href=/login>Sign in to continue</a>", GateLogin},
+		{"password form", `<form><input name=user><input type="password" name=pw></form>`, GateLogin},
+		{"phrase in a long page", article + "<footer>Already a subscriber? Subscribe to continue reading our newsletter</footer>", ""},
+		{"phrase in a script", "<p>Short page.</p><script>var msg = 'subscribe to continue reading';</script>", ""},
+	}
+	for _, tc := range testCases {
+		body := []byte(tc.body)
+		if gate := detectGate(body, visibleText(body)); gate != tc.gate {
+			t.Errorf("%s: expected gate %q, got %q", tc.name, tc.gate, gate)
+		}
+	}
+}
+
+func TestTeaser(t *testing.T) {
+	body := []byte(`<meta name="description" content="A plain description"><meta property="og:description" content="Rates rise &amp; markets   fall">`)
+	if got := teaser(body); got != "Rates rise & markets fall" {
+		t.Errorf("Expected the og:description, got %q", got)
+	}
+
+	body = []byte("<p>First <b>paragraph</b>.</p><p>" + strings.Repeat("word ", 200) + "</p>")
+	got := teaser(body)
+	if !strings.HasPrefix(got, "First paragraph. word") || !strings.HasSuffix(got, "…") || len([]rune(got)) != maxTeaserRunes+1 {
+		t.Errorf("Expected the paragraphs cut to %d runes, got %q", maxTeaserRunes, got)
+	}
+}
+
+func TestFetcher_FetchGated(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write([]byte(`<html><head><meta name="description" content="The first lines of the story"></head>` +
+			`<body><p>The first lines</p><div class="wall">Subscribe to continue reading</div></body></html>`))
+	}))
+	defer server.Close()
+
+	page, err := testFetcher(t, 10, 4096).Fetch(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("Fetch returned an error: %v", err)
+	}
+	if page.Gate != GatePaywall || page.Teaser != "The first lines of the story" || page.Body != nil {
+		t.Errorf("Expected a gated page with its teaser and no body, got %+v", page)
+	}
+	if !Unavailable(page) {
+		t.Error("Expected a gated page to be unavailable")
+	}
+}
@@ -0,0 +1,167 @@
+package fetch
+
+import (
+	"fmt"
+	"html"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	mdPre        = regexp.MustCompile(`(?is)<pre\b[^>]*>(.*?)</pre\s*>`)
+	mdImage      = regexp.MustCompile(`(?i)<img\b[^>]*>`)
+	mdLink       = regexp.MustCompile(`(?is)<a\b([^>]*)>(.*?)</a\s*>`)
+	mdCode       = regexp.MustCompile(`(?is)<code\b[^>]*>(.*?)</code\s*>`)
+	mdStrong     = regexp.MustCompile(`(?is)<(?:strong|b)\b[^>]*>(.*?)</(?:strong|b)\s*>`)
+	mdEmphasis   = regexp.MustCompile(`(?is)<(?:em|i)\b[^>]*>(.*?)</(?:em|i)\s*>`)
+	mdHeading    = regexp.MustCompile(`(?is)<h([1-6])\b[^>]*>(.*?)</h[1-6]\s*>`)
+	mdTable      = regexp.MustCompile(`(?is)<table\b[^>]*>(.*?)</table\s*>`)
+	mdRow        = regexp.MustCompile(`(?is)<tr\b[^>]*>(.*?)</tr\s*>`)
+	mdCell       = regexp.MustCompile(`(?is)<t[hd]\b[^>]*>(.*?)</t[hd]\s*>`)
+	mdBlockquote = regexp.MustCompile(`(?is)<blockquote\b[^>]*>(.*?)</blockquote\s*>`)
+	mdOrdered    = regexp.MustCompile(`(?is)<ol\b[^>]*>(.*?)</ol\s*>`)
+	mdRule       = regexp.MustCompile(`(?i)<hr\b[^>]*>`)
+	// mdPlaceholder marks where a preformatted block goes back once the
+	// whitespace around it has been collapsed
+	mdPlaceholder = regexp.MustCompile("\x00(\\d+)\x00")
+)
+
+// htmlToMarkdown converts the main content of a page to Markdown. Links and
+// images are resolved against base, when given.
+func htmlToMarkdown(content []byte, base *url.URL) string {
+	// Preformatted text keeps its whitespace, so it is set aside until the end
+	var blocks []string
+	content = mdPre.ReplaceAllFunc(content, func(m []byte) []byte {
+		code := html.UnescapeString(string(htmlTag.ReplaceAll(mdPre.FindSubmatch(m)[1], nil)))
+		blocks = append(blocks, "```\n"+strings.Trim(code, "\n")+"\n```")
+		return []byte(fmt.Sprintf("\n\n\x00%d\x00\n\n", len(blocks)-1))
+	})
+
+	content = mdImage.ReplaceAllFunc(content, func(m []byte) []byte {
+		attrs := attributes(m)
+		if attrs["src"] == "" {
+			return nil
+		}
+		return []byte(fmt.Sprintf("![%s](%s)", html.EscapeString(inline(attrs["alt"])), resolve(attrs["src"], base)))
+	})
+	content = mdLink.ReplaceAllFunc(content, func(m []byte) []byte {
+		parts := mdLink.FindSubmatch(m)
+		text := inline(string(parts[2]))
+		href := attributes(parts[1])["href"]
+		if text == "" || href == "" || strings.HasPrefix(href, "#") || strings.HasPrefix(strings.ToLower(href), "javascript:") {
+			return []byte(text)
+		}
+		return []byte(fmt.Sprintf("[%s](%s)", text, resolve(href, base)))
+	})
+	content = wrapInline(content, mdCode, "`")
+	content = wrapInline(content, mdStrong, "**")
+	content = wrapInline(content, mdEmphasis, "*")
+	content = mdHeading.ReplaceAllFunc(content, func(m []byte) []byte {
+		parts := mdHeading.FindSubmatch(m)
+		text := inline(string(parts[2]))
+		if text == "" {
+			return nil
+		}
+		return []byte("\n\n" + strings.Repeat("#", int(parts[1][0]-'0')) + " " + text + "\n\n")
+	})
+	content = mdTable.ReplaceAllFunc(content, func(m []byte) []byte {
+		return []byte("\n\n" + markdownTable(mdTable.FindSubmatch(m)[1]) + "\n\n")
+	})
+	content = mdBlockquote.ReplaceAllFunc(content, func(m []byte) []byte {
+		// The quote comes back decoded, so it is encoded again for the page's final decoding
+		quote := markdownBlocks(mdBlockquote.FindSubmatch(m)[1])
+		lines := strings.Split(quote, "\n")
+		for i, line := range lines {
+			lines[i] = strings.TrimSpace("> " + line)
+		}
+		return []byte("\n\n" + html.EscapeString(strings.Join(lines, "\n")) + "\n\n")
+	})
+
+	text := markdownBlocks(content)
+	return mdPlaceholder.ReplaceAllStringFunc(text, func(m string) string {
+		i, _ := strconv.Atoi(mdPlaceholder.FindStringSubmatch(m)[1])
+		return blocks[i]
+	})
+}
+
+// markdownBlocks turns the block structure of HTML into Markdown paragraphs
+// and lists, and drops the remaining markup
+func markdownBlocks(content []byte) string {
+	content = mdOrdered.ReplaceAllFunc(content, func(m []byte) []byte {
+		n := 0
+		return htmlItem.ReplaceAllFunc(mdOrdered.FindSubmatch(m)[1], func([]byte) []byte {
+			n++
+			return []byte(fmt.Sprintf("\n%d. ", n))
+		})
+	})
+	content = htmlItem.ReplaceAll(content, []byte("\n- "))
+	content = htmlBreak.ReplaceAll(content, []byte("\n"))
+	content = mdRule.ReplaceAll(content, []byte("\n\n---\n\n"))
+	content = htmlBlock.ReplaceAll(content, []byte("\n\n"))
+	content = htmlTag.ReplaceAll(content, nil)
+	return tidy(html.UnescapeString(string(content)))
+}
+
+// markdownTable renders the rows of an HTML table as a Markdown table, its
+// first row taken as the header
+func markdownTable(table []byte) string {
+	var rows []string
+	columns := 0
+	for _, row := range mdRow.FindAllSubmatch(table, -1) {
+		var cells []string
+		for _, cell := range mdCell.FindAllSubmatch(row[1], -1) {
+			cells = append(cells, strings.ReplaceAll(inline(string(cell[1])), "|", `\|`))
+		}
+		if len(cells) == 0 {
+			continue
+		}
+		columns = max(columns, len(cells))
+		rows = append(rows, "| "+strings.Join(cells, " | ")+" |")
+		if len(rows) == 1 {
+			rows = append(rows, "")
+		}
+	}
+	if len(rows) == 0 {
+		return ""
+	}
+	rows[1] = "|" + strings.Repeat(" --- |", columns)
+	if len(rows) == 2 {
+		rows = rows[:1]
+	}
+	return strings.Join(rows, "\n")
+}
+
+// wrapInline replaces the elements pattern matches with their text between
+// marker, dropping empty ones
+func wrapInline(content []byte, pattern *regexp.Regexp, marker string) []byte {
+	return pattern.ReplaceAllFunc(content, func(m []byte) []byte {
+		inner := pattern.FindSubmatch(m)[1]
+		text := strings.Join(strings.Fields(string(htmlTag.ReplaceAll(inner, nil))), " ")
+		if text == "" {
+			return nil
+		}
+		return []byte(marker + text + marker)
+	})
+}
+
+// inline returns an HTML fragment as one line of text, keeping its entities
+// encoded and the Markdown already converted
+func inline(fragment string) string {
+	return strings.Join(strings.Fields(htmlTag.ReplaceAllString(fragment, "")), " ")
+}
+
+// resolve returns ref as an absolute URL relative to base, encoded to survive
+// the final decoding of the page's entities
+func resolve(ref string, base *url.URL) string {
+	ref = strings.TrimSpace(ref)
+	if base != nil {
+		if u, err := base.Parse(ref); err == nil {
+			ref = u.String()
+		}
+	}
+	// Parentheses and spaces would end a Markdown link early
+	ref = strings.NewReplacer(" ", "%20", "(", "%28", ")", "%29").Replace(ref)
+	return html.EscapeString(ref)
+}
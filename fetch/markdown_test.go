@@ -0,0 +1,55 @@
+package fetch
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestHTMLToMarkdown(t *testing.T) {
+	content := []byte(`<h1>Hello <em>world</em></h1>
+<p>Read <a href="/docs?a=1&amp;b=2">the <b>docs</b></a> or <a href="#top">skip</a>, using <code>go test</code>.</p>
+<img src="img/logo.png" alt="Logo">
+<ol><li>one</li><li><p>two</p></li></ol>
+<ul><li>three</li></ul>
+<blockquote><p>Quoted &lt;text&gt;</p><p>More</p></blockquote>
+<pre><code>func main() {
+	fmt.Println("&lt;hi&gt;")
+}</code></pre>
+<table><tr><th>Name</th><th>Value</th></tr><tr><td>a | b</td><td>1</td></tr></table>
+<hr>
+<p>Fish &amp; chips</p>`)
+	base, _ := url.Parse("https://example.com/guide/")
+
+	expected := "# Hello *world*\n\n" +
+		"Read [the docs](https://example.com/docs?a=1&b=2) or skip, using `go test`.\n\n" +
+		"![Logo](https://example.com/guide/img/logo.png)\n\n" +
+		"1. one\n2. two\n\n" +
+		"- three\n\n" +
+		"> Quoted <text>\n>\n> More\n\n" +
+		"```\nfunc main() {\n\tfmt.Println(\"<hi>\")\n}\n```\n\n" +
+		"| Name | Value |\n| --- | --- |\n| a \\| b | 1 |\n\n" +
+		"---\n\n" +
+		"Fish & chips"
+	if got := htmlToMarkdown(content, base); got != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, got)
+	}
+}
+
+func TestExtract_Formats(t *testing.T) {
+	page := &Page{
+		FinalURL:    "https://example.com/post",
+		ContentType: "text/html",
+		Body:        []byte(`<html><head><title>Post</title></head><body><nav>Menu</nav><main><p>Some <a href="/x">link</a></p></main></body></html>`),
+	}
+	testCases := map[string]string{
+		FormatText:     "Some link",
+		FormatMarkdown: "Some [link](https://example.com/x)",
+		FormatHTML:     `<p>Some <a href="/x">link</a></p>`,
+	}
+	for format, expected := range testCases {
+		article := Extract(page, format)
+		if article.Title != "Post" || article.Text != expected {
+			t.Errorf("Extract(%s) = %+v, expected text %q", format, article, expected)
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package fetch
+
+import (
+	"math"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// BM25 parameters: term frequency saturation and length normalization
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// Sentences shorter than minQuoteTerms terms are headings, captions and
+// fragments rather than quotable statements; longer than maxQuoteRunes they
+// are usually text that lost its punctuation
+const (
+	minQuoteTerms = 4
+	maxQuoteRunes = 600
+)
+
+// Quote is a sentence of a page, located by its character offsets in the
+// page's readable text so it can be checked against the source
+type Quote struct {
+	URL  string `json:"url"`
+	Text string `json:"text"`
+	// Start and End are the offsets of the sentence, in characters, in the
+	// text format of Extract
+	Start int     `json:"start"`
+	End   int     `json:"end"`
+	Score float64 `json:"score"`
+}
+
+// Source is the readable text of a page to take quotes from
+type Source struct {
+	URL  string
+	Text string
+}
+
+// sentenceEnd reports whether r ends a sentence on its own, without a space
+// after it, as in Chinese and Japanese
+func sentenceEnd(r rune) bool {
+	return r == '。' || r == '！' || r == '？'
+}
+
+// splitSentences splits text into sentences, returned as quotes without a URL
+// or score. Sentences end at a full stop, question or exclamation mark
+// followed by a space, and at line breaks.
+func splitSentences(text string) []Quote {
+	var sentences []Quote
+	runes := []rune(text)
+	start := 0
+	flush := func(end int) {
+		s := string(runes[start:end])
+		trimmed := strings.TrimSpace(s)
+		if trimmed != "" {
+			lead := len([]rune(s)) - len([]rune(strings.TrimLeftFunc(s, unicode.IsSpace)))
+			begin := start + lead
+			sentences = append(sentences, Quote{Text: trimmed, Start: begin, End: begin + len([]rune(trimmed))})
+		}
+		start = end
+	}
+	for i, r := range runes {
+		switch {
+		case r == '\n':
+			flush(i)
+		case sentenceEnd(r):
+			flush(i + 1)
+		case (r == '.' || r == '!' || r == '?') && (i+1 == len(runes) || unicode.IsSpace(runes[i+1])):
+			flush(i + 1)
+		}
+	}
+	flush(len(runes))
+	return sentences
+}
+
+// terms splits text into lower-case terms for ranking. Chinese and Japanese
+// characters are terms of their own, since those languages don't space words.
+func terms(text string) []string {
+	var out []string
+	var word strings.Builder
+	endWord := func() {
+		if word.Len() > 0 {
+			out = append(out, word.String())
+			word.Reset()
+		}
+	}
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case isCJK(r):
+			endWord()
+			out = append(out, string(r))
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			word.WriteRune(r)
+		default:
+			endWord()
+		}
+	}
+	endWord()
+	return out
+}
+
+// RankQuotes returns the n sentences of sources most relevant to query, best
+// first, scored with BM25 over all the sentences of all the sources.
+// Sentences sharing no term with the query are never returned.
+func RankQuotes(query string, sources []Source, n int) []Quote {
+	queryTerms := terms(query)
+	if len(queryTerms) == 0 || n < 1 {
+		return nil
+	}
+
+	type candidate struct {
+		quote Quote
+		freq  map[string]int
+		size  int
+	}
+	var candidates []candidate
+	docFreq := make(map[string]int)
+	totalSize := 0
+	// Sentences repeated across pages, such as syndicated text, are quoted once
+	seen := make(map[string]bool)
+	for _, source := range sources {
+		for _, sentence := range splitSentences(source.Text) {
+			sentenceTerms := terms(sentence.Text)
+			if len(sentenceTerms) < minQuoteTerms || sentence.End-sentence.Start > maxQuoteRunes || seen[sentence.Text] {
+				continue
+			}
+			seen[sentence.Text] = true
+			freq := make(map[string]int)
+			for _, term := range sentenceTerms {
+				freq[term]++
+			}
+			for term := range freq {
+				docFreq[term]++
+			}
+			sentence.URL = source.URL
+			candidates = append(candidates, candidate{sentence, freq, len(sentenceTerms)})
+			totalSize += len(sentenceTerms)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	var unique []string
+	for _, term := range queryTerms {
+		if !slices.Contains(unique, term) {
+			unique = append(unique, term)
+		}
+	}
+	count := float64(len(candidates))
+	avgSize := float64(totalSize) / count
+	var quotes []Quote
+	for _, c := range candidates {
+		score := 0.0
+		for _, term := range unique {
+			tf := float64(c.freq[term])
+			if tf == 0 {
+				continue
+			}
+			df := float64(docFreq[term])
+			idf := math.Log(1 + (count-df+0.5)/(df+0.5))
+			score += idf * tf * (bm25K1 + 1) / (tf + bm25K1*(1-bm25B+bm25B*float64(c.size)/avgSize))
+		}
+		if score > 0 {
+			c.quote.Score = math.Round(score*1000) / 1000
+			quotes = append(quotes, c.quote)
+		}
+	}
+	// Ties keep the order of the sources and of the sentences in them
+	sort.SliceStable(quotes, func(i, j int) bool {
+		return quotes[i].Score > quotes[j].Score
+	})
+	if len(quotes) > n {
+		quotes = quotes[:n]
+	}
+	return quotes
+}
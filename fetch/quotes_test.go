@@ -0,0 +1,52 @@
+package fetch
+
+import (
+	"testing"
+)
+
+func TestSplitSentences(t *testing.T) {
+	text := "First sentence. Second one? Version 1.2 is out!\n\n  Indented line\n这是中文。第二句。"
+	expected := []string{"First sentence.", "Second one?", "Version 1.2 is out!", "Indented line", "这是中文。", "第二句。"}
+	sentences := splitSentences(text)
+	if len(sentences) != len(expected) {
+		t.Fatalf("Expected %d sentences, got %+v", len(expected), sentences)
+	}
+	runes := []rune(text)
+	for i, sentence := range sentences {
+		if sentence.Text != expected[i] {
+			t.Errorf("Sentence %d: expected %q, got %q", i, expected[i], sentence.Text)
+		}
+		// Offsets must point at the sentence in the original text
+		if got := string(runes[sentence.Start:sentence.End]); got != sentence.Text {
+			t.Errorf("Sentence %d: offsets %d-%d point at %q", i, sentence.Start, sentence.End, got)
+		}
+	}
+}
+
+func TestRankQuotes(t *testing.T) {
+	sources := []Source{
+		{URL: "https://a.example", Text: "Cookies help us improve the site. The Go garbage collector runs concurrently with the program. Go was designed at Google."},
+		{URL: "https://b.example", Text: "Subscribe to our newsletter for more news. A concurrent garbage collector keeps pause times short in Go."},
+		{URL: "https://c.example", Text: "The Go garbage collector runs concurrently with the program."},
+	}
+	quotes := RankQuotes("go garbage collector", sources, 2)
+	if len(quotes) != 2 {
+		t.Fatalf("Expected 2 quotes, got %+v", quotes)
+	}
+	if quotes[0].URL != "https://a.example" || quotes[0].Text != "The Go garbage collector runs concurrently with the program." {
+		t.Errorf("Unexpected best quote: %+v", quotes[0])
+	}
+	if quotes[0].Start != 34 || quotes[0].End != 94 {
+		t.Errorf("Unexpected offsets for the best quote: %d-%d", quotes[0].Start, quotes[0].End)
+	}
+	if quotes[1].URL != "https://b.example" || quotes[0].Score < quotes[1].Score {
+		t.Errorf("Expected the repeated sentence to be quoted once, got %+v", quotes)
+	}
+
+	if quotes := RankQuotes("kubernetes", sources, 5); len(quotes) != 0 {
+		t.Errorf("Expected no quotes for unrelated terms, got %+v", quotes)
+	}
+	if quotes := RankQuotes("  ", sources, 5); quotes != nil {
+		t.Errorf("Expected no quotes for an empty query, got %+v", quotes)
+	}
+}
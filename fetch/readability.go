@@ -0,0 +1,157 @@
+package fetch
+
+import (
+	"math"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Reading speeds used to estimate reading time. Chinese and Japanese text has
+// no spaces between words, so it is counted and read by the character.
+const (
+	wordsPerMinute      = 230
+	cjkCharsPerMinute   = 500
+	maxLanguageSample   = 20000
+	minStopWordsToGuess = 3
+)
+
+// htmlLang matches the language declared on the root element
+var htmlLang = regexp.MustCompile(`(?i)<html\b[^>]*\blang\s*=\s*["']?([a-z]{2,3})`)
+
+// stopWords are frequent words of languages written in the Latin script, used
+// to guess the language of pages that don't declare one
+var stopWords = map[string][]string{
+	"en": {"the", "and", "of", "to", "is", "that", "with", "for", "was", "are"},
+	"de": {"der", "die", "und", "das", "ist", "nicht", "mit", "den", "ein", "sich"},
+	"fr": {"le", "la", "les", "et", "des", "est", "une", "pour", "dans", "qui"},
+	"es": {"el", "los", "las", "del", "que", "por", "una", "con", "para", "es"},
+	"pt": {"os", "das", "não", "uma", "com", "para", "que", "do", "da", "em"},
+	"it": {"il", "gli", "della", "che", "è", "per", "una", "con", "non", "sono"},
+	"nl": {"de", "het", "een", "van", "en", "niet", "dat", "zijn", "op", "met"},
+}
+
+// Metrics describe how much there is to read on a page, so an agent can
+// decide which pages are worth reading in full
+type Metrics struct {
+	Words int `json:"words"`
+	// ReadingMinutes is the estimated time to read the page, at least one
+	// minute for a page with any text
+	ReadingMinutes int `json:"reading_minutes"`
+	// Language is the ISO 639 code of the page's language, declared by the
+	// page or guessed from its text, or empty when unknown
+	Language string `json:"language,omitempty"`
+}
+
+// measure returns the metrics of a page's text. declared is the language the
+// page or its headers name, if any.
+func measure(text, declared string) *Metrics {
+	var words, cjk int
+	inWord := false
+	for _, r := range text {
+		switch {
+		case isCJK(r):
+			cjk++
+			inWord = false
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if !inWord {
+				words++
+			}
+			inWord = true
+		case r == '\'' || r == '’' || r == '-':
+			// Contractions and hyphenated words are one word
+		default:
+			inWord = false
+		}
+	}
+
+	m := &Metrics{Words: words + cjk, Language: declared}
+	if m.Words > 0 {
+		minutes := float64(words)/wordsPerMinute + float64(cjk)/cjkCharsPerMinute
+		m.ReadingMinutes = max(1, int(math.Round(minutes)))
+	}
+	if m.Language == "" {
+		m.Language = guessLanguage(text)
+	}
+	return m
+}
+
+// declaredLanguage returns the primary language a page declares on its root
+// element or, failing that, in its Content-Language header
+func declaredLanguage(body []byte, contentLanguage string) string {
+	if m := htmlLang.FindSubmatch(body); m != nil {
+		return strings.ToLower(string(m[1]))
+	}
+	// The header may list several languages; only a single one is useful
+	if contentLanguage == "" || strings.Contains(contentLanguage, ",") {
+		return ""
+	}
+	primary, _, _ := strings.Cut(strings.TrimSpace(contentLanguage), "-")
+	if len(primary) < 2 || len(primary) > 3 {
+		return ""
+	}
+	return strings.ToLower(primary)
+}
+
+// guessLanguage guesses the language of text from its script and, for the
+// Latin script, its most frequent words. It returns "" when unsure.
+func guessLanguage(text string) string {
+	if len(text) > maxLanguageSample {
+		text = strings.ToValidUTF8(text[:maxLanguageSample], "")
+	}
+	var han, kana, hangul, cyrillic, arabic, latin int
+	for _, r := range text {
+		switch {
+		case unicode.Is(unicode.Hiragana, r) || unicode.Is(unicode.Katakana, r):
+			kana++
+		case unicode.Is(unicode.Han, r):
+			han++
+		case unicode.Is(unicode.Hangul, r):
+			hangul++
+		case unicode.Is(unicode.Cyrillic, r):
+			cyrillic++
+		case unicode.Is(unicode.Arabic, r):
+			arabic++
+		case unicode.Is(unicode.Latin, r):
+			latin++
+		}
+	}
+	switch {
+	case kana > 0 && kana+han > latin:
+		return "ja"
+	case hangul > latin && hangul > han:
+		return "ko"
+	case han > latin:
+		return "zh"
+	case cyrillic+arabic > latin || latin == 0:
+		// Too many languages share these scripts to tell them apart this way
+		return ""
+	}
+
+	counts := make(map[string]int)
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	}) {
+		counts[word]++
+	}
+	best, bestScore := "", 0
+	for lang, words := range stopWords {
+		score := 0
+		for _, word := range words {
+			score += counts[word]
+		}
+		if score > bestScore || (score == bestScore && lang < best) {
+			best, bestScore = lang, score
+		}
+	}
+	if bestScore < minStopWordsToGuess {
+		return ""
+	}
+	return best
+}
+
+// isCJK reports whether r is a Chinese or Japanese character. Korean puts
+// spaces between words, so Hangul is counted by the word.
+func isCJK(r rune) bool {
+	return unicode.Is(unicode.Han, r) || unicode.Is(unicode.Hiragana, r) || unicode.Is(unicode.Katakana, r)
+}
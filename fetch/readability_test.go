@@ -0,0 +1,82 @@
+package fetch
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMeasure(t *testing.T) {
+	text := strings.Repeat("The quick brown fox doesn't jump over the well-known dog. ", 50)
+	m := measure(text, "")
+	if m.Words != 500 || m.ReadingMinutes != 2 || m.Language != "en" {
+		t.Errorf("Unexpected metrics for English text: %+v", m)
+	}
+
+	m = measure(strings.Repeat("搜索引擎", 250), "")
+	if m.Words != 1000 || m.ReadingMinutes != 2 || m.Language != "zh" {
+		t.Errorf("Unexpected metrics for Chinese text: %+v", m)
+	}
+
+	m = measure("Hi", "fr")
+	if m.Words != 1 || m.ReadingMinutes != 1 || m.Language != "fr" {
+		t.Errorf("Expected a short page to take a minute in its declared language, got %+v", m)
+	}
+
+	if m := measure("", ""); m.Words != 0 || m.ReadingMinutes != 0 || m.Language != "" {
+		t.Errorf("Unexpected metrics for an empty page: %+v", m)
+	}
+}
+
+func TestGuessLanguage(t *testing.T) {
+	testCases := map[string]string{
+		"Der Hund ist nicht mit der Katze und das ist gut":          "de",
+		"Le chat est dans la maison et les enfants sont pour une":   "fr",
+		"El perro y los gatos que viven en la casa con una familia": "es",
+		"これは日本語の文章です":                                               "ja",
+		"한국어 문장입니다":                                                 "ko",
+		"Это русский текст":                                         "",
+		"Lorem ipsum":                                               "",
+	}
+	for text, expected := range testCases {
+		if got := guessLanguage(text); got != expected {
+			t.Errorf("guessLanguage(%q) = %q, expected %q", text, got, expected)
+		}
+	}
+}
+
+func TestDeclaredLanguage(t *testing.T) {
+	testCases := []struct {
+		body            string
+		contentLanguage string
+		expected        string
+	}{
+		{`<!DOCTYPE html><html class="no-js" lang="en-GB">`, "de", "en"},
+		{`<html>`, "pt-BR", "pt"},
+		{`<html>`, "en, de", ""},
+		{`<html>`, "", ""},
+	}
+	for _, tc := range testCases {
+		if got := declaredLanguage([]byte(tc.body), tc.contentLanguage); got != tc.expected {
+			t.Errorf("declaredLanguage(%q, %q) = %q, expected %q", tc.body, tc.contentLanguage, got, tc.expected)
+		}
+	}
+}
+
+func TestFetcher_FetchMetrics(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(`<html lang="de"><head><script>var a = "not counted";</script></head><body><p>Ein kurzer Text</p></body></html>`))
+	}))
+	defer server.Close()
+
+	page, err := testFetcher(t, 10, 4096).Fetch(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("Fetch returned an error: %v", err)
+	}
+	if page.Metrics == nil || page.Metrics.Words != 3 || page.Metrics.Language != "de" {
+		t.Errorf("Unexpected metrics: %+v", page.Metrics)
+	}
+}
@@ -0,0 +1,78 @@
+package fetch
+
+import (
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// DefaultContentTypes are the media types fetched when none are configured.
+// A trailing /* matches every subtype.
+var DefaultContentTypes = []string{
+	"text/*",
+	"application/json",
+	"application/xml",
+	"application/xhtml+xml",
+}
+
+// Reasons a page body is skipped instead of returned
+const (
+	// SkippedBinary marks non-text content such as images, PDFs and archives
+	SkippedBinary = "binary content"
+	// SkippedContentType marks text content whose type is not allowed
+	SkippedContentType = "content type not allowed"
+	// SkippedTooLarge marks pages whose declared length exceeds the page size limit
+	SkippedTooLarge = "too large"
+)
+
+// mediaType returns the lower-case media type of a Content-Type header, without parameters
+func mediaType(contentType string) string {
+	if contentType == "" {
+		return ""
+	}
+	mt, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		// Fall back to everything before the first parameter
+		mt, _, _ = strings.Cut(contentType, ";")
+	}
+	return strings.ToLower(strings.TrimSpace(mt))
+}
+
+// allowedType reports whether the media type matches one of the allowed patterns
+func allowedType(mt string, allowed []string) bool {
+	for _, pattern := range allowed {
+		pattern = strings.ToLower(strings.TrimSpace(pattern))
+		if prefix, ok := strings.CutSuffix(pattern, "/*"); ok {
+			if strings.HasPrefix(mt, prefix+"/") {
+				return true
+			}
+		} else if mt == pattern {
+			return true
+		}
+	}
+	return false
+}
+
+// isTextual reports whether the media type holds human-readable text
+func isTextual(mt string) bool {
+	return strings.HasPrefix(mt, "text/") ||
+		strings.HasSuffix(mt, "+json") || strings.HasSuffix(mt, "+xml") ||
+		mt == "application/json" || mt == "application/xml" || mt == "application/javascript"
+}
+
+// skipReason returns why content of the given media type is not returned, or
+// "" if it is allowed
+func skipReason(mt string, allowed []string) string {
+	if allowedType(mt, allowed) {
+		return ""
+	}
+	if isTextual(mt) {
+		return SkippedContentType
+	}
+	return SkippedBinary
+}
+
+// sniffType detects the media type of a body served without a Content-Type
+func sniffType(body []byte) string {
+	return mediaType(http.DetectContentType(body))
+}
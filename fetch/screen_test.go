@@ -0,0 +1,40 @@
+package fetch
+
+import "testing"
+
+func TestSkipReason(t *testing.T) {
+	testCases := []struct {
+		contentType string
+		expected    string
+	}{
+		{"text/html; charset=utf-8", ""},
+		{"text/plain", ""},
+		{"application/json", ""},
+		{"APPLICATION/XHTML+XML", ""},
+		{"application/pdf", SkippedBinary},
+		{"image/png", SkippedBinary},
+		{"application/octet-stream", SkippedBinary},
+		{"application/ld+json", SkippedContentType},
+		{"application/javascript", SkippedContentType},
+	}
+
+	for _, tc := range testCases {
+		if got := skipReason(mediaType(tc.contentType), DefaultContentTypes); got != tc.expected {
+			t.Errorf("Content type %q: expected %q, got %q", tc.contentType, tc.expected, got)
+		}
+	}
+
+	// Configured types replace the defaults
+	if got := skipReason("text/csv", []string{"text/html"}); got != SkippedContentType {
+		t.Errorf("Expected text/csv to be rejected, got %q", got)
+	}
+}
+
+func TestSniffType(t *testing.T) {
+	if got := sniffType([]byte("<!DOCTYPE html><html><body>hi</body></html>")); got != "text/html" {
+		t.Errorf("Expected text/html, got %q", got)
+	}
+	if got := sniffType([]byte("%PDF-1.7\n")); got != "application/pdf" {
+		t.Errorf("Expected application/pdf, got %q", got)
+	}
+}
@@ -0,0 +1,129 @@
+package fetch
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/netip"
+	"strings"
+	"syscall"
+)
+
+// blockedPrefixes are address ranges fetches may not connect to unless
+// allowlisted: loopback, private, link-local (including cloud metadata
+// endpoints), shared, and other non-public ranges
+var blockedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),      // "this" network
+	netip.MustParsePrefix("10.0.0.0/8"),     // RFC 1918
+	netip.MustParsePrefix("100.64.0.0/10"),  // carrier-grade NAT
+	netip.MustParsePrefix("127.0.0.0/8"),    // loopback
+	netip.MustParsePrefix("169.254.0.0/16"), // link-local, cloud metadata
+	netip.MustParsePrefix("172.16.0.0/12"),  // RFC 1918
+	netip.MustParsePrefix("192.0.0.0/24"),   // IETF protocol assignments
+	netip.MustParsePrefix("192.168.0.0/16"), // RFC 1918
+	netip.MustParsePrefix("198.18.0.0/15"),  // benchmarking
+	netip.MustParsePrefix("224.0.0.0/4"),    // multicast
+	netip.MustParsePrefix("240.0.0.0/4"),    // reserved, broadcast
+	netip.MustParsePrefix("::/128"),         // unspecified
+	netip.MustParsePrefix("::1/128"),        // loopback
+	netip.MustParsePrefix("64:ff9b:1::/48"), // local-use NAT64
+	netip.MustParsePrefix("fc00::/7"),       // unique local, incl. fd00:ec2::254 metadata
+	netip.MustParsePrefix("fe80::/10"),      // link-local
+	netip.MustParsePrefix("ff00::/8"),       // multicast
+}
+
+// BlockedError is returned for an internal address the guard refuses
+type BlockedError struct {
+	Addr netip.Addr
+}
+
+// Error implements the error interface
+func (e *BlockedError) Error() string {
+	return fmt.Sprintf("address %s is internal and blocked (add it to the fetch allowlist to permit it)", e.Addr)
+}
+
+// AddressGuard rejects connections to internal addresses. It checks the
+// address actually dialed, so DNS rebinding and redirects cannot bypass it.
+type AddressGuard struct {
+	allow []netip.Prefix
+}
+
+// NewAddressGuard creates a guard that additionally permits the allowlisted
+// IP addresses and CIDR ranges
+func NewAddressGuard(allowlist []string) (*AddressGuard, error) {
+	allow, err := ParseAllowlist(allowlist)
+	if err != nil {
+		return nil, err
+	}
+	return &AddressGuard{allow: allow}, nil
+}
+
+// ParseAllowlist parses IP addresses and CIDR ranges
+func ParseAllowlist(entries []string) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(entries))
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if strings.Contains(entry, "/") {
+			prefix, err := netip.ParsePrefix(entry)
+			if err != nil {
+				return nil, fmt.Errorf("invalid fetch allowlist entry %q: %w", entry, err)
+			}
+			prefixes = append(prefixes, prefix.Masked())
+			continue
+		}
+		addr, err := netip.ParseAddr(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid fetch allowlist entry %q: %w", entry, err)
+		}
+		prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+	return prefixes, nil
+}
+
+// Check returns an error if addr may not be fetched
+func (g *AddressGuard) Check(addr netip.Addr) error {
+	addr = addr.Unmap()
+	for _, prefix := range g.allow {
+		if prefix.Contains(addr) {
+			return nil
+		}
+	}
+	for _, prefix := range blockedPrefixes {
+		if prefix.Contains(addr) {
+			return &BlockedError{Addr: addr}
+		}
+	}
+	return nil
+}
+
+// Control is a net.Dialer Control function enforcing the guard on every connection
+func (g *AddressGuard) Control(_, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("unexpected dial address %q: %w", address, err)
+	}
+	return g.Check(addrPort.Addr())
+}
+
+// CheckHost returns an error if host, an IP address or a name, resolves to an
+// address that may not be fetched. It is for URLs handed to another service,
+// such as a renderer, whose connections the guard can't see; unlike Control it
+// can't catch DNS rebinding or redirects.
+func (g *AddressGuard) CheckHost(ctx context.Context, host string) error {
+	if addr, err := netip.ParseAddr(host); err == nil {
+		return g.Check(addr)
+	}
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return fmt.Errorf("failed to resolve %s: %w", host, err)
+	}
+	for _, addr := range addrs {
+		if err := g.Check(addr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package fetch
+
+import (
+	"context"
+	"net/netip"
+	"testing"
+)
+
+func TestAddressGuard(t *testing.T) {
+	guard, err := NewAddressGuard([]string{"10.1.2.0/24", "192.168.1.5"})
+	if err != nil {
+		t.Fatalf("NewAddressGuard returned an error: %v", err)
+	}
+
+	testCases := []struct {
+		addr    string
+		blocked bool
+	}{
+		{"93.184.216.34", false},
+		{"2606:2800:220:1:248:1893:25c8:1946", false},
+		{"127.0.0.1", true},
+		{"10.0.0.1", true},
+		{"172.16.5.4", true},
+		{"192.168.0.1", true},
+		{"169.254.169.254", true},
+		{"100.64.0.1", true},
+		{"0.0.0.0", true},
+		{"::1", true},
+		{"fd00:ec2::254", true},
+		{"fe80::1", true},
+		{"::ffff:127.0.0.1", true},
+		{"::ffff:10.0.0.1", true},
+		{"10.1.2.3", false},
+		{"192.168.1.5", false},
+		{"192.168.1.6", true},
+	}
+
+	for _, tc := range testCases {
+		err := guard.Check(netip.MustParseAddr(tc.addr))
+		if (err != nil) != tc.blocked {
+			t.Errorf("Address %s: expected blocked=%v, got %v", tc.addr, tc.blocked, err)
+		}
+	}
+}
+
+func TestAddressGuard_Control(t *testing.T) {
+	guard, _ := NewAddressGuard(nil)
+	if err := guard.Control("tcp4", "127.0.0.1:80", nil); err == nil {
+		t.Error("Expected loopback dial to be blocked, got nil")
+	}
+	if err := guard.Control("tcp6", "[2606:4700::1111]:443", nil); err != nil {
+		t.Errorf("Expected public dial to be allowed, got %v", err)
+	}
+}
+
+func TestAddressGuard_CheckHost(t *testing.T) {
+	guard, _ := NewAddressGuard(nil)
+	for _, host := range []string{"127.0.0.1", "::1", "localhost"} {
+		if err := guard.CheckHost(context.Background(), host); err == nil {
+			t.Errorf("Expected %s to be blocked, got nil", host)
+		}
+	}
+	if err := guard.CheckHost(context.Background(), "93.184.216.34"); err != nil {
+		t.Errorf("Expected a public address to be allowed, got %v", err)
+	}
+}
+
+func TestParseAllowlist(t *testing.T) {
+	if _, err := ParseAllowlist([]string{"10.0.0.0/8", " 127.0.0.1 ", ""}); err != nil {
+		t.Errorf("Expected valid allowlist, got %v", err)
+	}
+	for _, entry := range []string{"10.0.0.0/33", "internal.example.com", "10.0.0"} {
+		if _, err := ParseAllowlist([]string{entry}); err == nil {
+			t.Errorf("Expected error for %q, got nil", entry)
+		}
+	}
+}
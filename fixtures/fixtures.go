@@ -0,0 +1,223 @@
+// Package fixtures provides sanitized Bocha Web Search API responses and a
+// fake API, for this repository's tests, the fakeserver subcommand, and
+// downstream users testing code that talks to a Bocha-compatible endpoint
+package fixtures
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Fixture names
+const (
+	// Web is an English query with three web page results and no images
+	Web = "web"
+	// WebImages is a real Chinese-language response with web page and image results
+	WebImages = "web_images"
+	// Empty is a successful response with no results
+	Empty = "empty"
+	// Unauthorized is the error body returned for a bad API key (HTTP 401)
+	Unauthorized = "unauthorized"
+	// RateLimited is the error body returned when the rate limit is exceeded (HTTP 429)
+	RateLimited = "rate_limited"
+	// SchemaMinimal contains only the fields the client relies on
+	SchemaMinimal = "schema_minimal"
+	// SchemaExtra contains fields added by newer API versions and nulls where objects are expected
+	SchemaExtra = "schema_extra"
+	// PeopleAlsoAsk is the Web fixture with "people also ask" questions, including a duplicate and a blank one
+	PeopleAlsoAsk = "people_also_ask"
+	// Entity is the Web fixture with a knowledge panel, including a fact with no value
+	Entity = "entity"
+	// Corrected is the Web fixture for a misspelled query the API corrected
+	Corrected = "corrected"
+	// Legacy is a response in the flat results/summary shape of older endpoints
+	Legacy = "legacy"
+	// Pro is the Web fixture as returned by the pro endpoint, with an answer and a rich snippet
+	Pro = "pro"
+	// AISearch is an AI search response with web pages, weather, stock and
+	// encyclopedia cards, a card of another type, an answer and a follow-up question
+	AISearch = "ai_search"
+	// AgentSearch is a scholar agent's response with academic papers, one of
+	// them without a URL, and a follow-up question
+	AgentSearch = "agent_search"
+)
+
+// statusCodes maps error fixtures to the HTTP status they are served with
+var statusCodes = map[string]int{
+	Unauthorized: http.StatusUnauthorized,
+	RateLimited:  http.StatusTooManyRequests,
+}
+
+//go:embed responses/*.json
+var responses embed.FS
+
+// Names returns the names of every fixture, sorted
+func Names() []string {
+	entries, _ := responses.ReadDir("responses")
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, strings.TrimSuffix(entry.Name(), ".json"))
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Response returns the raw JSON body of the named fixture
+func Response(name string) ([]byte, error) {
+	data, err := responses.ReadFile(path.Join("responses", name+".json"))
+	if err != nil {
+		return nil, fmt.Errorf("unknown fixture %q", name)
+	}
+	return data, nil
+}
+
+// MustResponse is like Response but panics if the fixture does not exist
+func MustResponse(name string) []byte {
+	data, err := Response(name)
+	if err != nil {
+		panic(err)
+	}
+	return data
+}
+
+// StatusCode returns the HTTP status the named fixture is served with
+func StatusCode(name string) int {
+	if code, ok := statusCodes[name]; ok {
+		return code
+	}
+	return http.StatusOK
+}
+
+// Request is a search request received by the fake API
+type Request struct {
+	Query         string `json:"query"`
+	Freshness     string `json:"freshness"`
+	Count         int    `json:"count"`
+	Summary       bool   `json:"summary"`
+	Authorization string `json:"-"`
+}
+
+// API is an http.Handler emulating the Bocha Web Search API. The query selects
+// the response:
+//
+//   - a fixture name, e.g. "empty" or "unauthorized", returns that fixture
+//   - "slow:<duration> <query>" waits before answering <query>
+//   - "status:<code> <query>" fails with that HTTP status
+//   - anything else returns the Web fixture
+type API struct {
+	// Latency delays every response
+	Latency time.Duration
+	// APIKey, when set, is required as the bearer token
+	APIKey string
+
+	mu       sync.Mutex
+	requests []Request
+}
+
+// Requests returns every request received so far
+func (a *API) Requests() []Request {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return append([]Request(nil), a.requests...)
+}
+
+// ServeHTTP records the request and answers it with a fixture
+func (a *API) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req Request
+	body, _ := io.ReadAll(io.LimitReader(r.Body, 1024*1024))
+	if err := json.Unmarshal(body, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	req.Authorization = r.Header.Get("Authorization")
+
+	a.mu.Lock()
+	a.requests = append(a.requests, req)
+	a.mu.Unlock()
+
+	if a.APIKey != "" && req.Authorization != "Bearer "+a.APIKey {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write(MustResponse(Unauthorized))
+		return
+	}
+
+	delay := a.Latency
+	status := 0
+	name := strings.TrimSpace(req.Query)
+	for {
+		directive, rest, _ := strings.Cut(name, " ")
+		if value, ok := strings.CutPrefix(directive, "slow:"); ok {
+			if d, err := time.ParseDuration(value); err == nil {
+				delay += d
+			}
+		} else if value, ok := strings.CutPrefix(directive, "status:"); ok {
+			if code, err := strconv.Atoi(value); err == nil && code >= 100 && code <= 599 {
+				status = code
+			}
+		} else {
+			break
+		}
+		name = strings.TrimSpace(rest)
+	}
+
+	if delay > 0 {
+		select {
+		case <-time.After(delay):
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	if status != 0 && status != http.StatusOK {
+		writeError(w, status, fmt.Sprintf("simulated status %d", status))
+		return
+	}
+
+	data, err := Response(name)
+	if err != nil {
+		name = Web
+		data = MustResponse(Web)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(StatusCode(name))
+	_, _ = w.Write(data)
+}
+
+// Server is a fake API listening on a local port, for tests
+type Server struct {
+	*httptest.Server
+	*API
+}
+
+// NewServer starts a fake API server; callers must Close it
+func NewServer() *Server {
+	api := &API{}
+	return &Server{
+		Server: httptest.NewServer(api),
+		API:    api,
+	}
+}
+
+// writeError writes a Bocha-style JSON error body
+func writeError(w http.ResponseWriter, status int, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": msg})
+}
@@ -0,0 +1,70 @@
+package fixtures
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestResponses(t *testing.T) {
+	names := Names()
+	if len(names) != 14 {
+		t.Fatalf("Expected 14 fixtures, got %v", names)
+	}
+	for _, name := range names {
+		var v map[string]interface{}
+		if err := json.Unmarshal(MustResponse(name), &v); err != nil {
+			t.Errorf("Fixture %s is not valid JSON: %v", name, err)
+		}
+	}
+	if _, err := Response("missing"); err == nil {
+		t.Error("Expected error for unknown fixture, got nil")
+	}
+}
+
+func TestServer(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	post := func(query string) *http.Response {
+		body, _ := json.Marshal(Request{Query: query, Count: 10})
+		req, _ := http.NewRequest(http.MethodPost, server.URL, bytes.NewReader(body))
+		req.Header.Set("Authorization", "Bearer test-key")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		resp.Body.Close()
+		return resp
+	}
+
+	if resp := post("anything"); resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", resp.StatusCode)
+	}
+	if resp := post(Unauthorized); resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected status 401, got %d", resp.StatusCode)
+	}
+	if resp := post("status:503 golang"); resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("Expected status 503, got %d", resp.StatusCode)
+	}
+
+	start := time.Now()
+	if resp := post("slow:50ms empty"); resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", resp.StatusCode)
+	}
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("Expected a delay of at least 50ms, got %s", elapsed)
+	}
+
+	server.APIKey = "expected-key"
+	if resp := post("golang"); resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected status 401 for the wrong API key, got %d", resp.StatusCode)
+	}
+
+	requests := server.Requests()
+	if len(requests) != 5 || requests[0].Query != "anything" || requests[0].Authorization != "Bearer test-key" {
+		t.Errorf("Unexpected recorded requests: %+v", requests)
+	}
+}
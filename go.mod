@@ -2,10 +2,22 @@ module com.moguyn/mcp-go-search
 
 go 1.24.0
 
-require github.com/mark3labs/mcp-go v0.12.0
+require (
+	github.com/mark3labs/mcp-go v0.12.0
+	google.golang.org/grpc v1.75.1
+	google.golang.org/protobuf v1.36.6
+)
+
+require (
+	golang.org/x/net v0.41.0 // indirect
+	golang.org/x/sys v0.33.0 // indirect
+	golang.org/x/text v0.26.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20250707201910-8d1bb00bc6a7 // indirect
+)
 
 require (
 	github.com/google/uuid v1.6.0 // indirect
+	golang.org/x/sync v0.15.0
 	golang.org/x/time v0.11.0
 	gopkg.in/yaml.v3 v3.0.1
 )
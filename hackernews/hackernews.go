@@ -0,0 +1,163 @@
+// Package hackernews searches Hacker News stories through the Algolia Hacker
+// News Search API, which needs no key
+package hackernews
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"com.moguyn/mcp-go-search/config"
+	"com.moguyn/mcp-go-search/params"
+	"com.moguyn/mcp-go-search/search"
+)
+
+// Orders of search results
+const (
+	// SortRelevance ranks stories by relevance, then points and comments
+	SortRelevance = "relevance"
+	// SortDate lists the most recent stories first
+	SortDate = "date"
+)
+
+// discussionURL is the Hacker News page of an item, by ID
+const discussionURL = "https://news.ycombinator.com/item?id=%s"
+
+// maxAge maps the server's freshness values to how long ago a story may have
+// been posted
+var maxAge = map[string]time.Duration{
+	"day":     24 * time.Hour,
+	"week":    7 * 24 * time.Hour,
+	"month":   30 * 24 * time.Hour,
+	"oneYear": 365 * 24 * time.Hour,
+}
+
+// Story is a Hacker News story matching a search
+type Story struct {
+	ID          string    `json:"objectID"`
+	Title       string    `json:"title"`
+	URL         string    `json:"url"`
+	Author      string    `json:"author"`
+	Points      int       `json:"points"`
+	NumComments int       `json:"num_comments"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// DiscussionURL returns the story's comment page on Hacker News
+func (s Story) DiscussionURL() string {
+	return fmt.Sprintf(discussionURL, s.ID)
+}
+
+// Result is the stories found by a search
+type Result struct {
+	Query string  `json:"query"`
+	Sort  string  `json:"sort"`
+	Total int     `json:"total"`
+	Hits  []Story `json:"hits"`
+}
+
+// Client searches Hacker News
+type Client struct {
+	apiURL      string
+	httpClient  *http.Client
+	rateLimiter *rate.Limiter
+	now         func() time.Time
+}
+
+// NewClient creates a new Hacker News client with the provided configuration
+func NewClient(cfg *config.Config) *Client {
+	return &Client{
+		apiURL:     strings.TrimSuffix(cfg.HNAPIURL, "/"),
+		httpClient: search.NewHTTPClient(cfg),
+		// The API allows 10,000 requests an hour from one address
+		rateLimiter: rate.NewLimiter(rate.Limit(2), 5),
+		now:         time.Now,
+	}
+}
+
+// Search finds stories matching query, in the given order and at most count
+// of them. An empty sort means SortRelevance.
+func (c *Client) Search(ctx context.Context, query, sort, freshness string, count int) (*Result, error) {
+	p, _, err := params.Normalize(params.Search{Query: query, Freshness: freshness, Count: count}, params.DefaultLimits())
+	if err != nil {
+		return nil, err
+	}
+	endpoint := "/search"
+	switch sort {
+	case "", SortRelevance:
+		sort = SortRelevance
+	case SortDate:
+		endpoint = "/search_by_date"
+	default:
+		return nil, fmt.Errorf("invalid sort %q, must be one of: %s, %s", sort, SortRelevance, SortDate)
+	}
+
+	values := url.Values{}
+	values.Set("query", p.Query)
+	values.Set("tags", "story")
+	values.Set("hitsPerPage", strconv.Itoa(p.Count))
+	if age, ok := maxAge[p.Freshness]; ok {
+		values.Set("numericFilters", "created_at_i>"+strconv.FormatInt(c.now().Add(-age).Unix(), 10))
+	}
+
+	if err := c.rateLimiter.Wait(ctx); err != nil {
+		return nil, fmt.Errorf("rate limit exceeded: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.apiURL+endpoint+"?"+values.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTTP request: %w", err)
+	}
+	req.Header.Set("User-Agent", "BochaWebSearchMCPServer/1.0")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request to Hacker News API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 10*1024*1024)) // 10MB limit
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Hacker News API response body: %w", err)
+	}
+	result, err := parseResponse(resp.StatusCode, body)
+	if err != nil {
+		return nil, err
+	}
+	result.Query = p.Query
+	result.Sort = sort
+	return result, nil
+}
+
+// parseResponse decodes a search response body, turning errors into Go errors
+func parseResponse(statusCode int, body []byte) (*Result, error) {
+	var errorResp struct {
+		Message string `json:"message"`
+	}
+	if statusCode != http.StatusOK {
+		if err := json.Unmarshal(body, &errorResp); err == nil && errorResp.Message != "" {
+			return nil, fmt.Errorf("hacker news api error (status %d): %s", statusCode, errorResp.Message)
+		}
+		return nil, fmt.Errorf("hacker news api returned status code %d", statusCode)
+	}
+
+	var searchResp struct {
+		Hits   []Story `json:"hits"`
+		NbHits int     `json:"nbHits"`
+	}
+	if err := json.Unmarshal(body, &searchResp); err != nil {
+		return nil, fmt.Errorf("failed to parse hacker news api response: %w", err)
+	}
+	result := &Result{Total: searchResp.NbHits, Hits: searchResp.Hits}
+	if result.Hits == nil {
+		result.Hits = []Story{}
+	}
+	return result, nil
+}
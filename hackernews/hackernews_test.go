@@ -0,0 +1,128 @@
+package hackernews
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"com.moguyn/mcp-go-search/config"
+)
+
+const searchResponse = `{
+	"hits": [
+		{
+			"created_at": "2024-02-06T17:02:11Z",
+			"title": "Go 1.22 is released",
+			"url": "https://go.dev/blog/go1.22",
+			"author": "gopher",
+			"points": 512,
+			"num_comments": 230,
+			"objectID": "39312960",
+			"_tags": ["story", "author_gopher", "story_39312960"]
+		},
+		{
+			"created_at": "2024-02-07T08:15:00Z",
+			"title": "Ask HN: How do you structure Go projects?",
+			"url": null,
+			"author": "newbie",
+			"points": 12,
+			"num_comments": 8,
+			"objectID": "39320001"
+		}
+	],
+	"nbHits": 1843,
+	"page": 0,
+	"nbPages": 50,
+	"hitsPerPage": 2
+}`
+
+// newTestClient returns a client searching the given server
+func newTestClient(url string) *Client {
+	return NewClient(&config.Config{HNAPIURL: url, HTTPTimeout: 5 * time.Second})
+}
+
+func TestClient_Search(t *testing.T) {
+	now := time.Date(2024, 2, 8, 0, 0, 0, 0, time.UTC)
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		query := r.URL.Query()
+		if query.Get("query") != "go release" || query.Get("tags") != "story" || query.Get("hitsPerPage") != "2" {
+			t.Errorf("Unexpected parameters: %v", query)
+		}
+		_, _ = w.Write([]byte(searchResponse))
+	}))
+	defer server.Close()
+
+	client := newTestClient(server.URL + "/")
+	client.now = func() time.Time { return now }
+	result, err := client.Search(context.Background(), "go release", "", "", 2)
+	if err != nil {
+		t.Fatalf("Search returned an error: %v", err)
+	}
+	if result.Total != 1843 || result.Sort != SortRelevance || len(result.Hits) != 2 {
+		t.Fatalf("Unexpected result: %+v", result)
+	}
+	first := result.Hits[0]
+	if first.Points != 512 || first.NumComments != 230 || first.DiscussionURL() != "https://news.ycombinator.com/item?id=39312960" {
+		t.Errorf("Unexpected story: %+v", first)
+	}
+	if result.Hits[1].URL != "" {
+		t.Errorf("Expected no URL for a text post, got %q", result.Hits[1].URL)
+	}
+
+	if _, err := client.Search(context.Background(), "go release", SortDate, "week", 2); err != nil {
+		t.Fatalf("Search returned an error: %v", err)
+	}
+	if len(paths) != 2 || paths[0] != "/search" || paths[1] != "/search_by_date" {
+		t.Errorf("Expected a relevance then a date search, got %v", paths)
+	}
+}
+
+func TestClient_Search_Freshness(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if filter := r.URL.Query().Get("numericFilters"); filter != "created_at_i>1707264000" {
+			t.Errorf("Expected stories from the last day, got %q", filter)
+		}
+		_, _ = w.Write([]byte(`{"hits": [], "nbHits": 0}`))
+	}))
+	defer server.Close()
+
+	client := newTestClient(server.URL)
+	client.now = func() time.Time { return time.Date(2024, 2, 8, 0, 0, 0, 0, time.UTC) }
+	result, err := client.Search(context.Background(), "go", SortDate, "day", 10)
+	if err != nil || result.Hits == nil || len(result.Hits) != 0 {
+		t.Errorf("Expected an empty story list, got %v (%v)", result, err)
+	}
+}
+
+func TestClient_Search_Errors(t *testing.T) {
+	client := newTestClient("http://127.0.0.1:0")
+	if _, err := client.Search(context.Background(), "go", "popular", "", 10); err == nil {
+		t.Error("Expected error for an unknown sort, got nil")
+	}
+	if _, err := client.Search(context.Background(), "", "", "", 10); err == nil {
+		t.Error("Expected error for an empty query, got nil")
+	}
+	if _, err := client.Search(context.Background(), "go", "", "hour", 10); err == nil {
+		t.Error("Expected error for an invalid freshness, got nil")
+	}
+}
+
+func TestParseResponse_Errors(t *testing.T) {
+	tests := []struct {
+		statusCode int
+		body       string
+		expected   string
+	}{
+		{http.StatusBadRequest, `{"message": "Invalid syntax for numeric value", "status": 400}`, "hacker news api error (status 400): Invalid syntax for numeric value"},
+		{http.StatusBadGateway, `<html>bad gateway</html>`, "hacker news api returned status code 502"},
+	}
+	for _, tt := range tests {
+		if _, err := parseResponse(tt.statusCode, []byte(tt.body)); err == nil || err.Error() != tt.expected {
+			t.Errorf("Expected %q, got %v", tt.expected, err)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+// Package jsonpath evaluates the JSONPath-style paths that pick values out of
+// a decoded JSON document, such as "$.data.items" or "links[0].href"
+package jsonpath
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// step is one key or array index of a path
+type step struct {
+	key     string
+	index   int
+	isIndex bool
+}
+
+// Path is a compiled path. The zero Path selects the document itself.
+type Path struct {
+	expr  string
+	steps []step
+}
+
+// Compile parses a path made of dot-separated keys, array indexes in square
+// brackets and quoted keys in square brackets for keys containing dots, with
+// an optional leading "$" for the document. A negative index counts from the
+// end of the array.
+func Compile(expr string) (Path, error) {
+	p := Path{expr: expr}
+	rest := strings.TrimPrefix(strings.TrimSpace(expr), "$")
+	for first := true; rest != ""; first = false {
+		switch {
+		case rest[0] == '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return Path{}, fmt.Errorf("invalid path %q: unclosed bracket", expr)
+			}
+			inner := rest[1:end]
+			if len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0] {
+				p.steps = append(p.steps, step{key: inner[1 : len(inner)-1]})
+			} else {
+				index, err := strconv.Atoi(inner)
+				if err != nil {
+					return Path{}, fmt.Errorf("invalid path %q: %q is neither an index nor a quoted key", expr, inner)
+				}
+				p.steps = append(p.steps, step{index: index, isIndex: true})
+			}
+			rest = rest[end+1:]
+		case rest[0] == '.' || first:
+			if rest[0] == '.' {
+				rest = rest[1:]
+			}
+			end := strings.IndexAny(rest, ".[]")
+			if end < 0 {
+				end = len(rest)
+			}
+			if end == 0 {
+				return Path{}, fmt.Errorf("invalid path %q: empty key", expr)
+			}
+			p.steps = append(p.steps, step{key: rest[:end]})
+			rest = rest[end:]
+		default:
+			return Path{}, fmt.Errorf("invalid path %q: unexpected %q", expr, rest[0])
+		}
+	}
+	return p, nil
+}
+
+// String returns the path as it was written
+func (p Path) String() string {
+	return p.expr
+}
+
+// Lookup returns the value the path selects in doc, a document decoded into
+// maps, slices and scalars, and whether there is one
+func (p Path) Lookup(doc any) (any, bool) {
+	value := doc
+	for _, s := range p.steps {
+		if s.isIndex {
+			items, ok := value.([]any)
+			if !ok {
+				return nil, false
+			}
+			index := s.index
+			if index < 0 {
+				index += len(items)
+			}
+			if index < 0 || index >= len(items) {
+				return nil, false
+			}
+			value = items[index]
+			continue
+		}
+		fields, ok := value.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		if value, ok = fields[s.key]; !ok {
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+// LookupString returns the value the path selects in doc as text, or "" when
+// there is none. Numbers and booleans are written as in JSON; objects, arrays
+// and null give "".
+func (p Path) LookupString(doc any) string {
+	value, ok := p.Lookup(doc)
+	if !ok {
+		return ""
+	}
+	switch v := value.(type) {
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		return ""
+	}
+}
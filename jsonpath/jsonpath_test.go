@@ -0,0 +1,69 @@
+package jsonpath
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+const document = `{
+	"data": {
+		"items": [
+			{"title": "Go", "links": [{"href": "https://go.dev/"}], "score": 12.5, "meta.date": "2025-01-02"},
+			{"title": "Rust", "links": [], "pinned": true}
+		],
+		"total": 1234567890123
+	}
+}`
+
+func TestPath_Lookup(t *testing.T) {
+	decoder := json.NewDecoder(strings.NewReader(document))
+	decoder.UseNumber()
+	var doc any
+	if err := decoder.Decode(&doc); err != nil {
+		t.Fatalf("Failed to decode document: %v", err)
+	}
+
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{"$.data.items[0].title", "Go"},
+		{"data.items[0].links[0].href", "https://go.dev/"},
+		{"data.items[-1].title", "Rust"},
+		{"$['data'].items[0]['meta.date']", "2025-01-02"},
+		{"data.items[0].score", "12.5"},
+		{"data.items[1].pinned", "true"},
+		{"data.total", "1234567890123"},
+		{"data.items[1].links[0].href", ""},
+		{"data.items[5].title", ""},
+		{"data.items.title", ""},
+		{"data.items", ""},
+	}
+	for _, tt := range tests {
+		path, err := Compile(tt.path)
+		if err != nil {
+			t.Errorf("Compile(%q) returned an error: %v", tt.path, err)
+			continue
+		}
+		if got := path.LookupString(doc); got != tt.expected {
+			t.Errorf("Expected %q for %s, got %q", tt.expected, tt.path, got)
+		}
+	}
+
+	root, err := Compile("$")
+	if err != nil {
+		t.Fatalf("Compile returned an error: %v", err)
+	}
+	if value, ok := root.Lookup(doc); !ok || value == nil {
+		t.Error("Expected $ to select the document")
+	}
+}
+
+func TestCompile_Errors(t *testing.T) {
+	for _, expr := range []string{"data..items", "items[0", "items[first]", "items]", "$.", "data.items[0]title"} {
+		if _, err := Compile(expr); err == nil {
+			t.Errorf("Expected error for %q, got nil", expr)
+		}
+	}
+}
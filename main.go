@@ -1,18 +1,68 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"log"
 	"os"
+	"strings"
+	"sync/atomic"
 	"time"
+	_ "time/tzdata" // Zone data for TIMEZONE in images that lack it
 
 	"github.com/mark3labs/mcp-go/server"
 
+	"com.moguyn/mcp-go-search/admin"
 	"com.moguyn/mcp-go-search/config"
+	"com.moguyn/mcp-go-search/fetch"
+	"com.moguyn/mcp-go-search/hackernews"
 	"com.moguyn/mcp-go-search/mcp"
+	"com.moguyn/mcp-go-search/monitor"
+	"com.moguyn/mcp-go-search/pool"
+	"com.moguyn/mcp-go-search/privacy"
+	"com.moguyn/mcp-go-search/screenshot"
 	"com.moguyn/mcp-go-search/search"
+	_ "com.moguyn/mcp-go-search/search/providers/arxiv"
+	_ "com.moguyn/mcp-go-search/search/providers/baidu"
+	"com.moguyn/mcp-go-search/search/providers/bocha"
+	_ "com.moguyn/mcp-go-search/search/providers/brave"
+	_ "com.moguyn/mcp-go-search/search/providers/command"
+	_ "com.moguyn/mcp-go-search/search/providers/generic"
+	_ "com.moguyn/mcp-go-search/search/providers/google"
+	_ "com.moguyn/mcp-go-search/search/providers/grpcsearch"
+	_ "com.moguyn/mcp-go-search/search/providers/jina"
+	_ "com.moguyn/mcp-go-search/search/providers/pubmed"
+	_ "com.moguyn/mcp-go-search/search/providers/stackexchange"
+	"com.moguyn/mcp-go-search/stats"
+	"com.moguyn/mcp-go-search/store"
 )
 
+// Log levels, in increasing order of severity
+const (
+	levelDebug int32 = iota - 1
+	levelInfo
+	levelError
+)
+
+// logLevel is the minimum level logged by every Logger; the zero value is info
+var logLevel atomic.Int32
+
+// SetLogLevel changes the minimum level logged by every Logger
+func SetLogLevel(level string) error {
+	switch level {
+	case "debug":
+		logLevel.Store(levelDebug)
+	case "info", "":
+		logLevel.Store(levelInfo)
+	case "error":
+		logLevel.Store(levelError)
+	default:
+		return fmt.Errorf("invalid log level %q, must be one of: debug, info, error", level)
+	}
+	return nil
+}
+
 // Logger provides a simple structured logging interface
 type Logger struct {
 	prefix string
@@ -23,8 +73,19 @@ func NewLogger(prefix string) *Logger {
 	return &Logger{prefix: prefix}
 }
 
+// Debug logs a debug message with structured data
+func (l *Logger) Debug(msg string, data map[string]interface{}) {
+	if logLevel.Load() > levelDebug {
+		return
+	}
+	l.log("DEBUG", msg, data)
+}
+
 // Info logs an informational message with structured data
 func (l *Logger) Info(msg string, data map[string]interface{}) {
+	if logLevel.Load() > levelInfo {
+		return
+	}
 	l.log("INFO", msg, data)
 }
 
@@ -75,32 +136,696 @@ func runServer() error {
 		return err
 	}
 
-	// Create a new MCP server
+	if err := SetLogLevel(cfg.LogLevel); err != nil {
+		logger.Error("Configuration error", err, nil)
+		return err
+	}
+	logger.Info("Configuration loaded", cfg.Summary())
+
+	// Create a new MCP server. Tool list changes are announced so tools
+	// withheld by the readiness gate can be advertised later.
 	s := server.NewMCPServer(
 		cfg.ServerName,
 		cfg.ServerVersion,
 		server.WithLogging(),
+		server.WithToolCapabilities(true),
+		server.WithResourceCapabilities(false, false),
 	)
 
 	// Create the search service
-	searchService := search.NewBochaServiceWithConfig(cfg)
+	backend, err := search.NewProvider(cfg)
+	if err != nil {
+		logger.Error("Search provider error", err, nil)
+		return err
+	}
+	if closer, ok := backend.(io.Closer); ok {
+		defer closer.Close()
+	}
+	if bochaService, ok := backend.(*bocha.Service); ok && len(cfg.BochaRegions) > 1 {
+		probeRegions(bochaService, logger)
+	}
+	var searchService search.Service = backend
+	warmers := make(map[string]search.Warmer)
+	if warmer, ok := backend.(search.Warmer); ok {
+		warmers[backend.Name()] = warmer
+	}
+	keys := make(map[string]search.KeyRotator)
+	if rotator, ok := backend.(search.KeyRotator); ok {
+		keys[backend.Name()] = rotator
+	}
+
+	// Make the provider toggleable at runtime and record every upstream call
+	collector := stats.NewCollector()
+	if cfg.QueryLogPolicy != "" {
+		collector.SetQueryPolicy(cfg.QueryLogPolicy)
+	}
+	scrubber, err := privacy.NewScrubber(cfg.PIIScrub, cfg.PIIPatterns)
+	if err != nil {
+		logger.Error("PII scrubber error", err, nil)
+		return err
+	}
+	collector.SetScrubber(scrubber)
+	provider := search.NewToggleService(backend.Name(), searchService)
+	searchService = search.NewInstrumentedService(provider.Name(), provider, collector)
+	upstream := searchService
+
+	// Background, batched and fanned-out upstream calls share one bounded worker pool
+	workers := pool.New(cfg.WorkerPoolSize, cfg.JobTimeout)
+
+	// Query the aggregate providers alongside the configured one
+	var aggregator *search.Aggregator
+	if len(cfg.AggregateProviders) > 0 {
+		members := []search.AggregateMember{{Name: backend.Name(), Service: searchService}}
+		for _, name := range cfg.AggregateProviders {
+			if name == backend.Name() {
+				continue
+			}
+			member, err := newNamedProvider(cfg, name)
+			if err != nil {
+				logger.Error("Aggregate provider error", err, nil)
+				return err
+			}
+			if closer, ok := member.(io.Closer); ok {
+				defer closer.Close()
+			}
+			if warmer, ok := member.(search.Warmer); ok {
+				warmers[name] = warmer
+			}
+			if rotator, ok := member.(search.KeyRotator); ok {
+				keys[name] = rotator
+			}
+			members = append(members, search.AggregateMember{Name: name, Service: search.NewInstrumentedService(name, member, collector)})
+		}
+		aggregator = search.NewAggregator(members, cfg.ProviderTimeout, workers)
+		if cfg.AggregateAutoWeight {
+			aggregator.WeightBy(collector)
+		}
+		if cfg.AggregateMerge == config.AggregateMergeRRF {
+			aggregator.FuseRanks()
+		}
+		searchService = aggregator
+	}
+
+	// Let a search select one of the provider overrides instead
+	var router *search.Router
+	if len(cfg.ProviderOverrides) > 0 {
+		overrides := make(map[string]search.Service, len(cfg.ProviderOverrides))
+		for _, name := range cfg.ProviderOverrides {
+			if name == backend.Name() {
+				continue
+			}
+			override, err := newNamedProvider(cfg, name)
+			if err != nil {
+				logger.Error("Provider override error", err, nil)
+				return err
+			}
+			if closer, ok := override.(io.Closer); ok {
+				defer closer.Close()
+			}
+			if warmer, ok := override.(search.Warmer); ok {
+				warmers[name] = warmer
+			}
+			if rotator, ok := override.(search.KeyRotator); ok {
+				keys[name] = rotator
+			}
+			overrides[name] = search.NewInstrumentedService(name, override, collector)
+		}
+		router = search.NewRouter(backend.Name(), searchService, overrides)
+		searchService = router
+	}
+
+	// Keep the connections to the providers open so searches skip the handshake
+	if cfg.WarmStart {
+		warmCtx, stopWarming := context.WithCancel(context.Background())
+		defer stopWarming()
+		warmConnections(warmCtx, warmers, cfg.WarmStartInterval, logger)
+	}
+
+	// Cache responses when a TTL is configured
+	var cache *search.CachingService
+	if cfg.CacheTTL > 0 {
+		cache = search.NewCachingService(searchService, cfg.CacheTTL, cfg.CacheMaxEntries)
+		searchService = cache
+	}
+
+	// Reuse answers to similar questions when a similarity threshold is configured
+	var semantic *search.SemanticCache
+	if cfg.SemanticCacheThreshold > 0 {
+		var embedder search.Embedder = search.HashEmbedder{}
+		if cfg.SemanticCacheEmbeddingURL != "" {
+			embedder = search.NewHTTPEmbedder(cfg)
+		}
+		semantic = search.NewSemanticCache(searchService, embedder, cfg.SemanticCacheThreshold, cfg.SemanticCacheTTL, cfg.SemanticCacheMaxEntries)
+		searchService = semantic
+	}
+
+	// Process results through the configured pipeline stages, in order
+	booster, err := search.NewBooster(cfg.BoostRules)
+	if err != nil {
+		logger.Error("Boost rule error", err, nil)
+		return err
+	}
+	var boosting *search.BoostingService
+	var filtering *search.FilteringService
+	for _, stage := range cfg.Pipeline() {
+		switch stage {
+		case config.StageDedupe:
+			searchService = search.NewDedupeService(searchService)
+		case config.StageFilter:
+			// Drop denylisted results, including those from a refreshed feed,
+			// then remove or flag unsafe ones
+			filtering = search.NewFilteringService(searchService, search.NewDenylist(cfg.Denylist))
+			searchService = newSafetyService(cfg, filtering)
+			if cfg.DenylistFeedURL != "" {
+				feedCtx, stopFeed := context.WithCancel(context.Background())
+				defer stopFeed()
+				go newDenylistFeed(cfg, filtering).Run(feedCtx)
+			}
+		case config.StageRerank:
+			// Pin and boost results by domain for matching queries
+			boosting = search.NewBoostingService(searchService, booster)
+			searchService = boosting
+		case config.StageTruncate:
+			searchService = search.NewTruncateService(searchService)
+		case config.StageFormat:
+			searchService = search.NewFormatService(searchService)
+		}
+	}
+
+	// Search again for more when filtering leaves fewer results than asked for
+	searchService = search.NewAdaptiveCountService(searchService)
+
+	// Apply query rewrite rules before dispatching to the provider
+	rewriter, err := search.NewRewriter(cfg.RewriteRules)
+	if err != nil {
+		logger.Error("Rewrite rule error", err, nil)
+		return err
+	}
+	rewriting := search.NewRewritingService(searchService, rewriter)
+	searchService = rewriting
+
+	// Re-read credentials and non-structural settings on SIGHUP
+	reloadCtx, stopReload := context.WithCancel(context.Background())
+	defer stopReload()
+	watchReloadSignal(reloadCtx, func() {
+		reloadConfig(logger, cfg, reloadTargets{
+			keys:      keys,
+			rewriting: rewriting,
+			boosting:  boosting,
+			filtering: filtering,
+			collector: collector,
+		})
+	})
+
+	// Encrypt local state at rest when a key is configured
+	var dataCipher *store.Cipher
+	key, err := cfg.EncryptionKey()
+	if err != nil {
+		logger.Error("Encryption key error", err, nil)
+		return err
+	}
+	if key != nil {
+		if dataCipher, err = store.NewCipher(key); err != nil {
+			logger.Error("Encryption key error", err, nil)
+			return err
+		}
+	}
+
+	// Count API calls against the providers' daily quotas across restarts
+	zone, _ := cfg.Location()
+	usage, err := store.OpenUsageEncrypted(cfg.DataDir, dataCipher, zone)
+	if err != nil {
+		// Searching still works without usage counts, so this is not fatal
+		logger.Error("API usage unavailable", err, map[string]interface{}{
+			"data_dir": cfg.DataDir,
+		})
+	} else {
+		collector.Observe(func(call stats.Call) {
+			if err := usage.Record(call.Provider, call.Err != nil); err != nil {
+				logger.Error("Failed to record API usage", err, nil)
+			}
+		})
+	}
+
+	// Re-run standing queries in the background and post new results to the webhook
+	if len(cfg.Monitors) > 0 {
+		var notifier monitor.Notifier
+		if cfg.WebhookURL != "" {
+			notifier = monitor.NewWebhook(cfg)
+		}
+		standing, err := monitor.NewEncrypted(searchService, cfg.Monitors, notifier, cfg.DataDir, dataCipher)
+		if err != nil {
+			logger.Error("Monitor error", err, nil)
+			return err
+		}
+		standing.SetPool(workers)
+		monitorLogger := NewLogger("monitor")
+		standing.OnHit = func(hit monitor.Hit) {
+			monitorLogger.Info("Standing query has new results", map[string]interface{}{
+				"name":    hit.Name,
+				"results": len(hit.Results),
+			})
+		}
+		standing.OnError = func(name string, err error) {
+			monitorLogger.Error("Standing query error", err, map[string]interface{}{
+				"name": name,
+			})
+		}
+		monitorCtx, stopMonitor := context.WithCancel(context.Background())
+		defer stopMonitor()
+		go standing.Run(monitorCtx)
+	}
+
+	// Record the session's research trail and expose it and its searches as resources
+	transcript := mcp.NewTranscript()
+	transcript.SetRetention(cfg.HistoryMaxAge, cfg.HistoryMaxEntries)
+	transcript.OnChosen = collector.RecordChosen
+	transcriptResource := mcp.NewTranscriptResource(transcript)
+	s.AddResource(transcriptResource.Definition(), transcriptResource.Handler())
+	historyResource := mcp.NewHistoryResource(transcript)
+	s.AddResource(historyResource.Definition(), historyResource.Handler())
+	s.AddResourceTemplate(historyResource.EntryDefinition(), historyResource.Handler())
+	resultsResource := mcp.NewResultsResource(transcript)
+	s.AddResourceTemplate(resultsResource.Definition(), resultsResource.Handler())
+
+	// Create the tools
+	searchTool := mcp.NewSearchTool(searchService).WithTranscript(transcript)
+	searchTool.WithDefaultFreshness(cfg.DefaultFreshness(searchTool.Definition().Name))
+	if cfg.CacheTTL > 0 {
+		// A repeat is served from the session only as long as a cached response would be
+		searchTool.WithRepeatMaxAge(cfg.CacheTTL)
+	}
+	if router != nil {
+		searchTool.WithProviders(router.Providers())
+	}
+	if aggregator != nil {
+		searchTool.WithAggregateProviders(aggregator.Members())
+	}
+	if len(cfg.SearchPresets) > 0 {
+		presets := make(map[string]map[string]interface{}, len(cfg.SearchPresets))
+		for name, preset := range cfg.SearchPresets {
+			presets[name] = preset.Arguments()
+		}
+		searchTool.WithPresets(presets)
+	}
+	if !cfg.HideResultFooter {
+		searchTool.WithFooter(cfg.SearchCost)
+	}
+	if cfg.Incognito {
+		searchTool.WithIncognito(true)
+	}
+	if cfg.HideImages {
+		searchTool.WithImages(false)
+	}
+	if cfg.BochaPro {
+		searchTool.WithPro(true)
+	}
+	if cfg.StructuredSchemaVersion > 0 {
+		searchTool.WithSchemaVersion(cfg.StructuredSchemaVersion)
+	}
+	if loc, _ := cfg.Location(); loc != nil {
+		searchTool.WithLocation(loc)
+	}
+	verifyTool := mcp.NewVerifyTool(searchService)
+	if cfg.StructuredSchemaVersion > 0 {
+		verifyTool.WithSchemaVersion(cfg.StructuredSchemaVersion)
+	}
+	batchTool := mcp.NewBatchSearchTool(searchService).WithTranscript(transcript).WithPool(workers)
+	batchTool.WithDefaultFreshness(cfg.DefaultFreshness(batchTool.Definition().Name))
+	if loc, _ := cfg.Location(); loc != nil {
+		batchTool.WithLocation(loc)
+	}
+	tools := []mcp.ToolProvider{
+		searchTool,
+		batchTool,
+		verifyTool,
+		mcp.NewStatsTool(collector),
+	}
+	healthTool := mcp.NewHealthTool(collector, newHealthSources(backend, provider, aggregator, router, cache, semantic)).WithProbe(provider.Name(), upstream)
+	if cfg.StructuredSchemaVersion > 0 {
+		healthTool.WithSchemaVersion(cfg.StructuredSchemaVersion)
+	}
+	tools = append(tools, healthTool)
+	if usage != nil {
+		usageTool := mcp.NewUsageTool(usage, cfg.DailyQuotas)
+		if cfg.StructuredSchemaVersion > 0 {
+			usageTool.WithSchemaVersion(cfg.StructuredSchemaVersion)
+		}
+		tools = append(tools, usageTool)
+	}
+	if search.CapabilitiesOf(searchService).Videos {
+		videoTool := mcp.NewVideoSearchTool(searchService)
+		if loc, _ := cfg.Location(); loc != nil {
+			videoTool.WithLocation(loc)
+		}
+		tools = append(tools, videoTool)
+	}
+
+	// History, saved results and cached responses can be erased on demand and at shutdown
+	stores := mcp.DataStores{
+		History: func() int {
+			return transcript.Purge() + collector.ClearRecent()
+		},
+		Cache: flushCaches(cache, semantic),
+	}
+	if cfg.FetchEnabled {
+		fetcher, err := fetch.NewFetcher(cfg)
+		if err != nil {
+			logger.Error("Fetch configuration error", err, nil)
+			return err
+		}
+		fetchTool := mcp.NewFetchTool(fetcher).WithTranscript(transcript).WithPool(workers)
+		fetchPageTool := mcp.NewFetchPageTool(fetcher, cfg.FetchPageMaxChars).WithTranscript(transcript).WithPool(workers)
+		if cfg.StructuredSchemaVersion > 0 {
+			fetchTool.WithSchemaVersion(cfg.StructuredSchemaVersion)
+			fetchPageTool.WithSchemaVersion(cfg.StructuredSchemaVersion)
+		}
+		tools = append(tools, fetchTool, fetchPageTool)
+		searchTool.WithQuotes(fetcher, workers)
+		tools = append(tools, mcp.NewSummarizeTool(searchService).WithFetcher(fetcher, cfg.FetchPageMaxChars).WithPool(workers))
+
+		// Saved documents are handed to other local tools by path or resource URI
+		if cfg.DownloadDir != "" {
+			downloader, err := fetch.NewDownloader(fetcher, cfg)
+			if err != nil {
+				logger.Error("Download directory error", err, nil)
+				return err
+			}
+			defer downloader.Close()
+			downloadResource := mcp.NewDownloadResource(downloader)
+			s.AddResourceTemplate(downloadResource.Definition(), downloadResource.Handler())
+			tools = append(tools, mcp.NewDownloadTool(downloader).WithPool(workers))
+		}
+	}
+	if !cfg.FetchEnabled && search.CapabilitiesOf(searchService).Summaries {
+		tools = append(tools, mcp.NewSummarizeTool(searchService).WithPool(workers))
+	}
+	if cfg.HNSearchEnabled {
+		tools = append(tools, mcp.NewHNSearchTool(hackernews.NewClient(cfg)).WithPool(workers))
+	}
+	if cfg.ScreenshotURL != "" {
+		shooter, err := screenshot.NewClient(cfg)
+		if err != nil {
+			logger.Error("Screenshot configuration error", err, nil)
+			return err
+		}
+		tools = append(tools, mcp.NewScreenshotTool(shooter).WithPool(workers))
+	}
+	if saved, err := store.OpenSavedEncrypted(cfg.DataDir, dataCipher); err != nil {
+		// Searching still works without local state, so this is not fatal
+		logger.Error("Saved results unavailable", err, map[string]interface{}{
+			"data_dir": cfg.DataDir,
+		})
+	} else {
+		if err := saved.SetRetention(cfg.SavedMaxAge, cfg.SavedMaxEntries); err != nil {
+			logger.Error("Failed to apply saved results retention", err, nil)
+		}
+		stores.Saved = saved.Purge
+		tools = append(tools,
+			mcp.NewSaveResultTool(saved).WithTranscript(transcript),
+			mcp.NewListSavedTool(saved),
+			mcp.NewAnnotateSavedTool(saved),
+			mcp.NewSearchSavedTool(saved),
+		)
+	}
+	if cfg.PurgeToolEnabled {
+		tools = append(tools, mcp.NewPurgeDataTool(stores))
+	}
+	if cfg.PurgeOnShutdown {
+		defer func() {
+			counts, err := stores.Purge(mcp.PurgeAll)
+			if err != nil {
+				logger.Error("Failed to purge data at shutdown", err, nil)
+				return
+			}
+			logger.Info("Purged data at shutdown", map[string]interface{}{
+				"history": counts.History,
+				"saved":   counts.Saved,
+				"cache":   counts.Cache,
+			})
+		}()
+	}
+
+	// Restrict the tools to the client's profile
+	profile, allowed, err := cfg.ToolProfile()
+	if err != nil {
+		logger.Error("Tool profile error", err, nil)
+		return err
+	}
+	tools, unknown := mcp.FilterTools(tools, allowed)
+	if len(unknown) > 0 {
+		logger.Info("Tool profile references unknown tools", map[string]interface{}{
+			"profile": profile,
+			"tools":   strings.Join(unknown, ","),
+		})
+	}
 
-	// Create the search tool
-	searchTool := mcp.NewSearchTool(searchService)
+	// Add the tools to the server once the upstream is ready
+	readyCtx, stopReadiness := context.WithCancel(context.Background())
+	defer stopReadiness()
+	err = awaitReadiness(readyCtx, cfg.StartupCheck, provider, logger, func() {
+		for _, tool := range tools {
+			s.AddTool(tool.Definition(), mcp.Interactive(tool.Handler()))
+		}
+	})
+	if err != nil {
+		logger.Error("Readiness check failed", err, map[string]interface{}{
+			"suggestion": "Check the API key and base URL.",
+		})
+		return err
+	}
 
-	// Add the search tool to the server
-	s.AddTool(searchTool.Definition(), searchTool.Handler())
+	// Start the admin API when configured
+	if cfg.AdminAddr != "" {
+		adminServer := admin.NewServer(cfg.AdminAddr, cfg.AdminToken, newAdminControls(backend, provider, cache, semantic, collector))
+		go func() {
+			if err := adminServer.Start(); err != nil {
+				logger.Error("Admin server error", err, nil)
+			}
+		}()
+		defer func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			_ = adminServer.Shutdown(ctx)
+		}()
+		logger.Info("Admin API listening", map[string]interface{}{
+			"addr": cfg.AdminAddr,
+		})
+	}
 
 	// Start the server
 	logger.Info("Server ready", map[string]interface{}{
 		"name":    cfg.ServerName,
 		"version": cfg.ServerVersion,
+		"tools":   len(tools),
 	})
 
+	if cfg.SSEAddr != "" {
+		logger.Info("Serving over SSE", map[string]interface{}{
+			"addr": cfg.SSEAddr,
+		})
+		return serveSSE(s, cfg.SSEAddr, cfg.SSEToken)
+	}
 	return serveStdio(s)
 }
 
+// newDenylistFeed creates the feed that periodically refreshes the denylist of filtering
+func newDenylistFeed(cfg *config.Config, filtering *search.FilteringService) *search.DenylistFeed {
+	feed := search.NewDenylistFeed(cfg.DenylistFeedURL, cfg.DenylistRefresh, filtering)
+	feedLogger := NewLogger("denylist")
+	feed.OnUpdate = func(entries int) {
+		feedLogger.Debug("Denylist feed refreshed", map[string]interface{}{
+			"entries": entries,
+		})
+	}
+	feed.OnError = func(err error) {
+		feedLogger.Error("Denylist feed refresh failed, keeping the previous list", err, nil)
+	}
+	return feed
+}
+
+// newSafetyService wraps next to remove or flag unsafe results, logging each
+// one for auditing, or returns next when the safety filter is off
+func newSafetyService(cfg *config.Config, next search.Service) search.Service {
+	if cfg.SafetyMode != config.SafetyFlag && cfg.SafetyMode != config.SafetyRemove {
+		return next
+	}
+	classifiers := []search.Classifier{search.NewKeywordClassifier(cfg.SafetyKeywords)}
+	if cfg.SafetyClassifierURL != "" {
+		classifiers = append(classifiers, search.NewHTTPClassifier(cfg.SafetyClassifierURL, cfg.HTTPTimeout))
+	}
+	safety := search.NewSafetyService(next, cfg.SafetyMode == config.SafetyRemove, classifiers...)
+	safetyLogger := NewLogger("safety")
+	safety.OnFiltered = func(result search.WebPageResult, verdict search.Verdict, removed bool) {
+		action := "flagged"
+		if removed {
+			action = "removed"
+		}
+		safetyLogger.Info("Unsafe result "+action, map[string]interface{}{
+			"url":      result.URL,
+			"category": verdict.Category,
+		})
+	}
+	safety.OnError = func(err error) {
+		safetyLogger.Error("Safety classifier failed, results not classified by it", err, nil)
+	}
+	return safety
+}
+
+// adminStats is the payload returned by the admin stats endpoint
+type adminStats struct {
+	Search stats.Snapshot     `json:"search"`
+	Cache  *search.CacheStats `json:"cache,omitempty"`
+
+	SemanticCache *search.CacheStats `json:"semantic_cache,omitempty"`
+}
+
+// flushCaches returns a function emptying the response caches, or nil when
+// caching is disabled
+func flushCaches(cache *search.CachingService, semantic *search.SemanticCache) func() int {
+	if cache == nil && semantic == nil {
+		return nil
+	}
+	return func() int {
+		flushed := 0
+		if cache != nil {
+			flushed += cache.Flush()
+		}
+		if semantic != nil {
+			flushed += semantic.Flush()
+		}
+		return flushed
+	}
+}
+
+// newNamedProvider creates the named provider from the configuration, as if it
+// were the configured SEARCH_PROVIDER
+func newNamedProvider(cfg *config.Config, name string) (search.Provider, error) {
+	providerCfg := *cfg
+	providerCfg.SearchProvider = name
+	return search.NewProvider(&providerCfg)
+}
+
+// newAdminControls wires the admin API operations to the running services
+func newAdminControls(base search.Service, provider *search.ToggleService, cache *search.CachingService, semantic *search.SemanticCache, collector *stats.Collector) admin.Controls {
+	controls := admin.Controls{
+		SetLogLevel: SetLogLevel,
+		Stats: func() interface{} {
+			result := adminStats{Search: collector.Snapshot()}
+			if cache != nil {
+				cacheStats := cache.Stats()
+				result.Cache = &cacheStats
+			}
+			if semantic != nil {
+				semanticStats := semantic.Stats()
+				result.SemanticCache = &semanticStats
+			}
+			return result
+		},
+		Providers: func() map[string]bool {
+			return map[string]bool{provider.Name(): provider.Enabled()}
+		},
+		SetProviderEnabled: func(name string, enabled bool) error {
+			if name != provider.Name() {
+				return fmt.Errorf("unknown provider %q", name)
+			}
+			provider.SetEnabled(enabled)
+			return nil
+		},
+	}
+
+	bochaService, isBocha := base.(*bocha.Service)
+	if isBocha {
+		controls.RotateAPIKey = bochaService.SetAPIKey
+	}
+	controls.FlushCache = flushCaches(cache, semantic)
+
+	controls.Dashboard = func() admin.Dashboard {
+		dashboard := admin.Dashboard{
+			Search:    collector.Snapshot(),
+			Providers: controls.Providers(),
+		}
+		if cache != nil {
+			cacheStats := cache.Stats()
+			dashboard.Cache = &cacheStats
+		}
+		if isBocha {
+			rateLimit := bochaService.RateLimitStats()
+			dashboard.RateLimit = &rateLimit
+		}
+		return dashboard
+	}
+
+	return controls
+}
+
+// newHealthSources wires the health tool to the running services. Aggregate
+// members and provider overrides can't be disabled, so they are always enabled.
+func newHealthSources(base search.Service, provider *search.ToggleService, aggregator *search.Aggregator, router *search.Router, cache *search.CachingService, semantic *search.SemanticCache) mcp.HealthSources {
+	sources := mcp.HealthSources{
+		Providers: func() map[string]bool {
+			providers := make(map[string]bool)
+			if aggregator != nil {
+				for _, name := range aggregator.Members() {
+					providers[name] = true
+				}
+			}
+			if router != nil {
+				for name := range router.Providers() {
+					providers[name] = true
+				}
+			}
+			providers[provider.Name()] = provider.Enabled()
+			return providers
+		},
+	}
+	if bochaService, ok := base.(*bocha.Service); ok {
+		sources.RateLimit = bochaService.RateLimitStats
+	}
+	if cache != nil {
+		sources.Cache = cache.Stats
+	}
+	if semantic != nil {
+		sources.SemanticCache = semantic.Stats
+	}
+	return sources
+}
+
+// writeConfigSchema prints the JSON Schema of the configuration file
+func writeConfigSchema(w io.Writer) error {
+	schema, err := config.SchemaJSON()
+	if err != nil {
+		return fmt.Errorf("failed to generate schema: %w", err)
+	}
+	_, err = fmt.Fprintf(w, "%s\n", schema)
+	return err
+}
+
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "fakeserver" {
+		if err := runFakeServer(os.Args[2:]); err != nil {
+			log.Printf("fakeserver: %v", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "config-schema" {
+		if err := writeConfigSchema(os.Stdout); err != nil {
+			log.Printf("config-schema: %v", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "setup" {
+		if err := runSetup(os.Args[2:], os.Stdin, os.Stdout); err != nil {
+			log.Printf("setup: %v", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	if err := runServer(); err != nil {
 		os.Exit(1)
 	}
@@ -1,6 +1,8 @@
 package main
 
 import (
+	"bytes"
+	"encoding/json"
 	"os"
 	"testing"
 
@@ -72,6 +74,8 @@ func TestConfigSuccess(t *testing.T) {
 	os.Setenv("HTTP_TIMEOUT", "5s")
 	os.Setenv("SERVER_NAME", "Test Server")
 	os.Setenv("SERVER_VERSION", "0.0.1")
+	t.Setenv("UPSTREAM_ALLOWLIST", "test.api.com")
+	t.Setenv("DATA_DIR", t.TempDir())
 
 	// Call runServer - it should not return an error
 	err := runServer()
@@ -79,3 +83,30 @@ func TestConfigSuccess(t *testing.T) {
 		t.Errorf("Expected no error with valid configuration, but got: %v", err)
 	}
 }
+
+func TestSetLogLevel(t *testing.T) {
+	defer func() { _ = SetLogLevel("info") }()
+
+	for _, level := range []string{"debug", "info", "error", ""} {
+		if err := SetLogLevel(level); err != nil {
+			t.Errorf("Expected no error for level %q, got %v", level, err)
+		}
+	}
+	if err := SetLogLevel("verbose"); err == nil {
+		t.Error("Expected error for invalid level, got nil")
+	}
+}
+
+func TestWriteConfigSchema(t *testing.T) {
+	var out bytes.Buffer
+	if err := writeConfigSchema(&out); err != nil {
+		t.Fatalf("writeConfigSchema returned an error: %v", err)
+	}
+	var schema map[string]interface{}
+	if err := json.Unmarshal(out.Bytes(), &schema); err != nil {
+		t.Fatalf("Expected JSON output, got %v", err)
+	}
+	if _, ok := schema["properties"].(map[string]interface{})["bocha_api_key"]; !ok {
+		t.Error("Expected the schema to describe bocha_api_key")
+	}
+}
@@ -0,0 +1,195 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"com.moguyn/mcp-go-search/params"
+	"com.moguyn/mcp-go-search/pool"
+	"com.moguyn/mcp-go-search/search"
+)
+
+// maxBatchQueries bounds the queries of one batch_search call
+const maxBatchQueries = 10
+
+// BatchSearchTool runs several searches at once as an MCP tool, for agents
+// that split a task into sub-queries
+type BatchSearchTool struct {
+	searchService search.Service
+	transcript    *Transcript
+	workers       *pool.Pool
+	location      *time.Location
+	freshness     string
+}
+
+// NewBatchSearchTool creates a new batch search tool with the provided search service
+func NewBatchSearchTool(searchService search.Service) *BatchSearchTool {
+	return &BatchSearchTool{
+		searchService: searchService,
+	}
+}
+
+// WithTranscript records every search of a batch in the session transcript
+func (t *BatchSearchTool) WithTranscript(transcript *Transcript) *BatchSearchTool {
+	t.transcript = transcript
+	return t
+}
+
+// WithPool runs the queries on a shared worker pool, bounding how many run at once
+func (t *BatchSearchTool) WithPool(workers *pool.Pool) *BatchSearchTool {
+	t.workers = workers
+	return t
+}
+
+// WithLocation shows dates in loc instead of the zone the provider returned them in
+func (t *BatchSearchTool) WithLocation(loc *time.Location) *BatchSearchTool {
+	t.location = loc
+	return t
+}
+
+// WithDefaultFreshness sets the freshness used when a call doesn't set one
+func (t *BatchSearchTool) WithDefaultFreshness(freshness string) *BatchSearchTool {
+	t.freshness = freshness
+	return t
+}
+
+// Definition returns the MCP tool definition
+func (t *BatchSearchTool) Definition() mcp.Tool {
+	return mcp.NewTool("batch_search",
+		mcp.WithDescription("Run several web searches at once, such as the sub-queries of a task, and get their results grouped by query"),
+		withRequiredStringArray("queries", fmt.Sprintf("The search queries (1-%d)", maxBatchQueries), maxBatchQueries),
+		mcp.WithString("freshness",
+			mcp.Description("Filter results by freshness (noLimit, day, week, month, oneYear); applies to every query"),
+			mcp.Enum(params.Freshness...),
+		),
+		mcp.WithNumber("count",
+			mcp.Description("Number of results to return for each query (1-50)"),
+		),
+		withPriority(),
+	)
+}
+
+// withRequiredStringArray adds a required property holding up to maxItems
+// strings to the tool schema
+func withRequiredStringArray(name string, description string, maxItems int) mcp.ToolOption {
+	return func(tool *mcp.Tool) {
+		tool.InputSchema.Properties[name] = map[string]interface{}{
+			"type":        "array",
+			"description": description,
+			"items":       map[string]interface{}{"type": "string"},
+			"minItems":    1,
+			"maxItems":    maxItems,
+		}
+		tool.InputSchema.Required = append(tool.InputSchema.Required, name)
+	}
+}
+
+// batchResult is the outcome of one query of a batch
+type batchResult struct {
+	params   params.Search
+	adj      params.Adjustments
+	response *search.WebSearchResponse
+	err      error
+}
+
+// Handler returns the MCP tool handler function
+func (t *BatchSearchTool) Handler() func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+		defer cancel()
+
+		list, ok := request.Params.Arguments["queries"].([]interface{})
+		if !ok || len(list) == 0 {
+			return mcp.NewToolResultError("queries parameter is required and must be a non-empty array of strings"), nil
+		}
+		if len(list) > maxBatchQueries {
+			return mcp.NewToolResultError(fmt.Sprintf("too many queries (maximum %d)", maxBatchQueries)), nil
+		}
+		ctx, err := applyPriority(ctx, request.Params.Arguments)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		// Every query is checked before any is sent, so a bad one fails the call
+		// instead of wasting the searches of the others
+		caps := search.CapabilitiesOf(t.searchService)
+		results := make([]batchResult, len(list))
+		for i, item := range list {
+			if _, ok := item.(string); !ok {
+				return mcp.NewToolResultError(fmt.Sprintf("query %d must be a string", i+1)), nil
+			}
+			args := map[string]interface{}{"query": item}
+			for _, name := range []string{"freshness", "count"} {
+				if v, ok := request.Params.Arguments[name]; ok {
+					args[name] = v
+				}
+			}
+			p, adj, err := bindSearchArguments(args)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("query %d: %v", i+1, err)), nil
+			}
+			if p.Freshness == "" {
+				p.Freshness = t.freshness
+			}
+			results[i].adj = adj
+			p, adj, err = params.Normalize(p, caps.Limits())
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("query %d: %v", i+1, err)), nil
+			}
+			if err := caps.Check(p.Query, p.Freshness); err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("query %d: %v", i+1, err)), nil
+			}
+			results[i].params = p
+			results[i].adj = results[i].adj.Merge(adj)
+		}
+
+		// A failed query is reported in its place rather than canceling the batch
+		group, _ := t.workers.Group(ctx)
+		for i := range results {
+			group.Go(func(ctx context.Context) error {
+				p := results[i].params
+				ctx = search.WithAdjustments(ctx, results[i].adj)
+				results[i].response, results[i].err = t.searchService.Search(ctx, p.Query, p.Freshness, p.Count, false)
+				return nil
+			})
+		}
+		_ = group.Wait()
+
+		var b strings.Builder
+		now := time.Now()
+		if t.location != nil {
+			now = now.In(t.location)
+		}
+		failed := 0
+		for i, r := range results {
+			b.WriteString(fmt.Sprintf("## Query %d of %d: %s\n\n", i+1, len(results), r.params.Query))
+			if r.err != nil {
+				failed++
+				if ctx.Err() == context.DeadlineExceeded {
+					b.WriteString("Search timed out after 30 seconds\n\n")
+				} else {
+					b.WriteString(fmt.Sprintf("Search failed: %v\n\n", sanitizeErrorMessage(r.err.Error())))
+				}
+				continue
+			}
+			id := t.transcript.RecordSearch(ctx, r.params, r.response)
+			b.WriteString(formatSearchResults(r.params.Query, r.response, formatOptions{
+				Freshness:  r.params.Freshness,
+				Now:        now,
+				ExactQuery: caps.ExactQuery,
+				Location:   t.location,
+				HideImages: true,
+			}))
+			b.WriteString(resultsNote(id))
+			b.WriteString("\n")
+		}
+		if failed == len(results) {
+			return mcp.NewToolResultError(b.String()), nil
+		}
+		return mcp.NewToolResultText(b.String()), nil
+	}
+}
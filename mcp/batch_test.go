@@ -0,0 +1,114 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"com.moguyn/mcp-go-search/pool"
+	"com.moguyn/mcp-go-search/search"
+)
+
+func TestBatchSearchTool(t *testing.T) {
+	var running, peak atomic.Int32
+	service := &MockSearchService{
+		SearchFunc: func(_ context.Context, query string, _ string, count int, _ bool) (*search.WebSearchResponse, error) {
+			n := running.Add(1)
+			defer running.Add(-1)
+			for {
+				p := peak.Load()
+				if n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+			time.Sleep(20 * time.Millisecond)
+			if query == "broken" {
+				return nil, errors.New("upstream error")
+			}
+			response := &search.WebSearchResponse{}
+			response.Data.WebPages.Value = []search.WebPageResult{
+				{Name: "Result for " + query, URL: "https://example.com/" + query},
+			}
+			return response, nil
+		},
+	}
+	transcript := NewTranscript()
+	tool := NewBatchSearchTool(service).WithTranscript(transcript).WithPool(pool.New(2, 5*time.Second))
+	if tool.Definition().Name != "batch_search" {
+		t.Errorf("Expected tool name 'batch_search', got '%s'", tool.Definition().Name)
+	}
+
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]interface{}{"queries": []interface{}{"alpha", "broken", "gamma", "delta"}}
+	result, err := tool.Handler()(context.Background(), request)
+	if err != nil || result.IsError {
+		t.Fatalf("Unexpected result: %v, %+v", err, result)
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+	alpha := strings.Index(text, "## Query 1 of 4: alpha\n")
+	broken := strings.Index(text, "## Query 2 of 4: broken\n\nSearch failed: upstream error\n")
+	gamma := strings.Index(text, "## Query 3 of 4: gamma\n")
+	if alpha < 0 || broken < alpha || gamma < broken || !strings.Contains(text, "Result for delta") {
+		t.Errorf("Expected results grouped by query in order, got %s", text)
+	}
+	if p := peak.Load(); p != 2 {
+		t.Errorf("Expected the queries to run two at a time, peak was %d", p)
+	}
+	// Each successful query links to its result set
+	if links := strings.Count(text, "Full response (JSON): search://results/"); links != 3 {
+		t.Errorf("Expected a result set link per successful query, got %d", links)
+	}
+
+	request.Params.Arguments = map[string]interface{}{"queries": []interface{}{"alpha", 42}}
+	if result, _ := tool.Handler()(context.Background(), request); !result.IsError {
+		t.Error("Expected an error for a query that is not a string")
+	}
+	queries := make([]interface{}, maxBatchQueries+1)
+	for i := range queries {
+		queries[i] = "q"
+	}
+	request.Params.Arguments = map[string]interface{}{"queries": queries}
+	if result, _ := tool.Handler()(context.Background(), request); !result.IsError {
+		t.Error("Expected an error for too many queries")
+	}
+	request.Params.Arguments = map[string]interface{}{"queries": []interface{}{"broken"}}
+	if result, _ := tool.Handler()(context.Background(), request); !result.IsError {
+		t.Error("Expected an error when every query fails")
+	}
+}
+
+func TestBatchSearchTool_Aggregated(t *testing.T) {
+	// A full pool of batch queries fanning out to several providers must not
+	// wait on itself for workers
+	member := &MockSearchService{
+		SearchFunc: func(_ context.Context, query string, _ string, _ int, _ bool) (*search.WebSearchResponse, error) {
+			response := &search.WebSearchResponse{}
+			response.Data.WebPages.Value = []search.WebPageResult{{Name: "Result for " + query, URL: "https://example.com/" + query}}
+			return response, nil
+		},
+	}
+	workers := pool.New(2, 0)
+	aggregator := search.NewAggregator([]search.AggregateMember{{Name: "bocha", Service: member}, {Name: "brave", Service: member}}, time.Second, workers)
+	tool := NewBatchSearchTool(aggregator).WithPool(workers)
+
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]interface{}{"queries": []interface{}{"alpha", "beta", "gamma"}}
+	done := make(chan *mcp.CallToolResult, 1)
+	go func() {
+		result, _ := tool.Handler()(context.Background(), request)
+		done <- result
+	}()
+	select {
+	case result := <-done:
+		if result.IsError || !strings.Contains(result.Content[0].(mcp.TextContent).Text, "Result for gamma") {
+			t.Errorf("Expected every query to be answered, got %+v", result)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the batch to finish without waiting for a job timeout")
+	}
+}
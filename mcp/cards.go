@@ -0,0 +1,199 @@
+package mcp
+
+import (
+	"fmt"
+	"strings"
+
+	"com.moguyn/mcp-go-search/search"
+)
+
+// CardsURI identifies the structured modal cards attached to search results
+const CardsURI = "search://cards"
+
+// cardsOf returns the response's cards that have their details, with blank
+// facts removed
+func cardsOf(response *search.WebSearchResponse) []search.Card {
+	var cards []search.Card
+	for _, card := range response.Data.Cards {
+		switch {
+		case card.Weather != nil && strings.TrimSpace(card.Weather.Location) != "":
+			cards = append(cards, search.Card{Type: search.CardWeather, Weather: card.Weather})
+		case card.Stock != nil && strings.TrimSpace(card.Stock.Price) != "":
+			cards = append(cards, search.Card{Type: search.CardStock, Stock: card.Stock})
+		case card.Baike != nil && strings.TrimSpace(card.Baike.Title) != "":
+			baike := *card.Baike
+			baike.Facts = nil
+			for _, fact := range card.Baike.Facts {
+				fact.Label = strings.TrimSpace(fact.Label)
+				fact.Value = strings.TrimSpace(fact.Value)
+				if fact.Label != "" && fact.Value != "" {
+					baike.Facts = append(baike.Facts, fact)
+				}
+			}
+			cards = append(cards, search.Card{Type: search.CardBaike, Baike: &baike})
+		case card.Conversion != nil && strings.TrimSpace(card.Conversion.ToAmount) != "":
+			cards = append(cards, search.Card{Type: search.CardConversion, Conversion: card.Conversion})
+		case card.Definition != nil && strings.TrimSpace(card.Definition.Word) != "":
+			definition := *card.Definition
+			definition.Senses = nil
+			for _, sense := range card.Definition.Senses {
+				if strings.TrimSpace(sense.Definition) != "" {
+					definition.Senses = append(definition.Senses, sense)
+				}
+			}
+			if len(definition.Senses) > 0 {
+				cards = append(cards, search.Card{Type: search.CardDefinition, Definition: &definition})
+			}
+		}
+	}
+	return cards
+}
+
+// writeCards renders modal cards, each under a heading naming what it is about
+func writeCards(b *strings.Builder, cards []search.Card) {
+	for _, card := range cards {
+		switch card.Type {
+		case search.CardWeather:
+			writeWeatherCard(b, card.Weather)
+		case search.CardStock:
+			writeStockCard(b, card.Stock)
+		case search.CardBaike:
+			writeBaikeCard(b, card.Baike)
+		case search.CardConversion:
+			writeConversionCard(b, card.Conversion)
+		case search.CardDefinition:
+			writeDefinitionCard(b, card.Definition)
+		}
+		b.WriteString("\n")
+	}
+}
+
+// writeCardTitle writes a card's title underlined
+func writeCardTitle(b *strings.Builder, title string) {
+	b.WriteString(title + "\n")
+	b.WriteString(strings.Repeat("=", len(title)) + "\n\n")
+}
+
+// writeWeatherCard renders a weather card
+func writeWeatherCard(b *strings.Builder, w *search.WeatherCard) {
+	title := "Weather: " + w.Location
+	if w.Date != "" {
+		title += fmt.Sprintf(" (%s)", w.Date)
+	}
+	writeCardTitle(b, title)
+	if w.Condition != "" {
+		b.WriteString(fmt.Sprintf("   Conditions: %s\n", w.Condition))
+	}
+	if w.Temperature != "" {
+		b.WriteString(fmt.Sprintf("   Temperature: %s\n", w.Temperature))
+	}
+	if w.Low != "" || w.High != "" {
+		b.WriteString(fmt.Sprintf("   Low/High: %s / %s\n", w.Low, w.High))
+	}
+	for _, field := range []struct{ label, value string }{
+		{"Wind", w.Wind},
+		{"Humidity", w.Humidity},
+		{"Air Quality", w.AirQuality},
+	} {
+		if field.value != "" {
+			b.WriteString(fmt.Sprintf("   %s: %s\n", field.label, field.value))
+		}
+	}
+}
+
+// writeStockCard renders a stock card
+func writeStockCard(b *strings.Builder, s *search.StockCard) {
+	symbol := s.Symbol
+	if s.Exchange != "" {
+		symbol = strings.TrimPrefix(symbol+", "+s.Exchange, ", ")
+	}
+	title := "Stock: " + s.Name
+	if symbol != "" {
+		title += fmt.Sprintf(" (%s)", symbol)
+	}
+	writeCardTitle(b, title)
+	price := s.Price
+	if s.Currency != "" {
+		price += " " + s.Currency
+	}
+	b.WriteString(fmt.Sprintf("   Price: %s\n", price))
+	if s.Change != "" || s.ChangePercent != "" {
+		change := s.Change
+		if s.ChangePercent != "" {
+			change = strings.TrimSpace(fmt.Sprintf("%s (%s%%)", change, strings.TrimSuffix(s.ChangePercent, "%")))
+		}
+		b.WriteString(fmt.Sprintf("   Change: %s\n", change))
+	}
+	if s.Time != "" {
+		b.WriteString(fmt.Sprintf("   As of: %s\n", s.Time))
+	}
+}
+
+// writeBaikeCard renders an encyclopedia card
+func writeBaikeCard(b *strings.Builder, e *search.BaikeCard) {
+	writeCardTitle(b, "Encyclopedia: "+e.Title)
+	if e.Summary != "" {
+		b.WriteString(e.Summary + "\n")
+	}
+	for _, fact := range e.Facts {
+		b.WriteString(fmt.Sprintf("   %s: %s\n", fact.Label, fact.Value))
+	}
+	if e.URL != "" {
+		b.WriteString(fmt.Sprintf("   URL: %s\n", e.URL))
+	}
+}
+
+// writeConversionCard renders the computed value of a conversion or
+// calculation, leading with the value so it is read before any result
+func writeConversionCard(b *strings.Builder, c *search.ConversionCard) {
+	amount := func(value, unit string) string {
+		return strings.TrimSpace(value + " " + unit)
+	}
+	if c.Kind == search.ConversionCalculation {
+		writeCardTitle(b, "Calculation")
+		if c.Expression != "" {
+			b.WriteString(fmt.Sprintf("   %s = %s\n", c.Expression, c.ToAmount))
+		} else {
+			b.WriteString(fmt.Sprintf("   Result: %s\n", c.ToAmount))
+		}
+		return
+	}
+
+	writeCardTitle(b, "Conversion")
+	if c.FromAmount != "" {
+		b.WriteString(fmt.Sprintf("   %s = %s\n", amount(c.FromAmount, c.FromUnit), amount(c.ToAmount, c.ToUnit)))
+	} else {
+		b.WriteString(fmt.Sprintf("   Result: %s\n", amount(c.ToAmount, c.ToUnit)))
+	}
+	if c.Rate != "" && c.FromUnit != "" && c.ToUnit != "" {
+		b.WriteString(fmt.Sprintf("   Rate: 1 %s = %s %s\n", c.FromUnit, c.Rate, c.ToUnit))
+	}
+	if c.Time != "" {
+		b.WriteString(fmt.Sprintf("   As of: %s\n", c.Time))
+	}
+}
+
+// writeDefinitionCard renders a dictionary entry, numbering its senses
+func writeDefinitionCard(b *strings.Builder, d *search.DefinitionCard) {
+	writeCardTitle(b, "Definition: "+d.Word)
+	var pronunciations []string
+	for _, p := range d.Pronunciations {
+		pronunciations = append(pronunciations, strings.TrimSpace(p.Label+" "+p.Phonetic))
+	}
+	if len(pronunciations) > 0 {
+		b.WriteString(fmt.Sprintf("   Pronunciation: %s\n", strings.Join(pronunciations, ", ")))
+	}
+	for i, sense := range d.Senses {
+		if sense.PartOfSpeech != "" {
+			b.WriteString(fmt.Sprintf("   %d. (%s) %s\n", i+1, sense.PartOfSpeech, sense.Definition))
+		} else {
+			b.WriteString(fmt.Sprintf("   %d. %s\n", i+1, sense.Definition))
+		}
+		for _, example := range sense.Examples {
+			b.WriteString(fmt.Sprintf("      Example: %s\n", example))
+		}
+	}
+	if d.URL != "" {
+		b.WriteString(fmt.Sprintf("   URL: %s\n", d.URL))
+	}
+}
@@ -0,0 +1,132 @@
+package mcp
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"com.moguyn/mcp-go-search/search"
+)
+
+func TestCardsOf(t *testing.T) {
+	response := &search.WebSearchResponse{}
+	response.Data.Cards = []search.Card{
+		{Type: search.CardWeather, Weather: &search.WeatherCard{Location: " "}},
+		{Type: search.CardStock, Stock: &search.StockCard{Name: "Example", Price: "12.5"}},
+		{Type: search.CardBaike, Baike: &search.BaikeCard{Title: "Go", Facts: []search.Fact{{Label: "Designed by", Value: "Robert Griesemer"}, {Label: "Typing", Value: " "}}}},
+		{Type: "video"},
+	}
+	cards := cardsOf(response)
+	if len(cards) != 2 || cards[0].Type != search.CardStock || cards[1].Type != search.CardBaike {
+		t.Fatalf("Expected only the stock and encyclopedia cards, got %+v", cards)
+	}
+	if len(cards[1].Baike.Facts) != 1 || len(response.Data.Cards[2].Baike.Facts) != 2 {
+		t.Error("Expected blank facts removed without modifying the response")
+	}
+
+	var b strings.Builder
+	writeCards(&b, cards)
+	if !strings.Contains(b.String(), "Stock: Example\n==============\n\n   Price: 12.5\n") {
+		t.Errorf("Expected the stock card rendered, got:\n%s", b.String())
+	}
+}
+
+func TestWriteConversionCard(t *testing.T) {
+	var b strings.Builder
+	writeCards(&b, []search.Card{
+		{Type: search.CardConversion, Conversion: &search.ConversionCard{Kind: search.ConversionCurrency, FromAmount: "100", FromUnit: "USD", ToAmount: "724.51", ToUnit: "CNY", Rate: "7.2451", Time: "2024-05-01 10:00"}},
+		{Type: search.CardConversion, Conversion: &search.ConversionCard{Kind: search.ConversionCalculation, Expression: "2^10", ToAmount: "1024"}},
+	})
+	expected := "Conversion\n==========\n\n   100 USD = 724.51 CNY\n   Rate: 1 USD = 7.2451 CNY\n   As of: 2024-05-01 10:00\n\n" +
+		"Calculation\n===========\n\n   2^10 = 1024\n\n"
+	if b.String() != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, b.String())
+	}
+
+	response := &search.WebSearchResponse{}
+	response.Data.Cards = []search.Card{{Type: search.CardConversion, Conversion: &search.ConversionCard{Kind: search.ConversionUnit, FromUnit: "km"}}}
+	if cards := cardsOf(response); len(cards) != 0 {
+		t.Errorf("Expected a conversion without a value to be left out, got %+v", cards)
+	}
+}
+
+func TestWriteDefinitionCard(t *testing.T) {
+	response := &search.WebSearchResponse{}
+	response.Data.Cards = []search.Card{
+		{Type: search.CardDefinition, Definition: &search.DefinitionCard{
+			Word:           "serendipity",
+			Pronunciations: []search.Pronunciation{{Label: "US", Phonetic: "/ˌserənˈdɪpəti/"}, {Phonetic: "ser-en-dip-i-ty"}},
+			Senses: []search.Sense{
+				{PartOfSpeech: "noun", Definition: "The occurrence of events by chance in a happy way.", Examples: []string{"A fortunate stroke of serendipity."}},
+				{Definition: " "},
+				{Definition: "A happy accident."},
+			},
+			URL: "https://dict.example/serendipity",
+		}},
+		{Type: search.CardDefinition, Definition: &search.DefinitionCard{Word: "empty", Senses: []search.Sense{{Definition: ""}}}},
+	}
+	cards := cardsOf(response)
+	if len(cards) != 1 || len(cards[0].Definition.Senses) != 2 || len(response.Data.Cards[0].Definition.Senses) != 3 {
+		t.Fatalf("Expected blank senses and entries without senses left out, got %+v", cards)
+	}
+
+	var b strings.Builder
+	writeCards(&b, cards)
+	expected := "Definition: serendipity\n=======================\n\n" +
+		"   Pronunciation: US /ˌserənˈdɪpəti/, ser-en-dip-i-ty\n" +
+		"   1. (noun) The occurrence of events by chance in a happy way.\n" +
+		"      Example: A fortunate stroke of serendipity.\n" +
+		"   2. A happy accident.\n" +
+		"   URL: https://dict.example/serendipity\n\n"
+	if b.String() != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, b.String())
+	}
+}
+
+func TestHandler_AISearch(t *testing.T) {
+	var ai, pro bool
+	service := &MockSearchService{
+		SearchFunc: func(ctx context.Context, _ string, _ string, _ int, _ bool) (*search.WebSearchResponse, error) {
+			ai, pro = search.AISearch(ctx), search.Pro(ctx)
+			response := &search.WebSearchResponse{}
+			response.Data.Cards = []search.Card{{Type: search.CardWeather, Weather: &search.WeatherCard{Location: "Oslo", Condition: "Rain"}}}
+			return response, nil
+		},
+	}
+	call := func(tool *SearchTool, args map[string]interface{}) *mcp.CallToolResult {
+		request := mcp.CallToolRequest{}
+		request.Params.Arguments = args
+		result, _ := tool.Handler()(context.Background(), request)
+		return result
+	}
+
+	tool := NewSearchTool(service).WithPro(true)
+	result := call(tool, map[string]interface{}{"query": "weather oslo", "ai_search": true})
+	if result.IsError || !ai || pro {
+		t.Errorf("Expected ai_search to select the AI search over the pro default, got ai=%t pro=%t", ai, pro)
+	}
+	if text := result.Content[0].(mcp.TextContent).Text; !strings.Contains(text, "Weather: Oslo\n") {
+		t.Errorf("Expected the weather card, got:\n%s", text)
+	}
+	found := false
+	for _, content := range result.Content[1:] {
+		if resource, ok := content.(mcp.EmbeddedResource); ok {
+			if contents, ok := resource.Resource.(mcp.TextResourceContents); ok && contents.URI == CardsURI {
+				found = strings.Contains(contents.Text, `"location":"Oslo"`)
+			}
+		}
+	}
+	if !found {
+		t.Error("Expected the cards attached as data")
+	}
+
+	if result := call(tool, map[string]interface{}{"query": "weather oslo", "ai_search": true, "pro": true}); !result.IsError {
+		t.Error("Expected an error for pro combined with ai_search")
+	}
+	limited := NewSearchTool(&limitedSearchService{*service})
+	if result := call(limited, map[string]interface{}{"query": "weather oslo", "ai_search": true}); !result.IsError {
+		t.Error("Expected an error for ai_search with a provider without an AI search")
+	}
+}
@@ -0,0 +1,142 @@
+package mcp
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"com.moguyn/mcp-go-search/fetch"
+	"com.moguyn/mcp-go-search/pool"
+)
+
+// DownloadURIPrefix is the scheme of the resources saved documents are exposed as
+const DownloadURIPrefix = "download://"
+
+// DownloadTool saves the document at a URL into the download directory as an MCP tool
+type DownloadTool struct {
+	downloader *fetch.Downloader
+	workers    *pool.Pool
+}
+
+// NewDownloadTool creates a new download tool with the provided downloader
+func NewDownloadTool(downloader *fetch.Downloader) *DownloadTool {
+	return &DownloadTool{
+		downloader: downloader,
+	}
+}
+
+// WithPool runs downloads on a shared worker pool, bounding how many run at once
+func (t *DownloadTool) WithPool(workers *pool.Pool) *DownloadTool {
+	t.workers = workers
+	return t
+}
+
+// Definition returns the MCP tool definition
+func (t *DownloadTool) Definition() mcp.Tool {
+	return mcp.NewTool("download",
+		mcp.WithDescription("Save a document such as a PDF or web page from a search result URL to a local file, to hand it to other local tools. Returns the file path and a resource URI to read it"),
+		mcp.WithString("url",
+			mcp.Required(),
+			mcp.Description("The http or https URL of the document"),
+		),
+	)
+}
+
+// Handler returns the MCP tool handler function
+func (t *DownloadTool) Handler() func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ctx, cancel := context.WithTimeout(ctx, 60*time.Second)
+		defer cancel()
+
+		rawURL, ok := request.Params.Arguments["url"].(string)
+		if !ok || rawURL == "" {
+			return mcp.NewToolResultError("url parameter is required and must be a string"), nil
+		}
+
+		var download *fetch.Download
+		err := t.workers.Do(ctx, func(ctx context.Context) error {
+			var err error
+			download, err = t.downloader.Download(ctx, rawURL)
+			return err
+		})
+		if err != nil {
+			var budgetErr *fetch.BudgetExceededError
+			if errors.As(err, &budgetErr) {
+				return mcp.NewToolResultError(budgetErr.Error()), nil
+			}
+			if ctx.Err() == context.DeadlineExceeded {
+				return mcp.NewToolResultError("Download timed out after 60 seconds"), nil
+			}
+			return mcp.NewToolResultError(fmt.Sprintf("Download failed: %v", err)), nil
+		}
+
+		return mcp.NewToolResultText(formatDownload(download)), nil
+	}
+}
+
+// formatDownload renders a saved document as the text returned to the client
+func formatDownload(download *fetch.Download) string {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("Saved: %s\n", download.Path))
+	b.WriteString(fmt.Sprintf("Resource: %s%s\n", DownloadURIPrefix, download.Name))
+	b.WriteString(fmt.Sprintf("URL: %s\n", download.FinalURL))
+	b.WriteString(fmt.Sprintf("Content-Type: %s\n", download.ContentType))
+	b.WriteString(fmt.Sprintf("Size: %d bytes\n", download.Size))
+	return b.String()
+}
+
+// DownloadResource exposes saved documents as MCP resources
+type DownloadResource struct {
+	downloader *fetch.Downloader
+}
+
+// NewDownloadResource creates a new download resource
+func NewDownloadResource(downloader *fetch.Downloader) *DownloadResource {
+	return &DownloadResource{
+		downloader: downloader,
+	}
+}
+
+// Definition returns the MCP resource template definition
+func (r *DownloadResource) Definition() mcp.ResourceTemplate {
+	return mcp.NewResourceTemplate(DownloadURIPrefix+"{name}", "Downloaded document",
+		mcp.WithTemplateDescription("A document saved by the download tool"),
+	)
+}
+
+// Handler returns the MCP resource handler function. Text documents are
+// returned as text and everything else base64 encoded.
+func (r *DownloadResource) Handler() func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	return func(_ context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		uri := request.Params.URI
+		name, ok := strings.CutPrefix(uri, DownloadURIPrefix)
+		if !ok {
+			return nil, fmt.Errorf("unknown resource %q", uri)
+		}
+		file, err := r.downloader.Open(name)
+		if err != nil {
+			return nil, fmt.Errorf("download %q not found", name)
+		}
+		defer file.Close()
+		data, err := io.ReadAll(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read download %q: %w", name, err)
+		}
+
+		mimeType := fetch.ContentType(name)
+		if strings.HasPrefix(mimeType, "text/") || mimeType == "application/json" || mimeType == "application/xhtml+xml" {
+			return []mcp.ResourceContents{
+				mcp.TextResourceContents{URI: uri, MIMEType: mimeType, Text: string(data)},
+			}, nil
+		}
+		return []mcp.ResourceContents{
+			mcp.BlobResourceContents{URI: uri, MIMEType: mimeType, Blob: base64.StdEncoding.EncodeToString(data)},
+		}, nil
+	}
+}
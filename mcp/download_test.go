@@ -0,0 +1,85 @@
+package mcp
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"com.moguyn/mcp-go-search/config"
+	"com.moguyn/mcp-go-search/fetch"
+	"com.moguyn/mcp-go-search/pool"
+)
+
+func TestDownloadTool(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/pdf")
+		_, _ = w.Write([]byte("%PDF-1.7"))
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{
+		FetchTimeout:           5 * time.Second,
+		FetchMaxPagesPerMinute: 5,
+		FetchMaxBytesPerMinute: 1024,
+		FetchAllowlist:         []string{"127.0.0.1"},
+		DownloadDir:            t.TempDir(),
+		DownloadMaxBytes:       1024,
+		DownloadMaxTotalBytes:  4096,
+	}
+	fetcher, err := fetch.NewFetcher(cfg)
+	if err != nil {
+		t.Fatalf("NewFetcher returned an error: %v", err)
+	}
+	downloader, err := fetch.NewDownloader(fetcher, cfg)
+	if err != nil {
+		t.Fatalf("NewDownloader returned an error: %v", err)
+	}
+	defer downloader.Close()
+
+	tool := NewDownloadTool(downloader).WithPool(pool.New(1, 5*time.Second))
+	if tool.Definition().Name != "download" {
+		t.Errorf("Expected tool name 'download', got '%s'", tool.Definition().Name)
+	}
+
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]interface{}{"url": server.URL + "/report.pdf"}
+	result, err := tool.Handler()(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Handler returned an error: %v", err)
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+	if result.IsError || !strings.Contains(text, "Saved: "+cfg.DownloadDir) || !strings.Contains(text, "Size: 8 bytes") {
+		t.Fatalf("Unexpected result: %s", text)
+	}
+	start := strings.Index(text, DownloadURIPrefix)
+	uri := text[start : start+strings.Index(text[start:], "\n")]
+
+	resource := NewDownloadResource(downloader)
+	read := mcp.ReadResourceRequest{}
+	read.Params.URI = uri
+	contents, err := resource.Handler()(context.Background(), read)
+	if err != nil {
+		t.Fatalf("Resource handler returned an error: %v", err)
+	}
+	blob, ok := contents[0].(mcp.BlobResourceContents)
+	if !ok || blob.MIMEType != "application/pdf" || blob.Blob != base64.StdEncoding.EncodeToString([]byte("%PDF-1.7")) {
+		t.Errorf("Unexpected resource contents: %+v", contents[0])
+	}
+
+	read.Params.URI = DownloadURIPrefix + "..%2Fsecret"
+	if _, err := resource.Handler()(context.Background(), read); err == nil {
+		t.Error("Expected an error for an unknown download")
+	}
+
+	request.Params.Arguments = map[string]interface{}{}
+	result, _ = tool.Handler()(context.Background(), request)
+	if !result.IsError {
+		t.Error("Expected an error for a missing url")
+	}
+}
@@ -0,0 +1,53 @@
+package mcp
+
+import (
+	"fmt"
+	"strings"
+
+	"com.moguyn/mcp-go-search/search"
+)
+
+// EntityURI identifies the structured knowledge panel attached to search results
+const EntityURI = "search://entity"
+
+// entityOf returns the response's knowledge panel with blank facts removed,
+// or nil when there is no named entity
+func entityOf(response *search.WebSearchResponse) *search.Entity {
+	if response.Data.Entity == nil || strings.TrimSpace(response.Data.Entity.Name) == "" {
+		return nil
+	}
+	entity := *response.Data.Entity
+	entity.Name = strings.TrimSpace(entity.Name)
+	entity.Facts = nil
+	for _, fact := range response.Data.Entity.Facts {
+		fact.Label = strings.TrimSpace(fact.Label)
+		fact.Value = strings.TrimSpace(fact.Value)
+		if fact.Label != "" && fact.Value != "" {
+			entity.Facts = append(entity.Facts, fact)
+		}
+	}
+	return &entity
+}
+
+// writeEntity renders a knowledge panel
+func writeEntity(b *strings.Builder, entity *search.Entity) {
+	title := entity.Name
+	if entity.Type != "" {
+		title += fmt.Sprintf(" (%s)", entity.Type)
+	}
+	b.WriteString(fmt.Sprintf("Entity: %s\n", title))
+	b.WriteString(strings.Repeat("=", len("Entity: ")+len(title)) + "\n\n")
+	if entity.Description != "" {
+		b.WriteString(entity.Description + "\n")
+	}
+	if entity.URL != "" {
+		b.WriteString(fmt.Sprintf("   Official Site: %s\n", entity.URL))
+	}
+	for _, fact := range entity.Facts {
+		b.WriteString(fmt.Sprintf("   %s: %s\n", fact.Label, fact.Value))
+	}
+	if entity.Source != "" {
+		b.WriteString(fmt.Sprintf("   Source: %s\n", entity.Source))
+	}
+	b.WriteString("\n")
+}
@@ -0,0 +1,89 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"com.moguyn/mcp-go-search/search"
+)
+
+func TestEntityOf(t *testing.T) {
+	response := &search.WebSearchResponse{}
+	if entityOf(response) != nil {
+		t.Error("Expected no entity for a response without one")
+	}
+
+	response.Data.Entity = &search.Entity{Name: "  "}
+	if entityOf(response) != nil {
+		t.Error("Expected no entity without a name")
+	}
+
+	response.Data.Entity = &search.Entity{
+		Name:  " Go ",
+		Facts: []search.Fact{{Label: "Designed by", Value: "Rob Pike"}, {Label: "License", Value: " "}},
+	}
+	entity := entityOf(response)
+	if entity == nil || entity.Name != "Go" {
+		t.Fatalf("Expected entity named Go, got %+v", entity)
+	}
+	if len(entity.Facts) != 1 || entity.Facts[0].Label != "Designed by" {
+		t.Errorf("Expected the blank fact to be dropped, got %+v", entity.Facts)
+	}
+	if len(response.Data.Entity.Facts) != 2 {
+		t.Error("Expected the response not to be modified")
+	}
+}
+
+func TestWriteEntity(t *testing.T) {
+	var b strings.Builder
+	writeEntity(&b, &search.Entity{Name: "Go", Description: "A programming language.", URL: "https://go.dev"})
+	expected := "Entity: Go\n==========\n\nA programming language.\n   Official Site: https://go.dev\n\n"
+	if b.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, b.String())
+	}
+}
+
+func TestHandler_EntityContent(t *testing.T) {
+	response := &search.WebSearchResponse{}
+	response.Data.Entity = &search.Entity{Name: "Go", Type: "Programming language", URL: "https://go.dev"}
+	service := &MockSearchService{
+		SearchFunc: func(ctx context.Context, query string, freshness string, count int, summary bool) (*search.WebSearchResponse, error) {
+			return response, nil
+		},
+	}
+
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]interface{}{"query": "golang"}
+	result, err := NewSearchTool(service).Handler()(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Handler returned an error: %v", err)
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+	if strings.Index(text, "Entity: Go (Programming language)") > strings.Index(text, "Search Results:") {
+		t.Errorf("Expected the entity before the results, got:\n%s", text)
+	}
+	if len(result.Content) != 2 {
+		t.Fatalf("Expected text and resource content, got %d items", len(result.Content))
+	}
+	contents := result.Content[1].(mcp.EmbeddedResource).Resource.(mcp.TextResourceContents)
+	if contents.URI != EntityURI {
+		t.Errorf("Expected %s, got %s", EntityURI, contents.URI)
+	}
+	var envelope struct {
+		SchemaVersion int           `json:"schema_version"`
+		Data          search.Entity `json:"data"`
+	}
+	if err := json.Unmarshal([]byte(contents.Text), &envelope); err != nil {
+		t.Fatalf("Failed to decode entity: %v", err)
+	}
+	if envelope.SchemaVersion != SchemaVersion {
+		t.Errorf("Expected schema version %d, got %d", SchemaVersion, envelope.SchemaVersion)
+	}
+	if entity := envelope.Data; entity.URL != "https://go.dev" {
+		t.Errorf("Expected official site https://go.dev, got %q", entity.URL)
+	}
+}
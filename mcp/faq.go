@@ -0,0 +1,44 @@
+package mcp
+
+import (
+	"fmt"
+	"strings"
+
+	"com.moguyn/mcp-go-search/search"
+)
+
+// PeopleAlsoAskURI identifies the structured "people also ask" content attached to search results
+const PeopleAlsoAskURI = "search://people-also-ask"
+
+// peopleAlsoAsk returns the response's related questions, skipping blank and repeated ones
+func peopleAlsoAsk(response *search.WebSearchResponse) []search.Question {
+	var questions []search.Question
+	seen := make(map[string]bool)
+	for _, q := range response.Data.PeopleAlsoAsk {
+		q.Question = strings.TrimSpace(q.Question)
+		q.Answer = strings.TrimSpace(q.Answer)
+		key := strings.ToLower(q.Question)
+		if q.Question == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		questions = append(questions, q)
+	}
+	return questions
+}
+
+// writePeopleAlsoAsk renders related questions and their answers
+func writePeopleAlsoAsk(b *strings.Builder, questions []search.Question) {
+	b.WriteString("People Also Ask:\n")
+	b.WriteString("================\n\n")
+	for _, q := range questions {
+		b.WriteString(fmt.Sprintf("Q: %s\n", q.Question))
+		if q.Answer != "" {
+			b.WriteString(fmt.Sprintf("A: %s\n", q.Answer))
+		}
+		if q.URL != "" {
+			b.WriteString(fmt.Sprintf("   Source: %s\n", q.URL))
+		}
+		b.WriteString("\n")
+	}
+}
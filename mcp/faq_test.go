@@ -0,0 +1,94 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"com.moguyn/mcp-go-search/search"
+)
+
+func TestPeopleAlsoAsk(t *testing.T) {
+	response := &search.WebSearchResponse{}
+	response.Data.PeopleAlsoAsk = []search.Question{
+		{Question: " Does Go have generics? ", Answer: "Yes, since Go 1.18."},
+		{Question: "does go have generics?", Answer: "A repeat."},
+		{Question: "", Answer: "No question."},
+		{Question: "What is a constraint?"},
+	}
+
+	questions := peopleAlsoAsk(response)
+	if len(questions) != 2 {
+		t.Fatalf("Expected 2 questions, got %d: %+v", len(questions), questions)
+	}
+	if questions[0].Question != "Does Go have generics?" {
+		t.Errorf("Expected trimmed question, got %q", questions[0].Question)
+	}
+	if questions[1].Question != "What is a constraint?" {
+		t.Errorf("Expected second question to be kept, got %q", questions[1].Question)
+	}
+}
+
+func TestHandler_PeopleAlsoAskContent(t *testing.T) {
+	response := &search.WebSearchResponse{}
+	response.Data.PeopleAlsoAsk = []search.Question{
+		{Question: "Does Go have generics?", Answer: "Yes, since Go 1.18.", URL: "https://go.dev/blog/intro-generics"},
+	}
+	service := &MockSearchService{
+		SearchFunc: func(ctx context.Context, query string, freshness string, count int, summary bool) (*search.WebSearchResponse, error) {
+			return response, nil
+		},
+	}
+
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]interface{}{"query": "golang generics"}
+	result, err := NewSearchTool(service).Handler()(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Handler returned an error: %v", err)
+	}
+	if len(result.Content) != 2 {
+		t.Fatalf("Expected text and resource content, got %d items", len(result.Content))
+	}
+
+	resource, ok := result.Content[1].(mcp.EmbeddedResource)
+	if !ok {
+		t.Fatalf("Expected embedded resource, got %T", result.Content[1])
+	}
+	contents, ok := resource.Resource.(mcp.TextResourceContents)
+	if !ok {
+		t.Fatalf("Expected text resource contents, got %T", resource.Resource)
+	}
+	if contents.URI != PeopleAlsoAskURI || contents.MIMEType != "application/json" {
+		t.Errorf("Expected %s as application/json, got %s as %s", PeopleAlsoAskURI, contents.URI, contents.MIMEType)
+	}
+	var envelope struct {
+		Data []search.Question `json:"data"`
+	}
+	if err := json.Unmarshal([]byte(contents.Text), &envelope); err != nil {
+		t.Fatalf("Failed to decode questions: %v", err)
+	}
+	questions := envelope.Data
+	if len(questions) != 1 || questions[0].URL != "https://go.dev/blog/intro-generics" {
+		t.Errorf("Expected the question with its source, got %+v", questions)
+	}
+}
+
+func TestHandler_NoPeopleAlsoAsk(t *testing.T) {
+	service := &MockSearchService{
+		SearchFunc: func(ctx context.Context, query string, freshness string, count int, summary bool) (*search.WebSearchResponse, error) {
+			return &search.WebSearchResponse{}, nil
+		},
+	}
+
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]interface{}{"query": "golang generics"}
+	result, err := NewSearchTool(service).Handler()(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Handler returned an error: %v", err)
+	}
+	if len(result.Content) != 1 {
+		t.Errorf("Expected only text content, got %d items", len(result.Content))
+	}
+}
@@ -0,0 +1,266 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"com.moguyn/mcp-go-search/fetch"
+	"com.moguyn/mcp-go-search/pool"
+)
+
+// PageMetricsURI identifies the structured page metrics attached to fetched pages
+const PageMetricsURI = "fetch://page-metrics"
+
+// FetchTool retrieves the content of a URL as an MCP tool
+type FetchTool struct {
+	fetcher       *fetch.Fetcher
+	transcript    *Transcript
+	workers       *pool.Pool
+	schemaVersion int
+	// maxChars is the most readable text returned; zero returns the raw body
+	maxChars int
+}
+
+// NewFetchTool creates a new fetch tool with the provided fetcher
+func NewFetchTool(fetcher *fetch.Fetcher) *FetchTool {
+	return &FetchTool{
+		fetcher:       fetcher,
+		schemaVersion: SchemaVersion,
+	}
+}
+
+// NewFetchPageTool creates a fetch tool that returns the readable text of a
+// page, without markup and boilerplate, cut to at most maxChars characters
+func NewFetchPageTool(fetcher *fetch.Fetcher, maxChars int) *FetchTool {
+	t := NewFetchTool(fetcher)
+	t.maxChars = maxChars
+	return t
+}
+
+// WithTranscript records every fetched page in the session transcript
+func (t *FetchTool) WithTranscript(transcript *Transcript) *FetchTool {
+	t.transcript = transcript
+	return t
+}
+
+// WithPool runs fetches on a shared worker pool, bounding how many run at once
+func (t *FetchTool) WithPool(workers *pool.Pool) *FetchTool {
+	t.workers = workers
+	return t
+}
+
+// WithSchemaVersion sets the version of the structured output schema used for
+// attached data
+func (t *FetchTool) WithSchemaVersion(version int) *FetchTool {
+	t.schemaVersion = version
+	return t
+}
+
+// Definition returns the MCP tool definition
+func (t *FetchTool) Definition() mcp.Tool {
+	if t.maxChars > 0 {
+		return mcp.NewTool("fetch_page",
+			mcp.WithDescription("Fetch a web page, such as a search result URL, and return its readable text without navigation, ads and markup"),
+			mcp.WithString("url",
+				mcp.Required(),
+				mcp.Description("The http or https URL to fetch"),
+			),
+			mcp.WithNumber("max_chars",
+				mcp.Description(fmt.Sprintf("Most characters of text to return (1-%d)", t.maxChars)),
+			),
+			mcp.WithString("format",
+				mcp.Description("Return the main content as plain text, Markdown keeping headings, links, lists and tables, or cleaned HTML (default text)"),
+				mcp.Enum(fetch.Formats...),
+			),
+		)
+	}
+	return mcp.NewTool("fetch_url",
+		mcp.WithDescription("Fetch the content of a web page, such as a search result URL"),
+		mcp.WithString("url",
+			mcp.Required(),
+			mcp.Description("The http or https URL to fetch"),
+		),
+	)
+}
+
+// Handler returns the MCP tool handler function
+func (t *FetchTool) Handler() func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+		defer cancel()
+
+		rawURL, ok := request.Params.Arguments["url"].(string)
+		if !ok || rawURL == "" {
+			return mcp.NewToolResultError("url parameter is required and must be a string"), nil
+		}
+		maxChars := t.maxChars
+		if v, ok := request.Params.Arguments["max_chars"]; ok && t.maxChars > 0 {
+			n, ok := v.(float64)
+			if !ok || n < 1 || n != float64(int(n)) {
+				return mcp.NewToolResultError("max_chars must be a positive whole number"), nil
+			}
+			maxChars = min(int(n), t.maxChars)
+		}
+		format := fetch.FormatText
+		if v, ok := request.Params.Arguments["format"]; ok && t.maxChars > 0 {
+			format, _ = v.(string)
+			if !slices.Contains(fetch.Formats, format) {
+				return mcp.NewToolResultError(fmt.Sprintf("format must be one of %s", strings.Join(fetch.Formats, ", "))), nil
+			}
+		}
+
+		var page *fetch.Page
+		err := t.workers.Do(ctx, func(ctx context.Context) error {
+			var err error
+			page, err = t.fetcher.Fetch(ctx, rawURL)
+			return err
+		})
+
+		// Fall back to the latest archived copy of a dead, unreachable or
+		// refused page, keeping the live outcome when the archive has none
+		if t.fetcher.ArchiveEnabled() && ctx.Err() == nil && (fetch.Unreachable(err) || (err == nil && fetch.Unavailable(page))) {
+			var archived *fetch.Page
+			archiveErr := t.workers.Do(ctx, func(ctx context.Context) error {
+				var err error
+				archived, err = t.fetcher.FetchArchived(ctx, rawURL)
+				return err
+			})
+			if archiveErr == nil {
+				t.transcript.RecordFetch(archived)
+				return t.pageResult(archivedLabel(archived, page)+t.render(archived, format, maxChars), archived), nil
+			}
+		}
+		if err != nil {
+			var budgetErr *fetch.BudgetExceededError
+			if errors.As(err, &budgetErr) {
+				return mcp.NewToolResultError(budgetErr.Error()), nil
+			}
+			if ctx.Err() == context.DeadlineExceeded {
+				return mcp.NewToolResultError("Fetch timed out after 30 seconds"), nil
+			}
+			return mcp.NewToolResultError(fmt.Sprintf("Fetch failed: %v", err)), nil
+		}
+
+		t.transcript.RecordFetch(page)
+		return t.pageResult(t.render(page, format, maxChars), page), nil
+	}
+}
+
+// pageResult returns text as the tool result, with the page's metrics
+// attached as data so agents can rank pages without parsing the text
+func (t *FetchTool) pageResult(text string, page *fetch.Page) *mcp.CallToolResult {
+	result := mcp.NewToolResultText(text)
+	if page.Metrics != nil {
+		metrics := struct {
+			URL string `json:"url"`
+			*fetch.Metrics
+		}{page.URL, page.Metrics}
+		if content, err := structuredContent(PageMetricsURI, metrics, t.schemaVersion); err == nil {
+			result.Content = append(result.Content, content)
+		}
+	}
+	return result
+}
+
+// render renders a fetched page as the raw body or, for the fetch_page tool,
+// its readable content in format cut to maxChars
+func (t *FetchTool) render(page *fetch.Page, format string, maxChars int) string {
+	if t.maxChars > 0 {
+		return formatReadablePage(page, format, maxChars)
+	}
+	return formatPage(page)
+}
+
+// archivedLabel labels a page fetched from the archive so it is never
+// mistaken for the live page, which is nil if its host couldn't be reached
+func archivedLabel(page, live *fetch.Page) string {
+	reason := "the live page's host could not be reached"
+	switch {
+	case live == nil:
+	case live.Gate != "":
+		reason = fmt.Sprintf("the live page is behind a %s wall", live.Gate)
+	default:
+		reason = fmt.Sprintf("the live page returned status %d", live.StatusCode)
+	}
+	return fmt.Sprintf("Archived: Wayback Machine snapshot of %s from %s (%s)\n",
+		page.URL, page.ArchivedAt.UTC().Format("January 2, 2006 15:04 UTC"), reason)
+}
+
+// formatPage renders a fetched page as the text returned to the client
+func formatPage(page *fetch.Page) string {
+	var b strings.Builder
+	if !writePageHeader(&b, page) {
+		return b.String()
+	}
+	if page.Truncated {
+		b.WriteString("Truncated: yes\n")
+	}
+	writeMetrics(&b, page.Metrics)
+	b.WriteString("\n")
+	b.Write(page.Body)
+	return b.String()
+}
+
+// formatReadablePage renders the readable content of a fetched page in
+// format, cut to maxChars characters, as the text returned to the client
+func formatReadablePage(page *fetch.Page, format string, maxChars int) string {
+	var b strings.Builder
+	if !writePageHeader(&b, page) {
+		return b.String()
+	}
+	article := fetch.Extract(page, format)
+	text, cut := fetch.Cut(article.Text, maxChars)
+	if article.Title != "" {
+		b.WriteString(fmt.Sprintf("Title: %s\n", article.Title))
+	}
+	if page.Truncated || cut {
+		b.WriteString("Truncated: yes\n")
+	}
+	writeMetrics(&b, page.Metrics)
+	b.WriteString("\n")
+	b.WriteString(text)
+	b.WriteString("\n")
+	return b.String()
+}
+
+// writePageHeader writes the address, status and type of a page, and why its
+// content is withheld if it is. It reports whether the content may be shown.
+func writePageHeader(b *strings.Builder, page *fetch.Page) bool {
+	b.WriteString(fmt.Sprintf("URL: %s\n", page.FinalURL))
+	b.WriteString(fmt.Sprintf("Status: %d\n", page.StatusCode))
+	if page.ContentType != "" {
+		b.WriteString(fmt.Sprintf("Content-Type: %s\n", page.ContentType))
+	}
+	if page.Skipped != "" {
+		// Never put binary or oversized bodies into the model's context
+		b.WriteString(fmt.Sprintf("Skipped: %s\n", page.Skipped))
+		return false
+	}
+	if page.Gate != "" {
+		// A fragment of gated content reads as the whole article, so only the teaser is shown
+		b.WriteString(fmt.Sprintf("Gated: %s\n", page.Gate))
+		if page.Teaser != "" {
+			b.WriteString(fmt.Sprintf("\nTeaser: %s\n", page.Teaser))
+		}
+		return false
+	}
+	return true
+}
+
+// writeMetrics writes how much there is to read on a page, if measured
+func writeMetrics(b *strings.Builder, m *fetch.Metrics) {
+	if m == nil {
+		return
+	}
+	b.WriteString(fmt.Sprintf("Words: %d\n", m.Words))
+	b.WriteString(fmt.Sprintf("Reading time: %d min\n", m.ReadingMinutes))
+	if m.Language != "" {
+		b.WriteString(fmt.Sprintf("Language: %s\n", m.Language))
+	}
+}
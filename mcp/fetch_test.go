@@ -0,0 +1,218 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"com.moguyn/mcp-go-search/config"
+	"com.moguyn/mcp-go-search/fetch"
+	"com.moguyn/mcp-go-search/pool"
+)
+
+func TestFetchTool(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		_, _ = w.Write([]byte("hello from the page"))
+	}))
+	defer server.Close()
+
+	fetcher, err := fetch.NewFetcher(&config.Config{
+		FetchTimeout:           5 * time.Second,
+		FetchMaxPagesPerMinute: 1,
+		FetchMaxBytesPerMinute: 1024,
+		FetchAllowlist:         []string{"127.0.0.1"},
+	})
+	if err != nil {
+		t.Fatalf("NewFetcher returned an error: %v", err)
+	}
+	tool := NewFetchTool(fetcher).WithPool(pool.New(1, 5*time.Second))
+	if tool.Definition().Name != "fetch_url" {
+		t.Errorf("Expected tool name 'fetch_url', got '%s'", tool.Definition().Name)
+	}
+
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]interface{}{"url": server.URL}
+	result, err := tool.Handler()(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Handler returned an error: %v", err)
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+	if result.IsError || !strings.Contains(text, "hello from the page") || !strings.Contains(text, "Status: 200") {
+		t.Errorf("Unexpected result: %s", text)
+	}
+	if !strings.Contains(text, "Words: 4\nReading time: 1 min\n") {
+		t.Errorf("Expected the page's metrics, got %s", text)
+	}
+	if len(result.Content) != 2 {
+		t.Fatalf("Expected the metrics to be attached, got %d contents", len(result.Content))
+	}
+	metrics := result.Content[1].(mcp.EmbeddedResource).Resource.(mcp.TextResourceContents)
+	if metrics.URI != PageMetricsURI || metrics.Text != `{"schema_version":2,"data":{"url":"`+server.URL+`","words":4,"reading_minutes":1}}` {
+		t.Errorf("Unexpected metrics resource: %+v", metrics)
+	}
+
+	// The one page per minute has been used
+	result, _ = tool.Handler()(context.Background(), request)
+	if !result.IsError || !strings.Contains(result.Content[0].(mcp.TextContent).Text, "fetch budget exceeded") {
+		t.Errorf("Expected a budget error, got %+v", result.Content)
+	}
+
+	request.Params.Arguments = map[string]interface{}{}
+	result, _ = tool.Handler()(context.Background(), request)
+	if !result.IsError {
+		t.Error("Expected an error for a missing url")
+	}
+}
+
+func TestFetchTool_ArchiveFallback(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/wayback/available":
+			fmt.Fprintf(w, `{"archived_snapshots": {"closest": {"status": "200", "available": true, "url": "%s/web/20240102030405/dead", "timestamp": "20240102030405"}}}`, server.URL)
+		case "/web/20240102030405id_/dead":
+			w.Header().Set("Content-Type", "text/plain")
+			_, _ = w.Write([]byte("the page as it was"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	fetcher, err := fetch.NewFetcher(&config.Config{
+		FetchTimeout:           5 * time.Second,
+		FetchMaxPagesPerMinute: 5,
+		FetchMaxBytesPerMinute: 1024,
+		FetchAllowlist:         []string{"127.0.0.1"},
+		FetchArchiveFallback:   true,
+		FetchArchiveURL:        server.URL + "/wayback/available",
+	})
+	if err != nil {
+		t.Fatalf("NewFetcher returned an error: %v", err)
+	}
+	tool := NewFetchTool(fetcher).WithPool(pool.New(1, 5*time.Second))
+
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]interface{}{"url": server.URL + "/dead"}
+	result, err := tool.Handler()(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Handler returned an error: %v", err)
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+	if result.IsError || !strings.HasPrefix(text, "Archived: Wayback Machine snapshot of "+server.URL+"/dead from January 2, 2024 03:04 UTC (the live page returned status 404)") ||
+		!strings.Contains(text, "the page as it was") {
+		t.Errorf("Expected a labeled archived page, got %s", text)
+	}
+
+	// A host that doesn't resolve falls back to the archive as well
+	request.Params.Arguments = map[string]interface{}{"url": "http://unresolvable.invalid/dead"}
+	result, err = tool.Handler()(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Handler returned an error: %v", err)
+	}
+	text = result.Content[0].(mcp.TextContent).Text
+	if result.IsError || !strings.Contains(text, "(the live page's host could not be reached)") ||
+		!strings.Contains(text, "the page as it was") {
+		t.Errorf("Expected an archived page for an unresolvable host, got %s", text)
+	}
+}
+
+func TestFetchPageTool(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write([]byte(`<html><head><title>A Story</title></head><body>
+<nav><a href="/">Home</a></nav>
+<article><h1>A Story</h1><p>The first paragraph of the story.</p><p>The second paragraph.</p></article>
+<footer>Copyright</footer></body></html>`))
+	}))
+	defer server.Close()
+
+	fetcher, err := fetch.NewFetcher(&config.Config{
+		FetchTimeout:           5 * time.Second,
+		FetchMaxPagesPerMinute: 5,
+		FetchMaxBytesPerMinute: 4096,
+		FetchAllowlist:         []string{"127.0.0.1"},
+	})
+	if err != nil {
+		t.Fatalf("NewFetcher returned an error: %v", err)
+	}
+	tool := NewFetchPageTool(fetcher, 1000).WithPool(pool.New(1, 5*time.Second))
+	if tool.Definition().Name != "fetch_page" {
+		t.Errorf("Expected tool name 'fetch_page', got '%s'", tool.Definition().Name)
+	}
+
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]interface{}{"url": server.URL}
+	result, err := tool.Handler()(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Handler returned an error: %v", err)
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+	if result.IsError || !strings.Contains(text, "Title: A Story\n") ||
+		!strings.HasSuffix(text, "\n\nA Story\n\nThe first paragraph of the story.\n\nThe second paragraph.\n") {
+		t.Errorf("Expected the readable text of the page, got %q", text)
+	}
+	if strings.Contains(text, "Home") || strings.Contains(text, "Copyright") || strings.Contains(text, "<p>") {
+		t.Errorf("Expected boilerplate and markup to be removed, got %q", text)
+	}
+
+	request.Params.Arguments = map[string]interface{}{"url": server.URL, "max_chars": float64(20)}
+	result, _ = tool.Handler()(context.Background(), request)
+	text = result.Content[0].(mcp.TextContent).Text
+	if !strings.Contains(text, "Truncated: yes\n") || strings.Contains(text, "second") {
+		t.Errorf("Expected the text to be cut to max_chars, got %q", text)
+	}
+
+	request.Params.Arguments = map[string]interface{}{"url": server.URL, "format": "markdown"}
+	result, _ = tool.Handler()(context.Background(), request)
+	text = result.Content[0].(mcp.TextContent).Text
+	if !strings.HasSuffix(text, "\n\n# A Story\n\nThe first paragraph of the story.\n\nThe second paragraph.\n") {
+		t.Errorf("Expected the page as Markdown, got %q", text)
+	}
+
+	request.Params.Arguments = map[string]interface{}{"url": server.URL, "max_chars": float64(0)}
+	result, _ = tool.Handler()(context.Background(), request)
+	if !result.IsError {
+		t.Error("Expected an error for a max_chars of zero")
+	}
+
+	request.Params.Arguments = map[string]interface{}{"url": server.URL, "format": "pdf"}
+	result, _ = tool.Handler()(context.Background(), request)
+	if !result.IsError {
+		t.Error("Expected an error for an unknown format")
+	}
+}
+
+func TestFormatPage_Skipped(t *testing.T) {
+	text := formatPage(&fetch.Page{
+		FinalURL:    "https://example.com/report.pdf",
+		StatusCode:  200,
+		ContentType: "application/pdf",
+		Skipped:     fetch.SkippedBinary,
+		Body:        []byte("%PDF"),
+	})
+	if !strings.Contains(text, "Skipped: binary content") || strings.Contains(text, "%PDF") {
+		t.Errorf("Expected a skipped result without the body, got %q", text)
+	}
+}
+
+func TestFormatPage_Gated(t *testing.T) {
+	text := formatPage(&fetch.Page{
+		FinalURL:    "https://news.example.com/story",
+		StatusCode:  200,
+		ContentType: "text/html",
+		Gate:        fetch.GatePaywall,
+		Teaser:      "The first lines of the story",
+	})
+	expected := "URL: https://news.example.com/story\nStatus: 200\nContent-Type: text/html\nGated: paywall\n\nTeaser: The first lines of the story\n"
+	if text != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, text)
+	}
+}
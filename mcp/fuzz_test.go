@@ -0,0 +1,75 @@
+package mcp
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"com.moguyn/mcp-go-search/fixtures"
+	"com.moguyn/mcp-go-search/params"
+	"com.moguyn/mcp-go-search/search"
+)
+
+func FuzzBindSearchArguments(f *testing.F) {
+	f.Add(`{"query":"golang","freshness":"day","count":5,"summary":true}`)
+	f.Add(`{"query":"golang","count":1e308}`)
+	f.Add(`{"query":"golang","count":-1e308}`)
+	f.Add(`{"query":123,"count":"ten"}`)
+	f.Add(`{"query":"a","freshness":null,"summary":"yes"}`)
+
+	f.Fuzz(func(t *testing.T, raw string) {
+		var args map[string]interface{}
+		if err := json.Unmarshal([]byte(raw), &args); err != nil {
+			return
+		}
+
+		p, _, err := bindSearchArguments(args)
+		if err != nil {
+			return
+		}
+		if p.Query == "" || len(p.Query) > params.MaxQueryLength {
+			t.Errorf("Bound an invalid query of length %d", len(p.Query))
+		}
+		if p.Count < 1 || p.Count > params.MaxCount {
+			t.Errorf("Expected count within 1..%d, got %d", params.MaxCount, p.Count)
+		}
+	})
+}
+
+func FuzzSanitizeErrorMessage(f *testing.F) {
+	f.Add("request failed: Authorization: Bearer sk-abc123, retry later")
+	f.Add("Bearer ")
+	f.Add("Post https://api.bochaai.com/v1/web-search?key=secret: timeout")
+	f.Add("http://")
+
+	f.Fuzz(func(t *testing.T, msg string) {
+		sanitized := sanitizeErrorMessage(msg)
+		for _, part := range strings.Split(sanitized, "Bearer ")[1:] {
+			if !strings.HasPrefix(part, "[REDACTED]") {
+				t.Errorf("Expected every bearer token to be redacted, got %q", sanitized)
+			}
+		}
+		for _, prefix := range []string{"http://", "https://"} {
+			for _, part := range strings.Split(sanitized, prefix)[1:] {
+				if part != "" && !strings.ContainsRune(errorDelimiters, rune(part[0])) {
+					t.Errorf("Expected every URL to be redacted, got %q", sanitized)
+				}
+			}
+		}
+	})
+}
+
+func FuzzFormatSearchResults(f *testing.F) {
+	for _, name := range fixtures.Names() {
+		f.Add(string(fixtures.MustResponse(name)), true)
+	}
+
+	f.Fuzz(func(_ *testing.T, body string, summary bool) {
+		var response search.WebSearchResponse
+		if err := json.Unmarshal([]byte(body), &response); err != nil {
+			return
+		}
+		_ = formatSearchResults("query", &response, formatOptions{Freshness: "noLimit", Summary: summary, GroupByDate: summary, Now: time.Now()})
+	})
+}
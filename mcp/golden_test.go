@@ -0,0 +1,76 @@
+package mcp
+
+import (
+	"context"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"com.moguyn/mcp-go-search/config"
+	"com.moguyn/mcp-go-search/fixtures"
+	"com.moguyn/mcp-go-search/search/providers/bocha"
+)
+
+// update rewrites the golden files instead of comparing against them:
+// go test ./mcp -run Golden -update
+var update = flag.Bool("update", false, "update golden files")
+
+// TestSearchTool_Golden runs every fixture through the Bocha client and the
+// search tool formatter and compares the output with testdata/<fixture>.golden
+func TestSearchTool_Golden(t *testing.T) {
+	server := fixtures.NewServer()
+	defer server.Close()
+
+	service := bocha.NewWithConfig(&config.Config{
+		BochaAPIKey:         "test-api-key",
+		BochaAPIBaseURL:     server.URL,
+		BochaAISearchURL:    server.URL,
+		BochaAgentSearchURL: server.URL,
+		HTTPTimeout:         5 * time.Second,
+	})
+	handler := NewSearchTool(service).Handler()
+
+	for _, name := range fixtures.Names() {
+		t.Run(name, func(t *testing.T) {
+			request := mcp.CallToolRequest{}
+			request.Params.Arguments = map[string]interface{}{
+				"query":     name,
+				"freshness": "week",
+				"summary":   true,
+			}
+			switch name {
+			case fixtures.AISearch:
+				request.Params.Arguments["ai_search"] = true
+			case fixtures.AgentSearch:
+				request.Params.Arguments["agent"] = "bocha-scholar-agent"
+			}
+
+			result, err := handler(context.Background(), request)
+			if err != nil {
+				t.Fatalf("Handler returned an error: %v", err)
+			}
+			got := result.Content[0].(mcp.TextContent).Text
+			if result.IsError {
+				got = "ERROR: " + got
+			}
+
+			golden := filepath.Join("testdata", name+".golden")
+			if *update {
+				if err := os.WriteFile(golden, []byte(got), 0o600); err != nil {
+					t.Fatalf("Failed to update golden file: %v", err)
+				}
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("Failed to read golden file (run with -update to create it): %v", err)
+			}
+			if got != string(want) {
+				t.Errorf("Output does not match %s\n--- got ---\n%s\n--- want ---\n%s", golden, got, want)
+			}
+		})
+	}
+}
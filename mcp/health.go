@@ -0,0 +1,304 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"com.moguyn/mcp-go-search/search"
+	"com.moguyn/mcp-go-search/stats"
+)
+
+// HealthURI identifies the structured health report attached to health results
+const HealthURI = "search://health"
+
+// healthProbeQuery is the cheap query sent when a probe is requested
+const healthProbeQuery = "weather"
+
+// healthProbeTimeout bounds a probe search
+const healthProbeTimeout = 15 * time.Second
+
+// Overall health of the server
+const (
+	HealthOK       = "ok"
+	HealthDegraded = "degraded"
+	HealthDown     = "down"
+)
+
+// Reachability of a provider, judged from its last upstream call
+const (
+	ProviderReachable = "reachable"
+	ProviderFailing   = "failing"
+	ProviderUntested  = "untested"
+	ProviderDisabled  = "disabled"
+)
+
+// HealthSources are the parts of the server the health tool reports on. A nil
+// function is skipped, e.g. when caching is disabled.
+type HealthSources struct {
+	// Providers lists the configured providers and whether each is enabled
+	Providers     func() map[string]bool
+	RateLimit     func() search.RateLimitStats
+	Cache         func() search.CacheStats
+	SemanticCache func() search.CacheStats
+}
+
+// ProviderHealth is the state of one configured provider
+type ProviderHealth struct {
+	Name             string     `json:"name"`
+	Status           string     `json:"status"`
+	Queries          uint64     `json:"queries"`
+	Errors           uint64     `json:"errors"`
+	AverageLatencyMs float64    `json:"average_latency_ms"`
+	LastError        *time.Time `json:"last_error,omitempty"`
+}
+
+// HealthProbe is the outcome of a live search sent to the primary provider
+type HealthProbe struct {
+	OK        bool   `json:"ok"`
+	LatencyMs int64  `json:"latency_ms"`
+	Error     string `json:"error,omitempty"`
+}
+
+// HealthReport describes whether searches can currently succeed and why not
+type HealthReport struct {
+	Status        string                 `json:"status"`
+	Uptime        string                 `json:"uptime"`
+	Providers     []ProviderHealth       `json:"providers"`
+	Probe         *HealthProbe           `json:"probe,omitempty"`
+	RateLimit     *search.RateLimitStats `json:"rate_limit,omitempty"`
+	Cache         *search.CacheStats     `json:"cache,omitempty"`
+	SemanticCache *search.CacheStats     `json:"semantic_cache,omitempty"`
+}
+
+// HealthTool reports backend reachability, providers, rate limiting and
+// caching as an MCP tool, so clients can diagnose failures themselves
+type HealthTool struct {
+	collector     *stats.Collector
+	sources       HealthSources
+	probeName     string
+	probe         search.Service
+	schemaVersion int
+}
+
+// NewHealthTool creates a new health tool backed by the collector and sources
+func NewHealthTool(collector *stats.Collector, sources HealthSources) *HealthTool {
+	return &HealthTool{
+		collector:     collector,
+		sources:       sources,
+		schemaVersion: SchemaVersion,
+	}
+}
+
+// WithProbe lets a call check the named provider's reachability with a live
+// search sent to service, which should have no caches in front of it
+func (t *HealthTool) WithProbe(name string, service search.Service) *HealthTool {
+	t.probeName = name
+	t.probe = service
+	return t
+}
+
+// WithSchemaVersion sets the version of the structured output schema used for
+// attached data
+func (t *HealthTool) WithSchemaVersion(version int) *HealthTool {
+	t.schemaVersion = version
+	return t
+}
+
+// Definition returns the MCP tool definition
+func (t *HealthTool) Definition() mcp.Tool {
+	options := []mcp.ToolOption{
+		mcp.WithDescription("Report server health to diagnose failed searches: whether each configured provider is reachable, judged from its last call, error counts and latency, the rate limiter state and cache status"),
+	}
+	if t.probe != nil {
+		options = append(options, mcp.WithBoolean("probe",
+			mcp.Description("Send one live search to the primary provider to check it is reachable now; uses one query of the API quota"),
+		))
+	}
+	return mcp.NewTool("health", options...)
+}
+
+// Handler returns the MCP tool handler function
+func (t *HealthTool) Handler() func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		probe, _ := request.Params.Arguments["probe"].(bool)
+		if probe && t.probe == nil {
+			return mcp.NewToolResultError("probing is not available on this server"), nil
+		}
+
+		var checked *HealthProbe
+		if probe {
+			checked = t.runProbe(ctx)
+		}
+		report := t.report(checked)
+
+		result := mcp.NewToolResultText(formatHealth(report))
+		if content, err := structuredContent(HealthURI, report, t.schemaVersion); err == nil {
+			result.Content = append(result.Content, content)
+		}
+		return result, nil
+	}
+}
+
+// runProbe sends one cheap search to the probe service
+func (t *HealthTool) runProbe(ctx context.Context) *HealthProbe {
+	ctx, cancel := context.WithTimeout(ctx, healthProbeTimeout)
+	defer cancel()
+
+	start := time.Now()
+	_, err := t.probe.Search(ctx, healthProbeQuery, "noLimit", 1, false)
+	probe := &HealthProbe{OK: err == nil, LatencyMs: time.Since(start).Milliseconds()}
+	if err != nil {
+		probe.Error = sanitizeErrorMessage(err.Error())
+	}
+	return probe
+}
+
+// report builds the health report from the collected statistics, the sources
+// and the probe, if one was sent
+func (t *HealthTool) report(probe *HealthProbe) *HealthReport {
+	snapshot := t.collector.Snapshot()
+	report := &HealthReport{Uptime: snapshot.Uptime, Providers: []ProviderHealth{}, Probe: probe}
+
+	// The most recent call of each provider decides whether it is reachable
+	lastFailed := make(map[string]bool)
+	for _, record := range snapshot.Recent {
+		lastFailed[record.Provider] = record.Failed
+	}
+	counters := make(map[string]stats.ProviderStats)
+	for _, p := range snapshot.Providers {
+		counters[p.Name] = p
+	}
+
+	enabled := make(map[string]bool)
+	if t.sources.Providers != nil {
+		enabled = t.sources.Providers()
+	} else {
+		for name := range counters {
+			enabled[name] = true
+		}
+	}
+	names := make([]string, 0, len(enabled))
+	for name := range enabled {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	active, failing := 0, 0
+	for _, name := range names {
+		p := counters[name]
+		health := ProviderHealth{
+			Name:             name,
+			Queries:          p.Queries,
+			Errors:           p.Errors,
+			AverageLatencyMs: p.AverageLatencyMs,
+			LastError:        p.LastError,
+		}
+		failed, seen := lastFailed[name]
+		switch {
+		case !enabled[name]:
+			health.Status = ProviderDisabled
+		case probe != nil && name == t.probeName:
+			// A probe just sent is fresher than any earlier call
+			health.Status = ProviderReachable
+			if !probe.OK {
+				health.Status = ProviderFailing
+			}
+		case !seen && p.Queries > 0:
+			// Incognito calls and those that aged out of the recent queries
+			// leave only the counters to go on
+			health.Status = ProviderReachable
+			if p.LastError != nil && p.Errors == p.Queries {
+				health.Status = ProviderFailing
+			}
+		case !seen:
+			health.Status = ProviderUntested
+		case failed:
+			health.Status = ProviderFailing
+		default:
+			health.Status = ProviderReachable
+		}
+		if enabled[name] {
+			active++
+			if health.Status == ProviderFailing {
+				failing++
+			}
+		}
+		report.Providers = append(report.Providers, health)
+	}
+
+	switch {
+	case failing == active:
+		report.Status = HealthDown
+	case failing > 0:
+		report.Status = HealthDegraded
+	default:
+		report.Status = HealthOK
+	}
+
+	if t.sources.RateLimit != nil {
+		rateLimit := t.sources.RateLimit()
+		report.RateLimit = &rateLimit
+	}
+	if t.sources.Cache != nil {
+		cache := t.sources.Cache()
+		report.Cache = &cache
+	}
+	if t.sources.SemanticCache != nil {
+		semantic := t.sources.SemanticCache()
+		report.SemanticCache = &semantic
+	}
+	return report
+}
+
+// formatHealth renders a health report as the text returned to the client
+func formatHealth(report *HealthReport) string {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("Status: %s\n", report.Status))
+	b.WriteString(fmt.Sprintf("Uptime: %s\n", report.Uptime))
+	if report.Probe != nil {
+		if report.Probe.OK {
+			b.WriteString(fmt.Sprintf("Probe: succeeded in %d ms\n", report.Probe.LatencyMs))
+		} else {
+			b.WriteString(fmt.Sprintf("Probe: failed after %d ms: %s\n", report.Probe.LatencyMs, report.Probe.Error))
+		}
+	}
+
+	b.WriteString("\nProviders:\n")
+	if len(report.Providers) == 0 {
+		b.WriteString("- none configured\n")
+	}
+	for _, p := range report.Providers {
+		b.WriteString(fmt.Sprintf("- %s: %s (%d queries, %d errors", p.Name, p.Status, p.Queries, p.Errors))
+		if p.Queries > 0 {
+			b.WriteString(fmt.Sprintf(", %.0f ms average", p.AverageLatencyMs))
+		}
+		b.WriteString(")")
+		if p.LastError != nil {
+			b.WriteString(fmt.Sprintf(", last error at %s", p.LastError.Format(time.RFC3339)))
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	if report.RateLimit != nil {
+		b.WriteString(fmt.Sprintf("Rate limit: %.0f requests/s, burst %d, %.1f available now\n",
+			report.RateLimit.Limit, report.RateLimit.Burst, report.RateLimit.Available))
+	}
+	writeCacheHealth(&b, "Cache", report.Cache)
+	writeCacheHealth(&b, "Semantic cache", report.SemanticCache)
+	return b.String()
+}
+
+// writeCacheHealth renders one cache's counters, or that it is disabled
+func writeCacheHealth(b *strings.Builder, label string, cache *search.CacheStats) {
+	if cache == nil {
+		b.WriteString(fmt.Sprintf("%s: disabled\n", label))
+		return
+	}
+	b.WriteString(fmt.Sprintf("%s: %d entries, %d hits, %d misses\n", label, cache.Entries, cache.Hits, cache.Misses))
+}
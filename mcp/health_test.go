@@ -0,0 +1,157 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"com.moguyn/mcp-go-search/search"
+	"com.moguyn/mcp-go-search/stats"
+)
+
+func TestHealthTool(t *testing.T) {
+	collector := stats.NewCollector()
+	collector.Record(stats.Call{Provider: "bocha", Query: "one", Latency: 40 * time.Millisecond, Results: 5})
+	collector.Record(stats.Call{Provider: "brave", Query: "two", Latency: 10 * time.Millisecond, Err: errors.New("401 unauthorized")})
+	collector.Record(stats.Call{Provider: "bocha", Query: "three", Latency: 20 * time.Millisecond, Err: errors.New("timeout")})
+	collector.Record(stats.Call{Provider: "bocha", Query: "four", Latency: 30 * time.Millisecond, Results: 5})
+
+	tool := NewHealthTool(collector, HealthSources{
+		Providers: func() map[string]bool {
+			return map[string]bool{"bocha": true, "brave": true, "google": true, "jina": false}
+		},
+		RateLimit: func() search.RateLimitStats {
+			return search.RateLimitStats{Limit: 10, Burst: 20, Available: 19.5}
+		},
+		Cache: func() search.CacheStats {
+			return search.CacheStats{Entries: 3, Hits: 7, Misses: 4}
+		},
+	})
+	if tool.Definition().Name != "health" {
+		t.Errorf("Expected tool name 'health', got '%s'", tool.Definition().Name)
+	}
+	if _, ok := tool.Definition().InputSchema.Properties["probe"]; ok {
+		t.Error("Expected no probe parameter without a probe service")
+	}
+
+	result, err := tool.Handler()(context.Background(), mcp.CallToolRequest{})
+	if err != nil {
+		t.Fatalf("Handler returned an error: %v", err)
+	}
+	if result.IsError {
+		t.Fatal("Expected IsError to be false")
+	}
+
+	text := result.Content[0].(mcp.TextContent).Text
+	for _, want := range []string{
+		"Status: degraded",
+		"- bocha: reachable (3 queries, 1 errors, 30 ms average), last error at",
+		"- brave: failing (1 queries, 1 errors, 10 ms average)",
+		"- google: untested (0 queries, 0 errors)",
+		"- jina: disabled",
+		"Rate limit: 10 requests/s, burst 20, 19.5 available now",
+		"Cache: 3 entries, 7 hits, 4 misses",
+		"Semantic cache: disabled",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, text)
+		}
+	}
+
+	resource := result.Content[1].(mcp.EmbeddedResource).Resource.(mcp.TextResourceContents)
+	if resource.URI != HealthURI {
+		t.Errorf("Expected resource URI %s, got %s", HealthURI, resource.URI)
+	}
+	var envelope struct {
+		Data HealthReport `json:"data"`
+	}
+	if err := json.Unmarshal([]byte(resource.Text), &envelope); err != nil {
+		t.Fatalf("Failed to decode structured content: %v", err)
+	}
+	report := envelope.Data
+	if report.Status != HealthDegraded || len(report.Providers) != 4 || report.Cache == nil || report.SemanticCache != nil {
+		t.Errorf("Unexpected health report: %+v", report)
+	}
+}
+
+func TestHealthToolStatus(t *testing.T) {
+	// No provider has been called yet, so none is failing
+	tool := NewHealthTool(stats.NewCollector(), HealthSources{
+		Providers: func() map[string]bool { return map[string]bool{"bocha": true} },
+	})
+	if report := tool.report(nil); report.Status != HealthOK || report.Providers[0].Status != ProviderUntested {
+		t.Errorf("Unexpected health report: %+v", report)
+	}
+
+	// Every enabled provider failing its last call means searches can't succeed
+	collector := stats.NewCollector()
+	collector.Record(stats.Call{Provider: "bocha", Query: "q", Err: errors.New("503")})
+	tool = NewHealthTool(collector, HealthSources{})
+	if report := tool.report(nil); report.Status != HealthDown || report.Providers[0].Status != ProviderFailing {
+		t.Errorf("Unexpected health report: %+v", report)
+	}
+
+	// A disabled provider leaves nothing to search with
+	tool = NewHealthTool(stats.NewCollector(), HealthSources{
+		Providers: func() map[string]bool { return map[string]bool{"bocha": false} },
+	})
+	if report := tool.report(nil); report.Status != HealthDown {
+		t.Errorf("Expected status down, got %s", report.Status)
+	}
+}
+
+func TestHealthToolProbe(t *testing.T) {
+	collector := stats.NewCollector()
+	collector.Record(stats.Call{Provider: "bocha", Query: "q", Err: errors.New("timeout")})
+
+	var probeErr error
+	var queries []string
+	service := &MockSearchService{
+		SearchFunc: func(_ context.Context, query string, _ string, count int, _ bool) (*search.WebSearchResponse, error) {
+			queries = append(queries, query)
+			if count != 1 {
+				t.Errorf("Expected the probe to ask for one result, got %d", count)
+			}
+			return &search.WebSearchResponse{}, probeErr
+		},
+	}
+	tool := NewHealthTool(collector, HealthSources{}).WithProbe("bocha", service)
+	if _, ok := tool.Definition().InputSchema.Properties["probe"]; !ok {
+		t.Fatal("Expected a probe parameter")
+	}
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]interface{}{"probe": true}
+
+	// A successful probe overrides the failure of the provider's last call
+	result, err := tool.Handler()(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Handler returned an error: %v", err)
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+	if !strings.Contains(text, "Status: ok") || !strings.Contains(text, "Probe: succeeded in") || !strings.Contains(text, "- bocha: reachable") {
+		t.Errorf("Unexpected output:\n%s", text)
+	}
+
+	probeErr = errors.New("invalid api key")
+	result, _ = tool.Handler()(context.Background(), request)
+	text = result.Content[0].(mcp.TextContent).Text
+	if !strings.Contains(text, "Status: down") || !strings.Contains(text, "failed after") || !strings.Contains(text, "invalid api key") {
+		t.Errorf("Unexpected output:\n%s", text)
+	}
+	if len(queries) != 2 {
+		t.Errorf("Expected one search per probe, got %v", queries)
+	}
+
+	// Without a probe call nothing is searched
+	if _, err := tool.Handler()(context.Background(), mcp.CallToolRequest{}); err != nil {
+		t.Fatalf("Handler returned an error: %v", err)
+	}
+	if len(queries) != 2 {
+		t.Errorf("Expected no search without a probe, got %v", queries)
+	}
+}
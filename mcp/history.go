@@ -0,0 +1,154 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// HistoryURI is the URI of the search history resource; each search is
+// readable at HistoryURI + "/" + its id
+const HistoryURI = "search://history"
+
+// HistoryResult is a result returned by a search in the history
+type HistoryResult struct {
+	Name    string `json:"name"`
+	URL     string `json:"url"`
+	Snippet string `json:"snippet,omitempty"`
+}
+
+// HistoryEntry is a search in the history. Results are only set when a
+// single entry is read.
+type HistoryEntry struct {
+	ID        int             `json:"id"`
+	URI       string          `json:"uri"`
+	Query     string          `json:"query"`
+	Freshness string          `json:"freshness,omitempty"`
+	At        time.Time       `json:"at"`
+	Count     int             `json:"result_count"`
+	Results   []HistoryResult `json:"results,omitempty"`
+}
+
+// History lists the searches still in the transcript, oldest first
+func (t *Transcript) History() []HistoryEntry {
+	if t == nil {
+		return []HistoryEntry{}
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.expire()
+	history := []HistoryEntry{}
+	for _, entry := range t.entries {
+		if entry.page == nil {
+			history = append(history, historyEntry(entry))
+		}
+	}
+	return history
+}
+
+// HistorySearch returns the search with the given id and its results, if it is
+// still in the transcript
+func (t *Transcript) HistorySearch(id int) (HistoryEntry, bool) {
+	if t == nil {
+		return HistoryEntry{}, false
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.expire()
+	for _, entry := range t.entries {
+		if entry.page == nil && entry.id == id {
+			history := historyEntry(entry)
+			history.Results = make([]HistoryResult, 0, len(entry.results))
+			for _, result := range entry.results {
+				history.Results = append(history.Results, HistoryResult{Name: result.Name, URL: result.URL, Snippet: result.Snippet})
+			}
+			return history, true
+		}
+	}
+	return HistoryEntry{}, false
+}
+
+// historyEntry describes a search entry without its results
+func historyEntry(entry transcriptEntry) HistoryEntry {
+	return HistoryEntry{
+		ID:        entry.id,
+		URI:       fmt.Sprintf("%s/%d", HistoryURI, entry.id),
+		Query:     entry.query,
+		Freshness: entry.freshness,
+		At:        entry.at,
+		Count:     len(entry.results),
+	}
+}
+
+// historyIndex is the content of the search history resource
+type historyIndex struct {
+	Searches []HistoryEntry `json:"searches"`
+}
+
+// HistoryResource exposes the searches of the session as MCP resources: an
+// index of every search and a resource per search with its results
+type HistoryResource struct {
+	transcript *Transcript
+}
+
+// NewHistoryResource creates a new history resource backed by the transcript
+func NewHistoryResource(transcript *Transcript) *HistoryResource {
+	return &HistoryResource{
+		transcript: transcript,
+	}
+}
+
+// Definition returns the MCP resource definition of the history index
+func (r *HistoryResource) Definition() mcp.Resource {
+	return mcp.NewResource(HistoryURI, "Search history",
+		mcp.WithResourceDescription("The searches of this session, newest last, each with the URI of a resource holding its results"),
+		mcp.WithMIMEType("application/json"),
+	)
+}
+
+// EntryDefinition returns the MCP resource template definition of a single search
+func (r *HistoryResource) EntryDefinition() mcp.ResourceTemplate {
+	return mcp.NewResourceTemplate(HistoryURI+"/{id}", "Past search",
+		mcp.WithTemplateDescription("A search from the session history with the results it returned"),
+		mcp.WithTemplateMIMEType("application/json"),
+	)
+}
+
+// Handler returns the MCP resource handler function for the index and for
+// single searches
+func (r *HistoryResource) Handler() func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	return func(_ context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		uri := request.Params.URI
+		var content interface{}
+		if uri == HistoryURI {
+			content = historyIndex{Searches: r.transcript.History()}
+		} else {
+			raw, ok := strings.CutPrefix(uri, HistoryURI+"/")
+			if !ok {
+				return nil, fmt.Errorf("unknown resource %q", uri)
+			}
+			id, err := strconv.Atoi(raw)
+			if err != nil {
+				return nil, fmt.Errorf("invalid search id %q", raw)
+			}
+			entry, ok := r.transcript.HistorySearch(id)
+			if !ok {
+				return nil, fmt.Errorf("search %d is not in the history", id)
+			}
+			content = entry
+		}
+
+		data, err := json.MarshalIndent(content, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode search history: %w", err)
+		}
+		return []mcp.ResourceContents{
+			mcp.TextResourceContents{URI: uri, MIMEType: "application/json", Text: string(data)},
+		}, nil
+	}
+}
@@ -0,0 +1,91 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"com.moguyn/mcp-go-search/fetch"
+	"com.moguyn/mcp-go-search/params"
+	"com.moguyn/mcp-go-search/search"
+)
+
+func readHistory(t *testing.T, resource *HistoryResource, uri string, v interface{}) error {
+	t.Helper()
+	request := mcp.ReadResourceRequest{}
+	request.Params.URI = uri
+	contents, err := resource.Handler()(context.Background(), request)
+	if err != nil {
+		return err
+	}
+	text := contents[0].(mcp.TextResourceContents)
+	if text.URI != uri || text.MIMEType != "application/json" {
+		t.Errorf("Unexpected contents %s (%s)", text.URI, text.MIMEType)
+	}
+	if err := json.Unmarshal([]byte(text.Text), v); err != nil {
+		t.Fatalf("Failed to decode %s: %v", uri, err)
+	}
+	return nil
+}
+
+func TestHistoryResource(t *testing.T) {
+	transcript := NewTranscript()
+	transcript.SetRetention(0, 3)
+	transcript.RecordSearch(context.Background(), params.Search{Query: "first"}, &search.WebSearchResponse{})
+	transcript.RecordFetch(&fetch.Page{URL: "https://go.dev/", FinalURL: "https://go.dev/", StatusCode: 200})
+	transcript.RecordSearch(context.Background(), params.Search{Query: "go generics", Freshness: "week"}, &search.WebSearchResponse{Data: search.Data{WebPages: search.WebPages{
+		Value: []search.WebPageResult{
+			{Name: "Tutorial", URL: "https://go.dev/doc/tutorial/generics", Snippet: "Getting started"},
+			{Name: "Blog", URL: "https://go.dev/blog/intro-generics"},
+		},
+	}}})
+	transcript.RecordSearch(context.Background(), params.Search{Query: "third"}, &search.WebSearchResponse{})
+
+	resource := NewHistoryResource(transcript)
+	if definition := resource.Definition(); definition.URI != HistoryURI {
+		t.Errorf("Expected URI %s, got %s", HistoryURI, definition.URI)
+	}
+	if template := resource.EntryDefinition(); template.URITemplate != "search://history/{id}" {
+		t.Errorf("Unexpected URI template %s", template.URITemplate)
+	}
+
+	// The oldest search fell out of the retention limit, fetches aren't listed
+	var index historyIndex
+	if err := readHistory(t, resource, HistoryURI, &index); err != nil {
+		t.Fatalf("Handler returned an error: %v", err)
+	}
+	if len(index.Searches) != 2 {
+		t.Fatalf("Expected 2 searches, got %+v", index.Searches)
+	}
+	second := index.Searches[0]
+	if second.ID != 2 || second.URI != "search://history/2" || second.Query != "go generics" || second.Freshness != "week" ||
+		second.Count != 2 || second.Results != nil {
+		t.Errorf("Unexpected history entry: %+v", second)
+	}
+	if index.Searches[1].Query != "third" {
+		t.Errorf("Expected the newest search last, got %+v", index.Searches[1])
+	}
+
+	var entry HistoryEntry
+	if err := readHistory(t, resource, second.URI, &entry); err != nil {
+		t.Fatalf("Handler returned an error: %v", err)
+	}
+	if entry.Query != "go generics" || len(entry.Results) != 2 || entry.Results[0].Snippet != "Getting started" {
+		t.Errorf("Unexpected history entry: %+v", entry)
+	}
+
+	for _, uri := range []string{"search://history/1", "search://history/x", "search://other"} {
+		if err := readHistory(t, resource, uri, &entry); err == nil {
+			t.Errorf("Expected an error reading %s", uri)
+		}
+	}
+
+	// Ids are not reused after purging
+	transcript.Purge()
+	transcript.RecordSearch(context.Background(), params.Search{Query: "fresh"}, &search.WebSearchResponse{})
+	if history := transcript.History(); len(history) != 1 || history[0].ID != 4 {
+		t.Errorf("Expected a single search with id 4, got %+v", history)
+	}
+}
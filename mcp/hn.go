@@ -0,0 +1,117 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"com.moguyn/mcp-go-search/hackernews"
+	"com.moguyn/mcp-go-search/params"
+	"com.moguyn/mcp-go-search/pool"
+)
+
+// HNSearchTool searches Hacker News stories as an MCP tool
+type HNSearchTool struct {
+	client  *hackernews.Client
+	workers *pool.Pool
+}
+
+// NewHNSearchTool creates a new Hacker News search tool with the provided client
+func NewHNSearchTool(client *hackernews.Client) *HNSearchTool {
+	return &HNSearchTool{
+		client: client,
+	}
+}
+
+// WithPool runs searches on a shared worker pool, bounding how many run at once
+func (t *HNSearchTool) WithPool(workers *pool.Pool) *HNSearchTool {
+	t.workers = workers
+	return t
+}
+
+// Definition returns the MCP tool definition
+func (t *HNSearchTool) Definition() mcp.Tool {
+	return mcp.NewTool("hn_search",
+		mcp.WithDescription("Search Hacker News stories, with their points and number of comments, to find what developers are discussing"),
+		mcp.WithString("query",
+			mcp.Required(),
+			mcp.Description("The search query"),
+		),
+		mcp.WithString("sort",
+			mcp.Description("Order of the stories: relevance (default), or date for the most recent first"),
+			mcp.Enum(hackernews.SortRelevance, hackernews.SortDate),
+		),
+		mcp.WithString("freshness",
+			mcp.Description("Filter stories by when they were posted (noLimit, day, week, month, oneYear)"),
+			mcp.Enum(params.Freshness...),
+		),
+		mcp.WithNumber("count",
+			mcp.Description("Number of stories to return (1-50)"),
+		),
+	)
+}
+
+// Handler returns the MCP tool handler function
+func (t *HNSearchTool) Handler() func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+		defer cancel()
+
+		args := request.Params.Arguments
+		query, ok := args["query"].(string)
+		if !ok || query == "" {
+			return mcp.NewToolResultError("query parameter is required and must be a string"), nil
+		}
+		sort, _ := args["sort"].(string)
+		freshness, _ := args["freshness"].(string)
+		count := params.DefaultCount
+		if c, ok := args["count"].(float64); ok {
+			count = int(c)
+		}
+
+		var result *hackernews.Result
+		err := t.workers.Do(ctx, func(ctx context.Context) error {
+			var err error
+			result, err = t.client.Search(ctx, query, sort, freshness, count)
+			return err
+		})
+		if err != nil {
+			if ctx.Err() == context.DeadlineExceeded {
+				return mcp.NewToolResultError("Search timed out after 30 seconds"), nil
+			}
+			return mcp.NewToolResultError(fmt.Sprintf("Search failed: %v", err)), nil
+		}
+		return mcp.NewToolResultText(formatStories(result)), nil
+	}
+}
+
+// formatStories renders Hacker News stories as the text returned to the client
+func formatStories(result *hackernews.Result) string {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("Hacker News stories for %q, by %s (%d found):\n\n", result.Query, result.Sort, result.Total))
+	if len(result.Hits) == 0 {
+		b.WriteString("No stories found.\n")
+		return b.String()
+	}
+	for i, story := range result.Hits {
+		b.WriteString(fmt.Sprintf("%d. %s\n", i+1, story.Title))
+		// Ask HN and other text posts link to no page of their own
+		if story.URL != "" {
+			b.WriteString(fmt.Sprintf("   URL: %s\n", story.URL))
+		}
+		b.WriteString(fmt.Sprintf("   Discussion: %s\n", story.DiscussionURL()))
+		b.WriteString(fmt.Sprintf("   Points: %d\n", story.Points))
+		b.WriteString(fmt.Sprintf("   Comments: %d\n", story.NumComments))
+		if story.Author != "" {
+			b.WriteString(fmt.Sprintf("   Author: %s\n", story.Author))
+		}
+		if !story.CreatedAt.IsZero() {
+			b.WriteString(fmt.Sprintf("   Posted: %s\n", story.CreatedAt.Format("January 2, 2006")))
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
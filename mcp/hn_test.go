@@ -0,0 +1,78 @@
+package mcp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"com.moguyn/mcp-go-search/config"
+	"com.moguyn/mcp-go-search/hackernews"
+)
+
+func TestHNSearchTool_Handler(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/search_by_date" || r.URL.Query().Get("hitsPerPage") != "5" {
+			t.Errorf("Unexpected request %s", r.URL)
+		}
+		_, _ = w.Write([]byte(`{"nbHits": 2, "hits": [
+			{"objectID": "39312960", "title": "Go 1.22 is released", "url": "https://go.dev/blog/go1.22", "author": "gopher", "points": 512, "num_comments": 230, "created_at": "2024-02-06T17:02:11Z"},
+			{"objectID": "39320001", "title": "Ask HN: How do you structure Go projects?", "url": null, "points": 12, "num_comments": 8}
+		]}`))
+	}))
+	defer server.Close()
+
+	tool := NewHNSearchTool(hackernews.NewClient(&config.Config{HNAPIURL: server.URL, HTTPTimeout: 5 * time.Second}))
+	if tool.Definition().Name != "hn_search" {
+		t.Errorf("Expected the hn_search tool, got %s", tool.Definition().Name)
+	}
+	handler := tool.Handler()
+
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]interface{}{"query": "go", "sort": "date", "count": float64(5)}
+	result, err := handler(context.Background(), request)
+	if err != nil || result.IsError {
+		t.Fatalf("Expected a result, got %v (%v)", result, err)
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+	expected := "Hacker News stories for \"go\", by date (2 found):\n\n" +
+		"1. Go 1.22 is released\n" +
+		"   URL: https://go.dev/blog/go1.22\n" +
+		"   Discussion: https://news.ycombinator.com/item?id=39312960\n" +
+		"   Points: 512\n" +
+		"   Comments: 230\n" +
+		"   Author: gopher\n" +
+		"   Posted: February 6, 2024\n\n" +
+		"2. Ask HN: How do you structure Go projects?\n" +
+		"   Discussion: https://news.ycombinator.com/item?id=39320001\n" +
+		"   Points: 12\n" +
+		"   Comments: 8\n\n"
+	if text != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, text)
+	}
+}
+
+func TestHNSearchTool_Handler_Errors(t *testing.T) {
+	handler := NewHNSearchTool(hackernews.NewClient(&config.Config{HNAPIURL: "http://127.0.0.1:0", HTTPTimeout: time.Second})).Handler()
+	for _, args := range []map[string]interface{}{
+		{},
+		{"query": "go", "sort": "popular"},
+	} {
+		request := mcp.CallToolRequest{}
+		request.Params.Arguments = args
+		if result, _ := handler(context.Background(), request); !result.IsError {
+			t.Errorf("Expected an error for %v", args)
+		}
+	}
+}
+
+func TestFormatStories_Empty(t *testing.T) {
+	text := formatStories(&hackernews.Result{Query: "zzzxq", Sort: hackernews.SortRelevance, Hits: []hackernews.Story{}})
+	if !strings.Contains(text, "No stories found.") {
+		t.Errorf("Expected the empty notice, got:\n%s", text)
+	}
+}
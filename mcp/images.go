@@ -0,0 +1,72 @@
+package mcp
+
+import (
+	"fmt"
+	"math"
+
+	"com.moguyn/mcp-go-search/search"
+)
+
+// imageFilter limits the image results shown, since tiny icons and ads often
+// fill the image section. Zero values mean no limit.
+type imageFilter struct {
+	MaxImages int
+	MinWidth  int
+	MinHeight int
+}
+
+// bindImageFilter reads the max_images, min_width and min_height arguments
+func bindImageFilter(args map[string]interface{}) (imageFilter, error) {
+	var f imageFilter
+	for _, arg := range []struct {
+		name  string
+		value *int
+	}{
+		{"max_images", &f.MaxImages},
+		{"min_width", &f.MinWidth},
+		{"min_height", &f.MinHeight},
+	} {
+		raw, ok := args[arg.name]
+		if !ok {
+			continue
+		}
+		n, ok := raw.(float64)
+		if !ok || math.IsNaN(n) || n < 0 || n != math.Trunc(n) {
+			return imageFilter{}, fmt.Errorf("%s must be a non-negative integer", arg.name)
+		}
+		*arg.value = int(math.Min(n, math.MaxInt32))
+	}
+	return f, nil
+}
+
+// active reports whether the filter removes anything
+func (f imageFilter) active() bool {
+	return f.MaxImages > 0 || f.MinWidth > 0 || f.MinHeight > 0
+}
+
+// apply returns the images that meet the minimum size, in order and at most
+// MaxImages of them. Images of unknown size fail a minimum size.
+func (f imageFilter) apply(images []search.ImageResult) []search.ImageResult {
+	kept := make([]search.ImageResult, 0, len(images))
+	for _, image := range images {
+		if f.MaxImages > 0 && len(kept) == f.MaxImages {
+			break
+		}
+		if image.Width < f.MinWidth || image.Height < f.MinHeight {
+			continue
+		}
+		kept = append(kept, image)
+	}
+	return kept
+}
+
+// filterImages returns the response with its images filtered, leaving the
+// original untouched since it may be cached or in the transcript
+func filterImages(response *search.WebSearchResponse, f imageFilter) *search.WebSearchResponse {
+	if !f.active() || len(response.Data.Images.Value) == 0 {
+		return response
+	}
+	filtered := *response
+	filtered.Data.Images.Value = f.apply(response.Data.Images.Value)
+	return &filtered
+}
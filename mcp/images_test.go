@@ -0,0 +1,90 @@
+package mcp
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"com.moguyn/mcp-go-search/search"
+)
+
+func TestBindImageFilter(t *testing.T) {
+	f, err := bindImageFilter(map[string]interface{}{"max_images": float64(3), "min_width": float64(200)})
+	if err != nil {
+		t.Fatalf("bindImageFilter returned an error: %v", err)
+	}
+	if f.MaxImages != 3 || f.MinWidth != 200 || f.MinHeight != 0 {
+		t.Errorf("Unexpected filter: %+v", f)
+	}
+
+	for _, args := range []map[string]interface{}{
+		{"max_images": float64(-1)},
+		{"min_width": 2.5},
+		{"min_height": "100"},
+	} {
+		if _, err := bindImageFilter(args); err == nil {
+			t.Errorf("Expected error for %v, got nil", args)
+		}
+	}
+}
+
+func TestImageFilter_Apply(t *testing.T) {
+	images := []search.ImageResult{
+		{ContentURL: "icon", Width: 16, Height: 16},
+		{ContentURL: "photo", Width: 800, Height: 600},
+		{ContentURL: "unknown"},
+		{ContentURL: "banner", Width: 728, Height: 90},
+		{ContentURL: "poster", Width: 600, Height: 900},
+	}
+	tests := []struct {
+		filter   imageFilter
+		expected string
+	}{
+		{imageFilter{}, "icon,photo,unknown,banner,poster"},
+		{imageFilter{MaxImages: 2}, "icon,photo"},
+		{imageFilter{MinWidth: 200}, "photo,banner,poster"},
+		{imageFilter{MinWidth: 200, MinHeight: 200}, "photo,poster"},
+		{imageFilter{MinWidth: 200, MaxImages: 1}, "photo"},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, image := range tt.filter.apply(images) {
+			got = append(got, image.ContentURL)
+		}
+		if strings.Join(got, ",") != tt.expected {
+			t.Errorf("Expected %s for %+v, got %v", tt.expected, tt.filter, got)
+		}
+	}
+}
+
+func TestHandler_ImageFilter(t *testing.T) {
+	response := &search.WebSearchResponse{}
+	response.Data.Images.Value = []search.ImageResult{
+		{ContentURL: "https://example.com/icon.png", Width: 16, Height: 16},
+		{ContentURL: "https://example.com/photo.jpg", Width: 800, Height: 600},
+	}
+	service := &MockSearchService{
+		SearchFunc: func(_ context.Context, _ string, _ string, _ int, _ bool) (*search.WebSearchResponse, error) {
+			return response, nil
+		},
+	}
+	handler := NewSearchTool(service).Handler()
+
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]interface{}{"query": "photos", "min_width": float64(100)}
+	result, _ := handler(context.Background(), request)
+	text := result.Content[0].(mcp.TextContent).Text
+	if strings.Contains(text, "icon.png") || !strings.Contains(text, "1. Image\n   URL: https://example.com/photo.jpg") {
+		t.Errorf("Expected only the large image, got:\n%s", text)
+	}
+	if len(response.Data.Images.Value) != 2 {
+		t.Error("Expected the provider's response to be left untouched")
+	}
+
+	request.Params.Arguments = map[string]interface{}{"query": "photos", "max_images": float64(-2)}
+	if result, _ := handler(context.Background(), request); !result.IsError {
+		t.Error("Expected an error for a negative max_images")
+	}
+}
@@ -0,0 +1,64 @@
+package mcp
+
+import (
+	"fmt"
+	"strings"
+
+	"com.moguyn/mcp-go-search/search"
+)
+
+// AnswerURI identifies the structured direct answer attached to search results
+const AnswerURI = "search://answer"
+
+// RichSnippetsURI identifies the structured rich snippets attached to search results
+const RichSnippetsURI = "search://rich-snippets"
+
+// richSnippet is the rich snippet of one result, as attached to search results
+type richSnippet struct {
+	URL string `json:"url"`
+	search.RichSnippet
+}
+
+// answerOf returns the response's direct answer, or nil when it has none
+func answerOf(response *search.WebSearchResponse) *search.Answer {
+	if response.Data.Answer == nil || strings.TrimSpace(response.Data.Answer.Text) == "" {
+		return nil
+	}
+	answer := *response.Data.Answer
+	answer.Text = strings.TrimSpace(answer.Text)
+	return &answer
+}
+
+// richSnippets returns the rich snippets of the results that have one, in
+// result order, with blank facts removed
+func richSnippets(results []search.WebPageResult) []richSnippet {
+	var snippets []richSnippet
+	for _, result := range results {
+		if result.RichSnippet == nil {
+			continue
+		}
+		snippet := richSnippet{URL: result.URL, RichSnippet: search.RichSnippet{Type: result.RichSnippet.Type}}
+		for _, fact := range result.RichSnippet.Facts {
+			fact.Label = strings.TrimSpace(fact.Label)
+			fact.Value = strings.TrimSpace(fact.Value)
+			if fact.Label != "" && fact.Value != "" {
+				snippet.Facts = append(snippet.Facts, fact)
+			}
+		}
+		if len(snippet.Facts) > 0 {
+			snippets = append(snippets, snippet)
+		}
+	}
+	return snippets
+}
+
+// writeAnswer renders a direct answer with the sources it is based on
+func writeAnswer(b *strings.Builder, answer *search.Answer) {
+	b.WriteString("Answer:\n")
+	b.WriteString("=======\n\n")
+	b.WriteString(answer.Text + "\n")
+	for _, source := range answer.Sources {
+		b.WriteString(fmt.Sprintf("   Source: %s\n", source))
+	}
+	b.WriteString("\n")
+}
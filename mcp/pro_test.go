@@ -0,0 +1,115 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"com.moguyn/mcp-go-search/search"
+)
+
+func TestAnswerOf(t *testing.T) {
+	response := &search.WebSearchResponse{}
+	if answerOf(response) != nil {
+		t.Error("Expected no answer for a response without one")
+	}
+	response.Data.Answer = &search.Answer{Text: "  "}
+	if answerOf(response) != nil {
+		t.Error("Expected no answer without text")
+	}
+	response.Data.Answer = &search.Answer{Text: " Since Go 1.18. "}
+	if answer := answerOf(response); answer == nil || answer.Text != "Since Go 1.18." {
+		t.Errorf("Expected the trimmed answer, got %+v", answer)
+	}
+}
+
+func TestRichSnippets(t *testing.T) {
+	results := []search.WebPageResult{
+		{URL: "https://example.com/plain"},
+		{URL: "https://example.com/recipe", RichSnippet: &search.RichSnippet{
+			Type:  "recipe",
+			Facts: []search.Fact{{Label: "Cook time", Value: "20 min"}, {Label: "Yield", Value: " "}},
+		}},
+		{URL: "https://example.com/blank", RichSnippet: &search.RichSnippet{Type: "product"}},
+	}
+	snippets := richSnippets(results)
+	if len(snippets) != 1 || snippets[0].URL != "https://example.com/recipe" || len(snippets[0].Facts) != 1 {
+		t.Errorf("Expected only the recipe with its non-blank fact, got %+v", snippets)
+	}
+	if len(results[1].RichSnippet.Facts) != 2 {
+		t.Error("Expected the results not to be modified")
+	}
+}
+
+func TestHandler_Pro(t *testing.T) {
+	var pro bool
+	service := &MockSearchService{
+		SearchFunc: func(ctx context.Context, _ string, _ string, _ int, _ bool) (*search.WebSearchResponse, error) {
+			pro = search.Pro(ctx)
+			response := &search.WebSearchResponse{}
+			response.Data.Answer = &search.Answer{Text: "Since Go 1.18.", Sources: []string{"https://go.dev/blog/intro-generics"}}
+			response.Data.WebPages.Value = []search.WebPageResult{{
+				Name:        "Intro",
+				URL:         "https://go.dev/blog/intro-generics",
+				RichSnippet: &search.RichSnippet{Type: "article", Facts: []search.Fact{{Label: "Reading time", Value: "8 min"}}},
+			}}
+			return response, nil
+		},
+	}
+	call := func(tool *SearchTool, args map[string]interface{}) *mcp.CallToolResult {
+		request := mcp.CallToolRequest{}
+		request.Params.Arguments = args
+		result, _ := tool.Handler()(context.Background(), request)
+		return result
+	}
+
+	tool := NewSearchTool(service)
+	result := call(tool, map[string]interface{}{"query": "go generics", "pro": true})
+	if !pro {
+		t.Error("Expected pro to select the pro endpoint")
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+	if !strings.Contains(text, "Answer:\n=======\n\nSince Go 1.18.\n   Source: https://go.dev/blog/intro-generics\n") || !strings.Contains(text, "   Reading time: 8 min\n") {
+		t.Errorf("Expected the answer and the rich snippet, got:\n%s", text)
+	}
+	uris := make(map[string]string)
+	for _, content := range result.Content[1:] {
+		if resource, ok := content.(mcp.EmbeddedResource); ok {
+			if contents, ok := resource.Resource.(mcp.TextResourceContents); ok {
+				uris[contents.URI] = contents.Text
+			}
+		}
+	}
+	var envelope struct {
+		Data []richSnippet `json:"data"`
+	}
+	if err := json.Unmarshal([]byte(uris[RichSnippetsURI]), &envelope); err != nil || len(envelope.Data) != 1 || envelope.Data[0].Type != "article" {
+		t.Errorf("Expected the rich snippets as data, got %q (%v)", uris[RichSnippetsURI], err)
+	}
+	if !strings.Contains(uris[AnswerURI], `"text":"Since Go 1.18."`) {
+		t.Errorf("Expected the answer as data, got %q", uris[AnswerURI])
+	}
+
+	// The configured default can be turned off per call
+	tool.WithPro(true)
+	call(tool, map[string]interface{}{"query": "go generics"})
+	if !pro {
+		t.Error("Expected the configured default to select the pro endpoint")
+	}
+	call(tool, map[string]interface{}{"query": "go generics", "pro": false})
+	if pro {
+		t.Error("Expected pro=false to override the configured default")
+	}
+
+	// A provider without a pro endpoint ignores the default but rejects the argument
+	limited := NewSearchTool(&limitedSearchService{*service}).WithPro(true)
+	if result := call(limited, map[string]interface{}{"query": "go generics"}); result.IsError || pro {
+		t.Error("Expected the default to be ignored by a provider without a pro endpoint")
+	}
+	if result := call(limited, map[string]interface{}{"query": "go generics", "pro": true}); !result.IsError {
+		t.Error("Expected an error for pro with a provider without a pro endpoint")
+	}
+}
@@ -0,0 +1,88 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"com.moguyn/mcp-go-search/pool"
+)
+
+// ToolProvider is implemented by every tool the server can expose
+type ToolProvider interface {
+	Definition() mcp.Tool
+	Handler() func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error)
+}
+
+// Interactive wraps a tool handler to schedule its worker pool jobs as
+// interactive work, so background work waiting for workers doesn't hold up
+// a client waiting on the call. Calls the transport already scheduled as the
+// tenant of their session keep it.
+func Interactive(handler func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error)) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if pool.TenantOf(ctx) == "" {
+			ctx = pool.WithTenant(ctx, pool.TenantInteractive)
+		}
+		return handler(ctx, request)
+	}
+}
+
+// Priorities a call can ask to be scheduled with
+const (
+	PriorityInteractive = pool.TenantInteractive
+	PriorityBackground  = pool.TenantBackground
+)
+
+// withPriority adds the priority parameter to tools whose calls may be run as
+// background work
+func withPriority() mcp.ToolOption {
+	return mcp.WithString("priority",
+		mcp.Description("interactive (default) for a search someone is waiting on, or background for bulk work such as research batches, which then yields workers and rate limit to interactive calls and may take longer"),
+		mcp.Enum(PriorityInteractive, PriorityBackground),
+	)
+}
+
+// applyPriority schedules the call's worker pool jobs and upstream requests as
+// background work when its priority asks for it
+func applyPriority(ctx context.Context, args map[string]interface{}) (context.Context, error) {
+	switch priority, _ := args["priority"].(string); priority {
+	case "", PriorityInteractive:
+		return ctx, nil
+	case PriorityBackground:
+		return pool.WithTenant(ctx, pool.TenantBackground), nil
+	default:
+		return ctx, fmt.Errorf("invalid priority %q (expected %s or %s)", priority, PriorityInteractive, PriorityBackground)
+	}
+}
+
+// FilterTools returns the tools whose names appear in allowed, preserving the
+// order of tools. A nil allowed list means no restriction. Names in allowed
+// that do not match any tool are returned as unknown.
+func FilterTools(tools []ToolProvider, allowed []string) (filtered []ToolProvider, unknown []string) {
+	if allowed == nil {
+		return tools, nil
+	}
+
+	allowedSet := make(map[string]bool, len(allowed))
+	for _, name := range allowed {
+		allowedSet[name] = true
+	}
+
+	known := make(map[string]bool, len(tools))
+	for _, tool := range tools {
+		name := tool.Definition().Name
+		known[name] = true
+		if allowedSet[name] {
+			filtered = append(filtered, tool)
+		}
+	}
+
+	for _, name := range allowed {
+		if !known[name] {
+			unknown = append(unknown, name)
+		}
+	}
+
+	return filtered, unknown
+}
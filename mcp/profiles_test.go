@@ -0,0 +1,119 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"com.moguyn/mcp-go-search/pool"
+	"com.moguyn/mcp-go-search/search"
+)
+
+// namedTool is a minimal ToolProvider used to test filtering
+type namedTool string
+
+// Definition returns a tool with the receiver's name
+func (n namedTool) Definition() mcp.Tool {
+	return mcp.NewTool(string(n))
+}
+
+// Handler returns a handler that echoes the tool name
+func (n namedTool) Handler() func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(_ context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText(string(n)), nil
+	}
+}
+
+func TestFilterTools(t *testing.T) {
+	tools := []ToolProvider{namedTool("search"), namedTool("fetch_url"), namedTool("deep_search")}
+
+	// No restriction
+	filtered, unknown := FilterTools(tools, nil)
+	if len(filtered) != 3 || len(unknown) != 0 {
+		t.Errorf("Expected all tools and no unknown names, got %d tools and %v", len(filtered), unknown)
+	}
+
+	// Restricted profile
+	filtered, unknown = FilterTools(tools, []string{"deep_search", "search", "missing"})
+	if len(filtered) != 2 {
+		t.Fatalf("Expected 2 tools, got %d", len(filtered))
+	}
+	if filtered[0].Definition().Name != "search" || filtered[1].Definition().Name != "deep_search" {
+		t.Errorf("Expected tools in registration order, got %s, %s", filtered[0].Definition().Name, filtered[1].Definition().Name)
+	}
+	if len(unknown) != 1 || unknown[0] != "missing" {
+		t.Errorf("Expected unknown tool 'missing', got %v", unknown)
+	}
+
+	// Empty profile exposes nothing
+	filtered, _ = FilterTools(tools, []string{})
+	if len(filtered) != 0 {
+		t.Errorf("Expected no tools for empty profile, got %d", len(filtered))
+	}
+}
+
+func TestSearchToolImplementsToolProvider(_ *testing.T) {
+	var _ ToolProvider = NewSearchTool(&MockSearchService{})
+}
+
+func TestInteractive(t *testing.T) {
+	var tenant string
+	handler := Interactive(func(ctx context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		tenant = pool.TenantOf(ctx)
+		return mcp.NewToolResultText("ok"), nil
+	})
+	if _, err := handler(context.Background(), mcp.CallToolRequest{}); err != nil {
+		t.Fatalf("Handler returned an error: %v", err)
+	}
+	if tenant != pool.TenantInteractive {
+		t.Errorf("Expected the call scheduled as %q, got %q", pool.TenantInteractive, tenant)
+	}
+	// A session's calls stay scheduled as the session
+	if _, err := handler(pool.WithTenant(context.Background(), "session:1"), mcp.CallToolRequest{}); err != nil {
+		t.Fatalf("Handler returned an error: %v", err)
+	}
+	if tenant != "session:1" {
+		t.Errorf("Expected the call scheduled as its session, got %q", tenant)
+	}
+}
+
+func TestPriority(t *testing.T) {
+	var tenants []string
+	service := &MockSearchService{
+		SearchFunc: func(ctx context.Context, _ string, _ string, _ int, _ bool) (*search.WebSearchResponse, error) {
+			tenants = append(tenants, pool.TenantOf(ctx))
+			return &search.WebSearchResponse{}, nil
+		},
+	}
+	searchHandler := Interactive(NewSearchTool(service).Handler())
+	batchHandler := Interactive(NewBatchSearchTool(service).Handler())
+
+	for _, call := range []struct {
+		handler   func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error)
+		arguments map[string]interface{}
+		expected  string
+	}{
+		{searchHandler, map[string]interface{}{"query": "one"}, pool.TenantInteractive},
+		{searchHandler, map[string]interface{}{"query": "two", "priority": "interactive"}, pool.TenantInteractive},
+		{searchHandler, map[string]interface{}{"query": "three", "priority": "background"}, pool.TenantBackground},
+		{batchHandler, map[string]interface{}{"queries": []interface{}{"four"}, "priority": "background"}, pool.TenantBackground},
+	} {
+		tenants = nil
+		request := mcp.CallToolRequest{}
+		request.Params.Arguments = call.arguments
+		result, err := call.handler(context.Background(), request)
+		if err != nil || result.IsError {
+			t.Fatalf("Unexpected result for %v: %v, %+v", call.arguments, err, result)
+		}
+		if len(tenants) != 1 || tenants[0] != call.expected {
+			t.Errorf("Expected %v to search as %q, got %v", call.arguments, call.expected, tenants)
+		}
+	}
+
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]interface{}{"query": "five", "priority": "urgent"}
+	if result, _ := searchHandler(context.Background(), request); !result.IsError {
+		t.Error("Expected an error for an unknown priority")
+	}
+}
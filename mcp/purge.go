@@ -0,0 +1,102 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Values of the purge_data scope parameter
+const (
+	// PurgeHistory erases the session transcript and recent query list
+	PurgeHistory = "history"
+	// PurgeSaved deletes every saved result
+	PurgeSaved = "saved"
+	// PurgeCache empties the response caches
+	PurgeCache = "cache"
+	// PurgeAll erases all of the above
+	PurgeAll = "all"
+)
+
+// DataStores are the stores that can be purged. A nil function is skipped,
+// e.g. when caching is disabled.
+type DataStores struct {
+	History func() int
+	Saved   func() (int, error)
+	Cache   func() int
+}
+
+// PurgeCounts reports how many items were erased from each store
+type PurgeCounts struct {
+	History int `json:"history"`
+	Saved   int `json:"saved"`
+	Cache   int `json:"cache"`
+}
+
+// Purge erases the stores in scope
+func (d DataStores) Purge(scope string) (PurgeCounts, error) {
+	var counts PurgeCounts
+	switch scope {
+	case PurgeHistory, PurgeSaved, PurgeCache, PurgeAll:
+	default:
+		return counts, fmt.Errorf("invalid scope %q (expected history, saved, cache or all)", scope)
+	}
+
+	if (scope == PurgeHistory || scope == PurgeAll) && d.History != nil {
+		counts.History = d.History()
+	}
+	if (scope == PurgeCache || scope == PurgeAll) && d.Cache != nil {
+		counts.Cache = d.Cache()
+	}
+	if (scope == PurgeSaved || scope == PurgeAll) && d.Saved != nil {
+		n, err := d.Saved()
+		if err != nil {
+			return counts, fmt.Errorf("failed to purge saved results: %w", err)
+		}
+		counts.Saved = n
+	}
+	return counts, nil
+}
+
+// PurgeDataTool erases stored history, saved results and cached responses as an MCP tool
+type PurgeDataTool struct {
+	stores DataStores
+}
+
+// NewPurgeDataTool creates a new purge tool for the stores
+func NewPurgeDataTool(stores DataStores) *PurgeDataTool {
+	return &PurgeDataTool{
+		stores: stores,
+	}
+}
+
+// Definition returns the MCP tool definition
+func (t *PurgeDataTool) Definition() mcp.Tool {
+	return mcp.NewTool("purge_data",
+		mcp.WithDescription("Permanently erase stored data: the search history of this session, saved results, and cached search responses. This cannot be undone."),
+		mcp.WithString("scope",
+			mcp.Required(),
+			mcp.Description("What to erase: history, saved, cache, or all"),
+			mcp.Enum(PurgeHistory, PurgeSaved, PurgeCache, PurgeAll),
+		),
+	)
+}
+
+// Handler returns the MCP tool handler function
+func (t *PurgeDataTool) Handler() func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(_ context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// Erasing everything must be asked for, never a default
+		scope, _ := request.Params.Arguments["scope"].(string)
+		if scope == "" {
+			return mcp.NewToolResultError("scope is required: history, saved, cache or all"), nil
+		}
+
+		counts, err := t.stores.Purge(scope)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Purge failed: %v", err)), nil
+		}
+		return mcp.NewToolResultText(fmt.Sprintf("Purged %d history entries, %d saved results and %d cached responses.",
+			counts.History, counts.Saved, counts.Cache)), nil
+	}
+}
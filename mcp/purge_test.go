@@ -0,0 +1,95 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestDataStores_Purge(t *testing.T) {
+	var purged []string
+	stores := DataStores{
+		History: func() int { purged = append(purged, "history"); return 3 },
+		Saved:   func() (int, error) { purged = append(purged, "saved"); return 2, nil },
+		Cache:   func() int { purged = append(purged, "cache"); return 7 },
+	}
+
+	counts, err := stores.Purge(PurgeAll)
+	if err != nil {
+		t.Fatalf("Purge returned an error: %v", err)
+	}
+	if counts != (PurgeCounts{History: 3, Saved: 2, Cache: 7}) {
+		t.Errorf("Expected every store to be purged, got %+v", counts)
+	}
+
+	purged = nil
+	counts, err = stores.Purge(PurgeCache)
+	if err != nil {
+		t.Fatalf("Purge returned an error: %v", err)
+	}
+	if len(purged) != 1 || purged[0] != "cache" || counts.Cache != 7 || counts.History != 0 {
+		t.Errorf("Expected only the cache to be purged, got %v and %+v", purged, counts)
+	}
+
+	if _, err := stores.Purge("everything"); err == nil {
+		t.Error("Expected error for unknown scope, got nil")
+	}
+}
+
+func TestDataStores_PurgeSkipsMissingStores(t *testing.T) {
+	counts, err := DataStores{}.Purge(PurgeAll)
+	if err != nil {
+		t.Fatalf("Purge returned an error: %v", err)
+	}
+	if counts != (PurgeCounts{}) {
+		t.Errorf("Expected nothing purged, got %+v", counts)
+	}
+}
+
+func TestPurgeDataTool(t *testing.T) {
+	transcript := NewTranscript()
+	transcript.MarkChosen("https://example.com")
+	tool := NewPurgeDataTool(DataStores{
+		History: transcript.Purge,
+		Cache:   func() int { return 4 },
+	})
+
+	// Nothing is erased without a scope
+	request := mcp.CallToolRequest{}
+	result, err := tool.Handler()(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Handler returned an error: %v", err)
+	}
+	if !result.IsError || len(transcript.picked) != 1 {
+		t.Errorf("Expected an error and nothing purged without a scope, got %+v", result)
+	}
+
+	request.Params.Arguments = map[string]interface{}{"scope": PurgeAll}
+	result, err = tool.Handler()(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Handler returned an error: %v", err)
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+	if result.IsError || !strings.Contains(text, "4 cached responses") || len(transcript.picked) != 0 {
+		t.Errorf("Expected every store to be purged, got %q", text)
+	}
+}
+
+func TestPurgeDataTool_SavedError(t *testing.T) {
+	tool := NewPurgeDataTool(DataStores{
+		Saved: func() (int, error) { return 0, errors.New("read-only file system") },
+	})
+
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]interface{}{"scope": PurgeSaved}
+	result, err := tool.Handler()(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Handler returned an error: %v", err)
+	}
+	if !result.IsError {
+		t.Error("Expected an error result when saved results cannot be deleted")
+	}
+}
@@ -0,0 +1,62 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"com.moguyn/mcp-go-search/fetch"
+	"com.moguyn/mcp-go-search/search"
+)
+
+// QuotesURI identifies the structured quotes attached to search results
+const QuotesURI = "search://quotes"
+
+// Quotes are taken from the top quotePages results, at most maxQuotes at a time
+const (
+	quotePages = 3
+	maxQuotes  = 20
+)
+
+// findQuotes fetches the top results and returns the n sentences of their
+// readable text most relevant to the query. Pages that fail to fetch, are
+// gated or carry no text are left out, so the quotes may come from fewer pages.
+func (t *SearchTool) findQuotes(ctx context.Context, query string, results []search.WebPageResult, n int) []fetch.Quote {
+	var urls []string
+	for _, result := range results {
+		if result.URL != "" && len(urls) < quotePages {
+			urls = append(urls, result.URL)
+		}
+	}
+
+	// A page that fails to fetch is left out rather than failing the others
+	sources := make([]fetch.Source, len(urls))
+	group, _ := t.workers.Group(ctx)
+	for i, url := range urls {
+		group.Go(func(ctx context.Context) error {
+			page, err := t.fetcher.Fetch(ctx, url)
+			if err != nil || page.StatusCode != http.StatusOK || page.Skipped != "" || page.Gate != "" {
+				return nil
+			}
+			sources[i] = fetch.Source{URL: url, Text: fetch.Extract(page, fetch.FormatText).Text}
+			return nil
+		})
+	}
+	_ = group.Wait()
+	return fetch.RankQuotes(query, sources, n)
+}
+
+// writeQuotes renders quotes with their source and where they are in it
+func writeQuotes(b *strings.Builder, quotes []fetch.Quote) {
+	b.WriteString("Quotes:\n")
+	b.WriteString("=======\n\n")
+	if len(quotes) == 0 {
+		b.WriteString("No relevant sentences found in the top results.\n\n")
+		return
+	}
+	for i, quote := range quotes {
+		b.WriteString(fmt.Sprintf("%d. \"%s\"\n", i+1, quote.Text))
+		b.WriteString(fmt.Sprintf("   Source: %s (characters %d-%d)\n\n", quote.URL, quote.Start, quote.End))
+	}
+}
@@ -0,0 +1,90 @@
+package mcp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"com.moguyn/mcp-go-search/config"
+	"com.moguyn/mcp-go-search/fetch"
+	"com.moguyn/mcp-go-search/pool"
+	"com.moguyn/mcp-go-search/search"
+)
+
+func TestSearchTool_Quotes(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(`<html><body><nav>Home</nav><article><p>Go is a programming language.</p>
+<p>The Go garbage collector runs concurrently with the program.</p></article></body></html>`))
+	}))
+	defer server.Close()
+
+	fetcher, err := fetch.NewFetcher(&config.Config{
+		FetchTimeout:           5 * time.Second,
+		FetchMaxPagesPerMinute: 5,
+		FetchMaxBytesPerMinute: 4096,
+		FetchMaxPageBytes:      1024,
+		FetchAllowlist:         []string{"127.0.0.1"},
+	})
+	if err != nil {
+		t.Fatalf("NewFetcher returned an error: %v", err)
+	}
+	service := &MockSearchService{
+		SearchFunc: func(_ context.Context, _ string, _ string, _ int, _ bool) (*search.WebSearchResponse, error) {
+			response := &search.WebSearchResponse{}
+			response.Data.WebPages.Value = []search.WebPageResult{
+				{Name: "Missing", URL: server.URL + "/missing"},
+				{Name: "Article", URL: server.URL + "/article"},
+			}
+			return response, nil
+		},
+	}
+
+	tool := NewSearchTool(service)
+	if _, ok := tool.Definition().InputSchema.Properties["quotes"]; ok {
+		t.Error("Expected no quotes parameter without a fetcher")
+	}
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]interface{}{"query": "go garbage collector", "quotes": float64(1)}
+	if result, _ := tool.Handler()(context.Background(), request); !result.IsError {
+		t.Error("Expected an error for quotes without a fetcher")
+	}
+
+	tool.WithQuotes(fetcher, pool.New(2, 5*time.Second))
+	if _, ok := tool.Definition().InputSchema.Properties["quotes"]; !ok {
+		t.Error("Expected a quotes parameter with a fetcher")
+	}
+	result, err := tool.Handler()(context.Background(), request)
+	if err != nil || result.IsError {
+		t.Fatalf("Unexpected result: %v, %+v", err, result)
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+	expected := "Quotes:\n=======\n\n1. \"The Go garbage collector runs concurrently with the program.\"\n   Source: " + server.URL + "/article (characters 31-91)\n"
+	if !strings.Contains(text, expected) {
+		t.Errorf("Expected the best quote with its offsets, got %s", text)
+	}
+
+	var found bool
+	for _, content := range result.Content {
+		if resource, ok := content.(mcp.EmbeddedResource); ok && resource.Resource.(mcp.TextResourceContents).URI == QuotesURI {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("Expected the quotes to be attached")
+	}
+
+	request.Params.Arguments = map[string]interface{}{"query": "go", "quotes": float64(maxQuotes + 1)}
+	if result, _ := tool.Handler()(context.Background(), request); !result.IsError {
+		t.Error("Expected an error for too many quotes")
+	}
+}
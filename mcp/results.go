@@ -0,0 +1,92 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"com.moguyn/mcp-go-search/search"
+)
+
+// ResultsURIPrefix is the prefix of the URIs the raw result set of each
+// search is readable at, followed by the search's id in the history
+const ResultsURIPrefix = "search://results/"
+
+// Response returns the raw response of the search with the given id, if it is
+// still in the transcript
+func (t *Transcript) Response(id int) (*search.WebSearchResponse, bool) {
+	if t == nil {
+		return nil, false
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.expire()
+	for _, entry := range t.entries {
+		if entry.page == nil && entry.id == id {
+			return entry.response, true
+		}
+	}
+	return nil, false
+}
+
+// resultsNote points the client at the resource holding a search's result
+// set, or returns "" if the search wasn't recorded
+func resultsNote(id int) string {
+	if id == 0 {
+		return ""
+	}
+	return fmt.Sprintf("\nFull response (JSON): %s%d\n", ResultsURIPrefix, id)
+}
+
+// ResultsResource exposes the raw result set of every search in the
+// transcript as an MCP resource, so clients can re-read results without
+// searching again
+type ResultsResource struct {
+	transcript *Transcript
+}
+
+// NewResultsResource creates a new results resource backed by the transcript
+func NewResultsResource(transcript *Transcript) *ResultsResource {
+	return &ResultsResource{
+		transcript: transcript,
+	}
+}
+
+// Definition returns the MCP resource template definition
+func (r *ResultsResource) Definition() mcp.ResourceTemplate {
+	return mcp.NewResourceTemplate(ResultsURIPrefix+"{id}", "Search result set",
+		mcp.WithTemplateDescription("The raw response of a search from this session, as returned by the provider"),
+		mcp.WithTemplateMIMEType("application/json"),
+	)
+}
+
+// Handler returns the MCP resource handler function
+func (r *ResultsResource) Handler() func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	return func(_ context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		uri := request.Params.URI
+		raw, ok := strings.CutPrefix(uri, ResultsURIPrefix)
+		if !ok {
+			return nil, fmt.Errorf("unknown resource %q", uri)
+		}
+		id, err := strconv.Atoi(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid search id %q", raw)
+		}
+		response, ok := r.transcript.Response(id)
+		if !ok {
+			return nil, fmt.Errorf("results of search %d are no longer kept", id)
+		}
+
+		data, err := json.MarshalIndent(response, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode search results: %w", err)
+		}
+		return []mcp.ResourceContents{
+			mcp.TextResourceContents{URI: uri, MIMEType: "application/json", Text: string(data)},
+		}, nil
+	}
+}
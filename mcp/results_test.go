@@ -0,0 +1,77 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"com.moguyn/mcp-go-search/search"
+)
+
+func TestResultsResource(t *testing.T) {
+	calls := 0
+	service := &MockSearchService{
+		SearchFunc: func(_ context.Context, query string, _ string, _ int, _ bool) (*search.WebSearchResponse, error) {
+			calls++
+			response := &search.WebSearchResponse{Code: 200}
+			response.Data.WebPages.Value = []search.WebPageResult{{Name: "Result for " + query, URL: "https://example.com/" + query}}
+			return response, nil
+		},
+	}
+	transcript := NewTranscript()
+	tool := NewSearchTool(service).WithTranscript(transcript)
+
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]interface{}{"query": "golang"}
+	result, err := tool.Handler()(context.Background(), request)
+	if err != nil || result.IsError {
+		t.Fatalf("Unexpected result: %v, %+v", err, result)
+	}
+	if text := result.Content[0].(mcp.TextContent).Text; !strings.Contains(text, "\nFull response (JSON): search://results/1\n") {
+		t.Errorf("Expected a link to the result set, got:\n%s", text)
+	}
+	for _, content := range result.Content[1:] {
+		if _, ok := content.(mcp.EmbeddedResource); ok {
+			t.Errorf("Expected no embedded resources, got %+v", content)
+		}
+	}
+
+	resource := NewResultsResource(transcript)
+	if template := resource.Definition(); template.URITemplate != "search://results/{id}" {
+		t.Errorf("Unexpected URI template %s", template.URITemplate)
+	}
+	read := mcp.ReadResourceRequest{}
+	read.Params.URI = "search://results/1"
+	contents, err := resource.Handler()(context.Background(), read)
+	if err != nil {
+		t.Fatalf("Handler returned an error: %v", err)
+	}
+	text := contents[0].(mcp.TextResourceContents)
+	var response search.WebSearchResponse
+	if err := json.Unmarshal([]byte(text.Text), &response); err != nil {
+		t.Fatalf("Failed to decode result set: %v", err)
+	}
+	if text.MIMEType != "application/json" || response.Code != 200 || response.Data.WebPages.Value[0].Name != "Result for golang" {
+		t.Errorf("Unexpected result set: %s", text.Text)
+	}
+	if calls != 1 {
+		t.Errorf("Expected reading the result set not to search, got %d searches", calls)
+	}
+
+	for _, uri := range []string{"search://results/2", "search://results/x", "search://history/1"} {
+		read.Params.URI = uri
+		if _, err := resource.Handler()(context.Background(), read); err == nil {
+			t.Errorf("Expected an error reading %s", uri)
+		}
+	}
+
+	// Incognito searches are not kept, so there is nothing to link to
+	request.Params.Arguments = map[string]interface{}{"query": "private", "incognito": true}
+	result, _ = tool.Handler()(context.Background(), request)
+	if text := result.Content[0].(mcp.TextContent).Text; strings.Contains(text, "search://results/") {
+		t.Errorf("Expected no result set link for an incognito search, got:\n%s", text)
+	}
+}
@@ -0,0 +1,276 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"com.moguyn/mcp-go-search/store"
+)
+
+// SaveResultTool bookmarks a search result in the local store as an MCP tool
+type SaveResultTool struct {
+	saved      *store.Saved
+	transcript *Transcript
+}
+
+// NewSaveResultTool creates a new save tool backed by the store
+func NewSaveResultTool(saved *store.Saved) *SaveResultTool {
+	return &SaveResultTool{
+		saved: saved,
+	}
+}
+
+// WithTranscript fills in saved results from the session's searches and marks them as chosen
+func (t *SaveResultTool) WithTranscript(transcript *Transcript) *SaveResultTool {
+	t.transcript = transcript
+	return t
+}
+
+// Definition returns the MCP tool definition
+func (t *SaveResultTool) Definition() mcp.Tool {
+	return mcp.NewTool("save_result",
+		mcp.WithDescription("Save a search result for later, with optional tags and a note. Saved results persist across sessions; saving a URL again adds the tags and note to it."),
+		mcp.WithString("url",
+			mcp.Required(),
+			mcp.Description("The URL of the result to save"),
+		),
+		mcp.WithString("title",
+			mcp.Description("The result title; taken from this session's searches when omitted"),
+		),
+		mcp.WithString("tags",
+			mcp.Description("Comma-separated tags, e.g. \"golang, generics\""),
+		),
+		mcp.WithString("note",
+			mcp.Description("A note about why the result matters"),
+		),
+	)
+}
+
+// Handler returns the MCP tool handler function
+func (t *SaveResultTool) Handler() func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(_ context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := request.Params.Arguments
+		url, ok := args["url"].(string)
+		if !ok || strings.TrimSpace(url) == "" {
+			return mcp.NewToolResultError("url parameter is required and must be a string"), nil
+		}
+
+		result := store.Result{URL: strings.TrimSpace(url)}
+		result.Title, _ = args["title"].(string)
+		result.Tags = tagArgument(args["tags"])
+		if note, _ := args["note"].(string); note != "" {
+			result.Notes = []store.Note{{Text: note}}
+		}
+
+		// Fill in what the session already knows about the result
+		if found, query, ok := t.transcript.Lookup(result.URL); ok {
+			if result.Title == "" {
+				result.Title = found.Name
+			}
+			result.Snippet = found.Snippet
+			result.Query = query
+		}
+
+		saved, err := t.saved.Save(result)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Save failed: %v", err)), nil
+		}
+		t.transcript.MarkChosen(saved.URL)
+
+		return mcp.NewToolResultText(fmt.Sprintf("Saved result %s\n\n%s", saved.ID, formatSavedResult(saved))), nil
+	}
+}
+
+// ListSavedTool lists saved results as an MCP tool
+type ListSavedTool struct {
+	saved *store.Saved
+}
+
+// NewListSavedTool creates a new list tool backed by the store
+func NewListSavedTool(saved *store.Saved) *ListSavedTool {
+	return &ListSavedTool{
+		saved: saved,
+	}
+}
+
+// Definition returns the MCP tool definition
+func (t *ListSavedTool) Definition() mcp.Tool {
+	return mcp.NewTool("list_saved",
+		mcp.WithDescription("List saved search results, newest first"),
+		mcp.WithString("tag",
+			mcp.Description("Only list results with this tag"),
+		),
+	)
+}
+
+// Handler returns the MCP tool handler function
+func (t *ListSavedTool) Handler() func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(_ context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		tag, _ := request.Params.Arguments["tag"].(string)
+
+		results, err := t.saved.List(tag)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Listing saved results failed: %v", err)), nil
+		}
+		return mcp.NewToolResultText(formatSavedResults(results)), nil
+	}
+}
+
+// AnnotateSavedTool adds notes and tags to a saved result as an MCP tool
+type AnnotateSavedTool struct {
+	saved *store.Saved
+}
+
+// NewAnnotateSavedTool creates a new annotate tool backed by the store
+func NewAnnotateSavedTool(saved *store.Saved) *AnnotateSavedTool {
+	return &AnnotateSavedTool{
+		saved: saved,
+	}
+}
+
+// Definition returns the MCP tool definition
+func (t *AnnotateSavedTool) Definition() mcp.Tool {
+	return mcp.NewTool("annotate_saved",
+		mcp.WithDescription("Add a note to a saved result and add or remove its tags"),
+		mcp.WithString("id",
+			mcp.Required(),
+			mcp.Description("The id of the saved result, as shown by list_saved"),
+		),
+		mcp.WithString("note",
+			mcp.Description("A note to add"),
+		),
+		mcp.WithString("tags",
+			mcp.Description("Comma-separated tags to add"),
+		),
+		mcp.WithString("remove_tags",
+			mcp.Description("Comma-separated tags to remove"),
+		),
+	)
+}
+
+// Handler returns the MCP tool handler function
+func (t *AnnotateSavedTool) Handler() func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(_ context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := request.Params.Arguments
+		id, ok := args["id"].(string)
+		if !ok || id == "" {
+			return mcp.NewToolResultError("id parameter is required and must be a string"), nil
+		}
+
+		annotation := store.Annotation{
+			AddTags:    tagArgument(args["tags"]),
+			RemoveTags: tagArgument(args["remove_tags"]),
+		}
+		annotation.Note, _ = args["note"].(string)
+		if strings.TrimSpace(annotation.Note) == "" && len(annotation.AddTags) == 0 && len(annotation.RemoveTags) == 0 {
+			return mcp.NewToolResultError("at least one of note, tags or remove_tags is required"), nil
+		}
+
+		result, err := t.saved.Annotate(id, annotation)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Annotate failed: %v", err)), nil
+		}
+		return mcp.NewToolResultText(fmt.Sprintf("Updated result %s\n\n%s", result.ID, formatSavedResult(result))), nil
+	}
+}
+
+// SearchSavedTool searches saved results and their notes as an MCP tool
+type SearchSavedTool struct {
+	saved *store.Saved
+}
+
+// NewSearchSavedTool creates a new saved results search tool backed by the store
+func NewSearchSavedTool(saved *store.Saved) *SearchSavedTool {
+	return &SearchSavedTool{
+		saved: saved,
+	}
+}
+
+// Definition returns the MCP tool definition
+func (t *SearchSavedTool) Definition() mcp.Tool {
+	return mcp.NewTool("search_saved",
+		mcp.WithDescription("Search saved results by words in their title, URL, snippet, tags and notes"),
+		mcp.WithString("query",
+			mcp.Required(),
+			mcp.Description("Words that must all appear in a result"),
+		),
+		mcp.WithString("tag",
+			mcp.Description("Only search results with this tag"),
+		),
+	)
+}
+
+// Handler returns the MCP tool handler function
+func (t *SearchSavedTool) Handler() func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(_ context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		query, ok := request.Params.Arguments["query"].(string)
+		if !ok || strings.TrimSpace(query) == "" {
+			return mcp.NewToolResultError("query parameter is required and must be a string"), nil
+		}
+		tag, _ := request.Params.Arguments["tag"].(string)
+
+		results, err := t.saved.Search(query, tag)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Searching saved results failed: %v", err)), nil
+		}
+		return mcp.NewToolResultText(formatSavedResults(results)), nil
+	}
+}
+
+// tagArgument accepts tags as a comma-separated string or a list of strings
+func tagArgument(value interface{}) []string {
+	switch v := value.(type) {
+	case string:
+		return store.NormalizeTags(strings.Split(v, ","))
+	case []interface{}:
+		var tags []string
+		for _, item := range v {
+			if tag, ok := item.(string); ok {
+				tags = append(tags, tag)
+			}
+		}
+		return store.NormalizeTags(tags)
+	default:
+		return nil
+	}
+}
+
+// formatSavedResults renders saved results as the text returned to the client
+func formatSavedResults(results []store.Result) string {
+	if len(results) == 0 {
+		return "No saved results."
+	}
+
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("Saved Results: %d\n\n", len(results)))
+	for _, result := range results {
+		b.WriteString(formatSavedResult(result))
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// formatSavedResult renders a single saved result
+func formatSavedResult(result store.Result) string {
+	var b strings.Builder
+	title := result.Title
+	if title == "" {
+		title = result.URL
+	}
+	b.WriteString(fmt.Sprintf("[%s] %s\n", result.ID, title))
+	b.WriteString(fmt.Sprintf("   URL: %s\n", result.URL))
+	if len(result.Tags) > 0 {
+		b.WriteString(fmt.Sprintf("   Tags: %s\n", strings.Join(result.Tags, ", ")))
+	}
+	for _, note := range result.Notes {
+		b.WriteString(fmt.Sprintf("   Note (%s): %s\n", note.AddedAt.Format("January 2, 2006"), note.Text))
+	}
+	if result.Query != "" {
+		b.WriteString(fmt.Sprintf("   Found by: %s\n", result.Query))
+	}
+	b.WriteString(fmt.Sprintf("   Saved: %s\n", result.SavedAt.Format("January 2, 2006")))
+	return b.String()
+}
@@ -0,0 +1,118 @@
+package mcp
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"com.moguyn/mcp-go-search/params"
+	"com.moguyn/mcp-go-search/search"
+	"com.moguyn/mcp-go-search/store"
+)
+
+// callTool invokes a tool handler with the given arguments and returns its text
+func callTool(t *testing.T, tool ToolProvider, args map[string]interface{}) (string, bool) {
+	t.Helper()
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = args
+	result, err := tool.Handler()(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Handler returned an error: %v", err)
+	}
+	text, ok := result.Content[0].(mcp.TextContent)
+	if !ok {
+		t.Fatalf("Expected text content, got %T", result.Content[0])
+	}
+	return text.Text, result.IsError
+}
+
+func TestSavedResultTools(t *testing.T) {
+	saved, err := store.OpenSaved(t.TempDir())
+	if err != nil {
+		t.Fatalf("OpenSaved returned an error: %v", err)
+	}
+	transcript := NewTranscript()
+	transcript.RecordSearch(context.Background(), params.Search{Query: "go generics"}, &search.WebSearchResponse{Data: search.Data{WebPages: search.WebPages{
+		Value: []search.WebPageResult{{Name: "Generics tutorial", URL: "https://go.dev/doc/tutorial/generics", Snippet: "Learn generics"}},
+	}}})
+	save := NewSaveResultTool(saved).WithTranscript(transcript)
+	list := NewListSavedTool(saved)
+
+	text, isError := callTool(t, save, map[string]interface{}{
+		"url":  "https://go.dev/doc/tutorial/generics",
+		"tags": "Go, generics",
+		"note": "Best intro",
+	})
+	if isError {
+		t.Fatalf("Expected save to succeed, got %s", text)
+	}
+	for _, want := range []string{"Saved result 1", "[1] Generics tutorial", "Tags: go, generics", "): Best intro", "Found by: go generics"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Expected save output to contain %q, got:\n%s", want, text)
+		}
+	}
+	if !strings.Contains(transcript.Markdown(), "**(chosen)**") {
+		t.Error("Expected the saved result to be marked as chosen in the transcript")
+	}
+
+	if _, isError := callTool(t, save, map[string]interface{}{"url": "https://example.com", "tags": []interface{}{"misc"}}); isError {
+		t.Fatal("Expected save to succeed")
+	}
+
+	text, _ = callTool(t, list, map[string]interface{}{})
+	if !strings.HasPrefix(text, "Saved Results: 2") {
+		t.Errorf("Expected 2 saved results, got:\n%s", text)
+	}
+	text, _ = callTool(t, list, map[string]interface{}{"tag": "generics"})
+	if !strings.HasPrefix(text, "Saved Results: 1") || !strings.Contains(text, "go.dev") {
+		t.Errorf("Expected 1 result tagged generics, got:\n%s", text)
+	}
+	text, _ = callTool(t, list, map[string]interface{}{"tag": "unknown"})
+	if text != "No saved results." {
+		t.Errorf("Expected no results, got:\n%s", text)
+	}
+
+	if _, isError := callTool(t, save, map[string]interface{}{}); !isError {
+		t.Error("Expected an error without a url")
+	}
+}
+
+func TestAnnotateAndSearchSavedTools(t *testing.T) {
+	saved, err := store.OpenSaved(t.TempDir())
+	if err != nil {
+		t.Fatalf("OpenSaved returned an error: %v", err)
+	}
+	_, _ = saved.Save(store.Result{URL: "https://go.dev/blog/intro-generics", Title: "An Introduction To Generics"})
+	_, _ = saved.Save(store.Result{URL: "https://example.com/rust", Title: "Rust traits"})
+	annotate := NewAnnotateSavedTool(saved)
+	searchSaved := NewSearchSavedTool(saved)
+
+	text, isError := callTool(t, annotate, map[string]interface{}{
+		"id":   "2",
+		"note": "Traits play the role of generic constraints",
+		"tags": "compare",
+	})
+	if isError || !strings.Contains(text, "Tags: compare") || !strings.Contains(text, "): Traits play the role") {
+		t.Errorf("Expected the annotation to be applied, got:\n%s", text)
+	}
+	if _, isError := callTool(t, annotate, map[string]interface{}{"id": "2"}); !isError {
+		t.Error("Expected an error without a note or tags")
+	}
+	if text, isError := callTool(t, annotate, map[string]interface{}{"id": "9", "note": "x"}); !isError || !strings.Contains(text, "no saved result") {
+		t.Errorf("Expected an error for an unknown id, got %s", text)
+	}
+
+	text, _ = callTool(t, searchSaved, map[string]interface{}{"query": "generic"})
+	if !strings.HasPrefix(text, "Saved Results: 2") || strings.Index(text, "[1]") > strings.Index(text, "[2]") {
+		t.Errorf("Expected both results, best match first, got:\n%s", text)
+	}
+	text, _ = callTool(t, searchSaved, map[string]interface{}{"query": "generic", "tag": "compare"})
+	if !strings.HasPrefix(text, "Saved Results: 1") || !strings.Contains(text, "Rust traits") {
+		t.Errorf("Expected the tagged result, got:\n%s", text)
+	}
+	if _, isError := callTool(t, searchSaved, map[string]interface{}{}); !isError {
+		t.Error("Expected an error without a query")
+	}
+}
@@ -0,0 +1,106 @@
+package mcp
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"com.moguyn/mcp-go-search/pool"
+	"com.moguyn/mcp-go-search/screenshot"
+)
+
+// ScreenshotTool captures an image of a web page as an MCP tool
+type ScreenshotTool struct {
+	client  *screenshot.Client
+	workers *pool.Pool
+}
+
+// NewScreenshotTool creates a new screenshot tool with the provided client
+func NewScreenshotTool(client *screenshot.Client) *ScreenshotTool {
+	return &ScreenshotTool{
+		client: client,
+	}
+}
+
+// WithPool runs captures on a shared worker pool, bounding how many run at once
+func (t *ScreenshotTool) WithPool(workers *pool.Pool) *ScreenshotTool {
+	t.workers = workers
+	return t
+}
+
+// Definition returns the MCP tool definition
+func (t *ScreenshotTool) Definition() mcp.Tool {
+	return mcp.NewTool("screenshot",
+		mcp.WithDescription("Capture a screenshot of a web page, such as a search result URL, to check a source visually"),
+		mcp.WithString("url",
+			mcp.Required(),
+			mcp.Description("The http or https URL of the page"),
+		),
+		mcp.WithNumber("width",
+			mcp.Description(fmt.Sprintf("Viewport width in pixels (default %d, at most %d)", screenshot.DefaultWidth, screenshot.MaxWidth)),
+		),
+		mcp.WithNumber("height",
+			mcp.Description(fmt.Sprintf("Viewport height in pixels (default %d, at most %d)", screenshot.DefaultHeight, screenshot.MaxHeight)),
+		),
+		mcp.WithBoolean("full_page",
+			mcp.Description("Capture the whole page rather than only the viewport"),
+		),
+	)
+}
+
+// Handler returns the MCP tool handler function
+func (t *ScreenshotTool) Handler() func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ctx, cancel := context.WithTimeout(ctx, 60*time.Second)
+		defer cancel()
+
+		args := request.Params.Arguments
+		rawURL, ok := args["url"].(string)
+		if !ok || rawURL == "" {
+			return mcp.NewToolResultError("url parameter is required and must be a string"), nil
+		}
+		r := screenshot.Request{URL: rawURL}
+		for _, arg := range []struct {
+			name  string
+			value *int
+		}{
+			{"width", &r.Width},
+			{"height", &r.Height},
+		} {
+			raw, ok := args[arg.name]
+			if !ok {
+				continue
+			}
+			n, ok := raw.(float64)
+			if !ok || n < 1 || n != math.Trunc(n) {
+				return mcp.NewToolResultError(fmt.Sprintf("%s must be a positive integer", arg.name)), nil
+			}
+			*arg.value = int(math.Min(n, math.MaxInt32))
+		}
+		r.FullPage, _ = args["full_page"].(bool)
+
+		var image *screenshot.Image
+		err := t.workers.Do(ctx, func(ctx context.Context) error {
+			var err error
+			image, err = t.client.Capture(ctx, r)
+			return err
+		})
+		if err != nil {
+			if ctx.Err() == context.DeadlineExceeded {
+				return mcp.NewToolResultError("Screenshot timed out after 60 seconds"), nil
+			}
+			return mcp.NewToolResultError(fmt.Sprintf("Screenshot failed: %v", err)), nil
+		}
+
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.NewTextContent(fmt.Sprintf("Screenshot of %s (%s, %d bytes)", image.URL, image.MIMEType, len(image.Data))),
+				mcp.NewImageContent(base64.StdEncoding.EncodeToString(image.Data), image.MIMEType),
+			},
+		}, nil
+	}
+}
@@ -0,0 +1,62 @@
+package mcp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"com.moguyn/mcp-go-search/config"
+	"com.moguyn/mcp-go-search/pool"
+	"com.moguyn/mcp-go-search/screenshot"
+)
+
+func TestScreenshotTool(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "image/jpeg")
+		_, _ = w.Write([]byte{0xff, 0xd8, 0xff})
+	}))
+	defer server.Close()
+
+	client, err := screenshot.NewClient(&config.Config{
+		ScreenshotURL:      server.URL,
+		ScreenshotTimeout:  5 * time.Second,
+		ScreenshotMaxBytes: 1024,
+	})
+	if err != nil {
+		t.Fatalf("NewClient returned an error: %v", err)
+	}
+	tool := NewScreenshotTool(client).WithPool(pool.New(1, 5*time.Second))
+	if tool.Definition().Name != "screenshot" {
+		t.Errorf("Expected tool name 'screenshot', got '%s'", tool.Definition().Name)
+	}
+
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]interface{}{"url": "https://93.184.216.34/", "width": float64(800)}
+	result, err := tool.Handler()(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Handler returned an error: %v", err)
+	}
+	if result.IsError || len(result.Content) != 2 {
+		t.Fatalf("Unexpected result: %+v", result.Content)
+	}
+	image, ok := result.Content[1].(mcp.ImageContent)
+	if !ok || image.MIMEType != "image/jpeg" || image.Data != "/9j/" {
+		t.Errorf("Unexpected image content: %+v", result.Content[1])
+	}
+
+	for _, args := range []map[string]interface{}{
+		{},
+		{"url": "https://93.184.216.34/", "height": float64(-1)},
+		{"url": "http://localhost/"},
+	} {
+		request.Params.Arguments = args
+		result, _ = tool.Handler()(context.Background(), request)
+		if !result.IsError {
+			t.Errorf("Expected an error for %v", args)
+		}
+	}
+}
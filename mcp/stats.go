@@ -0,0 +1,45 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"com.moguyn/mcp-go-search/stats"
+)
+
+// StatsTool reports upstream usage statistics as an MCP tool
+type StatsTool struct {
+	collector *stats.Collector
+}
+
+// NewStatsTool creates a new stats tool backed by the collector
+func NewStatsTool(collector *stats.Collector) *StatsTool {
+	return &StatsTool{
+		collector: collector,
+	}
+}
+
+// Definition returns the MCP tool definition
+func (t *StatsTool) Definition() mcp.Tool {
+	return mcp.NewTool("stats",
+		mcp.WithDescription("Report search usage statistics: query and error counts, latency, bytes exchanged with the upstream API, average results per query, how often queries were truncated or counts clamped, and a scoreboard rating each provider's health and usefulness"),
+	)
+}
+
+// Handler returns the MCP tool handler function
+func (t *StatsTool) Handler() func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(_ context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		snapshot := t.collector.Snapshot()
+		// Recent queries are only shown on the admin dashboard
+		snapshot.Recent = nil
+
+		data, err := json.MarshalIndent(snapshot, "", "  ")
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to encode stats: %v", err)), nil
+		}
+		return mcp.NewToolResultText(string(data)), nil
+	}
+}
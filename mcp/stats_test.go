@@ -0,0 +1,42 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"com.moguyn/mcp-go-search/stats"
+)
+
+func TestStatsTool(t *testing.T) {
+	collector := stats.NewCollector()
+	collector.Record(stats.Call{Provider: "bocha", Query: "private", Latency: 20 * time.Millisecond, BytesSent: 64, BytesReceived: 2048, Results: 8, CountClamped: true})
+
+	tool := NewStatsTool(collector)
+	if tool.Definition().Name != "stats" {
+		t.Errorf("Expected tool name 'stats', got '%s'", tool.Definition().Name)
+	}
+
+	result, err := tool.Handler()(context.Background(), mcp.CallToolRequest{})
+	if err != nil {
+		t.Fatalf("Handler returned an error: %v", err)
+	}
+	if result.IsError {
+		t.Fatal("Expected IsError to be false")
+	}
+
+	text := result.Content[0].(mcp.TextContent).Text
+	var snapshot stats.Snapshot
+	if err := json.Unmarshal([]byte(text), &snapshot); err != nil {
+		t.Fatalf("Expected JSON output, got error: %v", err)
+	}
+	if snapshot.BytesReceived != 2048 || snapshot.AverageResults != 8 || snapshot.CountClamps != 1 {
+		t.Errorf("Unexpected snapshot: %+v", snapshot)
+	}
+	if len(snapshot.Recent) != 0 {
+		t.Error("Expected recent queries to be omitted from the stats tool")
+	}
+}
@@ -0,0 +1,42 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Versions of the structured output schema. The version changes whenever a
+// field of the attached data is renamed or removed, or its meaning changes.
+const (
+	// SchemaVersionLegacy attaches data bare, as before the schema was versioned
+	SchemaVersionLegacy = 1
+	// SchemaVersion is the current version, which wraps data in a
+	// structuredEnvelope naming the version
+	SchemaVersion = 2
+)
+
+// structuredEnvelope wraps attached data with the version of its schema, so
+// clients can tell which fields to expect
+type structuredEnvelope struct {
+	SchemaVersion int         `json:"schema_version"`
+	Data          interface{} `json:"data"`
+}
+
+// structuredContent returns v as an embedded JSON resource so clients can use
+// it without parsing the text, in the given version of the schema
+func structuredContent(uri string, v interface{}, version int) (mcp.Content, error) {
+	if version != SchemaVersionLegacy {
+		v = structuredEnvelope{SchemaVersion: version, Data: v}
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s: %w", uri, err)
+	}
+	return mcp.NewEmbeddedResource(mcp.TextResourceContents{
+		URI:      uri,
+		MIMEType: "application/json",
+		Text:     string(raw),
+	}), nil
+}
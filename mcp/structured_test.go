@@ -0,0 +1,31 @@
+package mcp
+
+import (
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestStructuredContent(t *testing.T) {
+	tests := []struct {
+		version  int
+		expected string
+	}{
+		{SchemaVersion, `{"schema_version":2,"data":["a","b"]}`},
+		{SchemaVersionLegacy, `["a","b"]`},
+	}
+	for _, tt := range tests {
+		content, err := structuredContent(PeopleAlsoAskURI, []string{"a", "b"}, tt.version)
+		if err != nil {
+			t.Fatalf("structuredContent returned an error: %v", err)
+		}
+		contents := content.(mcp.EmbeddedResource).Resource.(mcp.TextResourceContents)
+		if contents.Text != tt.expected {
+			t.Errorf("Expected %s for version %d, got %s", tt.expected, tt.version, contents.Text)
+		}
+	}
+
+	if _, err := structuredContent(EntityURI, func() {}, SchemaVersion); err == nil {
+		t.Error("Expected error for a value that cannot be encoded, got nil")
+	}
+}
@@ -0,0 +1,151 @@
+package mcp
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+	"unicode"
+
+	"com.moguyn/mcp-go-search/search"
+)
+
+// maxSuggestions caps the follow-up queries appended to search results
+const maxSuggestions = 5
+
+// maxSuggestionTermLength skips runs of text too long to be a single word,
+// such as unsegmented CJK sentences
+const maxSuggestionTermLength = 20
+
+// suggestionStopWords are common words never suggested as refinements
+var suggestionStopWords = map[string]bool{
+	"about": true, "after": true, "all": true, "also": true, "and": true,
+	"are": true, "been": true, "but": true, "can": true, "for": true,
+	"from": true, "has": true, "have": true, "how": true, "into": true,
+	"its": true, "more": true, "new": true, "not": true, "one": true,
+	"our": true, "out": true, "the": true, "their": true, "this": true,
+	"that": true, "was": true, "what": true, "when": true, "which": true,
+	"who": true, "why": true, "will": true, "with": true, "you": true,
+	"your": true, "com": true, "www": true, "http": true, "https": true,
+}
+
+// suggestQueries derives follow-up queries from a search and its results:
+// the query narrowed by terms that recur across results, restricted to or
+// excluding the dominant site, and as an exact phrase. Suggestions using
+// operators the provider doesn't support are left out.
+func suggestQueries(query string, results []search.WebPageResult, caps search.Capabilities) []string {
+	query = strings.TrimSpace(query)
+	if query == "" || len(results) == 0 {
+		return nil
+	}
+
+	var suggestions []string
+	seen := map[string]bool{query: true}
+	add := func(suggestion string) {
+		if len(suggestions) < maxSuggestions && !seen[suggestion] {
+			seen[suggestion] = true
+			suggestions = append(suggestions, suggestion)
+		}
+	}
+
+	for _, term := range recurringTerms(query, results, 2) {
+		add(query + " " + term)
+	}
+
+	hasSite := strings.Contains(strings.ToLower(query), search.OperatorSite)
+	if domain := dominantDomain(results); domain != "" && !hasSite && caps.SupportsOperator(search.OperatorSite) {
+		add(query + " " + search.OperatorSite + domain)
+		if caps.SupportsOperator(search.OperatorExclude) {
+			add(query + " " + search.OperatorExclude + search.OperatorSite + domain)
+		}
+	}
+
+	if len(strings.Fields(query)) > 1 && !strings.Contains(query, `"`) && caps.SupportsOperator(search.OperatorPhrase) {
+		add(`"` + query + `"`)
+	}
+
+	return suggestions
+}
+
+// recurringTerms returns up to n words that appear in the titles and snippets
+// of at least two results but not in the query, most widespread first
+func recurringTerms(query string, results []search.WebPageResult, n int) []string {
+	inQuery := make(map[string]bool)
+	for _, word := range suggestionWords(query) {
+		inQuery[word] = true
+	}
+
+	frequency := make(map[string]int)
+	for _, result := range results {
+		inResult := make(map[string]bool)
+		for _, word := range suggestionWords(result.Name + " " + result.Snippet) {
+			if inQuery[word] || inResult[word] {
+				continue
+			}
+			inResult[word] = true
+			frequency[word]++
+		}
+	}
+
+	var terms []string
+	for term, count := range frequency {
+		if count >= 2 {
+			terms = append(terms, term)
+		}
+	}
+	sort.Slice(terms, func(i, j int) bool {
+		if frequency[terms[i]] != frequency[terms[j]] {
+			return frequency[terms[i]] > frequency[terms[j]]
+		}
+		return terms[i] < terms[j]
+	})
+	if len(terms) > n {
+		terms = terms[:n]
+	}
+	return terms
+}
+
+// suggestionWords splits text into lower-case words worth suggesting
+func suggestionWords(text string) []string {
+	var words []string
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		length := len([]rune(word))
+		if length < 3 || length > maxSuggestionTermLength || suggestionStopWords[word] || isNumber(word) {
+			continue
+		}
+		words = append(words, word)
+	}
+	return words
+}
+
+// isNumber reports whether word consists of digits only
+func isNumber(word string) bool {
+	for _, r := range word {
+		if !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// dominantDomain returns the host that at least two results come from, or ""
+func dominantDomain(results []search.WebPageResult) string {
+	counts := make(map[string]int)
+	best := ""
+	for _, result := range results {
+		u, err := url.Parse(result.URL)
+		if err != nil || u.Hostname() == "" {
+			continue
+		}
+		host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+		counts[host]++
+		if counts[host] > counts[best] || (counts[host] == counts[best] && host < best) {
+			best = host
+		}
+	}
+	if counts[best] < 2 {
+		return ""
+	}
+	return best
+}
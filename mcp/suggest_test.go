@@ -0,0 +1,54 @@
+package mcp
+
+import (
+	"strings"
+	"testing"
+
+	"com.moguyn/mcp-go-search/search"
+)
+
+func suggestionResults() []search.WebPageResult {
+	return []search.WebPageResult{
+		{Name: "Tutorial: Getting started with generics", URL: "https://go.dev/doc/tutorial/generics", Snippet: "Type parameters in Go 1.18"},
+		{Name: "An Introduction To Generics", URL: "https://go.dev/blog/intro-generics", Snippet: "Type parameters and constraints"},
+		{Name: "Generics in Go explained", URL: "https://www.example.com/generics", Snippet: "How type parameters work"},
+	}
+}
+
+func TestSuggestQueries(t *testing.T) {
+	suggestions := suggestQueries("golang generics", suggestionResults(), search.DefaultCapabilities("test"))
+	expected := []string{
+		"golang generics parameters",
+		"golang generics type",
+		"golang generics site:go.dev",
+		"golang generics -site:go.dev",
+		`"golang generics"`,
+	}
+	if strings.Join(suggestions, "|") != strings.Join(expected, "|") {
+		t.Errorf("Expected %q, got %q", expected, suggestions)
+	}
+}
+
+func TestSuggestQueries_RespectsCapabilities(t *testing.T) {
+	caps := search.Capabilities{Provider: "test"}
+	suggestions := suggestQueries("golang generics", suggestionResults(), caps)
+	for _, suggestion := range suggestions {
+		if strings.Contains(suggestion, "site:") || strings.Contains(suggestion, `"`) {
+			t.Errorf("Expected no operators for a provider without them, got %q", suggestion)
+		}
+	}
+	if len(suggestions) != 2 {
+		t.Errorf("Expected 2 term suggestions, got %q", suggestions)
+	}
+
+	// Queries already restricted to a site are not restricted again
+	for _, suggestion := range suggestQueries("generics site:go.dev", suggestionResults(), search.DefaultCapabilities("test")) {
+		if strings.Count(suggestion, "site:") > 1 {
+			t.Errorf("Expected a single site: filter, got %q", suggestion)
+		}
+	}
+
+	if suggestions := suggestQueries("golang", nil, caps); suggestions != nil {
+		t.Errorf("Expected no suggestions without results, got %q", suggestions)
+	}
+}
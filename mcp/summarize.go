@@ -0,0 +1,201 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"com.moguyn/mcp-go-search/fetch"
+	"com.moguyn/mcp-go-search/pool"
+	"com.moguyn/mcp-go-search/search"
+)
+
+// maxSummarizeURLs bounds the URLs of one summarize_url call
+const maxSummarizeURLs = 5
+
+// Where the text returned for a URL came from
+const (
+	// SummarySourceProvider is a summary written by the search provider
+	SummarySourceProvider = "provider summary"
+	// SummarySourcePage is the page's own readable text, passed through
+	SummarySourcePage = "page text"
+)
+
+// SummarizeTool summarizes specific pages as an MCP tool, with the search
+// provider's summary of each page or, failing that, the page's own text
+type SummarizeTool struct {
+	searchService search.Service
+	fetcher       *fetch.Fetcher
+	maxChars      int
+	workers       *pool.Pool
+}
+
+// NewSummarizeTool creates a new summarize tool with the provided search service
+func NewSummarizeTool(searchService search.Service) *SummarizeTool {
+	return &SummarizeTool{
+		searchService: searchService,
+	}
+}
+
+// WithFetcher passes through the readable text of pages the provider has no
+// summary of, cut to maxChars characters
+func (t *SummarizeTool) WithFetcher(fetcher *fetch.Fetcher, maxChars int) *SummarizeTool {
+	t.fetcher = fetcher
+	t.maxChars = maxChars
+	return t
+}
+
+// WithPool runs the lookups on a shared worker pool, bounding how many run at once
+func (t *SummarizeTool) WithPool(workers *pool.Pool) *SummarizeTool {
+	t.workers = workers
+	return t
+}
+
+// Definition returns the MCP tool definition
+func (t *SummarizeTool) Definition() mcp.Tool {
+	return mcp.NewTool("summarize_url",
+		mcp.WithDescription("Summarize specific pages, such as search hits: returns the search provider's summary of each page, or the page's readable text when the provider has none"),
+		withRequiredStringArray("urls", fmt.Sprintf("The http or https URLs to summarize (1-%d)", maxSummarizeURLs), maxSummarizeURLs),
+	)
+}
+
+// pageSummary is the text found for one URL
+type pageSummary struct {
+	url       string
+	title     string
+	source    string
+	text      string
+	truncated bool
+	err       error
+}
+
+// Handler returns the MCP tool handler function
+func (t *SummarizeTool) Handler() func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ctx, cancel := context.WithTimeout(ctx, 60*time.Second)
+		defer cancel()
+
+		list, ok := request.Params.Arguments["urls"].([]interface{})
+		if !ok || len(list) == 0 {
+			return mcp.NewToolResultError("urls parameter is required and must be a non-empty array of strings"), nil
+		}
+		if len(list) > maxSummarizeURLs {
+			return mcp.NewToolResultError(fmt.Sprintf("too many urls (maximum %d)", maxSummarizeURLs)), nil
+		}
+		summaries := make([]pageSummary, len(list))
+		for i, item := range list {
+			rawURL, _ := item.(string)
+			u, err := url.Parse(strings.TrimSpace(rawURL))
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				return mcp.NewToolResultError(fmt.Sprintf("url %d must be an http or https URL", i+1)), nil
+			}
+			summaries[i].url = u.String()
+		}
+
+		// A URL that can't be summarized is reported in its place rather than
+		// failing the others
+		group, _ := t.workers.Group(ctx)
+		for i := range summaries {
+			group.Go(func(ctx context.Context) error {
+				t.summarize(ctx, &summaries[i])
+				return nil
+			})
+		}
+		_ = group.Wait()
+
+		var b strings.Builder
+		failed := 0
+		for _, s := range summaries {
+			b.WriteString(fmt.Sprintf("## %s\n\n", s.url))
+			if s.err != nil {
+				failed++
+				b.WriteString(fmt.Sprintf("Summary failed: %v\n\n", s.err))
+				continue
+			}
+			if s.title != "" {
+				b.WriteString(fmt.Sprintf("Title: %s\n", s.title))
+			}
+			b.WriteString(fmt.Sprintf("Source: %s\n", s.source))
+			if s.truncated {
+				b.WriteString("Truncated: yes\n")
+			}
+			b.WriteString("\n")
+			b.WriteString(s.text)
+			b.WriteString("\n\n")
+		}
+		if failed == len(summaries) {
+			return mcp.NewToolResultError(b.String()), nil
+		}
+		return mcp.NewToolResultText(b.String()), nil
+	}
+}
+
+// summarize fills in the provider's summary of a page, found by searching for
+// its URL, or else the page's readable text
+func (t *SummarizeTool) summarize(ctx context.Context, s *pageSummary) {
+	if search.CapabilitiesOf(t.searchService).Summaries {
+		response, err := t.searchService.Search(ctx, s.url, "", 10, true)
+		if err == nil {
+			for _, result := range response.Data.WebPages.Value {
+				if samePage(result.URL, s.url) && strings.TrimSpace(result.Summary) != "" {
+					s.title = result.Name
+					s.source = SummarySourceProvider
+					s.text = strings.TrimSpace(result.Summary)
+					return
+				}
+			}
+		} else if t.fetcher == nil {
+			s.err = fmt.Errorf("search failed: %s", sanitizeErrorMessage(err.Error()))
+			return
+		}
+	}
+	if t.fetcher == nil {
+		s.err = fmt.Errorf("the search provider has no summary of this page")
+		return
+	}
+
+	page, err := t.fetcher.Fetch(ctx, s.url)
+	if err != nil {
+		s.err = fmt.Errorf("fetch failed: %v", err)
+		return
+	}
+	switch {
+	case page.Skipped != "":
+		s.err = fmt.Errorf("page skipped: %s", page.Skipped)
+		return
+	case page.Gate != "":
+		s.err = fmt.Errorf("page is behind a %s wall", page.Gate)
+		return
+	case page.StatusCode != http.StatusOK:
+		s.err = fmt.Errorf("page returned status %d", page.StatusCode)
+		return
+	}
+	article := fetch.Extract(page, fetch.FormatText)
+	s.title = article.Title
+	s.source = SummarySourcePage
+	s.text, s.truncated = fetch.Cut(article.Text, t.maxChars)
+	s.truncated = s.truncated || page.Truncated
+}
+
+// samePage reports whether two URLs address the same page, ignoring the
+// scheme, a leading www. and a trailing slash
+func samePage(a, b string) bool {
+	normalize := func(raw string) string {
+		u, err := url.Parse(strings.TrimSpace(raw))
+		if err != nil {
+			return raw
+		}
+		host := strings.TrimPrefix(strings.ToLower(u.Host), "www.")
+		path := strings.TrimSuffix(u.EscapedPath(), "/")
+		if u.RawQuery != "" {
+			path += "?" + u.RawQuery
+		}
+		return host + path
+	}
+	return normalize(a) == normalize(b)
+}
@@ -0,0 +1,101 @@
+package mcp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"com.moguyn/mcp-go-search/config"
+	"com.moguyn/mcp-go-search/fetch"
+	"com.moguyn/mcp-go-search/pool"
+	"com.moguyn/mcp-go-search/search"
+)
+
+func TestSummarizeTool(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(`<html><head><title>Local page</title></head><body><nav>Menu</nav><main><p>The page's own text.</p></main></body></html>`))
+	}))
+	defer server.Close()
+
+	fetcher, err := fetch.NewFetcher(&config.Config{
+		FetchTimeout:           5 * time.Second,
+		FetchMaxPagesPerMinute: 5,
+		FetchMaxBytesPerMinute: 4096,
+		FetchMaxPageBytes:      1024,
+		FetchAllowlist:         []string{"127.0.0.1"},
+	})
+	if err != nil {
+		t.Fatalf("NewFetcher returned an error: %v", err)
+	}
+	service := &MockSearchService{
+		SearchFunc: func(_ context.Context, query string, _ string, _ int, summary bool) (*search.WebSearchResponse, error) {
+			if !summary {
+				t.Error("Expected summaries to be asked for")
+			}
+			response := &search.WebSearchResponse{}
+			response.Data.WebPages.Value = []search.WebPageResult{
+				{Name: "Other page", URL: "https://example.com/other", Summary: "Not this one."},
+				{Name: "Article", URL: "https://www.example.com/article/", Summary: "  A summary of the article.  "},
+			}
+			return response, nil
+		},
+	}
+	tool := NewSummarizeTool(service).WithFetcher(fetcher, 1000).WithPool(pool.New(2, 5*time.Second))
+	if tool.Definition().Name != "summarize_url" {
+		t.Errorf("Expected tool name 'summarize_url', got '%s'", tool.Definition().Name)
+	}
+
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]interface{}{"urls": []interface{}{
+		"https://example.com/article",
+		server.URL + "/page",
+		server.URL + "/missing",
+	}}
+	result, err := tool.Handler()(context.Background(), request)
+	if err != nil || result.IsError {
+		t.Fatalf("Unexpected result: %v, %+v", err, result)
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+	expected := []string{
+		"## https://example.com/article\n\nTitle: Article\nSource: provider summary\n\nA summary of the article.\n\n",
+		"## " + server.URL + "/page\n\nTitle: Local page\nSource: page text\n\nThe page's own text.\n\n",
+		"## " + server.URL + "/missing\n\nSummary failed: page returned status 404\n\n",
+	}
+	for _, part := range expected {
+		if !strings.Contains(text, part) {
+			t.Errorf("Expected %q in:\n%s", part, text)
+		}
+	}
+
+	request.Params.Arguments = map[string]interface{}{"urls": []interface{}{"ftp://example.com/file"}}
+	if result, _ := tool.Handler()(context.Background(), request); !result.IsError {
+		t.Error("Expected an error for a URL that is not http or https")
+	}
+}
+
+func TestSamePage(t *testing.T) {
+	testCases := []struct {
+		a, b     string
+		expected bool
+	}{
+		{"https://www.example.com/a/", "http://example.com/a", true},
+		{"https://example.com/a?x=1", "https://example.com/a?x=1", true},
+		{"https://example.com/a?x=1", "https://example.com/a", false},
+		{"https://example.com/a", "https://example.org/a", false},
+	}
+	for _, tc := range testCases {
+		if got := samePage(tc.a, tc.b); got != tc.expected {
+			t.Errorf("samePage(%q, %q) = %v, expected %v", tc.a, tc.b, got, tc.expected)
+		}
+	}
+}
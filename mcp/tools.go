@@ -3,45 +3,318 @@ package mcp
 import (
 	"context"
 	"fmt"
+	"math"
+	"slices"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
 
+	"com.moguyn/mcp-go-search/fetch"
+	"com.moguyn/mcp-go-search/params"
+	"com.moguyn/mcp-go-search/pool"
 	"com.moguyn/mcp-go-search/search"
 )
 
+// Values of the match_count parameter
+const (
+	// MatchCountNone leaves out the total number of matching pages
+	MatchCountNone = "none"
+	// MatchCountEstimated shows the provider's estimate of the total
+	MatchCountEstimated = "estimated"
+	// MatchCountExact asks the provider for an exact total and shows it
+	MatchCountExact = "exact"
+)
+
+// Values of the search tool's format argument
+const (
+	// FormatText renders each result as an indented block (the default)
+	FormatText = "text"
+	// FormatTable renders web results as a markdown table
+	FormatTable = "table"
+)
+
+// defaultRepeatMaxAge is how long a repeated search is served from the
+// transcript unless configured otherwise
+const defaultRepeatMaxAge = 10 * time.Minute
+
 // SearchTool provides the search functionality as an MCP tool
 type SearchTool struct {
 	searchService search.Service
+	transcript    *Transcript
+	footer        bool
+	costPerSearch float64
+	incognito     bool
+	location      *time.Location
+	hideImages    bool
+	freshness     string
+	presets       map[string]map[string]interface{}
+	providers     map[string]search.Capabilities
+	aggregate     []string
+	schemaVersion int
+	pro           bool
+	repeatMaxAge  time.Duration
+	// fetcher and workers fetch the top results for quotes, when enabled
+	fetcher *fetch.Fetcher
+	workers *pool.Pool
 }
 
 // NewSearchTool creates a new search tool with the provided search service
 func NewSearchTool(searchService search.Service) *SearchTool {
 	return &SearchTool{
 		searchService: searchService,
+		schemaVersion: SchemaVersion,
+		repeatMaxAge:  defaultRepeatMaxAge,
 	}
 }
 
+// WithTranscript records every successful search in the session transcript
+func (t *SearchTool) WithTranscript(transcript *Transcript) *SearchTool {
+	t.transcript = transcript
+	return t
+}
+
+// WithRepeatMaxAge serves a repeat of an earlier search from the transcript
+// only while the earlier search is at most maxAge old
+func (t *SearchTool) WithRepeatMaxAge(maxAge time.Duration) *SearchTool {
+	t.repeatMaxAge = maxAge
+	return t
+}
+
+// WithFooter ends every result with the provider, where the results came from,
+// the latency and, when costPerSearch is set, the approximate cost
+func (t *SearchTool) WithFooter(costPerSearch float64) *SearchTool {
+	t.footer = true
+	t.costPerSearch = costPerSearch
+	return t
+}
+
+// WithIncognito makes searches incognito unless a call sets incognito=false
+func (t *SearchTool) WithIncognito(incognito bool) *SearchTool {
+	t.incognito = incognito
+	return t
+}
+
+// WithLocation shows result dates, and groups them by day, in loc instead of
+// the zone the provider returned them in
+func (t *SearchTool) WithLocation(loc *time.Location) *SearchTool {
+	t.location = loc
+	return t
+}
+
+// WithDefaultFreshness sets the freshness used when a call does not set one
+func (t *SearchTool) WithDefaultFreshness(freshness string) *SearchTool {
+	t.freshness = freshness
+	return t
+}
+
+// WithPresets makes named sets of arguments selectable with the preset argument
+func (t *SearchTool) WithPresets(presets map[string]map[string]interface{}) *SearchTool {
+	t.presets = presets
+	return t
+}
+
+// WithProviders makes the named providers selectable with the provider
+// argument, given the capabilities of each
+func (t *SearchTool) WithProviders(providers map[string]search.Capabilities) *SearchTool {
+	t.providers = providers
+	return t
+}
+
+// WithAggregateProviders makes a subset of the named aggregate providers
+// selectable with the providers argument
+func (t *SearchTool) WithAggregateProviders(names []string) *SearchTool {
+	t.aggregate = names
+	return t
+}
+
+// WithSchemaVersion sets the version of the structured output schema used for
+// attached data, so clients written against an older version keep working
+func (t *SearchTool) WithSchemaVersion(version int) *SearchTool {
+	t.schemaVersion = version
+	return t
+}
+
+// WithPro sends searches to the provider's pro endpoint unless a call sets
+// pro=false
+func (t *SearchTool) WithPro(pro bool) *SearchTool {
+	t.pro = pro
+	return t
+}
+
+// WithImages sets whether image results are included unless a call sets
+// include_images
+func (t *SearchTool) WithImages(include bool) *SearchTool {
+	t.hideImages = !include
+	return t
+}
+
+// WithQuotes lets calls ask for quotes from the top results, fetched with
+// fetcher on the shared worker pool
+func (t *SearchTool) WithQuotes(fetcher *fetch.Fetcher, workers *pool.Pool) *SearchTool {
+	t.fetcher = fetcher
+	t.workers = workers
+	return t
+}
+
 // Definition returns the MCP tool definition
 func (t *SearchTool) Definition() mcp.Tool {
-	return mcp.NewTool("search",
+	options := []mcp.ToolOption{
 		mcp.WithDescription("Get the state of the world by searching the web"),
 		mcp.WithString("query",
 			mcp.Required(),
 			mcp.Description("The search query"),
 		),
 		mcp.WithString("freshness",
-			mcp.Description("Filter results by freshness (noLimit, day, week, month, oneYear)"),
+			mcp.Description("Filter results by freshness (noLimit, day, week, month, oneYear); defaults to the server's configured freshness for this tool"),
 			mcp.Enum("noLimit", "day", "week", "month", "oneYear"),
 		),
 		mcp.WithNumber("count",
 			mcp.Description("Number of results to return (1-50)"),
 		),
+		mcp.WithBoolean("auto_count",
+			mcp.Description("Treat count as a minimum: when removing duplicates and filtered results leaves fewer, search again for more, up to the provider's maximum"),
+		),
 		mcp.WithBoolean("summary",
 			mcp.Description("Whether to generate a summary based on search results"),
 		),
-	)
+		mcp.WithBoolean("group_by_date",
+			mcp.Description("Group results by publish date: today, this week, older"),
+		),
+		mcp.WithBoolean("no_autocorrect",
+			mcp.Description("Search for the query exactly as written, without spelling correction"),
+		),
+		mcp.WithString("match_count",
+			mcp.Description("Whether to show the total number of matching pages: none (default), estimated, or exact. The total counts pages that match, not results that can be retrieved"),
+			mcp.Enum(MatchCountNone, MatchCountEstimated, MatchCountExact),
+		),
+		mcp.WithBoolean("pro",
+			mcp.Description("Use the provider's pro endpoint, which adds a direct answer and rich snippets such as ratings or prices, for providers that have one"),
+		),
+		mcp.WithBoolean("ai_search",
+			mcp.Description("Use the provider's AI search, which adds a written answer and cards such as the weather, a stock quote or an encyclopedia entry, for providers that have one; cannot be combined with pro"),
+		),
+		mcp.WithBoolean("incognito",
+			mcp.Description("Keep this search out of the session history, query logs and cache, for sensitive queries"),
+		),
+		mcp.WithBoolean("refresh",
+			mcp.Description("Search the provider again even if the same search already ran this session, instead of repeating its results"),
+		),
+		mcp.WithBoolean("include_images",
+			mcp.Description("Whether to include image results; leave them out to save tokens when only text is needed"),
+		),
+		mcp.WithNumber("max_images",
+			mcp.Description("Maximum number of image results to include (no limit by default)"),
+		),
+		mcp.WithNumber("min_width",
+			mcp.Description("Leave out images narrower than this many pixels, such as icons and small ads"),
+		),
+		mcp.WithNumber("min_height",
+			mcp.Description("Leave out images shorter than this many pixels"),
+		),
+		mcp.WithString("format",
+			mcp.Description("How web results are rendered: text (default), or table for a markdown table of rank, title and link, site and date"),
+			mcp.Enum(FormatText, FormatTable),
+		),
+		withPriority(),
+	}
+	if len(t.presets) > 0 {
+		names := make([]string, 0, len(t.presets))
+		for name := range t.presets {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		options = append(options, mcp.WithString("preset",
+			mcp.Description("A named set of parameters configured on the server; parameters set in the call override it"),
+			mcp.Enum(names...),
+		))
+	}
+	if len(t.providers) > 1 {
+		options = append(options, mcp.WithString("provider",
+			mcp.Description("Search with this provider instead of the server's default, e.g. for results the default provider covers poorly"),
+			mcp.Enum(t.providerNames()...),
+		))
+	}
+	if caps := search.CapabilitiesOf(t.searchService); caps.AgentSearch {
+		agent := []mcp.PropertyOption{
+			mcp.Description("Hand the search to one of the provider's vertical agents, such as bocha-scholar-agent for academic papers or bocha-company-agent for company records; cannot be combined with pro or ai_search"),
+		}
+		if len(caps.Agents) > 0 {
+			agent = append(agent, mcp.Enum(caps.Agents...))
+		}
+		options = append(options, mcp.WithString("agent", agent...))
+	}
+	if t.fetcher != nil {
+		options = append(options, mcp.WithNumber("quotes",
+			mcp.Description(fmt.Sprintf("Fetch the top %d results and also return this many of their sentences most relevant to the query (1-%d), with their URL and character offsets in the page's text as returned by fetch_page", quotePages, maxQuotes)),
+		))
+	}
+	if len(t.aggregate) > 1 {
+		options = append(options, withStringArray("providers",
+			"Query only these of the aggregated providers, e.g. to leave out one that covers the topic poorly",
+			t.aggregate,
+		))
+	}
+	return mcp.NewTool("search", options...)
+}
+
+// checkAgent returns an error unless the provider can hand a search to agent
+func checkAgent(caps search.Capabilities, agent string) error {
+	if !caps.AgentSearch {
+		return fmt.Errorf("provider %s doesn't support agent", caps.Provider)
+	}
+	if len(caps.Agents) > 0 && !slices.Contains(caps.Agents, agent) {
+		return fmt.Errorf("invalid agent %q (expected one of: %s)", agent, strings.Join(caps.Agents, ", "))
+	}
+	return nil
+}
+
+// providerNames returns the names of the selectable providers, sorted
+func (t *SearchTool) providerNames() []string {
+	names := make([]string, 0, len(t.providers))
+	for name := range t.providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// withStringArray adds a property listing some of values to the tool schema
+func withStringArray(name string, description string, values []string) mcp.ToolOption {
+	return func(tool *mcp.Tool) {
+		tool.InputSchema.Properties[name] = map[string]interface{}{
+			"type":        "array",
+			"description": description,
+			"items":       map[string]interface{}{"type": "string", "enum": values},
+			"minItems":    1,
+			"uniqueItems": true,
+		}
+	}
+}
+
+// bindProviders reads the providers argument, which must name some of the
+// aggregate providers. It returns nil when the argument is not set.
+func (t *SearchTool) bindProviders(args map[string]interface{}) ([]string, error) {
+	raw, ok := args["providers"]
+	if !ok {
+		return nil, nil
+	}
+	list, ok := raw.([]interface{})
+	if !ok || len(list) == 0 {
+		return nil, fmt.Errorf("providers must be a non-empty array of provider names")
+	}
+	names := make([]string, 0, len(list))
+	for _, item := range list {
+		name, _ := item.(string)
+		if !slices.Contains(t.aggregate, name) {
+			return nil, fmt.Errorf("invalid provider %q in providers (expected some of: %s)", item, strings.Join(t.aggregate, ", "))
+		}
+		if !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	return names, nil
 }
 
 // Handler returns the MCP tool handler function
@@ -51,45 +324,149 @@ func (t *SearchTool) Handler() func(ctx context.Context, request mcp.CallToolReq
 		ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
 		defer cancel()
 
-		// Extract parameters from the request
-		query, ok := request.Params.Arguments["query"].(string)
-		if !ok || query == "" {
-			return mcp.NewToolResultError("query parameter is required and must be a string"), nil
+		// Extract parameters from the request, on top of the preset it selects
+		args, err := t.applyPreset(request.Params.Arguments)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		p, adj, err := bindSearchArguments(args)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		ctx = search.WithAdjustments(ctx, adj)
+		if ctx, err = applyPriority(ctx, args); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		query := p.Query
+		if p.Freshness == "" {
+			p.Freshness = t.freshness
 		}
 
-		// Validate query length to prevent abuse
-		if len(query) > 1000 {
-			return mcp.NewToolResultError("query is too long (maximum 1000 characters)"), nil
+		// Validate the parameters and adapt them to what the provider supports
+		caps := search.CapabilitiesOf(t.searchService)
+		if provider, _ := args["provider"].(string); provider != "" {
+			selected, ok := t.providers[provider]
+			if !ok {
+				return mcp.NewToolResultError(fmt.Sprintf("invalid provider %q (expected one of: %s)", provider, strings.Join(t.providerNames(), ", "))), nil
+			}
+			caps = selected
+			ctx = search.WithProvider(ctx, provider)
+		}
+		providers, err := t.bindProviders(args)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		if providers != nil {
+			if search.SelectedProvider(ctx) != "" {
+				return mcp.NewToolResultError("provider and providers cannot be combined"), nil
+			}
+			ctx = search.WithProviders(ctx, providers)
+		}
+		p, adj, err = params.Normalize(p, caps.Limits())
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		ctx = search.WithAdjustments(ctx, adj)
+		if err := caps.Check(p.Query, p.Freshness); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		if exact, _ := args["no_autocorrect"].(bool); exact {
+			if !caps.ExactQuery {
+				return mcp.NewToolResultError(fmt.Sprintf("provider %s doesn't support no_autocorrect", caps.Provider)), nil
+			}
+			ctx = search.WithExactQuery(ctx)
+		}
+		if auto, _ := args["auto_count"].(bool); auto {
+			ctx = search.WithAutoCount(ctx)
+		}
+		matchCount, _ := args["match_count"].(string)
+		switch matchCount {
+		case "", MatchCountNone, MatchCountEstimated:
+		case MatchCountExact:
+			if !caps.ExactCount {
+				return mcp.NewToolResultError(fmt.Sprintf("provider %s doesn't support exact match counts", caps.Provider)), nil
+			}
+			ctx = search.WithExactCount(ctx)
+		default:
+			return mcp.NewToolResultError(fmt.Sprintf("invalid match_count %q (expected none, estimated or exact)", matchCount)), nil
 		}
 
-		// Extract optional parameters with defaults
-		freshness := "noLimit"
-		if f, ok := request.Params.Arguments["freshness"].(string); ok && f != "" {
-			// Validate freshness parameter
-			if f != "noLimit" && f != "day" && f != "week" && f != "month" && f != "oneYear" {
-				return mcp.NewToolResultError(fmt.Sprintf("invalid freshness value: %q, must be one of: noLimit, day, week, month, oneYear", f)), nil
+		// The configured default only applies to providers with a pro endpoint,
+		// but asking for it explicitly from one without is an error
+		pro := t.pro
+		if v, ok := args["pro"].(bool); ok {
+			if v && !caps.Pro {
+				return mcp.NewToolResultError(fmt.Sprintf("provider %s doesn't support pro", caps.Provider)), nil
+			}
+			pro = v
+		}
+		if agent, _ := args["agent"].(string); agent != "" {
+			if err := checkAgent(caps, agent); err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			explicitPro, _ := args["pro"].(bool)
+			if ai, _ := args["ai_search"].(bool); ai || explicitPro {
+				return mcp.NewToolResultError("agent cannot be combined with pro or ai_search"), nil
+			}
+			ctx = search.WithAgent(ctx, agent)
+		} else if ai, _ := args["ai_search"].(bool); ai {
+			if !caps.AISearch {
+				return mcp.NewToolResultError(fmt.Sprintf("provider %s doesn't support ai_search", caps.Provider)), nil
+			}
+			if explicit, _ := args["pro"].(bool); explicit {
+				return mcp.NewToolResultError("pro and ai_search cannot be combined"), nil
 			}
-			freshness = f
+			ctx = search.WithAISearch(ctx)
+		} else if pro && caps.Pro {
+			ctx = search.WithPro(ctx)
 		}
 
-		count := 10
-		if c, ok := request.Params.Arguments["count"].(float64); ok {
-			count = int(c)
-			// Ensure count is within valid range
-			if count < 1 {
-				count = 1
-			} else if count > 50 {
-				count = 50
+		format, _ := args["format"].(string)
+		switch format {
+		case "", FormatText, FormatTable:
+		default:
+			return mcp.NewToolResultError(fmt.Sprintf("invalid format %q (expected text or table)", format)), nil
+		}
+
+		images, err := bindImageFilter(args)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		quoteCount := 0
+		if v, ok := args["quotes"]; ok {
+			n, ok := v.(float64)
+			if t.fetcher == nil {
+				return mcp.NewToolResultError("quotes requires page fetching to be enabled"), nil
+			}
+			if !ok || n < 1 || n > maxQuotes || n != float64(int(n)) {
+				return mcp.NewToolResultError(fmt.Sprintf("quotes must be a whole number from 1 to %d", maxQuotes)), nil
 			}
+			quoteCount = int(n)
 		}
 
-		summary := false
-		if s, ok := request.Params.Arguments["summary"].(bool); ok {
-			summary = s
+		incognito := t.incognito
+		if v, ok := args["incognito"].(bool); ok {
+			incognito = v
+		}
+		transcript := t.transcript
+		if incognito {
+			ctx = search.WithIncognito(ctx)
+			transcript = nil
 		}
 
-		// Perform the search
-		response, err := t.searchService.Search(ctx, query, freshness, count, summary)
+		// Serve a recent repeat of an earlier search from the transcript, with a
+		// notice either way so a looping agent notices it is going in circles
+		start := time.Now()
+		response, repeatedAt, repeated := transcript.Repeated(ctx, p)
+		refresh, _ := args["refresh"].(bool)
+		if repeated && (refresh || time.Since(repeatedAt) > t.repeatMaxAge) {
+			repeated = false
+		}
+		if !repeated {
+			response, err = t.searchService.Search(ctx, p.Query, p.Freshness, p.Count, p.Summary)
+		}
+		latency := time.Since(start)
 		if err != nil {
 			// Handle context cancellation
 			if ctx.Err() == context.DeadlineExceeded {
@@ -101,65 +478,454 @@ func (t *SearchTool) Handler() func(ctx context.Context, request mcp.CallToolReq
 			return mcp.NewToolResultError(fmt.Sprintf("Search failed: %v", errMsg)), nil
 		}
 
-		// Format the results
-		var resultBuilder strings.Builder
+		id := transcript.RecordSearch(ctx, p, response)
 
-		// Add search metadata
-		resultBuilder.WriteString(fmt.Sprintf("Search Query: \"%s\"\n", query))
-		resultBuilder.WriteString(fmt.Sprintf("Freshness: %s\n", formatFreshness(freshness)))
-		resultBuilder.WriteString(fmt.Sprintf("Results: %d\n\n", len(response.Data.WebPages.Value)))
+		opts := formatOptions{
+			Freshness:  p.Freshness,
+			Summary:    p.Summary,
+			Now:        time.Now(),
+			ExactQuery: caps.ExactQuery,
+			MatchCount: matchCount,
+			RepeatOf:   repeatedAt,
+			Refreshed:  !repeated,
+			Location:   t.location,
+			Format:     format,
+			HideImages: t.hideImages,
+			Warnings:   caps.Warnings(p.Query),
+		}
+		if include, ok := args["include_images"].(bool); ok {
+			opts.HideImages = !include
+		}
+		if t.location != nil {
+			opts.Now = opts.Now.In(t.location)
+		}
+		opts.GroupByDate, _ = args["group_by_date"].(bool)
+		opts.Suggestions = suggestQueries(query, response.Data.WebPages.Value, caps)
+		text := formatSearchResults(query, filterImages(response, images), opts)
+		var quotes []fetch.Quote
+		if quoteCount > 0 {
+			quotes = t.findQuotes(ctx, query, response.Data.WebPages.Value, quoteCount)
+			var b strings.Builder
+			writeQuotes(&b, quotes)
+			text += b.String()
+		}
+		text += resultsNote(id)
+		if t.footer {
+			text += t.formatFooter(caps.Provider, response, repeated, latency)
+		}
+		result := mcp.NewToolResultText(text)
 
-		// Add summary if available
-		if summary && response.Data.WebPages.WebSearchURL != "" {
-			resultBuilder.WriteString("Search URL:\n")
-			resultBuilder.WriteString(response.Data.WebPages.WebSearchURL)
-			resultBuilder.WriteString("\n\n")
+		// Answers, entity panels and related questions often answer the user
+		// directly, so also attach them as data
+		if answer := answerOf(response); answer != nil {
+			if content, err := structuredContent(AnswerURI, answer, t.schemaVersion); err == nil {
+				result.Content = append(result.Content, content)
+			}
+		}
+		if entity := entityOf(response); entity != nil {
+			if content, err := structuredContent(EntityURI, entity, t.schemaVersion); err == nil {
+				result.Content = append(result.Content, content)
+			}
+		}
+		if cards := cardsOf(response); len(cards) > 0 {
+			if content, err := structuredContent(CardsURI, cards, t.schemaVersion); err == nil {
+				result.Content = append(result.Content, content)
+			}
+		}
+		if questions := peopleAlsoAsk(response); len(questions) > 0 {
+			if content, err := structuredContent(PeopleAlsoAskURI, questions, t.schemaVersion); err == nil {
+				result.Content = append(result.Content, content)
+			}
 		}
+		if snippets := richSnippets(response.Data.WebPages.Value); len(snippets) > 0 {
+			if content, err := structuredContent(RichSnippetsURI, snippets, t.schemaVersion); err == nil {
+				result.Content = append(result.Content, content)
+			}
+		}
+		if len(quotes) > 0 {
+			if content, err := structuredContent(QuotesURI, quotes, t.schemaVersion); err == nil {
+				result.Content = append(result.Content, content)
+			}
+		}
+		result.Content = append(result.Content, faviconLinks(response.Data.WebPages.Value)...)
+		return result, nil
+	}
+}
 
-		// Add search results
-		resultBuilder.WriteString("Search Results:\n")
-		resultBuilder.WriteString("==============\n\n")
+// applyPreset returns the call's arguments on top of the arguments of the
+// preset it selects, or the call's arguments alone without a preset
+func (t *SearchTool) applyPreset(args map[string]interface{}) (map[string]interface{}, error) {
+	name, _ := args["preset"].(string)
+	if name == "" {
+		return args, nil
+	}
+	preset, ok := t.presets[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown preset %q", name)
+	}
+	merged := make(map[string]interface{}, len(preset)+len(args))
+	for k, v := range preset {
+		merged[k] = v
+	}
+	for k, v := range args {
+		merged[k] = v
+	}
+	return merged, nil
+}
 
-		for i, result := range response.Data.WebPages.Value {
-			resultBuilder.WriteString(fmt.Sprintf("%d. %s\n", i+1, result.Name))
-			resultBuilder.WriteString(fmt.Sprintf("   URL: %s\n", result.URL))
+// formatFooter describes where a result came from and what it cost
+func (t *SearchTool) formatFooter(provider string, response *search.WebSearchResponse, repeated bool, latency time.Duration) string {
+	source, cost := "live", t.costPerSearch
+	switch {
+	case repeated:
+		source, cost = "session", 0
+	case response.Meta.Cached:
+		source, cost = "cache", 0
+	}
 
-			if result.SiteIcon != "" {
-				resultBuilder.WriteString(fmt.Sprintf("   Favicon: %s\n", result.SiteIcon))
-			}
+	footer := fmt.Sprintf("\n---\nProvider: %s | Source: %s | Latency: %d ms", provider, source, latency.Milliseconds())
+	if t.costPerSearch > 0 {
+		footer += fmt.Sprintf(" | Cost: ~$%.4f", cost)
+	}
+	return footer + "\n"
+}
 
-			if result.SiteName != "" {
-				resultBuilder.WriteString(fmt.Sprintf("   Site: %s\n", result.SiteName))
-			}
+// faviconLinks returns the site icons of results, in result order and without
+// repeats, as resources linking to the icon so clients that render resources
+// can show them. They are meant for the user, not the model.
+func faviconLinks(results []search.WebPageResult) []mcp.Content {
+	var links []mcp.Content
+	seen := make(map[string]bool)
+	for _, result := range results {
+		icon := result.SiteIcon
+		if icon == "" || seen[icon] {
+			continue
+		}
+		seen[icon] = true
+		link := mcp.NewEmbeddedResource(mcp.TextResourceContents{
+			URI:      icon,
+			MIMEType: "text/uri-list",
+			Text:     icon,
+		})
+		link.Annotations = &struct {
+			Audience []mcp.Role `json:"audience,omitempty"`
+			Priority float64    `json:"priority,omitempty"`
+		}{Audience: []mcp.Role{mcp.RoleUser}}
+		links = append(links, link)
+	}
+	return links
+}
 
-			if result.Snippet != "" {
-				resultBuilder.WriteString(fmt.Sprintf("   Description: %s\n", result.Snippet))
-			}
+// bindSearchArguments extracts the search parameters from the tool call
+// arguments, applying defaults for the optional ones. Overlong queries are
+// truncated and out-of-range counts clamped, as the returned adjustments
+// report, so the usage statistics can count them.
+func bindSearchArguments(args map[string]interface{}) (params.Search, params.Adjustments, error) {
+	var adj params.Adjustments
+	query, ok := args["query"].(string)
+	if !ok || query == "" {
+		return params.Search{}, adj, fmt.Errorf("query parameter is required and must be a string")
+	}
 
-			if result.DateLastCrawled != "" {
-				resultBuilder.WriteString(fmt.Sprintf("   Date: %s\n", formatDate(result.DateLastCrawled)))
-			}
+	p := params.Search{
+		Count: params.DefaultCount,
+	}
+	p.Query, adj.QueryTruncated = params.TruncateQuery(query)
+	if f, ok := args["freshness"].(string); ok {
+		p.Freshness = f
+	}
+	if c, ok := args["count"].(float64); ok {
+		p.Count, adj.CountClamped = clampFloatCount(c)
+	}
+	if s, ok := args["summary"].(bool); ok {
+		p.Summary = s
+	}
+	return p, adj, nil
+}
+
+// clampFloatCount converts a JSON number to a count and reports whether it
+// was out of range. Values outside the accepted range are clamped before
+// conversion so huge numbers cannot overflow.
+func clampFloatCount(c float64) (int, bool) {
+	switch {
+	case math.IsNaN(c):
+		return params.DefaultCount, true
+	case c < 1:
+		return 1, true
+	case c > params.MaxCount:
+		return params.MaxCount, true
+	default:
+		return int(c), false
+	}
+}
+
+// formatOptions controls how search results are rendered
+type formatOptions struct {
+	Freshness string
+	Summary   bool
+	// GroupByDate groups web results into publish date buckets relative to Now
+	GroupByDate bool
+	Now         time.Time
+	// Suggestions are follow-up queries listed after the results
+	Suggestions []string
+	// ExactQuery reports whether the provider can search without spelling correction
+	ExactQuery bool
+	// MatchCount selects how the total number of matches is shown, see MatchCountNone
+	MatchCount string
+	// RepeatOf is when the same search already ran this session, if it did;
+	// Refreshed reports that it ran again rather than being repeated
+	RepeatOf  time.Time
+	Refreshed bool
+	// Warnings are notices about the query shown before the results
+	Warnings []string
+	// Location is the zone dates are shown in; nil keeps the provider's zone
+	Location *time.Location
+	// Format selects how web results are rendered, see FormatText
+	Format string
+	// HideImages leaves out the image results
+	HideImages bool
+}
+
+// formatSearchResults renders a search response as the text returned to the client
+func formatSearchResults(query string, response *search.WebSearchResponse, opts formatOptions) string {
+	var resultBuilder strings.Builder
+
+	if !opts.RepeatOf.IsZero() {
+		outcome := "its results are repeated below"
+		if opts.Refreshed {
+			outcome = "the results below are from a new search"
+		}
+		resultBuilder.WriteString(fmt.Sprintf("Notice: This search already ran at %s this session; %s. "+
+			"Refine the query instead of repeating it, or read %s for every search so far.\n\n", opts.RepeatOf.Format(time.TimeOnly), outcome, TranscriptURI))
+	}
+	for _, warning := range opts.Warnings {
+		resultBuilder.WriteString(fmt.Sprintf("Notice: The %s.\n\n", warning))
+	}
+
+	// Add search metadata
+	resultBuilder.WriteString(fmt.Sprintf("Search Query: \"%s\"\n", query))
+	if corrected := correctedQuery(query, response); corrected != "" {
+		resultBuilder.WriteString(fmt.Sprintf("Corrected Query: \"%s\"\n", corrected))
+		if opts.ExactQuery {
+			resultBuilder.WriteString("(results are for the corrected query; set no_autocorrect to search for the original)\n")
+		}
+	}
+	resultBuilder.WriteString(fmt.Sprintf("Freshness: %s\n", formatFreshness(opts.Freshness)))
+	resultBuilder.WriteString(fmt.Sprintf("Results: %d\n", len(response.Data.WebPages.Value)))
+	if total := response.Data.WebPages.TotalEstimatedMatches; total > 0 {
+		switch opts.MatchCount {
+		case MatchCountEstimated:
+			resultBuilder.WriteString(fmt.Sprintf("Estimated Matches: about %d (matching pages, not results that can be retrieved)\n", total))
+		case MatchCountExact:
+			resultBuilder.WriteString(fmt.Sprintf("Total Matches: %d (matching pages, not results that can be retrieved)\n", total))
+		}
+	}
+	if response.Meta.Degraded {
+		// Say which providers are missing, so the partial results aren't taken as complete
+		resultBuilder.WriteString("Degraded: true (partial results, some providers failed)\n")
+		for _, warning := range response.Meta.Warnings {
+			resultBuilder.WriteString(fmt.Sprintf("Warning: %s\n", sanitizeErrorMessage(warning)))
+		}
+	}
+	resultBuilder.WriteString("\n")
+
+	// Add the direct answer, the cards and the knowledge panel first, since
+	// they often answer the query outright
+	if answer := answerOf(response); answer != nil {
+		writeAnswer(&resultBuilder, answer)
+	}
+	writeCards(&resultBuilder, cardsOf(response))
+	if entity := entityOf(response); entity != nil {
+		writeEntity(&resultBuilder, entity)
+	}
+
+	// Add summary if available
+	if opts.Summary && response.Data.WebPages.WebSearchURL != "" {
+		resultBuilder.WriteString("Search URL:\n")
+		resultBuilder.WriteString(response.Data.WebPages.WebSearchURL)
+		resultBuilder.WriteString("\n\n")
+	}
+
+	// Add related questions ahead of the results they summarize
+	if questions := peopleAlsoAsk(response); len(questions) > 0 {
+		writePeopleAlsoAsk(&resultBuilder, questions)
+	}
+
+	// Add search results
+	resultBuilder.WriteString("Search Results:\n")
+	resultBuilder.WriteString("==============\n\n")
+
+	if opts.GroupByDate {
+		n := 0
+		for _, group := range groupByDate(response.Data.WebPages.Value, opts.Now) {
+			resultBuilder.WriteString(fmt.Sprintf("%s (%d)\n", group.label, len(group.results)))
+			resultBuilder.WriteString(strings.Repeat("-", len(group.label)) + "\n\n")
+			writeWebResults(&resultBuilder, n, group.results, opts)
+			n += len(group.results)
+		}
+	} else {
+		writeWebResults(&resultBuilder, 0, response.Data.WebPages.Value, opts)
+	}
 
+	// Add image results if available
+	if len(response.Data.Images.Value) > 0 && !opts.HideImages {
+		resultBuilder.WriteString("Image Results:\n")
+		resultBuilder.WriteString("==============\n\n")
+
+		for i, image := range response.Data.Images.Value {
+			resultBuilder.WriteString(fmt.Sprintf("%d. Image\n", i+1))
+			resultBuilder.WriteString(fmt.Sprintf("   URL: %s\n", image.ContentURL))
+			resultBuilder.WriteString(fmt.Sprintf("   Thumbnail: %s\n", image.ThumbnailURL))
+			resultBuilder.WriteString(fmt.Sprintf("   Host Page: %s\n", image.HostPageURL))
+			resultBuilder.WriteString(fmt.Sprintf("   Dimensions: %dx%d\n", image.Width, image.Height))
 			resultBuilder.WriteString("\n")
 		}
+	}
+
+	// Nudge the agent toward refining the search
+	if len(opts.Suggestions) > 0 {
+		resultBuilder.WriteString("Suggested Follow-up Queries:\n")
+		resultBuilder.WriteString("============================\n\n")
+		for _, suggestion := range opts.Suggestions {
+			resultBuilder.WriteString(fmt.Sprintf("- %s\n", suggestion))
+		}
+	}
+
+	return resultBuilder.String()
+}
 
-		// Add image results if available
-		if len(response.Data.Images.Value) > 0 {
-			resultBuilder.WriteString("Image Results:\n")
-			resultBuilder.WriteString("==============\n\n")
+// correctedQuery returns the query the provider searched for instead of the
+// one it received, or "" when it was not changed
+func correctedQuery(query string, response *search.WebSearchResponse) string {
+	if original := response.Data.QueryContext.OriginalQuery; original != "" {
+		query = original
+	}
+	altered := strings.TrimSpace(response.Data.QueryContext.AlteredQuery)
+	if altered == "" || strings.EqualFold(altered, strings.TrimSpace(query)) {
+		return ""
+	}
+	return altered
+}
+
+// writeWebResults renders web results in the selected format, numbered from
+// offset+1
+func writeWebResults(b *strings.Builder, offset int, results []search.WebPageResult, opts formatOptions) {
+	if opts.Format == FormatTable {
+		writeResultTable(b, offset, results, opts.Location)
+		return
+	}
+	for i, result := range results {
+		writeWebResult(b, offset+i+1, result, opts.Location)
+	}
+}
+
+// writeResultTable renders web results as a markdown table of rank, linked
+// title, site and date
+func writeResultTable(b *strings.Builder, offset int, results []search.WebPageResult, loc *time.Location) {
+	b.WriteString("| # | Title | Site | Date |\n")
+	b.WriteString("|---|-------|------|------|\n")
+	for i, result := range results {
+		title := fmt.Sprintf("[%s](%s)", escapeLinkText(result.Name), strings.NewReplacer(" ", "%20", "(", "%28", ")", "%29").Replace(result.URL))
+		if result.SafetyFlag != "" {
+			title += fmt.Sprintf(" (flagged as possibly unsafe: %s)", result.SafetyFlag)
+		}
+		var date string
+		if result.DateLastCrawled != "" {
+			date = formatDate(result.DateLastCrawled, loc)
+		}
+		b.WriteString(fmt.Sprintf("| %d | %s | %s | %s |\n", offset+i+1, escapeTableCell(title), escapeTableCell(result.SiteName), escapeTableCell(date)))
+	}
+	b.WriteString("\n")
+}
+
+// escapeTableCell keeps text inside a single markdown table cell
+func escapeTableCell(text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	return strings.ReplaceAll(text, "|", "\\|")
+}
+
+// escapeLinkText keeps brackets in a title from ending the link text early
+func escapeLinkText(text string) string {
+	return strings.NewReplacer("[", "\\[", "]", "\\]").Replace(text)
+}
+
+// writeWebResult renders a single numbered web page result, with its date in loc
+// when loc is set
+func writeWebResult(b *strings.Builder, n int, result search.WebPageResult, loc *time.Location) {
+	b.WriteString(fmt.Sprintf("%d. %s\n", n, result.Name))
+	b.WriteString(fmt.Sprintf("   URL: %s\n", result.URL))
+
+	if result.PDFURL != "" {
+		b.WriteString(fmt.Sprintf("   PDF: %s\n", result.PDFURL))
+	}
+
+	if result.SiteName != "" {
+		b.WriteString(fmt.Sprintf("   Site: %s\n", result.SiteName))
+	}
 
-			for i, image := range response.Data.Images.Value {
-				resultBuilder.WriteString(fmt.Sprintf("%d. Image\n", i+1))
-				resultBuilder.WriteString(fmt.Sprintf("   URL: %s\n", image.ContentURL))
-				resultBuilder.WriteString(fmt.Sprintf("   Thumbnail: %s\n", image.ThumbnailURL))
-				resultBuilder.WriteString(fmt.Sprintf("   Host Page: %s\n", image.HostPageURL))
-				resultBuilder.WriteString(fmt.Sprintf("   Dimensions: %dx%d\n", image.Width, image.Height))
-				resultBuilder.WriteString("\n")
+	if len(result.Authors) > 0 {
+		b.WriteString(fmt.Sprintf("   Authors: %s\n", strings.Join(result.Authors, ", ")))
+	}
+
+	if result.Snippet != "" {
+		b.WriteString(fmt.Sprintf("   Description: %s\n", result.Snippet))
+	}
+
+	if result.RichSnippet != nil {
+		for _, fact := range result.RichSnippet.Facts {
+			label, value := strings.TrimSpace(fact.Label), strings.TrimSpace(fact.Value)
+			if label != "" && value != "" {
+				b.WriteString(fmt.Sprintf("   %s: %s\n", label, value))
+			}
+		}
+	}
+
+	if result.DateLastCrawled != "" {
+		b.WriteString(fmt.Sprintf("   Date: %s\n", formatDate(result.DateLastCrawled, loc)))
+	}
+
+	if result.SafetyFlag != "" {
+		b.WriteString(fmt.Sprintf("   Warning: flagged as possibly unsafe (%s)\n", result.SafetyFlag))
+	}
+
+	b.WriteString("\n")
+}
+
+// dateGroup is a publish date bucket of results
+type dateGroup struct {
+	label   string
+	results []search.WebPageResult
+}
+
+// groupByDate buckets results by publish date relative to now, keeping the
+// provider's order within each bucket and omitting empty buckets
+func groupByDate(results []search.WebPageResult, now time.Time) []dateGroup {
+	groups := []dateGroup{{label: "Today"}, {label: "This Week"}, {label: "Older"}, {label: "Undated"}}
+	year, month, day := now.Date()
+	startOfToday := time.Date(year, month, day, 0, 0, 0, 0, now.Location())
+
+	for _, result := range results {
+		bucket := 3
+		if published, ok := parseDate(result.DateLastCrawled); ok {
+			switch {
+			case !published.Before(startOfToday):
+				bucket = 0
+			case !published.Before(startOfToday.AddDate(0, 0, -6)):
+				bucket = 1
+			default:
+				bucket = 2
 			}
 		}
+		groups[bucket].results = append(groups[bucket].results, result)
+	}
 
-		return mcp.NewToolResultText(resultBuilder.String()), nil
+	var nonEmpty []dateGroup
+	for _, group := range groups {
+		if len(group.results) > 0 {
+			nonEmpty = append(nonEmpty, group)
+		}
 	}
+	return nonEmpty
 }
 
 // formatFreshness returns a human-readable string for the freshness parameter
@@ -178,23 +944,39 @@ func formatFreshness(freshness string) string {
 	}
 }
 
-// formatDate attempts to format the date in a more readable format
-func formatDate(dateStr string) string {
-	// Try to parse the date
+// formatDate attempts to format the date in a more readable format. With loc
+// set, dates that carry a time of day are converted to it and shown with the
+// time and zone, so readers far from the provider's zone see the right day.
+func formatDate(dateStr string, loc *time.Location) string {
+	t, ok := parseDate(dateStr)
+	if !ok {
+		// Return the original string if parsing fails
+		return dateStr
+	}
+	// Date-only values are calendar days rather than instants and are not converted
+	if loc != nil && len(dateStr) > len(time.DateOnly) {
+		return t.In(loc).Format("January 2, 2006 15:04 MST")
+	}
+	return t.Format("January 2, 2006")
+}
+
+// parseDate parses the date formats used by providers
+func parseDate(dateStr string) (time.Time, bool) {
 	for _, layout := range []string{
 		time.RFC3339,
 		"2006-01-02T15:04:05Z",
 		"2006-01-02",
 	} {
 		if t, err := time.Parse(layout, dateStr); err == nil {
-			return t.Format("January 2, 2006")
+			return t, true
 		}
 	}
-
-	// Return the original string if parsing fails
-	return dateStr
+	return time.Time{}, false
 }
 
+// errorDelimiters end a token or URL embedded in an error message
+const errorDelimiters = " \t\n\r\",;:)"
+
 // sanitizeErrorMessage removes potentially sensitive information from error messages
 func sanitizeErrorMessage(errMsg string) string {
 	// Remove any API keys that might be in the error message
@@ -202,34 +984,39 @@ func sanitizeErrorMessage(errMsg string) string {
 	// you might want to use a more sophisticated approach
 	if strings.Contains(errMsg, "Bearer ") {
 		parts := strings.Split(errMsg, "Bearer ")
-		if len(parts) > 1 {
+		// Every part after the first starts with a token
+		for i := 1; i < len(parts); i++ {
 			// Find the end of the token
-			tokenEnd := strings.IndexAny(parts[1], " \t\n\r\",;:)")
+			tokenEnd := strings.IndexAny(parts[i], errorDelimiters)
 			if tokenEnd != -1 {
-				parts[1] = "[REDACTED]" + parts[1][tokenEnd:]
-				errMsg = strings.Join(parts, "Bearer ")
+				parts[i] = "[REDACTED]" + parts[i][tokenEnd:]
 			} else {
 				// If we can't find the end of the token, it might be at the end of the string
-				parts[1] = "[REDACTED]"
-				errMsg = strings.Join(parts, "Bearer ")
+				parts[i] = "[REDACTED]"
 			}
 		}
+		errMsg = strings.Join(parts, "Bearer ")
 	}
 
 	// Remove any URLs that might contain sensitive information
-	if strings.Contains(errMsg, "http") {
-		// Simple regex-like replacement for URLs
-		for _, prefix := range []string{"http://", "https://"} {
-			if idx := strings.Index(errMsg, prefix); idx != -1 {
-				start := idx
-				end := start + len(prefix)
-				// Find the end of the URL
-				for end < len(errMsg) && !strings.ContainsAny(string(errMsg[end]), " \t\n\r\",;:)") {
-					end++
-				}
-				if end > start+len(prefix) {
-					errMsg = errMsg[:start] + "[URL REDACTED]" + errMsg[end:]
-				}
+	for _, prefix := range []string{"http://", "https://"} {
+		from := 0
+		for {
+			idx := strings.Index(errMsg[from:], prefix)
+			if idx == -1 {
+				break
+			}
+			start := from + idx
+			end := start + len(prefix)
+			// Find the end of the URL
+			for end < len(errMsg) && !strings.ContainsRune(errorDelimiters, rune(errMsg[end])) {
+				end++
+			}
+			if end > start+len(prefix) {
+				errMsg = errMsg[:start] + "[URL REDACTED]" + errMsg[end:]
+				from = start + len("[URL REDACTED]")
+			} else {
+				from = end
 			}
 		}
 	}
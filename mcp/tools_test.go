@@ -4,9 +4,11 @@ import (
 	"context"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
 
+	"com.moguyn/mcp-go-search/params"
 	"com.moguyn/mcp-go-search/search"
 )
 
@@ -296,16 +298,27 @@ func TestFormatFreshness(t *testing.T) {
 func TestFormatDate(t *testing.T) {
 	testCases := []struct {
 		input    string
+		location string
 		expected string
 	}{
-		{"2023-01-01T12:00:00Z", "January 1, 2023"},
-		{"2023-01-01", "January 1, 2023"},
-		{"invalid", "invalid"}, // Should return original string for invalid format
+		{"2023-01-01T12:00:00Z", "", "January 1, 2023"},
+		{"2023-01-01", "", "January 1, 2023"},
+		{"invalid", "", "invalid"}, // Should return original string for invalid format
+		{"2023-01-01T20:00:00Z", "Asia/Shanghai", "January 2, 2023 04:00 CST"},
+		{"2023-01-01T02:00:00+08:00", "America/Los_Angeles", "December 31, 2022 10:00 PST"},
+		{"2023-01-01", "America/Los_Angeles", "January 1, 2023"},
 	}
 
 	for _, tc := range testCases {
-		t.Run(tc.input, func(t *testing.T) {
-			result := formatDate(tc.input)
+		t.Run(tc.input+tc.location, func(t *testing.T) {
+			var loc *time.Location
+			if tc.location != "" {
+				var err error
+				if loc, err = time.LoadLocation(tc.location); err != nil {
+					t.Fatalf("Failed to load %s: %v", tc.location, err)
+				}
+			}
+			result := formatDate(tc.input, loc)
 			if result != tc.expected {
 				t.Errorf("Expected '%s', got '%s'", tc.expected, result)
 			}
@@ -313,6 +326,70 @@ func TestFormatDate(t *testing.T) {
 	}
 }
 
+func TestFormatSearchResults_GroupByDate(t *testing.T) {
+	now := time.Date(2025, 3, 12, 15, 0, 0, 0, time.UTC)
+	response := &search.WebSearchResponse{Data: search.Data{WebPages: search.WebPages{
+		Value: []search.WebPageResult{
+			{Name: "Old", DateLastCrawled: "2024-12-01T10:00:00Z"},
+			{Name: "Undated"},
+			{Name: "Morning", DateLastCrawled: "2025-03-12T08:00:00Z"},
+			{Name: "Monday", DateLastCrawled: "2025-03-10"},
+			{Name: "Last week", DateLastCrawled: "2025-03-05T23:00:00Z"},
+		},
+	}}}
+
+	got := formatSearchResults("news", response, formatOptions{GroupByDate: true, Now: now})
+	expected := []string{
+		"Today (1)\n-----\n\n1. Morning\n",
+		"This Week (1)\n---------\n\n2. Monday\n",
+		"Older (2)\n-----\n\n3. Old\n",
+		"4. Last week\n",
+		"Undated (1)\n-------\n\n5. Undated\n",
+	}
+	last := -1
+	for _, want := range expected {
+		idx := strings.Index(got, want)
+		if idx <= last {
+			t.Fatalf("Expected %q after position %d, got:\n%s", want, last, got)
+		}
+		last = idx
+	}
+
+	// Without the option results keep the provider's order and numbering
+	got = formatSearchResults("news", response, formatOptions{Now: now})
+	if strings.Contains(got, "Today") || !strings.Contains(got, "1. Old\n") {
+		t.Errorf("Expected ungrouped results, got:\n%s", got)
+	}
+}
+
+func TestFormatSearchResults_Table(t *testing.T) {
+	response := &search.WebSearchResponse{Data: search.Data{WebPages: search.WebPages{
+		Value: []search.WebPageResult{
+			{Name: "Go [official]", URL: "https://go.dev/", SiteName: "Go", DateLastCrawled: "2025-03-12T08:00:00Z", Snippet: "Not in the table"},
+			{Name: "Pipes | and\nlines", URL: "https://example.com/a b", SafetyFlag: "adult"},
+		},
+	}}}
+
+	got := formatSearchResults("go", response, formatOptions{Format: FormatTable})
+	expected := "| # | Title | Site | Date |\n" +
+		"|---|-------|------|------|\n" +
+		"| 1 | [Go \\[official\\]](https://go.dev/) | Go | March 12, 2025 |\n" +
+		"| 2 | [Pipes \\| and lines](https://example.com/a%20b) (flagged as possibly unsafe: adult) |  |  |\n"
+	if !strings.Contains(got, expected) {
+		t.Errorf("Expected table:\n%s\ngot:\n%s", expected, got)
+	}
+	if strings.Contains(got, "Not in the table") {
+		t.Error("Expected snippets to be left out of the table")
+	}
+
+	// Grouped results get a table per group, numbered across groups
+	response.Data.WebPages.Value[1].DateLastCrawled = "2024-01-01"
+	got = formatSearchResults("go", response, formatOptions{Format: FormatTable, GroupByDate: true, Now: time.Date(2025, 3, 12, 15, 0, 0, 0, time.UTC)})
+	if strings.Count(got, "| # | Title | Site | Date |") != 2 || !strings.Contains(got, "| 2 | [Pipes") {
+		t.Errorf("Expected a table per date group, got:\n%s", got)
+	}
+}
+
 func TestSanitizeErrorMessage(t *testing.T) {
 	testCases := []struct {
 		name     string
@@ -365,3 +442,577 @@ func TestSanitizeErrorMessage(t *testing.T) {
 		})
 	}
 }
+
+// limitedSearchService is a mock provider that reports restricted capabilities
+type limitedSearchService struct {
+	MockSearchService
+}
+
+// Capabilities reports a provider without operators and a small maximum count
+func (m *limitedSearchService) Capabilities() search.Capabilities {
+	return search.Capabilities{
+		Provider:  "limited",
+		Freshness: []string{"noLimit", "day"},
+		MaxCount:  5,
+	}
+}
+
+func TestHandler_ProviderCapabilities(t *testing.T) {
+	var gotCount int
+	service := &limitedSearchService{MockSearchService{
+		SearchFunc: func(_ context.Context, _ string, _ string, count int, _ bool) (*search.WebSearchResponse, error) {
+			gotCount = count
+			return &search.WebSearchResponse{}, nil
+		},
+	}}
+	handler := NewSearchTool(service).Handler()
+
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]interface{}{"query": "golang", "count": float64(20)}
+	result, err := handler(context.Background(), request)
+	if err != nil || result.IsError {
+		t.Fatalf("Expected success, got %v %+v", err, result)
+	}
+	if gotCount != 5 {
+		t.Errorf("Expected count to be clamped to 5, got %d", gotCount)
+	}
+
+	request.Params.Arguments = map[string]interface{}{"query": "golang", "freshness": "week"}
+	result, _ = handler(context.Background(), request)
+	if !result.IsError {
+		t.Fatal("Expected an error for unsupported freshness")
+	}
+	if text := result.Content[0].(mcp.TextContent).Text; !strings.Contains(text, "provider limited doesn't support freshness") {
+		t.Errorf("Unexpected error message: %s", text)
+	}
+
+	request.Params.Arguments = map[string]interface{}{"query": "golang site:go.dev"}
+	result, _ = handler(context.Background(), request)
+	if !result.IsError {
+		t.Error("Expected an error for an unsupported operator")
+	}
+
+	// OR may be an ordinary word, so it is searched with a notice
+	request.Params.Arguments = map[string]interface{}{"query": "Tom OR Jerry"}
+	result, _ = handler(context.Background(), request)
+	if result.IsError {
+		t.Fatalf("Expected OR to be let through, got %+v", result)
+	}
+	if text := result.Content[0].(mcp.TextContent).Text; !strings.Contains(text, "Notice: The provider limited doesn't support the OR operator, so it was searched as an ordinary word.\n\n") {
+		t.Errorf("Expected a notice about OR, got:\n%s", text)
+	}
+}
+
+func TestHandler_NoAutocorrect(t *testing.T) {
+	var exact bool
+	service := &MockSearchService{
+		SearchFunc: func(ctx context.Context, query string, _ string, _ int, _ bool) (*search.WebSearchResponse, error) {
+			exact = search.ExactQuery(ctx)
+			response := &search.WebSearchResponse{}
+			response.Data.QueryContext.OriginalQuery = query
+			if !exact {
+				response.Data.QueryContext.AlteredQuery = "golang generics"
+			}
+			return response, nil
+		},
+	}
+	handler := NewSearchTool(service).Handler()
+
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]interface{}{"query": "golang genrics"}
+	result, _ := handler(context.Background(), request)
+	text := result.Content[0].(mcp.TextContent).Text
+	if !strings.Contains(text, "Search Query: \"golang genrics\"\nCorrected Query: \"golang generics\"\n") {
+		t.Errorf("Expected original and corrected queries, got:\n%s", text)
+	}
+	if !strings.Contains(text, "set no_autocorrect") {
+		t.Errorf("Expected a hint to disable correction, got:\n%s", text)
+	}
+
+	request.Params.Arguments = map[string]interface{}{"query": "golang genrics", "no_autocorrect": true}
+	result, _ = handler(context.Background(), request)
+	if !exact {
+		t.Error("Expected the search to ask for an exact query")
+	}
+	if text := result.Content[0].(mcp.TextContent).Text; strings.Contains(text, "Corrected Query") {
+		t.Errorf("Expected no corrected query, got:\n%s", text)
+	}
+
+	limited := &limitedSearchService{MockSearchService{SearchFunc: service.SearchFunc}}
+	result, _ = NewSearchTool(limited).Handler()(context.Background(), request)
+	if !result.IsError {
+		t.Error("Expected an error for a provider that always corrects queries")
+	}
+}
+
+func TestHandler_AutoCount(t *testing.T) {
+	var auto bool
+	service := &MockSearchService{
+		SearchFunc: func(ctx context.Context, _ string, _ string, _ int, _ bool) (*search.WebSearchResponse, error) {
+			auto = search.AutoCount(ctx)
+			return &search.WebSearchResponse{}, nil
+		},
+	}
+	handler := NewSearchTool(service).Handler()
+
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]interface{}{"query": "golang", "auto_count": true}
+	_, _ = handler(context.Background(), request)
+	if !auto {
+		t.Error("Expected auto_count to treat the count as a minimum")
+	}
+	request.Params.Arguments = map[string]interface{}{"query": "golang"}
+	_, _ = handler(context.Background(), request)
+	if auto {
+		t.Error("Expected a plain search not to")
+	}
+}
+
+func TestHandler_Adjustments(t *testing.T) {
+	var query string
+	var adj params.Adjustments
+	service := &MockSearchService{
+		SearchFunc: func(ctx context.Context, q string, _ string, _ int, _ bool) (*search.WebSearchResponse, error) {
+			query, adj = q, search.Adjustments(ctx)
+			return &search.WebSearchResponse{}, nil
+		},
+	}
+	handler := NewSearchTool(service).Handler()
+
+	// Overlong queries are truncated rather than rejected, and both
+	// adjustments reach the provider for the usage statistics
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]interface{}{"query": strings.Repeat("a", params.MaxQueryLength+10), "count": float64(500)}
+	if result, _ := handler(context.Background(), request); result.IsError {
+		t.Fatalf("Unexpected error: %+v", result)
+	}
+	if len(query) != params.MaxQueryLength || !adj.QueryTruncated || !adj.CountClamped {
+		t.Errorf("Expected a truncated query and a clamped count, got %d bytes and %+v", len(query), adj)
+	}
+
+	request.Params.Arguments = map[string]interface{}{"query": "golang", "count": float64(5)}
+	_, _ = handler(context.Background(), request)
+	if adj != (params.Adjustments{}) {
+		t.Errorf("Expected no adjustments, got %+v", adj)
+	}
+}
+
+func TestHandler_MatchCount(t *testing.T) {
+	var exact bool
+	service := &MockSearchService{
+		SearchFunc: func(ctx context.Context, _ string, _ string, _ int, _ bool) (*search.WebSearchResponse, error) {
+			exact = search.ExactCount(ctx)
+			response := &search.WebSearchResponse{}
+			response.Data.WebPages.TotalEstimatedMatches = 1250
+			return response, nil
+		},
+	}
+	handler := NewSearchTool(service).Handler()
+
+	tests := []struct {
+		matchCount string
+		expected   string
+	}{
+		{"", ""},
+		{MatchCountNone, ""},
+		{MatchCountEstimated, "Estimated Matches: about 1250 (matching pages, not results that can be retrieved)\n"},
+		{MatchCountExact, "Total Matches: 1250 (matching pages, not results that can be retrieved)\n"},
+	}
+	for _, tt := range tests {
+		request := mcp.CallToolRequest{}
+		request.Params.Arguments = map[string]interface{}{"query": "golang", "match_count": tt.matchCount}
+		result, _ := handler(context.Background(), request)
+		text := result.Content[0].(mcp.TextContent).Text
+		if tt.expected == "" && strings.Contains(text, "Matches:") {
+			t.Errorf("Expected no match count for %q, got:\n%s", tt.matchCount, text)
+		}
+		if tt.expected != "" && !strings.Contains(text, tt.expected) {
+			t.Errorf("Expected %q for %q, got:\n%s", tt.expected, tt.matchCount, text)
+		}
+		if exact != (tt.matchCount == MatchCountExact) {
+			t.Errorf("Expected exact count to be requested only for %q, got %v for %q", MatchCountExact, exact, tt.matchCount)
+		}
+	}
+
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]interface{}{"query": "golang", "match_count": "approximate"}
+	if result, _ := handler(context.Background(), request); !result.IsError {
+		t.Error("Expected an error for an invalid match_count")
+	}
+
+	limited := &limitedSearchService{MockSearchService{SearchFunc: service.SearchFunc}}
+	request.Params.Arguments = map[string]interface{}{"query": "golang", "match_count": MatchCountExact}
+	if result, _ := NewSearchTool(limited).Handler()(context.Background(), request); !result.IsError {
+		t.Error("Expected an error for a provider that only estimates counts")
+	}
+}
+
+func TestHandler_Format(t *testing.T) {
+	service := &MockSearchService{
+		SearchFunc: func(_ context.Context, _ string, _ string, _ int, _ bool) (*search.WebSearchResponse, error) {
+			response := &search.WebSearchResponse{}
+			response.Data.WebPages.Value = []search.WebPageResult{{Name: "Go", URL: "https://go.dev/"}}
+			return response, nil
+		},
+	}
+	handler := NewSearchTool(service).Handler()
+
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]interface{}{"query": "golang", "format": FormatTable}
+	result, _ := handler(context.Background(), request)
+	if text := result.Content[0].(mcp.TextContent).Text; !strings.Contains(text, "| 1 | [Go](https://go.dev/) |") {
+		t.Errorf("Expected a results table, got:\n%s", text)
+	}
+
+	request.Params.Arguments = map[string]interface{}{"query": "golang", "format": "html"}
+	if result, _ := handler(context.Background(), request); !result.IsError {
+		t.Error("Expected an error for an invalid format")
+	}
+}
+
+func TestHandler_FaviconLinks(t *testing.T) {
+	service := &MockSearchService{
+		SearchFunc: func(_ context.Context, _ string, _ string, _ int, _ bool) (*search.WebSearchResponse, error) {
+			response := &search.WebSearchResponse{}
+			response.Data.WebPages.Value = []search.WebPageResult{
+				{Name: "Go", URL: "https://go.dev/", SiteIcon: "https://go.dev/favicon.ico"},
+				{Name: "No icon", URL: "https://example.com/"},
+				{Name: "Go blog", URL: "https://go.dev/blog/", SiteIcon: "https://go.dev/favicon.ico"},
+			}
+			return response, nil
+		},
+	}
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]interface{}{"query": "golang"}
+	result, _ := NewSearchTool(service).Handler()(context.Background(), request)
+
+	if text := result.Content[0].(mcp.TextContent).Text; strings.Contains(text, "favicon.ico") {
+		t.Errorf("Expected no favicon URLs in the text, got:\n%s", text)
+	}
+	if len(result.Content) != 2 {
+		t.Fatalf("Expected the text and one favicon link, got %d contents", len(result.Content))
+	}
+	link, ok := result.Content[1].(mcp.EmbeddedResource)
+	if !ok {
+		t.Fatalf("Expected an embedded resource, got %T", result.Content[1])
+	}
+	contents := link.Resource.(mcp.TextResourceContents)
+	if contents.URI != "https://go.dev/favicon.ico" || contents.MIMEType != "text/uri-list" {
+		t.Errorf("Unexpected favicon resource: %+v", contents)
+	}
+	if link.Annotations == nil || len(link.Annotations.Audience) != 1 || link.Annotations.Audience[0] != mcp.RoleUser {
+		t.Errorf("Expected the favicon to be meant for the user, got %+v", link.Annotations)
+	}
+}
+
+func TestHandler_IncludeImages(t *testing.T) {
+	service := &MockSearchService{
+		SearchFunc: func(_ context.Context, _ string, _ string, _ int, _ bool) (*search.WebSearchResponse, error) {
+			response := &search.WebSearchResponse{}
+			response.Data.WebPages.Value = []search.WebPageResult{{Name: "Go", URL: "https://go.dev/"}}
+			response.Data.Images.Value = []search.ImageResult{{ContentURL: "https://go.dev/gopher.png"}}
+			return response, nil
+		},
+	}
+	call := func(tool *SearchTool, args map[string]interface{}) string {
+		request := mcp.CallToolRequest{}
+		request.Params.Arguments = args
+		result, _ := tool.Handler()(context.Background(), request)
+		return result.Content[0].(mcp.TextContent).Text
+	}
+
+	if text := call(NewSearchTool(service), map[string]interface{}{"query": "gopher"}); !strings.Contains(text, "Image Results:") {
+		t.Errorf("Expected image results by default, got:\n%s", text)
+	}
+	if text := call(NewSearchTool(service), map[string]interface{}{"query": "gopher", "include_images": false}); strings.Contains(text, "gopher.png") {
+		t.Errorf("Expected no image results with include_images=false, got:\n%s", text)
+	}
+
+	// The configured default can be overridden per call
+	hidden := NewSearchTool(service).WithImages(false)
+	if text := call(hidden, map[string]interface{}{"query": "gopher"}); strings.Contains(text, "Image Results:") {
+		t.Errorf("Expected no image results when hidden by default, got:\n%s", text)
+	}
+	if text := call(hidden, map[string]interface{}{"query": "gopher", "include_images": true}); !strings.Contains(text, "gopher.png") {
+		t.Errorf("Expected image results with include_images=true, got:\n%s", text)
+	}
+}
+
+func TestHandler_DefaultFreshness(t *testing.T) {
+	var freshness string
+	service := &MockSearchService{
+		SearchFunc: func(_ context.Context, _ string, f string, _ int, _ bool) (*search.WebSearchResponse, error) {
+			freshness = f
+			return &search.WebSearchResponse{}, nil
+		},
+	}
+	handler := NewSearchTool(service).WithDefaultFreshness("week").Handler()
+
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]interface{}{"query": "golang"}
+	_, _ = handler(context.Background(), request)
+	if freshness != "week" {
+		t.Errorf("Expected the default freshness week, got %q", freshness)
+	}
+
+	request.Params.Arguments = map[string]interface{}{"query": "golang", "freshness": "noLimit"}
+	_, _ = handler(context.Background(), request)
+	if freshness != "noLimit" {
+		t.Errorf("Expected the call's freshness to win, got %q", freshness)
+	}
+
+	request.Params.Arguments = map[string]interface{}{"query": "golang"}
+	_, _ = NewSearchTool(service).Handler()(context.Background(), request)
+	if freshness != "noLimit" {
+		t.Errorf("Expected noLimit without a default, got %q", freshness)
+	}
+}
+
+func TestHandler_Preset(t *testing.T) {
+	var freshness string
+	var count int
+	service := &MockSearchService{
+		SearchFunc: func(_ context.Context, _ string, f string, c int, _ bool) (*search.WebSearchResponse, error) {
+			freshness, count = f, c
+			response := &search.WebSearchResponse{}
+			response.Data.WebPages.Value = []search.WebPageResult{{Name: "Go", URL: "https://go.dev/"}}
+			return response, nil
+		},
+	}
+	tool := NewSearchTool(service).WithPresets(map[string]map[string]interface{}{
+		"news-scan": {"freshness": "day", "count": float64(20), "format": FormatTable},
+	})
+
+	if _, ok := tool.Definition().InputSchema.Properties["preset"]; !ok {
+		t.Fatal("Expected a preset parameter when presets are configured")
+	}
+	if _, ok := NewSearchTool(service).Definition().InputSchema.Properties["preset"]; ok {
+		t.Error("Expected no preset parameter without presets")
+	}
+
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]interface{}{"query": "golang", "preset": "news-scan", "count": float64(5)}
+	result, _ := tool.Handler()(context.Background(), request)
+	if freshness != "day" || count != 5 {
+		t.Errorf("Expected the preset's freshness and the call's count, got %q and %d", freshness, count)
+	}
+	if text := result.Content[0].(mcp.TextContent).Text; !strings.Contains(text, "| 1 | [Go](https://go.dev/) |") {
+		t.Errorf("Expected the preset's table format, got:\n%s", text)
+	}
+
+	request.Params.Arguments = map[string]interface{}{"query": "golang", "preset": "deep-dive"}
+	if result, _ := tool.Handler()(context.Background(), request); !result.IsError {
+		t.Error("Expected an error for an unknown preset")
+	}
+}
+
+func TestHandler_Provider(t *testing.T) {
+	var selected string
+	var count int
+	service := &MockSearchService{
+		SearchFunc: func(ctx context.Context, _ string, _ string, c int, _ bool) (*search.WebSearchResponse, error) {
+			selected, count = search.SelectedProvider(ctx), c
+			return &search.WebSearchResponse{}, nil
+		},
+	}
+	tool := NewSearchTool(service).WithProviders(map[string]search.Capabilities{
+		"bocha": search.DefaultCapabilities("bocha"),
+		"brave": {Provider: "brave", Freshness: []string{"noLimit", "day"}, MaxCount: 20},
+	})
+
+	property, ok := tool.Definition().InputSchema.Properties["provider"].(map[string]interface{})
+	if !ok || len(property["enum"].([]string)) != 2 {
+		t.Fatalf("Expected a provider parameter listing both providers, got %v", property)
+	}
+	if _, ok := NewSearchTool(service).Definition().InputSchema.Properties["provider"]; ok {
+		t.Error("Expected no provider parameter without overrides")
+	}
+
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]interface{}{"query": "golang", "provider": "brave", "count": float64(40)}
+	if result, _ := tool.Handler()(context.Background(), request); result.IsError {
+		t.Fatalf("Expected success, got %+v", result)
+	}
+	if selected != "brave" || count != 20 {
+		t.Errorf("Expected the search sent to brave with its maximum count, got %q and %d", selected, count)
+	}
+
+	request.Params.Arguments = map[string]interface{}{"query": "golang", "provider": "brave", "freshness": "week"}
+	if result, _ := tool.Handler()(context.Background(), request); !result.IsError {
+		t.Error("Expected an error for freshness the selected provider doesn't support")
+	}
+
+	request.Params.Arguments = map[string]interface{}{"query": "golang", "provider": "google"}
+	result, _ := tool.Handler()(context.Background(), request)
+	if !result.IsError || !strings.Contains(result.Content[0].(mcp.TextContent).Text, "expected one of: bocha, brave") {
+		t.Errorf("Expected an error for an unknown provider, got %+v", result)
+	}
+}
+
+func TestHandler_AggregateProviders(t *testing.T) {
+	var selected []string
+	service := &MockSearchService{
+		SearchFunc: func(ctx context.Context, _ string, _ string, _ int, _ bool) (*search.WebSearchResponse, error) {
+			selected = search.SelectedProviders(ctx)
+			return &search.WebSearchResponse{}, nil
+		},
+	}
+	tool := NewSearchTool(service).WithAggregateProviders([]string{"bocha", "brave", "google"})
+
+	property, ok := tool.Definition().InputSchema.Properties["providers"].(map[string]interface{})
+	if !ok || property["type"] != "array" {
+		t.Fatalf("Expected an array providers parameter, got %v", property)
+	}
+	if _, ok := NewSearchTool(service).Definition().InputSchema.Properties["providers"]; ok {
+		t.Error("Expected no providers parameter without aggregation")
+	}
+
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]interface{}{"query": "golang", "providers": []interface{}{"google", "bocha", "google"}}
+	if result, _ := tool.Handler()(context.Background(), request); result.IsError {
+		t.Fatalf("Expected success, got %+v", result)
+	}
+	if strings.Join(selected, ",") != "google,bocha" {
+		t.Errorf("Expected the selected providers without repeats, got %v", selected)
+	}
+
+	for _, providers := range []interface{}{[]interface{}{}, []interface{}{"jina"}, "bocha"} {
+		request.Params.Arguments = map[string]interface{}{"query": "golang", "providers": providers}
+		if result, _ := tool.Handler()(context.Background(), request); !result.IsError {
+			t.Errorf("Expected an error for providers %v", providers)
+		}
+	}
+}
+
+// agentSearchService is a mock provider with two vertical agents
+type agentSearchService struct {
+	MockSearchService
+}
+
+// Capabilities reports the default capabilities with the scholar and company agents
+func (m *agentSearchService) Capabilities() search.Capabilities {
+	caps := search.DefaultCapabilities("bocha")
+	caps.Agents = []string{"bocha-scholar-agent", "bocha-company-agent"}
+	return caps
+}
+
+func TestHandler_Agent(t *testing.T) {
+	var agent string
+	var ai, pro bool
+	service := &agentSearchService{MockSearchService{
+		SearchFunc: func(ctx context.Context, _ string, _ string, _ int, _ bool) (*search.WebSearchResponse, error) {
+			agent, ai, pro = search.Agent(ctx), search.AISearch(ctx), search.Pro(ctx)
+			return &search.WebSearchResponse{}, nil
+		},
+	}}
+	tool := NewSearchTool(service).WithPro(true)
+	call := func(args map[string]interface{}) *mcp.CallToolResult {
+		request := mcp.CallToolRequest{}
+		request.Params.Arguments = args
+		result, _ := tool.Handler()(context.Background(), request)
+		return result
+	}
+
+	property, ok := tool.Definition().InputSchema.Properties["agent"].(map[string]interface{})
+	if !ok || len(property["enum"].([]string)) != 2 {
+		t.Fatalf("Expected an agent parameter listing both agents, got %v", property)
+	}
+	if _, ok := NewSearchTool(&limitedSearchService{}).Definition().InputSchema.Properties["agent"]; ok {
+		t.Error("Expected no agent parameter for a provider without an agent search")
+	}
+
+	if result := call(map[string]interface{}{"query": "transformers", "agent": "bocha-scholar-agent"}); result.IsError {
+		t.Fatalf("Expected success, got %+v", result)
+	}
+	if agent != "bocha-scholar-agent" || ai || pro {
+		t.Errorf("Expected the search handed to the scholar agent over the pro default, got agent=%q ai=%t pro=%t", agent, ai, pro)
+	}
+
+	result := call(map[string]interface{}{"query": "transformers", "agent": "bocha-news-agent"})
+	if !result.IsError || !strings.Contains(result.Content[0].(mcp.TextContent).Text, "expected one of: bocha-scholar-agent, bocha-company-agent") {
+		t.Errorf("Expected an error for an unknown agent, got %+v", result)
+	}
+	for _, other := range []string{"pro", "ai_search"} {
+		if result := call(map[string]interface{}{"query": "transformers", "agent": "bocha-scholar-agent", other: true}); !result.IsError {
+			t.Errorf("Expected an error for agent combined with %s", other)
+		}
+	}
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]interface{}{"query": "transformers", "agent": "bocha-scholar-agent"}
+	limited := NewSearchTool(&limitedSearchService{service.MockSearchService})
+	if result, _ := limited.Handler()(context.Background(), request); !result.IsError {
+		t.Error("Expected an error for agent with a provider without an agent search")
+	}
+}
+
+func TestFormatSearchResults_Degraded(t *testing.T) {
+	response := &search.WebSearchResponse{}
+	response.Data.WebPages.Value = []search.WebPageResult{{Name: "Go", URL: "https://go.dev/"}}
+	response.Meta.Degraded = true
+	response.Meta.Warnings = []string{"brave: timed out after 10s", "google: request to https://www.googleapis.com/customsearch/v1?key=secret failed"}
+
+	text := formatSearchResults("golang", response, formatOptions{})
+	if !strings.Contains(text, "Results: 1\nDegraded: true (partial results, some providers failed)\nWarning: brave: timed out after 10s\n") {
+		t.Errorf("Expected the degraded flag and warnings, got:\n%s", text)
+	}
+	if strings.Contains(text, "secret") {
+		t.Errorf("Expected warnings to be sanitized, got:\n%s", text)
+	}
+
+	response.Meta.Degraded = false
+	if text := formatSearchResults("golang", response, formatOptions{}); strings.Contains(text, "Degraded") {
+		t.Errorf("Expected no degraded flag for complete results, got:\n%s", text)
+	}
+}
+
+func TestFormatSearchResults_Paper(t *testing.T) {
+	response := &search.WebSearchResponse{}
+	response.Data.WebPages.Value = []search.WebPageResult{{
+		Name:     "Attention Is All You Need",
+		URL:      "http://arxiv.org/abs/1706.03762v7",
+		PDFURL:   "http://arxiv.org/pdf/1706.03762v7",
+		SiteName: "arXiv",
+		Authors:  []string{"Ashish Vaswani", "Noam Shazeer"},
+	}}
+
+	text := formatSearchResults("attention", response, formatOptions{})
+	expected := "   URL: http://arxiv.org/abs/1706.03762v7\n   PDF: http://arxiv.org/pdf/1706.03762v7\n   Site: arXiv\n   Authors: Ashish Vaswani, Noam Shazeer\n"
+	if !strings.Contains(text, expected) {
+		t.Errorf("Expected the PDF link and authors, got:\n%s", text)
+	}
+}
+
+func TestHandler_Footer(t *testing.T) {
+	cached := false
+	service := &MockSearchService{
+		SearchFunc: func(_ context.Context, _ string, _ string, _ int, _ bool) (*search.WebSearchResponse, error) {
+			response := &search.WebSearchResponse{}
+			response.Meta.Cached = cached
+			return response, nil
+		},
+	}
+	call := func(tool *SearchTool, query string) string {
+		request := mcp.CallToolRequest{}
+		request.Params.Arguments = map[string]interface{}{"query": query}
+		result, _ := tool.Handler()(context.Background(), request)
+		return result.Content[0].(mcp.TextContent).Text
+	}
+
+	if text := call(NewSearchTool(service), "golang"); strings.Contains(text, "Provider:") {
+		t.Errorf("Expected no footer by default, got:\n%s", text)
+	}
+
+	tool := NewSearchTool(service).WithTranscript(NewTranscript()).WithFooter(0.01)
+	text := call(tool, "golang")
+	if !strings.Contains(text, "\n---\nProvider: search | Source: live | Latency: ") || !strings.HasSuffix(text, " ms | Cost: ~$0.0100\n") {
+		t.Errorf("Expected a live footer with the cost, got:\n%s", text)
+	}
+	if text := call(tool, "golang"); !strings.Contains(text, "Source: session") || !strings.Contains(text, "Cost: ~$0.0000") {
+		t.Errorf("Expected a free session footer for a repeat, got:\n%s", text)
+	}
+
+	cached = true
+	if text := call(NewSearchTool(service).WithFooter(0), "golang"); !strings.Contains(text, "Source: cache") || strings.Contains(text, "Cost:") {
+		t.Errorf("Expected a cache footer without cost, got:\n%s", text)
+	}
+}
@@ -0,0 +1,399 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"com.moguyn/mcp-go-search/fetch"
+	"com.moguyn/mcp-go-search/params"
+	"com.moguyn/mcp-go-search/search"
+)
+
+// TranscriptURI is the URI of the session transcript resource
+const TranscriptURI = "session://transcript"
+
+// Transcript bounds, so a long session cannot grow without limit.
+// maxTranscriptEntries is the default; see SetRetention.
+const (
+	maxTranscriptEntries = 500
+	maxTranscriptExcerpt = 2000
+)
+
+// transcriptEntry is a single search or fetch in the research trail
+type transcriptEntry struct {
+	at time.Time
+	// id numbers searches in the order they were made
+	id int
+
+	// Set for searches
+	query     string
+	freshness string
+	results   []search.WebPageResult
+	identity  string
+	response  *search.WebSearchResponse
+
+	// Set for fetches
+	page *transcriptPage
+}
+
+// transcriptPage is what the transcript keeps of a fetched page: the
+// metadata it shows and an excerpt of the body, not the body itself
+type transcriptPage struct {
+	url         string
+	finalURL    string
+	statusCode  int
+	contentType string
+	skipped     string
+	gate        string
+	teaser      string
+	excerpt     string
+}
+
+// newTranscriptPage keeps what the transcript shows of page
+func newTranscriptPage(page *fetch.Page) *transcriptPage {
+	kept := &transcriptPage{
+		url:         page.URL,
+		finalURL:    page.FinalURL,
+		statusCode:  page.StatusCode,
+		contentType: page.ContentType,
+		skipped:     page.Skipped,
+		gate:        page.Gate,
+		teaser:      page.Teaser,
+	}
+	if kept.skipped != "" || kept.gate != "" {
+		return kept
+	}
+	body := page.Body
+	if len(body) > maxTranscriptExcerpt {
+		body = body[:maxTranscriptExcerpt]
+	}
+	kept.excerpt = strings.ToValidUTF8(string(body), "")
+	if len(page.Body) > maxTranscriptExcerpt {
+		kept.excerpt += "\n…"
+	}
+	return kept
+}
+
+// Transcript records the searches and page fetches of a session. A nil
+// Transcript records nothing, so tools can be used without one.
+type Transcript struct {
+	mu      sync.Mutex
+	started time.Time
+	entries []transcriptEntry
+	dropped int
+	// searches counts every search recorded, so ids are never reused
+	searches int
+	picked   map[string]bool
+	now      func() time.Time
+
+	maxAge     time.Duration
+	maxEntries int
+
+	// OnChosen, if set, is called with the URL of each result fetched or
+	// marked as chosen
+	OnChosen func(url string)
+}
+
+// NewTranscript creates an empty transcript for a session starting now
+func NewTranscript() *Transcript {
+	return &Transcript{
+		started: time.Now(),
+		picked:  make(map[string]bool),
+		now:     time.Now,
+
+		maxEntries: maxTranscriptEntries,
+	}
+}
+
+// SetRetention drops entries older than maxAge and keeps at most maxEntries.
+// Zero means no limit.
+func (t *Transcript) SetRetention(maxAge time.Duration, maxEntries int) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.maxAge = maxAge
+	t.maxEntries = maxEntries
+	t.expire()
+}
+
+// Purge erases the transcript and returns how many entries were removed
+func (t *Transcript) Purge() int {
+	if t == nil {
+		return 0
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	n := len(t.entries)
+	t.entries = nil
+	t.dropped = 0
+	t.picked = make(map[string]bool)
+	return n
+}
+
+// RecordSearch adds a search and the response it returned, and returns the id
+// the search is kept under, or 0 if it wasn't recorded
+func (t *Transcript) RecordSearch(ctx context.Context, p params.Search, response *search.WebSearchResponse) int {
+	if t == nil || response == nil {
+		return 0
+	}
+	results := append([]search.WebPageResult(nil), response.Data.WebPages.Value...)
+	return t.add(transcriptEntry{
+		query:     p.Query,
+		freshness: p.Freshness,
+		results:   results,
+		identity:  searchIdentity(ctx, p),
+		response:  response,
+	})
+}
+
+// Repeated returns the response and time of the latest search in the session
+// identical to p, ignoring case and spacing in the query
+func (t *Transcript) Repeated(ctx context.Context, p params.Search) (*search.WebSearchResponse, time.Time, bool) {
+	if t == nil {
+		return nil, time.Time{}, false
+	}
+	identity := searchIdentity(ctx, p)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.expire()
+	for i := len(t.entries) - 1; i >= 0; i-- {
+		if entry := t.entries[i]; entry.response != nil && entry.identity == identity {
+			return entry.response, entry.at, true
+		}
+	}
+	return nil, time.Time{}, false
+}
+
+// searchIdentity identifies the parameters of a search for repeat detection
+func searchIdentity(ctx context.Context, p params.Search) string {
+	query := strings.Join(strings.Fields(strings.ToLower(p.Query)), " ")
+	return fmt.Sprintf("%s\x00%s\x00%d\x00%t\x00%t\x00%t\x00%t\x00%t\x00%t\x00%s\x00%s\x00%s", query, p.Freshness, p.Count, p.Summary,
+		search.ExactQuery(ctx), search.ExactCount(ctx), search.AutoCount(ctx), search.Pro(ctx), search.AISearch(ctx), search.Agent(ctx),
+		search.SelectedProvider(ctx), strings.Join(search.SelectedProviders(ctx), ","))
+}
+
+// RecordFetch adds a fetched page. Fetching a search result marks it as chosen.
+func (t *Transcript) RecordFetch(page *fetch.Page) {
+	if t == nil || page == nil {
+		return
+	}
+	t.add(transcriptEntry{page: newTranscriptPage(page)})
+	if t.OnChosen != nil && page.URL != "" {
+		t.OnChosen(page.URL)
+	}
+}
+
+// MarkChosen marks a result as chosen, e.g. because it was saved
+func (t *Transcript) MarkChosen(url string) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	t.picked[url] = true
+	t.mu.Unlock()
+	if t.OnChosen != nil {
+		t.OnChosen(url)
+	}
+}
+
+// Lookup returns the most recent search result with the given URL and the
+// query that found it
+func (t *Transcript) Lookup(url string) (result search.WebPageResult, query string, ok bool) {
+	if t == nil {
+		return search.WebPageResult{}, "", false
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.expire()
+	for i := len(t.entries) - 1; i >= 0; i-- {
+		for _, result := range t.entries[i].results {
+			if result.URL == url {
+				return result, t.entries[i].query, true
+			}
+		}
+	}
+	return search.WebPageResult{}, "", false
+}
+
+// add appends an entry, dropping the oldest once the transcript is full, and
+// returns the entry's id
+func (t *Transcript) add(entry transcriptEntry) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	entry.at = t.now()
+	if entry.page == nil {
+		t.searches++
+		entry.id = t.searches
+	}
+	t.entries = append(t.entries, entry)
+	t.expire()
+	return entry.id
+}
+
+// expire drops entries beyond the retention limits. The caller must hold t.mu.
+func (t *Transcript) expire() {
+	n := 0
+	if t.maxAge > 0 {
+		cutoff := t.now().Add(-t.maxAge)
+		for n < len(t.entries) && t.entries[n].at.Before(cutoff) {
+			n++
+		}
+	}
+	if t.maxEntries > 0 && len(t.entries)-n > t.maxEntries {
+		n = len(t.entries) - t.maxEntries
+	}
+	if n > 0 {
+		t.entries = append([]transcriptEntry(nil), t.entries[n:]...)
+		t.dropped += n
+	}
+}
+
+// Markdown renders the research trail: every search with its results, the
+// results that were chosen, and excerpts of the fetched pages
+func (t *Transcript) Markdown() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.expire()
+
+	// A result is chosen when it was saved or its page was fetched
+	chosen := make(map[string]bool)
+	for url := range t.picked {
+		chosen[url] = true
+	}
+	for _, entry := range t.entries {
+		if entry.page != nil {
+			chosen[entry.page.url] = true
+			chosen[entry.page.finalURL] = true
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString("# Research Transcript\n\n")
+	b.WriteString(fmt.Sprintf("Session started: %s\n", t.started.Format(time.RFC3339)))
+	b.WriteString(fmt.Sprintf("Searches: %d, pages fetched: %d\n", t.count(false), t.count(true)))
+	if t.dropped > 0 {
+		b.WriteString(fmt.Sprintf("Earlier entries omitted: %d\n", t.dropped))
+	}
+
+	var picks []search.WebPageResult
+	seen := make(map[string]bool)
+	for i, entry := range t.entries {
+		b.WriteString("\n")
+		if entry.page != nil {
+			writeTranscriptPage(&b, i+1, entry)
+			continue
+		}
+
+		b.WriteString(fmt.Sprintf("## %d. Search: %s\n\n", i+1, entry.query))
+		b.WriteString(fmt.Sprintf("_%s, %s_\n\n", formatFreshness(entry.freshness), entry.at.Format(time.TimeOnly)))
+		if len(entry.results) == 0 {
+			b.WriteString("No results.\n")
+		}
+		for j, result := range entry.results {
+			mark := ""
+			if chosen[result.URL] {
+				mark = " **(chosen)**"
+				if !seen[result.URL] {
+					seen[result.URL] = true
+					picks = append(picks, result)
+				}
+			}
+			b.WriteString(fmt.Sprintf("%d. [%s](%s)%s\n", j+1, markdownText(result.Name), result.URL, mark))
+			if result.Snippet != "" {
+				b.WriteString(fmt.Sprintf("   %s\n", markdownText(result.Snippet)))
+			}
+		}
+	}
+
+	if len(picks) > 0 {
+		b.WriteString("\n## Chosen Results\n\n")
+		for _, result := range picks {
+			b.WriteString(fmt.Sprintf("- [%s](%s)\n", markdownText(result.Name), result.URL))
+		}
+	}
+
+	return b.String()
+}
+
+// count returns the number of fetches or searches. The caller must hold t.mu.
+func (t *Transcript) count(fetches bool) int {
+	n := 0
+	for _, entry := range t.entries {
+		if (entry.page != nil) == fetches {
+			n++
+		}
+	}
+	return n
+}
+
+// writeTranscriptPage renders a fetched page with its excerpt
+func writeTranscriptPage(b *strings.Builder, n int, entry transcriptEntry) {
+	page := entry.page
+	b.WriteString(fmt.Sprintf("## %d. Fetched: %s\n\n", n, page.finalURL))
+	b.WriteString(fmt.Sprintf("_Status %d, %s, %s_\n", page.statusCode, page.contentType, entry.at.Format(time.TimeOnly)))
+	if page.skipped != "" {
+		b.WriteString(fmt.Sprintf("\nSkipped: %s\n", page.skipped))
+		return
+	}
+	if page.gate != "" {
+		b.WriteString(fmt.Sprintf("\nGated: %s\n", page.gate))
+		if page.teaser != "" {
+			b.WriteString(fmt.Sprintf("\n> %s\n", markdownText(page.teaser)))
+		}
+		return
+	}
+
+	excerpt := page.excerpt
+	// The fence must be longer than any backtick run in the page
+	fence := "```"
+	for strings.Contains(excerpt, fence) {
+		fence += "`"
+	}
+	b.WriteString(fmt.Sprintf("\n%s\n%s\n%s\n", fence, strings.TrimRight(excerpt, "\n"), fence))
+}
+
+// markdownText flattens text onto one line and escapes link brackets
+func markdownText(text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	return strings.NewReplacer("[", "\\[", "]", "\\]").Replace(text)
+}
+
+// TranscriptResource exposes the session transcript as an MCP resource
+type TranscriptResource struct {
+	transcript *Transcript
+}
+
+// NewTranscriptResource creates a new transcript resource
+func NewTranscriptResource(transcript *Transcript) *TranscriptResource {
+	return &TranscriptResource{
+		transcript: transcript,
+	}
+}
+
+// Definition returns the MCP resource definition
+func (r *TranscriptResource) Definition() mcp.Resource {
+	return mcp.NewResource(TranscriptURI, "Session transcript",
+		mcp.WithResourceDescription("Every query, chosen result and fetched page of this session, in markdown"),
+		mcp.WithMIMEType("text/markdown"),
+	)
+}
+
+// Handler returns the MCP resource handler function
+func (r *TranscriptResource) Handler() func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	return func(_ context.Context, _ mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		return []mcp.ResourceContents{
+			mcp.TextResourceContents{
+				URI:      TranscriptURI,
+				MIMEType: "text/markdown",
+				Text:     r.transcript.Markdown(),
+			},
+		}, nil
+	}
+}
@@ -0,0 +1,250 @@
+package mcp
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"com.moguyn/mcp-go-search/fetch"
+	"com.moguyn/mcp-go-search/params"
+	"com.moguyn/mcp-go-search/search"
+)
+
+func TestTranscript(t *testing.T) {
+	transcript := NewTranscript()
+	transcript.RecordSearch(context.Background(), params.Search{Query: "go generics", Freshness: "week"}, &search.WebSearchResponse{Data: search.Data{WebPages: search.WebPages{
+		Value: []search.WebPageResult{
+			{Name: "Tutorial [Go]", URL: "https://go.dev/doc/tutorial/generics", Snippet: "Getting started\nwith generics"},
+			{Name: "Blog", URL: "https://go.dev/blog/intro-generics"},
+		},
+	}}})
+	transcript.RecordFetch(&fetch.Page{
+		URL:         "https://go.dev/doc/tutorial/generics",
+		FinalURL:    "https://go.dev/doc/tutorial/generics",
+		StatusCode:  200,
+		ContentType: "text/html",
+		Body:        []byte("<h1>Generics</h1>\n```go\nfunc Map[T any]()\n```"),
+	})
+	transcript.RecordFetch(&fetch.Page{FinalURL: "https://example.com/a.pdf", StatusCode: 200, ContentType: "application/pdf", Skipped: fetch.SkippedBinary})
+
+	markdown := transcript.Markdown()
+	expected := []string{
+		"# Research Transcript",
+		"Searches: 1, pages fetched: 2",
+		"## 1. Search: go generics",
+		"_Past week, ",
+		"1. [Tutorial \\[Go\\]](https://go.dev/doc/tutorial/generics) **(chosen)**",
+		"   Getting started with generics",
+		"2. [Blog](https://go.dev/blog/intro-generics)\n",
+		"## 2. Fetched: https://go.dev/doc/tutorial/generics",
+		"````\n<h1>Generics</h1>\n```go\nfunc Map[T any]()\n```\n````",
+		"## 3. Fetched: https://example.com/a.pdf",
+		"Skipped: binary content",
+		"## Chosen Results\n\n- [Tutorial \\[Go\\]](https://go.dev/doc/tutorial/generics)\n",
+	}
+	for _, want := range expected {
+		if !strings.Contains(markdown, want) {
+			t.Errorf("Expected transcript to contain %q, got:\n%s", want, markdown)
+		}
+	}
+}
+
+func TestTranscript_OnChosen(t *testing.T) {
+	var chosen []string
+	transcript := NewTranscript()
+	transcript.OnChosen = func(url string) { chosen = append(chosen, url) }
+
+	transcript.RecordFetch(&fetch.Page{URL: "https://go.dev/", FinalURL: "https://go.dev/", StatusCode: 200})
+	transcript.MarkChosen("https://pkg.go.dev/")
+	if strings.Join(chosen, ",") != "https://go.dev/,https://pkg.go.dev/" {
+		t.Errorf("Expected the fetched and marked results, got %v", chosen)
+	}
+}
+
+func TestTranscript_Bounded(t *testing.T) {
+	transcript := NewTranscript()
+	response := &search.WebSearchResponse{}
+	for i := 0; i < maxTranscriptEntries+3; i++ {
+		transcript.RecordSearch(context.Background(), params.Search{Query: "query"}, response)
+	}
+	markdown := transcript.Markdown()
+	if !strings.Contains(markdown, "Earlier entries omitted: 3") {
+		t.Errorf("Expected 3 omitted entries, got:\n%s", markdown[:200])
+	}
+
+	// Fetched pages are kept as an excerpt, not with their whole body
+	transcript.RecordFetch(&fetch.Page{FinalURL: "https://example.com/big", StatusCode: 200, Body: []byte(strings.Repeat("x", 1<<20))})
+	page := transcript.entries[len(transcript.entries)-1].page
+	if len(page.excerpt) != maxTranscriptExcerpt+len("\n…") || !strings.HasSuffix(page.excerpt, "\n…") {
+		t.Errorf("Expected a cut excerpt, got %d bytes", len(page.excerpt))
+	}
+
+	// A nil transcript records nothing
+	var none *Transcript
+	none.RecordSearch(context.Background(), params.Search{Query: "query"}, response)
+	none.RecordFetch(&fetch.Page{})
+}
+
+func TestTranscript_Retention(t *testing.T) {
+	transcript := NewTranscript()
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	transcript.now = func() time.Time { return now }
+	transcript.SetRetention(time.Hour, 2)
+
+	response := &search.WebSearchResponse{}
+	transcript.RecordSearch(context.Background(), params.Search{Query: "first"}, response)
+	now = now.Add(2 * time.Hour)
+	transcript.RecordSearch(context.Background(), params.Search{Query: "second"}, response)
+	transcript.RecordSearch(context.Background(), params.Search{Query: "third"}, response)
+
+	if _, _, ok := transcript.Repeated(context.Background(), params.Search{Query: "first"}); ok {
+		t.Error("Expected the expired search to be forgotten")
+	}
+	transcript.RecordSearch(context.Background(), params.Search{Query: "fourth"}, response)
+	markdown := transcript.Markdown()
+	if strings.Contains(markdown, "Search: second") || !strings.Contains(markdown, "Search: fourth") {
+		t.Errorf("Expected only the two newest searches, got:\n%s", markdown)
+	}
+
+	if n := transcript.Purge(); n != 2 {
+		t.Errorf("Expected 2 entries purged, got %d", n)
+	}
+	if markdown := transcript.Markdown(); strings.Contains(markdown, "Search:") || strings.Contains(markdown, "omitted") {
+		t.Errorf("Expected an empty transcript after purging, got:\n%s", markdown)
+	}
+}
+
+func TestTranscriptResource(t *testing.T) {
+	mockService := &MockSearchService{
+		SearchFunc: func(_ context.Context, _ string, _ string, _ int, _ bool) (*search.WebSearchResponse, error) {
+			return &search.WebSearchResponse{Data: search.Data{WebPages: search.WebPages{
+				Value: []search.WebPageResult{{Name: "Result", URL: "https://example.com"}},
+			}}}, nil
+		},
+	}
+	transcript := NewTranscript()
+	tool := NewSearchTool(mockService).WithTranscript(transcript)
+
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]interface{}{"query": "test query"}
+	if _, err := tool.Handler()(context.Background(), request); err != nil {
+		t.Fatalf("Handler returned an error: %v", err)
+	}
+
+	resource := NewTranscriptResource(transcript)
+	if definition := resource.Definition(); definition.URI != "session://transcript" {
+		t.Errorf("Expected URI session://transcript, got %s", definition.URI)
+	}
+	contents, err := resource.Handler()(context.Background(), mcp.ReadResourceRequest{})
+	if err != nil {
+		t.Fatalf("Handler returned an error: %v", err)
+	}
+	text, ok := contents[0].(mcp.TextResourceContents)
+	if !ok {
+		t.Fatalf("Expected text contents, got %T", contents[0])
+	}
+	if text.MIMEType != "text/markdown" || !strings.Contains(text.Text, "## 1. Search: test query") {
+		t.Errorf("Expected the search in the transcript, got:\n%s", text.Text)
+	}
+}
+
+func TestSearchTool_RepeatedQuery(t *testing.T) {
+	calls := 0
+	mockService := &MockSearchService{
+		SearchFunc: func(_ context.Context, _ string, _ string, _ int, _ bool) (*search.WebSearchResponse, error) {
+			calls++
+			return &search.WebSearchResponse{Data: search.Data{WebPages: search.WebPages{
+				Value: []search.WebPageResult{{Name: "Result", URL: "https://example.com"}},
+			}}}, nil
+		},
+	}
+	handler := NewSearchTool(mockService).WithTranscript(NewTranscript()).Handler()
+	run := func(args map[string]interface{}) string {
+		request := mcp.CallToolRequest{}
+		request.Params.Arguments = args
+		result, err := handler(context.Background(), request)
+		if err != nil || result.IsError {
+			t.Fatalf("Expected success, got %v %+v", err, result)
+		}
+		return result.Content[0].(mcp.TextContent).Text
+	}
+
+	if text := run(map[string]interface{}{"query": "Go generics"}); strings.Contains(text, "Notice:") {
+		t.Errorf("Expected no notice for a new search, got:\n%s", text)
+	}
+	text := run(map[string]interface{}{"query": "go  GENERICS"})
+	if !strings.HasPrefix(text, "Notice: This search already ran at ") || !strings.Contains(text, TranscriptURI) {
+		t.Errorf("Expected a repeat notice pointing at the transcript, got:\n%s", text)
+	}
+	if !strings.Contains(text, "1. Result") {
+		t.Errorf("Expected the earlier results, got:\n%s", text)
+	}
+	if calls != 1 {
+		t.Errorf("Expected the repeat to be served from the session, got %d provider calls", calls)
+	}
+
+	// Different parameters are a different search
+	run(map[string]interface{}{"query": "go generics", "freshness": "day"})
+	if calls != 2 {
+		t.Errorf("Expected a search with other parameters to reach the provider, got %d calls", calls)
+	}
+
+	// refresh searches again, keeping the notice
+	text = run(map[string]interface{}{"query": "go generics", "refresh": true})
+	if calls != 3 || !strings.Contains(text, "the results below are from a new search") {
+		t.Errorf("Expected a refreshed search with a notice, got %d calls and:\n%s", calls, text)
+	}
+
+	// So does a repeat of a search older than the repeat max age
+	handler = NewSearchTool(mockService).WithTranscript(NewTranscript()).WithRepeatMaxAge(time.Nanosecond).Handler()
+	run(map[string]interface{}{"query": "go generics"})
+	time.Sleep(time.Millisecond)
+	text = run(map[string]interface{}{"query": "go generics"})
+	if calls != 5 || !strings.HasPrefix(text, "Notice: This search already ran at ") {
+		t.Errorf("Expected an old repeat to reach the provider with a notice, got %d calls and:\n%s", calls, text)
+	}
+}
+
+func TestSearchTool_Incognito(t *testing.T) {
+	var incognito []bool
+	mockService := &MockSearchService{
+		SearchFunc: func(ctx context.Context, _ string, _ string, _ int, _ bool) (*search.WebSearchResponse, error) {
+			incognito = append(incognito, search.Incognito(ctx))
+			return &search.WebSearchResponse{Data: search.Data{WebPages: search.WebPages{
+				Value: []search.WebPageResult{{Name: "Result", URL: "https://example.com/private"}},
+			}}}, nil
+		},
+	}
+	transcript := NewTranscript()
+	tool := NewSearchTool(mockService).WithTranscript(transcript)
+	run := func(args map[string]interface{}) {
+		request := mcp.CallToolRequest{}
+		request.Params.Arguments = args
+		if result, err := tool.Handler()(context.Background(), request); err != nil || result.IsError {
+			t.Fatalf("Expected success, got %v %+v", err, result)
+		}
+	}
+
+	run(map[string]interface{}{"query": "private matter", "incognito": true})
+	run(map[string]interface{}{"query": "private matter", "incognito": true})
+	if len(incognito) != 2 || !incognito[0] {
+		t.Errorf("Expected both searches to reach the provider as incognito, got %v", incognito)
+	}
+	if markdown := transcript.Markdown(); strings.Contains(markdown, "private matter") {
+		t.Errorf("Expected incognito searches to stay out of the transcript, got:\n%s", markdown)
+	}
+
+	// The server default can be overridden per call
+	tool.WithIncognito(true)
+	run(map[string]interface{}{"query": "public matter"})
+	run(map[string]interface{}{"query": "public matter", "incognito": false})
+	if !incognito[2] || incognito[3] {
+		t.Errorf("Expected the default to apply unless overridden, got %v", incognito)
+	}
+	if markdown := transcript.Markdown(); !strings.Contains(markdown, "public matter") {
+		t.Errorf("Expected the non-incognito search in the transcript, got:\n%s", markdown)
+	}
+}
@@ -0,0 +1,135 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"com.moguyn/mcp-go-search/store"
+)
+
+// UsageURI identifies the structured usage report attached to usage results
+const UsageURI = "search://usage"
+
+// ProviderQuota is a provider's API usage and what is left of its daily quota
+type ProviderQuota struct {
+	Provider string            `json:"provider"`
+	Today    store.UsageCounts `json:"today"`
+	Total    store.UsageCounts `json:"total"`
+	// DailyQuota and Remaining are only set for providers with a configured quota
+	DailyQuota int  `json:"daily_quota,omitempty"`
+	Remaining  *int `json:"remaining,omitempty"`
+}
+
+// UsageReport is the API usage of every provider called or given a quota
+type UsageReport struct {
+	Day       string          `json:"day"`
+	ResetsAt  time.Time       `json:"resets_at"`
+	Since     time.Time       `json:"since"`
+	Providers []ProviderQuota `json:"providers"`
+}
+
+// UsageTool reports API calls per provider against their daily quotas as an
+// MCP tool. Remaining quota is estimated: calls made with the same API key
+// outside this server aren't counted.
+type UsageTool struct {
+	usage         *store.Usage
+	quotas        map[string]int
+	schemaVersion int
+}
+
+// NewUsageTool creates a new usage tool backed by the usage file, with the
+// daily quota of each provider that has one
+func NewUsageTool(usage *store.Usage, quotas map[string]int) *UsageTool {
+	return &UsageTool{
+		usage:         usage,
+		quotas:        quotas,
+		schemaVersion: SchemaVersion,
+	}
+}
+
+// WithSchemaVersion sets the version of the structured output schema used for
+// attached data
+func (t *UsageTool) WithSchemaVersion(version int) *UsageTool {
+	t.schemaVersion = version
+	return t
+}
+
+// Definition returns the MCP tool definition
+func (t *UsageTool) Definition() mcp.Tool {
+	return mcp.NewTool("usage",
+		mcp.WithDescription("Report search API usage per provider, today and since counting began: call and error counts, and the estimated remaining daily quota, to pace searches before a quota runs out"),
+	)
+}
+
+// Handler returns the MCP tool handler function
+func (t *UsageTool) Handler() func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(_ context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		summary, err := t.usage.Summary()
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to read usage: %v", err)), nil
+		}
+		report := t.report(summary)
+
+		result := mcp.NewToolResultText(formatUsage(report))
+		if content, err := structuredContent(UsageURI, report, t.schemaVersion); err == nil {
+			result.Content = append(result.Content, content)
+		}
+		return result, nil
+	}
+}
+
+// report adds the quotas to the usage summary, listing providers given a
+// quota even if they haven't been called yet
+func (t *UsageTool) report(summary store.UsageSummary) *UsageReport {
+	report := &UsageReport{Day: summary.Day, ResetsAt: t.usage.ResetsAt(), Since: summary.Since, Providers: []ProviderQuota{}}
+	seen := make(map[string]bool)
+	for _, p := range summary.Providers {
+		seen[p.Provider] = true
+		report.Providers = append(report.Providers, ProviderQuota{Provider: p.Provider, Today: p.Today, Total: p.Total})
+	}
+	for provider := range t.quotas {
+		if !seen[provider] {
+			report.Providers = append(report.Providers, ProviderQuota{Provider: provider})
+		}
+	}
+	sort.Slice(report.Providers, func(i, j int) bool {
+		return report.Providers[i].Provider < report.Providers[j].Provider
+	})
+
+	for i := range report.Providers {
+		p := &report.Providers[i]
+		quota, ok := t.quotas[p.Provider]
+		if !ok {
+			continue
+		}
+		remaining := max(quota-int(p.Today.Calls), 0)
+		p.DailyQuota = quota
+		p.Remaining = &remaining
+	}
+	return report
+}
+
+// formatUsage renders a usage report as the text returned to the client
+func formatUsage(report *UsageReport) string {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("Usage for %s (daily counts reset at %s)\n", report.Day, report.ResetsAt.Format(time.RFC3339)))
+	b.WriteString(fmt.Sprintf("Counting since %s\n\n", report.Since.Format(time.RFC3339)))
+	if len(report.Providers) == 0 {
+		b.WriteString("No API calls made yet.\n")
+		return b.String()
+	}
+	for _, p := range report.Providers {
+		b.WriteString(fmt.Sprintf("%s:\n", p.Provider))
+		b.WriteString(fmt.Sprintf("   Today: %d calls, %d errors\n", p.Today.Calls, p.Today.Errors))
+		b.WriteString(fmt.Sprintf("   Total: %d calls, %d errors\n", p.Total.Calls, p.Total.Errors))
+		if p.Remaining != nil {
+			b.WriteString(fmt.Sprintf("   Daily quota: %d, about %d remaining\n", p.DailyQuota, *p.Remaining))
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,79 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"com.moguyn/mcp-go-search/store"
+)
+
+func TestUsageTool(t *testing.T) {
+	usage, err := store.OpenUsageEncrypted(t.TempDir(), nil, nil)
+	if err != nil {
+		t.Fatalf("OpenUsageEncrypted returned an error: %v", err)
+	}
+	tool := NewUsageTool(usage, map[string]int{"bocha": 3, "brave": 2000})
+	if tool.Definition().Name != "usage" {
+		t.Errorf("Expected tool name 'usage', got '%s'", tool.Definition().Name)
+	}
+
+	for _, failed := range []bool{false, true, false, false} {
+		if err := usage.Record("bocha", failed); err != nil {
+			t.Fatalf("Record returned an error: %v", err)
+		}
+	}
+	if err := usage.Record("jina", false); err != nil {
+		t.Fatalf("Record returned an error: %v", err)
+	}
+
+	result, err := tool.Handler()(context.Background(), mcp.CallToolRequest{})
+	if err != nil {
+		t.Fatalf("Handler returned an error: %v", err)
+	}
+	if result.IsError {
+		t.Fatal("Expected IsError to be false")
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+	for _, want := range []string{
+		"bocha:\n   Today: 4 calls, 1 errors\n   Total: 4 calls, 1 errors\n   Daily quota: 3, about 0 remaining\n",
+		"brave:\n   Today: 0 calls, 0 errors\n   Total: 0 calls, 0 errors\n   Daily quota: 2000, about 2000 remaining\n",
+		"jina:\n   Today: 1 calls, 0 errors\n   Total: 1 calls, 0 errors\n",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, text)
+		}
+	}
+	if strings.Contains(text, "jina:\n   Today: 1 calls, 0 errors\n   Total: 1 calls, 0 errors\n   Daily quota") {
+		t.Error("Expected no quota for a provider without one")
+	}
+
+	resource := result.Content[1].(mcp.EmbeddedResource).Resource.(mcp.TextResourceContents)
+	if resource.URI != UsageURI {
+		t.Errorf("Expected resource URI %s, got %s", UsageURI, resource.URI)
+	}
+	var envelope struct {
+		Data UsageReport `json:"data"`
+	}
+	if err := json.Unmarshal([]byte(resource.Text), &envelope); err != nil {
+		t.Fatalf("Failed to decode structured content: %v", err)
+	}
+	providers := envelope.Data.Providers
+	if len(providers) != 3 || providers[0].Remaining == nil || *providers[0].Remaining != 0 || providers[2].Remaining != nil {
+		t.Errorf("Unexpected usage report: %+v", envelope.Data)
+	}
+}
+
+func TestUsageTool_NoCalls(t *testing.T) {
+	usage, err := store.OpenUsageEncrypted(t.TempDir(), nil, nil)
+	if err != nil {
+		t.Fatalf("OpenUsageEncrypted returned an error: %v", err)
+	}
+	result, _ := NewUsageTool(usage, nil).Handler()(context.Background(), mcp.CallToolRequest{})
+	if text := result.Content[0].(mcp.TextContent).Text; !strings.Contains(text, "No API calls made yet.") {
+		t.Errorf("Unexpected output:\n%s", text)
+	}
+}
@@ -0,0 +1,326 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"com.moguyn/mcp-go-search/params"
+	"com.moguyn/mcp-go-search/search"
+)
+
+// ClaimCheckURI identifies the structured claim check attached to verify results
+const ClaimCheckURI = "search://claim-check"
+
+// Stances a source can take on a claim
+const (
+	StanceSupports    = "supports"
+	StanceContradicts = "contradicts"
+	StanceUnclear     = "unclear"
+)
+
+// Sources sharing less than minClaimCoverage of the claim's words are about
+// something else; from supportCoverage on, a source can take a side: it
+// contradicts the claim when its negation differs and supports it when it
+// restates the claim or uses an affirming cue
+const (
+	minClaimCoverage = 0.34
+	supportCoverage  = 0.6
+)
+
+// refutingCues are words fact-checkers and news use when a claim is wrong
+var refutingCues = []string{
+	"false", "fake", "myth", "debunk", "hoax", "misleading", "not true", "untrue",
+	"no evidence", "incorrect", "baseless", "unfounded", "disproven", "misinformation",
+	"rumor", "rumour", "谣言", "辟谣", "不实", "虚假", "误导", "假消息",
+}
+
+// affirmingCues are words sources use when a claim is confirmed
+var affirmingCues = []string{
+	"confirmed", "confirms", "proven", "is true", "it's true", "study finds", "studies show",
+	"evidence shows", "证实", "属实", "确认",
+}
+
+// negationWords turn a statement into its opposite; words ending in "n't"
+// count as well
+var negationWords = map[string]bool{
+	"not": true, "no": true, "never": true, "cannot": true, "neither": true, "nor": true,
+	"none": true, "nothing": true,
+}
+
+// ClaimSource is a search result weighed against a claim
+type ClaimSource struct {
+	Stance string `json:"stance"`
+	// Relevance is the share of the claim's words found in the source's title and snippet
+	Relevance float64 `json:"relevance"`
+	Title     string  `json:"title"`
+	URL       string  `json:"url"`
+	SiteName  string  `json:"site_name,omitempty"`
+	Snippet   string  `json:"snippet,omitempty"`
+	Date      string  `json:"date,omitempty"`
+}
+
+// ClaimCheck is the evidence found for and against a claim. Stances are
+// labeled from the wording of results, not by reading the pages, so they
+// are candidates for a verdict rather than the verdict itself.
+type ClaimCheck struct {
+	Claim       string        `json:"claim"`
+	Supports    int           `json:"supports"`
+	Contradicts int           `json:"contradicts"`
+	Unclear     int           `json:"unclear"`
+	Sources     []ClaimSource `json:"sources"`
+}
+
+// VerifyTool checks a claim against search results as an MCP tool
+type VerifyTool struct {
+	searchService search.Service
+	schemaVersion int
+}
+
+// NewVerifyTool creates a new verify tool with the provided search service
+func NewVerifyTool(searchService search.Service) *VerifyTool {
+	return &VerifyTool{
+		searchService: searchService,
+		schemaVersion: SchemaVersion,
+	}
+}
+
+// WithSchemaVersion sets the version of the structured output schema used for
+// attached data
+func (t *VerifyTool) WithSchemaVersion(version int) *VerifyTool {
+	t.schemaVersion = version
+	return t
+}
+
+// Definition returns the MCP tool definition
+func (t *VerifyTool) Definition() mcp.Tool {
+	return mcp.NewTool("verify",
+		mcp.WithDescription("Check a claim: search for it and for fact checks of it, and list the sources found with a stance label (supports, contradicts or unclear) and a citation. Stances are guessed from titles and snippets; read the sources before concluding"),
+		mcp.WithString("claim",
+			mcp.Required(),
+			mcp.Description("The claim to check, as a statement"),
+		),
+		mcp.WithString("freshness",
+			mcp.Description("Filter sources by freshness (noLimit, day, week, month, oneYear)"),
+			mcp.Enum(params.Freshness...),
+		),
+		mcp.WithNumber("count",
+			mcp.Description("Number of results to search for with each query (1-50)"),
+		),
+	)
+}
+
+// Handler returns the MCP tool handler function
+func (t *VerifyTool) Handler() func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+		defer cancel()
+
+		claim, ok := request.Params.Arguments["claim"].(string)
+		claim = strings.TrimSpace(claim)
+		if !ok || claim == "" {
+			return mcp.NewToolResultError("claim parameter is required and must be a string"), nil
+		}
+		args := make(map[string]interface{}, len(request.Params.Arguments))
+		for k, v := range request.Params.Arguments {
+			args[k] = v
+		}
+		args["query"] = claim
+		p, adj, err := bindSearchArguments(args)
+		if err != nil {
+			return mcp.NewToolResultError(strings.Replace(err.Error(), "query", "claim", 1)), nil
+		}
+		ctx = search.WithAdjustments(ctx, adj)
+		caps := search.CapabilitiesOf(t.searchService)
+		p, adj, err = params.Normalize(p, caps.Limits())
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		ctx = search.WithAdjustments(ctx, adj)
+		if err := caps.Check(p.Query, p.Freshness); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		// Fact checks rarely repeat a claim word for word, so they are searched
+		// for on their own; that search failing still leaves the claim's results
+		var results []search.WebPageResult
+		for i, query := range []string{claim, claim + " fact check"} {
+			response, err := t.searchService.Search(ctx, query, p.Freshness, p.Count, false)
+			if err != nil {
+				if i > 0 {
+					break
+				}
+				if ctx.Err() == context.DeadlineExceeded {
+					return mcp.NewToolResultError("Search timed out after 30 seconds"), nil
+				}
+				return mcp.NewToolResultError(fmt.Sprintf("Search failed: %v", sanitizeErrorMessage(err.Error()))), nil
+			}
+			results = append(results, response.Data.WebPages.Value...)
+		}
+
+		check := checkClaim(claim, results)
+		result := mcp.NewToolResultText(formatClaimCheck(check))
+		if content, err := structuredContent(ClaimCheckURI, check, t.schemaVersion); err == nil {
+			result.Content = append(result.Content, content)
+		}
+		return result, nil
+	}
+}
+
+// checkClaim labels the stance of each result on the claim, leaving out
+// repeats and results about something else, most relevant first
+func checkClaim(claim string, results []search.WebPageResult) *ClaimCheck {
+	check := &ClaimCheck{Claim: claim, Sources: []ClaimSource{}}
+	claimWords := make(map[string]bool)
+	for _, word := range suggestionWords(claim) {
+		claimWords[word] = true
+	}
+	lowerClaim := strings.ToLower(claim)
+
+	seen := make(map[string]bool)
+	for _, result := range results {
+		if result.URL == "" || seen[result.URL] {
+			continue
+		}
+		seen[result.URL] = true
+
+		text := strings.ToLower(result.Name + " " + result.Snippet)
+		found := make(map[string]bool)
+		for _, word := range suggestionWords(text) {
+			if claimWords[word] {
+				found[word] = true
+			}
+		}
+		relevance := 1.0
+		if len(claimWords) > 0 {
+			relevance = float64(len(found)) / float64(len(claimWords))
+		}
+		if relevance < minClaimCoverage {
+			continue
+		}
+
+		// Sharing the claim's words only says the source is on topic, a side
+		// needs a cue: "vaccines do not cause autism" shares every word of
+		// "vaccines cause autism"
+		stance := StanceUnclear
+		switch {
+		case refutes(text, lowerClaim):
+			stance = StanceContradicts
+		case relevance < supportCoverage:
+		case negated(text) != negated(lowerClaim):
+			stance = StanceContradicts
+		case restates(text, claim) || affirms(text, lowerClaim):
+			stance = StanceSupports
+		}
+		switch stance {
+		case StanceSupports:
+			check.Supports++
+		case StanceContradicts:
+			check.Contradicts++
+		default:
+			check.Unclear++
+		}
+		check.Sources = append(check.Sources, ClaimSource{
+			Stance:    stance,
+			Relevance: float64(int(relevance*100+0.5)) / 100,
+			Title:     result.Name,
+			URL:       result.URL,
+			SiteName:  result.SiteName,
+			Snippet:   strings.TrimSpace(result.Snippet),
+			Date:      result.DateLastCrawled,
+		})
+	}
+	sort.SliceStable(check.Sources, func(i, j int) bool {
+		return check.Sources[i].Relevance > check.Sources[j].Relevance
+	})
+	return check
+}
+
+// refutes reports whether text uses a refuting cue the claim itself doesn't,
+// so a claim that something is a myth isn't contradicted by agreeing sources
+func refutes(text, lowerClaim string) bool {
+	for _, cue := range refutingCues {
+		if strings.Contains(text, cue) && !strings.Contains(lowerClaim, cue) {
+			return true
+		}
+	}
+	return false
+}
+
+// affirms reports whether text uses an affirming cue the claim itself doesn't
+func affirms(text, lowerClaim string) bool {
+	for _, cue := range affirmingCues {
+		if strings.Contains(text, cue) && !strings.Contains(lowerClaim, cue) {
+			return true
+		}
+	}
+	return false
+}
+
+// negated reports whether text contains a negation word
+func negated(text string) bool {
+	for _, word := range strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && r != '\'' && r != '’'
+	}) {
+		word = strings.ReplaceAll(word, "’", "'")
+		if negationWords[word] || strings.HasSuffix(word, "n't") {
+			return true
+		}
+	}
+	return false
+}
+
+// restates reports whether text repeats the claim, its words appearing in
+// the same order with nothing in between
+func restates(text, claim string) bool {
+	claimWords := suggestionWords(claim)
+	if len(claimWords) == 0 {
+		return false
+	}
+	words := suggestionWords(text)
+	for i := 0; i+len(claimWords) <= len(words); i++ {
+		match := true
+		for j, word := range claimWords {
+			if words[i+j] != word {
+				match = false
+				break
+			}
+		}
+		if match {
+			return true
+		}
+	}
+	return false
+}
+
+// formatClaimCheck renders a claim check as the text returned to the client
+func formatClaimCheck(check *ClaimCheck) string {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("Claim: %q\n", check.Claim))
+	if len(check.Sources) == 0 {
+		b.WriteString("\nNo sources found about this claim.\n")
+		return b.String()
+	}
+	b.WriteString(fmt.Sprintf("Sources: %d supporting, %d contradicting, %d unclear\n", check.Supports, check.Contradicts, check.Unclear))
+	b.WriteString("Stances are guessed from titles and snippets; read the sources before concluding.\n\n")
+	for i, source := range check.Sources {
+		b.WriteString(fmt.Sprintf("%d. [%s] %s\n", i+1, source.Stance, source.Title))
+		b.WriteString(fmt.Sprintf("   URL: %s\n", source.URL))
+		if source.SiteName != "" {
+			b.WriteString(fmt.Sprintf("   Site: %s\n", source.SiteName))
+		}
+		if source.Date != "" {
+			b.WriteString(fmt.Sprintf("   Date: %s\n", source.Date))
+		}
+		if source.Snippet != "" {
+			b.WriteString(fmt.Sprintf("   Evidence: %s\n", source.Snippet))
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
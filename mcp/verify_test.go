@@ -0,0 +1,120 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"com.moguyn/mcp-go-search/search"
+)
+
+func TestCheckClaim(t *testing.T) {
+	results := []search.WebPageResult{
+		{Name: "Fact check: Great Wall not visible from space", URL: "https://facts.example/wall", Snippet: "The claim that the Great Wall is visible from space is a myth."},
+		{Name: "Great Wall visible from space, astronaut says", URL: "https://news.example/wall", Snippet: "The Great Wall of China is visible from space, an astronaut said."},
+		{Name: "Great Wall history", URL: "https://history.example/wall", Snippet: "The Great Wall was built over centuries."},
+		{Name: "Holiday deals", URL: "https://shop.example", Snippet: "Cheap flights to anywhere."},
+		{Name: "Great Wall visible from space, astronaut says", URL: "https://news.example/wall", Snippet: "A repeat."},
+	}
+	check := checkClaim("The Great Wall of China is visible from space", results)
+	if check.Supports != 1 || check.Contradicts != 1 || check.Unclear != 1 || len(check.Sources) != 3 {
+		t.Fatalf("Unexpected claim check: %+v", check)
+	}
+	stances := make(map[string]string)
+	for _, source := range check.Sources {
+		stances[source.URL] = source.Stance
+	}
+	if stances["https://facts.example/wall"] != StanceContradicts || stances["https://news.example/wall"] != StanceSupports ||
+		stances["https://history.example/wall"] != StanceUnclear {
+		t.Errorf("Unexpected stances: %v", stances)
+	}
+	if check.Sources[0].URL != "https://news.example/wall" || check.Sources[0].Relevance != 1 {
+		t.Errorf("Expected the most relevant source first, got %+v", check.Sources[0])
+	}
+
+	// A claim that something is a myth is not contradicted by sources agreeing with it
+	check = checkClaim("Lightning never strikes twice is a myth", []search.WebPageResult{
+		{Name: "Lightning never strikes twice? A myth", URL: "https://weather.example", Snippet: "Lightning often strikes the same place twice."},
+	})
+	if check.Sources[0].Stance != StanceSupports {
+		t.Errorf("Expected the source to support the claim, got %+v", check.Sources[0])
+	}
+
+	// Sharing the claim's words isn't support, negated sources contradict
+	check = checkClaim("Vaccines cause autism", []search.WebPageResult{
+		{Name: "Vaccines and autism", URL: "https://health.example/no-link", Snippet: "Vaccines do not cause autism, decades of research show."},
+		{Name: "Autism and vaccines", URL: "https://health.example/doesnt", Snippet: "The MMR vaccine doesn't cause autism."},
+		{Name: "Vaccines, autism and the study", URL: "https://health.example/link", Snippet: "Researchers found no link between vaccines and autism."},
+		{Name: "Autism causes explained", URL: "https://health.example/causes", Snippet: "What causes autism? Vaccines are often asked about."},
+		{Name: "Study confirms vaccines cause autism", URL: "https://blog.example", Snippet: "A new paper confirmed it."},
+	})
+	stances = make(map[string]string)
+	for _, source := range check.Sources {
+		stances[source.URL] = source.Stance
+	}
+	if stances["https://health.example/no-link"] != StanceContradicts || stances["https://health.example/doesnt"] != StanceContradicts ||
+		stances["https://health.example/link"] != StanceContradicts || stances["https://health.example/causes"] != StanceUnclear ||
+		stances["https://blog.example"] != StanceSupports {
+		t.Errorf("Unexpected stances: %v", stances)
+	}
+
+	// A negated claim is contradicted by a source asserting the opposite
+	check = checkClaim("Goldfish don't have a three-second memory", []search.WebPageResult{
+		{Name: "Goldfish memory", URL: "https://fish.example", Snippet: "Goldfish have a three-second memory."},
+	})
+	if check.Sources[0].Stance != StanceContradicts {
+		t.Errorf("Expected the source to contradict the claim, got %+v", check.Sources[0])
+	}
+}
+
+func TestVerifyTool(t *testing.T) {
+	var queries []string
+	service := &MockSearchService{
+		SearchFunc: func(_ context.Context, query string, _ string, _ int, _ bool) (*search.WebSearchResponse, error) {
+			queries = append(queries, query)
+			response := &search.WebSearchResponse{}
+			response.Data.WebPages.Value = []search.WebPageResult{
+				{Name: "Honey never spoils", URL: "https://food.example/honey", SiteName: "Food", Snippet: "Sealed honey never spoils."},
+			}
+			return response, nil
+		},
+	}
+	tool := NewVerifyTool(service)
+	if tool.Definition().Name != "verify" {
+		t.Errorf("Expected tool name 'verify', got '%s'", tool.Definition().Name)
+	}
+
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]interface{}{"claim": "Honey never spoils"}
+	result, err := tool.Handler()(context.Background(), request)
+	if err != nil || result.IsError {
+		t.Fatalf("Unexpected result: %v, %+v", err, result)
+	}
+	if len(queries) != 2 || queries[0] != "Honey never spoils" || queries[1] != "Honey never spoils fact check" {
+		t.Errorf("Expected the claim and a fact check to be searched, got %q", queries)
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+	if !strings.Contains(text, "Sources: 1 supporting, 0 contradicting, 0 unclear\n") ||
+		!strings.Contains(text, "1. [supports] Honey never spoils\n   URL: https://food.example/honey\n   Site: Food\n   Evidence: Sealed honey never spoils.\n") {
+		t.Errorf("Unexpected result text: %s", text)
+	}
+
+	resource := result.Content[1].(mcp.EmbeddedResource).Resource.(mcp.TextResourceContents)
+	var envelope struct {
+		Data ClaimCheck `json:"data"`
+	}
+	if err := json.Unmarshal([]byte(resource.Text), &envelope); err != nil || resource.URI != ClaimCheckURI {
+		t.Fatalf("Unexpected claim check resource: %v, %+v", err, resource)
+	}
+	if envelope.Data.Claim != "Honey never spoils" || envelope.Data.Supports != 1 || len(envelope.Data.Sources) != 1 {
+		t.Errorf("Unexpected claim check data: %+v", envelope.Data)
+	}
+
+	request.Params.Arguments = map[string]interface{}{"claim": "  "}
+	if result, _ := tool.Handler()(context.Background(), request); !result.IsError {
+		t.Error("Expected an error for an empty claim")
+	}
+}
@@ -0,0 +1,168 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"com.moguyn/mcp-go-search/params"
+	"com.moguyn/mcp-go-search/search"
+)
+
+// VideoSearchTool searches for videos as an MCP tool, showing the video
+// results providers return alongside web pages
+type VideoSearchTool struct {
+	searchService search.Service
+	location      *time.Location
+}
+
+// NewVideoSearchTool creates a new video search tool with the provided search service
+func NewVideoSearchTool(searchService search.Service) *VideoSearchTool {
+	return &VideoSearchTool{
+		searchService: searchService,
+	}
+}
+
+// WithLocation shows publication dates in loc instead of the zone the
+// provider returned them in
+func (t *VideoSearchTool) WithLocation(loc *time.Location) *VideoSearchTool {
+	t.location = loc
+	return t
+}
+
+// Definition returns the MCP tool definition
+func (t *VideoSearchTool) Definition() mcp.Tool {
+	return mcp.NewTool("video_search",
+		mcp.WithDescription("Search for videos, with their duration, publisher and link"),
+		mcp.WithString("query",
+			mcp.Required(),
+			mcp.Description("The search query"),
+		),
+		mcp.WithString("freshness",
+			mcp.Description("Filter videos by when they were published (noLimit, day, week, month, oneYear)"),
+			mcp.Enum(params.Freshness...),
+		),
+		mcp.WithNumber("count",
+			mcp.Description("Number of videos to return (1-50)"),
+		),
+	)
+}
+
+// Handler returns the MCP tool handler function
+func (t *VideoSearchTool) Handler() func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+		defer cancel()
+
+		p, adj, err := bindSearchArguments(request.Params.Arguments)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		ctx = search.WithAdjustments(ctx, adj)
+		caps := search.CapabilitiesOf(t.searchService)
+		p, adj, err = params.Normalize(p, caps.Limits())
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		ctx = search.WithAdjustments(ctx, adj)
+		if err := caps.Check(p.Query, p.Freshness); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		response, err := t.searchService.Search(ctx, p.Query, p.Freshness, p.Count, false)
+		if err != nil {
+			if ctx.Err() == context.DeadlineExceeded {
+				return mcp.NewToolResultError("Search timed out after 30 seconds"), nil
+			}
+			return mcp.NewToolResultError(fmt.Sprintf("Search failed: %v", sanitizeErrorMessage(err.Error()))), nil
+		}
+
+		videos := response.Data.Videos.Value
+		if len(videos) > p.Count {
+			videos = videos[:p.Count]
+		}
+		return mcp.NewToolResultText(formatVideos(p.Query, videos, t.location)), nil
+	}
+}
+
+// formatVideos renders video results as the text returned to the client
+func formatVideos(query string, videos []search.VideoResult, loc *time.Location) string {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("Videos for %q:\n\n", query))
+	if len(videos) == 0 {
+		b.WriteString("No videos found.\n")
+		return b.String()
+	}
+	for i, video := range videos {
+		b.WriteString(fmt.Sprintf("%d. %s\n", i+1, video.Name))
+		// The host page plays the video; the content URL is often a raw stream
+		if url := video.HostPageURL; url != "" {
+			b.WriteString(fmt.Sprintf("   URL: %s\n", url))
+		} else if video.ContentURL != "" {
+			b.WriteString(fmt.Sprintf("   URL: %s\n", video.ContentURL))
+		}
+		if duration := formatDuration(video.Duration); duration != "" {
+			b.WriteString(fmt.Sprintf("   Duration: %s\n", duration))
+		}
+		if publisher := videoPublisher(video); publisher != "" {
+			b.WriteString(fmt.Sprintf("   Publisher: %s\n", publisher))
+		}
+		if video.DatePublished != "" {
+			b.WriteString(fmt.Sprintf("   Published: %s\n", formatDate(video.DatePublished, loc)))
+		}
+		if video.ViewCount > 0 {
+			b.WriteString(fmt.Sprintf("   Views: %d\n", video.ViewCount))
+		}
+		if description := strings.TrimSpace(video.Description); description != "" {
+			b.WriteString(fmt.Sprintf("   Description: %s\n", description))
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// videoPublisher names who published a video: its publishers, or its creator
+// when none are given
+func videoPublisher(video search.VideoResult) string {
+	var names []string
+	for _, publisher := range video.Publisher {
+		if name := strings.TrimSpace(publisher.Name); name != "" {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 && video.Creator != nil {
+		return strings.TrimSpace(video.Creator.Name)
+	}
+	return strings.Join(names, ", ")
+}
+
+// isoDuration matches ISO 8601 durations of up to days, such as PT1H2M3S
+var isoDuration = regexp.MustCompile(`^P(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+(?:\.\d+)?)S)?)?$`)
+
+// formatDuration renders a video duration as a clock time such as 1:02:03.
+// Durations that are not ISO 8601 are shown as given.
+func formatDuration(duration string) string {
+	duration = strings.TrimSpace(duration)
+	m := isoDuration.FindStringSubmatch(duration)
+	if m == nil || duration == "P" || strings.HasSuffix(duration, "T") {
+		return duration
+	}
+	var total int
+	for i, unit := range []int{24 * 3600, 3600, 60} {
+		n, _ := strconv.Atoi(m[i+1])
+		total += n * unit
+	}
+	seconds, _ := strconv.ParseFloat(m[4], 64)
+	total += int(seconds)
+
+	hours, minutes, secs := total/3600, total/60%60, total%60
+	if hours > 0 {
+		return fmt.Sprintf("%d:%02d:%02d", hours, minutes, secs)
+	}
+	return fmt.Sprintf("%d:%02d", minutes, secs)
+}
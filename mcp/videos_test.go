@@ -0,0 +1,115 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"com.moguyn/mcp-go-search/search"
+)
+
+const videosResponse = `{
+	"code": 200,
+	"data": {
+		"webPages": {"value": []},
+		"videos": {
+			"value": [
+				{
+					"name": "Go in 100 Seconds",
+					"description": "  Learn the basics of Go.  ",
+					"hostPageUrl": "https://www.youtube.com/watch?v=446E-r0rXHI",
+					"contentUrl": "https://www.youtube.com/embed/446E-r0rXHI",
+					"publisher": [{"name": "YouTube"}],
+					"creator": {"name": "Fireship"},
+					"duration": "PT2M20S",
+					"datePublished": "2021-11-22",
+					"viewCount": 1500000
+				},
+				{
+					"name": "GopherCon keynote",
+					"contentUrl": "https://example.com/keynote.mp4",
+					"creator": {"name": "GopherCon"},
+					"duration": "PT1H2M3S"
+				},
+				{
+					"name": "Third video",
+					"hostPageUrl": "https://example.com/third"
+				}
+			]
+		}
+	}
+}`
+
+func TestVideoSearchTool(t *testing.T) {
+	var response search.WebSearchResponse
+	if err := json.Unmarshal([]byte(videosResponse), &response); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+	var gotQuery string
+	service := &MockSearchService{
+		SearchFunc: func(_ context.Context, query string, _ string, _ int, _ bool) (*search.WebSearchResponse, error) {
+			gotQuery = query
+			return &response, nil
+		},
+	}
+	tool := NewVideoSearchTool(service)
+	if tool.Definition().Name != "video_search" {
+		t.Errorf("Expected the video_search tool, got %s", tool.Definition().Name)
+	}
+
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]interface{}{"query": "golang tutorial", "count": float64(2)}
+	result, err := tool.Handler()(context.Background(), request)
+	if err != nil || result.IsError {
+		t.Fatalf("Expected a result, got %v (%v)", result, err)
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+	expected := "Videos for \"golang tutorial\":\n\n" +
+		"1. Go in 100 Seconds\n" +
+		"   URL: https://www.youtube.com/watch?v=446E-r0rXHI\n" +
+		"   Duration: 2:20\n" +
+		"   Publisher: YouTube\n" +
+		"   Published: November 22, 2021\n" +
+		"   Views: 1500000\n" +
+		"   Description: Learn the basics of Go.\n\n" +
+		"2. GopherCon keynote\n" +
+		"   URL: https://example.com/keynote.mp4\n" +
+		"   Duration: 1:02:03\n" +
+		"   Publisher: GopherCon\n\n"
+	if gotQuery != "golang tutorial" || text != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, text)
+	}
+
+	request.Params.Arguments = map[string]interface{}{}
+	result, _ = tool.Handler()(context.Background(), request)
+	if !result.IsError {
+		t.Error("Expected an error for a missing query")
+	}
+}
+
+func TestFormatVideos_Empty(t *testing.T) {
+	if text := formatVideos("cats", nil, nil); text != "Videos for \"cats\":\n\nNo videos found.\n" {
+		t.Errorf("Unexpected text %q", text)
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	tests := map[string]string{
+		"PT4M13S":   "4:13",
+		"PT45S":     "0:45",
+		"PT1H":      "1:00:00",
+		"P1DT2H":    "26:00:00",
+		"PT3M2.5S":  "3:02",
+		"4:13":      "4:13",
+		" PT10M0S ": "10:00",
+		"PT":        "PT",
+		"":          "",
+	}
+	for duration, expected := range tests {
+		if got := formatDuration(duration); got != expected {
+			t.Errorf("formatDuration(%q) = %q, expected %q", duration, got, expected)
+		}
+	}
+}
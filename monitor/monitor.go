@@ -0,0 +1,246 @@
+// Package monitor re-runs standing queries in the background and notifies a
+// webhook when they surface new results, whether or not a client is connected
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"com.moguyn/mcp-go-search/config"
+	"com.moguyn/mcp-go-search/params"
+	"com.moguyn/mcp-go-search/pool"
+	"com.moguyn/mcp-go-search/search"
+	"com.moguyn/mcp-go-search/store"
+)
+
+// SeenFile is the name of the file recording results already reported
+const SeenFile = "monitors.json"
+
+// maxSeenPerQuery bounds the URLs remembered for each standing query
+const maxSeenPerQuery = 1000
+
+// Result is a new search result reported in a hit
+type Result struct {
+	Title   string `json:"title"`
+	URL     string `json:"url"`
+	Snippet string `json:"snippet,omitempty"`
+}
+
+// Hit reports the new results of a standing query
+type Hit struct {
+	Name       string    `json:"name"`
+	Query      string    `json:"query"`
+	Results    []Result  `json:"new_results"`
+	DetectedAt time.Time `json:"detected_at"`
+}
+
+// Monitor runs standing queries on their intervals and reports new results.
+// The first run of a query only records a baseline so existing results are
+// not reported; the results seen are kept in the data directory across restarts.
+type Monitor struct {
+	service  search.Service
+	queries  []config.StandingQuery
+	notifier Notifier
+	seenPath string
+	cipher   *store.Cipher
+	workers  *pool.Pool
+
+	// OnHit and OnError, when set, are called after every hit and failure
+	OnHit   func(hit Hit)
+	OnError func(name string, err error)
+
+	mu   sync.Mutex
+	seen map[string][]string
+	now  func() time.Time
+}
+
+// New creates a new monitor. notifier may be nil to only record hits, and
+// dataDir may be empty to keep the results seen in memory.
+func New(service search.Service, queries []config.StandingQuery, notifier Notifier, dataDir string) (*Monitor, error) {
+	return NewEncrypted(service, queries, notifier, dataDir, nil)
+}
+
+// NewEncrypted creates a new monitor whose results seen are encrypted with c
+func NewEncrypted(service search.Service, queries []config.StandingQuery, notifier Notifier, dataDir string, c *store.Cipher) (*Monitor, error) {
+	m := &Monitor{
+		service:  service,
+		queries:  queries,
+		notifier: notifier,
+		cipher:   c,
+		seen:     make(map[string][]string),
+		now:      time.Now,
+	}
+	if dataDir != "" {
+		m.seenPath = filepath.Join(dataDir, SeenFile)
+		if err := m.load(); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// SetPool runs checks on a shared worker pool, bounding how many run at once
+// and how long each may take
+func (m *Monitor) SetPool(workers *pool.Pool) {
+	m.workers = workers
+}
+
+// Run checks every standing query immediately and then on its interval until
+// ctx is canceled. Checks are background work on the worker pool.
+func (m *Monitor) Run(ctx context.Context) {
+	ctx = pool.WithTenant(ctx, pool.TenantBackground)
+	var wg sync.WaitGroup
+	for _, query := range m.queries {
+		interval, err := query.Every()
+		if err != nil {
+			m.reportError(query.Name, err)
+			continue
+		}
+		wg.Add(1)
+		go func(query config.StandingQuery) {
+			defer wg.Done()
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+			for {
+				m.runOnce(ctx, query)
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+				}
+			}
+		}(query)
+	}
+	wg.Wait()
+}
+
+// runOnce checks a query, reporting the outcome through the callbacks
+func (m *Monitor) runOnce(ctx context.Context, query config.StandingQuery) {
+	var hit *Hit
+	err := m.workers.Do(ctx, func(ctx context.Context) error {
+		var err error
+		hit, err = m.Check(ctx, query)
+		return err
+	})
+	if err != nil {
+		m.reportError(query.Name, err)
+	}
+	if hit != nil && m.OnHit != nil {
+		m.OnHit(*hit)
+	}
+}
+
+// Check runs a standing query once and returns a hit if it surfaced results
+// not seen before. The hit is delivered to the notifier; a delivery failure is
+// returned along with the hit.
+func (m *Monitor) Check(ctx context.Context, query config.StandingQuery) (*Hit, error) {
+	count := query.Count
+	if count == 0 {
+		count = params.DefaultCount
+	}
+	response, err := m.service.Search(ctx, query.Query, query.Freshness, count, false)
+	if err != nil {
+		return nil, fmt.Errorf("standing query failed: %w", err)
+	}
+
+	m.mu.Lock()
+	previous, known := m.seen[query.Name]
+	seen := make(map[string]bool, len(previous))
+	for _, url := range previous {
+		seen[url] = true
+	}
+	var fresh []Result
+	urls := previous
+	for _, result := range response.Data.WebPages.Value {
+		if result.URL == "" || seen[result.URL] {
+			continue
+		}
+		seen[result.URL] = true
+		urls = append(urls, result.URL)
+		fresh = append(fresh, Result{Title: result.Name, URL: result.URL, Snippet: result.Snippet})
+	}
+	if len(urls) > maxSeenPerQuery {
+		urls = urls[len(urls)-maxSeenPerQuery:]
+	}
+	m.seen[query.Name] = urls
+	saveErr := m.save()
+	m.mu.Unlock()
+
+	if saveErr != nil {
+		m.reportError(query.Name, saveErr)
+	}
+	// The first run only establishes what counts as new
+	if !known || len(fresh) == 0 {
+		return nil, nil
+	}
+
+	hit := &Hit{
+		Name:       query.Name,
+		Query:      query.Query,
+		Results:    fresh,
+		DetectedAt: m.now().UTC(),
+	}
+	if m.notifier != nil {
+		if err := m.notifier.Notify(ctx, *hit); err != nil {
+			return hit, fmt.Errorf("failed to deliver notification: %w", err)
+		}
+	}
+	return hit, nil
+}
+
+// reportError passes a failure to OnError if it is set
+func (m *Monitor) reportError(name string, err error) {
+	if m.OnError != nil {
+		m.OnError(name, err)
+	}
+}
+
+// load reads the results seen by previous runs
+func (m *Monitor) load() error {
+	raw, err := os.ReadFile(m.seenPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read monitor state: %w", err)
+	}
+	if raw, err = m.cipher.Open(raw); err != nil {
+		return fmt.Errorf("failed to read monitor state %s: %w", m.seenPath, err)
+	}
+	if err := json.Unmarshal(raw, &m.seen); err != nil {
+		return fmt.Errorf("failed to parse monitor state %s: %w", m.seenPath, err)
+	}
+	return nil
+}
+
+// save records the results seen so far. The caller must hold m.mu.
+func (m *Monitor) save() error {
+	if m.seenPath == "" {
+		return nil
+	}
+	raw, err := json.Marshal(m.seen)
+	if err != nil {
+		return fmt.Errorf("failed to encode monitor state: %w", err)
+	}
+	if raw, err = m.cipher.Seal(raw); err != nil {
+		return fmt.Errorf("failed to encrypt monitor state: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(m.seenPath), 0o700); err != nil {
+		return fmt.Errorf("failed to create data directory: %w", err)
+	}
+	tmp := m.seenPath + ".tmp"
+	if err := os.WriteFile(tmp, raw, 0o600); err != nil {
+		return fmt.Errorf("failed to write monitor state: %w", err)
+	}
+	if err := os.Rename(tmp, m.seenPath); err != nil {
+		return fmt.Errorf("failed to write monitor state: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,207 @@
+package monitor
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"com.moguyn/mcp-go-search/config"
+	"com.moguyn/mcp-go-search/pool"
+	"com.moguyn/mcp-go-search/search"
+	"com.moguyn/mcp-go-search/store"
+)
+
+// stubService returns whichever results are currently set
+type stubService struct {
+	urls []string
+	err  error
+}
+
+// Search returns one result per URL
+func (s *stubService) Search(_ context.Context, _ string, _ string, _ int, _ bool) (*search.WebSearchResponse, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	var results []search.WebPageResult
+	for _, url := range s.urls {
+		results = append(results, search.WebPageResult{Name: "Title of " + url, URL: url})
+	}
+	return &search.WebSearchResponse{Data: search.Data{WebPages: search.WebPages{Value: results}}}, nil
+}
+
+// recordingNotifier records the hits it is asked to deliver
+type recordingNotifier struct {
+	hits []Hit
+	err  error
+}
+
+// Notify records the hit
+func (n *recordingNotifier) Notify(_ context.Context, hit Hit) error {
+	n.hits = append(n.hits, hit)
+	return n.err
+}
+
+func TestMonitor_Encrypted(t *testing.T) {
+	service := &stubService{urls: []string{"https://a.example/secret-project"}}
+	query := config.StandingQuery{Name: "releases", Query: "go release"}
+	dir := t.TempDir()
+	c, err := store.NewCipher(bytes.Repeat([]byte{7}, store.KeySize))
+	if err != nil {
+		t.Fatalf("NewCipher returned an error: %v", err)
+	}
+
+	m, err := NewEncrypted(service, []config.StandingQuery{query}, nil, dir, c)
+	if err != nil {
+		t.Fatalf("NewEncrypted returned an error: %v", err)
+	}
+	if _, err := m.Check(context.Background(), query); err != nil {
+		t.Fatalf("Check returned an error: %v", err)
+	}
+	raw, err := os.ReadFile(filepath.Join(dir, SeenFile))
+	if err != nil {
+		t.Fatalf("Failed to read monitor state: %v", err)
+	}
+	if bytes.Contains(raw, []byte("secret-project")) {
+		t.Error("Expected the monitor state to be encrypted")
+	}
+
+	if _, err := NewEncrypted(service, []config.StandingQuery{query}, nil, dir, c); err != nil {
+		t.Errorf("Expected the state to load with the key, got %v", err)
+	}
+	if _, err := New(service, []config.StandingQuery{query}, nil, dir); err == nil {
+		t.Error("Expected error loading encrypted state without a key, got nil")
+	}
+}
+
+func TestMonitor_Check(t *testing.T) {
+	service := &stubService{urls: []string{"https://a.example", "https://b.example"}}
+	notifier := &recordingNotifier{}
+	query := config.StandingQuery{Name: "releases", Query: "go release"}
+	dir := t.TempDir()
+
+	m, err := New(service, []config.StandingQuery{query}, notifier, dir)
+	if err != nil {
+		t.Fatalf("New returned an error: %v", err)
+	}
+
+	// The first run records a baseline without notifying
+	if hit, err := m.Check(context.Background(), query); hit != nil || err != nil {
+		t.Errorf("Expected no hit on the first run, got %v, %v", hit, err)
+	}
+
+	service.urls = []string{"https://c.example", "https://a.example", "https://b.example"}
+	hit, err := m.Check(context.Background(), query)
+	if err != nil {
+		t.Fatalf("Check returned an error: %v", err)
+	}
+	if hit == nil || len(hit.Results) != 1 || hit.Results[0].URL != "https://c.example" {
+		t.Fatalf("Expected a hit with the new result, got %+v", hit)
+	}
+	if len(notifier.hits) != 1 || notifier.hits[0].Name != "releases" {
+		t.Errorf("Expected the hit to be delivered, got %+v", notifier.hits)
+	}
+
+	// The results seen survive a restart
+	restarted, err := New(service, []config.StandingQuery{query}, notifier, dir)
+	if err != nil {
+		t.Fatalf("New returned an error: %v", err)
+	}
+	if hit, _ := restarted.Check(context.Background(), query); hit != nil {
+		t.Errorf("Expected no hit after a restart, got %+v", hit)
+	}
+
+	// Search and delivery failures are reported
+	service.err = fmt.Errorf("upstream down")
+	if _, err := m.Check(context.Background(), query); err == nil {
+		t.Error("Expected error for a failed search, got nil")
+	}
+	service.err = nil
+	service.urls = []string{"https://d.example"}
+	notifier.err = fmt.Errorf("webhook down")
+	if hit, err := m.Check(context.Background(), query); hit == nil || err == nil {
+		t.Errorf("Expected a hit and a delivery error, got %v, %v", hit, err)
+	}
+}
+
+func TestMonitor_Run(t *testing.T) {
+	service := &stubService{urls: []string{"https://a.example"}}
+	m, err := New(service, []config.StandingQuery{
+		{Name: "ok", Query: "q"},
+		{Name: "bad", Query: "q", Interval: "1s"},
+	}, nil, "")
+	if err != nil {
+		t.Fatalf("New returned an error: %v", err)
+	}
+	var errs []string
+	m.OnError = func(name string, _ error) { errs = append(errs, name) }
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	m.Run(ctx)
+
+	if len(errs) != 1 || errs[0] != "bad" {
+		t.Errorf("Expected an error for the invalid interval, got %v", errs)
+	}
+	if _, ok := m.seen["ok"]; !ok {
+		t.Error("Expected the valid query to run once before stopping")
+	}
+}
+
+// slowService blocks until the search is canceled
+type slowService struct{}
+
+// Search waits for ctx to end
+func (slowService) Search(ctx context.Context, _ string, _ string, _ int, _ bool) (*search.WebSearchResponse, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestMonitor_PoolJobTimeout(t *testing.T) {
+	query := config.StandingQuery{Name: "slow", Query: "q"}
+	m, err := New(slowService{}, []config.StandingQuery{query}, nil, "")
+	if err != nil {
+		t.Fatalf("New returned an error: %v", err)
+	}
+	m.SetPool(pool.New(1, 10*time.Millisecond))
+	var got error
+	m.OnError = func(_ string, err error) { got = err }
+
+	m.runOnce(context.Background(), query)
+
+	if !errors.Is(got, context.DeadlineExceeded) {
+		t.Errorf("Expected the check to time out, got %v", got)
+	}
+}
+
+// tenantService records the worker pool tenant searches are scheduled as
+type tenantService struct {
+	tenant string
+}
+
+// Search records the tenant of ctx
+func (s *tenantService) Search(ctx context.Context, _ string, _ string, _ int, _ bool) (*search.WebSearchResponse, error) {
+	s.tenant = pool.TenantOf(ctx)
+	return &search.WebSearchResponse{}, nil
+}
+
+func TestMonitor_RunsAsBackground(t *testing.T) {
+	service := &tenantService{}
+	m, err := New(service, []config.StandingQuery{{Name: "q", Query: "q"}}, nil, "")
+	if err != nil {
+		t.Fatalf("New returned an error: %v", err)
+	}
+	m.SetPool(pool.New(1, 0))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	m.Run(ctx)
+
+	if service.tenant != pool.TenantBackground {
+		t.Errorf("Expected checks scheduled as %q, got %q", pool.TenantBackground, service.tenant)
+	}
+}
@@ -0,0 +1,153 @@
+package monitor
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"com.moguyn/mcp-go-search/config"
+)
+
+// Webhook request headers. The signature is the hex HMAC-SHA256 of
+// "<timestamp>.<body>" keyed with the webhook secret, prefixed with "sha256=".
+const (
+	SignatureHeader = "X-Signature-256"
+	TimestampHeader = "X-Signature-Timestamp"
+)
+
+// Notifier delivers hits somewhere a person will see them
+type Notifier interface {
+	Notify(ctx context.Context, hit Hit) error
+}
+
+// Webhook posts hits to an HTTP endpoint, retrying failed deliveries with backoff
+type Webhook struct {
+	url        string
+	format     string
+	secret     string
+	maxRetries int
+	backoff    time.Duration
+	client     *http.Client
+	now        func() time.Time
+}
+
+// NewWebhook creates a webhook notifier from the configuration
+func NewWebhook(cfg *config.Config) *Webhook {
+	return &Webhook{
+		url:        cfg.WebhookURL,
+		format:     cfg.WebhookFormat,
+		secret:     cfg.WebhookSecret,
+		maxRetries: cfg.WebhookMaxRetries,
+		backoff:    time.Second,
+		client:     &http.Client{Timeout: 10 * time.Second},
+		now:        time.Now,
+	}
+}
+
+// Notify posts the hit. Network errors, 429 and 5xx responses are retried up
+// to maxRetries times, doubling the delay each time.
+func (w *Webhook) Notify(ctx context.Context, hit Hit) error {
+	body, err := w.payload(hit)
+	if err != nil {
+		return err
+	}
+
+	delay := w.backoff
+	for attempt := 0; ; attempt++ {
+		retry, err := w.post(ctx, body)
+		if err == nil {
+			return nil
+		}
+		if !retry || attempt >= w.maxRetries {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("webhook delivery canceled: %w", ctx.Err())
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+// post sends a single delivery attempt and reports whether a failure is worth retrying
+func (w *Webhook) post(ctx context.Context, body []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "BochaWebSearchMCPServer/1.0")
+	if w.secret != "" {
+		timestamp := strconv.FormatInt(w.now().Unix(), 10)
+		req.Header.Set(TimestampHeader, timestamp)
+		req.Header.Set(SignatureHeader, Sign(w.secret, timestamp, body))
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return true, fmt.Errorf("failed to send webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return retry, fmt.Errorf("webhook returned status code %d", resp.StatusCode)
+}
+
+// payload encodes the hit in the configured format
+func (w *Webhook) payload(hit Hit) ([]byte, error) {
+	var v interface{} = hit
+	if w.format == config.WebhookFormatSlack {
+		v = map[string]string{"text": slackText(hit)}
+	}
+	body, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode webhook payload: %w", err)
+	}
+	return body, nil
+}
+
+// slackTitle strips the characters that would end a title inside Slack's
+// link markup
+var slackTitle = strings.NewReplacer("<", "", ">", "", "|", "")
+
+// slackURL escapes a URL for Slack's link markup: the control characters
+// Slack expects as entities, and "|", which would end the URL early
+var slackURL = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", "|", "%7C")
+
+// slackText renders a hit as a Slack message using Slack's link markup
+func slackText(hit Hit) string {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("*%d new result(s) for %s* (%s)\n", len(hit.Results), hit.Name, hit.Query))
+	for _, result := range hit.Results {
+		title := result.Title
+		if title == "" {
+			title = result.URL
+		}
+		b.WriteString(fmt.Sprintf("• <%s|%s>\n", slackURL.Replace(result.URL), slackTitle.Replace(title)))
+	}
+	return b.String()
+}
+
+// Sign returns the signature of a webhook body sent at timestamp
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
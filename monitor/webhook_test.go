@@ -0,0 +1,125 @@
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"com.moguyn/mcp-go-search/config"
+)
+
+func testHit() Hit {
+	return Hit{
+		Name:    "releases",
+		Query:   "go release",
+		Results: []Result{{Title: "Go 1.25 <released>", URL: "https://go.dev/blog/go1.25"}},
+	}
+}
+
+func TestWebhook_SignsPayload(t *testing.T) {
+	var body []byte
+	var signature, timestamp string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		signature = r.Header.Get(SignatureHeader)
+		timestamp = r.Header.Get(TimestampHeader)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	webhook := NewWebhook(&config.Config{WebhookURL: server.URL, WebhookSecret: "s3cret", WebhookFormat: config.WebhookFormatGeneric})
+	webhook.now = func() time.Time { return time.Unix(1700000000, 0) }
+	if err := webhook.Notify(context.Background(), testHit()); err != nil {
+		t.Fatalf("Notify returned an error: %v", err)
+	}
+
+	var hit Hit
+	if err := json.Unmarshal(body, &hit); err != nil || hit.Name != "releases" || len(hit.Results) != 1 {
+		t.Errorf("Expected the hit as JSON, got %s", body)
+	}
+	if timestamp != "1700000000" {
+		t.Errorf("Expected timestamp 1700000000, got %q", timestamp)
+	}
+	if expected := Sign("s3cret", timestamp, body); signature != expected || !strings.HasPrefix(signature, "sha256=") {
+		t.Errorf("Expected signature %s, got %s", expected, signature)
+	}
+	if Sign("other", timestamp, body) == signature {
+		t.Error("Expected a different secret to produce a different signature")
+	}
+}
+
+func TestWebhook_Slack(t *testing.T) {
+	var message map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&message)
+	}))
+	defer server.Close()
+
+	webhook := NewWebhook(&config.Config{WebhookURL: server.URL, WebhookFormat: config.WebhookFormatSlack})
+	if err := webhook.Notify(context.Background(), testHit()); err != nil {
+		t.Fatalf("Notify returned an error: %v", err)
+	}
+	expected := "*1 new result(s) for releases* (go release)\n• <https://go.dev/blog/go1.25|Go 1.25 released>\n"
+	if message["text"] != expected {
+		t.Errorf("Expected %q, got %q", expected, message["text"])
+	}
+}
+
+func TestSlackText_EscapesURL(t *testing.T) {
+	// A URL can't end the link early or inject markup of its own
+	hit := Hit{
+		Name:    "releases",
+		Query:   "go release",
+		Results: []Result{{Title: "Go", URL: "https://example.com/?a=1&b=<!channel>|spoofed"}},
+	}
+	expected := "*1 new result(s) for releases* (go release)\n• <https://example.com/?a=1&amp;b=&lt;!channel&gt;%7Cspoofed|Go>\n"
+	if text := slackText(hit); text != expected {
+		t.Errorf("Expected %q, got %q", expected, text)
+	}
+}
+
+func TestWebhook_Retries(t *testing.T) {
+	var attempts atomic.Int32
+	status := http.StatusServiceUnavailable
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if attempts.Add(1) < 3 {
+			w.WriteHeader(status)
+		}
+	}))
+	defer server.Close()
+
+	webhook := NewWebhook(&config.Config{WebhookURL: server.URL, WebhookMaxRetries: 3})
+	webhook.backoff = time.Millisecond
+	if err := webhook.Notify(context.Background(), testHit()); err != nil {
+		t.Fatalf("Expected delivery to succeed after retries, got %v", err)
+	}
+	if attempts.Load() != 3 {
+		t.Errorf("Expected 3 attempts, got %d", attempts.Load())
+	}
+
+	// Client errors are not retried
+	attempts.Store(0)
+	status = http.StatusBadRequest
+	if err := webhook.Notify(context.Background(), testHit()); err == nil {
+		t.Error("Expected error for a rejected delivery, got nil")
+	}
+	if attempts.Load() != 1 {
+		t.Errorf("Expected 1 attempt, got %d", attempts.Load())
+	}
+
+	// Retries give up after maxRetries
+	attempts.Store(-10)
+	status = http.StatusInternalServerError
+	if err := webhook.Notify(context.Background(), testHit()); err == nil {
+		t.Error("Expected error after exhausting retries, got nil")
+	}
+	if attempts.Load() != -6 {
+		t.Errorf("Expected 4 attempts, got %d", attempts.Load()+10)
+	}
+}
@@ -0,0 +1,115 @@
+// Package params validates and normalizes search parameters. The MCP tool
+// layer and the providers share it so clamping and validation behave the same
+// everywhere; provider-specific limits are passed in as Limits.
+package params
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// DefaultCount is the number of results returned when the client does not ask for a count
+	DefaultCount = 10
+	// MaxCount is the largest count any provider accepts
+	MaxCount = 50
+	// MaxQueryLength is the maximum query length in bytes
+	MaxQueryLength = 1000
+	// DefaultFreshness places no limit on result age
+	DefaultFreshness = "noLimit"
+)
+
+// Freshness lists every freshness value understood by the server
+var Freshness = []string{"noLimit", "day", "week", "month", "oneYear"}
+
+// Search holds the parameters of a single search
+type Search struct {
+	Query     string
+	Freshness string
+	Count     int
+	Summary   bool
+}
+
+// Limits are the provider-specific bounds applied when normalizing parameters
+type Limits struct {
+	// MaxCount is the largest count the provider accepts; zero means MaxCount
+	MaxCount int
+}
+
+// DefaultLimits returns the limits of a provider accepting the full parameter range
+func DefaultLimits() Limits {
+	return Limits{MaxCount: MaxCount}
+}
+
+// Adjustments reports which parameters Normalize had to change
+type Adjustments struct {
+	QueryTruncated bool
+	CountClamped   bool
+}
+
+// Merge returns the adjustments made by either a or other
+func (a Adjustments) Merge(other Adjustments) Adjustments {
+	return Adjustments{
+		QueryTruncated: a.QueryTruncated || other.QueryTruncated,
+		CountClamped:   a.CountClamped || other.CountClamped,
+	}
+}
+
+// Normalize validates p and brings it within limits. An empty freshness becomes
+// DefaultFreshness; the query is truncated and the count clamped rather than rejected.
+func Normalize(p Search, limits Limits) (Search, Adjustments, error) {
+	var adj Adjustments
+	if p.Query == "" {
+		return p, adj, fmt.Errorf("search query cannot be empty")
+	}
+	p.Query, adj.QueryTruncated = TruncateQuery(p.Query)
+
+	if p.Freshness == "" {
+		p.Freshness = DefaultFreshness
+	}
+	if err := ValidateFreshness(p.Freshness); err != nil {
+		return p, adj, err
+	}
+
+	p.Count, adj.CountClamped = ClampCount(p.Count, limits.MaxCount)
+	return p, adj, nil
+}
+
+// ValidateFreshness returns an error if freshness is not a known value
+func ValidateFreshness(freshness string) error {
+	for _, f := range Freshness {
+		if f == freshness {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid freshness value: %q, must be one of: %s", freshness, strings.Join(Freshness, ", "))
+}
+
+// ClampCount limits count to the range 1..maxCount and reports whether it changed.
+// A maxCount of zero or above MaxCount means MaxCount.
+func ClampCount(count, maxCount int) (int, bool) {
+	if maxCount <= 0 || maxCount > MaxCount {
+		maxCount = MaxCount
+	}
+	if count < 1 {
+		return 1, true
+	}
+	if count > maxCount {
+		return maxCount, true
+	}
+	return count, false
+}
+
+// TruncateQuery shortens query to MaxQueryLength bytes without splitting a
+// UTF-8 sequence, and reports whether it was shortened
+func TruncateQuery(query string) (string, bool) {
+	if len(query) <= MaxQueryLength {
+		return query, false
+	}
+	cut := MaxQueryLength
+	for cut > 0 && !utf8.RuneStart(query[cut]) {
+		cut--
+	}
+	return query[:cut], true
+}
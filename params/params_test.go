@@ -0,0 +1,75 @@
+package params
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	p, adj, err := Normalize(Search{Query: "golang", Count: 80}, Limits{MaxCount: 20})
+	if err != nil {
+		t.Fatalf("Normalize returned an error: %v", err)
+	}
+	if p.Freshness != DefaultFreshness {
+		t.Errorf("Expected freshness %q, got %q", DefaultFreshness, p.Freshness)
+	}
+	if p.Count != 20 || !adj.CountClamped {
+		t.Errorf("Expected count clamped to 20, got %d (clamped=%v)", p.Count, adj.CountClamped)
+	}
+	if adj.QueryTruncated {
+		t.Error("Expected query not to be truncated")
+	}
+
+	p, adj, err = Normalize(Search{Query: strings.Repeat("a", 1200), Freshness: "day", Count: 5}, DefaultLimits())
+	if err != nil {
+		t.Fatalf("Normalize returned an error: %v", err)
+	}
+	if len(p.Query) != MaxQueryLength || !adj.QueryTruncated {
+		t.Errorf("Expected query truncated to %d bytes, got %d", MaxQueryLength, len(p.Query))
+	}
+	if p.Count != 5 || adj.CountClamped {
+		t.Errorf("Expected count 5 unchanged, got %d", p.Count)
+	}
+
+	if _, _, err := Normalize(Search{}, DefaultLimits()); err == nil {
+		t.Error("Expected error for empty query, got nil")
+	}
+	if _, _, err := Normalize(Search{Query: "a", Freshness: "hour"}, DefaultLimits()); err == nil {
+		t.Error("Expected error for invalid freshness, got nil")
+	}
+}
+
+func TestClampCount(t *testing.T) {
+	testCases := []struct {
+		count, maxCount, expected int
+		clamped                   bool
+	}{
+		{10, 50, 10, false},
+		{0, 50, 1, true},
+		{-3, 50, 1, true},
+		{60, 50, 50, true},
+		{30, 20, 20, true},
+		{60, 0, 50, true},
+		{60, 100, 50, true},
+	}
+
+	for _, tc := range testCases {
+		got, clamped := ClampCount(tc.count, tc.maxCount)
+		if got != tc.expected || clamped != tc.clamped {
+			t.Errorf("ClampCount(%d, %d): expected %d/%v, got %d/%v", tc.count, tc.maxCount, tc.expected, tc.clamped, got, clamped)
+		}
+	}
+}
+
+func TestTruncateQuery(t *testing.T) {
+	// A multi-byte rune straddling the limit is dropped whole
+	query := strings.Repeat("a", MaxQueryLength-1) + "é"
+	got, truncated := TruncateQuery(query)
+	if !truncated || got != strings.Repeat("a", MaxQueryLength-1) {
+		t.Errorf("Expected query cut before the split rune, got length %d", len(got))
+	}
+
+	if got, truncated := TruncateQuery("short"); got != "short" || truncated {
+		t.Errorf("Expected short query unchanged, got %q", got)
+	}
+}
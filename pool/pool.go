@@ -0,0 +1,234 @@
+// Package pool runs concurrent jobs on a shared, bounded set of workers, so
+// batch searches, provider fan-out and page fetches together never exceed a
+// configured number of in-flight calls
+package pool
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// DefaultSize is the number of jobs a pool runs at once unless configured
+const DefaultSize = 8
+
+// Tenants the server's own work is scheduled as
+const (
+	// TenantInteractive is work a client is waiting on, such as a tool call
+	TenantInteractive = "interactive"
+	// TenantBackground is work nobody is waiting on, such as standing queries.
+	// It yields to every other tenant when jobs are waiting.
+	TenantBackground = "background"
+)
+
+// tenantKey is the context key for the tenant a job is scheduled as
+type tenantKey struct{}
+
+// WithTenant returns a context whose jobs are scheduled as tenant, such as a
+// session or a kind of work
+func WithTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenant)
+}
+
+// TenantOf returns the tenant jobs run with ctx are scheduled as, or "" if none
+func TenantOf(ctx context.Context) string {
+	tenant, _ := ctx.Value(tenantKey{}).(string)
+	return tenant
+}
+
+// jobKey marks a context as that of a job running on the pool it holds
+type jobKey struct{}
+
+// waiter is a job waiting for a free worker
+type waiter struct {
+	ready   chan struct{}
+	granted bool
+}
+
+// Pool bounds the number of jobs running at once across every group started
+// from it. A nil Pool runs jobs without a limit or timeout.
+//
+// While every worker is busy, waiting jobs queue per tenant and freed workers
+// go to the tenants in turn, one job each, so a tenant queueing many jobs
+// doesn't delay the jobs of the others. Waiting TenantBackground jobs only get
+// a worker once no other tenant's job waits.
+type Pool struct {
+	size       int
+	jobTimeout time.Duration
+
+	mu      sync.Mutex
+	running int
+	queues  map[string][]*waiter
+	// turns lists the tenants other than TenantBackground with waiting jobs,
+	// the one whose turn it is first
+	turns []string
+}
+
+// New creates a pool running at most size jobs at once, each canceled after
+// jobTimeout. A size below 1 means DefaultSize and a zero timeout means none.
+func New(size int, jobTimeout time.Duration) *Pool {
+	if size < 1 {
+		size = DefaultSize
+	}
+	return &Pool{
+		size:       size,
+		jobTimeout: jobTimeout,
+		queues:     make(map[string][]*waiter),
+	}
+}
+
+// Size returns the number of jobs the pool runs at once
+func (p *Pool) Size() int {
+	if p == nil {
+		return 0
+	}
+	return p.size
+}
+
+// Do runs a single job on the pool, waiting for a free worker first. A job
+// started from within another job of the pool, such as a provider fan-out
+// inside a batch query, runs on its parent's worker instead: waiting for a
+// second worker while holding one could deadlock a full pool.
+func (p *Pool) Do(ctx context.Context, job func(ctx context.Context) error) error {
+	if p == nil {
+		return job(ctx)
+	}
+	if parent, _ := ctx.Value(jobKey{}).(*Pool); parent == p {
+		return job(ctx)
+	}
+	if err := p.acquire(ctx); err != nil {
+		return err
+	}
+	defer p.release()
+	ctx = context.WithValue(ctx, jobKey{}, p)
+
+	if p.jobTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.jobTimeout)
+		defer cancel()
+	}
+	return job(ctx)
+}
+
+// acquire takes a free worker, queueing as the context's tenant while none is
+func (p *Pool) acquire(ctx context.Context) error {
+	p.mu.Lock()
+	if p.running < p.size && len(p.turns) == 0 && len(p.queues[TenantBackground]) == 0 {
+		p.running++
+		p.mu.Unlock()
+		return nil
+	}
+	tenant := TenantOf(ctx)
+	w := &waiter{ready: make(chan struct{})}
+	if len(p.queues[tenant]) == 0 && tenant != TenantBackground {
+		p.turns = append(p.turns, tenant)
+	}
+	p.queues[tenant] = append(p.queues[tenant], w)
+	p.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		return nil
+	case <-ctx.Done():
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if w.granted {
+		// The worker was handed over as the job gave up; pass it on
+		p.handOver()
+		return ctx.Err()
+	}
+	p.dequeue(tenant, w)
+	return ctx.Err()
+}
+
+// release frees a job's worker, handing it to the tenant whose turn it is
+func (p *Pool) release() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.handOver()
+}
+
+// handOver gives a worker that just became free to the next waiting job, or
+// returns it to the pool if none is waiting. The caller must hold p.mu.
+func (p *Pool) handOver() {
+	if len(p.turns) == 0 {
+		if len(p.queues[TenantBackground]) > 0 {
+			p.grant(TenantBackground)
+		} else {
+			p.running--
+		}
+		return
+	}
+	tenant := p.turns[0]
+	p.grant(tenant)
+	p.turns = p.turns[1:]
+	if len(p.queues[tenant]) > 0 {
+		p.turns = append(p.turns, tenant)
+	}
+}
+
+// grant hands a worker to the tenant's first waiting job. The caller must hold p.mu.
+func (p *Pool) grant(tenant string) {
+	queue := p.queues[tenant]
+	w := queue[0]
+	if len(queue) == 1 {
+		delete(p.queues, tenant)
+	} else {
+		p.queues[tenant] = queue[1:]
+	}
+	w.granted = true
+	close(w.ready)
+}
+
+// dequeue removes a waiting job that gave up. The caller must hold p.mu.
+func (p *Pool) dequeue(tenant string, w *waiter) {
+	queue := p.queues[tenant]
+	for i, queued := range queue {
+		if queued == w {
+			p.queues[tenant] = append(queue[:i:i], queue[i+1:]...)
+			break
+		}
+	}
+	if len(p.queues[tenant]) > 0 {
+		return
+	}
+	delete(p.queues, tenant)
+	for i, t := range p.turns {
+		if t == tenant {
+			p.turns = append(p.turns[:i:i], p.turns[i+1:]...)
+			break
+		}
+	}
+}
+
+// Group is a set of related jobs run on a pool, such as the queries of one
+// batch. The first job to fail cancels the others.
+type Group struct {
+	pool  *Pool
+	group *errgroup.Group
+	ctx   context.Context
+}
+
+// Group starts a group of jobs on the pool. The returned context is canceled
+// when a job fails or Wait returns.
+func (p *Pool) Group(ctx context.Context) (*Group, context.Context) {
+	group, ctx := errgroup.WithContext(ctx)
+	return &Group{pool: p, group: group, ctx: ctx}, ctx
+}
+
+// Go runs job on the pool as part of the group. Jobs that should not cancel
+// their siblings report their failure some other way and return nil.
+func (g *Group) Go(job func(ctx context.Context) error) {
+	g.group.Go(func() error {
+		return g.pool.Do(g.ctx, job)
+	})
+}
+
+// Wait blocks until every job in the group has finished and returns the first error
+func (g *Group) Wait() error {
+	return g.group.Wait()
+}
@@ -0,0 +1,309 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPool_BoundsConcurrency(t *testing.T) {
+	p := New(2, 0)
+	var running, peak int32
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_ = p.Do(context.Background(), func(ctx context.Context) error {
+				now := atomic.AddInt32(&running, 1)
+				for {
+					old := atomic.LoadInt32(&peak)
+					if now <= old || atomic.CompareAndSwapInt32(&peak, old, now) {
+						break
+					}
+				}
+				time.Sleep(5 * time.Millisecond)
+				atomic.AddInt32(&running, -1)
+				return nil
+			})
+		}()
+	}
+	wg.Wait()
+
+	if peak > 2 {
+		t.Errorf("Expected at most 2 jobs at once, got %d", peak)
+	}
+}
+
+func TestPool_SharedAcrossGroups(t *testing.T) {
+	p := New(1, 0)
+	var running, peak int32
+	job := func(ctx context.Context) error {
+		if now := atomic.AddInt32(&running, 1); now > atomic.LoadInt32(&peak) {
+			atomic.StoreInt32(&peak, now)
+		}
+		time.Sleep(2 * time.Millisecond)
+		atomic.AddInt32(&running, -1)
+		return nil
+	}
+
+	first, _ := p.Group(context.Background())
+	second, _ := p.Group(context.Background())
+	for i := 0; i < 3; i++ {
+		first.Go(job)
+		second.Go(job)
+	}
+	if err := first.Wait(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := second.Wait(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if peak != 1 {
+		t.Errorf("Expected one job at a time across groups, got %d", peak)
+	}
+}
+
+func TestPool_JobTimeout(t *testing.T) {
+	p := New(1, 10*time.Millisecond)
+	err := p.Do(context.Background(), func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected deadline exceeded, got %v", err)
+	}
+}
+
+func TestPool_WaitingJobCanceled(t *testing.T) {
+	p := New(1, 0)
+	release := make(chan struct{})
+	go func() {
+		_ = p.Do(context.Background(), func(ctx context.Context) error {
+			<-release
+			return nil
+		})
+	}()
+	defer close(release)
+	time.Sleep(5 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	ran := false
+	err := p.Do(ctx, func(ctx context.Context) error {
+		ran = true
+		return nil
+	})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected deadline exceeded, got %v", err)
+	}
+	if ran {
+		t.Error("Expected the job not to run while the pool was full")
+	}
+}
+
+func TestGroup_FirstErrorCancelsOthers(t *testing.T) {
+	p := New(4, 0)
+	group, ctx := p.Group(context.Background())
+	failure := errors.New("boom")
+	group.Go(func(ctx context.Context) error {
+		return failure
+	})
+	group.Go(func(ctx context.Context) error {
+		<-ctx.Done()
+		return nil
+	})
+
+	if err := group.Wait(); !errors.Is(err, failure) {
+		t.Errorf("Expected %v, got %v", failure, err)
+	}
+	if ctx.Err() == nil {
+		t.Error("Expected the group context to be canceled")
+	}
+}
+
+func TestNew_DefaultSize(t *testing.T) {
+	if size := New(0, 0).Size(); size != DefaultSize {
+		t.Errorf("Expected size %d, got %d", DefaultSize, size)
+	}
+}
+
+func TestPool_Nil(t *testing.T) {
+	var p *Pool
+	if p.Size() != 0 {
+		t.Errorf("Expected size 0, got %d", p.Size())
+	}
+	called := false
+	if err := p.Do(context.Background(), func(ctx context.Context) error {
+		called = true
+		return nil
+	}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !called {
+		t.Error("Expected the job to run")
+	}
+}
+
+// queueJobs occupies the pool's only worker, queues the jobs of tenants in
+// order, then frees the worker and returns the order the jobs ran in
+func queueJobs(t *testing.T, p *Pool, tenants []string) []string {
+	t.Helper()
+	release := make(chan struct{})
+	started := make(chan struct{})
+	go func() {
+		_ = p.Do(context.Background(), func(ctx context.Context) error {
+			close(started)
+			<-release
+			return nil
+		})
+	}()
+	<-started
+
+	var mu sync.Mutex
+	var order []string
+	var wg sync.WaitGroup
+	for i, tenant := range tenants {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_ = p.Do(WithTenant(context.Background(), tenant), func(ctx context.Context) error {
+				mu.Lock()
+				order = append(order, tenant)
+				mu.Unlock()
+				return nil
+			})
+		}()
+		// Wait for the job to queue so the queueing order is known
+		for {
+			p.mu.Lock()
+			queued := 0
+			for _, queue := range p.queues {
+				queued += len(queue)
+			}
+			p.mu.Unlock()
+			if queued == i+1 {
+				break
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+	close(release)
+	wg.Wait()
+	return order
+}
+
+func TestPool_FairAcrossTenants(t *testing.T) {
+	// A batch queued first doesn't hold back the job of another tenant
+	order := queueJobs(t, New(1, 0), []string{"batch", "batch", "batch", "batch", "chat"})
+	expected := []string{"batch", "chat", "batch", "batch", "batch"}
+	if strings.Join(order, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected jobs to run in turns %v, got %v", expected, order)
+	}
+
+	// However many jobs one session queues, another session's job runs next
+	batch := "session:batch"
+	tenants := []string{batch, batch, batch, batch, batch, batch, batch, batch, TenantInteractive, "session:chat"}
+	order = queueJobs(t, New(1, 0), tenants)
+	expected = []string{batch, TenantInteractive, "session:chat", batch, batch, batch, batch, batch, batch, batch}
+	if strings.Join(order, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected the interactive and chat jobs to run before the rest of the batch %v, got %v", expected, order)
+	}
+}
+
+func TestPool_NestedJobsRunOnParentWorker(t *testing.T) {
+	// Every worker is held by a job that starts a group of its own; waiting
+	// for another worker would never end without a job timeout
+	p := New(2, 0)
+	outer, _ := p.Group(context.Background())
+	var inner atomic.Int32
+	for i := 0; i < 2; i++ {
+		outer.Go(func(ctx context.Context) error {
+			group, _ := p.Group(ctx)
+			for j := 0; j < 3; j++ {
+				group.Go(func(ctx context.Context) error {
+					inner.Add(1)
+					return nil
+				})
+			}
+			return group.Wait()
+		})
+	}
+	done := make(chan error, 1)
+	go func() { done <- outer.Wait() }()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Wait returned an error: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected nested jobs to run on their parent's worker")
+	}
+	if n := inner.Load(); n != 6 {
+		t.Errorf("Expected 6 nested jobs, got %d", n)
+	}
+
+	// Another pool's jobs still wait for a worker of their own
+	other := New(1, 0)
+	_ = p.Do(context.Background(), func(ctx context.Context) error {
+		return other.Do(ctx, func(ctx context.Context) error {
+			if ctx.Value(jobKey{}) != other {
+				t.Error("Expected the job to be marked as running on the other pool")
+			}
+			return nil
+		})
+	})
+}
+
+func TestPool_BackgroundYields(t *testing.T) {
+	// Background jobs queued first still wait for every other tenant's jobs
+	order := queueJobs(t, New(1, 0), []string{TenantBackground, TenantBackground, TenantBackground, "chat", "batch", "chat"})
+	expected := []string{"chat", "batch", "chat", TenantBackground, TenantBackground, TenantBackground}
+	if strings.Join(order, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected background jobs to run last %v, got %v", expected, order)
+	}
+}
+
+func TestPool_CanceledWaiterLeavesQueue(t *testing.T) {
+	p := New(1, 0)
+	release := make(chan struct{})
+	started := make(chan struct{})
+	go func() {
+		_ = p.Do(context.Background(), func(ctx context.Context) error {
+			close(started)
+			<-release
+			return nil
+		})
+	}()
+	<-started
+
+	ctx, cancel := context.WithTimeout(WithTenant(context.Background(), "batch"), 5*time.Millisecond)
+	defer cancel()
+	if err := p.Do(ctx, func(ctx context.Context) error { return nil }); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected deadline exceeded, got %v", err)
+	}
+	close(release)
+
+	// The worker is free again once the running job ends
+	deadline := time.Now().Add(time.Second)
+	for {
+		p.mu.Lock()
+		running, turns := p.running, len(p.turns)
+		p.mu.Unlock()
+		if running == 0 && turns == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected an idle pool, got %d running and %d tenants waiting", running, turns)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if TenantOf(context.Background()) != "" || TenantOf(ctx) != "batch" {
+		t.Error("Expected TenantOf to return the tenant set with WithTenant")
+	}
+}
@@ -0,0 +1,99 @@
+// Package privacy removes personal data from text before it is recorded
+package privacy
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Kinds of personal data the scrubber recognizes
+const (
+	// KindEmail matches email addresses
+	KindEmail = "email"
+	// KindPhone matches international (+...) and North American phone numbers
+	// and Chinese mobile numbers
+	KindPhone = "phone"
+	// KindID matches US social security numbers, Chinese resident ID numbers and
+	// payment card numbers
+	KindID = "id"
+)
+
+// Kinds lists every built-in kind, in the order they are applied
+var Kinds = []string{KindEmail, KindID, KindPhone}
+
+// builtinPatterns are the expressions matching each kind
+var builtinPatterns = map[string]*regexp.Regexp{
+	KindEmail: regexp.MustCompile(`(?i)[a-z0-9._%+-]+@[a-z0-9.-]+\.[a-z]{2,}`),
+	KindID: regexp.MustCompile(`\b\d{3}-\d{2}-\d{4}\b` +
+		`|\b\d{17}[\dXx]\b` +
+		`|\b\d{4}[ -]?\d{4}[ -]?\d{4}[ -]?\d{1,7}\b`),
+	KindPhone: regexp.MustCompile(`\+\d[\d\s().-]{6,}\d` +
+		`|\(?\b\d{3}\)?[\s.-]\d{3}[\s.-]\d{4}\b` +
+		`|\b1[3-9]\d{9}\b`),
+}
+
+// scrubRule replaces matches of a pattern with a placeholder
+type scrubRule struct {
+	pattern     *regexp.Regexp
+	placeholder string
+}
+
+// Scrubber replaces personal data in text with placeholders such as [email].
+// A nil Scrubber returns text unchanged.
+type Scrubber struct {
+	rules []scrubRule
+}
+
+// NewScrubber creates a scrubber for the given built-in kinds and extra
+// regular expressions, whose matches are replaced with [redacted]
+func NewScrubber(kinds []string, patterns []string) (*Scrubber, error) {
+	for _, wanted := range kinds {
+		if err := ValidateKind(wanted); err != nil {
+			return nil, err
+		}
+	}
+
+	s := &Scrubber{}
+	for _, kind := range Kinds {
+		for _, wanted := range kinds {
+			if strings.EqualFold(strings.TrimSpace(wanted), kind) {
+				s.rules = append(s.rules, scrubRule{builtinPatterns[kind], "[" + kind + "]"})
+				break
+			}
+		}
+	}
+	for i, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid PII pattern %d %q: %w", i, pattern, err)
+		}
+		s.rules = append(s.rules, scrubRule{re, "[redacted]"})
+	}
+	return s, nil
+}
+
+// ValidateKind returns an error unless kind is a built-in kind or "none"
+func ValidateKind(kind string) error {
+	kind = strings.ToLower(strings.TrimSpace(kind))
+	if kind == "none" {
+		return nil
+	}
+	for _, known := range Kinds {
+		if kind == known {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown PII kind %q, must be one of: %s, none", kind, strings.Join(Kinds, ", "))
+}
+
+// Scrub returns text with personal data replaced
+func (s *Scrubber) Scrub(text string) string {
+	if s == nil {
+		return text
+	}
+	for _, rule := range s.rules {
+		text = rule.pattern.ReplaceAllString(text, rule.placeholder)
+	}
+	return text
+}
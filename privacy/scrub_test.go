@@ -0,0 +1,70 @@
+package privacy
+
+import "testing"
+
+func TestScrubber(t *testing.T) {
+	scrubber, err := NewScrubber(Kinds, nil)
+	if err != nil {
+		t.Fatalf("Failed to create scrubber: %v", err)
+	}
+
+	testCases := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"email", "reset password for Jane.Doe+work@example.co.uk", "reset password for [email]"},
+		{"international phone", "who called +44 20 7946 0958", "who called [phone]"},
+		{"north american phone", "owner of (555) 123-4567", "owner of [phone]"},
+		{"chinese mobile", "13812345678 归属地", "[phone] 归属地"},
+		{"ssn", "ssn 123-45-6789 lookup", "ssn [id] lookup"},
+		{"resident id", "身份证 11010519491231002X", "身份证 [id]"},
+		{"card number", "card 4111 1111 1111 1111 declined", "card [id] declined"},
+		{"years", "best laptops 2024 2025", "best laptops 2024 2025"},
+		{"plain", "golang generics", "golang generics"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if result := scrubber.Scrub(tc.input); result != tc.expected {
+				t.Errorf("Expected '%s', got '%s'", tc.expected, result)
+			}
+		})
+	}
+}
+
+func TestScrubber_SelectedKindsAndPatterns(t *testing.T) {
+	scrubber, err := NewScrubber([]string{"email"}, []string{`EMP-\d{6}`})
+	if err != nil {
+		t.Fatalf("Failed to create scrubber: %v", err)
+	}
+
+	result := scrubber.Scrub("EMP-123456 jane@example.com 123-45-6789")
+	if result != "[redacted] [email] 123-45-6789" {
+		t.Errorf("Expected only email and custom pattern scrubbed, got %q", result)
+	}
+}
+
+func TestScrubber_None(t *testing.T) {
+	scrubber, err := NewScrubber([]string{"none"}, nil)
+	if err != nil {
+		t.Fatalf("Failed to create scrubber: %v", err)
+	}
+	if result := scrubber.Scrub("jane@example.com"); result != "jane@example.com" {
+		t.Errorf("Expected query unchanged, got %q", result)
+	}
+
+	var nilScrubber *Scrubber
+	if result := nilScrubber.Scrub("jane@example.com"); result != "jane@example.com" {
+		t.Errorf("Expected nil scrubber to leave query unchanged, got %q", result)
+	}
+}
+
+func TestNewScrubber_Invalid(t *testing.T) {
+	if _, err := NewScrubber([]string{"passport"}, nil); err == nil {
+		t.Error("Expected error for unknown kind, got nil")
+	}
+	if _, err := NewScrubber(nil, []string{"(unclosed"}); err == nil {
+		t.Error("Expected error for invalid pattern, got nil")
+	}
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"com.moguyn/mcp-go-search/config"
+	"com.moguyn/mcp-go-search/search"
+)
+
+// readinessQuery is the cheap query used to validate the upstream configuration
+const readinessQuery = "weather"
+
+// readinessTimeout bounds a single readiness check
+var readinessTimeout = 15 * time.Second
+
+// readinessRetryInterval is the initial delay between readiness checks in gate mode
+var readinessRetryInterval = 5 * time.Second
+
+// maxReadinessRetryInterval caps the backoff between readiness checks
+const maxReadinessRetryInterval = time.Minute
+
+// checkUpstream performs a single cheap search to verify that the provider
+// is reachable and accepts the configured credentials
+func checkUpstream(ctx context.Context, svc search.Service) error {
+	ctx, cancel := context.WithTimeout(ctx, readinessTimeout)
+	defer cancel()
+
+	if _, err := svc.Search(ctx, readinessQuery, "noLimit", 1, false); err != nil {
+		return fmt.Errorf("upstream readiness check failed: %w", err)
+	}
+	return nil
+}
+
+// awaitReadiness runs the configured startup check. In fail mode an unsuccessful
+// check is returned as an error. In gate mode ready is called once a check
+// succeeds, retrying in the background with backoff until ctx is canceled.
+// Otherwise ready is called immediately.
+func awaitReadiness(ctx context.Context, mode string, svc search.Service, logger *Logger, ready func()) error {
+	switch mode {
+	case config.StartupCheckFail:
+		if err := checkUpstream(ctx, svc); err != nil {
+			return err
+		}
+		ready()
+	case config.StartupCheckGate:
+		go func() {
+			interval := readinessRetryInterval
+			for {
+				err := checkUpstream(ctx, svc)
+				if err == nil {
+					logger.Info("Upstream readiness check passed, advertising tools", nil)
+					ready()
+					return
+				}
+				logger.Error("Upstream not ready, tools withheld", err, map[string]interface{}{
+					"retry_in": interval.String(),
+				})
+
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(interval):
+				}
+				interval *= 2
+				if interval > maxReadinessRetryInterval {
+					interval = maxReadinessRetryInterval
+				}
+			}
+		}()
+	default:
+		ready()
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"com.moguyn/mcp-go-search/config"
+	"com.moguyn/mcp-go-search/search"
+)
+
+// flakyService fails a fixed number of searches before succeeding
+type flakyService struct {
+	mu       sync.Mutex
+	failures int
+	calls    int
+}
+
+// Search fails until the configured number of failures has been returned
+func (s *flakyService) Search(_ context.Context, _ string, _ string, _ int, _ bool) (*search.WebSearchResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls++
+	if s.calls <= s.failures {
+		return nil, errors.New("invalid api key")
+	}
+	return &search.WebSearchResponse{}, nil
+}
+
+func TestAwaitReadiness_Off(t *testing.T) {
+	svc := &flakyService{failures: 1}
+	ready := false
+	if err := awaitReadiness(context.Background(), config.StartupCheckOff, svc, NewLogger("test"), func() { ready = true }); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !ready || svc.calls != 0 {
+		t.Errorf("Expected immediate readiness without upstream calls, got ready=%v calls=%d", ready, svc.calls)
+	}
+}
+
+func TestAwaitReadiness_Fail(t *testing.T) {
+	ready := false
+	err := awaitReadiness(context.Background(), config.StartupCheckFail, &flakyService{failures: 1}, NewLogger("test"), func() { ready = true })
+	if err == nil || ready {
+		t.Errorf("Expected failure without readiness, got err=%v ready=%v", err, ready)
+	}
+
+	err = awaitReadiness(context.Background(), config.StartupCheckFail, &flakyService{}, NewLogger("test"), func() { ready = true })
+	if err != nil || !ready {
+		t.Errorf("Expected readiness after a successful check, got err=%v ready=%v", err, ready)
+	}
+}
+
+func TestAwaitReadiness_Gate(t *testing.T) {
+	origInterval := readinessRetryInterval
+	readinessRetryInterval = 10 * time.Millisecond
+	defer func() { readinessRetryInterval = origInterval }()
+
+	svc := &flakyService{failures: 2}
+	ready := make(chan struct{})
+	err := awaitReadiness(context.Background(), config.StartupCheckGate, svc, NewLogger("test"), func() { close(ready) })
+	if err != nil {
+		t.Fatalf("Expected no error in gate mode, got %v", err)
+	}
+
+	select {
+	case <-ready:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for readiness")
+	}
+
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+	if svc.calls != 3 {
+		t.Errorf("Expected 3 checks, got %d", svc.calls)
+	}
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"com.moguyn/mcp-go-search/search/providers/bocha"
+)
+
+// regionProbeTimeout bounds the latency probe of the regional endpoints
+var regionProbeTimeout = 10 * time.Second
+
+// probeRegions measures the latency of the regional endpoints so searches go
+// to the fastest one first, and logs the result
+func probeRegions(svc *bocha.Service, logger *Logger) {
+	ctx, cancel := context.WithTimeout(context.Background(), regionProbeTimeout)
+	defer cancel()
+
+	regions := svc.ProbeRegions(ctx)
+	latencies := make([]string, 0, len(regions))
+	for _, region := range regions {
+		if region.Error != "" {
+			latencies = append(latencies, region.Endpoint+" unreachable")
+			continue
+		}
+		latencies = append(latencies, fmt.Sprintf("%s %s", region.Endpoint, region.Latency.Round(time.Millisecond)))
+	}
+	logger.Info("Probed Bocha regions", map[string]interface{}{
+		"selected": regions[0].Endpoint,
+		"regions":  strings.Join(latencies, ", "),
+	})
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"com.moguyn/mcp-go-search/config"
+	"com.moguyn/mcp-go-search/search/providers/bocha"
+)
+
+func TestProbeRegions(t *testing.T) {
+	up := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer up.Close()
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+
+	// The reachable region is searched first, even when listed last
+	service := bocha.NewWithConfig(&config.Config{
+		BochaAPIKey:  "test-api-key",
+		BochaRegions: []string{down.URL, up.URL},
+		HTTPTimeout:  5 * time.Second,
+	})
+	probeRegions(service, NewLogger("test"))
+	regions := service.Regions()
+	if len(regions) != 2 || regions[0].Endpoint != up.URL || regions[1].Error == "" {
+		t.Errorf("Expected the reachable region first and the unreachable one last, got %+v", regions)
+	}
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"com.moguyn/mcp-go-search/config"
+	"com.moguyn/mcp-go-search/privacy"
+	"com.moguyn/mcp-go-search/search"
+	"com.moguyn/mcp-go-search/stats"
+)
+
+// reloadTargets are the running components whose settings can change without a restart
+type reloadTargets struct {
+	// keys are the active providers whose API key can be rotated, by name
+	keys      map[string]search.KeyRotator
+	rewriting *search.RewritingService
+	boosting  *search.BoostingService
+	filtering *search.FilteringService
+	collector *stats.Collector
+}
+
+// reloadConfig re-reads the configuration and applies the non-structural
+// settings to the running components. Structural settings such as the
+// provider, transports and tool profiles only change on restart.
+func reloadConfig(logger *Logger, current *config.Config, targets reloadTargets) {
+	cfg := config.New()
+	if err := cfg.Validate(); err != nil {
+		logger.Error("Reload rejected, keeping current configuration", err, nil)
+		return
+	}
+
+	rewriter, err := search.NewRewriter(cfg.RewriteRules)
+	if err != nil {
+		logger.Error("Reload rejected, keeping current configuration", err, nil)
+		return
+	}
+
+	booster, err := search.NewBooster(cfg.BoostRules)
+	if err != nil {
+		logger.Error("Reload rejected, keeping current configuration", err, nil)
+		return
+	}
+
+	applied := []string{}
+	for name, rotator := range targets.keys {
+		key, ok := cfg.APIKey(name)
+		if !ok || key == "" || key == rotator.APIKey() {
+			continue
+		}
+		if err := rotator.SetAPIKey(key); err != nil {
+			logger.Error("Failed to rotate API key", err, map[string]interface{}{
+				"provider": name,
+			})
+		} else {
+			applied = append(applied, "api_key:"+name)
+		}
+	}
+	if err := SetLogLevel(cfg.LogLevel); err == nil {
+		applied = append(applied, "log_level")
+	}
+	if targets.collector != nil && cfg.QueryLogPolicy != "" {
+		targets.collector.SetQueryPolicy(cfg.QueryLogPolicy)
+		applied = append(applied, "query_log_policy")
+	}
+	if targets.collector != nil {
+		if scrubber, err := privacy.NewScrubber(cfg.PIIScrub, cfg.PIIPatterns); err == nil {
+			targets.collector.SetScrubber(scrubber)
+			applied = append(applied, "pii_scrub")
+		}
+	}
+	if targets.rewriting != nil {
+		targets.rewriting.SetRewriter(rewriter)
+		applied = append(applied, "rewrite_rules")
+	}
+	if targets.boosting != nil {
+		targets.boosting.SetBooster(booster)
+		applied = append(applied, "boost_rules")
+	}
+	if targets.filtering != nil {
+		targets.filtering.SetDenylist(search.NewDenylist(cfg.Denylist))
+		applied = append(applied, "denylist")
+	}
+
+	if cfg.SearchProvider != current.SearchProvider || cfg.AdminAddr != current.AdminAddr || cfg.DenylistFeedURL != current.DenylistFeedURL {
+		logger.Info("Structural configuration changes require a restart", nil)
+	}
+
+	logger.Info("Configuration reloaded", map[string]interface{}{
+		"applied": applied,
+	})
+}
+
+// watchReloadSignal calls reload every time the process receives SIGHUP,
+// until the context is canceled. The returned channel is closed once it has
+// stopped watching.
+func watchReloadSignal(ctx context.Context, reload func()) <-chan struct{} {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		defer signal.Stop(signals)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-signals:
+				reload()
+			}
+		}
+	}()
+	return done
+}
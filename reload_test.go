@@ -0,0 +1,133 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"com.moguyn/mcp-go-search/config"
+	"com.moguyn/mcp-go-search/search"
+	"com.moguyn/mcp-go-search/search/providers/bocha"
+	"com.moguyn/mcp-go-search/search/providers/brave"
+	"com.moguyn/mcp-go-search/stats"
+)
+
+// TestReloadConfig tests that a reload rotates the API key from the key file
+func TestReloadConfig(t *testing.T) {
+	keyFile := filepath.Join(t.TempDir(), "bocha.key")
+	if err := os.WriteFile(keyFile, []byte("original-key-123456\n"), 0600); err != nil {
+		t.Fatalf("Failed to write key file: %v", err)
+	}
+
+	t.Setenv("BOCHA_API_KEY", "")
+	t.Setenv("BOCHA_API_KEY_FILE", keyFile)
+	t.Setenv("BOCHA_API_BASE_URL", "https://test.api.com")
+	t.Setenv("UPSTREAM_ALLOWLIST", "test.api.com")
+	t.Setenv("CONFIG_FILE", "")
+	defer func() { _ = SetLogLevel("info") }()
+
+	cfg := config.New()
+	if cfg.BochaAPIKey != "original-key-123456" {
+		t.Fatalf("Expected key from file, got %q", cfg.BochaAPIKey)
+	}
+
+	bochaService := bocha.NewWithConfig(cfg)
+	rewriter, _ := search.NewRewriter(nil)
+	targets := reloadTargets{
+		keys:      map[string]search.KeyRotator{config.ProviderBocha: bochaService},
+		rewriting: search.NewRewritingService(bochaService, rewriter),
+		collector: stats.NewCollector(),
+	}
+
+	// Rotate the secret and reload
+	if err := os.WriteFile(keyFile, []byte("rotated-key-654321\n"), 0600); err != nil {
+		t.Fatalf("Failed to write key file: %v", err)
+	}
+	reloadConfig(NewLogger("test"), cfg, targets)
+
+	if bochaService.APIKey() != "rotated-key-654321" {
+		t.Errorf("Expected rotated key, got %q", bochaService.APIKey())
+	}
+
+	// An invalid configuration is rejected and the current key is kept
+	t.Setenv("LOG_LEVEL", "verbose")
+	if err := os.WriteFile(keyFile, []byte("ignored-key-000000\n"), 0600); err != nil {
+		t.Fatalf("Failed to write key file: %v", err)
+	}
+	reloadConfig(NewLogger("test"), cfg, targets)
+
+	if bochaService.APIKey() != "rotated-key-654321" {
+		t.Errorf("Expected key to be kept after rejected reload, got %q", bochaService.APIKey())
+	}
+}
+
+// TestReloadConfig_OtherProviders tests that a reload rotates the key of every
+// active provider, not only Bocha
+func TestReloadConfig_OtherProviders(t *testing.T) {
+	keyFile := filepath.Join(t.TempDir(), "brave.key")
+	if err := os.WriteFile(keyFile, []byte("brave-key-original\n"), 0600); err != nil {
+		t.Fatalf("Failed to write key file: %v", err)
+	}
+	t.Setenv("SEARCH_PROVIDER", config.ProviderBrave)
+	t.Setenv("BRAVE_API_KEY_FILE", keyFile)
+	t.Setenv("CONFIG_FILE", "")
+	defer func() { _ = SetLogLevel("info") }()
+
+	cfg := config.New()
+	braveService := brave.NewWithConfig(cfg)
+	targets := reloadTargets{keys: map[string]search.KeyRotator{config.ProviderBrave: braveService}}
+
+	if err := os.WriteFile(keyFile, []byte("brave-key-rotated\n"), 0600); err != nil {
+		t.Fatalf("Failed to write key file: %v", err)
+	}
+	reloadConfig(NewLogger("test"), cfg, targets)
+	if braveService.APIKey() != "brave-key-rotated" {
+		t.Errorf("Expected the rotated Brave key, got %q", braveService.APIKey())
+	}
+}
+
+// TestWatchReloadSignalStops tests that the watcher reloads on SIGHUP and
+// exits when its context is canceled
+func TestWatchReloadSignalStops(t *testing.T) {
+	// Keep SIGHUP from terminating the test binary once the watcher stops
+	guard := make(chan os.Signal, 1)
+	signal.Notify(guard, syscall.SIGHUP)
+	defer signal.Stop(guard)
+
+	process, err := os.FindProcess(os.Getpid())
+	if err != nil {
+		t.Fatalf("Failed to find the test process: %v", err)
+	}
+	reloads := make(chan struct{}, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := watchReloadSignal(ctx, func() { reloads <- struct{}{} })
+
+	if err := process.Signal(syscall.SIGHUP); err != nil {
+		t.Fatalf("Failed to send SIGHUP: %v", err)
+	}
+	select {
+	case <-reloads:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected SIGHUP to trigger a reload")
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the watcher to stop when its context is canceled")
+	}
+	if err := process.Signal(syscall.SIGHUP); err != nil {
+		t.Fatalf("Failed to send SIGHUP: %v", err)
+	}
+	select {
+	case <-reloads:
+		t.Error("Expected no reload after the watcher stopped")
+	case <-time.After(100 * time.Millisecond):
+	}
+}
@@ -0,0 +1,163 @@
+// Package screenshot captures images of web pages through an external
+// renderer, such as a headless Chrome service, so sources can be checked visually
+package screenshot
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"golang.org/x/time/rate"
+
+	"com.moguyn/mcp-go-search/config"
+	"com.moguyn/mcp-go-search/fetch"
+	"com.moguyn/mcp-go-search/search"
+)
+
+// Limits of the viewport a page is rendered in
+const (
+	DefaultWidth  = 1280
+	DefaultHeight = 800
+	MaxWidth      = 3840
+	MaxHeight     = 2160
+)
+
+// imageTypes are the media types accepted from the renderer
+var imageTypes = []string{"image/png", "image/jpeg", "image/webp"}
+
+// ErrTooLarge is returned for an image larger than the configured limit
+var ErrTooLarge = errors.New("screenshot exceeds the size limit")
+
+// Request is what the renderer is asked to capture
+type Request struct {
+	URL      string `json:"url"`
+	Width    int    `json:"width"`
+	Height   int    `json:"height"`
+	FullPage bool   `json:"full_page"`
+}
+
+// Image is a captured screenshot
+type Image struct {
+	URL      string
+	MIMEType string
+	Data     []byte
+}
+
+// Client asks the renderer for screenshots
+type Client struct {
+	endpoint    string
+	token       string
+	maxBytes    int64
+	httpClient  *http.Client
+	guard       *fetch.AddressGuard
+	rateLimiter *rate.Limiter
+}
+
+// NewClient creates a new screenshot client with the provided configuration.
+// Pages on internal addresses are refused unless they are in the fetch allowlist.
+func NewClient(cfg *config.Config) (*Client, error) {
+	guard, err := fetch.NewAddressGuard(cfg.FetchAllowlist)
+	if err != nil {
+		return nil, err
+	}
+	httpClient := search.NewHTTPClient(cfg)
+	httpClient.Timeout = cfg.ScreenshotTimeout
+	return &Client{
+		endpoint:   cfg.ScreenshotURL,
+		token:      cfg.ScreenshotToken,
+		maxBytes:   int64(cfg.ScreenshotMaxBytes),
+		httpClient: httpClient,
+		guard:      guard,
+		// Rendering is expensive, so a burst of calls is smoothed out
+		rateLimiter: rate.NewLimiter(rate.Limit(1), 3),
+	}, nil
+}
+
+// Capture renders the page in r and returns its image. A zero width or
+// height means the default.
+func (c *Client) Capture(ctx context.Context, r Request) (*Image, error) {
+	u, err := url.Parse(r.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("unsupported URL scheme %q, must be http or https", u.Scheme)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("URL has no host")
+	}
+	if r.Width == 0 {
+		r.Width = DefaultWidth
+	}
+	if r.Height == 0 {
+		r.Height = DefaultHeight
+	}
+	if r.Width < 1 || r.Width > MaxWidth || r.Height < 1 || r.Height > MaxHeight {
+		return nil, fmt.Errorf("viewport must be at most %dx%d", MaxWidth, MaxHeight)
+	}
+	// The renderer would otherwise open internal pages on our behalf
+	if err := c.guard.CheckHost(ctx, u.Hostname()); err != nil {
+		return nil, err
+	}
+
+	if err := c.rateLimiter.Wait(ctx); err != nil {
+		return nil, fmt.Errorf("rate limit exceeded: %w", err)
+	}
+	body, err := json.Marshal(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTTP request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", strings.Join(imageTypes, ", "))
+	req.Header.Set("User-Agent", "BochaWebSearchMCPServer/1.0")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request to renderer: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("renderer returned status code %d", resp.StatusCode)
+	}
+	mt, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if !isImageType(mt) {
+		return nil, fmt.Errorf("renderer returned %q instead of an image", mt)
+	}
+	if resp.ContentLength > c.maxBytes {
+		return nil, ErrTooLarge
+	}
+
+	// Read at most one byte past the limit to detect an oversized image
+	data, err := io.ReadAll(io.LimitReader(resp.Body, c.maxBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read renderer response body: %w", err)
+	}
+	if int64(len(data)) > c.maxBytes {
+		return nil, ErrTooLarge
+	}
+	return &Image{URL: r.URL, MIMEType: mt, Data: data}, nil
+}
+
+// isImageType reports whether mt is an image type accepted from the renderer
+func isImageType(mt string) bool {
+	for _, accepted := range imageTypes {
+		if mt == accepted {
+			return true
+		}
+	}
+	return false
+}
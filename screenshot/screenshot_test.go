@@ -0,0 +1,97 @@
+package screenshot
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"com.moguyn/mcp-go-search/config"
+)
+
+// newTestClient returns a client rendering through the given server
+func newTestClient(t *testing.T, url string) *Client {
+	client, err := NewClient(&config.Config{
+		ScreenshotURL:      url,
+		ScreenshotToken:    "renderer-token",
+		ScreenshotTimeout:  5 * time.Second,
+		ScreenshotMaxBytes: 16,
+		HTTPTimeout:        5 * time.Second,
+	})
+	if err != nil {
+		t.Fatalf("NewClient returned an error: %v", err)
+	}
+	return client
+}
+
+func TestClient_Capture(t *testing.T) {
+	var got Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Authorization") != "Bearer renderer-token" {
+			t.Errorf("Unexpected request: %s with %q", r.Method, r.Header.Get("Authorization"))
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("Failed to decode request: %v", err)
+		}
+		w.Header().Set("Content-Type", "image/png")
+		if got.FullPage {
+			_, _ = w.Write([]byte(strings.Repeat("x", 17)))
+			return
+		}
+		_, _ = w.Write([]byte("\x89PNG"))
+	}))
+	defer server.Close()
+
+	client := newTestClient(t, server.URL)
+	image, err := client.Capture(context.Background(), Request{URL: "https://93.184.216.34/page"})
+	if err != nil {
+		t.Fatalf("Capture returned an error: %v", err)
+	}
+	if image.MIMEType != "image/png" || string(image.Data) != "\x89PNG" {
+		t.Errorf("Unexpected image: %+v", image)
+	}
+	if got.URL != "https://93.184.216.34/page" || got.Width != DefaultWidth || got.Height != DefaultHeight {
+		t.Errorf("Expected the default viewport, got %+v", got)
+	}
+
+	_, err = client.Capture(context.Background(), Request{URL: "https://93.184.216.34/", FullPage: true})
+	if !errors.Is(err, ErrTooLarge) {
+		t.Errorf("Expected ErrTooLarge, got %v", err)
+	}
+}
+
+func TestClient_CaptureRefused(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		t.Error("Expected no request to reach the renderer")
+	}))
+	defer server.Close()
+
+	client := newTestClient(t, server.URL)
+	for _, r := range []Request{
+		{URL: "file:///etc/passwd"},
+		{URL: "http://127.0.0.1:8080/admin"},
+		{URL: "http://169.254.169.254/latest/meta-data"},
+		{URL: "https://93.184.216.34/", Width: MaxWidth + 1},
+	} {
+		if _, err := client.Capture(context.Background(), r); err == nil {
+			t.Errorf("Expected %+v to be refused, got nil", r)
+		}
+	}
+}
+
+func TestClient_CaptureNotAnImage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte("<html>error</html>"))
+	}))
+	defer server.Close()
+
+	client := newTestClient(t, server.URL)
+	if _, err := client.Capture(context.Background(), Request{URL: "https://93.184.216.34/"}); err == nil {
+		t.Error("Expected an error for a non-image response, got nil")
+	}
+}
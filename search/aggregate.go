@@ -0,0 +1,260 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"com.moguyn/mcp-go-search/pool"
+	"com.moguyn/mcp-go-search/stats"
+)
+
+// rrfK dampens the weight of the top ranks in reciprocal rank fusion, as in
+// the method's original description
+const rrfK = 60
+
+// AggregateMember is one of the providers an Aggregator queries
+type AggregateMember struct {
+	Name    string
+	Service Service
+}
+
+// Aggregator queries several providers concurrently and combines their
+// results. A provider that fails or runs out of time adds a warning to the
+// response instead of failing the search, which only fails when every
+// provider does.
+type Aggregator struct {
+	members []AggregateMember
+	timeout time.Duration
+	workers *pool.Pool
+	scores  *stats.Collector
+	fuse    bool
+}
+
+// NewAggregator creates an aggregator over members, the first of which is the
+// primary provider. Each provider is given at most timeout, zero meaning no
+// limit, and runs on workers.
+func NewAggregator(members []AggregateMember, timeout time.Duration, workers *pool.Pool) *Aggregator {
+	return &Aggregator{
+		members: members,
+		timeout: timeout,
+		workers: workers,
+	}
+}
+
+// WeightBy ranks the providers' results by the providers' scores on the
+// scoreboard, healthiest first, instead of in configuration order. Providers
+// without a score yet rank first, so they get tried.
+func (a *Aggregator) WeightBy(scores *stats.Collector) *Aggregator {
+	a.scores = scores
+	return a
+}
+
+// FuseRanks merges the providers' results by reciprocal rank fusion instead of
+// in turn: a result scores 1/(60+rank) for each provider returning it, and
+// results returned by several providers are merged into one and rank higher
+func (a *Aggregator) FuseRanks() *Aggregator {
+	a.fuse = true
+	return a
+}
+
+// Members returns the names of the providers, the primary one first
+func (a *Aggregator) Members() []string {
+	names := make([]string, len(a.members))
+	for i, member := range a.members {
+		names[i] = member.Name
+	}
+	return names
+}
+
+// Capabilities returns the capabilities of the primary provider. Parameters
+// another provider doesn't support make it fail with a warning.
+func (a *Aggregator) Capabilities() Capabilities {
+	return CapabilitiesOf(a.members[0].Service)
+}
+
+// Search queries every provider, or those the context selects, and combines
+// the results of those that answered
+func (a *Aggregator) Search(ctx context.Context, query string, freshness string, count int, summary bool) (*WebSearchResponse, error) {
+	members, err := a.selected(ctx)
+	if err != nil {
+		return nil, err
+	}
+	responses := make([]*WebSearchResponse, len(members))
+	errs := make([]error, len(members))
+
+	// Jobs report their failure in errs so they never cancel each other
+	group, groupCtx := a.workers.Group(ctx)
+	for i, member := range members {
+		group.Go(func(ctx context.Context) error {
+			if a.timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, a.timeout)
+				defer cancel()
+			}
+			responses[i], errs[i] = member.Service.Search(ctx, query, freshness, count, summary)
+			if errs[i] != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) && groupCtx.Err() == nil {
+				errs[i] = fmt.Errorf("timed out after %s", a.timeout)
+			}
+			return nil
+		})
+	}
+	_ = group.Wait()
+
+	var answered []*WebSearchResponse
+	var weights []float64
+	var warnings []string
+	for i, member := range members {
+		if errs[i] != nil {
+			warnings = append(warnings, fmt.Sprintf("%s: %v", member.Name, errs[i]))
+			continue
+		}
+		answered = append(answered, responses[i])
+		weights = append(weights, a.weight(member.Name))
+	}
+	if len(answered) == 0 {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("every provider failed: %s", strings.Join(warnings, "; "))
+	}
+
+	if a.scores != nil {
+		sort.Stable(byWeight{answered, weights})
+	}
+	var combined *WebSearchResponse
+	if a.fuse {
+		combined = fuseResponses(answered, count)
+	} else {
+		combined = mergeResponses(answered, count)
+	}
+	combined.Meta.Degraded = len(warnings) > 0
+	combined.Meta.Warnings = warnings
+	return combined, nil
+}
+
+// selected returns the members the context selects, in configuration order,
+// or every member when it selects none
+func (a *Aggregator) selected(ctx context.Context) ([]AggregateMember, error) {
+	names := SelectedProviders(ctx)
+	if len(names) == 0 {
+		return a.members, nil
+	}
+	wanted := make(map[string]bool, len(names))
+	for _, name := range names {
+		wanted[name] = true
+	}
+	members := make([]AggregateMember, 0, len(names))
+	for _, member := range a.members {
+		if wanted[member.Name] {
+			members = append(members, member)
+			delete(wanted, member.Name)
+		}
+	}
+	for _, name := range names {
+		if wanted[name] {
+			return nil, fmt.Errorf("unknown aggregate provider %q (expected one of: %s)", name, strings.Join(a.Members(), ", "))
+		}
+	}
+	return members, nil
+}
+
+// weight returns the provider's score, or the highest score without one
+func (a *Aggregator) weight(name string) float64 {
+	if a.scores == nil {
+		return 0
+	}
+	if score, ok := a.scores.Score(name); ok {
+		return score
+	}
+	return 1
+}
+
+// byWeight sorts responses by the weights of their providers, highest first
+type byWeight struct {
+	responses []*WebSearchResponse
+	weights   []float64
+}
+
+func (b byWeight) Len() int           { return len(b.responses) }
+func (b byWeight) Less(i, j int) bool { return b.weights[i] > b.weights[j] }
+func (b byWeight) Swap(i, j int) {
+	b.responses[i], b.responses[j] = b.responses[j], b.responses[i]
+	b.weights[i], b.weights[j] = b.weights[j], b.weights[i]
+}
+
+// mergeResponses combines responses into one, taking the web results of each
+// in turn so every provider's best results come first, up to count. The rest
+// of the response, such as the summary, comes from the first response.
+func mergeResponses(responses []*WebSearchResponse, count int) *WebSearchResponse {
+	merged := *responses[0]
+	merged.Meta = ResponseMeta{}
+
+	results := make([]WebPageResult, 0, count)
+	var images []ImageResult
+	var videos []VideoResult
+	for rank := 0; len(results) < count; rank++ {
+		added := false
+		for _, response := range responses {
+			if pages := response.Data.WebPages.Value; rank < len(pages) && len(results) < count {
+				results = append(results, pages[rank])
+				added = true
+			}
+		}
+		if !added {
+			break
+		}
+	}
+	for _, response := range responses {
+		images = append(images, response.Data.Images.Value...)
+		videos = append(videos, response.Data.Videos.Value...)
+		merged.Meta.BytesSent += response.Meta.BytesSent
+		merged.Meta.BytesReceived += response.Meta.BytesReceived
+		merged.Meta.QueryTruncated = merged.Meta.QueryTruncated || response.Meta.QueryTruncated
+		merged.Meta.CountClamped = merged.Meta.CountClamped || response.Meta.CountClamped
+	}
+	merged.Data.WebPages.Value = results
+	merged.Data.Images.Value = images
+	merged.Data.Videos.Value = videos
+	return &merged
+}
+
+// fuseResponses combines responses into one by reciprocal rank fusion, up to
+// count results. Duplicate results keep the fields of the first response
+// returning them, and ties keep the order of the responses. The rest of the
+// response comes from the first response, as in mergeResponses.
+func fuseResponses(responses []*WebSearchResponse, count int) *WebSearchResponse {
+	merged := mergeResponses(responses, 0)
+
+	type fused struct {
+		result WebPageResult
+		score  float64
+	}
+	byKey := make(map[string]*fused)
+	var results []*fused
+	for _, response := range responses {
+		for rank, result := range response.Data.WebPages.Value {
+			key := dedupeKey(result.URL)
+			entry, ok := byKey[key]
+			if !ok {
+				entry = &fused{result: result}
+				byKey[key] = entry
+				results = append(results, entry)
+			}
+			entry.score += 1 / float64(rrfK+rank+1)
+		}
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].score > results[j].score
+	})
+
+	pages := make([]WebPageResult, 0, min(count, len(results)))
+	for _, entry := range results[:min(count, len(results))] {
+		pages = append(pages, entry.result)
+	}
+	merged.Data.WebPages.Value = pages
+	return merged
+}
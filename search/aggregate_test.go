@@ -0,0 +1,171 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"com.moguyn/mcp-go-search/pool"
+	"com.moguyn/mcp-go-search/stats"
+)
+
+// pagesResponse returns a response with a web result for each URL
+func pagesResponse(urls ...string) *WebSearchResponse {
+	response := &WebSearchResponse{}
+	for _, url := range urls {
+		response.Data.WebPages.Value = append(response.Data.WebPages.Value, WebPageResult{URL: url})
+	}
+	response.Meta.BytesReceived = 100
+	return response
+}
+
+// slowService answers only when its context is done
+type slowService struct{}
+
+// Search waits for ctx and returns its error
+func (slowService) Search(ctx context.Context, _ string, _ string, _ int, _ bool) (*WebSearchResponse, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestAggregator_Search(t *testing.T) {
+	bocha := &recordingService{response: pagesResponse("a1", "a2", "a3")}
+	brave := &recordingService{response: pagesResponse("b1", "b2")}
+	aggregator := NewAggregator([]AggregateMember{{"bocha", bocha}, {"brave", brave}}, time.Second, pool.New(2, 0))
+
+	response, err := aggregator.Search(context.Background(), "golang", "", 4, false)
+	if err != nil {
+		t.Fatalf("Search returned an error: %v", err)
+	}
+	var urls []string
+	for _, result := range response.Data.WebPages.Value {
+		urls = append(urls, result.URL)
+	}
+	if strings.Join(urls, ",") != "a1,b1,a2,b2" {
+		t.Errorf("Expected the results of both providers in turn, got %v", urls)
+	}
+	if response.Meta.Degraded || response.Meta.BytesReceived != 200 {
+		t.Errorf("Unexpected meta: %+v", response.Meta)
+	}
+	if len(bocha.response.Data.WebPages.Value) != 3 {
+		t.Error("Expected the providers' responses to be left untouched")
+	}
+}
+
+func TestAggregator_PartialFailure(t *testing.T) {
+	bocha := &recordingService{response: pagesResponse("a1")}
+	brave := &recordingService{err: errors.New("brave api error (status 429): rate limited")}
+	aggregator := NewAggregator([]AggregateMember{
+		{"bocha", bocha},
+		{"brave", brave},
+		{"google", slowService{}},
+	}, 20*time.Millisecond, nil)
+
+	response, err := aggregator.Search(context.Background(), "golang", "", 10, false)
+	if err != nil {
+		t.Fatalf("Expected partial results, got error %v", err)
+	}
+	if len(response.Data.WebPages.Value) != 1 || !response.Meta.Degraded {
+		t.Errorf("Expected degraded partial results, got %+v", response)
+	}
+	expected := []string{"brave: brave api error (status 429): rate limited", "google: timed out after 20ms"}
+	if strings.Join(response.Meta.Warnings, "|") != strings.Join(expected, "|") {
+		t.Errorf("Expected warnings %v, got %v", expected, response.Meta.Warnings)
+	}
+
+	bocha.response, bocha.err = nil, errors.New("boom")
+	_, err = aggregator.Search(context.Background(), "golang", "", 10, false)
+	if err == nil || !strings.HasPrefix(err.Error(), "every provider failed: bocha: boom; brave:") {
+		t.Errorf("Expected error when every provider fails, got %v", err)
+	}
+}
+
+func TestAggregator_WeightBy(t *testing.T) {
+	scores := stats.NewCollector()
+	scores.Record(stats.Call{Provider: "bocha", Err: errors.New("boom")})
+	scores.Record(stats.Call{Provider: "brave", Results: 2})
+	bocha := &recordingService{response: pagesResponse("a1", "a2")}
+	brave := &recordingService{response: pagesResponse("b1", "b2")}
+	google := &recordingService{response: pagesResponse("c1")}
+	aggregator := NewAggregator([]AggregateMember{{"bocha", bocha}, {"brave", brave}, {"google", google}}, time.Second, nil).WeightBy(scores)
+
+	response, err := aggregator.Search(context.Background(), "golang", "", 5, false)
+	if err != nil {
+		t.Fatalf("Search returned an error: %v", err)
+	}
+	var urls []string
+	for _, result := range response.Data.WebPages.Value {
+		urls = append(urls, result.URL)
+	}
+	if strings.Join(urls, ",") != "c1,b1,a1,b2,a2" {
+		t.Errorf("Expected the unscored provider first and the failing one last, got %v", urls)
+	}
+}
+
+func TestAggregator_FuseRanks(t *testing.T) {
+	bocha := &recordingService{response: pagesResponse("https://a.example/1", "https://go.dev/doc/", "https://a.example/2")}
+	brave := &recordingService{response: pagesResponse("https://b.example/1", "https://www.go.dev/doc")}
+	aggregator := NewAggregator([]AggregateMember{{"bocha", bocha}, {"brave", brave}}, time.Second, nil).FuseRanks()
+
+	response, err := aggregator.Search(context.Background(), "golang", "", 3, false)
+	if err != nil {
+		t.Fatalf("Search returned an error: %v", err)
+	}
+	var urls []string
+	for _, result := range response.Data.WebPages.Value {
+		urls = append(urls, result.URL)
+	}
+	expected := "https://go.dev/doc/,https://a.example/1,https://b.example/1"
+	if strings.Join(urls, ",") != expected {
+		t.Errorf("Expected the result both providers returned first and once, got %v", urls)
+	}
+	if response.Meta.BytesReceived != 200 {
+		t.Errorf("Expected the bytes of both responses, got %d", response.Meta.BytesReceived)
+	}
+}
+
+func TestAggregator_SelectedProviders(t *testing.T) {
+	bocha := &recordingService{response: pagesResponse("a1")}
+	brave := &recordingService{response: pagesResponse("b1")}
+	google := &recordingService{response: pagesResponse("c1")}
+	aggregator := NewAggregator([]AggregateMember{{"bocha", bocha}, {"brave", brave}, {"google", google}}, time.Second, nil)
+	if members := aggregator.Members(); strings.Join(members, ",") != "bocha,brave,google" {
+		t.Errorf("Expected the members in configuration order, got %v", members)
+	}
+
+	ctx := WithProviders(context.Background(), []string{"google", "brave"})
+	response, err := aggregator.Search(ctx, "golang", "", 5, false)
+	if err != nil {
+		t.Fatalf("Search returned an error: %v", err)
+	}
+	var urls []string
+	for _, result := range response.Data.WebPages.Value {
+		urls = append(urls, result.URL)
+	}
+	if strings.Join(urls, ",") != "b1,c1" || bocha.calls != 0 {
+		t.Errorf("Expected only the selected providers, in configuration order, got %v and %d bocha calls", urls, bocha.calls)
+	}
+
+	ctx = WithProviders(context.Background(), []string{"brave", "jina"})
+	if _, err := aggregator.Search(ctx, "golang", "", 5, false); err == nil || !strings.Contains(err.Error(), `unknown aggregate provider "jina"`) {
+		t.Errorf("Expected error for an unknown provider, got %v", err)
+	}
+}
+
+func TestCachingService_SkipsDegraded(t *testing.T) {
+	response := pagesResponse("a1")
+	response.Meta.Degraded = true
+	next := &recordingService{response: response}
+	cache := NewCachingService(next, time.Minute, 10)
+
+	for i := 0; i < 2; i++ {
+		if _, err := cache.Search(context.Background(), "golang", "", 10, false); err != nil {
+			t.Fatalf("Search returned an error: %v", err)
+		}
+	}
+	if next.calls != 2 || cache.Stats().Entries != 0 {
+		t.Errorf("Expected degraded responses not to be cached, got %d calls and %d entries", next.calls, cache.Stats().Entries)
+	}
+}
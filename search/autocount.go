@@ -0,0 +1,54 @@
+package search
+
+import "context"
+
+// maxAutoCountSearches bounds the searches made for one adaptive count search
+const maxAutoCountSearches = 3
+
+// AdaptiveCountService wraps the result pipeline and, for searches made with
+// WithAutoCount, searches again with a larger count when filtering such as
+// duplicate and denylist removal leaves fewer results than requested. The
+// first search asks for just the requested count, so nothing is fetched in
+// reserve unless it turns out to be needed.
+type AdaptiveCountService struct {
+	next Service
+}
+
+// NewAdaptiveCountService creates a new service adapting the count to what
+// survives filtering
+func NewAdaptiveCountService(next Service) *AdaptiveCountService {
+	return &AdaptiveCountService{next: next}
+}
+
+// Capabilities returns the capabilities of the wrapped provider
+func (s *AdaptiveCountService) Capabilities() Capabilities {
+	return CapabilitiesOf(s.next)
+}
+
+// Search forwards the search and, while too few results survive, repeats it
+// with double the count, up to the provider's maximum
+func (s *AdaptiveCountService) Search(ctx context.Context, query string, freshness string, count int, summary bool) (*WebSearchResponse, error) {
+	response, err := s.next.Search(ctx, query, freshness, count, summary)
+	if err != nil || !AutoCount(ctx) {
+		return response, err
+	}
+
+	maxCount := CapabilitiesOf(s.next).MaxCount
+	asked := count
+	for searches := 1; searches < maxAutoCountSearches && len(response.Data.WebPages.Value) < count; searches++ {
+		next := min(asked*2, maxCount)
+		if next <= asked {
+			break
+		}
+		asked = next
+		more, err := s.next.Search(ctx, query, freshness, asked, summary)
+		if err != nil {
+			// The results so far are better than none
+			break
+		}
+		if len(more.Data.WebPages.Value) > len(response.Data.WebPages.Value) {
+			response = more
+		}
+	}
+	return limitResults(response, count), nil
+}
@@ -0,0 +1,62 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+// duplicatingService returns count results where every other one repeats the
+// one before, and records the counts asked for
+type duplicatingService struct {
+	counts []int
+}
+
+// Search returns count results, half of them duplicates
+func (s *duplicatingService) Search(_ context.Context, _ string, _ string, count int, _ bool) (*WebSearchResponse, error) {
+	s.counts = append(s.counts, count)
+	response := &WebSearchResponse{}
+	for i := 0; i < count; i++ {
+		response.Data.WebPages.Value = append(response.Data.WebPages.Value, WebPageResult{URL: fmt.Sprintf("https://example.com/%d", i/2)})
+	}
+	return response, nil
+}
+
+// Capabilities limits searches to 30 results
+func (s *duplicatingService) Capabilities() Capabilities {
+	return Capabilities{Provider: "duplicating", MaxCount: 30}
+}
+
+func TestAdaptiveCountService(t *testing.T) {
+	tests := []struct {
+		name     string
+		count    int
+		auto     bool
+		counts   string
+		expected int
+	}{
+		{"off", 10, false, "[10]", 5},
+		{"refetches until enough", 10, true, "[10 20]", 10},
+		{"stops at the maximum", 20, true, "[20 30]", 15},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next := &duplicatingService{}
+			service := NewAdaptiveCountService(NewDedupeService(next))
+			ctx := context.Background()
+			if tt.auto {
+				ctx = WithAutoCount(ctx)
+			}
+			response, err := service.Search(ctx, "golang", "", tt.count, false)
+			if err != nil {
+				t.Fatalf("Search returned an error: %v", err)
+			}
+			if got := fmt.Sprint(next.counts); got != tt.counts {
+				t.Errorf("Expected searches for %s results, got %s", tt.counts, got)
+			}
+			if len(response.Data.WebPages.Value) != tt.expected {
+				t.Errorf("Expected %d results, got %d", tt.expected, len(response.Data.WebPages.Value))
+			}
+		})
+	}
+}
@@ -0,0 +1,192 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync/atomic"
+
+	"com.moguyn/mcp-go-search/config"
+)
+
+// boostRule is a compiled result boosting rule
+type boostRule struct {
+	pattern *regexp.Regexp
+	pin     []string
+	boost   []string
+	demote  []string
+}
+
+// Booster reorders results by domain for queries matching its rules
+type Booster struct {
+	rules []boostRule
+}
+
+// NewBooster compiles the configured boost rules
+func NewBooster(rules []config.BoostRule) (*Booster, error) {
+	b := &Booster{}
+	for i, rule := range rules {
+		pattern, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid boost rule %d pattern %q: %w", i, rule.Pattern, err)
+		}
+		b.rules = append(b.rules, boostRule{
+			pattern: pattern,
+			pin:     normalizeDomains(rule.Pin),
+			boost:   normalizeDomains(rule.Boost),
+			demote:  normalizeDomains(rule.Demote),
+		})
+	}
+	return b, nil
+}
+
+// match returns the pinned, boosted and demoted domains of every rule matching the query
+func (b *Booster) match(query string) (pin, boost, demote []string) {
+	for _, rule := range b.rules {
+		if rule.pattern.MatchString(query) {
+			pin = append(pin, rule.pin...)
+			boost = append(boost, rule.boost...)
+			demote = append(demote, rule.demote...)
+		}
+	}
+	return pin, boost, demote
+}
+
+// BoostingService wraps a Service and reorders its results: results from pinned
+// domains come first, then boosted domains, then the rest, with demoted domains
+// last. When no result from a pinned domain is present, one is fetched with a
+// supplementary site: search.
+type BoostingService struct {
+	next    Service
+	booster atomic.Pointer[Booster]
+}
+
+// NewBoostingService creates a new service that reorders results using the booster
+func NewBoostingService(next Service, booster *Booster) *BoostingService {
+	s := &BoostingService{
+		next: next,
+	}
+	s.booster.Store(booster)
+	return s
+}
+
+// SetBooster atomically replaces the rules used for subsequent searches
+func (s *BoostingService) SetBooster(booster *Booster) {
+	s.booster.Store(booster)
+}
+
+// Capabilities returns the capabilities of the wrapped provider
+func (s *BoostingService) Capabilities() Capabilities {
+	return CapabilitiesOf(s.next)
+}
+
+// Search forwards the search to the wrapped service and reorders the results
+func (s *BoostingService) Search(ctx context.Context, query string, freshness string, count int, summary bool) (*WebSearchResponse, error) {
+	response, err := s.next.Search(ctx, query, freshness, count, summary)
+	if err != nil {
+		return nil, err
+	}
+
+	pin, boost, demote := s.booster.Load().match(query)
+	if len(pin) == 0 && len(boost) == 0 && len(demote) == 0 {
+		return response, nil
+	}
+
+	// Never modify the wrapped service's response, which may be cached
+	results := append([]WebPageResult(nil), response.Data.WebPages.Value...)
+
+	// Make sure every pinned domain is represented
+	for _, domain := range pin {
+		if indexOfDomain(results, domain) != -1 {
+			continue
+		}
+		extra, err := s.next.Search(ctx, query+" site:"+domain, freshness, 1, false)
+		if err != nil || len(extra.Data.WebPages.Value) == 0 {
+			continue
+		}
+		if hit := extra.Data.WebPages.Value[0]; domainMatches(resultHost(hit), domain) {
+			results = append(results, hit)
+		}
+	}
+
+	ordered := make([]WebPageResult, 0, len(results))
+	taken := make([]bool, len(results))
+	take := func(keep func(host string) bool) {
+		for i, result := range results {
+			if !taken[i] && keep(resultHost(result)) {
+				ordered = append(ordered, result)
+				taken[i] = true
+			}
+		}
+	}
+	for _, domain := range pin {
+		// Only the best result of each pinned domain is pinned
+		if i := indexOfDomain(results, domain); i != -1 && !taken[i] {
+			ordered = append(ordered, results[i])
+			taken[i] = true
+		}
+	}
+	take(func(host string) bool { return matchesAny(host, boost) })
+	take(func(host string) bool { return !matchesAny(host, demote) })
+	take(func(string) bool { return true })
+
+	// Supplementary pinned results displace the lowest-ranked ones
+	if limit := max(count, len(response.Data.WebPages.Value)); len(ordered) > limit {
+		ordered = ordered[:limit]
+	}
+
+	boosted := *response
+	boosted.Data.WebPages.Value = ordered
+	return &boosted, nil
+}
+
+// normalizeDomains lower-cases domains and strips schemes, wildcards and leading dots
+func normalizeDomains(domains []string) []string {
+	normalized := make([]string, 0, len(domains))
+	for _, domain := range domains {
+		domain = strings.ToLower(strings.TrimSpace(domain))
+		domain = strings.TrimPrefix(strings.TrimPrefix(domain, "https://"), "http://")
+		domain = strings.TrimPrefix(strings.TrimPrefix(domain, "*."), ".")
+		domain = strings.TrimSuffix(domain, "/")
+		if domain != "" {
+			normalized = append(normalized, domain)
+		}
+	}
+	return normalized
+}
+
+// resultHost returns the lower-case host name of a result's URL
+func resultHost(result WebPageResult) string {
+	u, err := url.Parse(result.URL)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(u.Hostname())
+}
+
+// domainMatches reports whether host is domain or one of its subdomains
+func domainMatches(host, domain string) bool {
+	return host == domain || strings.HasSuffix(host, "."+domain)
+}
+
+// matchesAny reports whether host matches any of the domains
+func matchesAny(host string, domains []string) bool {
+	for _, domain := range domains {
+		if domainMatches(host, domain) {
+			return true
+		}
+	}
+	return false
+}
+
+// indexOfDomain returns the index of the first result from domain, or -1
+func indexOfDomain(results []WebPageResult, domain string) int {
+	for i, result := range results {
+		if domainMatches(resultHost(result), domain) {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,110 @@
+package search
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"com.moguyn/mcp-go-search/config"
+)
+
+// siteService returns canned results, answering site: searches from a separate set
+type siteService struct {
+	results []WebPageResult
+	sites   map[string]WebPageResult
+	queries []string
+}
+
+// Search returns the canned results for the query
+func (s *siteService) Search(_ context.Context, query string, _ string, _ int, _ bool) (*WebSearchResponse, error) {
+	s.queries = append(s.queries, query)
+	if _, site, ok := strings.Cut(query, " site:"); ok {
+		var value []WebPageResult
+		if hit, found := s.sites[site]; found {
+			value = append(value, hit)
+		}
+		return &WebSearchResponse{Data: Data{WebPages: WebPages{Value: value}}}, nil
+	}
+	return &WebSearchResponse{Data: Data{WebPages: WebPages{Value: s.results}}}, nil
+}
+
+func urls(response *WebSearchResponse) []string {
+	var out []string
+	for _, result := range response.Data.WebPages.Value {
+		out = append(out, result.URL)
+	}
+	return out
+}
+
+func TestBoostingService(t *testing.T) {
+	next := &siteService{
+		results: []WebPageResult{
+			{URL: "https://spam.example.com/python-tips"},
+			{URL: "https://blog.example.org/python"},
+			{URL: "https://realpython.com/python-lists"},
+			{URL: "https://www.w3schools.com/python/"},
+		},
+		sites: map[string]WebPageResult{
+			"docs.python.org": {URL: "https://docs.python.org/3/tutorial/"},
+		},
+	}
+	booster, err := NewBooster([]config.BoostRule{{
+		Pattern: `(?i)\bpython\b`,
+		Pin:     []string{"docs.python.org"},
+		Boost:   []string{"realpython.com"},
+		Demote:  []string{"spam.example.com", "*.w3schools.com"},
+	}})
+	if err != nil {
+		t.Fatalf("NewBooster returned an error: %v", err)
+	}
+	service := NewBoostingService(next, booster)
+
+	response, err := service.Search(context.Background(), "python list comprehension", "", 4, false)
+	if err != nil {
+		t.Fatalf("Search returned an error: %v", err)
+	}
+	expected := []string{
+		"https://docs.python.org/3/tutorial/",
+		"https://realpython.com/python-lists",
+		"https://blog.example.org/python",
+		"https://spam.example.com/python-tips",
+	}
+	if got := urls(response); strings.Join(got, " ") != strings.Join(expected, " ") {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+	if len(next.queries) != 2 || next.queries[1] != "python list comprehension site:docs.python.org" {
+		t.Errorf("Expected a supplementary site: search, got %v", next.queries)
+	}
+	if next.results[0].URL != "https://spam.example.com/python-tips" {
+		t.Error("Expected the wrapped service's results not to be modified")
+	}
+
+	// Queries not matching a rule are untouched
+	next.queries = nil
+	response, _ = service.Search(context.Background(), "rust borrow checker", "", 4, false)
+	if urls(response)[0] != "https://spam.example.com/python-tips" || len(next.queries) != 1 {
+		t.Errorf("Expected unmatched query to pass through, got %v", urls(response))
+	}
+}
+
+func TestBoostingService_PinnedResultAlreadyPresent(t *testing.T) {
+	next := &siteService{results: []WebPageResult{
+		{URL: "https://example.com/a"},
+		{URL: "https://docs.python.org/3/library/"},
+	}}
+	booster, _ := NewBooster([]config.BoostRule{{Pattern: "python", Pin: []string{"https://docs.python.org/"}}})
+
+	response, _ := NewBoostingService(next, booster).Search(context.Background(), "python", "", 10, false)
+	if got := urls(response); got[0] != "https://docs.python.org/3/library/" || len(got) != 2 {
+		t.Errorf("Expected the present pinned result to move first, got %v", got)
+	}
+	if len(next.queries) != 1 {
+		t.Errorf("Expected no supplementary search, got %v", next.queries)
+	}
+}
+
+func TestNewBooster_InvalidPattern(t *testing.T) {
+	if _, err := NewBooster([]config.BoostRule{{Pattern: "("}}); err == nil {
+		t.Error("Expected error for invalid pattern, got nil")
+	}
+}
@@ -0,0 +1,193 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"com.moguyn/mcp-go-search/params"
+)
+
+// cacheCountBucket is the granularity counts are rounded up to when caching,
+// so requests for 7 and 10 results share one upstream call
+const cacheCountBucket = 10
+
+// cacheEntry holds a cached response and when it expires
+type cacheEntry struct {
+	response  *WebSearchResponse
+	expiresAt time.Time
+}
+
+// CacheStats reports cache effectiveness counters
+type CacheStats struct {
+	Entries int    `json:"entries"`
+	Hits    uint64 `json:"hits"`
+	Misses  uint64 `json:"misses"`
+}
+
+// CachingService wraps a Service and caches successful responses in memory
+type CachingService struct {
+	next       Service
+	ttl        time.Duration
+	maxEntries int
+
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+	hits    uint64
+	misses  uint64
+	now     func() time.Time
+}
+
+// NewCachingService creates a new caching service. Responses are kept for ttl;
+// when maxEntries is reached expired entries are evicted, then the oldest one.
+func NewCachingService(next Service, ttl time.Duration, maxEntries int) *CachingService {
+	return &CachingService{
+		next:       next,
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		entries:    make(map[string]cacheEntry),
+		now:        time.Now,
+	}
+}
+
+// Search returns a cached response when one is available, otherwise it
+// forwards the search to the wrapped service and caches the result.
+// Trivially different calls share an entry: see cacheKey.
+func (s *CachingService) Search(ctx context.Context, query string, freshness string, count int, summary bool) (*WebSearchResponse, error) {
+	if Incognito(ctx) {
+		return s.next.Search(ctx, query, freshness, count, summary)
+	}
+	bucket := cacheCountBucketFor(count)
+	if maxCount := CapabilitiesOf(s.next).MaxCount; maxCount > 0 && bucket > maxCount {
+		bucket = maxCount
+	}
+	// Searches made with different options, e.g. with and without spelling
+	// correction, return different responses
+	key := optionsKey(ctx) + "\x00" + cacheKey(query, freshness, bucket, summary)
+
+	s.mu.Lock()
+	if entry, ok := s.entries[key]; ok && s.now().Before(entry.expiresAt) {
+		s.hits++
+		s.mu.Unlock()
+		return markCached(limitResults(entry.response, count)), nil
+	}
+	s.misses++
+	s.mu.Unlock()
+
+	response, err := s.next.Search(ctx, query, freshness, bucket, summary)
+	if err != nil {
+		return nil, err
+	}
+	// Partial results are not kept, so the next search can get them all
+	if response.Meta.Degraded {
+		return limitResults(response, count), nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.maxEntries > 0 && len(s.entries) >= s.maxEntries {
+		s.evict()
+	}
+	s.entries[key] = cacheEntry{response: response, expiresAt: s.now().Add(s.ttl)}
+
+	return limitResults(response, count), nil
+}
+
+// Capabilities returns the capabilities of the wrapped provider
+func (s *CachingService) Capabilities() Capabilities {
+	return CapabilitiesOf(s.next)
+}
+
+// Flush removes every cached entry and returns how many were removed
+func (s *CachingService) Flush() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := len(s.entries)
+	s.entries = make(map[string]cacheEntry)
+	return n
+}
+
+// Stats returns the current cache counters
+func (s *CachingService) Stats() CacheStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return CacheStats{
+		Entries: len(s.entries),
+		Hits:    s.hits,
+		Misses:  s.misses,
+	}
+}
+
+// evict drops expired entries, or the entry closest to expiry if none have expired.
+// The caller must hold s.mu.
+func (s *CachingService) evict() {
+	now := s.now()
+	var oldestKey string
+	var oldest time.Time
+	removed := false
+	for key, entry := range s.entries {
+		if !now.Before(entry.expiresAt) {
+			delete(s.entries, key)
+			removed = true
+			continue
+		}
+		if oldestKey == "" || entry.expiresAt.Before(oldest) {
+			oldestKey, oldest = key, entry.expiresAt
+		}
+	}
+	if !removed && oldestKey != "" {
+		delete(s.entries, oldestKey)
+	}
+}
+
+// cacheKey builds the cache key for a search. The query is trimmed, case-folded
+// and whitespace-collapsed, and site: filters are sorted, so "Go  site:b site:a"
+// and "go site:a site:b" hit the same entry.
+func cacheKey(query, freshness string, count int, summary bool) string {
+	return fmt.Sprintf("%s\x00%s\x00%d\x00%t", normalizeQuery(query), strings.ToLower(strings.TrimSpace(freshness)), count, summary)
+}
+
+// normalizeQuery returns the canonical form of a query used for cache keys
+func normalizeQuery(query string) string {
+	var terms, sites []string
+	for _, field := range strings.Fields(strings.ToLower(query)) {
+		if strings.HasPrefix(field, "site:") {
+			sites = append(sites, field)
+		} else {
+			terms = append(terms, field)
+		}
+	}
+	sort.Strings(sites)
+	return strings.Join(append(terms, sites...), " ")
+}
+
+// cacheCountBucketFor clamps count to the range the providers accept and
+// rounds it up to the next bucket
+func cacheCountBucketFor(count int) int {
+	count, _ = params.ClampCount(count, params.MaxCount)
+	return (count + cacheCountBucket - 1) / cacheCountBucket * cacheCountBucket
+}
+
+// markCached returns a shallow copy of a cached response marked as served from cache
+func markCached(response *WebSearchResponse) *WebSearchResponse {
+	cached := *response
+	cached.Meta.Cached = true
+	return &cached
+}
+
+// limitResults returns response with at most count web page results. The
+// cached response is never modified; a shallow copy is trimmed instead.
+func limitResults(response *WebSearchResponse, count int) *WebSearchResponse {
+	if count < 1 {
+		count = 1
+	}
+	if response == nil || len(response.Data.WebPages.Value) <= count {
+		return response
+	}
+	trimmed := *response
+	trimmed.Data.WebPages.Value = response.Data.WebPages.Value[:count:count]
+	return &trimmed
+}
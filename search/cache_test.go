@@ -0,0 +1,167 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestCachingService_Search(t *testing.T) {
+	next := &recordingService{}
+	cache := NewCachingService(next, time.Minute, 10)
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		if _, err := cache.Search(ctx, "golang", "noLimit", 10, false); err != nil {
+			t.Fatalf("Search returned an error: %v", err)
+		}
+	}
+	if next.calls != 1 {
+		t.Errorf("Expected 1 upstream call, got %d", next.calls)
+	}
+
+	// Different parameters are cached separately
+	if _, err := cache.Search(ctx, "golang", "day", 10, false); err != nil {
+		t.Fatalf("Search returned an error: %v", err)
+	}
+	if next.calls != 2 {
+		t.Errorf("Expected 2 upstream calls, got %d", next.calls)
+	}
+
+	stats := cache.Stats()
+	if stats.Hits != 2 || stats.Misses != 2 || stats.Entries != 2 {
+		t.Errorf("Unexpected cache stats: %+v", stats)
+	}
+
+	if n := cache.Flush(); n != 2 {
+		t.Errorf("Expected 2 flushed entries, got %d", n)
+	}
+	if _, err := cache.Search(ctx, "golang", "noLimit", 10, false); err != nil {
+		t.Fatalf("Search returned an error: %v", err)
+	}
+	if next.calls != 3 {
+		t.Errorf("Expected a new upstream call after flush, got %d calls", next.calls)
+	}
+}
+
+func TestCachingService_Expiry(t *testing.T) {
+	next := &recordingService{}
+	cache := NewCachingService(next, time.Minute, 2)
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	_, _ = cache.Search(ctx, "a", "", 10, false)
+	now = now.Add(2 * time.Minute)
+	_, _ = cache.Search(ctx, "a", "", 10, false)
+	if next.calls != 2 {
+		t.Errorf("Expected expired entry to be refreshed, got %d calls", next.calls)
+	}
+
+	// Exceeding maxEntries evicts an entry
+	_, _ = cache.Search(ctx, "b", "", 10, false)
+	_, _ = cache.Search(ctx, "c", "", 10, false)
+	if entries := cache.Stats().Entries; entries > 2 {
+		t.Errorf("Expected at most 2 entries, got %d", entries)
+	}
+}
+
+func TestCachingService_ErrorsNotCached(t *testing.T) {
+	next := &recordingService{err: errors.New("upstream failure")}
+	cache := NewCachingService(next, time.Minute, 10)
+
+	for i := 0; i < 2; i++ {
+		if _, err := cache.Search(context.Background(), "a", "", 10, false); err == nil {
+			t.Error("Expected error, got nil")
+		}
+	}
+	if next.calls != 2 {
+		t.Errorf("Expected errors not to be cached, got %d calls", next.calls)
+	}
+}
+
+func TestCachingService_NormalizedKeys(t *testing.T) {
+	pages := make([]WebPageResult, 20)
+	for i := range pages {
+		pages[i] = WebPageResult{Name: fmt.Sprintf("result %d", i)}
+	}
+	next := &recordingService{response: &WebSearchResponse{Data: Data{WebPages: WebPages{Value: pages}}}}
+	cache := NewCachingService(next, time.Minute, 10)
+	ctx := context.Background()
+
+	first, err := cache.Search(ctx, "Golang  Generics site:go.dev site:github.com", "noLimit", 7, false)
+	if err != nil {
+		t.Fatalf("Search returned an error: %v", err)
+	}
+	if next.count != 10 {
+		t.Errorf("Expected upstream count to be rounded up to 10, got %d", next.count)
+	}
+	if len(first.Data.WebPages.Value) != 7 {
+		t.Errorf("Expected 7 results, got %d", len(first.Data.WebPages.Value))
+	}
+
+	second, err := cache.Search(ctx, " golang generics site:github.com site:go.dev ", "noLimit", 10, false)
+	if err != nil {
+		t.Fatalf("Search returned an error: %v", err)
+	}
+	if next.calls != 1 {
+		t.Errorf("Expected normalized query to hit the cache, got %d upstream calls", next.calls)
+	}
+	if len(second.Data.WebPages.Value) != 10 {
+		t.Errorf("Expected 10 results, got %d", len(second.Data.WebPages.Value))
+	}
+	if len(next.response.Data.WebPages.Value) != 20 {
+		t.Error("Expected the cached response not to be modified")
+	}
+
+	// A count in a different bucket is a separate entry
+	if _, err := cache.Search(ctx, "golang generics site:go.dev site:github.com", "noLimit", 15, false); err != nil {
+		t.Fatalf("Search returned an error: %v", err)
+	}
+	if next.calls != 2 || next.count != 20 {
+		t.Errorf("Expected a second upstream call for 20 results, got %d calls with count %d", next.calls, next.count)
+	}
+}
+
+func TestNormalizeQuery(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected string
+	}{
+		{"  Hello   World ", "hello world"},
+		{"site:b.com Rust site:a.com async", "rust async site:a.com site:b.com"},
+		{"", ""},
+	}
+
+	for _, tc := range testCases {
+		if got := normalizeQuery(tc.input); got != tc.expected {
+			t.Errorf("Expected %q for %q, got %q", tc.expected, tc.input, got)
+		}
+	}
+}
+
+func TestCachingService_MarksCached(t *testing.T) {
+	next := &recordingService{}
+	cache := NewCachingService(next, time.Minute, 10)
+	ctx := context.Background()
+
+	live, err := cache.Search(ctx, "golang", "noLimit", 10, false)
+	if err != nil {
+		t.Fatalf("Search returned an error: %v", err)
+	}
+	if live.Meta.Cached {
+		t.Error("Expected the first response to be live")
+	}
+	cached, err := cache.Search(ctx, "golang", "noLimit", 10, false)
+	if err != nil {
+		t.Fatalf("Search returned an error: %v", err)
+	}
+	if !cached.Meta.Cached {
+		t.Error("Expected the second response to be marked as cached")
+	}
+	if live.Meta.Cached {
+		t.Error("Expected the stored response not to be modified")
+	}
+}
@@ -0,0 +1,173 @@
+package search
+
+import (
+	"fmt"
+	"strings"
+
+	"com.moguyn/mcp-go-search/params"
+)
+
+// Query operators a provider may support
+const (
+	// OperatorSite restricts results to a domain, e.g. site:go.dev
+	OperatorSite = "site:"
+	// OperatorPhrase matches an exact phrase in double quotes
+	OperatorPhrase = `"`
+	// OperatorExclude excludes a term prefixed with a minus sign
+	OperatorExclude = "-"
+	// OperatorOr matches either of two terms
+	OperatorOr = "OR"
+)
+
+// Capabilities describes what a search provider supports
+type Capabilities struct {
+	// Provider is the name used in error messages
+	Provider string `json:"provider"`
+	// Images reports whether image results are returned alongside web pages
+	Images bool `json:"images"`
+	// Videos reports whether video results are returned alongside web pages
+	Videos bool `json:"videos"`
+	// News reports whether news results are supported
+	News bool `json:"news"`
+	// Summaries reports whether results carry a summary of their page when a
+	// search asks for summaries
+	Summaries bool `json:"summaries"`
+	// Freshness lists the supported freshness values
+	Freshness []string `json:"freshness"`
+	// MaxCount is the largest number of results a single search may request
+	MaxCount int `json:"max_count"`
+	// Operators lists the supported query operators
+	Operators []string `json:"operators"`
+	// ExactQuery reports whether spelling correction can be turned off, see WithExactQuery
+	ExactQuery bool `json:"exact_query"`
+	// ExactCount reports whether the total number of matches can be counted exactly, see WithExactCount
+	ExactCount bool `json:"exact_count"`
+	// Pro reports whether a pro endpoint with answers and rich snippets can be used, see WithPro
+	Pro bool `json:"pro"`
+	// AISearch reports whether an AI search with answers and modal cards can be used, see WithAISearch
+	AISearch bool `json:"ai_search"`
+	// AgentSearch reports whether a search can be handed to a vertical agent, see WithAgent
+	AgentSearch bool `json:"agent_search"`
+	// Agents lists the agents a search can be handed to; any name is passed on when it is empty
+	Agents []string `json:"agents,omitempty"`
+}
+
+// CapabilityReporter is implemented by services that can describe their provider.
+// Decorators implement it by forwarding to the service they wrap.
+type CapabilityReporter interface {
+	Capabilities() Capabilities
+}
+
+// DefaultCapabilities are assumed for providers that do not describe themselves:
+// every tool parameter is passed through unchanged
+func DefaultCapabilities(provider string) Capabilities {
+	return Capabilities{
+		Provider:    provider,
+		Freshness:   params.Freshness,
+		MaxCount:    params.MaxCount,
+		Operators:   []string{OperatorSite, OperatorPhrase, OperatorExclude, OperatorOr},
+		Summaries:   true,
+		ExactQuery:  true,
+		ExactCount:  true,
+		Pro:         true,
+		AISearch:    true,
+		AgentSearch: true,
+	}
+}
+
+// CapabilitiesOf returns the capabilities of the provider behind s
+func CapabilitiesOf(s Service) Capabilities {
+	if reporter, ok := s.(CapabilityReporter); ok {
+		return reporter.Capabilities()
+	}
+	return DefaultCapabilities("search")
+}
+
+// SupportsFreshness reports whether the provider accepts the freshness value
+func (c Capabilities) SupportsFreshness(freshness string) bool {
+	if freshness == "" {
+		return true
+	}
+	for _, f := range c.Freshness {
+		if f == freshness {
+			return true
+		}
+	}
+	return false
+}
+
+// SupportsOperator reports whether the provider understands the query operator
+func (c Capabilities) SupportsOperator(operator string) bool {
+	for _, op := range c.Operators {
+		if op == operator {
+			return true
+		}
+	}
+	return false
+}
+
+// Limits returns the bounds used to normalize parameters for the provider
+func (c Capabilities) Limits() params.Limits {
+	return params.Limits{MaxCount: c.MaxCount}
+}
+
+// ClampCount limits count to the range the provider accepts
+func (c Capabilities) ClampCount(count int) int {
+	count, _ = params.ClampCount(count, c.MaxCount)
+	return count
+}
+
+// Check returns an error naming the first parameter the provider does not
+// support. An unsupported OR is let through, since it is just as likely to be
+// an ordinary word; see Warnings.
+func (c Capabilities) Check(query, freshness string) error {
+	if !c.SupportsFreshness(freshness) {
+		return fmt.Errorf("provider %s doesn't support freshness %q (supported: %s)", c.Provider, freshness, strings.Join(c.Freshness, ", "))
+	}
+	for _, op := range QueryOperators(query) {
+		if op != OperatorOr && !c.SupportsOperator(op) {
+			return fmt.Errorf("provider %s doesn't support the %s operator", c.Provider, op)
+		}
+	}
+	return nil
+}
+
+// Warnings describes the operators in query that Check lets through although
+// the provider doesn't support them
+func (c Capabilities) Warnings(query string) []string {
+	var warnings []string
+	for _, op := range QueryOperators(query) {
+		if op == OperatorOr && !c.SupportsOperator(op) {
+			warnings = append(warnings, fmt.Sprintf("provider %s doesn't support the %s operator, so it was searched as an ordinary word", c.Provider, op))
+		}
+	}
+	return warnings
+}
+
+// QueryOperators returns the operators used in a query, each listed once
+func QueryOperators(query string) []string {
+	var ops []string
+	add := func(op string) {
+		for _, existing := range ops {
+			if existing == op {
+				return
+			}
+		}
+		ops = append(ops, op)
+	}
+
+	if strings.Count(query, `"`) >= 2 {
+		add(OperatorPhrase)
+	}
+	for _, field := range strings.Fields(query) {
+		switch {
+		case strings.HasPrefix(strings.ToLower(field), OperatorSite):
+			add(OperatorSite)
+		case len(field) > 1 && strings.HasPrefix(field, OperatorExclude):
+			add(OperatorExclude)
+		case field == OperatorOr:
+			add(OperatorOr)
+		}
+	}
+	return ops
+}
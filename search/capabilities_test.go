@@ -0,0 +1,127 @@
+package search
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"com.moguyn/mcp-go-search/params"
+	"com.moguyn/mcp-go-search/stats"
+)
+
+func TestCapabilities_Check(t *testing.T) {
+	caps := Capabilities{
+		Provider:  "limited",
+		Freshness: []string{"noLimit", "day"},
+		MaxCount:  20,
+		Operators: []string{OperatorSite},
+	}
+
+	testCases := []struct {
+		name      string
+		query     string
+		freshness string
+		errPart   string
+	}{
+		{"plain query", "golang generics", "day", ""},
+		{"empty freshness", "golang", "", ""},
+		{"site operator", "golang site:go.dev", "noLimit", ""},
+		{"unsupported freshness", "golang", "oneYear", `doesn't support freshness "oneYear"`},
+		{"unsupported phrase", `"exact phrase"`, "", `doesn't support the " operator`},
+		{"unsupported exclusion", "golang -java", "", "doesn't support the - operator"},
+		{"unsupported or", "golang OR rust", "", ""},
+		{"trailing or", "stand by me OR", "", ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := caps.Check(tc.query, tc.freshness)
+			if tc.errPart == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.errPart) {
+				t.Errorf("Expected error containing %q, got %v", tc.errPart, err)
+			}
+			if err != nil && !strings.Contains(err.Error(), "provider limited") {
+				t.Errorf("Expected error to name the provider, got %v", err)
+			}
+		})
+	}
+}
+
+func TestCapabilities_Warnings(t *testing.T) {
+	caps := Capabilities{Provider: "limited", Operators: []string{OperatorSite}}
+	if warnings := caps.Warnings("golang site:go.dev"); warnings != nil {
+		t.Errorf("Expected no warnings, got %v", warnings)
+	}
+	warnings := caps.Warnings("Tom OR Jerry")
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "provider limited doesn't support the OR operator") {
+		t.Errorf("Unexpected warnings: %v", warnings)
+	}
+	caps.Operators = append(caps.Operators, OperatorOr)
+	if warnings := caps.Warnings("Tom OR Jerry"); warnings != nil {
+		t.Errorf("Expected no warnings when OR is supported, got %v", warnings)
+	}
+}
+
+func TestCapabilities_ClampCount(t *testing.T) {
+	caps := Capabilities{MaxCount: 20}
+	if got := caps.ClampCount(0); got != 1 {
+		t.Errorf("Expected 1, got %d", got)
+	}
+	if got := caps.ClampCount(15); got != 15 {
+		t.Errorf("Expected 15, got %d", got)
+	}
+	if got := caps.ClampCount(30); got != 20 {
+		t.Errorf("Expected 20, got %d", got)
+	}
+}
+
+func TestCapabilitiesOf_ForwardedByDecorators(t *testing.T) {
+	var service Service = &limitedService{max: 20}
+	service = NewToggleService("limited", service)
+	service = NewInstrumentedService("limited", service, stats.NewCollector())
+	service = NewCachingService(service, time.Minute, 10)
+	rewriter, _ := NewRewriter(nil)
+	service = NewRewritingService(service, rewriter)
+
+	caps := CapabilitiesOf(service)
+	if caps.Provider != "limited" || caps.MaxCount != 20 {
+		t.Errorf("Expected the provider's capabilities through the decorators, got %+v", caps)
+	}
+
+	// Services that do not describe themselves get permissive defaults
+	caps = CapabilitiesOf(&recordingService{})
+	if caps.MaxCount != 50 || len(caps.Freshness) != len(params.Freshness) {
+		t.Errorf("Unexpected default capabilities: %+v", caps)
+	}
+}
+
+func TestCachingService_BucketRespectsMaxCount(t *testing.T) {
+	next := &limitedService{max: 25}
+	cache := NewCachingService(next, time.Minute, 10)
+
+	if _, err := cache.Search(context.Background(), "a", "", 22, false); err != nil {
+		t.Fatalf("Search returned an error: %v", err)
+	}
+	if next.count != 25 {
+		t.Errorf("Expected the count bucket to be capped at 25, got %d", next.count)
+	}
+}
+
+// limitedService is a recordingService that reports a maximum count
+type limitedService struct {
+	recordingService
+	max int
+}
+
+// Capabilities reports the configured maximum count
+func (s *limitedService) Capabilities() Capabilities {
+	caps := DefaultCapabilities("limited")
+	caps.MaxCount = s.max
+	return caps
+}
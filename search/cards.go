@@ -0,0 +1,105 @@
+package search
+
+// Types of the modal cards an AI search may return
+const (
+	CardWeather    = "weather"
+	CardStock      = "stock"
+	CardBaike      = "baike"
+	CardConversion = "conversion"
+	CardDefinition = "definition"
+)
+
+// Kinds of conversion card
+const (
+	ConversionCurrency    = "currency"
+	ConversionUnit        = "unit"
+	ConversionCalculation = "calculation"
+)
+
+// Card is a modal card answering a query of a common kind outright, such as
+// the weather forecast for a city. Type says which of the other fields is set.
+type Card struct {
+	Type       string          `json:"type"`
+	Weather    *WeatherCard    `json:"weather,omitempty"`
+	Stock      *StockCard      `json:"stock,omitempty"`
+	Baike      *BaikeCard      `json:"baike,omitempty"`
+	Conversion *ConversionCard `json:"conversion,omitempty"`
+	Definition *DefinitionCard `json:"definition,omitempty"`
+}
+
+// WeatherCard is the current weather and forecast of a place
+type WeatherCard struct {
+	Location    string `json:"location"`
+	Date        string `json:"date,omitempty"`
+	Condition   string `json:"condition,omitempty"`
+	Temperature string `json:"temperature,omitempty"`
+	High        string `json:"high,omitempty"`
+	Low         string `json:"low,omitempty"`
+	Wind        string `json:"wind,omitempty"`
+	Humidity    string `json:"humidity,omitempty"`
+	AirQuality  string `json:"airQuality,omitempty"`
+}
+
+// StockCard is the latest quote of a listed stock
+type StockCard struct {
+	Name          string `json:"name"`
+	Symbol        string `json:"symbol"`
+	Exchange      string `json:"exchange,omitempty"`
+	Price         string `json:"price"`
+	Change        string `json:"change,omitempty"`
+	ChangePercent string `json:"changePercent,omitempty"`
+	Currency      string `json:"currency,omitempty"`
+	// Time is when the quote was taken
+	Time string `json:"time,omitempty"`
+}
+
+// BaikeCard is an encyclopedia entry, such as one from Baidu Baike
+type BaikeCard struct {
+	Title   string `json:"title"`
+	Summary string `json:"summary,omitempty"`
+	URL     string `json:"url,omitempty"`
+	Image   string `json:"image,omitempty"`
+	Facts   []Fact `json:"facts,omitempty"`
+}
+
+// ConversionCard is the computed answer to a conversion or calculation query,
+// such as "100 USD to CNY". ToAmount holds the computed value.
+type ConversionCard struct {
+	// Kind is ConversionCurrency, ConversionUnit or ConversionCalculation
+	Kind string `json:"kind"`
+	// Expression is what was calculated, for calculations
+	Expression string `json:"expression,omitempty"`
+	FromAmount string `json:"fromAmount,omitempty"`
+	FromUnit   string `json:"fromUnit,omitempty"`
+	ToAmount   string `json:"toAmount"`
+	ToUnit     string `json:"toUnit,omitempty"`
+	// Rate is how many ToUnit one FromUnit is worth
+	Rate string `json:"rate,omitempty"`
+	// Time is when the rate was taken
+	Time string `json:"time,omitempty"`
+}
+
+// DefinitionCard is a dictionary entry answering a query such as "define
+// serendipity": how the word is pronounced and what it means
+type DefinitionCard struct {
+	Word           string          `json:"word"`
+	Pronunciations []Pronunciation `json:"pronunciations,omitempty"`
+	Senses         []Sense         `json:"senses"`
+	URL            string          `json:"url,omitempty"`
+}
+
+// Pronunciation is one way of pronouncing a word, such as its US or UK
+// phonetic transcription or its pinyin
+type Pronunciation struct {
+	// Label says whose pronunciation it is, such as "US" or "UK"
+	Label    string `json:"label,omitempty"`
+	Phonetic string `json:"phonetic"`
+	Audio    string `json:"audio,omitempty"`
+}
+
+// Sense is one meaning of a word, with sentences using it in that meaning
+type Sense struct {
+	PartOfSpeech string   `json:"partOfSpeech,omitempty"`
+	Definition   string   `json:"definition"`
+	Examples     []string `json:"examples,omitempty"`
+}
@@ -0,0 +1,214 @@
+package search
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// maxDenylistFeedSize bounds the size of a downloaded denylist
+const maxDenylistFeedSize = 5 * 1024 * 1024
+
+// Denylist blocks results by domain, including subdomains, or by URL prefix
+type Denylist struct {
+	domains  []string
+	prefixes []string
+}
+
+// NewDenylist creates a denylist from entries such as "example.com" or
+// "example.com/ads/". Schemes, wildcards and blank entries are ignored.
+func NewDenylist(entries []string) *Denylist {
+	d := &Denylist{}
+	for _, entry := range entries {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		entry = strings.TrimPrefix(strings.TrimPrefix(entry, "https://"), "http://")
+		entry = strings.TrimPrefix(strings.TrimPrefix(entry, "*."), ".")
+		if i := strings.Index(entry, "/"); i != -1 && i < len(entry)-1 {
+			d.prefixes = append(d.prefixes, strings.TrimPrefix(entry, "www."))
+		} else if domain := strings.TrimSuffix(entry, "/"); domain != "" {
+			d.domains = append(d.domains, domain)
+		}
+	}
+	return d
+}
+
+// ParseDenylist reads denylist entries, one per line. Blank lines and lines
+// starting with # are skipped.
+func ParseDenylist(r io.Reader) ([]string, error) {
+	var entries []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		entries = append(entries, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read denylist: %w", err)
+	}
+	return entries, nil
+}
+
+// Len returns the number of entries in the denylist
+func (d *Denylist) Len() int {
+	if d == nil {
+		return 0
+	}
+	return len(d.domains) + len(d.prefixes)
+}
+
+// Blocks reports whether the result is on the denylist
+func (d *Denylist) Blocks(result WebPageResult) bool {
+	if d.Len() == 0 {
+		return false
+	}
+	if matchesAny(resultHost(result), d.domains) {
+		return true
+	}
+	location := strings.ToLower(result.URL)
+	location = strings.TrimPrefix(strings.TrimPrefix(location, "https://"), "http://")
+	location = strings.TrimPrefix(location, "www.")
+	for _, prefix := range d.prefixes {
+		if strings.HasPrefix(location, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// FilteringService wraps a Service and drops results on the static denylist
+// or the denylist downloaded from a feed. Both can be replaced at runtime.
+type FilteringService struct {
+	next   Service
+	static atomic.Pointer[Denylist]
+	feed   atomic.Pointer[Denylist]
+}
+
+// NewFilteringService creates a new service that drops results blocked by the denylist
+func NewFilteringService(next Service, denylist *Denylist) *FilteringService {
+	s := &FilteringService{
+		next: next,
+	}
+	s.static.Store(denylist)
+	return s
+}
+
+// SetDenylist atomically replaces the static denylist
+func (s *FilteringService) SetDenylist(denylist *Denylist) {
+	s.static.Store(denylist)
+}
+
+// SetFeed atomically replaces the denylist downloaded from the feed
+func (s *FilteringService) SetFeed(denylist *Denylist) {
+	s.feed.Store(denylist)
+}
+
+// Capabilities returns the capabilities of the wrapped provider
+func (s *FilteringService) Capabilities() Capabilities {
+	return CapabilitiesOf(s.next)
+}
+
+// Search forwards the search to the wrapped service and drops blocked results
+func (s *FilteringService) Search(ctx context.Context, query string, freshness string, count int, summary bool) (*WebSearchResponse, error) {
+	response, err := s.next.Search(ctx, query, freshness, count, summary)
+	if err != nil {
+		return nil, err
+	}
+
+	static, feed := s.static.Load(), s.feed.Load()
+	if static.Len() == 0 && feed.Len() == 0 {
+		return response, nil
+	}
+
+	// Never modify the wrapped service's response, which may be cached
+	var kept []WebPageResult
+	for _, result := range response.Data.WebPages.Value {
+		if !static.Blocks(result) && !feed.Blocks(result) {
+			kept = append(kept, result)
+		}
+	}
+	if len(kept) == len(response.Data.WebPages.Value) {
+		return response, nil
+	}
+	if kept == nil {
+		kept = []WebPageResult{}
+	}
+
+	filtered := *response
+	filtered.Data.WebPages.Value = kept
+	return &filtered, nil
+}
+
+// DenylistFeed periodically downloads a denylist into a FilteringService.
+// When a download fails the previous list stays in effect.
+type DenylistFeed struct {
+	url      string
+	interval time.Duration
+	target   *FilteringService
+	client   *http.Client
+
+	// OnUpdate and OnError, when set, are called after every refresh
+	OnUpdate func(entries int)
+	OnError  func(err error)
+}
+
+// NewDenylistFeed creates a feed that refreshes target from url every interval
+func NewDenylistFeed(url string, interval time.Duration, target *FilteringService) *DenylistFeed {
+	return &DenylistFeed{
+		url:      url,
+		interval: interval,
+		target:   target,
+		client:   &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Run refreshes the denylist immediately and then on the interval until ctx is canceled
+func (f *DenylistFeed) Run(ctx context.Context) {
+	ticker := time.NewTicker(f.interval)
+	defer ticker.Stop()
+	for {
+		if err := f.Refresh(ctx); err != nil && f.OnError != nil {
+			f.OnError(err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Refresh downloads the denylist once and installs it
+func (f *DenylistFeed) Refresh(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, f.url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create denylist feed request: %w", err)
+	}
+	req.Header.Set("User-Agent", "BochaWebSearchMCPServer/1.0")
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to download denylist feed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("denylist feed returned status code %d", resp.StatusCode)
+	}
+
+	entries, err := ParseDenylist(io.LimitReader(resp.Body, maxDenylistFeedSize))
+	if err != nil {
+		return err
+	}
+	denylist := NewDenylist(entries)
+	f.target.SetFeed(denylist)
+	if f.OnUpdate != nil {
+		f.OnUpdate(denylist.Len())
+	}
+	return nil
+}
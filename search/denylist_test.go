@@ -0,0 +1,118 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDenylist(t *testing.T) {
+	denylist := NewDenylist([]string{"Spam.example", "https://*.content-farm.test/", "news.example/sponsored/", " "})
+	if denylist.Len() != 3 {
+		t.Fatalf("Expected 3 entries, got %d", denylist.Len())
+	}
+
+	tests := []struct {
+		url     string
+		blocked bool
+	}{
+		{"https://spam.example/page", true},
+		{"https://www.spam.example/page", true},
+		{"https://notspam.example/page", false},
+		{"http://a.b.content-farm.test/", true},
+		{"https://www.news.example/sponsored/deal", true},
+		{"https://news.example/sponsoredlinks", false},
+		{"https://news.example/world", false},
+	}
+	for _, tt := range tests {
+		if got := denylist.Blocks(WebPageResult{URL: tt.url}); got != tt.blocked {
+			t.Errorf("Expected Blocks(%s) to be %v, got %v", tt.url, tt.blocked, got)
+		}
+	}
+
+	var empty *Denylist
+	if empty.Blocks(WebPageResult{URL: "https://spam.example"}) {
+		t.Error("Expected a nil denylist to block nothing")
+	}
+}
+
+func TestParseDenylist(t *testing.T) {
+	entries, err := ParseDenylist(strings.NewReader("# org blocklist\nspam.example\n\n  content-farm.test  \n"))
+	if err != nil {
+		t.Fatalf("ParseDenylist returned an error: %v", err)
+	}
+	if strings.Join(entries, ",") != "spam.example,content-farm.test" {
+		t.Errorf("Expected 2 entries, got %q", entries)
+	}
+}
+
+func TestFilteringService(t *testing.T) {
+	original := []WebPageResult{
+		{Name: "Good", URL: "https://go.dev"},
+		{Name: "Spam", URL: "https://spam.example/a"},
+		{Name: "Farm", URL: "https://content-farm.test/b"},
+	}
+	next := &recordingService{response: &WebSearchResponse{Data: Data{WebPages: WebPages{Value: original}}}}
+	filtering := NewFilteringService(next, NewDenylist([]string{"spam.example"}))
+
+	names := func() string {
+		response, err := filtering.Search(context.Background(), "golang", "", 10, false)
+		if err != nil {
+			t.Fatalf("Search returned an error: %v", err)
+		}
+		var names []string
+		for _, result := range response.Data.WebPages.Value {
+			names = append(names, result.Name)
+		}
+		return strings.Join(names, ",")
+	}
+
+	if got := names(); got != "Good,Farm" {
+		t.Errorf("Expected the static denylist to apply, got %s", got)
+	}
+	filtering.SetFeed(NewDenylist([]string{"content-farm.test"}))
+	if got := names(); got != "Good" {
+		t.Errorf("Expected the feed to apply too, got %s", got)
+	}
+	filtering.SetDenylist(nil)
+	if got := names(); got != "Good,Spam" {
+		t.Errorf("Expected only the feed after clearing the static list, got %s", got)
+	}
+	if len(next.response.Data.WebPages.Value) != 3 {
+		t.Error("Expected the wrapped response not to be modified")
+	}
+}
+
+func TestDenylistFeed(t *testing.T) {
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(status)
+		fmt.Fprintln(w, "# blocked\nspam.example")
+	}))
+	defer server.Close()
+
+	filtering := NewFilteringService(&recordingService{}, nil)
+	feed := NewDenylistFeed(server.URL, time.Minute, filtering)
+	updated := -1
+	feed.OnUpdate = func(entries int) { updated = entries }
+
+	if err := feed.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh returned an error: %v", err)
+	}
+	if updated != 1 || filtering.feed.Load().Len() != 1 {
+		t.Errorf("Expected a feed of 1 entry, got %d", updated)
+	}
+
+	// A failed refresh keeps the previous list
+	status = http.StatusInternalServerError
+	if err := feed.Refresh(context.Background()); err == nil {
+		t.Error("Expected an error for a failed download, got nil")
+	}
+	if filtering.feed.Load().Len() != 1 {
+		t.Error("Expected the previous list to stay in effect")
+	}
+}
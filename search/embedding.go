@@ -0,0 +1,87 @@
+package search
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"com.moguyn/mcp-go-search/config"
+)
+
+// maxEmbeddingResponseBytes bounds the response of the embedding endpoint
+const maxEmbeddingResponseBytes = 4 << 20
+
+// HTTPEmbedder embeds text with an OpenAI-compatible embeddings endpoint, for
+// semantic similarity a lexical embedder can't capture
+type HTTPEmbedder struct {
+	endpoint   string
+	model      string
+	token      string
+	httpClient *http.Client
+}
+
+// NewHTTPEmbedder creates an embedder calling SemanticCacheEmbeddingURL with
+// SemanticCacheEmbeddingModel, sending SemanticCacheEmbeddingToken as a bearer
+// token when it is set
+func NewHTTPEmbedder(cfg *config.Config) *HTTPEmbedder {
+	return &HTTPEmbedder{
+		endpoint:   cfg.SemanticCacheEmbeddingURL,
+		model:      cfg.SemanticCacheEmbeddingModel,
+		token:      cfg.SemanticCacheEmbeddingToken,
+		httpClient: NewHTTPClient(cfg),
+	}
+}
+
+// embeddingRequest is the body of an embeddings request
+type embeddingRequest struct {
+	Model string `json:"model,omitempty"`
+	Input string `json:"input"`
+}
+
+// embeddingResponse is the part of an embeddings response that is used
+type embeddingResponse struct {
+	Data []struct {
+		Embedding []float64 `json:"embedding"`
+	} `json:"data"`
+}
+
+// Embed returns the embedding of text computed by the endpoint
+func (e *HTTPEmbedder) Embed(ctx context.Context, text string) ([]float64, error) {
+	body, err := json.Marshal(embeddingRequest{Model: e.model, Input: text})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode embedding request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTTP request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "BochaWebSearchMCPServer/1.0")
+	if e.token != "" {
+		req.Header.Set("Authorization", "Bearer "+e.token)
+	}
+
+	resp, err := e.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send embedding request: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("embedding endpoint returned status code %d", resp.StatusCode)
+	}
+	raw, err := io.ReadAll(io.LimitReader(resp.Body, maxEmbeddingResponseBytes))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read embedding response: %w", err)
+	}
+	var parsed embeddingResponse
+	if err := json.Unmarshal(raw, &parsed); err != nil {
+		return nil, fmt.Errorf("failed to parse embedding response: %w", err)
+	}
+	if len(parsed.Data) == 0 || len(parsed.Data[0].Embedding) == 0 {
+		return nil, fmt.Errorf("embedding endpoint returned no embedding")
+	}
+	return parsed.Data[0].Embedding, nil
+}
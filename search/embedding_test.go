@@ -0,0 +1,48 @@
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"com.moguyn/mcp-go-search/config"
+)
+
+func TestHTTPEmbedder(t *testing.T) {
+	var request embeddingRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_ = json.NewDecoder(r.Body).Decode(&request)
+		if request.Input == "fail" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		_, _ = w.Write([]byte(`{"data":[{"embedding":[0.6,0.8]}]}`))
+	}))
+	defer server.Close()
+
+	embedder := NewHTTPEmbedder(&config.Config{
+		SemanticCacheEmbeddingURL:   server.URL,
+		SemanticCacheEmbeddingModel: "text-embedding-3-small",
+		SemanticCacheEmbeddingToken: "secret",
+	})
+	vector, err := embedder.Embed(context.Background(), "capital of France")
+	if err != nil {
+		t.Fatalf("Embed returned an error: %v", err)
+	}
+	if len(vector) != 2 || vector[0] != 0.6 || vector[1] != 0.8 {
+		t.Errorf("Unexpected embedding %v", vector)
+	}
+	if request.Model != "text-embedding-3-small" || request.Input != "capital of France" {
+		t.Errorf("Unexpected request %+v", request)
+	}
+
+	if _, err := embedder.Embed(context.Background(), "fail"); err == nil {
+		t.Error("Expected an error from a failing endpoint")
+	}
+}
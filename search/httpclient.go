@@ -0,0 +1,49 @@
+package search
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"time"
+
+	"com.moguyn/mcp-go-search/config"
+)
+
+// NewHTTPClient creates the client providers call their upstream API with. It
+// applies the configured TLS policy, dialer options and timeout and refuses
+// redirects to another host.
+func NewHTTPClient(cfg *config.Config) *http.Client {
+	// Create a secure transport with modern TLS configuration. The
+	// configuration is validated at startup, so an error here is unexpected.
+	tlsConfig, err := cfg.TLSConfig()
+	if err != nil {
+		tlsConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+	transport := &http.Transport{
+		TLSClientConfig:   tlsConfig,
+		ForceAttemptHTTP2: true,
+		MaxIdleConns:      100,
+		IdleConnTimeout:   90 * time.Second,
+	}
+	if dial, err := cfg.DialContext(); err == nil && dial != nil {
+		transport.DialContext = dial
+	}
+
+	return &http.Client{
+		Timeout:       cfg.HTTPTimeout,
+		Transport:     transport,
+		CheckRedirect: sameHostRedirect,
+	}
+}
+
+// sameHostRedirect refuses redirects to another host, so a compromised or
+// misconfigured endpoint cannot forward queries to a host that was never allowed
+func sameHostRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= 10 {
+		return fmt.Errorf("stopped after 10 redirects")
+	}
+	if req.URL.Host != via[0].URL.Host {
+		return fmt.Errorf("refusing redirect from %s to %s", via[0].URL.Host, req.URL.Host)
+	}
+	return nil
+}
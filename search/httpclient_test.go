@@ -0,0 +1,37 @@
+package search
+
+import (
+	"crypto/tls"
+	"net/http"
+	"testing"
+	"time"
+
+	"com.moguyn/mcp-go-search/config"
+)
+
+func TestNewHTTPClient(t *testing.T) {
+	client := NewHTTPClient(&config.Config{HTTPTimeout: 5 * time.Second, TLSMinVersion: config.TLSVersion13})
+	if client.Timeout != 5*time.Second {
+		t.Errorf("Expected timeout 5s, got %s", client.Timeout)
+	}
+	transport := client.Transport.(*http.Transport)
+	if transport.TLSClientConfig.MinVersion != tls.VersionTLS13 {
+		t.Errorf("Expected TLS 1.3 minimum, got %x", transport.TLSClientConfig.MinVersion)
+	}
+	if transport.DialContext != nil {
+		t.Error("Expected the default dialer without dialer options")
+	}
+	if NewHTTPClient(&config.Config{DialIPFamily: config.IPFamilyIPv4}).Transport.(*http.Transport).DialContext == nil {
+		t.Error("Expected the configured dialer with an IP family")
+	}
+
+	from, _ := http.NewRequest(http.MethodPost, "https://api.example.com/search", nil)
+	same, _ := http.NewRequest(http.MethodPost, "https://api.example.com/v2/search", nil)
+	other, _ := http.NewRequest(http.MethodPost, "https://attacker.example/collect", nil)
+	if err := client.CheckRedirect(same, []*http.Request{from}); err != nil {
+		t.Errorf("Expected a same-host redirect to be followed, got %v", err)
+	}
+	if err := client.CheckRedirect(other, []*http.Request{from}); err == nil {
+		t.Error("Expected a redirect to another host to be refused")
+	}
+}
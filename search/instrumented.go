@@ -0,0 +1,63 @@
+package search
+
+import (
+	"context"
+	"time"
+
+	"com.moguyn/mcp-go-search/stats"
+)
+
+// InstrumentedService wraps a provider and records the outcome of every call
+type InstrumentedService struct {
+	provider  string
+	next      Service
+	collector *stats.Collector
+}
+
+// NewInstrumentedService creates a new service recording calls to the named provider
+func NewInstrumentedService(provider string, next Service, collector *stats.Collector) *InstrumentedService {
+	return &InstrumentedService{
+		provider:  provider,
+		next:      next,
+		collector: collector,
+	}
+}
+
+// Capabilities returns the capabilities of the wrapped provider
+func (s *InstrumentedService) Capabilities() Capabilities {
+	return CapabilitiesOf(s.next)
+}
+
+// Search forwards the search to the wrapped provider and records its latency and outcome
+func (s *InstrumentedService) Search(ctx context.Context, query string, freshness string, count int, summary bool) (*WebSearchResponse, error) {
+	start := time.Now()
+	response, err := s.next.Search(ctx, query, freshness, count, summary)
+
+	call := stats.Call{
+		Provider: s.provider,
+		Query:    query,
+		Latency:  time.Since(start),
+		Err:      err,
+
+		Incognito: Incognito(ctx),
+	}
+	// Adjustments made before the call reached the provider count as well
+	adj := Adjustments(ctx)
+	call.QueryTruncated = adj.QueryTruncated
+	call.CountClamped = adj.CountClamped
+	if response != nil {
+		call.BytesSent = response.Meta.BytesSent
+		call.BytesReceived = response.Meta.BytesReceived
+		call.Results = len(response.Data.WebPages.Value)
+		for _, result := range response.Data.WebPages.Value {
+			call.ResultURLs = append(call.ResultURLs, result.URL)
+		}
+		call.QueryTruncated = call.QueryTruncated || response.Meta.QueryTruncated
+		call.CountClamped = call.CountClamped || response.Meta.CountClamped
+	} else {
+		call.BytesSent, call.BytesReceived = Transferred(err)
+	}
+	s.collector.Record(call)
+
+	return response, err
+}
@@ -0,0 +1,44 @@
+package search
+
+import (
+	"fmt"
+	"sync"
+)
+
+// KeyRotator is implemented by providers whose API key can be replaced while
+// they run, so a rotated secret takes effect without a restart
+type KeyRotator interface {
+	APIKey() string
+	SetAPIKey(apiKey string) error
+}
+
+// RotatableKey holds a provider's API key. Providers embed it to implement
+// KeyRotator and read the key with APIKey for every request.
+type RotatableKey struct {
+	mu  sync.RWMutex
+	key string
+}
+
+// NewRotatableKey creates a holder for apiKey, which may be empty for
+// providers that work without a key
+func NewRotatableKey(apiKey string) *RotatableKey {
+	return &RotatableKey{key: apiKey}
+}
+
+// APIKey returns the current API key
+func (k *RotatableKey) APIKey() string {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return k.key
+}
+
+// SetAPIKey atomically replaces the API key used for subsequent requests
+func (k *RotatableKey) SetAPIKey(apiKey string) error {
+	if apiKey == "" {
+		return fmt.Errorf("api key cannot be empty")
+	}
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.key = apiKey
+	return nil
+}
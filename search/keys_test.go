@@ -0,0 +1,16 @@
+package search
+
+import "testing"
+
+func TestRotatableKey(t *testing.T) {
+	var rotator KeyRotator = NewRotatableKey("original-key")
+	if rotator.APIKey() != "original-key" {
+		t.Errorf("Expected the initial key, got %q", rotator.APIKey())
+	}
+	if err := rotator.SetAPIKey("rotated-key"); err != nil || rotator.APIKey() != "rotated-key" {
+		t.Errorf("Expected the rotated key, got %q, %v", rotator.APIKey(), err)
+	}
+	if err := rotator.SetAPIKey(""); err == nil || rotator.APIKey() != "rotated-key" {
+		t.Errorf("Expected an empty key to be rejected and the current one kept, got %q, %v", rotator.APIKey(), err)
+	}
+}
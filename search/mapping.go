@@ -0,0 +1,63 @@
+package search
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"com.moguyn/mcp-go-search/jsonpath"
+)
+
+// ParseMapped decodes a JSON document and maps it onto the common response
+// format with paths, keyed by the field names of config.GenericMapping.
+// Results without a URL are skipped, and the URL stands in for a missing
+// title. A document without results at the results path has no results.
+func ParseMapped(body []byte, paths map[string]jsonpath.Path) (*WebSearchResponse, error) {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var doc any
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, err
+	}
+
+	found, ok := paths["results"].Lookup(doc)
+	items, isList := found.([]any)
+	if ok && found != nil && !isList {
+		return nil, fmt.Errorf("no result list at %s", paths["results"])
+	}
+
+	lookup := func(name string, doc any) string {
+		path, ok := paths[name]
+		if !ok {
+			return ""
+		}
+		return path.LookupString(doc)
+	}
+	results := make([]WebPageResult, 0, len(items))
+	for _, item := range items {
+		result := WebPageResult{
+			Name:            lookup("title", item),
+			URL:             lookup("url", item),
+			Snippet:         lookup("snippet", item),
+			DateLastCrawled: lookup("date", item),
+			SiteName:        lookup("site_name", item),
+		}
+		if result.URL == "" {
+			continue
+		}
+		result.DisplayURL = result.URL
+		if result.Name == "" {
+			result.Name = result.URL
+		}
+		results = append(results, result)
+	}
+
+	response := &WebSearchResponse{Code: http.StatusOK}
+	response.Data.WebPages.Value = results
+	if total, err := strconv.Atoi(lookup("total", doc)); err == nil {
+		response.Data.WebPages.TotalEstimatedMatches = total
+	}
+	return response, nil
+}
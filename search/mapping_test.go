@@ -0,0 +1,44 @@
+package search
+
+import (
+	"testing"
+
+	"com.moguyn/mcp-go-search/jsonpath"
+)
+
+func TestParseMapped(t *testing.T) {
+	paths := make(map[string]jsonpath.Path)
+	for name, expr := range map[string]string{"results": "data.items", "title": "name", "url": "links[0]", "total": "data.count"} {
+		path, err := jsonpath.Compile(expr)
+		if err != nil {
+			t.Fatalf("Compile(%q) returned an error: %v", expr, err)
+		}
+		paths[name] = path
+	}
+
+	body := `{"data": {"count": 12, "items": [{"name": "Go", "links": ["https://go.dev/"]}, {"name": "No link"}, {"links": ["https://example.com/"]}]}}`
+	response, err := ParseMapped([]byte(body), paths)
+	if err != nil {
+		t.Fatalf("ParseMapped returned an error: %v", err)
+	}
+	results := response.Data.WebPages.Value
+	if len(results) != 2 {
+		t.Fatalf("Expected the 2 results with a URL, got %d", len(results))
+	}
+	if results[0].Name != "Go" || results[0].DisplayURL != "https://go.dev/" {
+		t.Errorf("Unexpected first result: %+v", results[0])
+	}
+	if results[1].Name != "https://example.com/" {
+		t.Errorf("Expected the URL as name without a title, got %q", results[1].Name)
+	}
+	if response.Data.WebPages.TotalEstimatedMatches != 12 {
+		t.Errorf("Expected 12 total matches, got %d", response.Data.WebPages.TotalEstimatedMatches)
+	}
+
+	if response, err := ParseMapped([]byte(`{"data": {}}`), paths); err != nil || len(response.Data.WebPages.Value) != 0 {
+		t.Errorf("Expected no results without a result list, got %v (%v)", response, err)
+	}
+	if _, err := ParseMapped([]byte(`{"data": {"items": "none"}}`), paths); err == nil || err.Error() != "no result list at data.items" {
+		t.Errorf("Expected error for a result list that isn't a list, got %v", err)
+	}
+}
@@ -0,0 +1,179 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"com.moguyn/mcp-go-search/params"
+)
+
+// exactQueryKey marks a context asking for the query to be searched as written
+type exactQueryKey struct{}
+
+// exactCountKey marks a context asking for the total number of matches to be counted exactly
+type exactCountKey struct{}
+
+// incognitoKey marks a context whose search must not be recorded anywhere
+type incognitoKey struct{}
+
+// providerKey holds the provider a context's search is sent to
+type providerKey struct{}
+
+// providersKey holds the aggregate providers a context's search queries
+type providersKey struct{}
+
+// autoCountKey marks a context whose count is a minimum number of results
+type autoCountKey struct{}
+
+// proKey marks a context whose search goes to the provider's pro endpoint
+type proKey struct{}
+
+// aiSearchKey marks a context whose search goes to the provider's AI search
+type aiSearchKey struct{}
+
+// agentKey holds the agent a context's search is handed to
+type agentKey struct{}
+
+// adjustmentsKey holds the adjustments made to a context's search parameters
+// before they reached the provider
+type adjustmentsKey struct{}
+
+// WithExactQuery returns a context asking the provider not to spell-correct the
+// query. Only providers whose capabilities report ExactQuery honor it.
+func WithExactQuery(ctx context.Context) context.Context {
+	return context.WithValue(ctx, exactQueryKey{}, true)
+}
+
+// ExactQuery reports whether ctx asks for the query to be searched as written
+func ExactQuery(ctx context.Context) bool {
+	exact, _ := ctx.Value(exactQueryKey{}).(bool)
+	return exact
+}
+
+// WithExactCount returns a context asking the provider to count the total
+// matches exactly instead of estimating them. Only providers whose capabilities
+// report ExactCount honor it.
+func WithExactCount(ctx context.Context) context.Context {
+	return context.WithValue(ctx, exactCountKey{}, true)
+}
+
+// ExactCount reports whether ctx asks for the total matches to be counted exactly
+func ExactCount(ctx context.Context) bool {
+	exact, _ := ctx.Value(exactCountKey{}).(bool)
+	return exact
+}
+
+// WithAdjustments returns a context recording that the search's parameters
+// were adjusted before reaching the provider, e.g. by the tool layer, on top of
+// the adjustments ctx already records
+func WithAdjustments(ctx context.Context, adj params.Adjustments) context.Context {
+	previous := Adjustments(ctx)
+	adj = previous.Merge(adj)
+	if adj == previous {
+		return ctx
+	}
+	return context.WithValue(ctx, adjustmentsKey{}, adj)
+}
+
+// Adjustments returns the adjustments ctx records
+func Adjustments(ctx context.Context) params.Adjustments {
+	adj, _ := ctx.Value(adjustmentsKey{}).(params.Adjustments)
+	return adj
+}
+
+// WithIncognito returns a context for a search that must not be cached,
+// listed among the recent queries or written to the audit log
+func WithIncognito(ctx context.Context) context.Context {
+	return context.WithValue(ctx, incognitoKey{}, true)
+}
+
+// Incognito reports whether ctx is for a search that must not be recorded
+func Incognito(ctx context.Context) bool {
+	incognito, _ := ctx.Value(incognitoKey{}).(bool)
+	return incognito
+}
+
+// WithProvider returns a context for a search sent to the named provider
+// instead of the configured one. Only a Router honors it.
+func WithProvider(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, providerKey{}, name)
+}
+
+// SelectedProvider returns the provider ctx's search is sent to, or "" for the
+// configured one
+func SelectedProvider(ctx context.Context) string {
+	name, _ := ctx.Value(providerKey{}).(string)
+	return name
+}
+
+// WithProviders returns a context for a search that queries only the named
+// aggregate providers. Only an Aggregator honors it.
+func WithProviders(ctx context.Context, names []string) context.Context {
+	return context.WithValue(ctx, providersKey{}, names)
+}
+
+// SelectedProviders returns the aggregate providers ctx's search queries, or
+// nil for all of them
+func SelectedProviders(ctx context.Context) []string {
+	names, _ := ctx.Value(providersKey{}).([]string)
+	return names
+}
+
+// WithAutoCount returns a context treating the count of its search as the
+// minimum number of results to return after filtering. Only an
+// AdaptiveCountService honors it.
+func WithAutoCount(ctx context.Context) context.Context {
+	return context.WithValue(ctx, autoCountKey{}, true)
+}
+
+// AutoCount reports whether ctx treats the count as a minimum
+func AutoCount(ctx context.Context) bool {
+	auto, _ := ctx.Value(autoCountKey{}).(bool)
+	return auto
+}
+
+// WithPro returns a context asking the provider for its pro endpoint, which
+// adds an answer and rich snippets. Only providers whose capabilities report
+// Pro honor it.
+func WithPro(ctx context.Context) context.Context {
+	return context.WithValue(ctx, proKey{}, true)
+}
+
+// Pro reports whether ctx asks for the provider's pro endpoint
+func Pro(ctx context.Context) bool {
+	pro, _ := ctx.Value(proKey{}).(bool)
+	return pro
+}
+
+// WithAISearch returns a context asking the provider for its AI search, which
+// adds a written answer and modal cards. Only providers whose capabilities
+// report AISearch honor it.
+func WithAISearch(ctx context.Context) context.Context {
+	return context.WithValue(ctx, aiSearchKey{}, true)
+}
+
+// AISearch reports whether ctx asks for the provider's AI search
+func AISearch(ctx context.Context) bool {
+	ai, _ := ctx.Value(aiSearchKey{}).(bool)
+	return ai
+}
+
+// WithAgent returns a context asking the provider to hand the search to one of
+// its vertical agents, such as a scholar or company lookup. Only providers
+// whose capabilities report AgentSearch honor it.
+func WithAgent(ctx context.Context, agent string) context.Context {
+	return context.WithValue(ctx, agentKey{}, agent)
+}
+
+// Agent returns the agent ctx asks the search to be handed to, or "" for none
+func Agent(ctx context.Context) string {
+	agent, _ := ctx.Value(agentKey{}).(string)
+	return agent
+}
+
+// optionsKey distinguishes cache entries for searches made with different options
+func optionsKey(ctx context.Context) string {
+	return fmt.Sprintf("%t\x00%t\x00%t\x00%t\x00%s\x00%s\x00%s", ExactQuery(ctx), ExactCount(ctx), Pro(ctx), AISearch(ctx),
+		Agent(ctx), SelectedProvider(ctx), strings.Join(SelectedProviders(ctx), ","))
+}
@@ -0,0 +1,85 @@
+package search
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestExactQuery(t *testing.T) {
+	if ExactQuery(context.Background()) {
+		t.Error("Expected a plain context not to ask for an exact query")
+	}
+	if !ExactQuery(WithExactQuery(context.Background())) {
+		t.Error("Expected WithExactQuery to ask for an exact query")
+	}
+	if !ExactCount(WithExactCount(context.Background())) || ExactCount(context.Background()) {
+		t.Error("Expected only WithExactCount to ask for an exact count")
+	}
+	if !Pro(WithPro(context.Background())) || Pro(context.Background()) {
+		t.Error("Expected only WithPro to ask for the pro endpoint")
+	}
+	if !AISearch(WithAISearch(context.Background())) || AISearch(context.Background()) {
+		t.Error("Expected only WithAISearch to ask for the AI search")
+	}
+	if Agent(WithAgent(context.Background(), "bocha-scholar-agent")) != "bocha-scholar-agent" || Agent(context.Background()) != "" {
+		t.Error("Expected only WithAgent to name an agent")
+	}
+}
+
+func TestCachingService_Options(t *testing.T) {
+	next := &recordingService{}
+	cache := NewCachingService(next, time.Minute, 10)
+	ctx := context.Background()
+
+	if _, err := cache.Search(ctx, "golang", "noLimit", 10, false); err != nil {
+		t.Fatalf("Search returned an error: %v", err)
+	}
+	if _, err := cache.Search(WithExactQuery(ctx), "golang", "noLimit", 10, false); err != nil {
+		t.Fatalf("Search returned an error: %v", err)
+	}
+	if _, err := cache.Search(WithExactCount(ctx), "golang", "noLimit", 10, false); err != nil {
+		t.Fatalf("Search returned an error: %v", err)
+	}
+	if _, err := cache.Search(WithPro(ctx), "golang", "noLimit", 10, false); err != nil {
+		t.Fatalf("Search returned an error: %v", err)
+	}
+	if _, err := cache.Search(WithProviders(ctx, []string{"brave"}), "golang", "noLimit", 10, false); err != nil {
+		t.Fatalf("Search returned an error: %v", err)
+	}
+	if _, err := cache.Search(WithAISearch(ctx), "golang", "noLimit", 10, false); err != nil {
+		t.Fatalf("Search returned an error: %v", err)
+	}
+	if _, err := cache.Search(WithAgent(ctx, "bocha-scholar-agent"), "golang", "noLimit", 10, false); err != nil {
+		t.Fatalf("Search returned an error: %v", err)
+	}
+	if next.calls != 7 {
+		t.Errorf("Expected searches with different options to be cached separately, got %d upstream calls", next.calls)
+	}
+}
+
+func TestIncognito_SkipsCachesAndAudit(t *testing.T) {
+	next := &recordingService{response: &WebSearchResponse{Data: Data{WebPages: WebPages{Value: safetyResults()}}}}
+	cache := NewCachingService(next, time.Minute, 10)
+	ctx := WithIncognito(context.Background())
+
+	for i := 0; i < 2; i++ {
+		if _, err := cache.Search(ctx, "golang", "noLimit", 10, false); err != nil {
+			t.Fatalf("Search returned an error: %v", err)
+		}
+	}
+	if next.calls != 2 || cache.Stats().Entries != 0 {
+		t.Errorf("Expected incognito searches to bypass the cache, got %d upstream calls and %d entries", next.calls, cache.Stats().Entries)
+	}
+
+	audited := 0
+	safety := NewSafetyService(next, true, NewKeywordClassifier(nil))
+	safety.OnFiltered = func(WebPageResult, Verdict, bool) { audited++ }
+	response, err := safety.Search(ctx, "golang", "", 10, false)
+	if err != nil {
+		t.Fatalf("Search returned an error: %v", err)
+	}
+	if audited != 0 || len(response.Data.WebPages.Value) != 2 {
+		t.Errorf("Expected unsafe results removed without auditing, got %d audited and %d results", audited, len(response.Data.WebPages.Value))
+	}
+}
@@ -0,0 +1,138 @@
+package search
+
+import (
+	"context"
+	"html"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// htmlTag matches markup some providers leave in titles and snippets, such as
+// <em> highlighting
+var htmlTag = regexp.MustCompile(`<[^>]*>`)
+
+// DedupeService wraps a Service and drops results whose URL repeats an earlier
+// one, ignoring the scheme, a leading www., a trailing slash and the fragment
+type DedupeService struct {
+	next Service
+}
+
+// NewDedupeService creates a new service that removes duplicate results
+func NewDedupeService(next Service) *DedupeService {
+	return &DedupeService{next: next}
+}
+
+// Capabilities returns the capabilities of the wrapped provider
+func (s *DedupeService) Capabilities() Capabilities {
+	return CapabilitiesOf(s.next)
+}
+
+// Search forwards the search to the wrapped service and removes duplicates
+func (s *DedupeService) Search(ctx context.Context, query string, freshness string, count int, summary bool) (*WebSearchResponse, error) {
+	response, err := s.next.Search(ctx, query, freshness, count, summary)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool, len(response.Data.WebPages.Value))
+	kept := make([]WebPageResult, 0, len(response.Data.WebPages.Value))
+	for _, result := range response.Data.WebPages.Value {
+		key := dedupeKey(result.URL)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		kept = append(kept, result)
+	}
+	if len(kept) == len(response.Data.WebPages.Value) {
+		return response, nil
+	}
+
+	// Never modify the wrapped service's response, which may be cached
+	deduped := *response
+	deduped.Data.WebPages.Value = kept
+	return &deduped, nil
+}
+
+// dedupeKey returns the form of a URL compared when removing duplicates
+func dedupeKey(rawURL string) string {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || u.Host == "" {
+		return rawURL
+	}
+	host := strings.TrimPrefix(strings.ToLower(u.Host), "www.")
+	return host + strings.TrimSuffix(u.EscapedPath(), "/") + "?" + u.RawQuery
+}
+
+// TruncateService wraps a Service and returns at most the requested number of
+// results, since earlier stages such as pinning may add some
+type TruncateService struct {
+	next Service
+}
+
+// NewTruncateService creates a new service that caps results at the requested count
+func NewTruncateService(next Service) *TruncateService {
+	return &TruncateService{next: next}
+}
+
+// Capabilities returns the capabilities of the wrapped provider
+func (s *TruncateService) Capabilities() Capabilities {
+	return CapabilitiesOf(s.next)
+}
+
+// Search forwards the search to the wrapped service and drops surplus results
+func (s *TruncateService) Search(ctx context.Context, query string, freshness string, count int, summary bool) (*WebSearchResponse, error) {
+	response, err := s.next.Search(ctx, query, freshness, count, summary)
+	if err != nil {
+		return nil, err
+	}
+	if count <= 0 || len(response.Data.WebPages.Value) <= count {
+		return response, nil
+	}
+
+	truncated := *response
+	truncated.Data.WebPages.Value = response.Data.WebPages.Value[:count:count]
+	return &truncated, nil
+}
+
+// FormatService wraps a Service and tidies result text: markup is stripped,
+// entities are decoded and whitespace is collapsed in titles and snippets
+type FormatService struct {
+	next Service
+}
+
+// NewFormatService creates a new service that cleans up result text
+func NewFormatService(next Service) *FormatService {
+	return &FormatService{next: next}
+}
+
+// Capabilities returns the capabilities of the wrapped provider
+func (s *FormatService) Capabilities() Capabilities {
+	return CapabilitiesOf(s.next)
+}
+
+// Search forwards the search to the wrapped service and cleans up its results
+func (s *FormatService) Search(ctx context.Context, query string, freshness string, count int, summary bool) (*WebSearchResponse, error) {
+	response, err := s.next.Search(ctx, query, freshness, count, summary)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]WebPageResult, len(response.Data.WebPages.Value))
+	for i, result := range response.Data.WebPages.Value {
+		result.Name = cleanText(result.Name)
+		result.Snippet = cleanText(result.Snippet)
+		results[i] = result
+	}
+
+	formatted := *response
+	formatted.Data.WebPages.Value = results
+	return &formatted, nil
+}
+
+// cleanText strips markup from text, decodes entities and collapses whitespace
+func cleanText(text string) string {
+	text = html.UnescapeString(htmlTag.ReplaceAllString(text, ""))
+	return strings.Join(strings.Fields(text), " ")
+}
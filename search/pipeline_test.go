@@ -0,0 +1,71 @@
+package search
+
+import (
+	"context"
+	"testing"
+)
+
+func TestDedupeService(t *testing.T) {
+	original := []WebPageResult{
+		{Name: "A", URL: "https://go.dev/doc/"},
+		{Name: "A again", URL: "http://www.go.dev/doc#install"},
+		{Name: "B", URL: "https://go.dev/doc?page=2"},
+		{Name: "C", URL: "https://pkg.go.dev"},
+	}
+	next := &recordingService{response: &WebSearchResponse{Data: Data{WebPages: WebPages{Value: original}}}}
+
+	response, err := NewDedupeService(next).Search(context.Background(), "go", "", 10, false)
+	if err != nil {
+		t.Fatalf("Search returned an error: %v", err)
+	}
+	results := response.Data.WebPages.Value
+	if len(results) != 3 || results[0].Name != "A" || results[1].Name != "B" || results[2].Name != "C" {
+		t.Errorf("Expected A, B and C, got %+v", results)
+	}
+	if len(original) != 4 {
+		t.Error("Expected the wrapped response to be left unchanged")
+	}
+}
+
+func TestTruncateService(t *testing.T) {
+	original := []WebPageResult{{URL: "https://a.example"}, {URL: "https://b.example"}, {URL: "https://c.example"}}
+	next := &recordingService{response: &WebSearchResponse{Data: Data{WebPages: WebPages{Value: original}}}}
+	truncate := NewTruncateService(next)
+
+	response, err := truncate.Search(context.Background(), "q", "", 2, false)
+	if err != nil {
+		t.Fatalf("Search returned an error: %v", err)
+	}
+	if len(response.Data.WebPages.Value) != 2 {
+		t.Errorf("Expected 2 results, got %d", len(response.Data.WebPages.Value))
+	}
+
+	response, _ = truncate.Search(context.Background(), "q", "", 10, false)
+	if len(response.Data.WebPages.Value) != 3 {
+		t.Errorf("Expected 3 results, got %d", len(response.Data.WebPages.Value))
+	}
+}
+
+func TestFormatService(t *testing.T) {
+	original := []WebPageResult{{
+		Name:    "The <em>Go</em> &amp; You",
+		URL:     "https://go.dev",
+		Snippet: "  Build   simple,\n secure <b>software</b>  ",
+	}}
+	next := &recordingService{response: &WebSearchResponse{Data: Data{WebPages: WebPages{Value: original}}}}
+
+	response, err := NewFormatService(next).Search(context.Background(), "go", "", 10, false)
+	if err != nil {
+		t.Fatalf("Search returned an error: %v", err)
+	}
+	result := response.Data.WebPages.Value[0]
+	if result.Name != "The Go & You" {
+		t.Errorf("Expected cleaned title, got %q", result.Name)
+	}
+	if result.Snippet != "Build simple, secure software" {
+		t.Errorf("Expected cleaned snippet, got %q", result.Snippet)
+	}
+	if original[0].Name != "The <em>Go</em> &amp; You" {
+		t.Error("Expected the wrapped response to be left unchanged")
+	}
+}
@@ -0,0 +1,253 @@
+package search
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sync"
+
+	"com.moguyn/mcp-go-search/config"
+	"com.moguyn/mcp-go-search/params"
+)
+
+// maxPluginMessageSize bounds a single response line read from a plugin
+const maxPluginMessageSize = 10 * 1024 * 1024 // 10MB, same as the HTTP response limit
+
+func init() {
+	Register(config.ProviderPlugin, func(cfg *config.Config) (Provider, error) {
+		return NewPluginService(cfg.PluginCommand, cfg.PluginArgs...), nil
+	})
+}
+
+// PluginRequest is the message written to a plugin's stdin for each search.
+// Every message is a single line of JSON.
+type PluginRequest struct {
+	ID        uint64 `json:"id"`
+	Query     string `json:"query"`
+	Freshness string `json:"freshness"`
+	Count     int    `json:"count"`
+	Summary   bool   `json:"summary"`
+	// NoAutocorrect asks the plugin to search for the query as written
+	NoAutocorrect bool `json:"no_autocorrect,omitempty"`
+	// ExactCount asks the plugin to count the total matches exactly
+	ExactCount bool `json:"exact_count,omitempty"`
+}
+
+// PluginResponse is the message a plugin writes to its stdout in reply to a request.
+// The ID must echo the request ID; exactly one of Result or Error should be set.
+type PluginResponse struct {
+	ID     uint64             `json:"id"`
+	Result *WebSearchResponse `json:"result,omitempty"`
+	Error  string             `json:"error,omitempty"`
+}
+
+// PluginService implements the Provider interface by delegating searches to an
+// external subprocess speaking line-delimited JSON over stdin/stdout
+type PluginService struct {
+	command string
+	args    []string
+
+	mu      sync.Mutex
+	cmd     *exec.Cmd
+	stdin   io.WriteCloser
+	stdout  io.ReadCloser
+	scanner *bufio.Scanner
+	nextID  uint64
+	// reading is closed when the goroutine reading the last reply returns
+	reading chan struct{}
+}
+
+// NewPluginService creates a new plugin-backed service. The subprocess is
+// started lazily on the first search and restarted if it exits.
+func NewPluginService(command string, args ...string) *PluginService {
+	return &PluginService{
+		command: command,
+		args:    args,
+	}
+}
+
+// Search sends the search to the plugin subprocess and waits for its reply
+func (s *PluginService) Search(ctx context.Context, query string, freshness string, count int, summary bool) (*WebSearchResponse, error) {
+	// Validate inputs and bring them within the plugin's limits
+	p, adj, err := params.Normalize(params.Search{
+		Query:     query,
+		Freshness: freshness,
+		Count:     count,
+		Summary:   summary,
+	}, s.Capabilities().Limits())
+	if err != nil {
+		return nil, err
+	}
+	meta := ResponseMeta{
+		QueryTruncated: adj.QueryTruncated,
+		CountClamped:   adj.CountClamped,
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.start(); err != nil {
+		return nil, err
+	}
+
+	s.nextID++
+	req := PluginRequest{
+		ID:        s.nextID,
+		Query:     p.Query,
+		Freshness: p.Freshness,
+		Count:     p.Count,
+		Summary:   p.Summary,
+
+		NoAutocorrect: ExactQuery(ctx),
+		ExactCount:    ExactCount(ctx),
+	}
+
+	line, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal plugin request: %w", err)
+	}
+	if _, err := s.stdin.Write(append(line, '\n')); err != nil {
+		s.stop()
+		return nil, fmt.Errorf("failed to write to plugin: %w", err)
+	}
+
+	// Read the reply in the background so the caller's context is honored
+	type readResult struct {
+		data []byte
+		err  error
+	}
+	done := make(chan readResult, 1)
+	scanner := s.scanner
+	reading := make(chan struct{})
+	s.reading = reading
+	go func() {
+		defer close(reading)
+		if scanner.Scan() {
+			done <- readResult{data: scanner.Bytes()}
+			return
+		}
+		err := scanner.Err()
+		if err == nil {
+			err = io.EOF
+		}
+		done <- readResult{err: err}
+	}()
+
+	var res readResult
+	select {
+	case <-ctx.Done():
+		// The stream is out of sync once a reply is abandoned, so start over
+		s.stop()
+		return nil, fmt.Errorf("plugin search canceled: %w", ctx.Err())
+	case res = <-done:
+	}
+
+	if res.err != nil {
+		s.stop()
+		return nil, fmt.Errorf("failed to read from plugin: %w", res.err)
+	}
+
+	var resp PluginResponse
+	if err := json.Unmarshal(res.data, &resp); err != nil {
+		s.stop()
+		return nil, fmt.Errorf("failed to parse plugin response: %w", err)
+	}
+	if resp.ID != req.ID {
+		s.stop()
+		return nil, fmt.Errorf("plugin response id %d does not match request id %d", resp.ID, req.ID)
+	}
+	if resp.Error != "" {
+		return nil, fmt.Errorf("plugin error: %s", resp.Error)
+	}
+	if resp.Result == nil || resp.Result.Data.WebPages.Value == nil {
+		return nil, fmt.Errorf("plugin returned empty or invalid response")
+	}
+
+	meta.BytesSent = int64(len(line) + 1)
+	meta.BytesReceived = int64(len(res.data) + 1)
+	resp.Result.Meta = meta
+
+	return resp.Result, nil
+}
+
+// Name returns the provider name used in configuration
+func (s *PluginService) Name() string {
+	return config.ProviderPlugin
+}
+
+// Capabilities describes the plugin. Plugins do not describe themselves, so
+// every tool parameter is passed through for the plugin to accept or reject.
+func (s *PluginService) Capabilities() Capabilities {
+	return DefaultCapabilities(config.ProviderPlugin)
+}
+
+// Close stops the plugin subprocess if it is running
+func (s *PluginService) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stop()
+	return nil
+}
+
+// start launches the subprocess if it is not already running.
+// The caller must hold s.mu.
+func (s *PluginService) start() error {
+	if s.cmd != nil {
+		return nil
+	}
+
+	// #nosec G204 -- the plugin command is supplied by the operator's configuration
+	cmd := exec.Command(s.command, s.args...)
+	// Plugin logs go to our stderr; stdout is reserved for the protocol
+	cmd.Stderr = os.Stderr
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return fmt.Errorf("failed to create plugin stdin pipe: %w", err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("failed to create plugin stdout pipe: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start plugin %q: %w", s.command, err)
+	}
+
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 64*1024), maxPluginMessageSize)
+
+	s.cmd = cmd
+	s.stdin = stdin
+	s.stdout = stdout
+	s.scanner = scanner
+	return nil
+}
+
+// stop terminates the subprocess so the next search starts a fresh one.
+// The caller must hold s.mu.
+func (s *PluginService) stop() {
+	if s.cmd == nil {
+		return
+	}
+	_ = s.stdin.Close()
+	if s.cmd.Process != nil {
+		_ = s.cmd.Process.Kill()
+	}
+	// Wait closes stdout, so it must not be called while a reply is being
+	// read. Closing our end first ends the read even if a child of the plugin
+	// still holds the pipe open.
+	_ = s.stdout.Close()
+	if s.reading != nil {
+		<-s.reading
+	}
+	_ = s.cmd.Wait()
+	s.cmd = nil
+	s.stdin = nil
+	s.stdout = nil
+	s.scanner = nil
+	s.reading = nil
+}
@@ -0,0 +1,124 @@
+package search
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestPluginHelperProcess is not a real test. It is executed as the plugin
+// subprocess by the PluginService tests below.
+func TestPluginHelperProcess(_ *testing.T) {
+	mode := os.Getenv("GO_PLUGIN_HELPER_MODE")
+	if mode == "" {
+		return
+	}
+	defer os.Exit(0)
+
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		var req PluginRequest
+		if err := json.Unmarshal(scanner.Bytes(), &req); err != nil {
+			os.Exit(2)
+		}
+
+		resp := PluginResponse{ID: req.ID}
+		switch mode {
+		case "error":
+			resp.Error = "backend unavailable"
+		case "hang":
+			time.Sleep(time.Minute)
+		case "exit":
+			os.Exit(1)
+		default:
+			resp.Result = &WebSearchResponse{
+				Code: 200,
+				Data: Data{
+					QueryContext: QueryContext{OriginalQuery: req.Query},
+					WebPages: WebPages{
+						Value: []WebPageResult{
+							{Name: fmt.Sprintf("%s result (%d, %s)", req.Query, req.Count, req.Freshness), URL: "https://example.com"},
+						},
+					},
+				},
+			}
+		}
+
+		out, _ := json.Marshal(resp)
+		fmt.Println(string(out))
+	}
+}
+
+// newHelperPluginService starts this test binary as a plugin in the given mode
+func newHelperPluginService(t *testing.T, mode string) *PluginService {
+	t.Helper()
+	t.Setenv("GO_PLUGIN_HELPER_MODE", mode)
+	service := NewPluginService(os.Args[0], "-test.run=TestPluginHelperProcess")
+	t.Cleanup(func() { _ = service.Close() })
+	return service
+}
+
+func TestPluginService_Search(t *testing.T) {
+	service := newHelperPluginService(t, "ok")
+
+	// Issue several searches to make sure the subprocess is reused
+	for i := 0; i < 3; i++ {
+		resp, err := service.Search(context.Background(), "golang", "week", 100, false)
+		if err != nil {
+			t.Fatalf("Search returned an error: %v", err)
+		}
+		if len(resp.Data.WebPages.Value) != 1 {
+			t.Fatalf("Expected 1 result, got %d", len(resp.Data.WebPages.Value))
+		}
+		if resp.Data.WebPages.Value[0].Name != "golang result (50, week)" {
+			t.Errorf("Unexpected result name: %s", resp.Data.WebPages.Value[0].Name)
+		}
+	}
+}
+
+func TestPluginService_Search_Errors(t *testing.T) {
+	// Empty query is rejected before the plugin is started
+	service := NewPluginService("/nonexistent/plugin")
+	if _, err := service.Search(context.Background(), "", "", 10, false); err == nil {
+		t.Error("Expected error for empty query, got nil")
+	}
+
+	// Missing binary
+	if _, err := service.Search(context.Background(), "test", "", 10, false); err == nil {
+		t.Error("Expected error for missing plugin binary, got nil")
+	}
+
+	// Plugin-reported error
+	service = newHelperPluginService(t, "error")
+	_, err := service.Search(context.Background(), "test", "", 10, false)
+	if err == nil || !strings.Contains(err.Error(), "backend unavailable") {
+		t.Errorf("Expected plugin error, got %v", err)
+	}
+
+	// Plugin exits mid-request
+	service = newHelperPluginService(t, "exit")
+	if _, err := service.Search(context.Background(), "test", "", 10, false); err == nil {
+		t.Error("Expected error when plugin exits, got nil")
+	}
+}
+
+func TestPluginService_Search_ContextCanceled(t *testing.T) {
+	service := newHelperPluginService(t, "hang")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	_, err := service.Search(ctx, "test", "", 10, false)
+	if err == nil || !strings.Contains(err.Error(), "canceled") {
+		t.Errorf("Expected cancellation error, got %v", err)
+	}
+	// The abandoned read is joined before the subprocess is reaped
+	if service.cmd != nil || service.reading != nil {
+		t.Error("Expected the plugin to be stopped after cancellation")
+	}
+}
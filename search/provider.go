@@ -0,0 +1,75 @@
+package search
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"com.moguyn/mcp-go-search/config"
+)
+
+// Provider is a search backend. Engines implement it and register a factory
+// with Register, so they can be selected with SEARCH_PROVIDER without changes
+// to the tools or decorators, which only see the Service interface.
+type Provider interface {
+	Service
+	// Name identifies the provider in configuration, logs and errors
+	Name() string
+	// Capabilities describes what the provider supports
+	Capabilities() Capabilities
+}
+
+// Factory creates a provider from the configuration
+type Factory func(cfg *config.Config) (Provider, error)
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]Factory)
+)
+
+// Register makes a provider available under name, usually from the init
+// function of the provider's package. Registering a name twice panics.
+func Register(name string, factory Factory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if factory == nil {
+		panic("search: Register factory is nil for provider " + name)
+	}
+	if _, dup := registry[name]; dup {
+		panic("search: Register called twice for provider " + name)
+	}
+	registry[name] = factory
+}
+
+// Providers returns the names of the registered providers, sorted
+func Providers() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewProvider creates the provider selected by SearchProvider, Bocha when it is unset
+func NewProvider(cfg *config.Config) (Provider, error) {
+	name := cfg.SearchProvider
+	if name == "" {
+		name = config.ProviderBocha
+	}
+	registryMu.RLock()
+	factory, ok := registry[name]
+	registryMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown SEARCH_PROVIDER %q, must be one of: %s", name, strings.Join(Providers(), ", "))
+	}
+
+	provider, err := factory(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s provider: %w", name, err)
+	}
+	return provider, nil
+}
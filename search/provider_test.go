@@ -0,0 +1,43 @@
+package search
+
+import (
+	"strings"
+	"testing"
+
+	"com.moguyn/mcp-go-search/config"
+)
+
+func TestNewProvider(t *testing.T) {
+	provider, err := NewProvider(&config.Config{SearchProvider: config.ProviderPlugin, PluginCommand: "/usr/local/bin/search-plugin"})
+	if err != nil {
+		t.Fatalf("NewProvider returned an error: %v", err)
+	}
+	if provider.Name() != config.ProviderPlugin {
+		t.Errorf("Expected the plugin provider, got %q", provider.Name())
+	}
+
+	_, err = NewProvider(&config.Config{SearchProvider: "unknown"})
+	if err == nil || !strings.Contains(err.Error(), config.ProviderPlugin) {
+		t.Errorf("Expected an error listing the registered providers, got %v", err)
+	}
+}
+
+func TestRegister(t *testing.T) {
+	Register("test-engine", func(*config.Config) (Provider, error) {
+		return &PluginService{}, nil
+	})
+	found := false
+	for _, name := range Providers() {
+		found = found || name == "test-engine"
+	}
+	if !found {
+		t.Errorf("Expected test-engine to be registered, got %v", Providers())
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected registering a name twice to panic")
+		}
+	}()
+	Register("test-engine", func(*config.Config) (Provider, error) { return nil, nil })
+}
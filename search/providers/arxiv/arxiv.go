@@ -0,0 +1,310 @@
+// Package arxiv implements the search provider for the arXiv API, which
+// searches the titles, authors and abstracts of research papers
+package arxiv
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"com.moguyn/mcp-go-search/config"
+	"com.moguyn/mcp-go-search/params"
+	"com.moguyn/mcp-go-search/search"
+)
+
+// MaxCount is the largest number of papers returned for one search. The API
+// accepts more, but pages of that size are slow to build.
+const MaxCount = params.MaxCount
+
+// fieldPrefixes are the API's search fields, which a query term may name
+// itself, e.g. au:hinton or cat:cs.LG
+var fieldPrefixes = []string{"ti:", "au:", "abs:", "co:", "jr:", "cat:", "rn:", "id:", "all:"}
+
+// freshnessWindows maps the server's freshness values to how far back a
+// paper's submission date may go
+var freshnessWindows = map[string]func(time.Time) time.Time{
+	"day":     func(t time.Time) time.Time { return t.AddDate(0, 0, -1) },
+	"week":    func(t time.Time) time.Time { return t.AddDate(0, 0, -7) },
+	"month":   func(t time.Time) time.Time { return t.AddDate(0, -1, 0) },
+	"oneYear": func(t time.Time) time.Time { return t.AddDate(-1, 0, 0) },
+}
+
+// submittedDateLayout is the form of the bounds of a submittedDate range
+const submittedDateLayout = "200601021504"
+
+func init() {
+	search.Register(config.ProviderArxiv, func(cfg *config.Config) (search.Provider, error) {
+		return NewWithConfig(cfg), nil
+	})
+}
+
+// Feed is the Atom feed the API responds with
+type Feed struct {
+	TotalResults int     `xml:"totalResults"`
+	Entries      []Entry `xml:"entry"`
+}
+
+// Entry is a single paper, or the description of an error
+type Entry struct {
+	ID        string   `xml:"id"`
+	Title     string   `xml:"title"`
+	Summary   string   `xml:"summary"`
+	Published string   `xml:"published"`
+	Authors   []Author `xml:"author"`
+	Links     []Link   `xml:"link"`
+}
+
+// Author is one of a paper's authors
+type Author struct {
+	Name string `xml:"name"`
+}
+
+// Link is a link to the paper's abstract page or PDF
+type Link struct {
+	Href  string `xml:"href,attr"`
+	Rel   string `xml:"rel,attr"`
+	Title string `xml:"title,attr"`
+}
+
+// Service implements the search.Provider interface for the arXiv API
+type Service struct {
+	apiURL      string
+	httpClient  *http.Client
+	rateLimiter *rate.Limiter
+	now         func() time.Time
+}
+
+// NewWithConfig creates a new arXiv provider with the provided configuration
+func NewWithConfig(cfg *config.Config) *Service {
+	return &Service{
+		apiURL:     cfg.ArxivAPIURL,
+		httpClient: search.NewHTTPClient(cfg),
+		// The API's terms ask for no more than one request every three seconds
+		rateLimiter: rate.NewLimiter(rate.Every(3*time.Second), 1),
+		now:         time.Now,
+	}
+}
+
+// Name returns the provider name used in configuration
+func (s *Service) Name() string {
+	return config.ProviderArxiv
+}
+
+// Capabilities describes what the arXiv API supports. Freshness filters on
+// the date a paper was submitted.
+func (s *Service) Capabilities() search.Capabilities {
+	return search.Capabilities{
+		Provider:  config.ProviderArxiv,
+		Freshness: params.Freshness,
+		MaxCount:  MaxCount,
+		Operators: []string{search.OperatorPhrase, search.OperatorExclude, search.OperatorOr},
+	}
+}
+
+// Search performs a search using the arXiv API
+func (s *Service) Search(ctx context.Context, query string, freshness string, count int, summary bool) (*search.WebSearchResponse, error) {
+	if err := search.WaitRateLimit(ctx, s.rateLimiter); err != nil {
+		return nil, fmt.Errorf("rate limit exceeded: %w", err)
+	}
+
+	// Validate inputs and bring them within the API's limits
+	p, adj, err := params.Normalize(params.Search{
+		Query:     query,
+		Freshness: freshness,
+		Count:     count,
+		Summary:   summary,
+	}, s.Capabilities().Limits())
+	if err != nil {
+		return nil, err
+	}
+
+	searchQuery := buildQuery(p.Query)
+	if since, ok := freshnessWindows[p.Freshness]; ok {
+		now := s.now().UTC()
+		searchQuery = fmt.Sprintf("(%s) AND submittedDate:[%s TO %s]", searchQuery,
+			since(now).Format(submittedDateLayout), now.Format(submittedDateLayout))
+	}
+	values := url.Values{}
+	values.Set("search_query", searchQuery)
+	values.Set("start", "0")
+	values.Set("max_results", strconv.Itoa(p.Count))
+	requestURL := s.apiURL + "?" + values.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTTP request: %w", err)
+	}
+	req.Header.Set("Accept", "application/atom+xml")
+	req.Header.Set("User-Agent", "BochaWebSearchMCPServer/1.0")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request to arXiv API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 10*1024*1024)) // 10MB limit
+	if err != nil {
+		return nil, fmt.Errorf("failed to read arXiv API response body: %w", err)
+	}
+
+	searchResp, err := parseResponse(resp.StatusCode, body)
+	if err != nil {
+		return nil, search.WithTransfer(err, int64(len(requestURL)), int64(len(body)))
+	}
+	searchResp.Data.QueryContext.OriginalQuery = p.Query
+	searchResp.Meta = search.ResponseMeta{
+		QueryTruncated: adj.QueryTruncated,
+		CountClamped:   adj.CountClamped,
+		BytesSent:      int64(len(requestURL)),
+		BytesReceived:  int64(len(body)),
+	}
+	return searchResp, nil
+}
+
+// buildQuery turns a query into the API's search syntax. Plain terms and
+// phrases match any field and must all match, OR joins its neighbours and a
+// minus sign excludes a term. Terms naming a field, such as au:hinton, are
+// kept as they are.
+func buildQuery(query string) string {
+	var include []string
+	var exclude []string
+	or := false
+	for _, term := range queryTerms(query) {
+		if term == "OR" {
+			or = len(include) > 0
+			continue
+		}
+		excluded := false
+		if rest, ok := strings.CutPrefix(term, "-"); ok && rest != "" {
+			excluded, term = true, rest
+		}
+		if !hasFieldPrefix(term) {
+			term = "all:" + term
+		}
+		switch {
+		case excluded:
+			exclude = append(exclude, term)
+		case len(include) == 0:
+			include = append(include, term)
+		case or:
+			include = append(include, "OR", term)
+		default:
+			include = append(include, "AND", term)
+		}
+		or = false
+	}
+	if len(include) == 0 {
+		// The API can't search for exclusions alone
+		include, exclude = exclude, nil
+	}
+	built := strings.Join(include, " ")
+	for _, term := range exclude {
+		built += " ANDNOT " + term
+	}
+	return built
+}
+
+// queryTerms splits a query on spaces, keeping quoted phrases whole
+func queryTerms(query string) []string {
+	var terms []string
+	var current strings.Builder
+	quoted := false
+	for _, r := range query {
+		switch {
+		case r == '"':
+			quoted = !quoted
+			current.WriteRune(r)
+		case !quoted && (r == ' ' || r == '\t' || r == '\n'):
+			if current.Len() > 0 {
+				terms = append(terms, current.String())
+				current.Reset()
+			}
+		default:
+			current.WriteRune(r)
+		}
+	}
+	if quoted {
+		// Close a phrase the query left open
+		current.WriteRune('"')
+	}
+	if current.Len() > 0 {
+		terms = append(terms, current.String())
+	}
+	return terms
+}
+
+// hasFieldPrefix reports whether a term names one of the API's search fields
+func hasFieldPrefix(term string) bool {
+	for _, prefix := range fieldPrefixes {
+		if strings.HasPrefix(term, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseResponse decodes an Atom feed into the common response format. The API
+// describes errors as a feed holding a single entry whose ID is an error URL.
+func parseResponse(statusCode int, body []byte) (*search.WebSearchResponse, error) {
+	var feed Feed
+	if err := xml.Unmarshal(body, &feed); err != nil {
+		if statusCode != http.StatusOK {
+			return nil, fmt.Errorf("arxiv api returned status code %d", statusCode)
+		}
+		return nil, fmt.Errorf("failed to parse arxiv api response: %w", err)
+	}
+	if len(feed.Entries) == 1 && strings.Contains(feed.Entries[0].ID, "/api/errors") {
+		return nil, fmt.Errorf("arxiv api error (status %d): %s", statusCode, collapseSpace(feed.Entries[0].Summary))
+	}
+	if statusCode != http.StatusOK {
+		return nil, fmt.Errorf("arxiv api returned status code %d", statusCode)
+	}
+
+	results := make([]search.WebPageResult, 0, len(feed.Entries))
+	for _, entry := range feed.Entries {
+		results = append(results, toWebPageResult(entry))
+	}
+	searchResp := &search.WebSearchResponse{Code: http.StatusOK}
+	searchResp.Data.WebPages.TotalEstimatedMatches = feed.TotalResults
+	searchResp.Data.WebPages.Value = results
+	return searchResp, nil
+}
+
+// toWebPageResult maps a paper onto the common result format, with its
+// abstract as the snippet
+func toWebPageResult(entry Entry) search.WebPageResult {
+	result := search.WebPageResult{
+		Name:            collapseSpace(entry.Title),
+		URL:             entry.ID,
+		Snippet:         collapseSpace(entry.Summary),
+		SiteName:        "arXiv",
+		DateLastCrawled: entry.Published,
+	}
+	for _, link := range entry.Links {
+		switch {
+		case link.Title == "pdf":
+			result.PDFURL = link.Href
+		case link.Rel == "alternate":
+			result.URL = link.Href
+		}
+	}
+	result.DisplayURL = result.URL
+	for _, author := range entry.Authors {
+		result.Authors = append(result.Authors, collapseSpace(author.Name))
+	}
+	return result
+}
+
+// collapseSpace joins the lines a feed wraps long titles and abstracts over
+func collapseSpace(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
@@ -0,0 +1,142 @@
+package arxiv
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"com.moguyn/mcp-go-search/config"
+	"com.moguyn/mcp-go-search/search"
+)
+
+const arxivResponse = `<?xml version="1.0" encoding="UTF-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom" xmlns:opensearch="http://a9.com/-/spec/opensearch/1.1/" xmlns:arxiv="http://arxiv.org/schemas/atom">
+  <title type="html">ArXiv Query: search_query=all:attention&amp;id_list=&amp;start=0&amp;max_results=2</title>
+  <opensearch:totalResults>12345</opensearch:totalResults>
+  <opensearch:startIndex>0</opensearch:startIndex>
+  <entry>
+    <id>http://arxiv.org/abs/1706.03762v7</id>
+    <updated>2023-08-02T00:41:18Z</updated>
+    <published>2017-06-12T17:57:34Z</published>
+    <title>Attention Is All You
+  Need</title>
+    <summary>  The dominant sequence transduction models are based on complex recurrent or
+convolutional neural networks.
+</summary>
+    <author><name>Ashish Vaswani</name></author>
+    <author><name>Noam Shazeer</name></author>
+    <link href="http://arxiv.org/abs/1706.03762v7" rel="alternate" type="text/html"/>
+    <link title="pdf" href="http://arxiv.org/pdf/1706.03762v7" rel="related" type="application/pdf"/>
+    <arxiv:primary_category term="cs.CL" scheme="http://arxiv.org/schemas/atom"/>
+  </entry>
+</feed>`
+
+const arxivError = `<?xml version="1.0" encoding="UTF-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+  <entry>
+    <id>http://arxiv.org/api/errors#incorrect_id_format_for_1234</id>
+    <title>Error</title>
+    <summary>incorrect id format for 1234</summary>
+  </entry>
+</feed>`
+
+func TestService_Search(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			t.Errorf("Expected GET request, got %s", r.Method)
+		}
+		query := r.URL.Query()
+		expected := "(all:attention AND au:vaswani) AND submittedDate:[202505150000 TO 202506150000]"
+		if query.Get("search_query") != expected {
+			t.Errorf("Expected search query %q, got %q", expected, query.Get("search_query"))
+		}
+		if query.Get("max_results") != "5" {
+			t.Errorf("Expected 5 results, got %s", query.Get("max_results"))
+		}
+		_, _ = w.Write([]byte(arxivResponse))
+	}))
+	defer server.Close()
+
+	service := NewWithConfig(&config.Config{ArxivAPIURL: server.URL, HTTPTimeout: 5 * time.Second})
+	service.now = func() time.Time { return time.Date(2025, 6, 15, 0, 0, 0, 0, time.UTC) }
+	response, err := service.Search(context.Background(), "attention au:vaswani", "month", 5, false)
+	if err != nil {
+		t.Fatalf("Search returned an error: %v", err)
+	}
+
+	if response.Data.WebPages.TotalEstimatedMatches != 12345 {
+		t.Errorf("Expected 12345 matches, got %d", response.Data.WebPages.TotalEstimatedMatches)
+	}
+	results := response.Data.WebPages.Value
+	if len(results) != 1 {
+		t.Fatalf("Expected 1 paper, got %d", len(results))
+	}
+	paper := results[0]
+	if paper.Name != "Attention Is All You Need" || paper.URL != "http://arxiv.org/abs/1706.03762v7" {
+		t.Errorf("Unexpected paper: %+v", paper)
+	}
+	if paper.PDFURL != "http://arxiv.org/pdf/1706.03762v7" {
+		t.Errorf("Expected the PDF link, got %q", paper.PDFURL)
+	}
+	if strings.Join(paper.Authors, ", ") != "Ashish Vaswani, Noam Shazeer" {
+		t.Errorf("Expected both authors, got %v", paper.Authors)
+	}
+	if !strings.HasPrefix(paper.Snippet, "The dominant sequence transduction models are based on complex recurrent or convolutional") {
+		t.Errorf("Expected the abstract on one line, got %q", paper.Snippet)
+	}
+	if paper.DateLastCrawled != "2017-06-12T17:57:34Z" {
+		t.Errorf("Expected the publication date, got %q", paper.DateLastCrawled)
+	}
+}
+
+func TestBuildQuery(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected string
+	}{
+		{"graph neural networks", "all:graph AND all:neural AND all:networks"},
+		{`"large language models" -survey`, `all:"large language models" ANDNOT all:survey`},
+		{"transformer OR attention cat:cs.LG", "all:transformer OR all:attention AND cat:cs.LG"},
+		{"OR diffusion", "all:diffusion"},
+		{"-survey", "all:survey"},
+		{`"open phrase`, `all:"open phrase"`},
+	}
+	for _, tt := range tests {
+		if got := buildQuery(tt.query); got != tt.expected {
+			t.Errorf("Expected %q for %q, got %q", tt.expected, tt.query, got)
+		}
+	}
+}
+
+func TestParseResponse_Errors(t *testing.T) {
+	tests := []struct {
+		name       string
+		statusCode int
+		body       string
+		expected   string
+	}{
+		{"error entry", http.StatusBadRequest, arxivError, "arxiv api error (status 400): incorrect id format for 1234"},
+		{"no details", http.StatusServiceUnavailable, `<html>unavailable</html>`, "arxiv api returned status code 503"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseResponse(tt.statusCode, []byte(tt.body))
+			if err == nil || err.Error() != tt.expected {
+				t.Errorf("Expected %q, got %v", tt.expected, err)
+			}
+		})
+	}
+}
+
+func TestRegistered(t *testing.T) {
+	provider, err := search.NewProvider(&config.Config{SearchProvider: config.ProviderArxiv})
+	if err != nil {
+		t.Fatalf("NewProvider returned an error: %v", err)
+	}
+	if provider.Name() != config.ProviderArxiv || provider.Capabilities().MaxCount != MaxCount {
+		t.Errorf("Expected the arXiv provider, got %s", provider.Name())
+	}
+}
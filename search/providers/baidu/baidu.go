@@ -0,0 +1,244 @@
+// Package baidu implements the search provider for Baidu web search, through
+// the Qianfan AI Search API
+package baidu
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"text/template"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"com.moguyn/mcp-go-search/config"
+	"com.moguyn/mcp-go-search/params"
+	"com.moguyn/mcp-go-search/search"
+)
+
+// MaxCount is the largest number of web results the API returns for one search
+const MaxCount = 50
+
+// searchSource selects Baidu web search among the API's sources
+const searchSource = "baidu_search_v2"
+
+// recencyFilters maps the server's freshness values to the API's recency filter
+var recencyFilters = map[string]string{
+	"week":    "week",
+	"month":   "month",
+	"oneYear": "year",
+}
+
+// beijing is the zone of the dates the API returns, which carry no offset
+var beijing = time.FixedZone("CST", 8*60*60)
+
+func init() {
+	search.Register(config.ProviderBaidu, func(cfg *config.Config) (search.Provider, error) {
+		return NewWithConfig(cfg), nil
+	})
+}
+
+// Message is a chat message carrying the query
+type Message struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// ResourceFilter limits the results of one resource type
+type ResourceFilter struct {
+	Type string `json:"type"`
+	TopK int    `json:"top_k"`
+}
+
+// Request is the request body of the web search API
+type Request struct {
+	Messages            []Message        `json:"messages"`
+	SearchSource        string           `json:"search_source"`
+	ResourceTypeFilter  []ResourceFilter `json:"resource_type_filter"`
+	SearchRecencyFilter string           `json:"search_recency_filter,omitempty"`
+}
+
+// Response is the part of a web search response the provider uses
+type Response struct {
+	RequestID  string      `json:"request_id"`
+	References []Reference `json:"references"`
+}
+
+// Reference is a single search result
+type Reference struct {
+	Title     string `json:"title"`
+	URL       string `json:"url"`
+	Content   string `json:"content"`
+	Date      string `json:"date"`
+	Icon      string `json:"icon"`
+	Website   string `json:"website"`
+	WebAnchor string `json:"web_anchor"`
+	Type      string `json:"type"`
+}
+
+// Service implements the search.Provider interface for Baidu web search
+type Service struct {
+	*search.RotatableKey
+	apiBaseURL  string
+	template    *template.Template
+	httpClient  *http.Client
+	rateLimiter *rate.Limiter
+}
+
+// NewWithConfig creates a new Baidu provider with the provided configuration
+func NewWithConfig(cfg *config.Config) *Service {
+	// The template is validated at startup, so an error here is unexpected
+	tmpl, _ := cfg.RequestTemplate(config.ProviderBaidu)
+	return &Service{
+		RotatableKey: search.NewRotatableKey(cfg.BaiduAPIKey),
+		apiBaseURL:   cfg.BaiduAPIBaseURL,
+		template:     tmpl,
+		httpClient:   search.NewHTTPClient(cfg),
+		// Stay well within the API's default queries per second
+		rateLimiter: rate.NewLimiter(rate.Limit(5), 5),
+	}
+}
+
+// Name returns the provider name used in configuration
+func (s *Service) Name() string {
+	return config.ProviderBaidu
+}
+
+// Capabilities describes what the web search API supports. It has no filter
+// for the past day.
+func (s *Service) Capabilities() search.Capabilities {
+	return search.Capabilities{
+		Provider:  config.ProviderBaidu,
+		Freshness: []string{params.DefaultFreshness, "week", "month", "oneYear"},
+		MaxCount:  MaxCount,
+		Operators: []string{search.OperatorSite, search.OperatorPhrase, search.OperatorExclude},
+	}
+}
+
+// Search performs a search using the web search API
+func (s *Service) Search(ctx context.Context, query string, freshness string, count int, summary bool) (*search.WebSearchResponse, error) {
+	if err := search.WaitRateLimit(ctx, s.rateLimiter); err != nil {
+		return nil, fmt.Errorf("rate limit exceeded: %w", err)
+	}
+
+	// Validate inputs and bring them within the API's limits
+	p, adj, err := params.Normalize(params.Search{
+		Query:     query,
+		Freshness: freshness,
+		Count:     count,
+		Summary:   summary,
+	}, s.Capabilities().Limits())
+	if err != nil {
+		return nil, err
+	}
+
+	var reqBody []byte
+	if s.template != nil {
+		reqBody, err = config.RenderRequestBody(s.template, config.RequestTemplateData{Search: p})
+	} else {
+		reqBody, err = json.Marshal(Request{
+			Messages:            []Message{{Role: "user", Content: p.Query}},
+			SearchSource:        searchSource,
+			ResourceTypeFilter:  []ResourceFilter{{Type: "web", TopK: p.Count}},
+			SearchRecencyFilter: recencyFilters[p.Freshness],
+		})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request body: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.apiBaseURL, bytes.NewBuffer(reqBody))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTTP request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+s.APIKey())
+	req.Header.Set("User-Agent", "BochaWebSearchMCPServer/1.0")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request to Baidu API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 10*1024*1024)) // 10MB limit
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Baidu API response body: %w", err)
+	}
+
+	searchResp, err := parseResponse(resp.StatusCode, body)
+	if err != nil {
+		return nil, search.WithTransfer(err, int64(len(reqBody)), int64(len(body)))
+	}
+	searchResp.Data.QueryContext.OriginalQuery = p.Query
+	searchResp.Meta = search.ResponseMeta{
+		QueryTruncated: adj.QueryTruncated,
+		CountClamped:   adj.CountClamped,
+		BytesSent:      int64(len(reqBody)),
+		BytesReceived:  int64(len(body)),
+	}
+	return searchResp, nil
+}
+
+// parseResponse decodes a web search response body into the common response
+// format, turning errors into Go errors. The API reports some errors with a
+// 200 status and an error code in the body.
+func parseResponse(statusCode int, body []byte) (*search.WebSearchResponse, error) {
+	var errorResp struct {
+		Code     any    `json:"code"`
+		Message  string `json:"message"`
+		ErrorMsg string `json:"error_msg"`
+	}
+	if err := json.Unmarshal(body, &errorResp); err == nil {
+		message := errorResp.Message
+		if message == "" {
+			message = errorResp.ErrorMsg
+		}
+		if message != "" && (statusCode != http.StatusOK || errorResp.Code != nil) {
+			return nil, fmt.Errorf("baidu api error (status %d): %s", statusCode, message)
+		}
+	}
+	if statusCode != http.StatusOK {
+		return nil, fmt.Errorf("baidu api returned status code %d", statusCode)
+	}
+
+	var baiduResp Response
+	if err := json.Unmarshal(body, &baiduResp); err != nil {
+		return nil, fmt.Errorf("failed to parse baidu api response: %w", err)
+	}
+
+	results := make([]search.WebPageResult, 0, len(baiduResp.References))
+	for _, ref := range baiduResp.References {
+		if ref.Type != "" && ref.Type != "web" {
+			continue
+		}
+		results = append(results, toWebPageResult(ref))
+	}
+	searchResp := &search.WebSearchResponse{Code: http.StatusOK, LogID: baiduResp.RequestID}
+	searchResp.Data.WebPages.Value = results
+	return searchResp, nil
+}
+
+// toWebPageResult maps a reference onto the common result format
+func toWebPageResult(ref Reference) search.WebPageResult {
+	result := search.WebPageResult{
+		Name:       ref.Title,
+		URL:        ref.URL,
+		DisplayURL: ref.URL,
+		Snippet:    ref.Content,
+		SiteName:   ref.Website,
+		SiteIcon:   ref.Icon,
+	}
+	if result.Name == "" {
+		result.Name = ref.WebAnchor
+	}
+	if date, err := time.ParseInLocation(time.DateTime, ref.Date, beijing); err == nil {
+		result.DateLastCrawled = date.Format(time.RFC3339)
+	} else {
+		result.DateLastCrawled = ref.Date
+	}
+	return result
+}
@@ -0,0 +1,145 @@
+package baidu
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"com.moguyn/mcp-go-search/config"
+	"com.moguyn/mcp-go-search/search"
+)
+
+const baiduResponse = `{
+	"request_id": "0d6e3b4c-2f4a-4a8e-9c1d-7f1d5b6a2e90",
+	"references": [
+		{
+			"id": 1,
+			"title": "Go 语言泛型入门",
+			"url": "https://go.dev/doc/tutorial/generics",
+			"content": "本教程介绍 Go 中泛型的基础知识。",
+			"date": "2025-03-12 09:30:00",
+			"icon": "https://go.dev/favicon.ico",
+			"website": "Go",
+			"web_anchor": "Go 泛型教程",
+			"type": "web"
+		},
+		{
+			"id": 2,
+			"url": "https://example.cn/go-generics",
+			"content": "类型参数与约束速查。",
+			"date": "",
+			"web_anchor": "Go 泛型速查表",
+			"type": "web"
+		},
+		{
+			"id": 3,
+			"title": "Gopher",
+			"url": "https://example.cn/gopher.png",
+			"type": "image"
+		}
+	]
+}`
+
+func TestService_Search(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("Expected POST request, got %s", r.Method)
+		}
+		if auth := r.Header.Get("Authorization"); auth != "Bearer test-baidu-key" {
+			t.Errorf("Expected the bearer token, got %q", auth)
+		}
+		var body Request
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Fatalf("Failed to decode request body: %v", err)
+		}
+		if len(body.Messages) != 1 || body.Messages[0].Content != "go 泛型" || body.SearchSource != searchSource {
+			t.Errorf("Unexpected request body: %+v", body)
+		}
+		if len(body.ResourceTypeFilter) != 1 || body.ResourceTypeFilter[0].TopK != 50 || body.SearchRecencyFilter != "year" {
+			t.Errorf("Unexpected filters: %+v", body)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(baiduResponse))
+	}))
+	defer server.Close()
+
+	service := NewWithConfig(&config.Config{
+		BaiduAPIKey:     "test-baidu-key",
+		BaiduAPIBaseURL: server.URL,
+		HTTPTimeout:     5 * time.Second,
+	})
+	response, err := service.Search(context.Background(), "go 泛型", "oneYear", 80, false)
+	if err != nil {
+		t.Fatalf("Search returned an error: %v", err)
+	}
+
+	if !response.Meta.CountClamped {
+		t.Error("Expected the count to be clamped to Baidu's maximum")
+	}
+	if response.LogID != "0d6e3b4c-2f4a-4a8e-9c1d-7f1d5b6a2e90" {
+		t.Errorf("Expected the request ID as log ID, got %q", response.LogID)
+	}
+	results := response.Data.WebPages.Value
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 web results, got %d", len(results))
+	}
+	first := results[0]
+	if first.Name != "Go 语言泛型入门" || first.SiteName != "Go" || first.SiteIcon != "https://go.dev/favicon.ico" {
+		t.Errorf("Unexpected first result: %+v", first)
+	}
+	if first.DateLastCrawled != "2025-03-12T09:30:00+08:00" {
+		t.Errorf("Expected the date in RFC 3339 with Beijing's offset, got %q", first.DateLastCrawled)
+	}
+	if results[1].Name != "Go 泛型速查表" {
+		t.Errorf("Expected the anchor text as name without a title, got %q", results[1].Name)
+	}
+}
+
+func TestService_Search_Errors(t *testing.T) {
+	service := NewWithConfig(&config.Config{BaiduAPIKey: "test-baidu-key", HTTPTimeout: 5 * time.Second})
+	if _, err := service.Search(context.Background(), "新闻", "day", 10, false); err == nil {
+		t.Error("Expected error for the unsupported day freshness, got nil")
+	}
+	if _, err := service.Search(context.Background(), "", "", 10, false); err == nil {
+		t.Error("Expected error for empty query, got nil")
+	}
+}
+
+func TestParseResponse_Errors(t *testing.T) {
+	tests := []struct {
+		name       string
+		statusCode int
+		body       string
+		expected   string
+	}{
+		{"status", http.StatusUnauthorized, `{"code": "InvalidApiKey", "message": "invalid api key"}`, "baidu api error (status 401): invalid api key"},
+		{"error code with 200", http.StatusOK, `{"error_code": 336501, "code": 336501, "error_msg": "Rate limit reached"}`, "baidu api error (status 200): Rate limit reached"},
+		{"no details", http.StatusBadGateway, `<html>bad gateway</html>`, "baidu api returned status code 502"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseResponse(tt.statusCode, []byte(tt.body))
+			if err == nil || err.Error() != tt.expected {
+				t.Errorf("Expected %q, got %v", tt.expected, err)
+			}
+		})
+	}
+
+	response, err := parseResponse(http.StatusOK, []byte(`{"request_id": "1"}`))
+	if err != nil || response.Data.WebPages.Value == nil || len(response.Data.WebPages.Value) != 0 {
+		t.Errorf("Expected an empty result list, got %v (%v)", response, err)
+	}
+}
+
+func TestRegistered(t *testing.T) {
+	provider, err := search.NewProvider(&config.Config{SearchProvider: config.ProviderBaidu, BaiduAPIKey: "test-baidu-key"})
+	if err != nil {
+		t.Fatalf("NewProvider returned an error: %v", err)
+	}
+	if provider.Name() != config.ProviderBaidu || provider.Capabilities().MaxCount != MaxCount {
+		t.Errorf("Expected the Baidu provider, got %s", provider.Name())
+	}
+}
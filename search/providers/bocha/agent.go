@@ -0,0 +1,96 @@
+package bocha
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"com.moguyn/mcp-go-search/search"
+)
+
+// AgentRequest is the request body of the Bocha Agent Search API
+type AgentRequest struct {
+	AgentID    string `json:"agentId"`
+	Query      string `json:"query"`
+	SearchType string `json:"searchType"`
+	Answer     bool   `json:"answer"`
+	Stream     bool   `json:"stream"`
+}
+
+// Names the fields of an agent's results are recognized by, most likely first
+var (
+	agentTitleFields    = []string{"title", "name"}
+	agentURLFields      = []string{"url", "link"}
+	agentSnippetFields  = []string{"snippet", "summary", "abstract", "description", "content"}
+	agentDateFields     = []string{"datePublished", "publishDate", "date", "dateLastCrawled"}
+	agentSiteNameFields = []string{"siteName", "source", "journal", "publisher"}
+)
+
+// parseAgentResponse decodes an agent search response body into the common
+// response format. An agent answers in the messages of an AI search, with
+// sources of its own content type, such as academic papers or company
+// records, whose items become the results.
+func parseAgentResponse(statusCode int, body []byte) (*search.WebSearchResponse, error) {
+	return parseMessages(statusCode, body, "agent search", addAgentSource)
+}
+
+// addAgentSource adds a source message of an agent search to resp: web pages
+// and cards as in an AI search, and the items of any other source as results
+func addAgentSource(resp *search.WebSearchResponse, message aiMessage) error {
+	switch message.ContentType {
+	case "webpage", "weather_china", "stock", "baike_pro":
+		return addSource(resp, message)
+	}
+
+	// The items are a bare list or the value of an object, on their own or
+	// in a webPages section
+	var items []map[string]any
+	if err := json.Unmarshal([]byte(message.Content), &items); err != nil {
+		var wrapped struct {
+			Value    []map[string]any `json:"value"`
+			WebPages struct {
+				Value []map[string]any `json:"value"`
+			} `json:"webPages"`
+		}
+		if err := json.Unmarshal([]byte(message.Content), &wrapped); err != nil {
+			return err
+		}
+		items = wrapped.Value
+		if items == nil {
+			items = wrapped.WebPages.Value
+		}
+	}
+	for _, item := range items {
+		result := search.WebPageResult{
+			Name:            agentField(item, agentTitleFields),
+			URL:             agentField(item, agentURLFields),
+			Snippet:         agentField(item, agentSnippetFields),
+			DateLastCrawled: agentField(item, agentDateFields),
+			SiteName:        agentField(item, agentSiteNameFields),
+		}
+		if result.URL == "" {
+			continue
+		}
+		if result.Name == "" {
+			result.Name = result.URL
+		}
+		result.DisplayURL = result.URL
+		resp.Data.WebPages.Value = append(resp.Data.WebPages.Value, result)
+	}
+	return nil
+}
+
+// agentField returns the first of the named fields of item that has text, or
+// "" when none has. Numbers are written as in JSON.
+func agentField(item map[string]any, names []string) string {
+	for _, name := range names {
+		switch v := item[name].(type) {
+		case string:
+			if v != "" {
+				return v
+			}
+		case float64:
+			return strconv.FormatFloat(v, 'f', -1, 64)
+		}
+	}
+	return ""
+}
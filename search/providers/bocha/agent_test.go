@@ -0,0 +1,101 @@
+package bocha
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"com.moguyn/mcp-go-search/config"
+	"com.moguyn/mcp-go-search/fixtures"
+	"com.moguyn/mcp-go-search/search"
+)
+
+func TestService_Search_Agent(t *testing.T) {
+	var (
+		paths []string
+		sent  AgentRequest
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/v1/agent-search" {
+			_ = json.Unmarshal(body, &sent)
+			_, _ = w.Write(fixtures.MustResponse(fixtures.AgentSearch))
+			return
+		}
+		_, _ = w.Write(fixtures.MustResponse(fixtures.AISearch))
+	}))
+	defer server.Close()
+
+	service := NewWithConfig(&config.Config{
+		BochaAPIKey:         "test-api-key",
+		BochaAPIBaseURL:     server.URL + "/v1/web-search",
+		BochaAISearchURL:    server.URL + "/v1/ai-search",
+		BochaAgentSearchURL: server.URL + "/v1/agent-search",
+		BochaAgents:         config.DefaultBochaAgents,
+		HTTPTimeout:         5 * time.Second,
+	})
+	if caps := service.Capabilities(); !caps.AgentSearch || len(caps.Agents) != 3 {
+		t.Errorf("Expected the agent search and its agents to be reported, got %+v", caps)
+	}
+
+	ctx := search.WithAgent(context.Background(), "bocha-scholar-agent")
+	response, err := service.Search(ctx, "transformers", "noLimit", 1, false)
+	if err != nil {
+		t.Fatalf("Search returned an error: %v", err)
+	}
+	if sent.AgentID != "bocha-scholar-agent" || sent.Query != "transformers" || sent.SearchType != "neural" || sent.Stream {
+		t.Errorf("Expected the query sent to the scholar agent, got %+v", sent)
+	}
+	results := response.Data.WebPages.Value
+	if len(results) != 1 {
+		t.Fatalf("Expected the results cut to the count, got %+v", results)
+	}
+	if results[0].Name != "Attention Is All You Need" || results[0].SiteName != "NeurIPS" || results[0].DateLastCrawled != "2017-06-12" {
+		t.Errorf("Unexpected result: %+v", results[0])
+	}
+
+	// An agent takes the place of the AI search for that call
+	if _, err := service.Search(search.WithAISearch(ctx), "transformers", "noLimit", 10, false); err != nil {
+		t.Fatalf("Search returned an error: %v", err)
+	}
+	if len(paths) != 2 || paths[0] != "/v1/agent-search" || paths[1] != "/v1/agent-search" {
+		t.Errorf("Expected the agent search, got %v", paths)
+	}
+}
+
+func TestParseAgentResponse(t *testing.T) {
+	response, err := parseAgentResponse(http.StatusOK, fixtures.MustResponse(fixtures.AgentSearch))
+	if err != nil {
+		t.Fatalf("parseAgentResponse returned an error: %v", err)
+	}
+	results := response.Data.WebPages.Value
+	if len(results) != 2 {
+		t.Fatalf("Expected the papers with a URL, got %+v", results)
+	}
+	if results[1].DateLastCrawled != "2019" || results[1].URL != results[1].DisplayURL {
+		t.Errorf("Expected a numeric date as text and the URL displayed, got %+v", results[1])
+	}
+
+	// Items may be a bare list, and a result without a title is named by its URL
+	body := `{"code": 200, "messages": [{"type": "source", "content_type": "company", "content": "[{\"name\": \"Example Ltd\", \"url\": \"https://company.example.cn/1\", \"description\": \"A company\"}, {\"link\": \"https://company.example.cn/2\"}]"}]}`
+	response, err = parseAgentResponse(http.StatusOK, []byte(body))
+	if err != nil {
+		t.Fatalf("parseAgentResponse returned an error: %v", err)
+	}
+	results = response.Data.WebPages.Value
+	if len(results) != 2 || results[0].Name != "Example Ltd" || results[0].Snippet != "A company" || results[1].Name != "https://company.example.cn/2" {
+		t.Errorf("Unexpected results: %+v", results)
+	}
+
+	_, err = parseAgentResponse(http.StatusOK, []byte(`{"code": 200, "messages": [{"type": "source", "content_type": "company", "content": "\"text\""}]}`))
+	if err == nil || !strings.HasPrefix(err.Error(), "failed to parse bocha agent search company source") {
+		t.Errorf("Expected an error for a source that isn't a list, got %v", err)
+	}
+}
@@ -0,0 +1,368 @@
+package bocha
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"com.moguyn/mcp-go-search/search"
+)
+
+// AIRequest is the request body of the Bocha AI Search API
+type AIRequest struct {
+	Query     string `json:"query"`
+	Freshness string `json:"freshness"`
+	Count     int    `json:"count"`
+	Answer    bool   `json:"answer"`
+	Stream    bool   `json:"stream"`
+}
+
+// aiResponse is an AI search response: a list of messages, each a source of
+// the answer, the answer itself or a follow-up question
+type aiResponse struct {
+	Code     int         `json:"code"`
+	LogID    string      `json:"log_id"`
+	Msg      any         `json:"msg"`
+	Messages []aiMessage `json:"messages"`
+}
+
+// aiMessage is one message of an AI search response. The content of a
+// source is JSON in a string, shaped by its content type.
+type aiMessage struct {
+	Role        string `json:"role"`
+	Type        string `json:"type"`
+	ContentType string `json:"content_type"`
+	Content     string `json:"content"`
+}
+
+// aiWebPages is the content of a webpage source, the web search results
+// either in their own section or as a bare list
+type aiWebPages struct {
+	WebPages search.WebPages        `json:"webPages"`
+	Value    []search.WebPageResult `json:"value"`
+}
+
+// aiWeather is the content of a weather card
+type aiWeather struct {
+	City        flexString `json:"city"`
+	Date        flexString `json:"date"`
+	Weather     flexString `json:"weather"`
+	Temperature flexString `json:"temperature"`
+	TempHigh    flexString `json:"temp_high"`
+	TempLow     flexString `json:"temp_low"`
+	Wind        flexString `json:"wind"`
+	Humidity    flexString `json:"humidity"`
+	AQI         flexString `json:"aqi"`
+}
+
+// aiStock is the content of a stock card
+type aiStock struct {
+	Name          flexString `json:"name"`
+	Code          flexString `json:"code"`
+	Market        flexString `json:"market"`
+	Price         flexString `json:"price"`
+	Change        flexString `json:"change"`
+	ChangePercent flexString `json:"change_percent"`
+	Currency      flexString `json:"currency"`
+	UpdateTime    flexString `json:"update_time"`
+}
+
+// aiBaike is the content of an encyclopedia card
+type aiBaike struct {
+	Title    flexString `json:"title"`
+	Abstract flexString `json:"abstract"`
+	URL      flexString `json:"url"`
+	Image    flexString `json:"image"`
+	Card     []struct {
+		Name  flexString `json:"name"`
+		Value flexString `json:"value"`
+	} `json:"card"`
+}
+
+// aiDefinition is the content of a dictionary card
+type aiDefinition struct {
+	Word        flexString `json:"word"`
+	URL         flexString `json:"url"`
+	Senses      []aiSense  `json:"senses"`
+	Definitions []aiSense  `json:"definitions"`
+}
+
+// aiSense is one meaning of a dictionary card's word
+type aiSense struct {
+	PartOfSpeech flexString   `json:"part_of_speech"`
+	POS          flexString   `json:"pos"`
+	Definition   flexString   `json:"definition"`
+	Meaning      flexString   `json:"meaning"`
+	Examples     []flexString `json:"examples"`
+	Example      flexString   `json:"example"`
+}
+
+// definitionPronunciations lists the fields of a dictionary card holding a
+// pronunciation, with the label it is shown under and its recording's field
+var definitionPronunciations = []struct {
+	label, phonetic, audio string
+}{
+	{"", "phonetic", "audio"},
+	{"US", "us_phonetic", "us_audio"},
+	{"UK", "uk_phonetic", "uk_audio"},
+	{"Pinyin", "pinyin", ""},
+}
+
+// conversionKinds maps the content types of conversion and calculator cards
+// to the kind of conversion they hold
+var conversionKinds = map[string]string{
+	"exchangerate":    search.ConversionCurrency,
+	"exchange_rate":   search.ConversionCurrency,
+	"unit_conversion": search.ConversionUnit,
+	"unitconversion":  search.ConversionUnit,
+	"calculator":      search.ConversionCalculation,
+}
+
+// conversionFields lists the names each field of a conversion card goes by,
+// since the cards of different kinds don't name them alike
+var conversionFields = struct {
+	expression, fromAmount, fromUnit, toAmount, toUnit, rate, time []string
+}{
+	expression: []string{"expression", "formula", "query"},
+	fromAmount: []string{"amount", "from_amount", "fromAmount", "from_value", "money"},
+	fromUnit:   []string{"from", "from_currency", "fromCurrency", "from_unit", "fromUnit", "currency_from"},
+	toAmount:   []string{"result", "to_amount", "toAmount", "to_value", "converted", "value"},
+	toUnit:     []string{"to", "to_currency", "toCurrency", "to_unit", "toUnit", "currency_to"},
+	rate:       []string{"rate", "exchange_rate", "exchangeRate"},
+	time:       []string{"update_time", "updateTime", "time", "date"},
+}
+
+// flexString is a card field the API writes as a string or a number
+type flexString string
+
+// UnmarshalJSON implements json.Unmarshaler
+func (f *flexString) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*f = flexString(s)
+		return nil
+	}
+	var n json.Number
+	if err := json.Unmarshal(data, &n); err != nil {
+		return err
+	}
+	*f = flexString(n.String())
+	return nil
+}
+
+// parseAIResponse decodes an AI search response body into the common response
+// format, with the answer and the weather, stock, encyclopedia, conversion and
+// dictionary cards.
+// Cards of other types and follow-up questions are left out.
+func parseAIResponse(statusCode int, body []byte) (*search.WebSearchResponse, error) {
+	return parseMessages(statusCode, body, "ai search", addSource)
+}
+
+// parseMessages decodes a response of messages, from the search named kind,
+// joining the answer's messages and adding each source with add
+func parseMessages(statusCode int, body []byte, kind string, add func(*search.WebSearchResponse, aiMessage) error) (*search.WebSearchResponse, error) {
+	if statusCode != http.StatusOK {
+		var errorResp struct {
+			Error string `json:"error"`
+		}
+		if err := json.Unmarshal(body, &errorResp); err == nil && errorResp.Error != "" {
+			return nil, fmt.Errorf("bocha api error (status %d): %s", statusCode, errorResp.Error)
+		}
+		return nil, fmt.Errorf("bocha api returned status code %d", statusCode)
+	}
+
+	var aiResp aiResponse
+	if err := json.Unmarshal(body, &aiResp); err != nil {
+		return nil, fmt.Errorf("failed to parse bocha %s response: %w", kind, err)
+	}
+	if aiResp.Code != 0 && aiResp.Code != http.StatusOK {
+		return nil, fmt.Errorf("bocha %s returned code %d", kind, aiResp.Code)
+	}
+
+	searchResp := &search.WebSearchResponse{Code: http.StatusOK, LogID: aiResp.LogID, Msg: aiResp.Msg}
+	searchResp.Data.WebPages.Value = []search.WebPageResult{}
+	var answer strings.Builder
+	for _, message := range aiResp.Messages {
+		switch message.Type {
+		case "answer":
+			answer.WriteString(message.Content)
+		case "source":
+			if err := add(searchResp, message); err != nil {
+				return nil, fmt.Errorf("failed to parse bocha %s %s source: %w", kind, message.ContentType, err)
+			}
+		}
+	}
+	if text := strings.TrimSpace(answer.String()); text != "" {
+		searchResp.Data.Answer = &search.Answer{Text: text}
+	}
+	return searchResp, nil
+}
+
+// addSource adds the web pages or the card of a source message to resp
+func addSource(resp *search.WebSearchResponse, message aiMessage) error {
+	content := []byte(message.Content)
+	switch message.ContentType {
+	case "webpage":
+		var pages aiWebPages
+		if err := json.Unmarshal(content, &pages); err != nil {
+			return err
+		}
+		if pages.WebPages.Value != nil {
+			resp.Data.WebPages = pages.WebPages
+		} else if pages.Value != nil {
+			resp.Data.WebPages.Value = pages.Value
+		}
+	case "weather_china":
+		var w aiWeather
+		if err := json.Unmarshal(content, &w); err != nil {
+			return err
+		}
+		resp.Data.Cards = append(resp.Data.Cards, search.Card{Type: search.CardWeather, Weather: &search.WeatherCard{
+			Location:    string(w.City),
+			Date:        string(w.Date),
+			Condition:   string(w.Weather),
+			Temperature: string(w.Temperature),
+			High:        string(w.TempHigh),
+			Low:         string(w.TempLow),
+			Wind:        string(w.Wind),
+			Humidity:    string(w.Humidity),
+			AirQuality:  string(w.AQI),
+		}})
+	case "stock":
+		var s aiStock
+		if err := json.Unmarshal(content, &s); err != nil {
+			return err
+		}
+		resp.Data.Cards = append(resp.Data.Cards, search.Card{Type: search.CardStock, Stock: &search.StockCard{
+			Name:          string(s.Name),
+			Symbol:        string(s.Code),
+			Exchange:      string(s.Market),
+			Price:         string(s.Price),
+			Change:        string(s.Change),
+			ChangePercent: string(s.ChangePercent),
+			Currency:      string(s.Currency),
+			Time:          string(s.UpdateTime),
+		}})
+	case "baike_pro":
+		var b aiBaike
+		if err := json.Unmarshal(content, &b); err != nil {
+			return err
+		}
+		card := &search.BaikeCard{
+			Title:   string(b.Title),
+			Summary: string(b.Abstract),
+			URL:     string(b.URL),
+			Image:   string(b.Image),
+		}
+		for _, fact := range b.Card {
+			card.Facts = append(card.Facts, search.Fact{Label: string(fact.Name), Value: string(fact.Value)})
+		}
+		resp.Data.Cards = append(resp.Data.Cards, search.Card{Type: search.CardBaike, Baike: card})
+	case "dictionary", "dict":
+		card, err := parseDefinition(content)
+		if err != nil {
+			return err
+		}
+		resp.Data.Cards = append(resp.Data.Cards, search.Card{Type: search.CardDefinition, Definition: card})
+	default:
+		kind, ok := conversionKinds[message.ContentType]
+		if !ok {
+			return nil
+		}
+		card, err := parseConversion(kind, content)
+		if err != nil {
+			return err
+		}
+		resp.Data.Cards = append(resp.Data.Cards, search.Card{Type: search.CardConversion, Conversion: card})
+	}
+	return nil
+}
+
+// parseDefinition decodes a dictionary card. Senses come as senses or
+// definitions, each with its examples as a list or a single example.
+func parseDefinition(content []byte) (*search.DefinitionCard, error) {
+	var d aiDefinition
+	if err := json.Unmarshal(content, &d); err != nil {
+		return nil, err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(content, &fields); err != nil {
+		return nil, err
+	}
+	field := func(name string) string {
+		var value flexString
+		if raw, ok := fields[name]; ok && json.Unmarshal(raw, &value) == nil {
+			return strings.TrimSpace(string(value))
+		}
+		return ""
+	}
+
+	card := &search.DefinitionCard{
+		Word: strings.TrimSpace(string(d.Word)),
+		URL:  strings.TrimSpace(string(d.URL)),
+	}
+	for _, p := range definitionPronunciations {
+		if phonetic := field(p.phonetic); phonetic != "" {
+			card.Pronunciations = append(card.Pronunciations, search.Pronunciation{Label: p.label, Phonetic: phonetic, Audio: field(p.audio)})
+		}
+	}
+	for _, s := range append(d.Senses, d.Definitions...) {
+		sense := search.Sense{
+			PartOfSpeech: strings.TrimSpace(string(s.PartOfSpeech)),
+			Definition:   strings.TrimSpace(string(s.Definition)),
+		}
+		if sense.PartOfSpeech == "" {
+			sense.PartOfSpeech = strings.TrimSpace(string(s.POS))
+		}
+		if sense.Definition == "" {
+			sense.Definition = strings.TrimSpace(string(s.Meaning))
+		}
+		for _, example := range append(s.Examples, s.Example) {
+			if text := strings.TrimSpace(string(example)); text != "" {
+				sense.Examples = append(sense.Examples, text)
+			}
+		}
+		card.Senses = append(card.Senses, sense)
+	}
+	return card, nil
+}
+
+// parseConversion decodes a conversion or calculator card. The computed value
+// is derived from the amount and rate when the card leaves it out.
+func parseConversion(kind string, content []byte) (*search.ConversionCard, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(content, &fields); err != nil {
+		return nil, err
+	}
+	pick := func(names []string) string {
+		for _, name := range names {
+			var value flexString
+			if raw, ok := fields[name]; ok && json.Unmarshal(raw, &value) == nil && strings.TrimSpace(string(value)) != "" {
+				return strings.TrimSpace(string(value))
+			}
+		}
+		return ""
+	}
+	f := conversionFields
+	card := &search.ConversionCard{
+		Kind:       kind,
+		Expression: pick(f.expression),
+		FromAmount: pick(f.fromAmount),
+		FromUnit:   pick(f.fromUnit),
+		ToAmount:   pick(f.toAmount),
+		ToUnit:     pick(f.toUnit),
+		Rate:       pick(f.rate),
+		Time:       pick(f.time),
+	}
+	if card.ToAmount == "" && card.FromAmount != "" && card.Rate != "" {
+		amount, errAmount := strconv.ParseFloat(strings.ReplaceAll(card.FromAmount, ",", ""), 64)
+		rate, errRate := strconv.ParseFloat(card.Rate, 64)
+		if errAmount == nil && errRate == nil {
+			card.ToAmount = strconv.FormatFloat(math.Round(amount*rate*10000)/10000, 'f', -1, 64)
+		}
+	}
+	return card, nil
+}
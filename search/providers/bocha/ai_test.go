@@ -0,0 +1,163 @@
+package bocha
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"com.moguyn/mcp-go-search/config"
+	"com.moguyn/mcp-go-search/fixtures"
+	"com.moguyn/mcp-go-search/search"
+)
+
+func TestService_Search_AISearch(t *testing.T) {
+	var (
+		paths []string
+		sent  AIRequest
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/v1/ai-search" {
+			_ = json.Unmarshal(body, &sent)
+			_, _ = w.Write(fixtures.MustResponse(fixtures.AISearch))
+			return
+		}
+		_, _ = w.Write(fixtures.MustResponse(fixtures.Web))
+	}))
+	defer server.Close()
+
+	service := NewWithConfig(&config.Config{
+		BochaAPIKey:      "test-api-key",
+		BochaAPIBaseURL:  server.URL + "/v1/web-search",
+		BochaAISearchURL: server.URL + "/v1/ai-search",
+		HTTPTimeout:      5 * time.Second,
+	})
+	if !service.Capabilities().AISearch {
+		t.Error("Expected the AI search to be reported")
+	}
+
+	response, err := service.Search(search.WithAISearch(context.Background()), "北京天气", "day", 5, false)
+	if err != nil {
+		t.Fatalf("Search returned an error: %v", err)
+	}
+	if !sent.Answer || sent.Stream || sent.Query != "北京天气" || sent.Count != 5 {
+		t.Errorf("Expected a request for the whole answer at once, got %+v", sent)
+	}
+	if response.Data.Answer == nil || !strings.HasPrefix(response.Data.Answer.Text, "北京今天晴") {
+		t.Errorf("Expected the answer, got %+v", response.Data.Answer)
+	}
+	if len(response.Data.WebPages.Value) != 1 || response.Data.WebPages.TotalEstimatedMatches != 320 {
+		t.Errorf("Expected the web page source as results, got %+v", response.Data.WebPages)
+	}
+	cards := response.Data.Cards
+	if len(cards) != 3 {
+		t.Fatalf("Expected the weather, stock and encyclopedia cards, got %+v", cards)
+	}
+	if w := cards[0].Weather; cards[0].Type != search.CardWeather || w == nil || w.Location != "北京" || w.Temperature != "24" || w.High != "27" {
+		t.Errorf("Unexpected weather card: %+v", cards[0])
+	}
+	if s := cards[1].Stock; cards[1].Type != search.CardStock || s == nil || s.Symbol != "EXH" || s.Price != "123.45" {
+		t.Errorf("Unexpected stock card: %+v", cards[1])
+	}
+	if b := cards[2].Baike; cards[2].Type != search.CardBaike || b == nil || b.Title != "北京市" || len(b.Facts) != 2 {
+		t.Errorf("Unexpected encyclopedia card: %+v", cards[2])
+	}
+
+	if _, err := service.Search(context.Background(), "golang", "noLimit", 5, false); err != nil {
+		t.Fatalf("Search returned an error: %v", err)
+	}
+	if len(paths) != 2 || paths[0] != "/v1/ai-search" || paths[1] != "/v1/web-search" {
+		t.Errorf("Expected the AI search only when asked for, got %v", paths)
+	}
+}
+
+func TestParseAIResponse_Errors(t *testing.T) {
+	tests := []struct {
+		name       string
+		statusCode int
+		body       string
+		expected   string
+	}{
+		{"status", http.StatusUnauthorized, `{"error": "invalid api key"}`, "bocha api error (status 401): invalid api key"},
+		{"code", http.StatusOK, `{"code": 429, "msg": "rate limited"}`, "bocha ai search returned code 429"},
+		{"not json", http.StatusOK, `<html>`, "failed to parse bocha ai search response"},
+		{"bad card", http.StatusOK, `{"code": 200, "messages": [{"type": "source", "content_type": "stock", "content": "["}]}`, "failed to parse bocha ai search stock source"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseAIResponse(tt.statusCode, []byte(tt.body))
+			if err == nil || !strings.HasPrefix(err.Error(), tt.expected) {
+				t.Errorf("Expected %q, got %v", tt.expected, err)
+			}
+		})
+	}
+
+	response, err := parseAIResponse(http.StatusOK, []byte(`{"code": 200, "messages": [{"type": "follow_up", "content": "What else?"}]}`))
+	if err != nil || response.Data.Answer != nil || response.Data.WebPages.Value == nil {
+		t.Errorf("Expected an empty response without sources or answer, got %+v (%v)", response, err)
+	}
+}
+
+func TestParseAIResponse_Conversion(t *testing.T) {
+	body := `{"code": 200, "messages": [
+		{"type": "source", "content_type": "exchangerate", "content": "{\"from_currency\": \"USD\", \"to_currency\": \"CNY\", \"amount\": 100, \"rate\": \"7.2451\", \"update_time\": \"2024-05-01 10:00\"}"},
+		{"type": "source", "content_type": "unit_conversion", "content": "{\"from\": \"km\", \"to\": \"mi\", \"from_value\": \"10\", \"to_value\": 6.2137, \"extra\": {\"nested\": true}}"},
+		{"type": "source", "content_type": "calculator", "content": "{\"expression\": \"2^10\", \"result\": 1024}"}
+	]}`
+	response, err := parseAIResponse(http.StatusOK, []byte(body))
+	if err != nil {
+		t.Fatalf("parseAIResponse returned an error: %v", err)
+	}
+	cards := response.Data.Cards
+	if len(cards) != 3 {
+		t.Fatalf("Expected three conversion cards, got %+v", cards)
+	}
+	expected := []search.ConversionCard{
+		{Kind: search.ConversionCurrency, FromAmount: "100", FromUnit: "USD", ToAmount: "724.51", ToUnit: "CNY", Rate: "7.2451", Time: "2024-05-01 10:00"},
+		{Kind: search.ConversionUnit, FromAmount: "10", FromUnit: "km", ToAmount: "6.2137", ToUnit: "mi"},
+		{Kind: search.ConversionCalculation, Expression: "2^10", ToAmount: "1024"},
+	}
+	for i, card := range cards {
+		if card.Type != search.CardConversion || card.Conversion == nil || *card.Conversion != expected[i] {
+			t.Errorf("Card %d: expected %+v, got %+v", i, expected[i], card.Conversion)
+		}
+	}
+}
+
+func TestParseAIResponse_Definition(t *testing.T) {
+	content := `{"word": "serendipity", "us_phonetic": "/ˌserənˈdɪpəti/", "uk_phonetic": "/ˌserənˈdɪpɪti/", "us_audio": "https://dict.example/us.mp3", "url": "https://dict.example/serendipity",` +
+		` "senses": [{"pos": "noun", "definition": "The occurrence of events by chance in a happy way.", "examples": ["A fortunate stroke of serendipity.", " "]}],` +
+		` "definitions": [{"part_of_speech": "noun", "meaning": "A happy accident.", "example": "Finding it was pure serendipity."}]}`
+	message, _ := json.Marshal(map[string]string{"type": "source", "content_type": "dictionary", "content": content})
+	response, err := parseAIResponse(http.StatusOK, []byte(`{"code": 200, "messages": [`+string(message)+`]}`))
+	if err != nil {
+		t.Fatalf("parseAIResponse returned an error: %v", err)
+	}
+	cards := response.Data.Cards
+	if len(cards) != 1 || cards[0].Type != search.CardDefinition || cards[0].Definition == nil {
+		t.Fatalf("Expected one definition card, got %+v", cards)
+	}
+	expected := search.DefinitionCard{
+		Word: "serendipity",
+		Pronunciations: []search.Pronunciation{
+			{Label: "US", Phonetic: "/ˌserənˈdɪpəti/", Audio: "https://dict.example/us.mp3"},
+			{Label: "UK", Phonetic: "/ˌserənˈdɪpɪti/"},
+		},
+		Senses: []search.Sense{
+			{PartOfSpeech: "noun", Definition: "The occurrence of events by chance in a happy way.", Examples: []string{"A fortunate stroke of serendipity."}},
+			{PartOfSpeech: "noun", Definition: "A happy accident.", Examples: []string{"Finding it was pure serendipity."}},
+		},
+		URL: "https://dict.example/serendipity",
+	}
+	if !reflect.DeepEqual(*cards[0].Definition, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, *cards[0].Definition)
+	}
+}
@@ -0,0 +1,314 @@
+// Package bocha implements the search provider for the Bocha Web Search API
+package bocha
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"text/template"
+
+	"golang.org/x/time/rate"
+
+	"com.moguyn/mcp-go-search/config"
+	"com.moguyn/mcp-go-search/params"
+	"com.moguyn/mcp-go-search/search"
+)
+
+func init() {
+	search.Register(config.ProviderBocha, func(cfg *config.Config) (search.Provider, error) {
+		return NewWithConfig(cfg), nil
+	})
+}
+
+// Request is the request body of the Bocha Web Search API
+type Request struct {
+	Query     string `json:"query"`
+	Freshness string `json:"freshness"`
+	Count     int    `json:"count"`
+	Summary   bool   `json:"summary"`
+}
+
+// Service implements the search.Provider interface for the Bocha Web Search API
+type Service struct {
+	keyMu       sync.RWMutex
+	apiKey      string
+	regionsMu   sync.RWMutex
+	regions     []Region
+	pro         bool
+	aiSearch    bool
+	agentSearch bool
+	agents      []string
+	template    *template.Template
+	httpClient  *http.Client
+	rateLimiter *rate.Limiter
+}
+
+// New creates a new Bocha provider configured from the environment
+func New() *Service {
+	return NewWithConfig(config.New())
+}
+
+// NewWithConfig creates a new Bocha provider with the provided configuration
+func NewWithConfig(cfg *config.Config) *Service {
+	// Create a rate limiter that allows 10 requests per second with a burst of 20
+	limiter := rate.NewLimiter(rate.Limit(10), 20)
+	// The template is validated at startup, so an error here is unexpected
+	tmpl, _ := cfg.RequestTemplate(config.ProviderBocha)
+
+	return &Service{
+		apiKey:      cfg.BochaAPIKey,
+		regions:     regionsFromConfig(cfg),
+		pro:         cfg.BochaProURL != "",
+		aiSearch:    cfg.BochaAISearchURL != "",
+		agentSearch: cfg.BochaAgentSearchURL != "",
+		agents:      cfg.BochaAgents,
+		template:    tmpl,
+		httpClient:  search.NewHTTPClient(cfg),
+		rateLimiter: limiter,
+	}
+}
+
+// Search performs a search using the Bocha Web Search API
+func (s *Service) Search(ctx context.Context, query string, freshness string, count int, summary bool) (*search.WebSearchResponse, error) {
+	// Apply rate limiting
+	if err := search.WaitRateLimit(ctx, s.rateLimiter); err != nil {
+		return nil, fmt.Errorf("rate limit exceeded: %w", err)
+	}
+
+	// Validate inputs and bring them within the API's limits
+	p, adj, err := params.Normalize(params.Search{
+		Query:     query,
+		Freshness: freshness,
+		Count:     count,
+		Summary:   summary,
+	}, s.Capabilities().Limits())
+	if err != nil {
+		return nil, err
+	}
+	meta := search.ResponseMeta{
+		QueryTruncated: adj.QueryTruncated,
+		CountClamped:   adj.CountClamped,
+	}
+
+	// Create the request payload; the AI search is asked for its answer in
+	// one piece rather than streamed, and an agent only for its sources
+	var reqBody any = Request{
+		Query:     p.Query,
+		Freshness: p.Freshness,
+		Count:     p.Count,
+		Summary:   p.Summary,
+	}
+	aiSearch := search.AISearch(ctx)
+	if aiSearch {
+		reqBody = AIRequest{
+			Query:     p.Query,
+			Freshness: p.Freshness,
+			Count:     p.Count,
+			Answer:    true,
+		}
+	}
+	agent := search.Agent(ctx)
+	if agent != "" {
+		reqBody = AgentRequest{
+			AgentID:    agent,
+			Query:      p.Query,
+			SearchType: "neural",
+		}
+	}
+
+	// Convert the request to JSON, or render the configured template
+	var jsonData []byte
+	if s.template != nil {
+		jsonData, err = config.RenderRequestBody(s.template, config.RequestTemplateData{Search: p, Pro: search.Pro(ctx), AISearch: aiSearch, Agent: agent})
+	} else {
+		jsonData, err = json.Marshal(reqBody)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	// Try the regions in order, failing over when one is unreachable or
+	// failing; the pro endpoint takes the same request and adds an answer and
+	// rich snippets, and the AI and agent searches answer in messages of
+	// their own shape
+	regions := s.Regions()
+	var (
+		statusCode int
+		body       []byte
+	)
+	for i, region := range regions {
+		endpoint := region.SearchURL
+		switch {
+		case agent != "" && region.AgentSearchURL != "":
+			endpoint = region.AgentSearchURL
+		case aiSearch && region.AISearchURL != "":
+			endpoint = region.AISearchURL
+		case search.Pro(ctx) && region.ProURL != "":
+			endpoint = region.ProURL
+		}
+		statusCode, body, err = s.send(ctx, endpoint, jsonData)
+		if (err == nil && statusCode < http.StatusInternalServerError) || ctx.Err() != nil || i == len(regions)-1 {
+			break
+		}
+		s.demote(region.Endpoint)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	parse := parseResponse
+	switch {
+	case agent != "":
+		parse = parseAgentResponse
+	case aiSearch:
+		parse = parseAIResponse
+	}
+	searchResp, err := parse(statusCode, body)
+	if err != nil {
+		return nil, search.WithTransfer(err, int64(len(jsonData)), int64(len(body)))
+	}
+	// An agent takes no count, so its results are cut to it here
+	if results := searchResp.Data.WebPages.Value; agent != "" && len(results) > p.Count {
+		searchResp.Data.WebPages.Value = results[:p.Count]
+	}
+
+	meta.BytesSent = int64(len(jsonData))
+	meta.BytesReceived = int64(len(body))
+	searchResp.Meta = meta
+
+	return searchResp, nil
+}
+
+// send posts a search request to endpoint and returns the response's status
+// and body
+func (s *Service) send(ctx context.Context, endpoint string, jsonData []byte) (int, []byte, error) {
+	// Create the HTTP request
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to create HTTP request: %w", err)
+	}
+
+	// Set headers
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", s.APIKey()))
+	req.Header.Set("User-Agent", "BochaWebSearchMCPServer/1.0")
+
+	// Send the request
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to send request to Bocha API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Read the response body with a size limit to prevent memory exhaustion
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 10*1024*1024)) // 10MB limit
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to read Bocha API response body: %w", err)
+	}
+	return resp.StatusCode, body, nil
+}
+
+// parseResponse decodes a Bocha API response body in either the current or
+// the legacy shape, turning non-200 statuses and responses without web page
+// results into errors
+func parseResponse(statusCode int, body []byte) (*search.WebSearchResponse, error) {
+	// Check for non-200 status code
+	if statusCode != http.StatusOK {
+		// Try to extract error message from response if possible
+		var errorResp struct {
+			Error string `json:"error"`
+		}
+		if err := json.Unmarshal(body, &errorResp); err == nil && errorResp.Error != "" {
+			return nil, fmt.Errorf("bocha api error (status %d): %s", statusCode, errorResp.Error)
+		}
+
+		// Don't return the full response body in case of error to avoid leaking sensitive information
+		return nil, fmt.Errorf("bocha api returned status code %d", statusCode)
+	}
+
+	// Older endpoints answer with a flat list of results
+	if isLegacyResponse(body) {
+		var legacy legacyResponse
+		if err := json.Unmarshal(body, &legacy); err != nil {
+			return nil, fmt.Errorf("failed to parse bocha api response: %w", err)
+		}
+		return legacy.toEnvelope(), nil
+	}
+
+	// Parse the response
+	var searchResp search.WebSearchResponse
+	if err := json.Unmarshal(body, &searchResp); err != nil {
+		return nil, fmt.Errorf("failed to parse bocha api response: %w", err)
+	}
+
+	// Validate response
+	if searchResp.Data.WebPages.Value == nil {
+		return nil, fmt.Errorf("bocha api returned empty or invalid response")
+	}
+
+	return &searchResp, nil
+}
+
+// RateLimitStats returns the current state of the rate limiter
+func (s *Service) RateLimitStats() search.RateLimitStats {
+	return search.RateLimitStats{
+		Limit:     float64(s.rateLimiter.Limit()),
+		Burst:     s.rateLimiter.Burst(),
+		Available: s.rateLimiter.Tokens(),
+	}
+}
+
+// APIKey returns the API key currently used to authenticate with Bocha
+func (s *Service) APIKey() string {
+	s.keyMu.RLock()
+	defer s.keyMu.RUnlock()
+	return s.apiKey
+}
+
+// SetAPIKey atomically replaces the API key used for subsequent requests
+func (s *Service) SetAPIKey(apiKey string) error {
+	if apiKey == "" {
+		return fmt.Errorf("api key cannot be empty")
+	}
+	s.keyMu.Lock()
+	defer s.keyMu.Unlock()
+	s.apiKey = apiKey
+	return nil
+}
+
+// Name returns the provider name used in configuration
+func (s *Service) Name() string {
+	return config.ProviderBocha
+}
+
+// Capabilities describes what the Bocha Web Search API supports
+func (s *Service) Capabilities() search.Capabilities {
+	return search.Capabilities{
+		Provider:    config.ProviderBocha,
+		Images:      true,
+		Videos:      true,
+		Summaries:   true,
+		Freshness:   params.Freshness,
+		MaxCount:    params.MaxCount,
+		Operators:   []string{search.OperatorSite, search.OperatorPhrase, search.OperatorExclude},
+		Pro:         s.pro,
+		AISearch:    s.aiSearch,
+		AgentSearch: s.agentSearch,
+		Agents:      s.agents,
+	}
+}
+
+// sanitizeQuery performs basic sanitization on the search query
+// to prevent potential injection attacks
+func sanitizeQuery(query string) string {
+	// This is a simple implementation - in a production environment,
+	// you might want to use a more sophisticated sanitization library
+
+	// Limit query length to prevent DoS attacks
+	query, _ = params.TruncateQuery(query)
+	return query
+}
@@ -1,7 +1,8 @@
-package search
+package bocha
 
 import (
 	"context"
+	"crypto/tls"
 	"encoding/json"
 	"io"
 	"net/http"
@@ -12,10 +13,12 @@ import (
 	"time"
 
 	"com.moguyn/mcp-go-search/config"
+	"com.moguyn/mcp-go-search/fixtures"
+	"com.moguyn/mcp-go-search/search"
 )
 
-// TestNewBochaService tests the NewBochaService function
-func TestNewBochaService(t *testing.T) {
+// TestNew tests the New function
+func TestNew(t *testing.T) {
 	// Save original environment variables to restore later
 	origAPIKey := os.Getenv("BOCHA_API_KEY")
 	origAPIBaseURL := os.Getenv("BOCHA_API_BASE_URL")
@@ -34,22 +37,33 @@ func TestNewBochaService(t *testing.T) {
 	os.Setenv("HTTP_TIMEOUT", "5s")
 
 	// Create a new service
-	service := NewBochaService()
+	service := New()
 
 	// Check that the service was created with the correct values
 	if service.apiKey != "test-api-key" {
 		t.Errorf("Expected apiKey to be 'test-api-key', got '%s'", service.apiKey)
 	}
-	if service.apiBaseURL != "https://test.api.com" {
-		t.Errorf("Expected apiBaseURL to be 'https://test.api.com', got '%s'", service.apiBaseURL)
+	if regions := service.Regions(); len(regions) != 1 || regions[0].SearchURL != "https://test.api.com" {
+		t.Errorf("Expected the single region at 'https://test.api.com', got %+v", regions)
 	}
 	if service.httpClient.Timeout != 5*time.Second {
 		t.Errorf("Expected httpClient.Timeout to be 5s, got %s", service.httpClient.Timeout)
 	}
 }
 
-// TestBochaService_Search tests the Search method of BochaService
-func TestBochaService_Search(t *testing.T) {
+func TestNewWithConfig_TLS(t *testing.T) {
+	service := NewWithConfig(&config.Config{
+		BochaAPIKey:   "test-api-key",
+		TLSMinVersion: config.TLSVersion13,
+	})
+	transport := service.httpClient.Transport.(*http.Transport)
+	if transport.TLSClientConfig.MinVersion != tls.VersionTLS13 {
+		t.Errorf("Expected TLS 1.3 minimum, got %x", transport.TLSClientConfig.MinVersion)
+	}
+}
+
+// TestService_Search tests the Search method of Service
+func TestService_Search(t *testing.T) {
 	// Mock server response
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// Check request method
@@ -75,7 +89,7 @@ func TestBochaService_Search(t *testing.T) {
 			t.Fatalf("Failed to read request body: %v", err)
 		}
 
-		var req WebSearchRequest
+		var req Request
 		if err := json.Unmarshal(body, &req); err != nil {
 			t.Fatalf("Failed to parse request body: %v", err)
 		}
@@ -98,19 +112,19 @@ func TestBochaService_Search(t *testing.T) {
 		}
 
 		// Return a mock response
-		resp := WebSearchResponse{
+		resp := search.WebSearchResponse{
 			Code:  200,
 			LogID: "test-log-id",
 			Msg:   nil,
-			Data: Data{
+			Data: search.Data{
 				Type: "SearchResponse",
-				QueryContext: QueryContext{
+				QueryContext: search.QueryContext{
 					OriginalQuery: "test query",
 				},
-				WebPages: WebPages{
+				WebPages: search.WebPages{
 					WebSearchURL:          "https://bochaai.com/search?q=test+query",
 					TotalEstimatedMatches: 2,
-					Value: []WebPageResult{
+					Value: []search.WebPageResult{
 						{
 							ID:              "https://api.bochaai.com/v1/#WebPages.0",
 							Name:            "Test Result 1",
@@ -134,8 +148,8 @@ func TestBochaService_Search(t *testing.T) {
 					},
 					SomeResultsRemoved: false,
 				},
-				Images: Images{
-					Value: []ImageResult{
+				Images: search.Images{
+					Value: []search.ImageResult{
 						{
 							ThumbnailURL:       "https://example.com/thumbnail1.jpg",
 							ContentURL:         "https://example.com/image1.jpg",
@@ -165,7 +179,7 @@ func TestBochaService_Search(t *testing.T) {
 	}
 
 	// Create a search service with the test configuration
-	service := NewBochaServiceWithConfig(cfg)
+	service := NewWithConfig(cfg)
 
 	// Call the Search method
 	ctx := context.Background()
@@ -202,8 +216,8 @@ func TestBochaService_Search(t *testing.T) {
 	}
 }
 
-// TestBochaService_Search_Validation tests the validation in the Search method
-func TestBochaService_Search_Validation(t *testing.T) {
+// TestService_Search_Validation tests the validation in the Search method
+func TestService_Search_Validation(t *testing.T) {
 	// Create a mock server that returns a valid response
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
@@ -244,7 +258,7 @@ func TestBochaService_Search_Validation(t *testing.T) {
 	}
 
 	// Create a search service with the test configuration
-	service := NewBochaServiceWithConfig(cfg)
+	service := NewWithConfig(cfg)
 	ctx := context.Background()
 
 	// Test empty query
@@ -323,8 +337,8 @@ func TestSanitizeQuery(t *testing.T) {
 	}
 }
 
-// TestBochaService_Search_Errors tests error handling in the Search method
-func TestBochaService_Search_Errors(t *testing.T) {
+// TestService_Search_Errors tests error handling in the Search method
+func TestService_Search_Errors(t *testing.T) {
 	// Test server that returns an error
 	errorServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
@@ -341,7 +355,7 @@ func TestBochaService_Search_Errors(t *testing.T) {
 	}
 
 	// Create a search service with the error configuration
-	errorService := NewBochaServiceWithConfig(errorCfg)
+	errorService := NewWithConfig(errorCfg)
 
 	// Test with error response
 	ctx := context.Background()
@@ -368,7 +382,7 @@ func TestBochaService_Search_Errors(t *testing.T) {
 	}
 
 	// Create a search service with the invalid JSON configuration
-	invalidJSONService := NewBochaServiceWithConfig(invalidJSONCfg)
+	invalidJSONService := NewWithConfig(invalidJSONCfg)
 
 	// Test with invalid JSON response
 	_, err = invalidJSONService.Search(ctx, "test query", "noLimit", 10, true)
@@ -407,7 +421,7 @@ func TestBochaService_Search_Errors(t *testing.T) {
 	}
 
 	// Create a search service with the empty results configuration
-	emptyResultsService := NewBochaServiceWithConfig(emptyResultsCfg)
+	emptyResultsService := NewWithConfig(emptyResultsCfg)
 
 	// Test with empty results
 	_, err = emptyResultsService.Search(ctx, "test query", "noLimit", 10, true)
@@ -415,3 +429,102 @@ func TestBochaService_Search_Errors(t *testing.T) {
 		t.Errorf("Expected no error for empty results, got %v", err)
 	}
 }
+
+func TestService_RefusesCrossHostRedirect(t *testing.T) {
+	forwarded := false
+	attacker := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		forwarded = true
+	}))
+	defer attacker.Close()
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, attacker.URL, http.StatusTemporaryRedirect)
+	}))
+	defer upstream.Close()
+
+	service := NewWithConfig(&config.Config{
+		BochaAPIKey:     "test-api-key",
+		BochaAPIBaseURL: upstream.URL,
+		HTTPTimeout:     5 * time.Second,
+	})
+	if _, err := service.Search(context.Background(), "golang", "noLimit", 10, false); err == nil {
+		t.Error("Expected error for a redirect to another host, got nil")
+	}
+	if forwarded {
+		t.Error("Expected the query not to reach the other host")
+	}
+}
+
+func TestRegistered(t *testing.T) {
+	provider, err := search.NewProvider(&config.Config{BochaAPIKey: "test-api-key", HTTPTimeout: time.Second})
+	if err != nil {
+		t.Fatalf("NewProvider returned an error: %v", err)
+	}
+	if _, ok := provider.(*Service); !ok {
+		t.Errorf("Expected the Bocha provider by default, got %T", provider)
+	}
+	if provider.Name() != config.ProviderBocha {
+		t.Errorf("Expected name %q, got %q", config.ProviderBocha, provider.Name())
+	}
+}
+
+func TestService_Search_RequestTemplate(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if string(body) != `{"q": "golang generics", "limit": 5}` {
+			t.Errorf("Expected the rendered template, got %s", body)
+		}
+		_, _ = w.Write(fixtures.MustResponse(fixtures.Web))
+	}))
+	defer server.Close()
+
+	service := NewWithConfig(&config.Config{
+		BochaAPIKey:      "test-api-key",
+		BochaAPIBaseURL:  server.URL,
+		HTTPTimeout:      5 * time.Second,
+		RequestTemplates: map[string]string{config.ProviderBocha: `{"q": {{json .Query}}, "limit": {{.Count}}}`},
+	})
+	if _, err := service.Search(context.Background(), "golang generics", "noLimit", 5, false); err != nil {
+		t.Fatalf("Search returned an error: %v", err)
+	}
+}
+
+func TestService_Search_Pro(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(fixtures.MustResponse(fixtures.Pro))
+	}))
+	defer server.Close()
+
+	service := NewWithConfig(&config.Config{
+		BochaAPIKey:     "test-api-key",
+		BochaAPIBaseURL: server.URL + "/v1/web-search",
+		BochaProURL:     server.URL + "/v1/web-search-pro",
+		HTTPTimeout:     5 * time.Second,
+	})
+	if !service.Capabilities().Pro {
+		t.Error("Expected the pro endpoint to be reported")
+	}
+
+	response, err := service.Search(search.WithPro(context.Background()), "golang generics", "noLimit", 10, false)
+	if err != nil {
+		t.Fatalf("Search returned an error: %v", err)
+	}
+	if response.Data.Answer == nil || len(response.Data.Answer.Sources) != 2 {
+		t.Errorf("Expected the answer with its sources, got %+v", response.Data.Answer)
+	}
+	if snippet := response.Data.WebPages.Value[0].RichSnippet; snippet == nil || snippet.Type != "tutorial" {
+		t.Errorf("Expected the rich snippet of the first result, got %+v", snippet)
+	}
+	if _, err := service.Search(context.Background(), "golang generics", "noLimit", 10, false); err != nil {
+		t.Fatalf("Search returned an error: %v", err)
+	}
+	if len(paths) != 2 || paths[0] != "/v1/web-search-pro" || paths[1] != "/v1/web-search" {
+		t.Errorf("Expected the pro endpoint only when asked for, got %v", paths)
+	}
+
+	if NewWithConfig(&config.Config{BochaAPIKey: "test-api-key"}).Capabilities().Pro {
+		t.Error("Expected no pro endpoint without a pro URL")
+	}
+}
@@ -0,0 +1,40 @@
+package bocha
+
+import (
+	"net/http"
+	"testing"
+	"unicode/utf8"
+
+	"com.moguyn/mcp-go-search/fixtures"
+)
+
+func FuzzSanitizeQuery(f *testing.F) {
+	f.Add("golang generics")
+	f.Add("阿里巴巴2025年的ESG报告")
+	f.Add("")
+
+	f.Fuzz(func(t *testing.T, query string) {
+		sanitized := sanitizeQuery(query)
+		if len(sanitized) > 1000 {
+			t.Errorf("Expected at most 1000 bytes, got %d", len(sanitized))
+		}
+		if utf8.ValidString(query) && !utf8.ValidString(sanitized) {
+			t.Errorf("Truncation split a UTF-8 sequence: %q", sanitized)
+		}
+	})
+}
+
+func FuzzParseResponse(f *testing.F) {
+	for _, name := range fixtures.Names() {
+		f.Add(fixtures.StatusCode(name), fixtures.MustResponse(name))
+	}
+	f.Add(http.StatusOK, []byte(`{"data":{"webPages":{"value":null}}}`))
+	f.Add(http.StatusBadGateway, []byte(`<html>bad gateway</html>`))
+
+	f.Fuzz(func(t *testing.T, statusCode int, body []byte) {
+		response, err := parseResponse(statusCode, body)
+		if err == nil && (response == nil || response.Data.WebPages.Value == nil) {
+			t.Error("Expected an error or a response with web page results")
+		}
+	})
+}
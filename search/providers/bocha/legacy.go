@@ -0,0 +1,75 @@
+package bocha
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"com.moguyn/mcp-go-search/search"
+)
+
+// legacyResponse is the flat response shape of older Bocha-compatible
+// endpoints, still returned by some proxies in front of the API:
+//
+//	{"query": "...", "summary": "...", "results": [{"title": "...", "url": "...", ...}]}
+type legacyResponse struct {
+	Code    int            `json:"code"`
+	Query   string         `json:"query"`
+	Summary string         `json:"summary"`
+	Results []legacyResult `json:"results"`
+}
+
+// legacyResult is a single result of the flat response shape
+type legacyResult struct {
+	Title         string `json:"title"`
+	URL           string `json:"url"`
+	Snippet       string `json:"snippet"`
+	Summary       string `json:"summary"`
+	SiteName      string `json:"siteName"`
+	SiteIcon      string `json:"siteIcon"`
+	DatePublished string `json:"datePublished"`
+}
+
+// isLegacyResponse reports whether body has the flat results/summary shape
+// rather than the code/data/webPages envelope
+func isLegacyResponse(body []byte) bool {
+	var probe struct {
+		Data    json.RawMessage `json:"data"`
+		Results json.RawMessage `json:"results"`
+	}
+	if err := json.Unmarshal(body, &probe); err != nil {
+		return false
+	}
+	return (len(probe.Data) == 0 || string(probe.Data) == "null") && len(probe.Results) > 0 && string(probe.Results) != "null"
+}
+
+// toEnvelope converts a flat response to the current format. A result's
+// summary stands in for a missing snippet; the overall summary has no
+// counterpart and is dropped.
+func (l legacyResponse) toEnvelope() *search.WebSearchResponse {
+	results := make([]search.WebPageResult, 0, len(l.Results))
+	for _, r := range l.Results {
+		snippet := r.Snippet
+		if snippet == "" {
+			snippet = r.Summary
+		}
+		results = append(results, search.WebPageResult{
+			Name:            r.Title,
+			URL:             r.URL,
+			DisplayURL:      r.URL,
+			Snippet:         snippet,
+			SiteName:        r.SiteName,
+			SiteIcon:        r.SiteIcon,
+			DateLastCrawled: r.DatePublished,
+		})
+	}
+	code := l.Code
+	if code == 0 {
+		code = http.StatusOK
+	}
+	searchResp := &search.WebSearchResponse{Code: code}
+	searchResp.Data.Type = "SearchResponse"
+	searchResp.Data.QueryContext.OriginalQuery = l.Query
+	searchResp.Data.WebPages.TotalEstimatedMatches = len(results)
+	searchResp.Data.WebPages.Value = results
+	return searchResp
+}
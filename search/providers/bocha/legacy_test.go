@@ -0,0 +1,55 @@
+package bocha
+
+import (
+	"net/http"
+	"testing"
+
+	"com.moguyn/mcp-go-search/fixtures"
+)
+
+func TestIsLegacyResponse(t *testing.T) {
+	tests := []struct {
+		body     string
+		expected bool
+	}{
+		{`{"results": [{"title": "Go", "url": "https://go.dev"}]}`, true},
+		{`{"data": null, "results": []}`, true},
+		{`{"code": 200, "data": {"webPages": {"value": []}}}`, false},
+		{`{"results": null}`, false},
+		{`not json`, false},
+	}
+	for _, tt := range tests {
+		if got := isLegacyResponse([]byte(tt.body)); got != tt.expected {
+			t.Errorf("Expected %v for %s, got %v", tt.expected, tt.body, got)
+		}
+	}
+}
+
+func TestParseResponse_Legacy(t *testing.T) {
+	response, err := parseResponse(http.StatusOK, fixtures.MustResponse(fixtures.Legacy))
+	if err != nil {
+		t.Fatalf("parseResponse returned an error: %v", err)
+	}
+	if response.Code != http.StatusOK || response.Data.QueryContext.OriginalQuery != "golang generics" {
+		t.Errorf("Unexpected envelope: %+v", response)
+	}
+	results := response.Data.WebPages.Value
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 results, got %d", len(results))
+	}
+	if results[0].Name != "Tutorial: Getting started with generics" || results[0].DisplayURL != "https://go.dev/doc/tutorial/generics" {
+		t.Errorf("Unexpected first result: %+v", results[0])
+	}
+	if results[0].DateLastCrawled != "2025-02-11T08:15:00Z" {
+		t.Errorf("Expected the publication date, got %q", results[0].DateLastCrawled)
+	}
+	if results[1].Snippet == "" {
+		t.Error("Expected the result's summary as snippet when it has none")
+	}
+
+	// An empty flat response is still a valid, empty result list
+	response, err = parseResponse(http.StatusOK, []byte(`{"query": "nothing", "results": []}`))
+	if err != nil || response.Data.WebPages.Value == nil || len(response.Data.WebPages.Value) != 0 {
+		t.Errorf("Expected an empty result list, got %v (%v)", response, err)
+	}
+}
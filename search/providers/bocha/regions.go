@@ -0,0 +1,149 @@
+package bocha
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"com.moguyn/mcp-go-search/config"
+	"com.moguyn/mcp-go-search/search"
+)
+
+// probeTimeout bounds the probe of a single region
+const probeTimeout = 5 * time.Second
+
+// Region is one of the endpoints the API is served from
+type Region struct {
+	Endpoint  string `json:"endpoint"`
+	SearchURL string `json:"search_url"`
+	ProURL    string `json:"pro_url,omitempty"`
+	// AISearchURL is the region's AI search, when there is one
+	AISearchURL string `json:"ai_search_url,omitempty"`
+	// AgentSearchURL is the region's agent search, when there is one
+	AgentSearchURL string `json:"agent_search_url,omitempty"`
+	// Latency is the round trip measured by the last probe; zero before probing
+	Latency time.Duration `json:"latency"`
+	// Error is why the last probe failed, when it did
+	Error string `json:"error,omitempty"`
+}
+
+// regionsFromConfig returns the regions searches go to, in the configured
+// order: BochaRegions, or the single endpoint of BochaAPIBaseURL, BochaProURL,
+// BochaAISearchURL and BochaAgentSearchURL
+func regionsFromConfig(cfg *config.Config) []Region {
+	if len(cfg.BochaRegions) == 0 {
+		return []Region{{
+			Endpoint:       cfg.BochaEndpoint,
+			SearchURL:      cfg.BochaAPIBaseURL,
+			ProURL:         cfg.BochaProURL,
+			AISearchURL:    cfg.BochaAISearchURL,
+			AgentSearchURL: cfg.BochaAgentSearchURL,
+		}}
+	}
+	regions := make([]Region, 0, len(cfg.BochaRegions))
+	for _, endpoint := range cfg.BochaRegions {
+		region := Region{Endpoint: endpoint, SearchURL: cfg.BochaRegionURL(endpoint, config.BochaWebSearch)}
+		if cfg.BochaProURL != "" {
+			region.ProURL = cfg.BochaRegionURL(endpoint, config.BochaWebSearchPro)
+		}
+		if cfg.BochaAISearchURL != "" {
+			region.AISearchURL = cfg.BochaRegionURL(endpoint, config.BochaAISearch)
+		}
+		if cfg.BochaAgentSearchURL != "" {
+			region.AgentSearchURL = cfg.BochaRegionURL(endpoint, config.BochaAgentSearch)
+		}
+		regions = append(regions, region)
+	}
+	return regions
+}
+
+// Regions returns the regions in the order searches try them
+func (s *Service) Regions() []Region {
+	s.regionsMu.RLock()
+	defer s.regionsMu.RUnlock()
+	return append([]Region(nil), s.regions...)
+}
+
+// ProbeRegions measures how long each region takes to answer and orders the
+// regions fastest first, with unreachable ones last. It returns the new order.
+// The probe is a HEAD request to the search URL, which costs no quota; any
+// response counts as reachable.
+func (s *Service) ProbeRegions(ctx context.Context) []Region {
+	regions := s.Regions()
+	if len(regions) < 2 {
+		return regions
+	}
+
+	var wg sync.WaitGroup
+	for i := range regions {
+		wg.Add(1)
+		go func(region *Region) {
+			defer wg.Done()
+			region.Latency, region.Error = 0, ""
+			latency, err := s.probe(ctx, region.SearchURL)
+			if err != nil {
+				region.Error = err.Error()
+				return
+			}
+			region.Latency = latency
+		}(&regions[i])
+	}
+	wg.Wait()
+
+	sort.SliceStable(regions, func(i, j int) bool {
+		if (regions[i].Error == "") != (regions[j].Error == "") {
+			return regions[i].Error == ""
+		}
+		return regions[i].Error == "" && regions[i].Latency < regions[j].Latency
+	})
+
+	s.regionsMu.Lock()
+	s.regions = regions
+	s.regionsMu.Unlock()
+	return append([]Region(nil), regions...)
+}
+
+// probe returns the time a region takes to answer a HEAD request
+func (s *Service) probe(ctx context.Context, url string) (time.Duration, error) {
+	ctx, cancel := context.WithTimeout(ctx, probeTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create HTTP request: %w", err)
+	}
+	req.Header.Set("User-Agent", "BochaWebSearchMCPServer/1.0")
+
+	start := time.Now()
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to probe Bocha API: %w", err)
+	}
+	resp.Body.Close()
+	return time.Since(start), nil
+}
+
+// Warm connects to the region searches go to first, ahead of the first search
+func (s *Service) Warm(ctx context.Context) error {
+	regions := s.Regions()
+	if len(regions) == 0 {
+		return nil
+	}
+	return search.WarmURL(ctx, s.httpClient, regions[0].SearchURL)
+}
+
+// demote moves a region that failed a search behind the others, so later
+// searches go to a working region first
+func (s *Service) demote(endpoint string) {
+	s.regionsMu.Lock()
+	defer s.regionsMu.Unlock()
+	for i, region := range s.regions {
+		if region.Endpoint != endpoint {
+			continue
+		}
+		s.regions = append(append(s.regions[:i:i], s.regions[i+1:]...), region)
+		return
+	}
+}
@@ -0,0 +1,166 @@
+package bocha
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"com.moguyn/mcp-go-search/config"
+	"com.moguyn/mcp-go-search/fixtures"
+	"com.moguyn/mcp-go-search/search"
+)
+
+// regionServer serves a region of the API, answering probes after delay and
+// searches with status, counting the searches it gets
+type regionServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	searches []string
+}
+
+func newRegionServer(t *testing.T, delay time.Duration, status int) *regionServer {
+	t.Helper()
+	server := &regionServer{}
+	server.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead {
+			time.Sleep(delay)
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		server.mu.Lock()
+		server.searches = append(server.searches, r.URL.Path)
+		server.mu.Unlock()
+		w.WriteHeader(status)
+		_, _ = w.Write(fixtures.MustResponse(fixtures.Web))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func (s *regionServer) Searches() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.searches...)
+}
+
+func TestRegionsFromConfig(t *testing.T) {
+	regions := regionsFromConfig(&config.Config{
+		BochaRegions: []string{"https://eu.search-proxy.example/v1", "https://us.search-proxy.example/v1"},
+		BochaPaths:   map[string]string{config.BochaWebSearch: "/search"},
+		BochaProURL:  "https://api.bochaai.com/v1/web-search-pro",
+	})
+	if len(regions) != 2 || regions[0].SearchURL != "https://eu.search-proxy.example/v1/search" || regions[1].ProURL != "https://us.search-proxy.example/v1/web-search-pro" {
+		t.Errorf("Expected the operations below each region, got %+v", regions)
+	}
+
+	regions = regionsFromConfig(&config.Config{BochaAPIBaseURL: "https://api.bochaai.com/v1/web-search"})
+	if len(regions) != 1 || regions[0].SearchURL != "https://api.bochaai.com/v1/web-search" || regions[0].ProURL != "" {
+		t.Errorf("Expected the single configured endpoint, got %+v", regions)
+	}
+}
+
+func TestService_ProbeRegions(t *testing.T) {
+	slow := newRegionServer(t, 100*time.Millisecond, http.StatusOK)
+	fast := newRegionServer(t, 0, http.StatusOK)
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+
+	service := NewWithConfig(&config.Config{
+		BochaAPIKey:  "test-api-key",
+		BochaRegions: []string{down.URL, slow.URL, fast.URL},
+		HTTPTimeout:  5 * time.Second,
+	})
+	regions := service.ProbeRegions(context.Background())
+	if len(regions) != 3 || regions[0].Endpoint != fast.URL || regions[1].Endpoint != slow.URL || regions[2].Endpoint != down.URL {
+		t.Fatalf("Expected the fastest region first and the unreachable one last, got %+v", regions)
+	}
+	if regions[0].Latency <= 0 || regions[1].Latency < 100*time.Millisecond || regions[2].Error == "" {
+		t.Errorf("Expected the measured latencies and the probe error, got %+v", regions)
+	}
+
+	if _, err := service.Search(context.Background(), "golang", "noLimit", 10, false); err != nil {
+		t.Fatalf("Search returned an error: %v", err)
+	}
+	if len(fast.Searches()) != 1 || len(slow.Searches()) != 0 {
+		t.Errorf("Expected the search to go to the fastest region, got %d and %d", len(fast.Searches()), len(slow.Searches()))
+	}
+}
+
+func TestService_Warm(t *testing.T) {
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+	up := newRegionServer(t, 0, http.StatusOK)
+
+	// Warming connects to the region searches try first, without searching
+	service := NewWithConfig(&config.Config{
+		BochaAPIKey:  "test-api-key",
+		BochaRegions: []string{up.URL, down.URL},
+		HTTPTimeout:  5 * time.Second,
+	})
+	var _ search.Warmer = service
+	if err := service.Warm(context.Background()); err != nil {
+		t.Errorf("Warm returned an error: %v", err)
+	}
+	if len(up.Searches()) != 0 {
+		t.Errorf("Expected no search, got %v", up.Searches())
+	}
+
+	service = NewWithConfig(&config.Config{
+		BochaAPIKey:  "test-api-key",
+		BochaRegions: []string{down.URL, up.URL},
+		HTTPTimeout:  5 * time.Second,
+	})
+	if err := service.Warm(context.Background()); err == nil {
+		t.Error("Expected an error warming an unreachable region")
+	}
+}
+
+func TestService_Search_Failover(t *testing.T) {
+	failing := newRegionServer(t, 0, http.StatusServiceUnavailable)
+	working := newRegionServer(t, 0, http.StatusOK)
+
+	service := NewWithConfig(&config.Config{
+		BochaAPIKey:  "test-api-key",
+		BochaRegions: []string{failing.URL, working.URL},
+		BochaProURL:  "https://api.bochaai.com/v1/web-search-pro",
+		HTTPTimeout:  5 * time.Second,
+	})
+	for i := 0; i < 2; i++ {
+		if _, err := service.Search(context.Background(), "golang", "noLimit", 10, false); err != nil {
+			t.Fatalf("Search returned an error: %v", err)
+		}
+	}
+	if len(failing.Searches()) != 1 || len(working.Searches()) != 2 {
+		t.Errorf("Expected the failing region to be tried once, got %d and %d searches", len(failing.Searches()), len(working.Searches()))
+	}
+	if regions := service.Regions(); regions[0].Endpoint != working.URL {
+		t.Errorf("Expected the working region first after a failover, got %+v", regions)
+	}
+
+	if _, err := service.Search(search.WithPro(context.Background()), "golang", "noLimit", 10, false); err != nil {
+		t.Fatalf("Search returned an error: %v", err)
+	}
+	if searches := working.Searches(); searches[len(searches)-1] != "/web-search-pro" {
+		t.Errorf("Expected the pro path below the region, got %v", searches)
+	}
+}
+
+func TestService_Search_ClientErrorsDoNotFailOver(t *testing.T) {
+	unauthorized := newRegionServer(t, 0, http.StatusUnauthorized)
+	other := newRegionServer(t, 0, http.StatusOK)
+
+	service := NewWithConfig(&config.Config{
+		BochaAPIKey:  "test-api-key",
+		BochaRegions: []string{unauthorized.URL, other.URL},
+		HTTPTimeout:  5 * time.Second,
+	})
+	if _, err := service.Search(context.Background(), "golang", "noLimit", 10, false); err == nil {
+		t.Error("Expected the client error to be returned, got nil")
+	}
+	if len(other.Searches()) != 0 {
+		t.Error("Expected no failover for a client error")
+	}
+}
@@ -0,0 +1,229 @@
+// Package brave implements the search provider for the Brave Search API
+package brave
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"com.moguyn/mcp-go-search/config"
+	"com.moguyn/mcp-go-search/params"
+	"com.moguyn/mcp-go-search/search"
+)
+
+// MaxCount is the largest number of results Brave returns for one search
+const MaxCount = 20
+
+// freshnessParams maps the server's freshness values to Brave's
+var freshnessParams = map[string]string{
+	"day":     "pd",
+	"week":    "pw",
+	"month":   "pm",
+	"oneYear": "py",
+}
+
+func init() {
+	search.Register(config.ProviderBrave, func(cfg *config.Config) (search.Provider, error) {
+		return NewWithConfig(cfg), nil
+	})
+}
+
+// Response is the part of a Brave web search response the provider uses
+type Response struct {
+	Query struct {
+		Original string `json:"original"`
+		Altered  string `json:"altered"`
+	} `json:"query"`
+	Web struct {
+		Results []Result `json:"results"`
+	} `json:"web"`
+}
+
+// Result is a single Brave web result
+type Result struct {
+	Title          string `json:"title"`
+	URL            string `json:"url"`
+	Description    string `json:"description"`
+	PageAge        string `json:"page_age"`
+	Language       string `json:"language"`
+	FamilyFriendly *bool  `json:"family_friendly"`
+	Profile        struct {
+		Name string `json:"name"`
+	} `json:"profile"`
+	MetaURL struct {
+		Hostname string `json:"hostname"`
+		Path     string `json:"path"`
+		Favicon  string `json:"favicon"`
+	} `json:"meta_url"`
+}
+
+// Service implements the search.Provider interface for the Brave Search API
+type Service struct {
+	*search.RotatableKey
+	apiBaseURL  string
+	httpClient  *http.Client
+	rateLimiter *rate.Limiter
+}
+
+// NewWithConfig creates a new Brave provider with the provided configuration
+func NewWithConfig(cfg *config.Config) *Service {
+	return &Service{
+		RotatableKey: search.NewRotatableKey(cfg.BraveAPIKey),
+		apiBaseURL:   cfg.BraveAPIBaseURL,
+		httpClient:   search.NewHTTPClient(cfg),
+		// The free plan allows one request per second
+		rateLimiter: rate.NewLimiter(rate.Limit(1), 1),
+	}
+}
+
+// Warm connects to the Brave Search API ahead of the first search
+func (s *Service) Warm(ctx context.Context) error {
+	return search.WarmURL(ctx, s.httpClient, s.apiBaseURL)
+}
+
+// Name returns the provider name used in configuration
+func (s *Service) Name() string {
+	return config.ProviderBrave
+}
+
+// Capabilities describes what the Brave Search API supports
+func (s *Service) Capabilities() search.Capabilities {
+	return search.Capabilities{
+		Provider:   config.ProviderBrave,
+		Freshness:  params.Freshness,
+		MaxCount:   MaxCount,
+		Operators:  []string{search.OperatorSite, search.OperatorPhrase, search.OperatorExclude},
+		ExactQuery: true,
+	}
+}
+
+// Search performs a search using the Brave Search API
+func (s *Service) Search(ctx context.Context, query string, freshness string, count int, summary bool) (*search.WebSearchResponse, error) {
+	if err := search.WaitRateLimit(ctx, s.rateLimiter); err != nil {
+		return nil, fmt.Errorf("rate limit exceeded: %w", err)
+	}
+
+	// Validate inputs and bring them within the API's limits
+	p, adj, err := params.Normalize(params.Search{
+		Query:     query,
+		Freshness: freshness,
+		Count:     count,
+		Summary:   summary,
+	}, s.Capabilities().Limits())
+	if err != nil {
+		return nil, err
+	}
+
+	values := url.Values{}
+	values.Set("q", p.Query)
+	values.Set("count", strconv.Itoa(p.Count))
+	if f, ok := freshnessParams[p.Freshness]; ok {
+		values.Set("freshness", f)
+	}
+	if search.ExactQuery(ctx) {
+		values.Set("spellcheck", "false")
+	}
+	requestURL := s.apiBaseURL + "?" + values.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTTP request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("X-Subscription-Token", s.APIKey())
+	req.Header.Set("User-Agent", "BochaWebSearchMCPServer/1.0")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request to Brave API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 10*1024*1024)) // 10MB limit
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Brave API response body: %w", err)
+	}
+
+	searchResp, err := parseResponse(resp.StatusCode, body)
+	if err != nil {
+		return nil, search.WithTransfer(err, int64(len(requestURL)), int64(len(body)))
+	}
+	if p.Summary {
+		searchResp.Data.WebPages.WebSearchURL = "https://search.brave.com/search?q=" + url.QueryEscape(p.Query)
+	}
+	searchResp.Meta = search.ResponseMeta{
+		QueryTruncated: adj.QueryTruncated,
+		CountClamped:   adj.CountClamped,
+		BytesSent:      int64(len(requestURL)),
+		BytesReceived:  int64(len(body)),
+	}
+	return searchResp, nil
+}
+
+// parseResponse decodes a Brave API response body into the common response
+// format, turning non-200 statuses into errors
+func parseResponse(statusCode int, body []byte) (*search.WebSearchResponse, error) {
+	if statusCode != http.StatusOK {
+		var errorResp struct {
+			Error struct {
+				Detail string `json:"detail"`
+			} `json:"error"`
+		}
+		if err := json.Unmarshal(body, &errorResp); err == nil && errorResp.Error.Detail != "" {
+			return nil, fmt.Errorf("brave api error (status %d): %s", statusCode, errorResp.Error.Detail)
+		}
+		return nil, fmt.Errorf("brave api returned status code %d", statusCode)
+	}
+
+	var braveResp Response
+	if err := json.Unmarshal(body, &braveResp); err != nil {
+		return nil, fmt.Errorf("failed to parse brave api response: %w", err)
+	}
+
+	results := make([]search.WebPageResult, 0, len(braveResp.Web.Results))
+	for _, result := range braveResp.Web.Results {
+		results = append(results, toWebPageResult(result))
+	}
+	searchResp := &search.WebSearchResponse{Code: http.StatusOK}
+	searchResp.Data.QueryContext = search.QueryContext{
+		OriginalQuery: braveResp.Query.Original,
+		AlteredQuery:  braveResp.Query.Altered,
+	}
+	searchResp.Data.WebPages.Value = results
+	return searchResp, nil
+}
+
+// toWebPageResult maps a Brave result onto the common result format
+func toWebPageResult(result Result) search.WebPageResult {
+	converted := search.WebPageResult{
+		Name:       result.Title,
+		URL:        result.URL,
+		DisplayURL: result.MetaURL.Hostname + result.MetaURL.Path,
+		Snippet:    result.Description,
+		SiteName:   result.Profile.Name,
+		SiteIcon:   result.MetaURL.Favicon,
+	}
+	if converted.DisplayURL == "" {
+		converted.DisplayURL = result.URL
+	}
+	if result.Language != "" {
+		converted.Language = result.Language
+	}
+	if result.FamilyFriendly != nil {
+		converted.IsFamilyFriendly = *result.FamilyFriendly
+	}
+	// Brave leaves the zone off page ages, which are in UTC
+	if age, err := time.Parse("2006-01-02T15:04:05", result.PageAge); err == nil {
+		converted.DateLastCrawled = age.UTC().Format(time.RFC3339)
+	} else {
+		converted.DateLastCrawled = result.PageAge
+	}
+	return converted
+}
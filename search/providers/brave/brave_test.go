@@ -0,0 +1,135 @@
+package brave
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"com.moguyn/mcp-go-search/config"
+	"com.moguyn/mcp-go-search/search"
+)
+
+const braveResponse = `{
+	"type": "search",
+	"query": {"original": "golang generics", "altered": "go generics"},
+	"web": {
+		"type": "search",
+		"results": [
+			{
+				"title": "Tutorial: Getting started with generics",
+				"url": "https://go.dev/doc/tutorial/generics",
+				"description": "This tutorial introduces the basics of <strong>generics</strong> in Go.",
+				"page_age": "2024-03-01T10:20:30",
+				"language": "en",
+				"family_friendly": true,
+				"profile": {"name": "Go"},
+				"meta_url": {"hostname": "go.dev", "path": "/doc/tutorial/generics", "favicon": "https://imgs.search.brave.com/go.png"}
+			},
+			{
+				"title": "An Introduction To Generics",
+				"url": "https://go.dev/blog/intro-generics",
+				"description": "The Go 1.18 release adds support for generics."
+			}
+		]
+	}
+}`
+
+func TestService_Search(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			t.Errorf("Expected GET request, got %s", r.Method)
+		}
+		if token := r.Header.Get("X-Subscription-Token"); token != "test-brave-key" {
+			t.Errorf("Expected the subscription token header, got %q", token)
+		}
+		query := r.URL.Query()
+		if query.Get("q") != "golang generics" || query.Get("count") != "20" || query.Get("freshness") != "pw" {
+			t.Errorf("Unexpected query parameters: %s", r.URL.RawQuery)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(braveResponse))
+	}))
+	defer server.Close()
+
+	service := NewWithConfig(&config.Config{
+		BraveAPIKey:     "test-brave-key",
+		BraveAPIBaseURL: server.URL,
+		HTTPTimeout:     5 * time.Second,
+	})
+	response, err := service.Search(context.Background(), "golang generics", "week", 50, true)
+	if err != nil {
+		t.Fatalf("Search returned an error: %v", err)
+	}
+
+	if !response.Meta.CountClamped {
+		t.Error("Expected the count to be clamped to Brave's maximum")
+	}
+	if response.Data.QueryContext.AlteredQuery != "go generics" {
+		t.Errorf("Expected altered query 'go generics', got %q", response.Data.QueryContext.AlteredQuery)
+	}
+	if !strings.HasPrefix(response.Data.WebPages.WebSearchURL, "https://search.brave.com/search?q=") {
+		t.Errorf("Expected a Brave search URL, got %q", response.Data.WebPages.WebSearchURL)
+	}
+	results := response.Data.WebPages.Value
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 results, got %d", len(results))
+	}
+	first := results[0]
+	if first.Name != "Tutorial: Getting started with generics" || first.URL != "https://go.dev/doc/tutorial/generics" {
+		t.Errorf("Unexpected first result: %+v", first)
+	}
+	if first.SiteName != "Go" || first.DisplayURL != "go.dev/doc/tutorial/generics" {
+		t.Errorf("Expected site name and display URL from the profile and meta URL, got %+v", first)
+	}
+	if first.DateLastCrawled != "2024-03-01T10:20:30Z" {
+		t.Errorf("Expected the page age in RFC 3339, got %q", first.DateLastCrawled)
+	}
+	if results[1].DisplayURL != "https://go.dev/blog/intro-generics" {
+		t.Errorf("Expected the URL as display URL without meta URL, got %q", results[1].DisplayURL)
+	}
+}
+
+func TestService_Search_Errors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"type": "ErrorResponse", "error": {"status": 401, "detail": "The provided subscription token is invalid."}}`))
+	}))
+	defer server.Close()
+
+	service := NewWithConfig(&config.Config{
+		BraveAPIKey:     "bad-key",
+		BraveAPIBaseURL: server.URL,
+		HTTPTimeout:     5 * time.Second,
+	})
+	_, err := service.Search(context.Background(), "golang", "", 10, false)
+	if err == nil || err.Error() != "brave api error (status 401): The provided subscription token is invalid." {
+		t.Errorf("Expected the Brave error detail, got %v", err)
+	}
+
+	if _, err := service.Search(context.Background(), "", "", 10, false); err == nil {
+		t.Error("Expected error for empty query, got nil")
+	}
+}
+
+func TestParseResponse_NoResults(t *testing.T) {
+	response, err := parseResponse(http.StatusOK, []byte(`{"query": {"original": "zzzz"}}`))
+	if err != nil {
+		t.Fatalf("parseResponse returned an error: %v", err)
+	}
+	if response.Data.WebPages.Value == nil || len(response.Data.WebPages.Value) != 0 {
+		t.Errorf("Expected an empty result list, got %v", response.Data.WebPages.Value)
+	}
+}
+
+func TestRegistered(t *testing.T) {
+	provider, err := search.NewProvider(&config.Config{SearchProvider: config.ProviderBrave, BraveAPIKey: "test-brave-key"})
+	if err != nil {
+		t.Fatalf("NewProvider returned an error: %v", err)
+	}
+	if provider.Name() != config.ProviderBrave || provider.Capabilities().MaxCount != MaxCount {
+		t.Errorf("Expected the Brave provider, got %s", provider.Name())
+	}
+}
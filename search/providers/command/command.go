@@ -0,0 +1,211 @@
+// Package command implements a search provider that runs a configured search
+// command once per search and maps the JSON it prints onto results, so an
+// existing search CLI can be used without writing a plugin
+package command
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"com.moguyn/mcp-go-search/config"
+	"com.moguyn/mcp-go-search/jsonpath"
+	"com.moguyn/mcp-go-search/params"
+	"com.moguyn/mcp-go-search/search"
+)
+
+// maxStderr bounds the error output kept for error messages
+const maxStderr = 4 * 1024
+
+// baseEnv is the environment every command gets, on top of the configured
+// variables
+var baseEnv = []string{"PATH", "HOME", "LANG", "TMPDIR"}
+
+// errOutputTooLarge stops a command writing more output than allowed
+var errOutputTooLarge = errors.New("output limit exceeded")
+
+func init() {
+	search.Register(config.ProviderCommand, func(cfg *config.Config) (search.Provider, error) {
+		service, err := NewWithConfig(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return service, nil
+	})
+}
+
+// Service implements the search.Provider interface by running a command
+type Service struct {
+	path      string
+	args      []string
+	env       []string
+	dir       string
+	timeout   time.Duration
+	maxOutput int
+	paths     map[string]jsonpath.Path
+	slots     chan struct{}
+}
+
+// NewWithConfig creates a new command provider with the provided configuration
+func NewWithConfig(cfg *config.Config) (*Service, error) {
+	paths, err := cfg.CommandMapping.Paths()
+	if err != nil {
+		return nil, err
+	}
+	var env []string
+	for _, name := range append(append([]string{}, baseEnv...), cfg.CommandEnv...) {
+		if value, ok := os.LookupEnv(name); ok {
+			env = append(env, name+"="+value)
+		}
+	}
+	return &Service{
+		path:      cfg.CommandPath,
+		args:      cfg.CommandArgs,
+		env:       env,
+		dir:       cfg.CommandDir,
+		timeout:   cfg.CommandTimeout,
+		maxOutput: cfg.CommandMaxOutput,
+		paths:     paths,
+		slots:     make(chan struct{}, max(cfg.CommandMaxProcs, 1)),
+	}, nil
+}
+
+// Name returns the provider name used in configuration
+func (s *Service) Name() string {
+	return config.ProviderCommand
+}
+
+// Capabilities describes what the command is assumed to support. The query
+// is passed as written, so operators are left to the command.
+func (s *Service) Capabilities() search.Capabilities {
+	return search.Capabilities{
+		Provider:  config.ProviderCommand,
+		Freshness: params.Freshness,
+		MaxCount:  params.MaxCount,
+		Operators: []string{search.OperatorSite, search.OperatorPhrase, search.OperatorExclude, search.OperatorOr},
+	}
+}
+
+// Search runs the command for the search and maps its output
+func (s *Service) Search(ctx context.Context, query string, freshness string, count int, summary bool) (*search.WebSearchResponse, error) {
+	// Validate inputs and bring them within the command's limits
+	p, adj, err := params.Normalize(params.Search{
+		Query:     query,
+		Freshness: freshness,
+		Count:     count,
+		Summary:   summary,
+	}, s.Capabilities().Limits())
+	if err != nil {
+		return nil, err
+	}
+
+	select {
+	case s.slots <- struct{}{}:
+		defer func() { <-s.slots }()
+	case <-ctx.Done():
+		return nil, fmt.Errorf("command search canceled: %w", ctx.Err())
+	}
+
+	args, stdin := s.expand(p)
+	out, err := s.run(ctx, args, stdin)
+	if err != nil {
+		return nil, err
+	}
+
+	searchResp, err := search.ParseMapped(out, s.paths)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse command output: %w", err)
+	}
+	searchResp.Data.QueryContext.OriginalQuery = p.Query
+	searchResp.Meta = search.ResponseMeta{
+		QueryTruncated: adj.QueryTruncated,
+		CountClamped:   adj.CountClamped,
+		BytesSent:      int64(len(stdin)),
+		BytesReceived:  int64(len(out)),
+	}
+	return searchResp, nil
+}
+
+// expand substitutes the search into the arguments. Without a {query}
+// argument the query is written to the command's stdin instead.
+func (s *Service) expand(p params.Search) ([]string, string) {
+	replacer := strings.NewReplacer(
+		"{query}", p.Query,
+		"{count}", strconv.Itoa(p.Count),
+		"{freshness}", p.Freshness,
+	)
+	args := make([]string, len(s.args))
+	inArgs := false
+	for i, arg := range s.args {
+		inArgs = inArgs || strings.Contains(arg, "{query}")
+		args[i] = replacer.Replace(arg)
+	}
+	if inArgs {
+		return args, ""
+	}
+	return args, p.Query + "\n"
+}
+
+// run runs the command and returns its output. The command is killed when it
+// times out, when ctx is done or when it writes more than the output limit.
+func (s *Service) run(ctx context.Context, args []string, stdin string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, s.path, args...)
+	cmd.Env = s.env
+	cmd.Dir = s.dir
+	cmd.Stdin = strings.NewReader(stdin)
+	stdout := &limitedBuffer{limit: s.maxOutput, onExceed: cancel}
+	stderr := &limitedBuffer{limit: maxStderr}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	// Don't wait on children that keep the output open after a kill
+	cmd.WaitDelay = time.Second
+
+	err := cmd.Run()
+	switch {
+	case stdout.exceeded:
+		return nil, fmt.Errorf("command output exceeds %d bytes", s.maxOutput)
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return nil, fmt.Errorf("command timed out after %s", s.timeout)
+	case ctx.Err() != nil:
+		return nil, fmt.Errorf("command search canceled: %w", ctx.Err())
+	case err != nil:
+		if message := strings.TrimSpace(stderr.buf.String()); message != "" {
+			return nil, fmt.Errorf("command failed: %w: %s", err, message)
+		}
+		return nil, fmt.Errorf("command failed: %w", err)
+	}
+	return stdout.buf.Bytes(), nil
+}
+
+// limitedBuffer keeps up to limit bytes of output. Beyond the limit it calls
+// onExceed when set, failing the write, and otherwise drops the rest. The
+// buffer isn't embedded, so copies can't bypass Write through ReadFrom.
+type limitedBuffer struct {
+	buf      bytes.Buffer
+	limit    int
+	onExceed func()
+	exceeded bool
+}
+
+// Write implements io.Writer
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if room := b.limit - b.buf.Len(); len(p) > room {
+		if b.onExceed != nil {
+			b.exceeded = true
+			b.onExceed()
+			return 0, errOutputTooLarge
+		}
+		b.buf.Write(p[:max(room, 0)])
+		return len(p), nil
+	}
+	return b.buf.Write(p)
+}
@@ -0,0 +1,163 @@
+package command
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"com.moguyn/mcp-go-search/config"
+	"com.moguyn/mcp-go-search/search"
+)
+
+// shellConfig returns a configuration running script with sh, with args
+// passed on as the script's positional parameters
+func shellConfig(script string, args ...string) *config.Config {
+	return &config.Config{
+		CommandPath:      "/bin/sh",
+		CommandArgs:      append([]string{"-c", script, "sh"}, args...),
+		CommandTimeout:   5 * time.Second,
+		CommandMaxOutput: 1024 * 1024,
+		CommandMaxProcs:  2,
+		CommandMapping:   config.DefaultGenericMapping,
+	}
+}
+
+func newService(t *testing.T, cfg *config.Config) *Service {
+	t.Helper()
+	if _, err := os.Stat(cfg.CommandPath); err != nil {
+		t.Skipf("%s is not available: %v", cfg.CommandPath, err)
+	}
+	service, err := NewWithConfig(cfg)
+	if err != nil {
+		t.Fatalf("NewWithConfig returned an error: %v", err)
+	}
+	return service
+}
+
+func TestService_Search_Args(t *testing.T) {
+	cfg := shellConfig(
+		`printf '{"results": [{"title": "%s", "url": "https://example.com/%s", "snippet": "%s"}], "total": 7}' "$1" "$2" "$3"`,
+		"{query}", "{count}", "{freshness}",
+	)
+	cfg.CommandMapping.Total = "total"
+	service := newService(t, cfg)
+
+	response, err := service.Search(context.Background(), "golang", "week", 3, false)
+	if err != nil {
+		t.Fatalf("Search returned an error: %v", err)
+	}
+	results := response.Data.WebPages.Value
+	if len(results) != 1 {
+		t.Fatalf("Expected 1 result, got %d", len(results))
+	}
+	if results[0].Name != "golang" || results[0].URL != "https://example.com/3" || results[0].Snippet != "week" {
+		t.Errorf("Expected the search substituted into the arguments, got %+v", results[0])
+	}
+	if response.Data.WebPages.TotalEstimatedMatches != 7 {
+		t.Errorf("Expected 7 total matches, got %d", response.Data.WebPages.TotalEstimatedMatches)
+	}
+	if response.Data.QueryContext.OriginalQuery != "golang" {
+		t.Errorf("Expected original query golang, got %q", response.Data.QueryContext.OriginalQuery)
+	}
+	if response.Meta.BytesSent != 0 || response.Meta.BytesReceived == 0 {
+		t.Errorf("Unexpected meta: %+v", response.Meta)
+	}
+}
+
+func TestService_Search_Stdin(t *testing.T) {
+	service := newService(t, shellConfig(
+		`read -r q; printf '{"results": [{"title": "%s", "url": "https://example.com/"}]}' "$q"`,
+	))
+
+	response, err := service.Search(context.Background(), "read from stdin", "", 5, false)
+	if err != nil {
+		t.Fatalf("Search returned an error: %v", err)
+	}
+	if results := response.Data.WebPages.Value; len(results) != 1 || results[0].Name != "read from stdin" {
+		t.Errorf("Expected the query read from stdin as title, got %+v", results)
+	}
+	if response.Meta.BytesSent != int64(len("read from stdin\n")) {
+		t.Errorf("Expected the query and newline sent, got %d bytes", response.Meta.BytesSent)
+	}
+}
+
+func TestService_Search_Environment(t *testing.T) {
+	t.Setenv("COMMAND_TEST_SHARED", "shared")
+	t.Setenv("COMMAND_TEST_SECRET", "secret")
+	cfg := shellConfig(`printf '{"results": [{"title": "%s|%s", "url": "https://example.com/"}]}' "$COMMAND_TEST_SHARED" "$COMMAND_TEST_SECRET"`)
+	cfg.CommandEnv = []string{"COMMAND_TEST_SHARED"}
+	service := newService(t, cfg)
+
+	response, err := service.Search(context.Background(), "env", "", 5, false)
+	if err != nil {
+		t.Fatalf("Search returned an error: %v", err)
+	}
+	if results := response.Data.WebPages.Value; len(results) != 1 || results[0].Name != "shared|" {
+		t.Errorf("Expected only the listed variable passed on, got %+v", results)
+	}
+}
+
+func TestService_Search_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		script   string
+		setup    func(cfg *config.Config)
+		expected string
+	}{
+		{"exit status", `echo "index unavailable" >&2; exit 3`, nil, "command failed: exit status 3: index unavailable"},
+		{"timeout", `sleep 5`, func(cfg *config.Config) { cfg.CommandTimeout = 100 * time.Millisecond }, "command timed out after 100ms"},
+		{"output limit", `head -c 4096 /dev/zero; sleep 5`, func(cfg *config.Config) { cfg.CommandMaxOutput = 1024 }, "command output exceeds 1024 bytes"},
+		{"not json", `echo not json`, nil, "failed to parse command output"},
+		{"not a list", `echo '{"results": 1}'`, nil, "failed to parse command output: no result list at results"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := shellConfig(tt.script)
+			if tt.setup != nil {
+				tt.setup(cfg)
+			}
+			service := newService(t, cfg)
+			start := time.Now()
+			_, err := service.Search(context.Background(), "query", "", 5, false)
+			if err == nil || !strings.HasPrefix(err.Error(), tt.expected) {
+				t.Errorf("Expected %q, got %v", tt.expected, err)
+			}
+			if elapsed := time.Since(start); elapsed > 3*time.Second {
+				t.Errorf("Expected the command to be stopped, took %s", elapsed)
+			}
+		})
+	}
+}
+
+func TestService_Search_MaxProcs(t *testing.T) {
+	cfg := shellConfig(`echo '{"results": []}'`)
+	cfg.CommandMaxProcs = 1
+	service := newService(t, cfg)
+
+	// Hold the only slot so the search has to wait for it
+	service.slots <- struct{}{}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := service.Search(ctx, "query", "", 5, false); err == nil || !strings.Contains(err.Error(), "canceled") {
+		t.Errorf("Expected the search to be canceled while waiting, got %v", err)
+	}
+	<-service.slots
+
+	if _, err := service.Search(context.Background(), "query", "", 5, false); err != nil {
+		t.Errorf("Expected the search to run once the slot is free, got %v", err)
+	}
+}
+
+func TestRegistered(t *testing.T) {
+	cfg := shellConfig(`true`)
+	cfg.SearchProvider = config.ProviderCommand
+	provider, err := search.NewProvider(cfg)
+	if err != nil {
+		t.Fatalf("NewProvider returned an error: %v", err)
+	}
+	if provider.Name() != config.ProviderCommand {
+		t.Errorf("Expected the command provider, got %s", provider.Name())
+	}
+}
@@ -0,0 +1,197 @@
+// Package generic implements a search provider for self-hosted and otherwise
+// unsupported JSON search APIs, whose responses are mapped onto results by
+// configured paths
+package generic
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"text/template"
+
+	"com.moguyn/mcp-go-search/config"
+	"com.moguyn/mcp-go-search/jsonpath"
+	"com.moguyn/mcp-go-search/params"
+	"com.moguyn/mcp-go-search/search"
+)
+
+func init() {
+	search.Register(config.ProviderGeneric, func(cfg *config.Config) (search.Provider, error) {
+		service, err := NewWithConfig(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return service, nil
+	})
+}
+
+// Service implements the search.Provider interface for a generic JSON search API
+type Service struct {
+	*search.RotatableKey
+	endpoint   config.GenericEndpoint
+	template   *template.Template
+	paths      map[string]jsonpath.Path
+	httpClient *http.Client
+}
+
+// NewWithConfig creates a new generic provider with the provided configuration
+func NewWithConfig(cfg *config.Config) (*Service, error) {
+	endpoint, err := cfg.GenericEndpoint()
+	if err != nil {
+		return nil, err
+	}
+	paths, err := endpoint.Mapping.Paths()
+	if err != nil {
+		return nil, err
+	}
+	tmpl, err := cfg.RequestTemplate(config.ProviderGeneric)
+	if err != nil {
+		return nil, err
+	}
+	return &Service{
+		RotatableKey: search.NewRotatableKey(cfg.GenericAPIKey),
+		endpoint:     endpoint,
+		template:     tmpl,
+		paths:        paths,
+		httpClient:   search.NewHTTPClient(cfg),
+	}, nil
+}
+
+// Name returns the provider name used in configuration
+func (s *Service) Name() string {
+	return config.ProviderGeneric
+}
+
+// Capabilities describes what the API is assumed to support. The query is
+// sent as written, so operators are left to the API. Freshness can only be
+// passed on by a request template.
+func (s *Service) Capabilities() search.Capabilities {
+	caps := search.Capabilities{
+		Provider:  config.ProviderGeneric,
+		Freshness: []string{params.DefaultFreshness},
+		MaxCount:  params.MaxCount,
+		Operators: []string{search.OperatorSite, search.OperatorPhrase, search.OperatorExclude, search.OperatorOr},
+	}
+	if s.template != nil {
+		caps.Freshness = params.Freshness
+	}
+	return caps
+}
+
+// Search performs a search using the configured API
+func (s *Service) Search(ctx context.Context, query string, freshness string, count int, summary bool) (*search.WebSearchResponse, error) {
+	// Validate inputs and bring them within the API's limits
+	p, adj, err := params.Normalize(params.Search{
+		Query:     query,
+		Freshness: freshness,
+		Count:     count,
+		Summary:   summary,
+	}, s.Capabilities().Limits())
+	if err != nil {
+		return nil, err
+	}
+
+	req, sent, err := s.newRequest(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "BochaWebSearchMCPServer/1.0")
+	if apiKey := s.APIKey(); apiKey != "" && s.endpoint.AuthParam == "" {
+		if http.CanonicalHeaderKey(s.endpoint.AuthHeader) == "Authorization" {
+			req.Header.Set("Authorization", "Bearer "+apiKey)
+		} else {
+			req.Header.Set(s.endpoint.AuthHeader, apiKey)
+		}
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request to generic API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 10*1024*1024)) // 10MB limit
+	if err != nil {
+		return nil, fmt.Errorf("failed to read generic API response body: %w", err)
+	}
+
+	searchResp, err := s.parseResponse(resp.StatusCode, body)
+	if err != nil {
+		return nil, search.WithTransfer(err, sent, int64(len(body)))
+	}
+	searchResp.Data.QueryContext.OriginalQuery = p.Query
+	searchResp.Meta = search.ResponseMeta{
+		QueryTruncated: adj.QueryTruncated,
+		CountClamped:   adj.CountClamped,
+		BytesSent:      sent,
+		BytesReceived:  int64(len(body)),
+	}
+	return searchResp, nil
+}
+
+// newRequest returns the search request and the number of bytes it sends. A
+// GET carries the query and count as parameters; a POST carries the rendered
+// template, or without one a JSON object of the query and count.
+func (s *Service) newRequest(ctx context.Context, p params.Search) (*http.Request, int64, error) {
+	u, err := url.Parse(s.endpoint.URL)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to parse generic API URL: %w", err)
+	}
+	values := u.Query()
+	if apiKey := s.APIKey(); apiKey != "" && s.endpoint.AuthParam != "" {
+		values.Set(s.endpoint.AuthParam, apiKey)
+	}
+
+	if s.endpoint.Method == http.MethodGet {
+		values.Set(s.endpoint.QueryParam, p.Query)
+		if s.endpoint.CountParam != "" {
+			values.Set(s.endpoint.CountParam, strconv.Itoa(p.Count))
+		}
+		u.RawQuery = values.Encode()
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to create HTTP request: %w", err)
+		}
+		return req, int64(len(u.String())), nil
+	}
+
+	var reqBody []byte
+	if s.template != nil {
+		reqBody, err = config.RenderRequestBody(s.template, config.RequestTemplateData{Search: p})
+	} else {
+		body := map[string]any{s.endpoint.QueryParam: p.Query}
+		if s.endpoint.CountParam != "" {
+			body[s.endpoint.CountParam] = p.Count
+		}
+		reqBody, err = json.Marshal(body)
+	}
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to marshal request body: %w", err)
+	}
+	u.RawQuery = values.Encode()
+	req, err := http.NewRequestWithContext(ctx, s.endpoint.Method, u.String(), bytes.NewBuffer(reqBody))
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to create HTTP request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return req, int64(len(reqBody)), nil
+}
+
+// parseResponse maps a response body onto the common response format with
+// the configured paths
+func (s *Service) parseResponse(statusCode int, body []byte) (*search.WebSearchResponse, error) {
+	if statusCode != http.StatusOK {
+		return nil, fmt.Errorf("generic api returned status code %d", statusCode)
+	}
+	searchResp, err := search.ParseMapped(body, s.paths)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse generic api response: %w", err)
+	}
+	return searchResp, nil
+}
@@ -0,0 +1,210 @@
+package generic
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"com.moguyn/mcp-go-search/config"
+	"com.moguyn/mcp-go-search/search"
+)
+
+const hitsResponse = `{
+	"took": 3,
+	"hits": {
+		"total": {"value": 42},
+		"hits": [
+			{"_source": {"headline": "Go generics", "link": "https://go.dev/doc/tutorial/generics", "body": "Type parameters in Go.", "published": "2025-03-12T09:30:00Z"}},
+			{"_source": {"headline": "No link"}},
+			{"_source": {"link": "https://example.com/untitled"}}
+		]
+	}
+}`
+
+// hitsMapping maps responses shaped like hitsResponse
+var hitsMapping = config.GenericMapping{
+	Results: "$.hits.hits",
+	Title:   "_source.headline",
+	URL:     "_source.link",
+	Snippet: "_source.body",
+	Date:    "_source.published",
+	Total:   "hits.total.value",
+}
+
+func TestService_Search(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			t.Errorf("Expected GET request, got %s", r.Method)
+		}
+		if q, size, index := r.URL.Query().Get("query"), r.URL.Query().Get("size"), r.URL.Query().Get("index"); q != "go generics" || size != "5" || index != "docs" {
+			t.Errorf("Unexpected parameters: %s", r.URL.RawQuery)
+		}
+		if key := r.Header.Get("X-Api-Key"); key != "test-generic-key" {
+			t.Errorf("Expected the key in X-Api-Key, got %q", key)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(hitsResponse))
+	}))
+	defer server.Close()
+
+	service, err := NewWithConfig(&config.Config{
+		GenericAPIURL:     server.URL + "/search?index=docs",
+		GenericAPIKey:     "test-generic-key",
+		GenericAuthHeader: "X-Api-Key",
+		GenericQueryParam: "query",
+		GenericCountParam: "size",
+		GenericMapping:    hitsMapping,
+		HTTPTimeout:       5 * time.Second,
+	})
+	if err != nil {
+		t.Fatalf("NewWithConfig returned an error: %v", err)
+	}
+	response, err := service.Search(context.Background(), "go generics", "", 5, false)
+	if err != nil {
+		t.Fatalf("Search returned an error: %v", err)
+	}
+
+	results := response.Data.WebPages.Value
+	if len(results) != 2 {
+		t.Fatalf("Expected the 2 results with a URL, got %d", len(results))
+	}
+	first := results[0]
+	if first.Name != "Go generics" || first.URL != "https://go.dev/doc/tutorial/generics" || first.Snippet != "Type parameters in Go." || first.DateLastCrawled != "2025-03-12T09:30:00Z" {
+		t.Errorf("Unexpected first result: %+v", first)
+	}
+	if results[1].Name != "https://example.com/untitled" {
+		t.Errorf("Expected the URL as name without a title, got %q", results[1].Name)
+	}
+	if response.Data.WebPages.TotalEstimatedMatches != 42 || response.Meta.BytesReceived != int64(len(hitsResponse)) {
+		t.Errorf("Unexpected total or meta: %d, %+v", response.Data.WebPages.TotalEstimatedMatches, response.Meta)
+	}
+}
+
+func TestService_Search_Template(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("Expected POST request, got %s", r.Method)
+		}
+		if auth := r.Header.Get("Authorization"); auth != "Bearer test-generic-key" {
+			t.Errorf("Expected the bearer token, got %q", auth)
+		}
+		var body map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Fatalf("Failed to decode request body: %v", err)
+		}
+		if body["q"] != "golang" || body["recency"] != "week" {
+			t.Errorf("Unexpected request body: %v", body)
+		}
+		_, _ = w.Write([]byte(`{"results": [{"title": "Go", "url": "https://go.dev/", "snippet": "The Go language"}]}`))
+	}))
+	defer server.Close()
+
+	service, err := NewWithConfig(&config.Config{
+		GenericAPIURL:     server.URL,
+		GenericAPIKey:     "test-generic-key",
+		GenericAuthHeader: "Authorization",
+		GenericMapping:    config.DefaultGenericMapping,
+		RequestTemplates:  map[string]string{config.ProviderGeneric: `{"q": {{json .Query}}, "recency": {{json .Freshness}}}`},
+		HTTPTimeout:       5 * time.Second,
+	})
+	if err != nil {
+		t.Fatalf("NewWithConfig returned an error: %v", err)
+	}
+	response, err := service.Search(context.Background(), "golang", "week", 10, false)
+	if err != nil {
+		t.Fatalf("Search returned an error: %v", err)
+	}
+	if results := response.Data.WebPages.Value; len(results) != 1 || results[0].Snippet != "The Go language" {
+		t.Errorf("Unexpected results: %+v", results)
+	}
+}
+
+func TestService_Search_PostWithKeyParam(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Query().Get("api_key") != "test-generic-key" {
+			t.Errorf("Expected a POST with the key as parameter, got %s %s", r.Method, r.URL)
+		}
+		if auth := r.Header.Get("Authorization"); auth != "" {
+			t.Errorf("Expected no Authorization header, got %q", auth)
+		}
+		var body map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Fatalf("Failed to decode request body: %v", err)
+		}
+		if body["question"] != "golang" || body["top_k"] != float64(3) {
+			t.Errorf("Unexpected request body: %v", body)
+		}
+		_, _ = w.Write([]byte(`[{"name": "Go", "href": "https://go.dev/"}]`))
+	}))
+	defer server.Close()
+
+	service, err := NewWithConfig(&config.Config{
+		GenericAPIURL:     server.URL,
+		GenericMethod:     http.MethodPost,
+		GenericAPIKey:     "test-generic-key",
+		GenericAuthParam:  "api_key",
+		GenericQueryParam: "question",
+		GenericCountParam: "top_k",
+		GenericMapping:    config.GenericMapping{Results: "$", Title: "name", URL: "href"},
+		HTTPTimeout:       5 * time.Second,
+	})
+	if err != nil {
+		t.Fatalf("NewWithConfig returned an error: %v", err)
+	}
+	response, err := service.Search(context.Background(), "golang", "", 3, false)
+	if err != nil {
+		t.Fatalf("Search returned an error: %v", err)
+	}
+	if results := response.Data.WebPages.Value; len(results) != 1 || results[0].Name != "Go" || results[0].URL != "https://go.dev/" {
+		t.Errorf("Unexpected results: %+v", results)
+	}
+}
+
+func TestParseResponse_Errors(t *testing.T) {
+	service, err := NewWithConfig(&config.Config{GenericMapping: hitsMapping})
+	if err != nil {
+		t.Fatalf("NewWithConfig returned an error: %v", err)
+	}
+	tests := []struct {
+		name       string
+		statusCode int
+		body       string
+		expected   string
+	}{
+		{"status", http.StatusServiceUnavailable, `{"error": "unavailable"}`, "generic api returned status code 503"},
+		{"not a list", http.StatusOK, `{"hits": {"hits": {"total": 0}}}`, "failed to parse generic api response: no result list at $.hits.hits"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.parseResponse(tt.statusCode, []byte(tt.body))
+			if err == nil || err.Error() != tt.expected {
+				t.Errorf("Expected %q, got %v", tt.expected, err)
+			}
+		})
+	}
+
+	response, err := service.parseResponse(http.StatusOK, []byte(`{"hits": {}}`))
+	if err != nil || len(response.Data.WebPages.Value) != 0 {
+		t.Errorf("Expected an empty result list, got %v (%v)", response, err)
+	}
+	if _, err := service.parseResponse(http.StatusOK, []byte(`<html>`)); err == nil {
+		t.Error("Expected error for a response that isn't JSON, got nil")
+	}
+}
+
+func TestRegistered(t *testing.T) {
+	provider, err := search.NewProvider(&config.Config{SearchProvider: config.ProviderGeneric, GenericMapping: config.DefaultGenericMapping})
+	if err != nil {
+		t.Fatalf("NewProvider returned an error: %v", err)
+	}
+	if provider.Name() != config.ProviderGeneric || len(provider.Capabilities().Freshness) != 1 {
+		t.Errorf("Expected the generic provider without freshness filters, got %s", provider.Name())
+	}
+
+	if _, err := search.NewProvider(&config.Config{SearchProvider: config.ProviderGeneric, GenericMapping: config.GenericMapping{Results: "["}}); err == nil {
+		t.Error("Expected error for an invalid mapping, got nil")
+	}
+}
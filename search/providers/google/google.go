@@ -0,0 +1,221 @@
+// Package google implements the search provider for Google Programmable Search,
+// through the Custom Search JSON API
+package google
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"golang.org/x/time/rate"
+
+	"com.moguyn/mcp-go-search/config"
+	"com.moguyn/mcp-go-search/params"
+	"com.moguyn/mcp-go-search/search"
+)
+
+// MaxCount is the largest number of results the API returns for one request
+const MaxCount = 10
+
+// dateRestricts maps the server's freshness values to Google's dateRestrict
+var dateRestricts = map[string]string{
+	"day":     "d1",
+	"week":    "w1",
+	"month":   "m1",
+	"oneYear": "y1",
+}
+
+// publishedTags are the page metatags that may carry a publish date, in order of preference
+var publishedTags = []string{"article:published_time", "og:updated_time", "article:modified_time"}
+
+func init() {
+	search.Register(config.ProviderGoogle, func(cfg *config.Config) (search.Provider, error) {
+		return NewWithConfig(cfg), nil
+	})
+}
+
+// Response is the part of a Custom Search JSON API response the provider uses
+type Response struct {
+	SearchInformation struct {
+		TotalResults string `json:"totalResults"`
+	} `json:"searchInformation"`
+	Items []Item `json:"items"`
+}
+
+// Item is a single Custom Search result
+type Item struct {
+	Title        string `json:"title"`
+	Link         string `json:"link"`
+	DisplayLink  string `json:"displayLink"`
+	FormattedURL string `json:"formattedUrl"`
+	Snippet      string `json:"snippet"`
+	Pagemap      struct {
+		Metatags []map[string]string `json:"metatags"`
+	} `json:"pagemap"`
+}
+
+// Service implements the search.Provider interface for Google Programmable Search
+type Service struct {
+	*search.RotatableKey
+	cx          string
+	apiBaseURL  string
+	httpClient  *http.Client
+	rateLimiter *rate.Limiter
+}
+
+// NewWithConfig creates a new Google provider with the provided configuration
+func NewWithConfig(cfg *config.Config) *Service {
+	return &Service{
+		RotatableKey: search.NewRotatableKey(cfg.GoogleAPIKey),
+		cx:           cfg.GoogleCX,
+		apiBaseURL:   cfg.GoogleAPIBaseURL,
+		httpClient:   search.NewHTTPClient(cfg),
+		// The API allows 100 queries per minute per user by default
+		rateLimiter: rate.NewLimiter(rate.Limit(100.0/60), 5),
+	}
+}
+
+// Warm connects to the Custom Search JSON API ahead of the first search
+func (s *Service) Warm(ctx context.Context) error {
+	return search.WarmURL(ctx, s.httpClient, s.apiBaseURL)
+}
+
+// Name returns the provider name used in configuration
+func (s *Service) Name() string {
+	return config.ProviderGoogle
+}
+
+// Capabilities describes what the Custom Search JSON API supports
+func (s *Service) Capabilities() search.Capabilities {
+	return search.Capabilities{
+		Provider:  config.ProviderGoogle,
+		Freshness: params.Freshness,
+		MaxCount:  MaxCount,
+		Operators: []string{search.OperatorSite, search.OperatorPhrase, search.OperatorExclude, search.OperatorOr},
+	}
+}
+
+// Search performs a search using the Custom Search JSON API
+func (s *Service) Search(ctx context.Context, query string, freshness string, count int, summary bool) (*search.WebSearchResponse, error) {
+	if err := search.WaitRateLimit(ctx, s.rateLimiter); err != nil {
+		return nil, fmt.Errorf("rate limit exceeded: %w", err)
+	}
+
+	// Validate inputs and bring them within the API's limits
+	p, adj, err := params.Normalize(params.Search{
+		Query:     query,
+		Freshness: freshness,
+		Count:     count,
+		Summary:   summary,
+	}, s.Capabilities().Limits())
+	if err != nil {
+		return nil, err
+	}
+
+	values := url.Values{}
+	values.Set("cx", s.cx)
+	values.Set("q", p.Query)
+	values.Set("num", strconv.Itoa(p.Count))
+	if restrict, ok := dateRestricts[p.Freshness]; ok {
+		values.Set("dateRestrict", restrict)
+	}
+	requestURL := s.apiBaseURL + "?" + values.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTTP request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	// The key goes in a header rather than the URL, so it never appears in errors
+	req.Header.Set("X-Goog-Api-Key", s.APIKey())
+	req.Header.Set("User-Agent", "BochaWebSearchMCPServer/1.0")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request to Google API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 10*1024*1024)) // 10MB limit
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Google API response body: %w", err)
+	}
+
+	searchResp, err := parseResponse(resp.StatusCode, body)
+	if err != nil {
+		return nil, search.WithTransfer(err, int64(len(requestURL)), int64(len(body)))
+	}
+	searchResp.Data.QueryContext.OriginalQuery = p.Query
+	if p.Summary {
+		searchResp.Data.WebPages.WebSearchURL = "https://cse.google.com/cse?" + url.Values{"cx": {s.cx}, "q": {p.Query}}.Encode()
+	}
+	searchResp.Meta = search.ResponseMeta{
+		QueryTruncated: adj.QueryTruncated,
+		CountClamped:   adj.CountClamped,
+		BytesSent:      int64(len(requestURL)),
+		BytesReceived:  int64(len(body)),
+	}
+	return searchResp, nil
+}
+
+// parseResponse decodes a Custom Search response body into the common
+// response format, turning non-200 statuses into errors
+func parseResponse(statusCode int, body []byte) (*search.WebSearchResponse, error) {
+	if statusCode != http.StatusOK {
+		var errorResp struct {
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if err := json.Unmarshal(body, &errorResp); err == nil && errorResp.Error.Message != "" {
+			return nil, fmt.Errorf("google api error (status %d): %s", statusCode, errorResp.Error.Message)
+		}
+		return nil, fmt.Errorf("google api returned status code %d", statusCode)
+	}
+
+	var googleResp Response
+	if err := json.Unmarshal(body, &googleResp); err != nil {
+		return nil, fmt.Errorf("failed to parse google api response: %w", err)
+	}
+
+	// Searches without matches have no items at all
+	results := make([]search.WebPageResult, 0, len(googleResp.Items))
+	for _, item := range googleResp.Items {
+		results = append(results, toWebPageResult(item))
+	}
+	searchResp := &search.WebSearchResponse{Code: http.StatusOK}
+	searchResp.Data.WebPages.Value = results
+	searchResp.Data.WebPages.TotalEstimatedMatches, _ = strconv.Atoi(googleResp.SearchInformation.TotalResults)
+	return searchResp, nil
+}
+
+// toWebPageResult maps a Custom Search item onto the common result format
+func toWebPageResult(item Item) search.WebPageResult {
+	result := search.WebPageResult{
+		Name:       item.Title,
+		URL:        item.Link,
+		DisplayURL: item.FormattedURL,
+		Snippet:    item.Snippet,
+		SiteName:   item.DisplayLink,
+	}
+	if result.DisplayURL == "" {
+		result.DisplayURL = item.Link
+	}
+	if len(item.Pagemap.Metatags) > 0 {
+		tags := item.Pagemap.Metatags[0]
+		if name := tags["og:site_name"]; name != "" {
+			result.SiteName = name
+		}
+		for _, tag := range publishedTags {
+			if date := tags[tag]; date != "" {
+				result.DateLastCrawled = date
+				break
+			}
+		}
+	}
+	return result
+}
@@ -0,0 +1,139 @@
+package google
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"com.moguyn/mcp-go-search/config"
+	"com.moguyn/mcp-go-search/search"
+)
+
+const googleResponse = `{
+	"kind": "customsearch#search",
+	"searchInformation": {"searchTime": 0.31, "totalResults": "1250000"},
+	"items": [
+		{
+			"kind": "customsearch#result",
+			"title": "Tutorial: Getting started with generics",
+			"link": "https://go.dev/doc/tutorial/generics",
+			"displayLink": "go.dev",
+			"formattedUrl": "https://go.dev/doc/tutorial/generics",
+			"snippet": "This tutorial introduces the basics of generics in Go.",
+			"pagemap": {"metatags": [{"og:site_name": "The Go Programming Language", "article:published_time": "2024-03-01T10:20:30Z"}]}
+		},
+		{
+			"kind": "customsearch#result",
+			"title": "An Introduction To Generics",
+			"link": "https://go.dev/blog/intro-generics",
+			"displayLink": "go.dev",
+			"snippet": "The Go 1.18 release adds support for generics."
+		}
+	]
+}`
+
+func TestService_Search(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			t.Errorf("Expected GET request, got %s", r.Method)
+		}
+		if key := r.Header.Get("X-Goog-Api-Key"); key != "test-google-key" {
+			t.Errorf("Expected the API key header, got %q", key)
+		}
+		query := r.URL.Query()
+		if query.Get("key") != "" {
+			t.Error("Expected the API key to stay out of the URL")
+		}
+		if query.Get("cx") != "test-cx" || query.Get("q") != "golang generics" || query.Get("num") != "10" || query.Get("dateRestrict") != "w1" {
+			t.Errorf("Unexpected query parameters: %s", r.URL.RawQuery)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(googleResponse))
+	}))
+	defer server.Close()
+
+	service := NewWithConfig(&config.Config{
+		GoogleAPIKey:     "test-google-key",
+		GoogleCX:         "test-cx",
+		GoogleAPIBaseURL: server.URL,
+		HTTPTimeout:      5 * time.Second,
+	})
+	response, err := service.Search(context.Background(), "golang generics", "week", 50, true)
+	if err != nil {
+		t.Fatalf("Search returned an error: %v", err)
+	}
+
+	if !response.Meta.CountClamped {
+		t.Error("Expected the count to be clamped to Google's maximum")
+	}
+	if response.Data.QueryContext.OriginalQuery != "golang generics" {
+		t.Errorf("Expected original query 'golang generics', got %q", response.Data.QueryContext.OriginalQuery)
+	}
+	if response.Data.WebPages.TotalEstimatedMatches != 1250000 {
+		t.Errorf("Expected 1250000 estimated matches, got %d", response.Data.WebPages.TotalEstimatedMatches)
+	}
+	if !strings.HasPrefix(response.Data.WebPages.WebSearchURL, "https://cse.google.com/cse?cx=test-cx") {
+		t.Errorf("Expected a Programmable Search URL, got %q", response.Data.WebPages.WebSearchURL)
+	}
+	results := response.Data.WebPages.Value
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 results, got %d", len(results))
+	}
+	first := results[0]
+	if first.Name != "Tutorial: Getting started with generics" || first.URL != "https://go.dev/doc/tutorial/generics" {
+		t.Errorf("Unexpected first result: %+v", first)
+	}
+	if first.SiteName != "The Go Programming Language" || first.DateLastCrawled != "2024-03-01T10:20:30Z" {
+		t.Errorf("Expected site name and date from the page metatags, got %+v", first)
+	}
+	second := results[1]
+	if second.SiteName != "go.dev" || second.DisplayURL != "https://go.dev/blog/intro-generics" {
+		t.Errorf("Expected the display link and URL without metatags, got %+v", second)
+	}
+}
+
+func TestService_Search_Errors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"error": {"code": 400, "message": "API key not valid. Please pass a valid API key.", "status": "INVALID_ARGUMENT"}}`))
+	}))
+	defer server.Close()
+
+	service := NewWithConfig(&config.Config{
+		GoogleAPIKey:     "bad-key",
+		GoogleCX:         "test-cx",
+		GoogleAPIBaseURL: server.URL,
+		HTTPTimeout:      5 * time.Second,
+	})
+	_, err := service.Search(context.Background(), "golang", "", 10, false)
+	if err == nil || err.Error() != "google api error (status 400): API key not valid. Please pass a valid API key." {
+		t.Errorf("Expected the Google error message, got %v", err)
+	}
+
+	if _, err := service.Search(context.Background(), "", "", 10, false); err == nil {
+		t.Error("Expected error for empty query, got nil")
+	}
+}
+
+func TestParseResponse_NoResults(t *testing.T) {
+	response, err := parseResponse(http.StatusOK, []byte(`{"searchInformation": {"totalResults": "0"}}`))
+	if err != nil {
+		t.Fatalf("parseResponse returned an error: %v", err)
+	}
+	if response.Data.WebPages.Value == nil || len(response.Data.WebPages.Value) != 0 {
+		t.Errorf("Expected an empty result list, got %v", response.Data.WebPages.Value)
+	}
+}
+
+func TestRegistered(t *testing.T) {
+	provider, err := search.NewProvider(&config.Config{SearchProvider: config.ProviderGoogle, GoogleAPIKey: "test-google-key", GoogleCX: "test-cx"})
+	if err != nil {
+		t.Fatalf("NewProvider returned an error: %v", err)
+	}
+	if provider.Name() != config.ProviderGoogle || provider.Capabilities().MaxCount != MaxCount {
+		t.Errorf("Expected the Google provider, got %s", provider.Name())
+	}
+}
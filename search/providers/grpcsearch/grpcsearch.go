@@ -0,0 +1,186 @@
+// Package grpcsearch implements a search provider for internal search
+// services reached over gRPC, which implement the SearchService of
+// proto/mcpsearch/v1/search.proto
+package grpcsearch
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"com.moguyn/mcp-go-search/config"
+	"com.moguyn/mcp-go-search/params"
+	"com.moguyn/mcp-go-search/search"
+	"com.moguyn/mcp-go-search/search/providers/grpcsearch/searchpb"
+)
+
+// maxMessageSize bounds a single response from the service
+const maxMessageSize = 10 * 1024 * 1024 // 10MB, same as the HTTP response limit
+
+func init() {
+	search.Register(config.ProviderGRPC, func(cfg *config.Config) (search.Provider, error) {
+		service, err := NewWithConfig(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return service, nil
+	})
+}
+
+// Service implements the search.Provider interface for a gRPC search service
+type Service struct {
+	*search.RotatableKey
+	conn     *grpc.ClientConn
+	client   searchpb.SearchServiceClient
+	metadata metadata.MD
+	timeout  time.Duration
+}
+
+// NewWithConfig creates a new gRPC provider with the provided configuration.
+// The connection is made on the first search, and remade when it breaks.
+func NewWithConfig(cfg *config.Config) (*Service, error) {
+	creds := insecure.NewCredentials()
+	if !cfg.GRPCPlaintext {
+		tlsConfig, err := cfg.GRPCTLSConfig()
+		if err != nil {
+			return nil, err
+		}
+		creds = credentials.NewTLS(tlsConfig)
+	}
+	opts := []grpc.DialOption{
+		grpc.WithTransportCredentials(creds),
+		grpc.WithUserAgent("BochaWebSearchMCPServer/1.0"),
+		grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(maxMessageSize)),
+	}
+	dial, err := cfg.DialContext()
+	if err != nil {
+		return nil, err
+	}
+	if dial != nil {
+		opts = append(opts, grpc.WithContextDialer(func(ctx context.Context, address string) (net.Conn, error) {
+			return dial(ctx, "tcp", address)
+		}))
+	}
+	conn, err := grpc.NewClient(cfg.GRPCTarget, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create gRPC client: %w", err)
+	}
+
+	return &Service{
+		RotatableKey: search.NewRotatableKey(cfg.GRPCAPIKey),
+		conn:         conn,
+		client:       searchpb.NewSearchServiceClient(conn),
+		metadata:     metadata.New(cfg.GRPCMetadata),
+		timeout:      cfg.HTTPTimeout,
+	}, nil
+}
+
+// Name returns the provider name used in configuration
+func (s *Service) Name() string {
+	return config.ProviderGRPC
+}
+
+// Capabilities describes what the service is assumed to support. The query
+// is sent as written, so operators are left to the service.
+func (s *Service) Capabilities() search.Capabilities {
+	return search.Capabilities{
+		Provider:   config.ProviderGRPC,
+		Freshness:  params.Freshness,
+		MaxCount:   params.MaxCount,
+		Operators:  []string{search.OperatorSite, search.OperatorPhrase, search.OperatorExclude, search.OperatorOr},
+		ExactQuery: true,
+	}
+}
+
+// Search calls the service's Search method
+func (s *Service) Search(ctx context.Context, query string, freshness string, count int, summary bool) (*search.WebSearchResponse, error) {
+	// Validate inputs and bring them within the service's limits
+	p, adj, err := params.Normalize(params.Search{
+		Query:     query,
+		Freshness: freshness,
+		Count:     count,
+		Summary:   summary,
+	}, s.Capabilities().Limits())
+	if err != nil {
+		return nil, err
+	}
+
+	if s.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.timeout)
+		defer cancel()
+	}
+	md := s.metadata
+	if apiKey := s.APIKey(); apiKey != "" {
+		md = md.Copy()
+		md.Set("authorization", "Bearer "+apiKey)
+	}
+	ctx = metadata.NewOutgoingContext(ctx, md)
+
+	req := &searchpb.SearchRequest{
+		Query:      p.Query,
+		Freshness:  p.Freshness,
+		Count:      int32(p.Count),
+		Summary:    p.Summary,
+		ExactQuery: search.ExactQuery(ctx),
+	}
+	resp, err := s.client.Search(ctx, req)
+	if err != nil {
+		st := status.Convert(err)
+		return nil, fmt.Errorf("grpc search failed (%s): %s", st.Code(), st.Message())
+	}
+
+	searchResp := toResponse(resp)
+	searchResp.Data.QueryContext.OriginalQuery = p.Query
+	searchResp.Meta = search.ResponseMeta{
+		QueryTruncated: adj.QueryTruncated,
+		CountClamped:   adj.CountClamped,
+		BytesSent:      int64(proto.Size(req)),
+		BytesReceived:  int64(proto.Size(resp)),
+	}
+	return searchResp, nil
+}
+
+// Close closes the connection to the service
+func (s *Service) Close() error {
+	return s.conn.Close()
+}
+
+// toResponse maps a service response onto the common response format.
+// Results without a URL are skipped, and the URL stands in for a missing
+// title.
+func toResponse(resp *searchpb.SearchResponse) *search.WebSearchResponse {
+	results := make([]search.WebPageResult, 0, len(resp.GetResults()))
+	for _, r := range resp.GetResults() {
+		if r.GetUrl() == "" {
+			continue
+		}
+		result := search.WebPageResult{
+			Name:            r.GetTitle(),
+			URL:             r.GetUrl(),
+			DisplayURL:      r.GetUrl(),
+			Snippet:         r.GetSnippet(),
+			SiteName:        r.GetSiteName(),
+			DateLastCrawled: r.GetDate(),
+		}
+		if result.Name == "" {
+			result.Name = result.URL
+		}
+		results = append(results, result)
+	}
+
+	searchResp := &search.WebSearchResponse{Code: http.StatusOK}
+	searchResp.Data.WebPages.Value = results
+	searchResp.Data.WebPages.TotalEstimatedMatches = int(resp.GetTotalEstimatedMatches())
+	searchResp.Data.QueryContext.AlteredQuery = resp.GetAlteredQuery()
+	return searchResp
+}
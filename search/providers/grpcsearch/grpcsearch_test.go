@@ -0,0 +1,235 @@
+package grpcsearch
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"com.moguyn/mcp-go-search/config"
+	"com.moguyn/mcp-go-search/search"
+	"com.moguyn/mcp-go-search/search/providers/grpcsearch/searchpb"
+)
+
+// fakeServer records the last request and its metadata and answers with
+// response, or err when it is set
+type fakeServer struct {
+	searchpb.UnimplementedSearchServiceServer
+	request  *searchpb.SearchRequest
+	metadata metadata.MD
+	response *searchpb.SearchResponse
+	err      error
+}
+
+func (f *fakeServer) Search(ctx context.Context, req *searchpb.SearchRequest) (*searchpb.SearchResponse, error) {
+	f.request = req
+	f.metadata, _ = metadata.FromIncomingContext(ctx)
+	if f.err != nil {
+		return nil, f.err
+	}
+	return f.response, nil
+}
+
+// startServer serves fake on a local port and returns its address
+func startServer(t *testing.T, fake *fakeServer, opts ...grpc.ServerOption) string {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	server := grpc.NewServer(opts...)
+	searchpb.RegisterSearchServiceServer(server, fake)
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+	return lis.Addr().String()
+}
+
+func newService(t *testing.T, cfg *config.Config) *Service {
+	t.Helper()
+	service, err := NewWithConfig(cfg)
+	if err != nil {
+		t.Fatalf("NewWithConfig returned an error: %v", err)
+	}
+	t.Cleanup(func() { service.Close() })
+	return service
+}
+
+func TestService_Search(t *testing.T) {
+	fake := &fakeServer{response: &searchpb.SearchResponse{
+		Results: []*searchpb.Result{
+			{Title: "Go", Url: "https://go.dev", Snippet: "The Go language", SiteName: "go.dev", Date: "2024-01-02T00:00:00Z"},
+			{Url: "https://example.com"},
+			{Title: "No URL"},
+		},
+		TotalEstimatedMatches: 42,
+		AlteredQuery:          "golang",
+	}}
+	target := startServer(t, fake)
+	service := newService(t, &config.Config{
+		GRPCTarget:    target,
+		GRPCPlaintext: true,
+		GRPCAPIKey:    "secret",
+		GRPCMetadata:  map[string]string{"x-tenant": "search"},
+		HTTPTimeout:   5 * time.Second,
+	})
+
+	ctx := search.WithExactQuery(context.Background())
+	response, err := service.Search(ctx, "golnag", "week", 5, true)
+	if err != nil {
+		t.Fatalf("Search returned an error: %v", err)
+	}
+
+	if fake.request.GetQuery() != "golnag" || fake.request.GetFreshness() != "week" || fake.request.GetCount() != 5 {
+		t.Errorf("Expected the search in the request, got %v", fake.request)
+	}
+	if !fake.request.GetSummary() || !fake.request.GetExactQuery() {
+		t.Errorf("Expected summary and exact_query set, got %v", fake.request)
+	}
+	if got := fake.metadata.Get("authorization"); len(got) != 1 || got[0] != "Bearer secret" {
+		t.Errorf("Expected authorization 'Bearer secret', got %v", got)
+	}
+	if got := fake.metadata.Get("x-tenant"); len(got) != 1 || got[0] != "search" {
+		t.Errorf("Expected x-tenant 'search', got %v", got)
+	}
+
+	results := response.Data.WebPages.Value
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 results, got %d", len(results))
+	}
+	if results[0].Name != "Go" || results[0].URL != "https://go.dev" || results[0].Snippet != "The Go language" ||
+		results[0].SiteName != "go.dev" || results[0].DateLastCrawled != "2024-01-02T00:00:00Z" {
+		t.Errorf("Expected the first result mapped, got %+v", results[0])
+	}
+	if results[1].Name != "https://example.com" {
+		t.Errorf("Expected the URL as the missing title, got %q", results[1].Name)
+	}
+	if response.Data.WebPages.TotalEstimatedMatches != 42 {
+		t.Errorf("Expected 42 total matches, got %d", response.Data.WebPages.TotalEstimatedMatches)
+	}
+	if response.Data.QueryContext.OriginalQuery != "golnag" || response.Data.QueryContext.AlteredQuery != "golang" {
+		t.Errorf("Expected the original and altered queries, got %+v", response.Data.QueryContext)
+	}
+	if response.Meta.BytesSent == 0 || response.Meta.BytesReceived == 0 {
+		t.Errorf("Expected the bytes sent and received counted, got %+v", response.Meta)
+	}
+}
+
+func TestService_Search_NoKey(t *testing.T) {
+	fake := &fakeServer{response: &searchpb.SearchResponse{}}
+	service := newService(t, &config.Config{GRPCTarget: startServer(t, fake), GRPCPlaintext: true})
+
+	if _, err := service.Search(context.Background(), "golang", "noLimit", 10, false); err != nil {
+		t.Fatalf("Search returned an error: %v", err)
+	}
+	if got := fake.metadata.Get("authorization"); len(got) != 0 {
+		t.Errorf("Expected no authorization without a key, got %v", got)
+	}
+}
+
+func TestService_Search_Error(t *testing.T) {
+	fake := &fakeServer{err: status.Error(codes.Unauthenticated, "bad key")}
+	service := newService(t, &config.Config{GRPCTarget: startServer(t, fake), GRPCPlaintext: true})
+
+	_, err := service.Search(context.Background(), "golang", "noLimit", 10, false)
+	if err == nil {
+		t.Fatal("Expected an error, got nil")
+	}
+	if !strings.Contains(err.Error(), "Unauthenticated") || !strings.Contains(err.Error(), "bad key") {
+		t.Errorf("Expected the status in the error, got %v", err)
+	}
+}
+
+func TestService_Search_InvalidParams(t *testing.T) {
+	service := newService(t, &config.Config{GRPCTarget: "127.0.0.1:1", GRPCPlaintext: true})
+
+	if _, err := service.Search(context.Background(), "", "noLimit", 10, false); err == nil {
+		t.Error("Expected an error for an empty query, got nil")
+	}
+}
+
+func TestService_Search_TLS(t *testing.T) {
+	dir := t.TempDir()
+	cert := writeCertificate(t, dir)
+	fake := &fakeServer{response: &searchpb.SearchResponse{
+		Results: []*searchpb.Result{{Title: "Go", Url: "https://go.dev"}},
+	}}
+	target := startServer(t, fake, grpc.Creds(credentials.NewServerTLSFromCert(&cert)))
+
+	service := newService(t, &config.Config{
+		GRPCTarget:     target,
+		GRPCCAFile:     filepath.Join(dir, "cert.pem"),
+		GRPCServerName: "search.internal",
+	})
+	response, err := service.Search(context.Background(), "golang", "noLimit", 10, false)
+	if err != nil {
+		t.Fatalf("Search returned an error: %v", err)
+	}
+	if len(response.Data.WebPages.Value) != 1 {
+		t.Errorf("Expected 1 result, got %d", len(response.Data.WebPages.Value))
+	}
+
+	// Without the CA the service's certificate isn't trusted
+	untrusted := newService(t, &config.Config{GRPCTarget: target, GRPCServerName: "search.internal"})
+	if _, err := untrusted.Search(context.Background(), "golang", "noLimit", 10, false); err == nil {
+		t.Error("Expected an error for an untrusted certificate, got nil")
+	}
+}
+
+// writeCertificate writes a self-signed certificate for search.internal and
+// its key to dir as cert.pem and key.pem
+func writeCertificate(t *testing.T, dir string) tls.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "search.internal"},
+		DNSNames:              []string{"search.internal"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Failed to create certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("Failed to marshal key: %v", err)
+	}
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	if err := os.WriteFile(filepath.Join(dir, "cert.pem"), certPEM, 0o600); err != nil {
+		t.Fatalf("Failed to write certificate: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "key.pem"), keyPEM, 0o600); err != nil {
+		t.Fatalf("Failed to write key: %v", err)
+	}
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		t.Fatalf("Failed to load certificate: %v", err)
+	}
+	return cert
+}
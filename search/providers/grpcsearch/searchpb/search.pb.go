@@ -0,0 +1,326 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: mcpsearch/v1/search.proto
+
+// The search service the grpc provider calls. An internal search service
+// implements SearchService; the server sends one Search call per search.
+
+package searchpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// SearchRequest is a search, with its parameters validated and clamped
+type SearchRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The query as the user wrote it, operators included
+	Query string `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
+	// One of noLimit, day, week, month and oneYear
+	Freshness string `protobuf:"bytes,2,opt,name=freshness,proto3" json:"freshness,omitempty"`
+	// The number of results wanted, from 1 to 50
+	Count int32 `protobuf:"varint,3,opt,name=count,proto3" json:"count,omitempty"`
+	// Whether a summary of each result is wanted
+	Summary bool `protobuf:"varint,4,opt,name=summary,proto3" json:"summary,omitempty"`
+	// Whether the query should be searched as written, without spelling correction
+	ExactQuery    bool `protobuf:"varint,5,opt,name=exact_query,json=exactQuery,proto3" json:"exact_query,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchRequest) Reset() {
+	*x = SearchRequest{}
+	mi := &file_mcpsearch_v1_search_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchRequest) ProtoMessage() {}
+
+func (x *SearchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_mcpsearch_v1_search_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchRequest.ProtoReflect.Descriptor instead.
+func (*SearchRequest) Descriptor() ([]byte, []int) {
+	return file_mcpsearch_v1_search_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *SearchRequest) GetQuery() string {
+	if x != nil {
+		return x.Query
+	}
+	return ""
+}
+
+func (x *SearchRequest) GetFreshness() string {
+	if x != nil {
+		return x.Freshness
+	}
+	return ""
+}
+
+func (x *SearchRequest) GetCount() int32 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+func (x *SearchRequest) GetSummary() bool {
+	if x != nil {
+		return x.Summary
+	}
+	return false
+}
+
+func (x *SearchRequest) GetExactQuery() bool {
+	if x != nil {
+		return x.ExactQuery
+	}
+	return false
+}
+
+// SearchResponse is the results of a search, best first
+type SearchResponse struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Results []*Result              `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	// The number of matching documents, when the service knows it
+	TotalEstimatedMatches int64 `protobuf:"varint,2,opt,name=total_estimated_matches,json=totalEstimatedMatches,proto3" json:"total_estimated_matches,omitempty"`
+	// The query the results are for, when the service corrected it
+	AlteredQuery  string `protobuf:"bytes,3,opt,name=altered_query,json=alteredQuery,proto3" json:"altered_query,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchResponse) Reset() {
+	*x = SearchResponse{}
+	mi := &file_mcpsearch_v1_search_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchResponse) ProtoMessage() {}
+
+func (x *SearchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_mcpsearch_v1_search_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchResponse.ProtoReflect.Descriptor instead.
+func (*SearchResponse) Descriptor() ([]byte, []int) {
+	return file_mcpsearch_v1_search_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *SearchResponse) GetResults() []*Result {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+func (x *SearchResponse) GetTotalEstimatedMatches() int64 {
+	if x != nil {
+		return x.TotalEstimatedMatches
+	}
+	return 0
+}
+
+func (x *SearchResponse) GetAlteredQuery() string {
+	if x != nil {
+		return x.AlteredQuery
+	}
+	return ""
+}
+
+// Result is a single search result
+type Result struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Title    string                 `protobuf:"bytes,1,opt,name=title,proto3" json:"title,omitempty"`
+	Url      string                 `protobuf:"bytes,2,opt,name=url,proto3" json:"url,omitempty"`
+	Snippet  string                 `protobuf:"bytes,3,opt,name=snippet,proto3" json:"snippet,omitempty"`
+	SiteName string                 `protobuf:"bytes,4,opt,name=site_name,json=siteName,proto3" json:"site_name,omitempty"`
+	// When the document was published or last changed, in RFC 3339 format
+	Date          string `protobuf:"bytes,5,opt,name=date,proto3" json:"date,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Result) Reset() {
+	*x = Result{}
+	mi := &file_mcpsearch_v1_search_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Result) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Result) ProtoMessage() {}
+
+func (x *Result) ProtoReflect() protoreflect.Message {
+	mi := &file_mcpsearch_v1_search_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Result.ProtoReflect.Descriptor instead.
+func (*Result) Descriptor() ([]byte, []int) {
+	return file_mcpsearch_v1_search_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Result) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *Result) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *Result) GetSnippet() string {
+	if x != nil {
+		return x.Snippet
+	}
+	return ""
+}
+
+func (x *Result) GetSiteName() string {
+	if x != nil {
+		return x.SiteName
+	}
+	return ""
+}
+
+func (x *Result) GetDate() string {
+	if x != nil {
+		return x.Date
+	}
+	return ""
+}
+
+var File_mcpsearch_v1_search_proto protoreflect.FileDescriptor
+
+const file_mcpsearch_v1_search_proto_rawDesc = "" +
+	"\n" +
+	"\x19mcpsearch/v1/search.proto\x12\fmcpsearch.v1\"\x94\x01\n" +
+	"\rSearchRequest\x12\x14\n" +
+	"\x05query\x18\x01 \x01(\tR\x05query\x12\x1c\n" +
+	"\tfreshness\x18\x02 \x01(\tR\tfreshness\x12\x14\n" +
+	"\x05count\x18\x03 \x01(\x05R\x05count\x12\x18\n" +
+	"\asummary\x18\x04 \x01(\bR\asummary\x12\x1f\n" +
+	"\vexact_query\x18\x05 \x01(\bR\n" +
+	"exactQuery\"\x9d\x01\n" +
+	"\x0eSearchResponse\x12.\n" +
+	"\aresults\x18\x01 \x03(\v2\x14.mcpsearch.v1.ResultR\aresults\x126\n" +
+	"\x17total_estimated_matches\x18\x02 \x01(\x03R\x15totalEstimatedMatches\x12#\n" +
+	"\raltered_query\x18\x03 \x01(\tR\falteredQuery\"{\n" +
+	"\x06Result\x12\x14\n" +
+	"\x05title\x18\x01 \x01(\tR\x05title\x12\x10\n" +
+	"\x03url\x18\x02 \x01(\tR\x03url\x12\x18\n" +
+	"\asnippet\x18\x03 \x01(\tR\asnippet\x12\x1b\n" +
+	"\tsite_name\x18\x04 \x01(\tR\bsiteName\x12\x12\n" +
+	"\x04date\x18\x05 \x01(\tR\x04date2T\n" +
+	"\rSearchService\x12C\n" +
+	"\x06Search\x12\x1b.mcpsearch.v1.SearchRequest\x1a\x1c.mcpsearch.v1.SearchResponseB?Z=com.moguyn/mcp-go-search/search/providers/grpcsearch/searchpbb\x06proto3"
+
+var (
+	file_mcpsearch_v1_search_proto_rawDescOnce sync.Once
+	file_mcpsearch_v1_search_proto_rawDescData []byte
+)
+
+func file_mcpsearch_v1_search_proto_rawDescGZIP() []byte {
+	file_mcpsearch_v1_search_proto_rawDescOnce.Do(func() {
+		file_mcpsearch_v1_search_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_mcpsearch_v1_search_proto_rawDesc), len(file_mcpsearch_v1_search_proto_rawDesc)))
+	})
+	return file_mcpsearch_v1_search_proto_rawDescData
+}
+
+var file_mcpsearch_v1_search_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_mcpsearch_v1_search_proto_goTypes = []any{
+	(*SearchRequest)(nil),  // 0: mcpsearch.v1.SearchRequest
+	(*SearchResponse)(nil), // 1: mcpsearch.v1.SearchResponse
+	(*Result)(nil),         // 2: mcpsearch.v1.Result
+}
+var file_mcpsearch_v1_search_proto_depIdxs = []int32{
+	2, // 0: mcpsearch.v1.SearchResponse.results:type_name -> mcpsearch.v1.Result
+	0, // 1: mcpsearch.v1.SearchService.Search:input_type -> mcpsearch.v1.SearchRequest
+	1, // 2: mcpsearch.v1.SearchService.Search:output_type -> mcpsearch.v1.SearchResponse
+	2, // [2:3] is the sub-list for method output_type
+	1, // [1:2] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	1, // [1:1] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
+}
+
+func init() { file_mcpsearch_v1_search_proto_init() }
+func file_mcpsearch_v1_search_proto_init() {
+	if File_mcpsearch_v1_search_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_mcpsearch_v1_search_proto_rawDesc), len(file_mcpsearch_v1_search_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   3,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_mcpsearch_v1_search_proto_goTypes,
+		DependencyIndexes: file_mcpsearch_v1_search_proto_depIdxs,
+		MessageInfos:      file_mcpsearch_v1_search_proto_msgTypes,
+	}.Build()
+	File_mcpsearch_v1_search_proto = out.File
+	file_mcpsearch_v1_search_proto_goTypes = nil
+	file_mcpsearch_v1_search_proto_depIdxs = nil
+}
@@ -0,0 +1,279 @@
+// Package jina implements the search provider for the Jina Search API, whose
+// results carry the content of each page already cleaned up as markdown
+package jina
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"text/template"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/time/rate"
+
+	"com.moguyn/mcp-go-search/config"
+	"com.moguyn/mcp-go-search/params"
+	"com.moguyn/mcp-go-search/search"
+)
+
+// MaxCount is the largest number of results Jina returns for one search
+const MaxCount = 10
+
+// maxSnippetLength is the number of characters of page content kept as a
+// result's snippet
+const maxSnippetLength = 2000
+
+// maxReads is the number of results without content the reader is asked to
+// read, since each read fetches a whole page
+const maxReads = 3
+
+// dateLayouts are the forms result dates come in
+var dateLayouts = []string{time.RFC3339, "Jan 2, 2006", time.DateOnly}
+
+func init() {
+	search.Register(config.ProviderJina, func(cfg *config.Config) (search.Provider, error) {
+		return NewWithConfig(cfg), nil
+	})
+}
+
+// Request is the request body of the search API
+type Request struct {
+	Query string `json:"q"`
+	Num   int    `json:"num"`
+}
+
+// Response is a search API response
+type Response struct {
+	Code int    `json:"code"`
+	Data []Page `json:"data"`
+}
+
+// ReaderResponse is a reader API response
+type ReaderResponse struct {
+	Code int  `json:"code"`
+	Data Page `json:"data"`
+}
+
+// Page is a single search result, or a page read by the reader
+type Page struct {
+	Title       string `json:"title"`
+	URL         string `json:"url"`
+	Description string `json:"description"`
+	Content     string `json:"content"`
+	Date        string `json:"date"`
+}
+
+// Service implements the search.Provider interface for the Jina Search API
+type Service struct {
+	*search.RotatableKey
+	searchURL   string
+	readerURL   string
+	template    *template.Template
+	httpClient  *http.Client
+	rateLimiter *rate.Limiter
+}
+
+// NewWithConfig creates a new Jina provider with the provided configuration
+func NewWithConfig(cfg *config.Config) *Service {
+	// The template is validated at startup, so an error here is unexpected
+	tmpl, _ := cfg.RequestTemplate(config.ProviderJina)
+	return &Service{
+		RotatableKey: search.NewRotatableKey(cfg.JinaAPIKey),
+		searchURL:    cfg.JinaSearchURL,
+		readerURL:    cfg.JinaReaderURL,
+		template:     tmpl,
+		httpClient:   search.NewHTTPClient(cfg),
+		// The default key allows 40 searches a minute
+		rateLimiter: rate.NewLimiter(rate.Every(1500*time.Millisecond), 2),
+	}
+}
+
+// Name returns the provider name used in configuration
+func (s *Service) Name() string {
+	return config.ProviderJina
+}
+
+// Capabilities describes what the Jina Search API supports. It has no
+// freshness filter.
+func (s *Service) Capabilities() search.Capabilities {
+	return search.Capabilities{
+		Provider:  config.ProviderJina,
+		Freshness: []string{params.DefaultFreshness},
+		MaxCount:  MaxCount,
+		Operators: []string{search.OperatorSite, search.OperatorPhrase, search.OperatorExclude},
+	}
+}
+
+// Search performs a search using the Jina Search API, then reads the pages of
+// the first few results that came back without content
+func (s *Service) Search(ctx context.Context, query string, freshness string, count int, summary bool) (*search.WebSearchResponse, error) {
+	if err := search.WaitRateLimit(ctx, s.rateLimiter); err != nil {
+		return nil, fmt.Errorf("rate limit exceeded: %w", err)
+	}
+
+	// Validate inputs and bring them within the API's limits
+	p, adj, err := params.Normalize(params.Search{
+		Query:     query,
+		Freshness: freshness,
+		Count:     count,
+		Summary:   summary,
+	}, s.Capabilities().Limits())
+	if err != nil {
+		return nil, err
+	}
+
+	var reqBody []byte
+	if s.template != nil {
+		reqBody, err = config.RenderRequestBody(s.template, config.RequestTemplateData{Search: p})
+	} else {
+		reqBody, err = json.Marshal(Request{Query: p.Query, Num: p.Count})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request body: %w", err)
+	}
+	body, err := s.do(ctx, http.MethodPost, s.searchURL, reqBody)
+	if err != nil {
+		return nil, err
+	}
+	var jinaResp Response
+	if err := json.Unmarshal(body, &jinaResp); err != nil {
+		return nil, fmt.Errorf("failed to parse jina api response: %w", err)
+	}
+
+	meta := search.ResponseMeta{
+		QueryTruncated: adj.QueryTruncated,
+		CountClamped:   adj.CountClamped,
+		BytesSent:      int64(len(reqBody)),
+		BytesReceived:  int64(len(body)),
+	}
+	pages := jinaResp.Data
+	if len(pages) > p.Count {
+		pages = pages[:p.Count]
+	}
+	reads := 0
+	for i := range pages {
+		if pages[i].Content != "" || pages[i].URL == "" || reads == maxReads {
+			continue
+		}
+		reads++
+		// A page the reader can't read keeps its description
+		readerURL := s.readerURL + pages[i].URL
+		readerBody, err := s.do(ctx, http.MethodGet, readerURL, nil)
+		if err != nil {
+			continue
+		}
+		meta.BytesSent += int64(len(readerURL))
+		meta.BytesReceived += int64(len(readerBody))
+		var read ReaderResponse
+		if err := json.Unmarshal(readerBody, &read); err == nil {
+			pages[i].Content = read.Data.Content
+		}
+	}
+
+	results := make([]search.WebPageResult, 0, len(pages))
+	for _, page := range pages {
+		results = append(results, toWebPageResult(page))
+	}
+	searchResp := &search.WebSearchResponse{Code: http.StatusOK}
+	searchResp.Data.QueryContext.OriginalQuery = p.Query
+	searchResp.Data.WebPages.Value = results
+	searchResp.Meta = meta
+	return searchResp, nil
+}
+
+// do sends a request to one of the API's endpoints and returns the body of a
+// successful response
+func (s *Service) do(ctx context.Context, method, requestURL string, reqBody []byte) ([]byte, error) {
+	var bodyReader io.Reader
+	if reqBody != nil {
+		bodyReader = bytes.NewReader(reqBody)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, requestURL, bodyReader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTTP request: %w", err)
+	}
+	if reqBody != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Authorization", "Bearer "+s.APIKey())
+	req.Header.Set("X-Retain-Images", "none")
+	req.Header.Set("User-Agent", "BochaWebSearchMCPServer/1.0")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request to Jina API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 10*1024*1024)) // 10MB limit
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Jina API response body: %w", err)
+	}
+	if err := checkStatus(resp.StatusCode, body); err != nil {
+		sent := int64(len(reqBody))
+		if reqBody == nil {
+			sent = int64(len(requestURL))
+		}
+		return nil, search.WithTransfer(err, sent, int64(len(body)))
+	}
+	return body, nil
+}
+
+// checkStatus turns a non-200 status into an error with the API's message
+func checkStatus(statusCode int, body []byte) error {
+	if statusCode == http.StatusOK {
+		return nil
+	}
+	var errorResp struct {
+		Message         string `json:"message"`
+		ReadableMessage string `json:"readableMessage"`
+	}
+	if err := json.Unmarshal(body, &errorResp); err == nil {
+		message := errorResp.ReadableMessage
+		if message == "" {
+			message = errorResp.Message
+		}
+		if message != "" {
+			return fmt.Errorf("jina api error (status %d): %s", statusCode, message)
+		}
+	}
+	return fmt.Errorf("jina api returned status code %d", statusCode)
+}
+
+// toWebPageResult maps a page onto the common result format, with its
+// markdown content, shortened, as the snippet
+func toWebPageResult(page Page) search.WebPageResult {
+	result := search.WebPageResult{
+		Name:       page.Title,
+		URL:        page.URL,
+		DisplayURL: page.URL,
+		Snippet:    snippet(page),
+	}
+	for _, layout := range dateLayouts {
+		if date, err := time.Parse(layout, page.Date); err == nil {
+			result.DateLastCrawled = date.Format(time.RFC3339)
+			break
+		}
+	}
+	return result
+}
+
+// snippet returns the page's content cut to maxSnippetLength characters, or
+// its description when it has no content
+func snippet(page Page) string {
+	content := strings.TrimSpace(page.Content)
+	if content == "" {
+		return page.Description
+	}
+	if utf8.RuneCountInString(content) <= maxSnippetLength {
+		return content
+	}
+	runes := []rune(content)
+	return strings.TrimSpace(string(runes[:maxSnippetLength])) + "…"
+}
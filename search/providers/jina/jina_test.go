@@ -0,0 +1,139 @@
+package jina
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"com.moguyn/mcp-go-search/config"
+	"com.moguyn/mcp-go-search/search"
+)
+
+const jinaResponse = `{
+	"code": 200,
+	"status": 20000,
+	"data": [
+		{
+			"title": "Tutorial: Getting started with generics",
+			"url": "https://go.dev/doc/tutorial/generics",
+			"description": "This tutorial introduces the basics of generics in Go.",
+			"content": "# Tutorial: Getting started with generics\n\nThis tutorial introduces the basics of generics in Go.",
+			"date": "Mar 12, 2025"
+		},
+		{
+			"title": "Go generics cheat sheet",
+			"url": "https://example.com/go-generics",
+			"description": "Type parameters and constraints at a glance."
+		}
+	],
+	"meta": {"usage": {"tokens": 1234}}
+}`
+
+// newTestServer serves the search endpoint at /search and the reader at /read/
+func newTestServer(t *testing.T, reads *int) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if target, ok := strings.CutPrefix(r.URL.Path, "/read/"); ok {
+			*reads++
+			if target != "https://example.com/go-generics" {
+				t.Errorf("Unexpected page read: %s", target)
+			}
+			_, _ = w.Write([]byte(`{"code": 200, "data": {"title": "Go generics cheat sheet", "content": "## Type parameters\n\nfunc Map[T, U any]"}}`))
+			return
+		}
+		if r.Method != http.MethodPost {
+			t.Errorf("Expected POST request, got %s", r.Method)
+		}
+		if auth := r.Header.Get("Authorization"); auth != "Bearer test-jina-key" {
+			t.Errorf("Expected the bearer token, got %q", auth)
+		}
+		var body Request
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Fatalf("Failed to decode request body: %v", err)
+		}
+		if body.Query != "go generics" || body.Num != MaxCount {
+			t.Errorf("Unexpected request body: %+v", body)
+		}
+		_, _ = w.Write([]byte(jinaResponse))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestService_Search(t *testing.T) {
+	reads := 0
+	server := newTestServer(t, &reads)
+	service := NewWithConfig(&config.Config{
+		JinaAPIKey:    "test-jina-key",
+		JinaSearchURL: server.URL + "/search",
+		JinaReaderURL: server.URL + "/read/",
+		HTTPTimeout:   5 * time.Second,
+	})
+
+	response, err := service.Search(context.Background(), "go generics", "", 20, false)
+	if err != nil {
+		t.Fatalf("Search returned an error: %v", err)
+	}
+	if !response.Meta.CountClamped {
+		t.Error("Expected the count to be clamped to Jina's maximum")
+	}
+	results := response.Data.WebPages.Value
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 results, got %d", len(results))
+	}
+	if !strings.HasPrefix(results[0].Snippet, "# Tutorial: Getting started with generics\n\n") {
+		t.Errorf("Expected the markdown content as snippet, got %q", results[0].Snippet)
+	}
+	if results[0].DateLastCrawled != "2025-03-12T00:00:00Z" {
+		t.Errorf("Expected the date in RFC 3339, got %q", results[0].DateLastCrawled)
+	}
+	if reads != 1 || results[1].Snippet != "## Type parameters\n\nfunc Map[T, U any]" {
+		t.Errorf("Expected the reader's content for the result without content, got %d reads and %q", reads, results[1].Snippet)
+	}
+}
+
+func TestService_Search_Errors(t *testing.T) {
+	service := NewWithConfig(&config.Config{JinaAPIKey: "test-jina-key", HTTPTimeout: 5 * time.Second})
+	if _, err := service.Search(context.Background(), "news", "day", 10, false); err == nil {
+		t.Error("Expected error for unsupported freshness, got nil")
+	}
+
+	tests := []struct {
+		statusCode int
+		body       string
+		expected   string
+	}{
+		{http.StatusUnauthorized, `{"code": 401, "name": "AuthenticationRequiredError", "readableMessage": "AuthenticationRequiredError: Authentication is required"}`, "jina api error (status 401): AuthenticationRequiredError: Authentication is required"},
+		{http.StatusPaymentRequired, `{"code": 402, "message": "Insufficient balance"}`, "jina api error (status 402): Insufficient balance"},
+		{http.StatusBadGateway, `<html>bad gateway</html>`, "jina api returned status code 502"},
+	}
+	for _, tt := range tests {
+		if err := checkStatus(tt.statusCode, []byte(tt.body)); err == nil || err.Error() != tt.expected {
+			t.Errorf("Expected %q, got %v", tt.expected, err)
+		}
+	}
+}
+
+func TestSnippet(t *testing.T) {
+	long := strings.Repeat("字", maxSnippetLength+10)
+	if got := snippet(Page{Content: long}); got != strings.Repeat("字", maxSnippetLength)+"…" {
+		t.Errorf("Expected the content cut to %d characters, got %d", maxSnippetLength, len([]rune(got)))
+	}
+	if got := snippet(Page{Description: "A description", Content: "  "}); got != "A description" {
+		t.Errorf("Expected the description without content, got %q", got)
+	}
+}
+
+func TestRegistered(t *testing.T) {
+	provider, err := search.NewProvider(&config.Config{SearchProvider: config.ProviderJina, JinaAPIKey: "test-jina-key"})
+	if err != nil {
+		t.Fatalf("NewProvider returned an error: %v", err)
+	}
+	if provider.Name() != config.ProviderJina || provider.Capabilities().MaxCount != MaxCount {
+		t.Errorf("Expected the Jina provider, got %s", provider.Name())
+	}
+}
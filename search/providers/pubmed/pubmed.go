@@ -0,0 +1,372 @@
+// Package pubmed implements the search provider for PubMed, through the NCBI
+// E-utilities API, which searches the biomedical literature
+package pubmed
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"com.moguyn/mcp-go-search/config"
+	"com.moguyn/mcp-go-search/params"
+	"com.moguyn/mcp-go-search/search"
+)
+
+// MaxCount is the largest number of articles fetched for one search
+const MaxCount = params.MaxCount
+
+// toolName identifies the server to NCBI, as its usage policy asks
+const toolName = "mcp-go-search"
+
+// articleURL is the PubMed page of an article, by PMID
+const articleURL = "https://pubmed.ncbi.nlm.nih.gov/%s/"
+
+// relativeDays maps the server's freshness values to the number of days back
+// an article's publication date may go
+var relativeDays = map[string]int{
+	"day":     1,
+	"week":    7,
+	"month":   30,
+	"oneYear": 365,
+}
+
+func init() {
+	search.Register(config.ProviderPubMed, func(cfg *config.Config) (search.Provider, error) {
+		return NewWithConfig(cfg), nil
+	})
+}
+
+// SearchResponse is the part of an esearch response the provider uses
+type SearchResponse struct {
+	Result struct {
+		Count  string   `json:"count"`
+		IDList []string `json:"idlist"`
+	} `json:"esearchresult"`
+}
+
+// ArticleSet is an efetch response
+type ArticleSet struct {
+	Articles []Article `xml:"PubmedArticle"`
+}
+
+// Article is a single PubMed record
+type Article struct {
+	PMID    string `xml:"MedlineCitation>PMID"`
+	Title   Markup `xml:"MedlineCitation>Article>ArticleTitle"`
+	Journal struct {
+		Title   string  `xml:"Title"`
+		PubDate PubDate `xml:"JournalIssue>PubDate"`
+	} `xml:"MedlineCitation>Article>Journal"`
+	Abstract []AbstractText `xml:"MedlineCitation>Article>Abstract>AbstractText"`
+	Authors  []Author       `xml:"MedlineCitation>Article>AuthorList>Author"`
+}
+
+// Markup is text that may contain inline markup, such as <i> and <sup>
+type Markup struct {
+	Inner string `xml:",innerxml"`
+}
+
+// AbstractText is a section of an abstract, labelled in structured abstracts
+type AbstractText struct {
+	Label string `xml:"Label,attr"`
+	Markup
+}
+
+// Author is one of an article's authors, or a group authoring it
+type Author struct {
+	LastName       string `xml:"LastName"`
+	ForeName       string `xml:"ForeName"`
+	CollectiveName string `xml:"CollectiveName"`
+}
+
+// PubDate is the publication date of the journal issue
+type PubDate struct {
+	Year        string `xml:"Year"`
+	Month       string `xml:"Month"`
+	Day         string `xml:"Day"`
+	MedlineDate string `xml:"MedlineDate"`
+}
+
+// Service implements the search.Provider interface for PubMed
+type Service struct {
+	*search.RotatableKey
+	baseURL     string
+	httpClient  *http.Client
+	rateLimiter *rate.Limiter
+}
+
+// NewWithConfig creates a new PubMed provider with the provided configuration
+func NewWithConfig(cfg *config.Config) *Service {
+	// NCBI allows three requests a second without a key and ten with one;
+	// every search makes two
+	limit := rate.Limit(3)
+	if cfg.PubMedAPIKey != "" {
+		limit = rate.Limit(10)
+	}
+	return &Service{
+		RotatableKey: search.NewRotatableKey(cfg.PubMedAPIKey),
+		baseURL:      strings.TrimSuffix(cfg.PubMedAPIBaseURL, "/"),
+		httpClient:   search.NewHTTPClient(cfg),
+		rateLimiter:  rate.NewLimiter(limit, 1),
+	}
+}
+
+// Name returns the provider name used in configuration
+func (s *Service) Name() string {
+	return config.ProviderPubMed
+}
+
+// Capabilities describes what PubMed supports. Freshness filters on the
+// publication date.
+func (s *Service) Capabilities() search.Capabilities {
+	return search.Capabilities{
+		Provider:  config.ProviderPubMed,
+		Freshness: params.Freshness,
+		MaxCount:  MaxCount,
+		Operators: []string{search.OperatorPhrase, search.OperatorExclude, search.OperatorOr},
+	}
+}
+
+// Search finds the IDs of matching articles with esearch, then fetches their
+// records with efetch
+func (s *Service) Search(ctx context.Context, query string, freshness string, count int, summary bool) (*search.WebSearchResponse, error) {
+	// Validate inputs and bring them within the API's limits
+	p, adj, err := params.Normalize(params.Search{
+		Query:     query,
+		Freshness: freshness,
+		Count:     count,
+		Summary:   summary,
+	}, s.Capabilities().Limits())
+	if err != nil {
+		return nil, err
+	}
+
+	values := s.values()
+	values.Set("term", buildTerm(p.Query))
+	values.Set("retmax", strconv.Itoa(p.Count))
+	values.Set("retmode", "json")
+	values.Set("sort", "relevance")
+	if days, ok := relativeDays[p.Freshness]; ok {
+		values.Set("datetype", "pdat")
+		values.Set("reldate", strconv.Itoa(days))
+	}
+	form := values.Encode()
+	body, err := s.post(ctx, "esearch.fcgi", form)
+	if err != nil {
+		return nil, err
+	}
+	var found SearchResponse
+	if err := json.Unmarshal(body, &found); err != nil {
+		return nil, fmt.Errorf("failed to parse pubmed search response: %w", err)
+	}
+	meta := search.ResponseMeta{
+		QueryTruncated: adj.QueryTruncated,
+		CountClamped:   adj.CountClamped,
+		BytesSent:      int64(len(form)),
+		BytesReceived:  int64(len(body)),
+	}
+
+	searchResp := &search.WebSearchResponse{Code: http.StatusOK}
+	searchResp.Data.QueryContext.OriginalQuery = p.Query
+	searchResp.Data.WebPages.TotalEstimatedMatches, _ = strconv.Atoi(found.Result.Count)
+	searchResp.Data.WebPages.Value = []search.WebPageResult{}
+	searchResp.Meta = meta
+	if len(found.Result.IDList) == 0 {
+		return searchResp, nil
+	}
+
+	values = s.values()
+	values.Set("id", strings.Join(found.Result.IDList, ","))
+	values.Set("retmode", "xml")
+	form = values.Encode()
+	body, err = s.post(ctx, "efetch.fcgi", form)
+	if err != nil {
+		return nil, err
+	}
+	var set ArticleSet
+	if err := xml.Unmarshal(body, &set); err != nil {
+		return nil, fmt.Errorf("failed to parse pubmed articles: %w", err)
+	}
+	searchResp.Meta.BytesSent += int64(len(form))
+	searchResp.Meta.BytesReceived += int64(len(body))
+
+	// efetch returns records in PMID order; keep esearch's relevance order
+	byID := make(map[string]Article, len(set.Articles))
+	for _, article := range set.Articles {
+		byID[article.PMID] = article
+	}
+	for _, id := range found.Result.IDList {
+		if article, ok := byID[id]; ok {
+			searchResp.Data.WebPages.Value = append(searchResp.Data.WebPages.Value, toWebPageResult(article))
+		}
+	}
+	return searchResp, nil
+}
+
+// values returns the parameters every request carries
+func (s *Service) values() url.Values {
+	values := url.Values{}
+	values.Set("db", "pubmed")
+	values.Set("tool", toolName)
+	if apiKey := s.APIKey(); apiKey != "" {
+		values.Set("api_key", apiKey)
+	}
+	return values
+}
+
+// post sends a form to one of the E-utilities and returns the body of a
+// successful response. The parameters go in the body rather than the URL, so
+// the key never appears in errors and long ID lists fit.
+func (s *Service) post(ctx context.Context, utility string, form string) ([]byte, error) {
+	if err := search.WaitRateLimit(ctx, s.rateLimiter); err != nil {
+		return nil, fmt.Errorf("rate limit exceeded: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.baseURL+"/"+utility, strings.NewReader(form))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTTP request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("User-Agent", "BochaWebSearchMCPServer/1.0")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request to PubMed API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 10*1024*1024)) // 10MB limit
+	if err != nil {
+		return nil, fmt.Errorf("failed to read PubMed API response body: %w", err)
+	}
+	if err := checkStatus(resp.StatusCode, body); err != nil {
+		return nil, search.WithTransfer(err, int64(len(form)), int64(len(body)))
+	}
+	return body, nil
+}
+
+// checkStatus turns a non-200 status, or an error reported in a 200 search
+// response, into an error with the API's message
+func checkStatus(statusCode int, body []byte) error {
+	var errorResp struct {
+		Error  string `json:"error"`
+		Result struct {
+			Error string `json:"ERROR"`
+		} `json:"esearchresult"`
+	}
+	if err := json.Unmarshal(body, &errorResp); err == nil {
+		message := errorResp.Error
+		if message == "" {
+			message = errorResp.Result.Error
+		}
+		if message != "" {
+			return fmt.Errorf("pubmed api error (status %d): %s", statusCode, message)
+		}
+	}
+	if statusCode != http.StatusOK {
+		return fmt.Errorf("pubmed api returned status code %d", statusCode)
+	}
+	return nil
+}
+
+// buildTerm turns a query into PubMed's search syntax, which already joins
+// terms with AND and understands OR and quoted phrases; a minus sign becomes NOT
+func buildTerm(query string) string {
+	fields := strings.Fields(query)
+	for i, field := range fields {
+		if rest, ok := strings.CutPrefix(field, "-"); ok && rest != "" && i > 0 {
+			fields[i] = "NOT " + rest
+		}
+	}
+	return strings.Join(fields, " ")
+}
+
+// toWebPageResult maps an article onto the common result format, with its
+// abstract as the snippet and its journal as the site
+func toWebPageResult(article Article) search.WebPageResult {
+	link := fmt.Sprintf(articleURL, article.PMID)
+	result := search.WebPageResult{
+		Name:            plainText(article.Title.Inner),
+		URL:             link,
+		DisplayURL:      link,
+		Snippet:         abstract(article.Abstract),
+		SiteName:        article.Journal.Title,
+		DateLastCrawled: article.Journal.PubDate.String(),
+	}
+	for _, author := range article.Authors {
+		switch {
+		case author.CollectiveName != "":
+			result.Authors = append(result.Authors, author.CollectiveName)
+		case author.ForeName != "":
+			result.Authors = append(result.Authors, author.ForeName+" "+author.LastName)
+		case author.LastName != "":
+			result.Authors = append(result.Authors, author.LastName)
+		}
+	}
+	return result
+}
+
+// abstract joins the sections of an abstract, prefixing labelled sections
+// with their label
+func abstract(sections []AbstractText) string {
+	parts := make([]string, 0, len(sections))
+	for _, section := range sections {
+		text := plainText(section.Inner)
+		if text == "" {
+			continue
+		}
+		if section.Label != "" {
+			text = section.Label + ": " + text
+		}
+		parts = append(parts, text)
+	}
+	return strings.Join(parts, " ")
+}
+
+// plainText removes the inline markup, such as <i> and <sup>, that titles and
+// abstracts contain, and joins their lines
+func plainText(text string) string {
+	var b strings.Builder
+	decoder := xml.NewDecoder(strings.NewReader("<t>" + text + "</t>"))
+	decoder.Strict = false
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			break
+		}
+		if data, ok := token.(xml.CharData); ok {
+			b.Write(data)
+		}
+	}
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+// String returns the date as YYYY-MM-DD, or as precise as the record is.
+// Dates PubMed only gives in free form, such as "2023 Spring", are returned
+// as they are.
+func (d PubDate) String() string {
+	if d.Year == "" {
+		return d.MedlineDate
+	}
+	month, err := time.Parse("Jan", d.Month)
+	if err != nil {
+		if n, err := strconv.Atoi(d.Month); err == nil && n >= 1 && n <= 12 {
+			month = time.Date(0, time.Month(n), 1, 0, 0, 0, 0, time.UTC)
+		} else {
+			return d.Year
+		}
+	}
+	day, err := strconv.Atoi(d.Day)
+	if err != nil {
+		return fmt.Sprintf("%s-%02d", d.Year, int(month.Month()))
+	}
+	return fmt.Sprintf("%s-%02d-%02d", d.Year, int(month.Month()), day)
+}
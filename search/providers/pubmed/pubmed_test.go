@@ -0,0 +1,205 @@
+package pubmed
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"com.moguyn/mcp-go-search/config"
+	"com.moguyn/mcp-go-search/search"
+)
+
+const searchResponse = `{
+	"header": {"type": "esearch", "version": "0.3"},
+	"esearchresult": {"count": "2417", "retmax": "2", "retstart": "0", "idlist": ["37192001", "36542003"]}
+}`
+
+// The records come back in PMID order, not in the order of the search
+const fetchResponse = `<?xml version="1.0" ?>
+<!DOCTYPE PubmedArticleSet PUBLIC "-//NLM//DTD PubMedArticle, 1st January 2024//EN" "https://dtd.nlm.nih.gov/ncbi/pubmed/out/pubmed_240101.dtd">
+<PubmedArticleSet>
+<PubmedArticle>
+  <MedlineCitation Status="MEDLINE" Owner="NLM">
+    <PMID Version="1">36542003</PMID>
+    <Article PubModel="Print">
+      <Journal>
+        <Title>The Lancet</Title>
+        <JournalIssue><PubDate><MedlineDate>2022 Winter</MedlineDate></PubDate></JournalIssue>
+      </Journal>
+      <ArticleTitle>Vaccine uptake in older adults.</ArticleTitle>
+      <AuthorList><Author><CollectiveName>COVID-19 Study Group</CollectiveName></Author></AuthorList>
+    </Article>
+  </MedlineCitation>
+</PubmedArticle>
+<PubmedArticle>
+  <MedlineCitation Status="MEDLINE" Owner="NLM">
+    <PMID Version="1">37192001</PMID>
+    <Article PubModel="Print-Electronic">
+      <Journal>
+        <Title>Nature medicine</Title>
+        <JournalIssue><PubDate><Year>2023</Year><Month>May</Month><Day>04</Day></PubDate></JournalIssue>
+      </Journal>
+      <ArticleTitle>Effectiveness of mRNA vaccines against <i>SARS-CoV-2</i> variants.</ArticleTitle>
+      <Abstract>
+        <AbstractText Label="BACKGROUND">Variants of
+          concern emerged in 2021.</AbstractText>
+        <AbstractText Label="RESULTS">Effectiveness was 89% (CI<sub>95</sub> 85-92).</AbstractText>
+      </Abstract>
+      <AuthorList>
+        <Author><LastName>Smith</LastName><ForeName>Jane A</ForeName><Initials>JA</Initials></Author>
+        <Author><LastName>Chen</LastName><ForeName>Wei</ForeName><Initials>W</Initials></Author>
+      </AuthorList>
+    </Article>
+  </MedlineCitation>
+</PubmedArticle>
+</PubmedArticleSet>`
+
+func TestService_Search(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("Expected POST request, got %s", r.Method)
+		}
+		if err := r.ParseForm(); err != nil {
+			t.Fatalf("Failed to parse form: %v", err)
+		}
+		if r.URL.RawQuery != "" {
+			t.Errorf("Expected no parameters in the URL, got %q", r.URL.RawQuery)
+		}
+		if r.PostForm.Get("db") != "pubmed" || r.PostForm.Get("api_key") != "test-ncbi-key" || r.PostForm.Get("tool") != toolName {
+			t.Errorf("Unexpected common parameters: %v", r.PostForm)
+		}
+		switch r.URL.Path {
+		case "/esearch.fcgi":
+			if term := r.PostForm.Get("term"); term != `"mRNA vaccine" NOT review` {
+				t.Errorf("Unexpected term %q", term)
+			}
+			if r.PostForm.Get("reldate") != "365" || r.PostForm.Get("datetype") != "pdat" || r.PostForm.Get("retmax") != "2" {
+				t.Errorf("Unexpected search parameters: %v", r.PostForm)
+			}
+			_, _ = w.Write([]byte(searchResponse))
+		case "/efetch.fcgi":
+			if id := r.PostForm.Get("id"); id != "37192001,36542003" {
+				t.Errorf("Unexpected IDs %q", id)
+			}
+			_, _ = w.Write([]byte(fetchResponse))
+		default:
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	service := NewWithConfig(&config.Config{PubMedAPIKey: "test-ncbi-key", PubMedAPIBaseURL: server.URL + "/", HTTPTimeout: 5 * time.Second})
+	response, err := service.Search(context.Background(), `"mRNA vaccine" -review`, "oneYear", 2, false)
+	if err != nil {
+		t.Fatalf("Search returned an error: %v", err)
+	}
+
+	if response.Data.WebPages.TotalEstimatedMatches != 2417 {
+		t.Errorf("Expected 2417 matches, got %d", response.Data.WebPages.TotalEstimatedMatches)
+	}
+	results := response.Data.WebPages.Value
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 articles, got %d", len(results))
+	}
+	first := results[0]
+	if first.Name != "Effectiveness of mRNA vaccines against SARS-CoV-2 variants." || first.URL != "https://pubmed.ncbi.nlm.nih.gov/37192001/" {
+		t.Errorf("Expected the most relevant article first without markup, got %+v", first)
+	}
+	if first.Snippet != "BACKGROUND: Variants of concern emerged in 2021. RESULTS: Effectiveness was 89% (CI95 85-92)." {
+		t.Errorf("Unexpected abstract %q", first.Snippet)
+	}
+	if strings.Join(first.Authors, ", ") != "Jane A Smith, Wei Chen" || first.SiteName != "Nature medicine" || first.DateLastCrawled != "2023-05-04" {
+		t.Errorf("Unexpected article details: %+v", first)
+	}
+	second := results[1]
+	if len(second.Authors) != 1 || second.Authors[0] != "COVID-19 Study Group" || second.DateLastCrawled != "2022 Winter" {
+		t.Errorf("Expected the group author and free-form date, got %+v", second)
+	}
+}
+
+func TestService_Search_NoResults(t *testing.T) {
+	fetched := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/efetch.fcgi" {
+			fetched = true
+		}
+		_, _ = w.Write([]byte(`{"esearchresult": {"count": "0", "idlist": []}}`))
+	}))
+	defer server.Close()
+
+	service := NewWithConfig(&config.Config{PubMedAPIBaseURL: server.URL, HTTPTimeout: 5 * time.Second})
+	response, err := service.Search(context.Background(), "zzzxq", "", 10, false)
+	if err != nil {
+		t.Fatalf("Search returned an error: %v", err)
+	}
+	if response.Data.WebPages.Value == nil || len(response.Data.WebPages.Value) != 0 || fetched {
+		t.Errorf("Expected an empty result list without fetching, got %v", response.Data.WebPages.Value)
+	}
+}
+
+func TestCheckStatus(t *testing.T) {
+	tests := []struct {
+		statusCode int
+		body       string
+		expected   string
+	}{
+		{http.StatusTooManyRequests, `{"error": "API rate limit exceeded", "api-key": "1.2.3.4", "count": "4", "limit": "3"}`, "pubmed api error (status 429): API rate limit exceeded"},
+		{http.StatusOK, `{"esearchresult": {"ERROR": "Invalid query"}}`, "pubmed api error (status 200): Invalid query"},
+		{http.StatusBadGateway, `<html>bad gateway</html>`, "pubmed api returned status code 502"},
+	}
+	for _, tt := range tests {
+		if err := checkStatus(tt.statusCode, []byte(tt.body)); err == nil || err.Error() != tt.expected {
+			t.Errorf("Expected %q, got %v", tt.expected, err)
+		}
+	}
+	if err := checkStatus(http.StatusOK, []byte(fetchResponse)); err != nil {
+		t.Errorf("Expected no error for articles, got %v", err)
+	}
+}
+
+func TestBuildTerm(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected string
+	}{
+		{"asthma children", "asthma children"},
+		{"asthma -adults", "asthma NOT adults"},
+		{"-adults", "-adults"},
+		{"asthma OR copd", "asthma OR copd"},
+	}
+	for _, tt := range tests {
+		if got := buildTerm(tt.query); got != tt.expected {
+			t.Errorf("Expected %q for %q, got %q", tt.expected, tt.query, got)
+		}
+	}
+}
+
+func TestPubDate_String(t *testing.T) {
+	tests := []struct {
+		date     PubDate
+		expected string
+	}{
+		{PubDate{Year: "2023", Month: "May", Day: "4"}, "2023-05-04"},
+		{PubDate{Year: "2023", Month: "11"}, "2023-11"},
+		{PubDate{Year: "2023"}, "2023"},
+		{PubDate{MedlineDate: "2022 Nov-Dec"}, "2022 Nov-Dec"},
+	}
+	for _, tt := range tests {
+		if got := tt.date.String(); got != tt.expected {
+			t.Errorf("Expected %q for %+v, got %q", tt.expected, tt.date, got)
+		}
+	}
+}
+
+func TestRegistered(t *testing.T) {
+	provider, err := search.NewProvider(&config.Config{SearchProvider: config.ProviderPubMed})
+	if err != nil {
+		t.Fatalf("NewProvider returned an error: %v", err)
+	}
+	if provider.Name() != config.ProviderPubMed || provider.Capabilities().MaxCount != MaxCount {
+		t.Errorf("Expected the PubMed provider, got %s", provider.Name())
+	}
+}
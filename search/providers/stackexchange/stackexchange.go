@@ -0,0 +1,287 @@
+// Package stackexchange implements the search provider for Stack Overflow and
+// the other Stack Exchange sites, through the Stack Exchange API
+package stackexchange
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/time/rate"
+
+	"com.moguyn/mcp-go-search/config"
+	"com.moguyn/mcp-go-search/params"
+	"com.moguyn/mcp-go-search/search"
+)
+
+// MaxCount is the largest page of questions the API returns
+const MaxCount = params.MaxCount
+
+// excerptLength is the number of characters of an accepted answer shown
+const excerptLength = 300
+
+// maxAge maps the server's freshness values to how long ago a question may
+// have been asked
+var maxAge = map[string]time.Duration{
+	"day":     24 * time.Hour,
+	"week":    7 * 24 * time.Hour,
+	"month":   30 * 24 * time.Hour,
+	"oneYear": 365 * 24 * time.Hour,
+}
+
+// htmlTag matches the markup of answer bodies
+var htmlTag = regexp.MustCompile(`<[^>]*>`)
+
+func init() {
+	search.Register(config.ProviderStackExchange, func(cfg *config.Config) (search.Provider, error) {
+		return NewWithConfig(cfg), nil
+	})
+}
+
+// Wrapper is the common envelope of API responses
+type Wrapper[T any] struct {
+	Items        []T    `json:"items"`
+	Total        int    `json:"total"`
+	Backoff      int    `json:"backoff"`
+	ErrorID      int    `json:"error_id"`
+	ErrorName    string `json:"error_name"`
+	ErrorMessage string `json:"error_message"`
+}
+
+// Question is a single search result
+type Question struct {
+	QuestionID       int      `json:"question_id"`
+	Title            string   `json:"title"`
+	Link             string   `json:"link"`
+	Score            int      `json:"score"`
+	AnswerCount      int      `json:"answer_count"`
+	AcceptedAnswerID int      `json:"accepted_answer_id"`
+	Tags             []string `json:"tags"`
+	CreationDate     int64    `json:"creation_date"`
+}
+
+// Answer is an accepted answer, fetched with its body
+type Answer struct {
+	AnswerID int    `json:"answer_id"`
+	Score    int    `json:"score"`
+	Body     string `json:"body"`
+}
+
+// Service implements the search.Provider interface for Stack Exchange
+type Service struct {
+	*search.RotatableKey
+	site        string
+	apiURL      string
+	httpClient  *http.Client
+	rateLimiter *rate.Limiter
+	now         func() time.Time
+}
+
+// NewWithConfig creates a new Stack Exchange provider with the provided
+// configuration
+func NewWithConfig(cfg *config.Config) *Service {
+	return &Service{
+		RotatableKey: search.NewRotatableKey(cfg.StackExchangeKey),
+		site:         cfg.StackExchangeSite,
+		apiURL:       strings.TrimSuffix(cfg.StackExchangeAPIURL, "/"),
+		httpClient:   search.NewHTTPClient(cfg),
+		// The API throttles clients sending more than 30 requests a second;
+		// every search makes up to two
+		rateLimiter: rate.NewLimiter(rate.Limit(10), 5),
+		now:         time.Now,
+	}
+}
+
+// Name returns the provider name used in configuration
+func (s *Service) Name() string {
+	return config.ProviderStackExchange
+}
+
+// Capabilities describes what the search API supports. Freshness filters on
+// the date a question was asked.
+func (s *Service) Capabilities() search.Capabilities {
+	return search.Capabilities{
+		Provider:  config.ProviderStackExchange,
+		Freshness: params.Freshness,
+		MaxCount:  MaxCount,
+	}
+}
+
+// Search finds matching questions, then fetches the accepted answers of those
+// that have one
+func (s *Service) Search(ctx context.Context, query string, freshness string, count int, summary bool) (*search.WebSearchResponse, error) {
+	// Validate inputs and bring them within the API's limits
+	p, adj, err := params.Normalize(params.Search{
+		Query:     query,
+		Freshness: freshness,
+		Count:     count,
+		Summary:   summary,
+	}, s.Capabilities().Limits())
+	if err != nil {
+		return nil, err
+	}
+
+	values := s.values()
+	values.Set("q", p.Query)
+	values.Set("sort", "relevance")
+	values.Set("order", "desc")
+	values.Set("pagesize", strconv.Itoa(p.Count))
+	if age, ok := maxAge[p.Freshness]; ok {
+		values.Set("fromdate", strconv.FormatInt(s.now().Add(-age).Unix(), 10))
+	}
+	var questions Wrapper[Question]
+	sent, received, err := s.get(ctx, "/search/advanced", values, &questions)
+	if err != nil {
+		return nil, err
+	}
+
+	searchResp := &search.WebSearchResponse{Code: http.StatusOK}
+	searchResp.Data.QueryContext.OriginalQuery = p.Query
+	searchResp.Data.WebPages.TotalEstimatedMatches = questions.Total
+	searchResp.Meta = search.ResponseMeta{
+		QueryTruncated: adj.QueryTruncated,
+		CountClamped:   adj.CountClamped,
+		BytesSent:      sent,
+		BytesReceived:  received,
+	}
+
+	answers := make(map[int]Answer)
+	var ids []string
+	for _, question := range questions.Items {
+		if question.AcceptedAnswerID != 0 {
+			ids = append(ids, strconv.Itoa(question.AcceptedAnswerID))
+		}
+	}
+	if len(ids) > 0 {
+		var accepted Wrapper[Answer]
+		values := s.values()
+		values.Set("filter", "withbody")
+		values.Set("pagesize", strconv.Itoa(len(ids)))
+		sent, received, err := s.get(ctx, "/answers/"+strings.Join(ids, ";"), values, &accepted)
+		if err != nil {
+			return nil, err
+		}
+		searchResp.Meta.BytesSent += sent
+		searchResp.Meta.BytesReceived += received
+		for _, answer := range accepted.Items {
+			answers[answer.AnswerID] = answer
+		}
+	}
+
+	results := make([]search.WebPageResult, 0, len(questions.Items))
+	for _, question := range questions.Items {
+		answer, ok := answers[question.AcceptedAnswerID]
+		results = append(results, toWebPageResult(question, answer, ok))
+	}
+	searchResp.Data.WebPages.Value = results
+	return searchResp, nil
+}
+
+// values returns the parameters every request carries. The key is not a
+// secret; it only raises the daily quota.
+func (s *Service) values() url.Values {
+	values := url.Values{}
+	values.Set("site", s.site)
+	if apiKey := s.APIKey(); apiKey != "" {
+		values.Set("key", apiKey)
+	}
+	return values
+}
+
+// get sends a request to the API and decodes the response into v, returning
+// the bytes sent and received
+func (s *Service) get(ctx context.Context, path string, values url.Values, v any) (int64, int64, error) {
+	if err := search.WaitRateLimit(ctx, s.rateLimiter); err != nil {
+		return 0, 0, fmt.Errorf("rate limit exceeded: %w", err)
+	}
+	query := values.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.apiURL+path+"?"+query, nil)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to create HTTP request: %w", err)
+	}
+	req.Header.Set("User-Agent", "BochaWebSearchMCPServer/1.0")
+
+	// The API compresses every response; the transport decompresses it
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to send request to Stack Exchange API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 10*1024*1024)) // 10MB limit
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to read Stack Exchange API response body: %w", err)
+	}
+	if err := parseResponse(resp.StatusCode, body, v); err != nil {
+		return 0, 0, search.WithTransfer(err, int64(len(query)), int64(len(body)))
+	}
+	return int64(len(query)), int64(len(body)), nil
+}
+
+// parseResponse decodes a response body into v, turning the API's error
+// responses into Go errors
+func parseResponse(statusCode int, body []byte, v any) error {
+	var errorResp Wrapper[json.RawMessage]
+	if err := json.Unmarshal(body, &errorResp); err == nil && errorResp.ErrorID != 0 {
+		return fmt.Errorf("stackexchange api error (status %d): %s: %s", statusCode, errorResp.ErrorName, errorResp.ErrorMessage)
+	}
+	if statusCode != http.StatusOK {
+		return fmt.Errorf("stackexchange api returned status code %d", statusCode)
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("failed to parse stackexchange api response: %w", err)
+	}
+	return nil
+}
+
+// toWebPageResult maps a question onto the common result format, with an
+// excerpt of its accepted answer as the snippet and its score, number of
+// answers and tags as facts
+func toWebPageResult(question Question, answer Answer, accepted bool) search.WebPageResult {
+	result := search.WebPageResult{
+		Name:       html.UnescapeString(question.Title),
+		URL:        question.Link,
+		DisplayURL: question.Link,
+		RichSnippet: &search.RichSnippet{
+			Type: "question",
+			Facts: []search.Fact{
+				{Label: "Score", Value: strconv.Itoa(question.Score)},
+				{Label: "Answers", Value: strconv.Itoa(question.AnswerCount)},
+				{Label: "Tags", Value: strings.Join(question.Tags, ", ")},
+			},
+		},
+	}
+	if u, err := url.Parse(question.Link); err == nil {
+		result.SiteName = u.Host
+	}
+	if question.CreationDate > 0 {
+		result.DateLastCrawled = time.Unix(question.CreationDate, 0).UTC().Format(time.RFC3339)
+	}
+	if accepted {
+		result.Snippet = fmt.Sprintf("Accepted answer (score %d): %s", answer.Score, excerpt(answer.Body))
+	}
+	return result
+}
+
+// excerpt returns the start of an answer body as plain text, cut at a word
+// boundary
+func excerpt(body string) string {
+	text := strings.Join(strings.Fields(html.UnescapeString(htmlTag.ReplaceAllString(body, " "))), " ")
+	if utf8.RuneCountInString(text) <= excerptLength {
+		return text
+	}
+	cut := string([]rune(text)[:excerptLength])
+	if i := strings.LastIndex(cut, " "); i > 0 {
+		cut = cut[:i]
+	}
+	return cut + "…"
+}
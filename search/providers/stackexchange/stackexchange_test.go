@@ -0,0 +1,181 @@
+package stackexchange
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"com.moguyn/mcp-go-search/config"
+	"com.moguyn/mcp-go-search/search"
+)
+
+const questionsResponse = `{
+	"items": [
+		{
+			"tags": ["go", "generics"],
+			"is_answered": true,
+			"answer_count": 3,
+			"accepted_answer_id": 7001,
+			"score": 42,
+			"creation_date": 1655290800,
+			"question_id": 6001,
+			"link": "https://stackoverflow.com/questions/6001/how-to-constrain-a-type-parameter",
+			"title": "How to constrain a type parameter to &quot;comparable&quot; types?"
+		},
+		{
+			"tags": ["go"],
+			"is_answered": false,
+			"answer_count": 0,
+			"score": -1,
+			"creation_date": 1655377200,
+			"question_id": 6002,
+			"link": "https://stackoverflow.com/questions/6002/generic-methods",
+			"title": "Generic methods in Go"
+		}
+	],
+	"has_more": true,
+	"quota_max": 300,
+	"quota_remaining": 299
+}`
+
+const answersResponse = `{
+	"items": [
+		{
+			"answer_id": 7001,
+			"question_id": 6001,
+			"score": 57,
+			"is_accepted": true,
+			"body": "<p>Use the predeclared <code>comparable</code> constraint:</p>\n\n<pre><code>func Index[T comparable](s []T, x T) int\n</code></pre>\n<p>It allows <code>==</code> &amp; <code>!=</code>.</p>"
+		}
+	],
+	"has_more": false
+}`
+
+func TestService_Search(t *testing.T) {
+	now := time.Date(2022, 6, 20, 12, 0, 0, 0, time.UTC)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			t.Errorf("Expected GET request, got %s", r.Method)
+		}
+		query := r.URL.Query()
+		if query.Get("site") != "serverfault" || query.Get("key") != "test-se-key" {
+			t.Errorf("Unexpected common parameters: %v", query)
+		}
+		switch r.URL.Path {
+		case "/search/advanced":
+			if query.Get("q") != "go generics" || query.Get("sort") != "relevance" || query.Get("pagesize") != "2" {
+				t.Errorf("Unexpected search parameters: %v", query)
+			}
+			if query.Get("fromdate") != "1655121600" {
+				t.Errorf("Expected questions from a week ago, got %q", query.Get("fromdate"))
+			}
+			_, _ = w.Write([]byte(questionsResponse))
+		case "/answers/7001":
+			if query.Get("filter") != "withbody" {
+				t.Errorf("Expected the answers with their bodies, got %v", query)
+			}
+			_, _ = w.Write([]byte(answersResponse))
+		default:
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	service := NewWithConfig(&config.Config{
+		StackExchangeKey:    "test-se-key",
+		StackExchangeSite:   "serverfault",
+		StackExchangeAPIURL: server.URL + "/",
+		HTTPTimeout:         5 * time.Second,
+	})
+	service.now = func() time.Time { return now }
+	response, err := service.Search(context.Background(), "go generics", "week", 2, false)
+	if err != nil {
+		t.Fatalf("Search returned an error: %v", err)
+	}
+
+	results := response.Data.WebPages.Value
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 questions, got %d", len(results))
+	}
+	first := results[0]
+	if first.Name != `How to constrain a type parameter to "comparable" types?` || first.URL != "https://stackoverflow.com/questions/6001/how-to-constrain-a-type-parameter" {
+		t.Errorf("Expected the decoded title and link, got %+v", first)
+	}
+	expected := "Accepted answer (score 57): Use the predeclared comparable constraint: func Index[T comparable](s []T, x T) int It allows == & != ."
+	if first.Snippet != expected {
+		t.Errorf("Expected %q, got %q", expected, first.Snippet)
+	}
+	if first.SiteName != "stackoverflow.com" || first.DateLastCrawled != "2022-06-15T11:00:00Z" {
+		t.Errorf("Unexpected question details: %+v", first)
+	}
+	facts := first.RichSnippet.Facts
+	if len(facts) != 3 || facts[0].Value != "42" || facts[1].Value != "3" || facts[2].Value != "go, generics" {
+		t.Errorf("Expected the score, answers and tags as facts, got %+v", facts)
+	}
+	if results[1].Snippet != "" || results[1].RichSnippet.Facts[0].Value != "-1" {
+		t.Errorf("Expected no excerpt without an accepted answer, got %+v", results[1])
+	}
+	if response.Meta.BytesReceived != int64(len(questionsResponse)+len(answersResponse)) {
+		t.Errorf("Expected both responses to be counted, got %d bytes", response.Meta.BytesReceived)
+	}
+}
+
+func TestService_Search_NoAccepted(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		_, _ = w.Write([]byte(`{"items": [{"question_id": 1, "title": "Unanswered", "link": "https://superuser.com/questions/1"}]}`))
+	}))
+	defer server.Close()
+
+	service := NewWithConfig(&config.Config{StackExchangeSite: "superuser", StackExchangeAPIURL: server.URL, HTTPTimeout: 5 * time.Second})
+	response, err := service.Search(context.Background(), "unanswered", "", 10, false)
+	if err != nil {
+		t.Fatalf("Search returned an error: %v", err)
+	}
+	if len(response.Data.WebPages.Value) != 1 || requests != 1 {
+		t.Errorf("Expected one question without fetching answers, got %d after %d requests", len(response.Data.WebPages.Value), requests)
+	}
+}
+
+func TestParseResponse_Errors(t *testing.T) {
+	tests := []struct {
+		statusCode int
+		body       string
+		expected   string
+	}{
+		{http.StatusBadRequest, `{"error_id": 400, "error_message": "site is required", "error_name": "bad_parameter"}`, "stackexchange api error (status 400): bad_parameter: site is required"},
+		{http.StatusBadRequest, `{"error_id": 502, "error_message": "too many requests from this IP", "error_name": "throttle_violation"}`, "stackexchange api error (status 400): throttle_violation: too many requests from this IP"},
+		{http.StatusBadGateway, `<html>bad gateway</html>`, "stackexchange api returned status code 502"},
+	}
+	for _, tt := range tests {
+		var questions Wrapper[Question]
+		if err := parseResponse(tt.statusCode, []byte(tt.body), &questions); err == nil || err.Error() != tt.expected {
+			t.Errorf("Expected %q, got %v", tt.expected, err)
+		}
+	}
+}
+
+func TestExcerpt(t *testing.T) {
+	long := strings.Repeat("word ", 100)
+	got := excerpt("<p>" + long + "</p>")
+	if !strings.HasSuffix(got, "word…") || len([]rune(got)) > excerptLength+1 {
+		t.Errorf("Expected the excerpt cut at a word boundary, got %q", got)
+	}
+	if got := excerpt("<p>Short &lt;answer&gt;</p>"); got != "Short <answer>" {
+		t.Errorf("Expected the plain text, got %q", got)
+	}
+}
+
+func TestRegistered(t *testing.T) {
+	provider, err := search.NewProvider(&config.Config{SearchProvider: config.ProviderStackExchange})
+	if err != nil {
+		t.Fatalf("NewProvider returned an error: %v", err)
+	}
+	if provider.Name() != config.ProviderStackExchange || provider.Capabilities().MaxCount != MaxCount {
+		t.Errorf("Expected the Stack Exchange provider, got %s", provider.Name())
+	}
+}
@@ -0,0 +1,39 @@
+package search
+
+import (
+	"context"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"com.moguyn/mcp-go-search/pool"
+)
+
+// WaitRateLimit blocks until limiter allows a call. Background calls, those
+// scheduled as pool.TenantBackground, also leave half the burst to other
+// calls: while fewer tokens are available they wait for the limiter to refill,
+// so under contention they yield to calls a client is waiting on.
+func WaitRateLimit(ctx context.Context, limiter *rate.Limiter) error {
+	if pool.TenantOf(ctx) != pool.TenantBackground {
+		return limiter.Wait(ctx)
+	}
+	limit := limiter.Limit()
+	reserve := float64(limiter.Burst() / 2)
+	if limit == rate.Inf || limit <= 0 || reserve == 0 {
+		return limiter.Wait(ctx)
+	}
+	for {
+		tokens := limiter.Tokens()
+		if tokens >= reserve+1 {
+			return limiter.Wait(ctx)
+		}
+		delay := time.Duration((reserve + 1 - tokens) / float64(limit) * float64(time.Second))
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
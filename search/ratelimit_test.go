@@ -0,0 +1,48 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"com.moguyn/mcp-go-search/pool"
+)
+
+func TestWaitRateLimit(t *testing.T) {
+	background := pool.WithTenant(context.Background(), pool.TenantBackground)
+
+	// A full bucket lets background calls through at once
+	limiter := rate.NewLimiter(rate.Limit(20), 4)
+	start := time.Now()
+	if err := WaitRateLimit(background, limiter); err != nil {
+		t.Fatalf("WaitRateLimit returned an error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 20*time.Millisecond {
+		t.Errorf("Expected no wait with a full bucket, waited %v", elapsed)
+	}
+
+	// Once the bucket is drained, background calls wait for half of it to
+	// refill while interactive calls only wait for one token
+	limiter.AllowN(time.Now(), 3)
+	ctx, cancel := context.WithTimeout(background, 60*time.Millisecond)
+	defer cancel()
+	if err := WaitRateLimit(ctx, limiter); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the background call to yield, got %v", err)
+	}
+	ctx, cancel = context.WithTimeout(pool.WithTenant(context.Background(), pool.TenantInteractive), 200*time.Millisecond)
+	defer cancel()
+	if err := WaitRateLimit(ctx, limiter); err != nil {
+		t.Errorf("Expected the interactive call to go through, got %v", err)
+	}
+
+	start = time.Now()
+	if err := WaitRateLimit(background, limiter); err != nil {
+		t.Fatalf("WaitRateLimit returned an error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 80*time.Millisecond {
+		t.Errorf("Expected the background call to wait for the bucket to refill, waited %v", elapsed)
+	}
+}
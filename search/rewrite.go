@@ -0,0 +1,73 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync/atomic"
+
+	"com.moguyn/mcp-go-search/config"
+)
+
+// rewriteRule is a compiled query rewrite rule
+type rewriteRule struct {
+	pattern     *regexp.Regexp
+	replacement string
+}
+
+// Rewriter applies an ordered list of regex rewrite rules to search queries
+type Rewriter struct {
+	rules []rewriteRule
+}
+
+// NewRewriter compiles the configured rewrite rules
+func NewRewriter(rules []config.RewriteRule) (*Rewriter, error) {
+	r := &Rewriter{}
+	for i, rule := range rules {
+		pattern, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid rewrite rule %d pattern %q: %w", i, rule.Pattern, err)
+		}
+		r.rules = append(r.rules, rewriteRule{pattern: pattern, replacement: rule.Replacement})
+	}
+	return r, nil
+}
+
+// Rewrite applies every rule in order, each rule seeing the output of the previous one
+func (r *Rewriter) Rewrite(query string) string {
+	for _, rule := range r.rules {
+		query = rule.pattern.ReplaceAllString(query, rule.replacement)
+	}
+	return strings.TrimSpace(query)
+}
+
+// RewritingService wraps a Service and rewrites queries before dispatching them
+type RewritingService struct {
+	next     Service
+	rewriter atomic.Pointer[Rewriter]
+}
+
+// NewRewritingService creates a new service that rewrites queries using the rewriter
+func NewRewritingService(next Service, rewriter *Rewriter) *RewritingService {
+	s := &RewritingService{
+		next: next,
+	}
+	s.rewriter.Store(rewriter)
+	return s
+}
+
+// SetRewriter atomically replaces the rules used for subsequent searches
+func (s *RewritingService) SetRewriter(rewriter *Rewriter) {
+	s.rewriter.Store(rewriter)
+}
+
+// Capabilities returns the capabilities of the wrapped provider
+func (s *RewritingService) Capabilities() Capabilities {
+	return CapabilitiesOf(s.next)
+}
+
+// Search rewrites the query and forwards the search to the wrapped service
+func (s *RewritingService) Search(ctx context.Context, query string, freshness string, count int, summary bool) (*WebSearchResponse, error) {
+	return s.next.Search(ctx, s.rewriter.Load().Rewrite(query), freshness, count, summary)
+}
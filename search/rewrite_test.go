@@ -0,0 +1,101 @@
+package search
+
+import (
+	"context"
+	"testing"
+
+	"com.moguyn/mcp-go-search/config"
+)
+
+// recordingService is a Service that records the parameters it was called with
+type recordingService struct {
+	query     string
+	freshness string
+	count     int
+	summary   bool
+	calls     int
+	response  *WebSearchResponse
+	err       error
+}
+
+// Search records the call and returns the canned response
+func (s *recordingService) Search(_ context.Context, query string, freshness string, count int, summary bool) (*WebSearchResponse, error) {
+	s.calls++
+	s.query, s.freshness, s.count, s.summary = query, freshness, count, summary
+	if s.response == nil && s.err == nil {
+		return &WebSearchResponse{Data: Data{WebPages: WebPages{Value: []WebPageResult{}}}}, nil
+	}
+	return s.response, s.err
+}
+
+func TestRewriter(t *testing.T) {
+	rewriter, err := NewRewriter([]config.RewriteRule{
+		{Pattern: `(?i)\bproject falcon\b`, Replacement: "falcon payments platform"},
+		{Pattern: `(?i)^(.*\bkubernetes\b.*)$`, Replacement: "$1 site:kubernetes.io"},
+	})
+	if err != nil {
+		t.Fatalf("NewRewriter returned an error: %v", err)
+	}
+
+	testCases := []struct {
+		input    string
+		expected string
+	}{
+		{"Project Falcon outage", "falcon payments platform outage"},
+		{"kubernetes pod eviction", "kubernetes pod eviction site:kubernetes.io"},
+		{"unrelated query", "unrelated query"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			if result := rewriter.Rewrite(tc.input); result != tc.expected {
+				t.Errorf("Expected '%s', got '%s'", tc.expected, result)
+			}
+		})
+	}
+}
+
+func TestNewRewriter_InvalidPattern(t *testing.T) {
+	if _, err := NewRewriter([]config.RewriteRule{{Pattern: "("}}); err == nil {
+		t.Error("Expected error for invalid pattern, got nil")
+	}
+}
+
+func TestRewritingService_Search(t *testing.T) {
+	next := &recordingService{}
+	rewriter, err := NewRewriter([]config.RewriteRule{{Pattern: `\bk8s\b`, Replacement: "kubernetes"}})
+	if err != nil {
+		t.Fatalf("NewRewriter returned an error: %v", err)
+	}
+
+	service := NewRewritingService(next, rewriter)
+	if _, err := service.Search(context.Background(), "k8s ingress", "day", 5, true); err != nil {
+		t.Fatalf("Search returned an error: %v", err)
+	}
+
+	if next.query != "kubernetes ingress" {
+		t.Errorf("Expected rewritten query 'kubernetes ingress', got '%s'", next.query)
+	}
+	if next.freshness != "day" || next.count != 5 || !next.summary {
+		t.Errorf("Expected other parameters to pass through unchanged, got %s/%d/%v", next.freshness, next.count, next.summary)
+	}
+}
+
+func TestRewritingService_SetRewriter(t *testing.T) {
+	next := &recordingService{}
+	empty, _ := NewRewriter(nil)
+	service := NewRewritingService(next, empty)
+
+	_, _ = service.Search(context.Background(), "k8s", "", 10, false)
+	if next.query != "k8s" {
+		t.Errorf("Expected unchanged query, got '%s'", next.query)
+	}
+
+	rewriter, _ := NewRewriter([]config.RewriteRule{{Pattern: `\bk8s\b`, Replacement: "kubernetes"}})
+	service.SetRewriter(rewriter)
+
+	_, _ = service.Search(context.Background(), "k8s", "", 10, false)
+	if next.query != "kubernetes" {
+		t.Errorf("Expected rewritten query after swap, got '%s'", next.query)
+	}
+}
@@ -0,0 +1,59 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Router sends a search to the provider selected with WithProvider, and every
+// other search to the configured provider
+type Router struct {
+	primary   string
+	next      Service
+	providers map[string]Service
+}
+
+// NewRouter creates a router sending searches to next, the named primary
+// provider, unless they select one of providers
+func NewRouter(primary string, next Service, providers map[string]Service) *Router {
+	return &Router{
+		primary:   primary,
+		next:      next,
+		providers: providers,
+	}
+}
+
+// Capabilities returns the capabilities of the primary provider
+func (r *Router) Capabilities() Capabilities {
+	return CapabilitiesOf(r.next)
+}
+
+// Providers returns the capabilities of every provider a search may select,
+// including the primary one, by name
+func (r *Router) Providers() map[string]Capabilities {
+	providers := map[string]Capabilities{r.primary: r.Capabilities()}
+	for name, service := range r.providers {
+		providers[name] = CapabilitiesOf(service)
+	}
+	return providers
+}
+
+// Search forwards the search to the selected provider, or to the primary one
+func (r *Router) Search(ctx context.Context, query string, freshness string, count int, summary bool) (*WebSearchResponse, error) {
+	name := SelectedProvider(ctx)
+	if name == "" || name == r.primary {
+		return r.next.Search(ctx, query, freshness, count, summary)
+	}
+	provider, ok := r.providers[name]
+	if !ok {
+		names := make([]string, 0, len(r.providers)+1)
+		for known := range r.Providers() {
+			names = append(names, known)
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("search provider %s is not available, must be one of: %s", name, strings.Join(names, ", "))
+	}
+	return provider.Search(ctx, query, freshness, count, summary)
+}
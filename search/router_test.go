@@ -0,0 +1,53 @@
+package search
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRouter(t *testing.T) {
+	primary := &recordingService{}
+	brave := &recordingService{}
+	router := NewRouter("bocha", primary, map[string]Service{"brave": brave})
+	ctx := context.Background()
+
+	if _, err := router.Search(ctx, "golang", "", 10, false); err != nil {
+		t.Fatalf("Search returned an error: %v", err)
+	}
+	if _, err := router.Search(WithProvider(ctx, "bocha"), "golang", "", 10, false); err != nil {
+		t.Fatalf("Search returned an error: %v", err)
+	}
+	if _, err := router.Search(WithProvider(ctx, "brave"), "rust", "", 10, false); err != nil {
+		t.Fatalf("Search returned an error: %v", err)
+	}
+	if primary.calls != 2 || brave.calls != 1 || brave.query != "rust" {
+		t.Errorf("Expected 2 primary and 1 brave call, got %d and %d", primary.calls, brave.calls)
+	}
+
+	_, err := router.Search(WithProvider(ctx, "google"), "golang", "", 10, false)
+	if err == nil || err.Error() != "search provider google is not available, must be one of: bocha, brave" {
+		t.Errorf("Expected error for an unavailable provider, got %v", err)
+	}
+
+	providers := router.Providers()
+	if len(providers) != 2 || providers["bocha"].Provider != "search" {
+		t.Errorf("Expected the capabilities of both providers, got %v", providers)
+	}
+}
+
+func TestRouter_Caching(t *testing.T) {
+	primary := &recordingService{}
+	brave := &recordingService{}
+	cache := NewCachingService(NewRouter("bocha", primary, map[string]Service{"brave": brave}), time.Minute, 10)
+	ctx := context.Background()
+
+	for _, c := range []context.Context{ctx, WithProvider(ctx, "brave"), ctx, WithProvider(ctx, "brave")} {
+		if _, err := cache.Search(c, "golang", "noLimit", 10, false); err != nil {
+			t.Fatalf("Search returned an error: %v", err)
+		}
+	}
+	if primary.calls != 1 || brave.calls != 1 {
+		t.Errorf("Expected each provider's answer to be cached separately, got %d and %d calls", primary.calls, brave.calls)
+	}
+}
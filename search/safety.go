@@ -0,0 +1,237 @@
+package search
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Safety categories reported by the built-in keyword list
+const (
+	SafetyCategoryAdult    = "adult"
+	SafetyCategoryViolence = "violence"
+	// SafetyCategoryKeyword is reported for configured keywords
+	SafetyCategoryKeyword = "keyword"
+)
+
+// builtinSafetyKeywords are words that mark a result as unsafe, by category.
+// The list is deliberately short: it catches the obvious cases a provider's
+// SafeSearch lets through, and operators extend it or add a classifier.
+var builtinSafetyKeywords = map[string]string{
+	"porn": SafetyCategoryAdult, "porno": SafetyCategoryAdult, "xxx": SafetyCategoryAdult,
+	"nsfw": SafetyCategoryAdult, "hentai": SafetyCategoryAdult, "nude": SafetyCategoryAdult,
+	"nudes": SafetyCategoryAdult, "camgirl": SafetyCategoryAdult, "escort service": SafetyCategoryAdult,
+	"gore": SafetyCategoryViolence, "beheading": SafetyCategoryViolence, "snuff": SafetyCategoryViolence,
+	"execution video": SafetyCategoryViolence, "dismemberment": SafetyCategoryViolence,
+}
+
+// Verdict is a classifier's judgement of a single result
+type Verdict struct {
+	Unsafe   bool   `json:"unsafe"`
+	Category string `json:"category,omitempty"`
+}
+
+// Classifier judges whether results are safe to show. It returns one verdict
+// per result, in order.
+type Classifier interface {
+	Classify(ctx context.Context, results []WebPageResult) ([]Verdict, error)
+}
+
+// KeywordClassifier marks results whose title, URL or snippet contain a listed
+// word or phrase. Matching ignores case and punctuation and respects word boundaries.
+type KeywordClassifier struct {
+	keywords map[string]string
+}
+
+// NewKeywordClassifier creates a classifier from the built-in list and extra keywords
+func NewKeywordClassifier(extra []string) *KeywordClassifier {
+	c := &KeywordClassifier{keywords: make(map[string]string)}
+	for keyword, category := range builtinSafetyKeywords {
+		c.keywords[safetyText(keyword)] = category
+	}
+	for _, keyword := range extra {
+		if keyword = safetyText(keyword); strings.TrimSpace(keyword) != "" {
+			c.keywords[keyword] = SafetyCategoryKeyword
+		}
+	}
+	return c
+}
+
+// Classify checks every result against the keyword list
+func (c *KeywordClassifier) Classify(_ context.Context, results []WebPageResult) ([]Verdict, error) {
+	verdicts := make([]Verdict, len(results))
+	for i, result := range results {
+		text := safetyText(result.Name + " " + result.URL + " " + result.Snippet)
+		for keyword, category := range c.keywords {
+			if strings.Contains(text, keyword) && (!verdicts[i].Unsafe || category < verdicts[i].Category) {
+				// The smallest category wins so verdicts don't depend on map order
+				verdicts[i] = Verdict{Unsafe: true, Category: category}
+			}
+		}
+	}
+	return verdicts, nil
+}
+
+// safetyText lower-cases text and replaces punctuation with single spaces,
+// padding it so keywords can be matched as whole words
+func safetyText(text string) string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return " " + strings.Join(words, " ") + " "
+}
+
+// classifierItem is a result as sent to a classification endpoint
+type classifierItem struct {
+	Title   string `json:"title"`
+	URL     string `json:"url"`
+	Snippet string `json:"snippet"`
+}
+
+// HTTPClassifier asks a classification endpoint about results. It posts
+// {"results": [{"title", "url", "snippet"}]} and expects
+// {"verdicts": [{"unsafe", "category"}]} with one verdict per result.
+type HTTPClassifier struct {
+	url    string
+	client *http.Client
+}
+
+// NewHTTPClassifier creates a classifier backed by the endpoint at url
+func NewHTTPClassifier(url string, timeout time.Duration) *HTTPClassifier {
+	return &HTTPClassifier{
+		url:    url,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+// Classify posts the results to the endpoint
+func (c *HTTPClassifier) Classify(ctx context.Context, results []WebPageResult) ([]Verdict, error) {
+	items := make([]classifierItem, len(results))
+	for i, result := range results {
+		items[i] = classifierItem{Title: result.Name, URL: result.URL, Snippet: result.Snippet}
+	}
+	body, err := json.Marshal(map[string][]classifierItem{"results": items})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode classifier request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create classifier request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "BochaWebSearchMCPServer/1.0")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send classifier request: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("classifier returned status code %d", resp.StatusCode)
+	}
+
+	var reply struct {
+		Verdicts []Verdict `json:"verdicts"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1024*1024)).Decode(&reply); err != nil {
+		return nil, fmt.Errorf("failed to parse classifier response: %w", err)
+	}
+	if len(reply.Verdicts) != len(results) {
+		return nil, fmt.Errorf("classifier returned %d verdicts for %d results", len(reply.Verdicts), len(results))
+	}
+	return reply.Verdicts, nil
+}
+
+// SafetyService wraps a Service and runs its results through classifiers,
+// removing unsafe results or flagging them with their category. A classifier
+// that fails is skipped, so searches keep working when an endpoint is down.
+type SafetyService struct {
+	next        Service
+	classifiers []Classifier
+	remove      bool
+
+	// OnFiltered, when set, is called for every unsafe result for auditing,
+	// except in incognito searches
+	OnFiltered func(result WebPageResult, verdict Verdict, removed bool)
+	// OnError, when set, is called when a classifier fails
+	OnError func(err error)
+}
+
+// NewSafetyService creates a new service that removes unsafe results, or only
+// flags them when remove is false
+func NewSafetyService(next Service, remove bool, classifiers ...Classifier) *SafetyService {
+	return &SafetyService{
+		next:        next,
+		classifiers: classifiers,
+		remove:      remove,
+	}
+}
+
+// Capabilities returns the capabilities of the wrapped provider
+func (s *SafetyService) Capabilities() Capabilities {
+	return CapabilitiesOf(s.next)
+}
+
+// Search forwards the search to the wrapped service and filters unsafe results
+func (s *SafetyService) Search(ctx context.Context, query string, freshness string, count int, summary bool) (*WebSearchResponse, error) {
+	response, err := s.next.Search(ctx, query, freshness, count, summary)
+	if err != nil {
+		return nil, err
+	}
+	results := response.Data.WebPages.Value
+	if len(results) == 0 {
+		return response, nil
+	}
+
+	verdicts := make([]Verdict, len(results))
+	for _, classifier := range s.classifiers {
+		found, err := classifier.Classify(ctx, results)
+		if err != nil {
+			if s.OnError != nil {
+				s.OnError(err)
+			}
+			continue
+		}
+		for i, verdict := range found {
+			if verdict.Unsafe && !verdicts[i].Unsafe {
+				verdicts[i] = verdict
+			}
+		}
+	}
+
+	// Never modify the wrapped service's response, which may be cached
+	kept := make([]WebPageResult, 0, len(results))
+	changed := false
+	for i, result := range results {
+		verdict := verdicts[i]
+		if !verdict.Unsafe {
+			kept = append(kept, result)
+			continue
+		}
+		changed = true
+		if verdict.Category == "" {
+			verdict.Category = "unsafe"
+		}
+		if s.OnFiltered != nil && !Incognito(ctx) {
+			s.OnFiltered(result, verdict, s.remove)
+		}
+		if !s.remove {
+			result.SafetyFlag = verdict.Category
+			kept = append(kept, result)
+		}
+	}
+	if !changed {
+		return response, nil
+	}
+
+	filtered := *response
+	filtered.Data.WebPages.Value = kept
+	return &filtered, nil
+}
@@ -0,0 +1,127 @@
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func safetyResults() []WebPageResult {
+	return []WebPageResult{
+		{Name: "Go generics tutorial", URL: "https://go.dev/doc/tutorial/generics"},
+		{Name: "Free NSFW pictures", URL: "https://example.com/pics"},
+		{Name: "Raw footage", URL: "https://example.com/v", Snippet: "Uncensored GORE compilation"},
+		{Name: "Goren's blog", URL: "https://example.com/goren", Snippet: "Pornography laws explained"},
+	}
+}
+
+func TestKeywordClassifier(t *testing.T) {
+	classifier := NewKeywordClassifier([]string{"Casino Bonus"})
+	results := append(safetyResults(), WebPageResult{Name: "Best casino-bonus codes", URL: "https://example.com/c"})
+
+	verdicts, err := classifier.Classify(context.Background(), results)
+	if err != nil {
+		t.Fatalf("Classify returned an error: %v", err)
+	}
+	expected := []Verdict{
+		{},
+		{Unsafe: true, Category: SafetyCategoryAdult},
+		{Unsafe: true, Category: SafetyCategoryViolence},
+		{}, // keywords only match whole words
+		{Unsafe: true, Category: SafetyCategoryKeyword},
+	}
+	for i, want := range expected {
+		if verdicts[i] != want {
+			t.Errorf("Expected %+v for %q, got %+v", want, results[i].Name, verdicts[i])
+		}
+	}
+}
+
+func TestSafetyService(t *testing.T) {
+	next := &recordingService{response: &WebSearchResponse{Data: Data{WebPages: WebPages{Value: safetyResults()}}}}
+
+	var audited []string
+	remove := NewSafetyService(next, true, NewKeywordClassifier(nil))
+	remove.OnFiltered = func(result WebPageResult, verdict Verdict, removed bool) {
+		if removed {
+			audited = append(audited, verdict.Category+" "+result.URL)
+		}
+	}
+	response, err := remove.Search(context.Background(), "golang", "", 10, false)
+	if err != nil {
+		t.Fatalf("Search returned an error: %v", err)
+	}
+	if len(response.Data.WebPages.Value) != 2 {
+		t.Errorf("Expected 2 safe results, got %+v", response.Data.WebPages.Value)
+	}
+	if strings.Join(audited, ",") != "adult https://example.com/pics,violence https://example.com/v" {
+		t.Errorf("Expected both removals to be audited, got %q", audited)
+	}
+
+	flag := NewSafetyService(next, false, NewKeywordClassifier(nil))
+	response, err = flag.Search(context.Background(), "golang", "", 10, false)
+	if err != nil {
+		t.Fatalf("Search returned an error: %v", err)
+	}
+	if len(response.Data.WebPages.Value) != 4 || response.Data.WebPages.Value[1].SafetyFlag != SafetyCategoryAdult {
+		t.Errorf("Expected every result with the unsafe one flagged, got %+v", response.Data.WebPages.Value)
+	}
+	if next.response.Data.WebPages.Value[1].SafetyFlag != "" {
+		t.Error("Expected the wrapped response not to be modified")
+	}
+}
+
+func TestHTTPClassifier(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			Results []classifierItem `json:"results"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		verdicts := make([]Verdict, len(request.Results))
+		for i, item := range request.Results {
+			if strings.Contains(item.URL, "/v") {
+				verdicts[i] = Verdict{Unsafe: true, Category: "violence"}
+			}
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"verdicts": verdicts})
+	}))
+	defer server.Close()
+
+	verdicts, err := NewHTTPClassifier(server.URL, 5*time.Second).Classify(context.Background(), safetyResults())
+	if err != nil {
+		t.Fatalf("Classify returned an error: %v", err)
+	}
+	if len(verdicts) != 4 || !verdicts[2].Unsafe || verdicts[0].Unsafe {
+		t.Errorf("Unexpected verdicts: %+v", verdicts)
+	}
+}
+
+func TestSafetyService_ClassifierFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	next := &recordingService{response: &WebSearchResponse{Data: Data{WebPages: WebPages{Value: safetyResults()}}}}
+	service := NewSafetyService(next, true, NewHTTPClassifier(server.URL, 5*time.Second), NewKeywordClassifier(nil))
+	failures := 0
+	service.OnError = func(error) { failures++ }
+
+	response, err := service.Search(context.Background(), "golang", "", 10, false)
+	if err != nil {
+		t.Fatalf("Expected the search to succeed without the classifier, got %v", err)
+	}
+	if failures != 1 {
+		t.Errorf("Expected 1 classifier failure, got %d", failures)
+	}
+	if len(response.Data.WebPages.Value) != 2 {
+		t.Errorf("Expected the keyword classifier to still apply, got %d results", len(response.Data.WebPages.Value))
+	}
+}
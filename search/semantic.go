@@ -0,0 +1,313 @@
+package search
+
+import (
+	"context"
+	"hash/fnv"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// DefaultEmbeddingDims is the vector size produced by the built-in embedder
+const DefaultEmbeddingDims = 512
+
+// Embedder turns text into a vector whose cosine similarity to other vectors
+// reflects how close the texts are in meaning
+type Embedder interface {
+	Embed(ctx context.Context, text string) ([]float64, error)
+}
+
+// HashEmbedder is a dependency-free Embedder that hashes words and character
+// trigrams into a fixed-size vector. It captures lexical rather than semantic
+// similarity: questions differing in a single word score high, so it needs a
+// strict threshold. HTTPEmbedder calls a real embedding model instead.
+type HashEmbedder struct {
+	Dims int
+}
+
+// stopWords are ignored when embedding so filler words don't dominate short questions
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "can": true, "do": true,
+	"does": true, "for": true, "how": true, "i": true, "in": true, "is": true,
+	"it": true, "me": true, "of": true, "on": true, "please": true, "s": true,
+	"the": true, "to": true, "was": true, "what": true, "which": true,
+	"who": true, "why": true, "with": true,
+}
+
+// Embed returns the L2-normalized hashed feature vector of text
+func (e HashEmbedder) Embed(_ context.Context, text string) ([]float64, error) {
+	dims := e.Dims
+	if dims < 1 {
+		dims = DefaultEmbeddingDims
+	}
+	vector := make([]float64, dims)
+
+	for _, word := range queryWords(strings.ToLower(text)) {
+		if stopWords[word] {
+			continue
+		}
+		addFeature(vector, "w:"+word, 1)
+
+		// Trigrams make plurals and other inflections land close together
+		runes := []rune("^" + word + "$")
+		for i := 0; i+3 <= len(runes); i++ {
+			addFeature(vector, "t:"+string(runes[i:i+3]), 0.5)
+		}
+	}
+
+	normalize(vector)
+	return vector, nil
+}
+
+// queryWords splits text into words of letters and digits
+func queryWords(text string) []string {
+	return strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// keyTerms are the parts of a question whose change changes the answer however
+// similar the rest is: its numbers, such as years and versions, and its
+// capitalized names
+type keyTerms struct {
+	numbers  string
+	entities []string
+	words    map[string]bool
+}
+
+// keyTermsOf extracts the key terms of a question
+func keyTermsOf(query string) keyTerms {
+	terms := keyTerms{words: make(map[string]bool)}
+	var numbers []string
+	for _, word := range queryWords(query) {
+		lower := strings.ToLower(word)
+		terms.words[lower] = true
+		switch {
+		case strings.IndexFunc(word, unicode.IsDigit) >= 0:
+			numbers = append(numbers, lower)
+		case unicode.IsUpper([]rune(word)[0]) && !stopWords[lower]:
+			terms.entities = append(terms.entities, lower)
+		}
+	}
+	sort.Strings(numbers)
+	terms.numbers = strings.Join(numbers, " ")
+	return terms
+}
+
+// matches reports whether two questions share their key terms: the same
+// numbers, and each one's names appear in the other, in any case
+func (k keyTerms) matches(other keyTerms) bool {
+	if k.numbers != other.numbers {
+		return false
+	}
+	for _, entity := range k.entities {
+		if !other.words[entity] {
+			return false
+		}
+	}
+	for _, entity := range other.entities {
+		if !k.words[entity] {
+			return false
+		}
+	}
+	return true
+}
+
+// addFeature adds weight to the dimension feature hashes to. The hash sign
+// spreads collisions so they cancel out rather than accumulate.
+func addFeature(vector []float64, feature string, weight float64) {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(feature))
+	sum := h.Sum64()
+	if sum&1 == 1 {
+		weight = -weight
+	}
+	vector[(sum>>1)%uint64(len(vector))] += weight
+}
+
+// normalize scales vector to unit length in place
+func normalize(vector []float64) {
+	var norm float64
+	for _, v := range vector {
+		norm += v * v
+	}
+	if norm == 0 {
+		return
+	}
+	norm = math.Sqrt(norm)
+	for i := range vector {
+		vector[i] /= norm
+	}
+}
+
+// cosineSimilarity returns the cosine of the angle between a and b
+func cosineSimilarity(a, b []float64) float64 {
+	if len(a) != len(b) {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += a[i] * b[i]
+		normA += a[i] * a[i]
+		normB += b[i] * b[i]
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
+
+// semanticEntry is a previously answered question
+type semanticEntry struct {
+	vector    []float64
+	terms     keyTerms
+	freshness string
+	count     int
+	response  *WebSearchResponse
+	expiresAt time.Time
+}
+
+// SemanticCache wraps a Service and answers summary searches from earlier
+// answers to sufficiently similar questions. Plain searches pass through.
+type SemanticCache struct {
+	next       Service
+	embedder   Embedder
+	threshold  float64
+	ttl        time.Duration
+	maxEntries int
+
+	mu      sync.Mutex
+	entries []semanticEntry
+	hits    uint64
+	misses  uint64
+	now     func() time.Time
+}
+
+// NewSemanticCache creates a new semantic cache. A cached answer is reused
+// when its question's similarity to the new one is at least threshold (0-1).
+// Answers are kept for ttl; the oldest is evicted once maxEntries is reached.
+func NewSemanticCache(next Service, embedder Embedder, threshold float64, ttl time.Duration, maxEntries int) *SemanticCache {
+	if embedder == nil {
+		embedder = HashEmbedder{}
+	}
+	return &SemanticCache{
+		next:       next,
+		embedder:   embedder,
+		threshold:  threshold,
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		now:        time.Now,
+	}
+}
+
+// Search returns the answer to the most similar cached question when one
+// clears the threshold, otherwise it forwards the search and caches the answer
+func (s *SemanticCache) Search(ctx context.Context, query string, freshness string, count int, summary bool) (*WebSearchResponse, error) {
+	// A similar question is no substitute for an exact query or count, or for
+	// another provider's or endpoint's answer, and incognito questions are never kept
+	if !summary || ExactQuery(ctx) || ExactCount(ctx) || Pro(ctx) || AISearch(ctx) || Agent(ctx) != "" || SelectedProvider(ctx) != "" || SelectedProviders(ctx) != nil || Incognito(ctx) {
+		return s.next.Search(ctx, query, freshness, count, summary)
+	}
+
+	vector, err := s.embedder.Embed(ctx, query)
+	if err != nil {
+		// The cache is an optimization; answer the question without it
+		return s.next.Search(ctx, query, freshness, count, summary)
+	}
+	freshness = strings.ToLower(strings.TrimSpace(freshness))
+
+	terms := keyTermsOf(query)
+	if response := s.lookup(vector, terms, freshness, count); response != nil {
+		return response, nil
+	}
+
+	response, err := s.next.Search(ctx, query, freshness, count, summary)
+	if err != nil || response.Meta.Degraded {
+		return response, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.expire()
+	if s.maxEntries > 0 && len(s.entries) >= s.maxEntries {
+		s.entries = s.entries[1:]
+	}
+	s.entries = append(s.entries, semanticEntry{
+		vector:    vector,
+		terms:     terms,
+		freshness: freshness,
+		count:     count,
+		response:  response,
+		expiresAt: s.now().Add(s.ttl),
+	})
+
+	return response, nil
+}
+
+// lookup returns the cached answer most similar to vector, or nil if none
+// clears the threshold. Answers must share the key terms and the freshness
+// filter and have been generated from at least count results.
+func (s *SemanticCache) lookup(vector []float64, terms keyTerms, freshness string, count int) *WebSearchResponse {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	var best *semanticEntry
+	bestScore := s.threshold
+	for i := range s.entries {
+		entry := &s.entries[i]
+		if entry.freshness != freshness || entry.count < count || !now.Before(entry.expiresAt) || !entry.terms.matches(terms) {
+			continue
+		}
+		if score := cosineSimilarity(vector, entry.vector); score >= bestScore {
+			best, bestScore = entry, score
+		}
+	}
+
+	if best == nil {
+		s.misses++
+		return nil
+	}
+	s.hits++
+	return markCached(limitResults(best.response, count))
+}
+
+// expire drops answers past their TTL. The caller must hold s.mu.
+func (s *SemanticCache) expire() {
+	now := s.now()
+	kept := s.entries[:0]
+	for _, entry := range s.entries {
+		if now.Before(entry.expiresAt) {
+			kept = append(kept, entry)
+		}
+	}
+	s.entries = kept
+}
+
+// Capabilities returns the capabilities of the wrapped provider
+func (s *SemanticCache) Capabilities() Capabilities {
+	return CapabilitiesOf(s.next)
+}
+
+// Flush removes every cached answer and returns how many were removed
+func (s *SemanticCache) Flush() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := len(s.entries)
+	s.entries = nil
+	return n
+}
+
+// Stats returns the current cache counters
+func (s *SemanticCache) Stats() CacheStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return CacheStats{
+		Entries: len(s.entries),
+		Hits:    s.hits,
+		Misses:  s.misses,
+	}
+}
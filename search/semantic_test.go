@@ -0,0 +1,121 @@
+package search
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestHashEmbedder(t *testing.T) {
+	embed := func(text string) []float64 {
+		vector, err := HashEmbedder{}.Embed(context.Background(), text)
+		if err != nil {
+			t.Fatalf("Embed returned an error: %v", err)
+		}
+		return vector
+	}
+
+	question := embed("What is the capital of France?")
+	if score := cosineSimilarity(question, embed("capital of france")); score < 0.99 {
+		t.Errorf("Expected stop words and case to be ignored, got similarity %.3f", score)
+	}
+	if score := cosineSimilarity(question, embed("What is the capital of Germany?")); score > 0.7 {
+		t.Errorf("Expected different questions to be dissimilar, got similarity %.3f", score)
+	}
+	if score := cosineSimilarity(embed("latest rust release"), embed("latest rust releases")); score < 0.8 {
+		t.Errorf("Expected inflections to be similar, got similarity %.3f", score)
+	}
+}
+
+func TestSemanticCache(t *testing.T) {
+	next := &recordingService{response: &WebSearchResponse{Data: Data{WebPages: WebPages{
+		WebSearchURL: "https://example.com/answer",
+		Value:        []WebPageResult{{Name: "Paris"}, {Name: "France"}},
+	}}}}
+	cache := NewSemanticCache(next, nil, 0.9, time.Hour, 10)
+	ctx := context.Background()
+
+	if _, err := cache.Search(ctx, "What is the capital of France?", "", 2, true); err != nil {
+		t.Fatalf("Search returned an error: %v", err)
+	}
+	response, err := cache.Search(ctx, "capital of France", "", 1, true)
+	if err != nil {
+		t.Fatalf("Search returned an error: %v", err)
+	}
+	if next.calls != 1 {
+		t.Errorf("Expected a similar question to be answered from the cache, got %d calls", next.calls)
+	}
+	if len(response.Data.WebPages.Value) != 1 {
+		t.Errorf("Expected 1 result, got %d", len(response.Data.WebPages.Value))
+	}
+
+	// Dissimilar questions, other freshness filters, larger counts and
+	// plain searches all go upstream
+	_, _ = cache.Search(ctx, "capital of Germany", "", 2, true)
+	_, _ = cache.Search(ctx, "capital of France", "week", 2, true)
+	_, _ = cache.Search(ctx, "capital of France", "", 5, true)
+	_, _ = cache.Search(ctx, "capital of France", "", 2, false)
+	if next.calls != 5 {
+		t.Errorf("Expected 5 upstream calls, got %d", next.calls)
+	}
+
+	stats := cache.Stats()
+	if stats.Hits != 1 || stats.Misses != 4 || stats.Entries != 4 {
+		t.Errorf("Expected 1 hit, 4 misses and 4 entries, got %+v", stats)
+	}
+}
+
+func TestSemanticCache_ExpiryAndEviction(t *testing.T) {
+	next := &recordingService{}
+	cache := NewSemanticCache(next, nil, 0.9, time.Minute, 2)
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	_, _ = cache.Search(ctx, "golang generics", "", 10, true)
+	_, _ = cache.Search(ctx, "rust lifetimes", "", 10, true)
+	_, _ = cache.Search(ctx, "zig comptime", "", 10, true)
+	if stats := cache.Stats(); stats.Entries != 2 {
+		t.Errorf("Expected 2 entries after eviction, got %d", stats.Entries)
+	}
+	_, _ = cache.Search(ctx, "golang generics", "", 10, true)
+	if next.calls != 4 {
+		t.Errorf("Expected the oldest answer to be evicted, got %d calls", next.calls)
+	}
+
+	now = now.Add(2 * time.Minute)
+	_, _ = cache.Search(ctx, "zig comptime", "", 10, true)
+	if next.calls != 5 {
+		t.Errorf("Expected the expired answer to be refreshed, got %d calls", next.calls)
+	}
+	if n := cache.Flush(); n != 1 {
+		t.Errorf("Expected 1 flushed entry, got %d", n)
+	}
+}
+
+func TestSemanticCache_KeyTerms(t *testing.T) {
+	next := &recordingService{}
+	// Even under a threshold loose enough to match these pairs by wording,
+	// differing numbers and names keep them apart
+	cache := NewSemanticCache(next, nil, 0.8, time.Hour, 10)
+	ctx := context.Background()
+
+	pairs := [][2]string{
+		{"python 3.11 release date", "python 3.12 release date"},
+		{"who won the 2022 World Cup", "who won the 2018 World Cup"},
+		{"weather in Paris today", "weather in Lyon today"},
+	}
+	for _, pair := range pairs {
+		_, _ = cache.Search(ctx, pair[0], "", 2, true)
+		_, _ = cache.Search(ctx, pair[1], "", 2, true)
+	}
+	if next.calls != 2*len(pairs) {
+		t.Errorf("Expected questions about other numbers or names to go upstream, got %d calls", next.calls)
+	}
+
+	// Rephrasings with the same numbers and names still hit
+	_, _ = cache.Search(ctx, "What is the python 3.11 release date?", "", 2, true)
+	if next.calls != 2*len(pairs) {
+		t.Errorf("Expected a rephrased question to be answered from the cache, got %d calls", next.calls)
+	}
+}
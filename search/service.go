@@ -1,28 +1,10 @@
 package search
 
 import (
-	"bytes"
 	"context"
-	"crypto/tls"
-	"encoding/json"
-	"fmt"
-	"io"
-	"net/http"
-	"time"
-
-	"golang.org/x/time/rate"
-
-	"com.moguyn/mcp-go-search/config"
+	"errors"
 )
 
-// WebSearchRequest represents the request structure for the Bocha Web Search API
-type WebSearchRequest struct {
-	Query     string `json:"query"`
-	Freshness string `json:"freshness"`
-	Count     int    `json:"count"`
-	Summary   bool   `json:"summary"`
-}
-
 // WebPageResult represents a single web page result from the Bocha Web Search API
 type WebPageResult struct {
 	ID               string `json:"id"`
@@ -37,6 +19,20 @@ type WebPageResult struct {
 	Language         any    `json:"language"`
 	IsFamilyFriendly any    `json:"isFamilyFriendly"`
 	IsNavigational   any    `json:"isNavigational"`
+
+	// Summary is a longer summary of the page, for providers that write one
+	// when a search asks for summaries
+	Summary string `json:"summary,omitempty"`
+
+	// Authors and PDFURL describe research papers, for providers that return them
+	Authors []string `json:"authors,omitempty"`
+	PDFURL  string   `json:"pdfUrl,omitempty"`
+
+	// SafetyFlag is the category of a result flagged by the safety filter
+	SafetyFlag string `json:"safetyFlag,omitempty"`
+
+	// RichSnippet holds structured details of the page, for pro endpoints that return them
+	RichSnippet *RichSnippet `json:"richSnippet,omitempty"`
 }
 
 // WebPages represents the web pages section of the search response
@@ -72,9 +68,87 @@ type Images struct {
 	IsFamilyFriendly any           `json:"isFamilyFriendly"`
 }
 
+// VideoResult represents a single video result from the Bocha Web Search API
+type VideoResult struct {
+	WebSearchURL       any    `json:"webSearchUrl"`
+	Name               string `json:"name"`
+	Description        string `json:"description"`
+	ThumbnailURL       string `json:"thumbnailUrl"`
+	Publisher          []Org  `json:"publisher"`
+	Creator            *Org   `json:"creator"`
+	ContentURL         string `json:"contentUrl"`
+	HostPageURL        string `json:"hostPageUrl"`
+	HostPageDisplayURL string `json:"hostPageDisplayUrl"`
+	EncodingFormat     any    `json:"encodingFormat"`
+	Width              int    `json:"width"`
+	Height             int    `json:"height"`
+	// Duration is an ISO 8601 duration such as PT4M13S, or a clock time such as 4:13
+	Duration           string `json:"duration"`
+	DatePublished      string `json:"datePublished"`
+	ViewCount          int    `json:"viewCount"`
+	MotionThumbnailURL any    `json:"motionThumbnailUrl"`
+	EmbedHTML          any    `json:"embedHtml"`
+}
+
+// Org names the publisher or creator of a video
+type Org struct {
+	Name string `json:"name"`
+}
+
+// Videos represents the videos section of the search response
+type Videos struct {
+	ID               any           `json:"id"`
+	ReadLink         any           `json:"readLink"`
+	WebSearchURL     any           `json:"webSearchUrl"`
+	Value            []VideoResult `json:"value"`
+	IsFamilyFriendly any           `json:"isFamilyFriendly"`
+}
+
+// Question is a related question with its answer, as shown in "people also ask" boxes
+type Question struct {
+	Question string `json:"question"`
+	Answer   string `json:"answer"`
+	URL      string `json:"url,omitempty"`
+	Title    string `json:"title,omitempty"`
+}
+
+// Entity is a knowledge panel describing the person, place or thing a query is about
+type Entity struct {
+	Name        string `json:"name"`
+	Type        string `json:"type,omitempty"`
+	Description string `json:"description,omitempty"`
+	// URL is the entity's official site
+	URL string `json:"url,omitempty"`
+	// Source names where the description comes from, e.g. Wikipedia
+	Source string `json:"source,omitempty"`
+	Facts  []Fact `json:"facts,omitempty"`
+}
+
+// Answer is a direct answer to the query, written from the results
+type Answer struct {
+	Text string `json:"text"`
+	// Sources are the URLs of the results the answer is based on
+	Sources []string `json:"sources,omitempty"`
+}
+
+// RichSnippet is structured data about a page, such as the rating of a
+// product or the cooking time of a recipe
+type RichSnippet struct {
+	Type  string `json:"type"`
+	Facts []Fact `json:"facts,omitempty"`
+}
+
+// Fact is a labeled attribute of an entity, such as "Founded: 2009"
+type Fact struct {
+	Label string `json:"label"`
+	Value string `json:"value"`
+}
+
 // QueryContext represents the query context section of the search response
 type QueryContext struct {
 	OriginalQuery string `json:"originalQuery"`
+	// AlteredQuery is the spell-corrected query the results are for, when the provider changed it
+	AlteredQuery string `json:"alteredQuery,omitempty"`
 }
 
 // Data represents the data section of the search response
@@ -83,7 +157,16 @@ type Data struct {
 	QueryContext QueryContext `json:"queryContext"`
 	WebPages     WebPages     `json:"webPages"`
 	Images       Images       `json:"images,omitempty"`
-	Videos       any          `json:"videos"`
+	Videos       Videos       `json:"videos,omitempty"`
+
+	// Entity is the knowledge panel, for providers that return one
+	Entity *Entity `json:"entity,omitempty"`
+	// PeopleAlsoAsk holds FAQ-style related questions, for providers that return them
+	PeopleAlsoAsk []Question `json:"peopleAlsoAsk,omitempty"`
+	// Answer is the direct answer, for pro endpoints that return one
+	Answer *Answer `json:"answer,omitempty"`
+	// Cards are the modal cards, such as the weather, of AI searches that return them
+	Cards []Card `json:"cards,omitempty"`
 }
 
 // WebSearchResponse represents the response structure from the Bocha Web Search API
@@ -92,155 +175,72 @@ type WebSearchResponse struct {
 	LogID string `json:"log_id"`
 	Msg   any    `json:"msg"`
 	Data  Data   `json:"data"`
-}
 
-// Service defines the interface for search operations
-type Service interface {
-	Search(ctx context.Context, query string, freshness string, count int, summary bool) (*WebSearchResponse, error)
+	// Meta describes how the response was obtained; it is not part of the API payload
+	Meta ResponseMeta `json:"-"`
 }
 
-// BochaService implements the Service interface for Bocha Web Search API
-type BochaService struct {
-	apiKey      string
-	apiBaseURL  string
-	httpClient  *http.Client
-	rateLimiter *rate.Limiter
+// ResponseMeta carries provider-side details about a search call
+type ResponseMeta struct {
+	// Payload sizes exchanged with the upstream API
+	BytesSent     int64
+	BytesReceived int64
+
+	// QueryTruncated reports that the query was shortened to the maximum length
+	QueryTruncated bool
+	// CountClamped reports that the requested count was outside the supported range
+	CountClamped bool
+	// Cached reports that the response was served from a cache instead of the provider
+	Cached bool
+	// Degraded reports that some providers of an aggregated search failed, so
+	// the results are partial; Warnings says which providers and why
+	Degraded bool
+	Warnings []string
 }
 
-// NewBochaService creates a new instance of the BochaService
-func NewBochaService() *BochaService {
-	return NewBochaServiceWithConfig(config.New())
+// TransferError is a failed search that still exchanged data with the
+// provider, e.g. an error status with a body, so its traffic can be counted
+type TransferError struct {
+	Err           error
+	BytesSent     int64
+	BytesReceived int64
 }
 
-// NewBochaServiceWithConfig creates a new instance of the BochaService with the provided configuration
-func NewBochaServiceWithConfig(cfg *config.Config) *BochaService {
-	// Create a secure transport with modern TLS configuration
-	transport := &http.Transport{
-		TLSClientConfig: &tls.Config{
-			MinVersion: tls.VersionTLS12,
-		},
-		ForceAttemptHTTP2: true,
-		MaxIdleConns:      100,
-		IdleConnTimeout:   90 * time.Second,
-	}
-
-	// Create a rate limiter that allows 10 requests per second with a burst of 20
-	limiter := rate.NewLimiter(rate.Limit(10), 20)
-
-	return &BochaService{
-		apiKey:     cfg.BochaAPIKey,
-		apiBaseURL: cfg.BochaAPIBaseURL,
-		httpClient: &http.Client{
-			Timeout:   cfg.HTTPTimeout,
-			Transport: transport,
-		},
-		rateLimiter: limiter,
-	}
+// Error implements the error interface
+func (e *TransferError) Error() string {
+	return e.Err.Error()
 }
 
-// Search performs a search using the Bocha Web Search API
-func (s *BochaService) Search(ctx context.Context, query string, freshness string, count int, summary bool) (*WebSearchResponse, error) {
-	// Apply rate limiting
-	if err := s.rateLimiter.Wait(ctx); err != nil {
-		return nil, fmt.Errorf("rate limit exceeded: %w", err)
-	}
-
-	// Validate inputs
-	if query == "" {
-		return nil, fmt.Errorf("search query cannot be empty")
-	}
-
-	// Sanitize the query to prevent potential injection attacks
-	query = sanitizeQuery(query)
-
-	// Validate freshness parameter if provided
-	if freshness != "" && freshness != "noLimit" && freshness != "day" && freshness != "week" && freshness != "month" && freshness != "oneYear" {
-		return nil, fmt.Errorf("invalid freshness value: %q, must be one of: noLimit, day, week, month, oneYear", freshness)
-	}
-
-	if count < 1 {
-		count = 1
-	} else if count > 50 {
-		count = 50
-	}
-
-	// Create the request payload
-	reqBody := WebSearchRequest{
-		Query:     query,
-		Freshness: freshness,
-		Count:     count,
-		Summary:   summary,
-	}
-
-	// Convert the request to JSON
-	jsonData, err := json.Marshal(reqBody)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request: %w", err)
-	}
-
-	// Create the HTTP request
-	req, err := http.NewRequestWithContext(ctx, "POST", s.apiBaseURL, bytes.NewBuffer(jsonData))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create HTTP request: %w", err)
-	}
-
-	// Set headers
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", s.apiKey))
-	req.Header.Set("User-Agent", "BochaWebSearchMCPServer/1.0")
-
-	// Send the request
-	resp, err := s.httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to send request to Bocha API: %w", err)
-	}
-	defer resp.Body.Close()
-
-	// Read the response body with a size limit to prevent memory exhaustion
-	body, err := io.ReadAll(io.LimitReader(resp.Body, 10*1024*1024)) // 10MB limit
-	if err != nil {
-		return nil, fmt.Errorf("failed to read Bocha API response body: %w", err)
-	}
-
-	// Check for non-200 status code
-	if resp.StatusCode != http.StatusOK {
-		// Try to extract error message from response if possible
-		var errorResp struct {
-			Error string `json:"error"`
-		}
-		if err := json.Unmarshal(body, &errorResp); err == nil && errorResp.Error != "" {
-			return nil, fmt.Errorf("bocha api error (status %d): %s", resp.StatusCode, errorResp.Error)
-		}
-
-		// Don't return the full response body in case of error to avoid leaking sensitive information
-		return nil, fmt.Errorf("bocha api returned status code %d", resp.StatusCode)
-	}
+// Unwrap returns the underlying error
+func (e *TransferError) Unwrap() error {
+	return e.Err
+}
 
-	// Parse the response
-	var searchResp WebSearchResponse
-	if err := json.Unmarshal(body, &searchResp); err != nil {
-		return nil, fmt.Errorf("failed to parse bocha api response: %w", err)
+// WithTransfer attaches the payload sizes a failed call exchanged to err
+func WithTransfer(err error, sent, received int64) error {
+	if err == nil {
+		return nil
 	}
+	return &TransferError{Err: err, BytesSent: sent, BytesReceived: received}
+}
 
-	// Validate response
-	if searchResp.Data.WebPages.Value == nil {
-		return nil, fmt.Errorf("bocha api returned empty or invalid response")
+// Transferred returns the payload sizes attached to err by WithTransfer
+func Transferred(err error) (sent, received int64) {
+	var transferErr *TransferError
+	if errors.As(err, &transferErr) {
+		return transferErr.BytesSent, transferErr.BytesReceived
 	}
-
-	return &searchResp, nil
+	return 0, 0
 }
 
-// sanitizeQuery performs basic sanitization on the search query
-// to prevent potential injection attacks
-func sanitizeQuery(query string) string {
-	// This is a simple implementation - in a production environment,
-	// you might want to use a more sophisticated sanitization library
-
-	// Limit query length to prevent DoS attacks
-	const maxQueryLength = 1000
-	if len(query) > maxQueryLength {
-		query = query[:maxQueryLength]
-	}
+// Service defines the interface for search operations
+type Service interface {
+	Search(ctx context.Context, query string, freshness string, count int, summary bool) (*WebSearchResponse, error)
+}
 
-	return query
+// RateLimitStats describes the state of the upstream rate limiter
+type RateLimitStats struct {
+	Limit     float64 `json:"limit_per_second"`
+	Burst     int     `json:"burst"`
+	Available float64 `json:"available_tokens"`
 }
@@ -0,0 +1,52 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+)
+
+// ToggleService wraps a provider so it can be enabled or disabled at runtime
+type ToggleService struct {
+	name    string
+	next    Service
+	enabled atomic.Bool
+}
+
+// NewToggleService creates a new, enabled, toggleable service for the named provider
+func NewToggleService(name string, next Service) *ToggleService {
+	s := &ToggleService{
+		name: name,
+		next: next,
+	}
+	s.enabled.Store(true)
+	return s
+}
+
+// Name returns the provider name
+func (s *ToggleService) Name() string {
+	return s.name
+}
+
+// Enabled reports whether the provider is currently enabled
+func (s *ToggleService) Enabled() bool {
+	return s.enabled.Load()
+}
+
+// SetEnabled enables or disables the provider
+func (s *ToggleService) SetEnabled(enabled bool) {
+	s.enabled.Store(enabled)
+}
+
+// Capabilities returns the capabilities of the wrapped provider
+func (s *ToggleService) Capabilities() Capabilities {
+	return CapabilitiesOf(s.next)
+}
+
+// Search forwards the search to the wrapped provider if it is enabled
+func (s *ToggleService) Search(ctx context.Context, query string, freshness string, count int, summary bool) (*WebSearchResponse, error) {
+	if !s.Enabled() {
+		return nil, fmt.Errorf("search provider %s is disabled", s.name)
+	}
+	return s.next.Search(ctx, query, freshness, count, summary)
+}
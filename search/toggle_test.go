@@ -0,0 +1,83 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"com.moguyn/mcp-go-search/params"
+	"com.moguyn/mcp-go-search/stats"
+)
+
+func TestToggleService(t *testing.T) {
+	next := &recordingService{}
+	service := NewToggleService("bocha", next)
+
+	if !service.Enabled() {
+		t.Error("Expected service to be enabled by default")
+	}
+	if _, err := service.Search(context.Background(), "a", "", 10, false); err != nil {
+		t.Errorf("Expected no error while enabled, got %v", err)
+	}
+
+	service.SetEnabled(false)
+	if _, err := service.Search(context.Background(), "a", "", 10, false); err == nil {
+		t.Error("Expected error while disabled, got nil")
+	}
+	if next.calls != 1 {
+		t.Errorf("Expected disabled provider not to be called, got %d calls", next.calls)
+	}
+}
+
+func TestInstrumentedService(t *testing.T) {
+	collector := stats.NewCollector()
+	next := &recordingService{}
+	service := NewInstrumentedService("bocha", next, collector)
+
+	_, _ = service.Search(context.Background(), "a", "", 10, false)
+	next.err = errors.New("boom")
+	_, _ = service.Search(context.Background(), "a", "", 10, false)
+
+	snapshot := collector.Snapshot()
+	if snapshot.Queries != 2 || snapshot.Errors != 1 {
+		t.Errorf("Expected 2 queries and 1 error, got %d and %d", snapshot.Queries, snapshot.Errors)
+	}
+}
+
+func TestInstrumentedService_RecordsResponseMeta(t *testing.T) {
+	collector := stats.NewCollector()
+	next := &recordingService{response: &WebSearchResponse{
+		Data: Data{WebPages: WebPages{Value: []WebPageResult{{Name: "a"}, {Name: "b"}}}},
+		Meta: ResponseMeta{BytesSent: 10, BytesReceived: 200, QueryTruncated: true},
+	}}
+	service := NewInstrumentedService("bocha", next, collector)
+
+	_, _ = service.Search(context.Background(), "a", "", 10, false)
+
+	snapshot := collector.Snapshot()
+	if snapshot.BytesSent != 10 || snapshot.BytesReceived != 200 {
+		t.Errorf("Expected byte counts from response meta, got %d and %d", snapshot.BytesSent, snapshot.BytesReceived)
+	}
+	if snapshot.AverageResults != 2 || snapshot.QueryTruncations != 1 {
+		t.Errorf("Expected 2 results and 1 truncation, got %f and %d", snapshot.AverageResults, snapshot.QueryTruncations)
+	}
+}
+
+func TestInstrumentedService_RecordsAdjustmentsAndFailedTransfers(t *testing.T) {
+	collector := stats.NewCollector()
+	next := &recordingService{err: WithTransfer(errors.New("status 500"), 40, 300)}
+	service := NewInstrumentedService("bocha", next, collector)
+
+	// Adjustments made before the provider was called count as well
+	ctx := WithAdjustments(context.Background(), params.Adjustments{QueryTruncated: true})
+	ctx = WithAdjustments(ctx, params.Adjustments{CountClamped: true})
+	_, _ = service.Search(ctx, "a", "", 10, false)
+
+	snapshot := collector.Snapshot()
+	if snapshot.QueryTruncations != 1 || snapshot.CountClamps != 1 {
+		t.Errorf("Expected 1 truncation and 1 clamp, got %d and %d", snapshot.QueryTruncations, snapshot.CountClamps)
+	}
+	if snapshot.BytesSent != 40 || snapshot.BytesReceived != 300 {
+		t.Errorf("Expected the bytes of the failed call to be counted, got %d and %d", snapshot.BytesSent, snapshot.BytesReceived)
+	}
+}
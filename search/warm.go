@@ -0,0 +1,70 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// warmTimeout bounds a single warm-up request
+const warmTimeout = 10 * time.Second
+
+// Warmer is implemented by providers that can connect to their upstream API
+// ahead of a search, so the search reuses the connection
+type Warmer interface {
+	Warm(ctx context.Context) error
+}
+
+// WarmURL sends a HEAD request to url with client, leaving the connection idle
+// in the client's pool for the next request to the same host. The request
+// costs no quota and any response, whatever its status, counts as success.
+func WarmURL(ctx context.Context, client *http.Client, url string) error {
+	ctx, cancel := context.WithTimeout(ctx, warmTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create HTTP request: %w", err)
+	}
+
+	// A redirect would connect to another URL than searches use
+	noRedirect := *client
+	noRedirect.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+	resp, err := noRedirect.Do(req)
+	if err != nil {
+		return err
+	}
+	// The body must be read to the end for the connection to be reused
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return resp.Body.Close()
+}
+
+// KeepWarm warms every warmer now and then every interval until ctx is done,
+// so connections closed while idle are opened again before the next search. A
+// zero interval warms once. Failures are passed to onError, if set.
+func KeepWarm(ctx context.Context, warmers map[string]Warmer, interval time.Duration, onError func(name string, err error)) {
+	warm := func() {
+		for name, warmer := range warmers {
+			if err := warmer.Warm(ctx); err != nil && ctx.Err() == nil && onError != nil {
+				onError(name, err)
+			}
+		}
+	}
+	warm()
+	if interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			warm()
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWarmURL(t *testing.T) {
+	var connections, heads atomic.Int32
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead {
+			heads.Add(1)
+		}
+		if r.URL.Path == "/moved" {
+			http.Redirect(w, r, "https://elsewhere.example/", http.StatusFound)
+			return
+		}
+		// The API rejects anything but a search, which still warms the connection
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}))
+	server.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			connections.Add(1)
+		}
+	}
+	server.StartTLS()
+	defer server.Close()
+	client := server.Client()
+
+	if err := WarmURL(context.Background(), client, server.URL+"/v1/search"); err != nil {
+		t.Fatalf("WarmURL returned an error: %v", err)
+	}
+	resp, err := client.Get(server.URL + "/v1/search")
+	if err != nil {
+		t.Fatalf("Search request failed: %v", err)
+	}
+	resp.Body.Close()
+	if n := connections.Load(); n != 1 {
+		t.Errorf("Expected the search to reuse the warm connection, got %d connections", n)
+	}
+
+	// Redirects are not followed
+	if err := WarmURL(context.Background(), client, server.URL+"/moved"); err != nil {
+		t.Errorf("Expected a redirect to count as warm, got %v", err)
+	}
+	if n := heads.Load(); n != 2 {
+		t.Errorf("Expected 2 HEAD requests, got %d", n)
+	}
+
+	server.Close()
+	if err := WarmURL(context.Background(), client, server.URL+"/v1/search"); err == nil {
+		t.Error("Expected an error for an unreachable server")
+	}
+}
+
+// countingWarmer counts its warm-ups and fails when told to
+type countingWarmer struct {
+	mu    sync.Mutex
+	count int
+	err   error
+}
+
+func (w *countingWarmer) Warm(_ context.Context) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.count++
+	return w.err
+}
+
+func (w *countingWarmer) warmed() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.count
+}
+
+func TestKeepWarm(t *testing.T) {
+	// A zero interval warms once and returns
+	once := &countingWarmer{err: errors.New("connection refused")}
+	var failed []string
+	KeepWarm(context.Background(), map[string]Warmer{"brave": once}, 0, func(name string, err error) {
+		failed = append(failed, name)
+	})
+	if once.warmed() != 1 || len(failed) != 1 || failed[0] != "brave" {
+		t.Errorf("Expected one failed warm-up, got %d warm-ups and failures %v", once.warmed(), failed)
+	}
+
+	// Otherwise warm-ups repeat until the context is canceled
+	repeated := &countingWarmer{}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		KeepWarm(ctx, map[string]Warmer{"bocha": repeated}, 5*time.Millisecond, nil)
+		close(done)
+	}()
+	deadline := time.Now().Add(time.Second)
+	for repeated.warmed() < 3 {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected repeated warm-ups, got %d", repeated.warmed())
+		}
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Expected KeepWarm to return once the context is canceled")
+	}
+}
@@ -0,0 +1,320 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"com.moguyn/mcp-go-search/config"
+	"com.moguyn/mcp-go-search/search"
+)
+
+// Secret backends offered by the setup wizard for storing the API key
+const (
+	secretFile   = "file"
+	secretConfig = "config"
+	secretEnv    = "env"
+)
+
+// setupTestQuery is the query used to check the new configuration
+const setupTestQuery = "model context protocol"
+
+// setupProviders are the providers the wizard can configure, with the
+// environment variable each reads its key from
+var setupProviders = []struct {
+	name   string
+	keyEnv string
+}{
+	{config.ProviderBocha, "BOCHA_API_KEY"},
+	{config.ProviderBrave, "BRAVE_API_KEY"},
+	{config.ProviderGoogle, "GOOGLE_API_KEY"},
+	{config.ProviderBaidu, "BAIDU_API_KEY"},
+	{config.ProviderJina, "JINA_API_KEY"},
+}
+
+// setupFile is the configuration file written by the wizard. Fields are in the
+// order they appear in the file and empty ones are left out.
+type setupFile struct {
+	SearchProvider   string `yaml:"search_provider"`
+	BochaAPIKey      string `yaml:"bocha_api_key,omitempty"`
+	BochaAPIKeyFile  string `yaml:"bocha_api_key_file,omitempty"`
+	BraveAPIKey      string `yaml:"brave_api_key,omitempty"`
+	BraveAPIKeyFile  string `yaml:"brave_api_key_file,omitempty"`
+	GoogleAPIKey     string `yaml:"google_api_key,omitempty"`
+	GoogleAPIKeyFile string `yaml:"google_api_key_file,omitempty"`
+	GoogleCX         string `yaml:"google_cx,omitempty"`
+	BaiduAPIKey      string `yaml:"baidu_api_key,omitempty"`
+	BaiduAPIKeyFile  string `yaml:"baidu_api_key_file,omitempty"`
+	JinaAPIKey       string `yaml:"jina_api_key,omitempty"`
+	JinaAPIKeyFile   string `yaml:"jina_api_key_file,omitempty"`
+	HTTPTimeout      string `yaml:"http_timeout"`
+	LogLevel         string `yaml:"log_level"`
+}
+
+// setupWizard walks through a first-run configuration interactively
+type setupWizard struct {
+	in  *bufio.Reader
+	out io.Writer
+	// command is the server binary named in the client install snippet
+	command string
+	// newProvider builds the provider used for the test query
+	newProvider func(cfg *config.Config) (search.Provider, error)
+}
+
+// prompt asks a question and returns the trimmed answer, or def when the
+// answer is empty
+func (w *setupWizard) prompt(question, def string) (string, error) {
+	if def != "" {
+		fmt.Fprintf(w.out, "%s [%s]: ", question, def)
+	} else {
+		fmt.Fprintf(w.out, "%s: ", question)
+	}
+	line, err := w.in.ReadString('\n')
+	if err != nil && (!errors.Is(err, io.EOF) || line == "") {
+		return "", fmt.Errorf("failed to read answer: %w", err)
+	}
+	if answer := strings.TrimSpace(line); answer != "" {
+		return answer, nil
+	}
+	return def, nil
+}
+
+// choose asks until the answer is one of choices
+func (w *setupWizard) choose(question string, choices []string, def string) (string, error) {
+	for {
+		answer, err := w.prompt(fmt.Sprintf("%s (%s)", question, strings.Join(choices, ", ")), def)
+		if err != nil {
+			return "", err
+		}
+		for _, choice := range choices {
+			if strings.EqualFold(answer, choice) {
+				return choice, nil
+			}
+		}
+		fmt.Fprintf(w.out, "Please answer one of: %s\n", strings.Join(choices, ", "))
+	}
+}
+
+// required asks until the answer is not empty
+func (w *setupWizard) required(question string) (string, error) {
+	for {
+		answer, err := w.prompt(question, "")
+		if err != nil || answer != "" {
+			return answer, err
+		}
+		fmt.Fprintln(w.out, "A value is required")
+	}
+}
+
+// run asks for the provider, key and defaults, writes the configuration file
+// to path, runs a test query and prints the client install snippet
+func (w *setupWizard) run(path string, force bool) error {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return fmt.Errorf("failed to resolve %s: %w", path, err)
+	}
+	if _, err := os.Stat(absPath); err == nil && !force {
+		overwrite, err := w.choose(fmt.Sprintf("%s exists, overwrite it?", absPath), []string{"y", "n"}, "n")
+		if err != nil {
+			return err
+		}
+		if overwrite != "y" {
+			return fmt.Errorf("%s exists; rerun with -force or choose another -config", absPath)
+		}
+	}
+
+	names := make([]string, len(setupProviders))
+	for i, p := range setupProviders {
+		names[i] = p.name
+	}
+	provider, err := w.choose("Search provider", names, config.ProviderBocha)
+	if err != nil {
+		return err
+	}
+	keyEnv := setupProviders[0].keyEnv
+	for _, p := range setupProviders {
+		if p.name == provider {
+			keyEnv = p.keyEnv
+		}
+	}
+
+	apiKey, err := w.required(fmt.Sprintf("%s API key", provider))
+	if err != nil {
+		return err
+	}
+	var cx string
+	if provider == config.ProviderGoogle {
+		if cx, err = w.required("Programmable Search Engine ID (cx)"); err != nil {
+			return err
+		}
+	}
+
+	backend, err := w.choose("Store the API key in", []string{secretFile, secretConfig, secretEnv}, secretFile)
+	if err != nil {
+		return err
+	}
+	var keyFile string
+	if backend == secretFile {
+		def := filepath.Join(filepath.Dir(absPath), provider+"_api_key")
+		if keyFile, err = w.prompt("Key file", def); err != nil {
+			return err
+		}
+		if keyFile, err = filepath.Abs(keyFile); err != nil {
+			return fmt.Errorf("failed to resolve key file: %w", err)
+		}
+	}
+
+	timeout, err := w.prompt("HTTP timeout", "10s")
+	if err != nil {
+		return err
+	}
+	if _, err := time.ParseDuration(timeout); err != nil {
+		return fmt.Errorf("invalid HTTP timeout %q: %w", timeout, err)
+	}
+	logLevel, err := w.choose("Log level", []string{"debug", "info", "error"}, "info")
+	if err != nil {
+		return err
+	}
+
+	file := setupFile{SearchProvider: provider, GoogleCX: cx, HTTPTimeout: timeout, LogLevel: logLevel}
+	switch backend {
+	case secretFile:
+		if err := os.WriteFile(keyFile, []byte(apiKey+"\n"), 0600); err != nil {
+			return fmt.Errorf("failed to write key file: %w", err)
+		}
+		fmt.Fprintf(w.out, "Wrote API key to %s\n", keyFile)
+		setKey(&file, provider, "", keyFile)
+	case secretConfig:
+		setKey(&file, provider, apiKey, "")
+	}
+	data, err := yaml.Marshal(file)
+	if err != nil {
+		return fmt.Errorf("failed to encode config: %w", err)
+	}
+	// The file may hold the key, so it is only readable by its owner
+	if err := os.WriteFile(absPath, data, 0600); err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	fmt.Fprintf(w.out, "Wrote configuration to %s\n", absPath)
+
+	if err := w.testQuery(absPath, provider, apiKey); err != nil {
+		return err
+	}
+
+	env := map[string]string{"CONFIG_FILE": absPath}
+	if backend == secretEnv {
+		env[keyEnv] = apiKey
+	}
+	snippet, err := json.MarshalIndent(map[string]interface{}{
+		"mcpServers": map[string]interface{}{
+			"search": map[string]interface{}{
+				"command": w.command,
+				"env":     env,
+			},
+		},
+	}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode client snippet: %w", err)
+	}
+	fmt.Fprintf(w.out, "\nAdd the server to your MCP client configuration:\n\n%s\n", snippet)
+	return nil
+}
+
+// testQuery loads the written configuration and runs one search with it
+func (w *setupWizard) testQuery(path, provider, apiKey string) error {
+	cfg := config.New()
+	if err := cfg.LoadFromFile(path); err != nil {
+		return fmt.Errorf("failed to load the new config: %w", err)
+	}
+	// The key is set directly, since the environment may hold another one and
+	// the key may not be in the file at all
+	cfg.SearchProvider = provider
+	switch provider {
+	case config.ProviderBrave:
+		cfg.BraveAPIKey = apiKey
+	case config.ProviderGoogle:
+		cfg.GoogleAPIKey = apiKey
+	case config.ProviderBaidu:
+		cfg.BaiduAPIKey = apiKey
+	case config.ProviderJina:
+		cfg.JinaAPIKey = apiKey
+	default:
+		cfg.BochaAPIKey = apiKey
+	}
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+	backend, err := w.newProvider(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to create provider: %w", err)
+	}
+
+	fmt.Fprintf(w.out, "Running a test query with %s...\n", provider)
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.HTTPTimeout+5*time.Second)
+	defer cancel()
+	resp, err := backend.Search(ctx, setupTestQuery, "", 3, false)
+	if err != nil {
+		return fmt.Errorf("test query failed, check the API key and run setup again: %w", err)
+	}
+	fmt.Fprintf(w.out, "Test query returned %d results\n", len(resp.Data.WebPages.Value))
+	return nil
+}
+
+// setKey stores either the key or the key file in the field for provider
+func setKey(file *setupFile, provider, key, keyFile string) {
+	switch provider {
+	case config.ProviderBrave:
+		file.BraveAPIKey, file.BraveAPIKeyFile = key, keyFile
+	case config.ProviderGoogle:
+		file.GoogleAPIKey, file.GoogleAPIKeyFile = key, keyFile
+	case config.ProviderBaidu:
+		file.BaiduAPIKey, file.BaiduAPIKeyFile = key, keyFile
+	case config.ProviderJina:
+		file.JinaAPIKey, file.JinaAPIKeyFile = key, keyFile
+	default:
+		file.BochaAPIKey, file.BochaAPIKeyFile = key, keyFile
+	}
+}
+
+// runSetup runs the interactive setup wizard for the setup subcommand
+func runSetup(args []string, in io.Reader, out io.Writer) error {
+	flags := flag.NewFlagSet("setup", flag.ContinueOnError)
+	flags.SetOutput(out)
+	path := flags.String("config", "config.yaml", "configuration file to write")
+	force := flags.Bool("force", false, "overwrite an existing configuration file without asking")
+	flags.Usage = func() {
+		fmt.Fprintf(out, "Usage: %s setup [flags]\n\n", "mcp-search-server")
+		fmt.Fprintln(out, "Interactively writes a configuration file, checks it with a test query and")
+		fmt.Fprintln(out, "prints the snippet to add the server to an MCP client.")
+		fmt.Fprintln(out)
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+
+	command, err := os.Executable()
+	if err != nil {
+		command = "mcp-search-server"
+	}
+	wizard := &setupWizard{
+		in:          bufio.NewReader(in),
+		out:         out,
+		command:     command,
+		newProvider: search.NewProvider,
+	}
+	return wizard.run(*path, *force)
+}
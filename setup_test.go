@@ -0,0 +1,135 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+
+	"com.moguyn/mcp-go-search/config"
+	"com.moguyn/mcp-go-search/fixtures"
+	"com.moguyn/mcp-go-search/search"
+)
+
+// newTestWizard returns a wizard answering with the given lines, whose test
+// query goes to the fake API
+func newTestWizard(t *testing.T, answers ...string) (*setupWizard, *bytes.Buffer) {
+	t.Helper()
+	upstream := httptest.NewServer(&fixtures.API{})
+	t.Cleanup(upstream.Close)
+
+	out := &bytes.Buffer{}
+	return &setupWizard{
+		in:      bufio.NewReader(strings.NewReader(strings.Join(answers, "\n") + "\n")),
+		out:     out,
+		command: "/usr/local/bin/mcp-search-server",
+		newProvider: func(cfg *config.Config) (search.Provider, error) {
+			cfg.BochaAPIBaseURL = upstream.URL
+			return search.NewProvider(cfg)
+		},
+	}, out
+}
+
+func TestSetupWizard_KeyFile(t *testing.T) {
+	t.Setenv("CONFIG_FILE", "")
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+
+	// Provider and backend take their defaults; an invalid log level is asked again
+	wizard, out := newTestWizard(t, "", "bocha-test-key", "", "", "5s", "verbose", "debug")
+	if err := wizard.run(path, false); err != nil {
+		t.Fatalf("run returned an error: %v\n%s", err, out)
+	}
+
+	key, err := os.ReadFile(filepath.Join(dir, "bocha_api_key"))
+	if err != nil || strings.TrimSpace(string(key)) != "bocha-test-key" {
+		t.Errorf("Expected the key in the key file, got %q (%v)", key, err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read config: %v", err)
+	}
+	var written map[string]string
+	if err := yaml.Unmarshal(data, &written); err != nil {
+		t.Fatalf("Failed to parse config: %v", err)
+	}
+	if written["search_provider"] != "bocha" || written["bocha_api_key_file"] != filepath.Join(dir, "bocha_api_key") {
+		t.Errorf("Unexpected config: %v", written)
+	}
+	if _, ok := written["bocha_api_key"]; ok {
+		t.Error("Expected the key to stay out of the config file")
+	}
+	if written["http_timeout"] != "5s" || written["log_level"] != "debug" {
+		t.Errorf("Expected the chosen defaults, got %v", written)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("Expected the config to be readable only by its owner, got %v", info.Mode())
+	}
+
+	output := out.String()
+	if !strings.Contains(output, "Please answer one of") {
+		t.Error("Expected the invalid log level to be asked again")
+	}
+	if !strings.Contains(output, "Test query returned") {
+		t.Errorf("Expected the test query result, got:\n%s", output)
+	}
+	if !strings.Contains(output, `"command": "/usr/local/bin/mcp-search-server"`) || !strings.Contains(output, `"CONFIG_FILE": "`+path+`"`) {
+		t.Errorf("Expected the client snippet, got:\n%s", output)
+	}
+	if strings.Contains(output, "BOCHA_API_KEY\"") {
+		t.Error("Expected the key to stay out of the snippet")
+	}
+}
+
+func TestSetupWizard_EnvBackend(t *testing.T) {
+	t.Setenv("CONFIG_FILE", "")
+	path := filepath.Join(t.TempDir(), "config.yaml")
+
+	wizard, out := newTestWizard(t, "bocha", "bocha-test-key", "env", "", "")
+	if err := wizard.run(path, false); err != nil {
+		t.Fatalf("run returned an error: %v\n%s", err, out)
+	}
+	data, _ := os.ReadFile(path)
+	if strings.Contains(string(data), "bocha-test-key") {
+		t.Errorf("Expected no key in the config, got:\n%s", data)
+	}
+	if !strings.Contains(out.String(), `"BOCHA_API_KEY": "bocha-test-key"`) {
+		t.Errorf("Expected the key in the snippet's environment, got:\n%s", out)
+	}
+}
+
+func TestSetupWizard_Errors(t *testing.T) {
+	t.Setenv("CONFIG_FILE", "")
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("log_level: info\n"), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	// An existing file is kept unless overwriting is confirmed
+	wizard, _ := newTestWizard(t, "")
+	if err := wizard.run(path, false); err == nil {
+		t.Error("Expected error for an existing config, got nil")
+	}
+
+	// A failing test query is reported
+	wizard, out := newTestWizard(t, "brave", "bad-key", "config", "", "")
+	wizard.newProvider = func(*config.Config) (search.Provider, error) {
+		return nil, errors.New("unreachable")
+	}
+	if err := wizard.run(path, true); err == nil || !strings.Contains(err.Error(), "unreachable") {
+		t.Errorf("Expected the provider error, got %v", err)
+	}
+	data, _ := os.ReadFile(path)
+	if !strings.Contains(string(data), "brave_api_key: bad-key") {
+		t.Errorf("Expected the key in the config, got:\n%s", data)
+	}
+	if strings.Contains(out.String(), "mcpServers") {
+		t.Error("Expected no client snippet after a failed test query")
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/mark3labs/mcp-go/server"
+
+	"com.moguyn/mcp-go-search/pool"
+)
+
+// sessionTenantPrefix starts the worker pool tenant of each SSE session
+const sessionTenantPrefix = "session:"
+
+// serveSSE serves the MCP server over SSE on addr until the process is
+// interrupted. It is a variable that can be overridden in tests.
+var serveSSE = func(s *server.MCPServer, addr, token string) error {
+	httpServer := &http.Server{Addr: addr, ReadHeaderTimeout: 10 * time.Second}
+	sseServer := server.NewSSEServer(s, server.WithHTTPServer(httpServer))
+	httpServer.Handler = requireToken(token, sessionTenants(sseServer))
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = sseServer.Shutdown(shutdownCtx)
+	}()
+
+	if err := httpServer.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// sessionTenants schedules the worker pool jobs of each SSE session's calls as
+// a tenant of their own, so sessions take turns for free workers and one
+// session queueing many calls doesn't hold up the others
+func sessionTenants(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if id := r.URL.Query().Get("sessionId"); id != "" {
+			r = r.WithContext(pool.WithTenant(r.Context(), sessionTenantPrefix+id))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// requireToken rejects requests without token as their bearer token
+func requireToken(token string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sent := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if token == "" || subtle.ConstantTimeCompare([]byte(sent), []byte(token)) != 1 {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
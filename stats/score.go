@@ -0,0 +1,112 @@
+package stats
+
+import (
+	"math"
+	"time"
+)
+
+// maxResultOwners is the number of recent result URLs remembered to credit
+// their provider when one is chosen
+const maxResultOwners = 1000
+
+// Weights of the parts of a provider's score. Latency is scored relative to
+// scoreLatency: a provider answering that fast scores half.
+const (
+	weightAnswered = 0.4
+	weightLatency  = 0.2
+	weightNonEmpty = 0.2
+	weightChosen   = 0.2
+	scoreLatency   = time.Second
+)
+
+// resultOwners remembers which provider returned each of the most recent
+// result URLs
+type resultOwners struct {
+	max      int
+	provider map[string]string
+	order    []string
+}
+
+// newResultOwners creates an empty set remembering up to max URLs
+func newResultOwners(max int) *resultOwners {
+	return &resultOwners{
+		max:      max,
+		provider: make(map[string]string),
+	}
+}
+
+// add records that provider returned url, forgetting the oldest URL when full
+func (o *resultOwners) add(url, provider string) {
+	if url == "" {
+		return
+	}
+	if _, ok := o.provider[url]; !ok {
+		o.order = append(o.order, url)
+	}
+	o.provider[url] = provider
+	for len(o.order) > o.max {
+		delete(o.provider, o.order[0])
+		o.order = o.order[1:]
+	}
+}
+
+// take returns the provider that returned url and forgets it, so a result is
+// credited once however often it is chosen
+func (o *resultOwners) take(url string) (string, bool) {
+	provider, ok := o.provider[url]
+	if ok {
+		delete(o.provider, url)
+	}
+	return provider, ok
+}
+
+// RecordChosen credits the provider that returned url with a chosen result,
+// one the agent went on to fetch or save. Agents choosing a provider's results
+// is the best available sign that they were worth returning.
+func (c *Collector) RecordChosen(url string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	provider, ok := c.owners.take(url)
+	if !ok {
+		return
+	}
+	if counters, ok := c.providers[provider]; ok {
+		counters.chosen++
+	}
+}
+
+// Score returns the named provider's score, and false if it has not been
+// called yet
+func (c *Collector) Score(provider string) (float64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	counters, ok := c.providers[provider]
+	if !ok || counters.queries == 0 {
+		return 0, false
+	}
+	var ps ProviderStats
+	ps.score(counters)
+	return ps.Score, true
+}
+
+// score fills in the scoreboard fields from counters. The score, between 0
+// and 1, weighs how often the provider answers, how fast, how often with
+// results, and how many of its results are chosen per answer.
+func (ps *ProviderStats) score(counters *providerCounters) {
+	ps.ResultsChosen = counters.chosen
+	if counters.queries == 0 {
+		return
+	}
+	succeeded := counters.queries - counters.errors
+	ps.SuccessRate = float64(succeeded) / float64(counters.queries)
+	latency := counters.totalLatency / time.Duration(counters.queries)
+	latencyScore := 1 / (1 + float64(latency)/float64(scoreLatency))
+	nonEmpty := 0.0
+	if succeeded > 0 {
+		ps.EmptyRate = float64(counters.empty) / float64(succeeded)
+		ps.ChosenPerQuery = float64(counters.chosen) / float64(succeeded)
+		nonEmpty = 1 - ps.EmptyRate
+	}
+	ps.Score = weightAnswered*ps.SuccessRate +
+		ps.SuccessRate*(weightLatency*latencyScore+weightNonEmpty*nonEmpty+weightChosen*math.Min(ps.ChosenPerQuery, 1))
+}
@@ -0,0 +1,53 @@
+package stats
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestCollector_Scoreboard(t *testing.T) {
+	c := NewCollector()
+	c.Record(Call{Provider: "bocha", Latency: time.Second, Results: 2, ResultURLs: []string{"https://go.dev/", "https://pkg.go.dev/"}})
+	c.Record(Call{Provider: "bocha", Latency: time.Second, Results: 0})
+	c.Record(Call{Provider: "brave", Latency: time.Second, Err: errors.New("boom")})
+	c.Record(Call{Provider: "brave", Latency: time.Second, Results: 1, ResultURLs: []string{"https://secret.example/"}, Incognito: true})
+
+	c.RecordChosen("https://go.dev/")
+	c.RecordChosen("https://go.dev/")
+	c.RecordChosen("https://secret.example/")
+	c.RecordChosen("https://unknown.example/")
+
+	snapshot := c.Snapshot()
+	bocha, brave := snapshot.Providers[0], snapshot.Providers[1]
+	if bocha.SuccessRate != 1 || bocha.EmptyRate != 0.5 || bocha.ResultsChosen != 1 || bocha.ChosenPerQuery != 0.5 {
+		t.Errorf("Unexpected bocha scoreboard: %+v", bocha)
+	}
+	// 0.4 answered + 0.2*0.5 latency + 0.2*0.5 non-empty + 0.2*0.5 chosen
+	if math.Abs(bocha.Score-0.7) > 1e-9 {
+		t.Errorf("Expected bocha to score 0.7, got %f", bocha.Score)
+	}
+	if brave.SuccessRate != 0.5 || brave.ResultsChosen != 0 {
+		t.Errorf("Expected incognito results not to be credited, got %+v", brave)
+	}
+	if score, ok := c.Score("brave"); !ok || score != brave.Score || score >= bocha.Score {
+		t.Errorf("Expected brave to score below bocha, got %f (%t)", score, ok)
+	}
+	if _, ok := c.Score("google"); ok {
+		t.Error("Expected no score for a provider that was never called")
+	}
+}
+
+func TestResultOwners_Bounded(t *testing.T) {
+	owners := newResultOwners(2)
+	owners.add("a", "bocha")
+	owners.add("b", "bocha")
+	owners.add("c", "brave")
+	if _, ok := owners.take("a"); ok {
+		t.Error("Expected the oldest URL to be forgotten")
+	}
+	if provider, ok := owners.take("c"); !ok || provider != "brave" {
+		t.Errorf("Expected c to belong to brave, got %q", provider)
+	}
+}
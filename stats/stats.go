@@ -0,0 +1,306 @@
+// Package stats collects runtime statistics about upstream search calls
+package stats
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"com.moguyn/mcp-go-search/privacy"
+)
+
+// maxRecentQueries is the number of recent queries kept for the dashboard
+const maxRecentQueries = 50
+
+// Query policies control how much of a query is kept in the recent query list
+const (
+	// QueryPolicyFull keeps queries verbatim
+	QueryPolicyFull = "full"
+	// QueryPolicyRedact keeps the first letter of each word
+	QueryPolicyRedact = "redact"
+	// QueryPolicyHash keeps a short hash so repeated queries can be recognized
+	QueryPolicyHash = "hash"
+	// QueryPolicyHide keeps nothing
+	QueryPolicyHide = "hide"
+)
+
+// QueryRecord describes one recent upstream call
+type QueryRecord struct {
+	Time      time.Time `json:"time"`
+	Provider  string    `json:"provider"`
+	Query     string    `json:"query"`
+	LatencyMs int64     `json:"latency_ms"`
+	Failed    bool      `json:"failed"`
+}
+
+// ProviderStats holds the counters for a single search provider
+type ProviderStats struct {
+	Name             string     `json:"name"`
+	Queries          uint64     `json:"queries"`
+	Errors           uint64     `json:"errors"`
+	AverageLatencyMs float64    `json:"average_latency_ms"`
+	AverageResults   float64    `json:"average_results"`
+	BytesSent        int64      `json:"bytes_sent"`
+	BytesReceived    int64      `json:"bytes_received"`
+	LastError        *time.Time `json:"last_error,omitempty"`
+
+	// Scoreboard: how healthy and useful the provider's answers are, see Score
+	SuccessRate    float64 `json:"success_rate"`
+	EmptyRate      float64 `json:"empty_rate"`
+	ResultsChosen  uint64  `json:"results_chosen"`
+	ChosenPerQuery float64 `json:"chosen_per_query"`
+	Score          float64 `json:"score"`
+}
+
+// Snapshot is a point-in-time copy of the collected statistics
+type Snapshot struct {
+	StartedAt time.Time `json:"started_at"`
+	Uptime    string    `json:"uptime"`
+	Queries   uint64    `json:"queries"`
+	Errors    uint64    `json:"errors"`
+
+	// Upstream payload sizes and result counts across all providers
+	BytesSent      int64   `json:"bytes_sent"`
+	BytesReceived  int64   `json:"bytes_received"`
+	AverageResults float64 `json:"average_results"`
+
+	// Number of requests whose query was truncated or count clamped
+	QueryTruncations uint64 `json:"query_truncations"`
+	CountClamps      uint64 `json:"count_clamps"`
+
+	Providers []ProviderStats `json:"providers"`
+	Recent    []QueryRecord   `json:"recent_queries"`
+}
+
+// Call describes the outcome of one upstream call
+type Call struct {
+	Provider string
+	Query    string
+	Latency  time.Duration
+	Err      error
+
+	// Payload sizes on the wire
+	BytesSent     int64
+	BytesReceived int64
+
+	// Results is the number of results returned by a successful call, and
+	// ResultURLs their URLs, to credit the provider when one is chosen
+	Results    int
+	ResultURLs []string
+
+	// QueryTruncated and CountClamped report adjustments made to the request
+	QueryTruncated bool
+	CountClamped   bool
+
+	// Incognito calls are counted but not listed among the recent queries
+	Incognito bool
+}
+
+// providerCounters is the mutable state behind ProviderStats
+type providerCounters struct {
+	queries       uint64
+	errors        uint64
+	totalLatency  time.Duration
+	lastError     time.Time
+	bytesSent     int64
+	bytesReceived int64
+	results       uint64
+	empty         uint64
+	chosen        uint64
+}
+
+// Collector accumulates statistics; it is safe for concurrent use
+type Collector struct {
+	mu          sync.Mutex
+	startedAt   time.Time
+	providers   map[string]*providerCounters
+	recent      []QueryRecord
+	queryPolicy string
+	scrubber    *privacy.Scrubber
+
+	queryTruncations uint64
+	countClamps      uint64
+
+	// owners maps recent result URLs to the provider that returned them
+	owners *resultOwners
+
+	observers []func(Call)
+}
+
+// NewCollector creates a new, empty collector
+func NewCollector() *Collector {
+	return &Collector{
+		startedAt:   time.Now(),
+		providers:   make(map[string]*providerCounters),
+		queryPolicy: QueryPolicyRedact,
+		owners:      newResultOwners(maxResultOwners),
+	}
+}
+
+// SetQueryPolicy sets how queries are stored in the recent query list
+func (c *Collector) SetQueryPolicy(policy string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.queryPolicy = policy
+}
+
+// SetScrubber sets the scrubber that removes personal data from queries
+// before the query policy is applied
+func (c *Collector) SetScrubber(scrubber *privacy.Scrubber) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.scrubber = scrubber
+}
+
+// ClearRecent empties the recent query list and returns how many queries were removed
+func (c *Collector) ClearRecent() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	n := len(c.recent)
+	c.recent = nil
+	return n
+}
+
+// Observe calls fn with every call recorded from now on, after it is counted,
+// such as to persist usage
+func (c *Collector) Observe(fn func(Call)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.observers = append(c.observers, fn)
+}
+
+// Record records the outcome of one upstream call
+func (c *Collector) Record(call Call) {
+	c.mu.Lock()
+	c.record(call)
+	observers := c.observers
+	c.mu.Unlock()
+
+	for _, observe := range observers {
+		observe(call)
+	}
+}
+
+// record counts one upstream call. The caller must hold c.mu.
+func (c *Collector) record(call Call) {
+	if !call.Incognito {
+		c.recent = append(c.recent, QueryRecord{
+			Time:      time.Now(),
+			Provider:  call.Provider,
+			Query:     ApplyQueryPolicy(c.scrubber.Scrub(call.Query), c.queryPolicy),
+			LatencyMs: call.Latency.Milliseconds(),
+			Failed:    call.Err != nil,
+		})
+		if len(c.recent) > maxRecentQueries {
+			c.recent = c.recent[len(c.recent)-maxRecentQueries:]
+		}
+	}
+
+	counters, ok := c.providers[call.Provider]
+	if !ok {
+		counters = &providerCounters{}
+		c.providers[call.Provider] = counters
+	}
+	counters.queries++
+	counters.totalLatency += call.Latency
+	counters.bytesSent += call.BytesSent
+	counters.bytesReceived += call.BytesReceived
+	if call.Err != nil {
+		counters.errors++
+		counters.lastError = time.Now()
+	} else {
+		counters.results += uint64(call.Results)
+		if call.Results == 0 {
+			counters.empty++
+		}
+		// Incognito results are not kept, even to credit their provider
+		if !call.Incognito {
+			for _, url := range call.ResultURLs {
+				c.owners.add(url, call.Provider)
+			}
+		}
+	}
+
+	if call.QueryTruncated {
+		c.queryTruncations++
+	}
+	if call.CountClamped {
+		c.countClamps++
+	}
+}
+
+// Snapshot returns a copy of the current statistics
+func (c *Collector) Snapshot() Snapshot {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	snapshot := Snapshot{
+		StartedAt: c.startedAt,
+		Uptime:    time.Since(c.startedAt).Round(time.Second).String(),
+		Providers: make([]ProviderStats, 0, len(c.providers)),
+		Recent:    append([]QueryRecord(nil), c.recent...),
+
+		QueryTruncations: c.queryTruncations,
+		CountClamps:      c.countClamps,
+	}
+	var totalResults, successes uint64
+	for name, counters := range c.providers {
+		ps := ProviderStats{
+			Name:          name,
+			Queries:       counters.queries,
+			Errors:        counters.errors,
+			BytesSent:     counters.bytesSent,
+			BytesReceived: counters.bytesReceived,
+		}
+		if succeeded := counters.queries - counters.errors; succeeded > 0 {
+			ps.AverageResults = float64(counters.results) / float64(succeeded)
+		}
+		if !counters.lastError.IsZero() {
+			lastError := counters.lastError
+			ps.LastError = &lastError
+		}
+		if counters.queries > 0 {
+			ps.AverageLatencyMs = float64(counters.totalLatency.Milliseconds()) / float64(counters.queries)
+		}
+		ps.score(counters)
+		snapshot.Queries += counters.queries
+		snapshot.Errors += counters.errors
+		snapshot.BytesSent += counters.bytesSent
+		snapshot.BytesReceived += counters.bytesReceived
+		snapshot.Providers = append(snapshot.Providers, ps)
+		totalResults += counters.results
+		successes += counters.queries - counters.errors
+	}
+	if successes > 0 {
+		snapshot.AverageResults = float64(totalResults) / float64(successes)
+	}
+	sort.Slice(snapshot.Providers, func(i, j int) bool {
+		return snapshot.Providers[i].Name < snapshot.Providers[j].Name
+	})
+
+	return snapshot
+}
+
+// ApplyQueryPolicy returns the query as it may be stored under the given policy.
+// Unknown policies are treated as QueryPolicyHide.
+func ApplyQueryPolicy(query, policy string) string {
+	switch policy {
+	case QueryPolicyFull:
+		return query
+	case QueryPolicyRedact:
+		words := strings.Fields(query)
+		for i, word := range words {
+			r := []rune(word)
+			words[i] = string(r[0]) + strings.Repeat("*", len(r)-1)
+		}
+		return strings.Join(words, " ")
+	case QueryPolicyHash:
+		sum := sha256.Sum256([]byte(query))
+		return "#" + hex.EncodeToString(sum[:4])
+	default:
+		return "[hidden]"
+	}
+}
@@ -0,0 +1,160 @@
+package stats
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"com.moguyn/mcp-go-search/privacy"
+)
+
+func TestCollector(t *testing.T) {
+	c := NewCollector()
+
+	c.Record(Call{Provider: "bocha", Query: "golang generics", Latency: 100 * time.Millisecond, BytesSent: 80, BytesReceived: 4000, Results: 10})
+	c.Record(Call{Provider: "bocha", Query: "golang generics", Latency: 300 * time.Millisecond, Err: errors.New("boom"), BytesSent: 80, QueryTruncated: true})
+	c.Record(Call{Provider: "plugin", Query: "rust traits", Latency: 50 * time.Millisecond, Results: 4, CountClamped: true})
+
+	snapshot := c.Snapshot()
+
+	if snapshot.Queries != 3 {
+		t.Errorf("Expected 3 queries, got %d", snapshot.Queries)
+	}
+	if snapshot.Errors != 1 {
+		t.Errorf("Expected 1 error, got %d", snapshot.Errors)
+	}
+	if len(snapshot.Providers) != 2 {
+		t.Fatalf("Expected 2 providers, got %d", len(snapshot.Providers))
+	}
+
+	bocha := snapshot.Providers[0]
+	if bocha.Name != "bocha" {
+		t.Fatalf("Expected providers sorted by name, got %s first", bocha.Name)
+	}
+	if bocha.Queries != 2 || bocha.Errors != 1 {
+		t.Errorf("Expected 2 queries and 1 error for bocha, got %d and %d", bocha.Queries, bocha.Errors)
+	}
+	if bocha.AverageLatencyMs != 200 {
+		t.Errorf("Expected average latency 200ms, got %f", bocha.AverageLatencyMs)
+	}
+	if bocha.BytesSent != 160 || bocha.BytesReceived != 4000 {
+		t.Errorf("Expected 160 bytes sent and 4000 received for bocha, got %d and %d", bocha.BytesSent, bocha.BytesReceived)
+	}
+	if bocha.AverageResults != 10 {
+		t.Errorf("Expected failed calls to be excluded from average results, got %f", bocha.AverageResults)
+	}
+	if snapshot.AverageResults != 7 {
+		t.Errorf("Expected overall average of 7 results, got %f", snapshot.AverageResults)
+	}
+	if snapshot.BytesSent != 160 || snapshot.BytesReceived != 4000 {
+		t.Errorf("Expected overall byte totals, got %d and %d", snapshot.BytesSent, snapshot.BytesReceived)
+	}
+	if snapshot.QueryTruncations != 1 || snapshot.CountClamps != 1 {
+		t.Errorf("Expected 1 truncation and 1 clamp, got %d and %d", snapshot.QueryTruncations, snapshot.CountClamps)
+	}
+	if bocha.LastError == nil {
+		t.Error("Expected last error time for bocha")
+	}
+	if snapshot.Providers[1].LastError != nil {
+		t.Error("Expected no last error time for plugin")
+	}
+
+	if len(snapshot.Recent) != 3 {
+		t.Fatalf("Expected 3 recent queries, got %d", len(snapshot.Recent))
+	}
+	if snapshot.Recent[0].Query != "g***** g*******" {
+		t.Errorf("Expected redacted query by default, got %q", snapshot.Recent[0].Query)
+	}
+	if !snapshot.Recent[1].Failed {
+		t.Error("Expected second recent query to be marked as failed")
+	}
+}
+
+func TestCollector_RecentQueriesBounded(t *testing.T) {
+	c := NewCollector()
+	c.SetQueryPolicy(QueryPolicyFull)
+	for i := 0; i < maxRecentQueries+10; i++ {
+		c.Record(Call{Provider: "bocha", Query: "query", Latency: time.Millisecond})
+	}
+
+	recent := c.Snapshot().Recent
+	if len(recent) != maxRecentQueries {
+		t.Errorf("Expected %d recent queries, got %d", maxRecentQueries, len(recent))
+	}
+	if recent[0].Query != "query" {
+		t.Errorf("Expected full query, got %q", recent[0].Query)
+	}
+}
+
+func TestApplyQueryPolicy(t *testing.T) {
+	testCases := []struct {
+		policy   string
+		expected string
+	}{
+		{QueryPolicyFull, "secret project plan"},
+		{QueryPolicyRedact, "s***** p****** p***"},
+		{QueryPolicyHide, "[hidden]"},
+		{"unknown", "[hidden]"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.policy, func(t *testing.T) {
+			if result := ApplyQueryPolicy("secret project plan", tc.policy); result != tc.expected {
+				t.Errorf("Expected '%s', got '%s'", tc.expected, result)
+			}
+		})
+	}
+
+	hashed := ApplyQueryPolicy("secret project plan", QueryPolicyHash)
+	if len(hashed) != 9 || hashed != ApplyQueryPolicy("secret project plan", QueryPolicyHash) {
+		t.Errorf("Expected a stable short hash, got %q", hashed)
+	}
+}
+
+func TestCollector_ScrubsBeforePolicy(t *testing.T) {
+	c := NewCollector()
+	c.SetQueryPolicy(QueryPolicyFull)
+	scrubber, err := privacy.NewScrubber(privacy.Kinds, nil)
+	if err != nil {
+		t.Fatalf("Failed to create scrubber: %v", err)
+	}
+	c.SetScrubber(scrubber)
+
+	c.Record(Call{Provider: "bocha", Query: "invoice for jane@example.com", Latency: time.Millisecond})
+
+	if recent := c.Snapshot().Recent; recent[0].Query != "invoice for [email]" {
+		t.Errorf("Expected scrubbed query, got %q", recent[0].Query)
+	}
+}
+
+func TestCollector_IncognitoNotListed(t *testing.T) {
+	c := NewCollector()
+	c.Record(Call{Provider: "bocha", Query: "private matter", Latency: time.Millisecond, Incognito: true})
+
+	snapshot := c.Snapshot()
+	if snapshot.Queries != 1 {
+		t.Errorf("Expected the incognito call to be counted, got %d queries", snapshot.Queries)
+	}
+	if len(snapshot.Recent) != 0 {
+		t.Errorf("Expected no recent queries, got %+v", snapshot.Recent)
+	}
+}
+
+func TestCollector_Observe(t *testing.T) {
+	c := NewCollector()
+	c.Record(Call{Provider: "bocha", Query: "before"})
+
+	var observed []Call
+	c.Observe(func(call Call) {
+		// Observers run outside the lock, so they may read the collector
+		if c.Snapshot().Queries == 0 {
+			t.Error("Expected the call to be counted before it is observed")
+		}
+		observed = append(observed, call)
+	})
+	c.Record(Call{Provider: "brave", Query: "after", Err: errors.New("boom"), Incognito: true})
+
+	if len(observed) != 1 || observed[0].Provider != "brave" || observed[0].Err == nil {
+		t.Errorf("Expected only the call recorded after Observe, got %+v", observed)
+	}
+}
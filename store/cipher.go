@@ -0,0 +1,91 @@
+package store
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// KeySize is the length of an encryption key: AES-256
+const KeySize = 32
+
+// encryptedMagic starts every encrypted file, so plaintext files written
+// before encryption was enabled can still be read
+var encryptedMagic = []byte("mcpenc1:")
+
+// ErrEncrypted is returned when reading an encrypted file without a key
+var ErrEncrypted = errors.New("file is encrypted and no encryption key is configured")
+
+// Cipher encrypts local state at rest with AES-256-GCM. A nil Cipher stores
+// data in plaintext.
+type Cipher struct {
+	aead cipher.AEAD
+}
+
+// NewCipher creates a cipher from a 32-byte key
+func NewCipher(key []byte) (*Cipher, error) {
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("encryption key must be %d bytes, got %d", KeySize, len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	return &Cipher{aead: aead}, nil
+}
+
+// ParseKey decodes a key given as base64 or hex
+func ParseKey(encoded string) ([]byte, error) {
+	encoded = strings.TrimSpace(encoded)
+	if key, err := hex.DecodeString(encoded); err == nil && len(key) == KeySize {
+		return key, nil
+	}
+	if key, err := base64.StdEncoding.DecodeString(encoded); err == nil && len(key) == KeySize {
+		return key, nil
+	}
+	return nil, fmt.Errorf("encryption key must be %d bytes encoded as base64 or hex", KeySize)
+}
+
+// Seal encrypts data
+func (c *Cipher) Seal(data []byte) ([]byte, error) {
+	if c == nil {
+		return data, nil
+	}
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	sealed := append(append([]byte(nil), encryptedMagic...), nonce...)
+	return c.aead.Seal(sealed, nonce, data, encryptedMagic), nil
+}
+
+// Open decrypts data written by Seal. Plaintext data is returned unchanged, so
+// existing files are encrypted the next time they are written.
+func (c *Cipher) Open(data []byte) ([]byte, error) {
+	if !bytes.HasPrefix(data, encryptedMagic) {
+		return data, nil
+	}
+	if c == nil {
+		return nil, ErrEncrypted
+	}
+	data = data[len(encryptedMagic):]
+	if len(data) < c.aead.NonceSize() {
+		return nil, fmt.Errorf("encrypted data is truncated")
+	}
+	nonce, ciphertext := data[:c.aead.NonceSize()], data[c.aead.NonceSize():]
+	plain, err := c.aead.Open(nil, nonce, ciphertext, encryptedMagic)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt data, wrong key or corrupt file: %w", err)
+	}
+	return plain, nil
+}
@@ -0,0 +1,99 @@
+package store
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func testCipher(t *testing.T, fill byte) *Cipher {
+	t.Helper()
+	c, err := NewCipher(bytes.Repeat([]byte{fill}, KeySize))
+	if err != nil {
+		t.Fatalf("NewCipher returned an error: %v", err)
+	}
+	return c
+}
+
+func TestCipher(t *testing.T) {
+	c := testCipher(t, 1)
+	sealed, err := c.Seal([]byte(`{"query":"private"}`))
+	if err != nil {
+		t.Fatalf("Seal returned an error: %v", err)
+	}
+	if bytes.Contains(sealed, []byte("private")) {
+		t.Error("Expected the sealed data not to contain the plaintext")
+	}
+
+	plain, err := c.Open(sealed)
+	if err != nil || string(plain) != `{"query":"private"}` {
+		t.Errorf("Expected the plaintext back, got %q, %v", plain, err)
+	}
+	if _, err := testCipher(t, 2).Open(sealed); err == nil {
+		t.Error("Expected error opening with the wrong key, got nil")
+	}
+	var none *Cipher
+	if _, err := none.Open(sealed); !errors.Is(err, ErrEncrypted) {
+		t.Errorf("Expected ErrEncrypted without a key, got %v", err)
+	}
+
+	// Plaintext written before encryption was enabled is still readable
+	if plain, err := c.Open([]byte(`{"results":[]}`)); err != nil || string(plain) != `{"results":[]}` {
+		t.Errorf("Expected plaintext unchanged, got %q, %v", plain, err)
+	}
+}
+
+func TestParseKey(t *testing.T) {
+	hexKey := strings.Repeat("ab", KeySize)
+	if key, err := ParseKey(hexKey); err != nil || len(key) != KeySize {
+		t.Errorf("Expected hex key to parse, got %d bytes, %v", len(key), err)
+	}
+	if key, err := ParseKey(" MDEyMzQ1Njc4OTAxMjM0NTY3ODkwMTIzNDU2Nzg5MDE=\n"); err != nil || string(key) != "01234567890123456789012345678901" {
+		t.Errorf("Expected base64 key to parse, got %q, %v", key, err)
+	}
+	if _, err := ParseKey("too-short"); err == nil {
+		t.Error("Expected error for a short key, got nil")
+	}
+	if _, err := NewCipher([]byte("short")); err == nil {
+		t.Error("Expected error for a short raw key, got nil")
+	}
+}
+
+func TestSaved_Encrypted(t *testing.T) {
+	dir := t.TempDir()
+
+	// A plaintext file is encrypted on the next write
+	plain, err := OpenSaved(dir)
+	if err != nil {
+		t.Fatalf("OpenSaved returned an error: %v", err)
+	}
+	if _, err := plain.Save(Result{URL: "https://example.com/private-research"}); err != nil {
+		t.Fatalf("Save returned an error: %v", err)
+	}
+
+	saved, err := OpenSavedEncrypted(dir, testCipher(t, 1))
+	if err != nil {
+		t.Fatalf("OpenSavedEncrypted returned an error: %v", err)
+	}
+	if _, err := saved.Save(Result{URL: "https://go.dev"}); err != nil {
+		t.Fatalf("Save returned an error: %v", err)
+	}
+	raw, err := os.ReadFile(filepath.Join(dir, SavedFile))
+	if err != nil {
+		t.Fatalf("Failed to read saved file: %v", err)
+	}
+	if bytes.Contains(raw, []byte("private-research")) {
+		t.Error("Expected the saved results file to be encrypted")
+	}
+
+	results, err := saved.List("")
+	if err != nil || len(results) != 2 {
+		t.Errorf("Expected both results back, got %d, %v", len(results), err)
+	}
+	if _, err := OpenSaved(dir); !errors.Is(err, ErrEncrypted) {
+		t.Errorf("Expected ErrEncrypted opening without a key, got %v", err)
+	}
+}
@@ -0,0 +1,411 @@
+// Package store persists the search results users save, so findings can be
+// curated across sessions, and the API calls counted against daily quotas
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SavedFile is the name of the saved results file inside the data directory
+const SavedFile = "saved.json"
+
+// Result is a saved search result
+type Result struct {
+	ID      string    `json:"id"`
+	URL     string    `json:"url"`
+	Title   string    `json:"title,omitempty"`
+	Snippet string    `json:"snippet,omitempty"`
+	Query   string    `json:"query,omitempty"`
+	Tags    []string  `json:"tags,omitempty"`
+	Notes   []Note    `json:"notes,omitempty"`
+	SavedAt time.Time `json:"saved_at"`
+}
+
+// Note is a user annotation on a saved result
+type Note struct {
+	Text    string    `json:"text"`
+	AddedAt time.Time `json:"added_at"`
+}
+
+// Annotation changes the notes and tags of a saved result
+type Annotation struct {
+	Note       string
+	AddTags    []string
+	RemoveTags []string
+}
+
+// savedData is the on-disk layout of the saved results file
+type savedData struct {
+	NextID  int      `json:"next_id"`
+	Results []Result `json:"results"`
+}
+
+// Saved is a JSON file of saved results. The file is re-read before every
+// operation so several server processes can share it.
+type Saved struct {
+	path   string
+	mu     sync.Mutex
+	now    func() time.Time
+	cipher *Cipher
+
+	maxAge     time.Duration
+	maxEntries int
+}
+
+// OpenSaved opens the saved results file in dir, creating the directory if needed
+func OpenSaved(dir string) (*Saved, error) {
+	return OpenSavedEncrypted(dir, nil)
+}
+
+// OpenSavedEncrypted opens the saved results file in dir, encrypting it with c.
+// A plaintext file is encrypted the next time it is written.
+func OpenSavedEncrypted(dir string, c *Cipher) (*Saved, error) {
+	if dir == "" {
+		return nil, fmt.Errorf("data directory is not set")
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create data directory: %w", err)
+	}
+	s := &Saved{
+		path:   filepath.Join(dir, SavedFile),
+		now:    time.Now,
+		cipher: c,
+	}
+	// Fail early on a corrupt file rather than on the first save
+	if _, err := s.load(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Save stores a result. Saving a URL again updates its entry: tags are merged,
+// notes are appended and other non-empty fields replace the old ones.
+func (s *Saved) Save(result Result) (Result, error) {
+	if strings.TrimSpace(result.URL) == "" {
+		return Result{}, fmt.Errorf("url is required")
+	}
+	result.Tags = NormalizeTags(result.Tags)
+	notes := result.Notes
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, err := s.loadCurrent()
+	if err != nil {
+		return Result{}, err
+	}
+
+	now := s.now().UTC()
+	for i, existing := range data.Results {
+		if existing.URL != result.URL {
+			continue
+		}
+		merged := mergeResult(existing, result, now)
+		data.Results[i] = merged
+		return merged, s.write(data)
+	}
+
+	data.NextID++
+	result.ID = strconv.Itoa(data.NextID)
+	result.SavedAt = now
+	result.Notes = nil
+	for _, note := range notes {
+		result.addNote(note.Text, now)
+	}
+	data.Results = append(data.Results, result)
+	s.expire(data)
+	return result, s.write(data)
+}
+
+// List returns saved results, newest first. When tag is set only results
+// carrying it are returned.
+func (s *Saved) List(tag string) ([]Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, err := s.loadCurrent()
+	if err != nil {
+		return nil, err
+	}
+
+	tag = normalizeTag(tag)
+	var results []Result
+	for _, result := range data.Results {
+		if tag == "" || hasTag(result, tag) {
+			results = append(results, result)
+		}
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].SavedAt.After(results[j].SavedAt)
+	})
+	return results, nil
+}
+
+// Annotate adds a note and changes the tags of the saved result with the given ID
+func (s *Saved) Annotate(id string, annotation Annotation) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, err := s.loadCurrent()
+	if err != nil {
+		return Result{}, err
+	}
+
+	for i := range data.Results {
+		result := &data.Results[i]
+		if result.ID != id {
+			continue
+		}
+		result.addNote(annotation.Note, s.now().UTC())
+		result.Tags = NormalizeTags(append(result.Tags, annotation.AddTags...))
+		for _, tag := range annotation.RemoveTags {
+			result.removeTag(normalizeTag(tag))
+		}
+		return *result, s.write(data)
+	}
+	return Result{}, fmt.Errorf("no saved result with id %q", id)
+}
+
+// Search returns saved results containing every term of query in their title,
+// URL, snippet, query, tags or notes, best matches first. When tag is set only
+// results carrying it are searched.
+func (s *Saved) Search(query, tag string) ([]Result, error) {
+	results, err := s.List(tag)
+	if err != nil {
+		return nil, err
+	}
+	terms := strings.Fields(strings.ToLower(query))
+	if len(terms) == 0 {
+		return results, nil
+	}
+
+	type scored struct {
+		result Result
+		score  int
+	}
+	var matches []scored
+	for _, result := range results {
+		if score := result.matchScore(terms); score > 0 {
+			matches = append(matches, scored{result, score})
+		}
+	}
+	// Results stay newest first within the same score
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].score > matches[j].score
+	})
+
+	found := make([]Result, len(matches))
+	for i, match := range matches {
+		found[i] = match.result
+	}
+	return found, nil
+}
+
+// SetRetention deletes results saved longer than maxAge ago and keeps at most
+// maxEntries, the newest. Zero means no limit. Results past the limits are
+// deleted right away and whenever the file is next used.
+func (s *Saved) SetRetention(maxAge time.Duration, maxEntries int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.maxAge = maxAge
+	s.maxEntries = maxEntries
+	_, err := s.loadCurrent()
+	return err
+}
+
+// Purge deletes every saved result and returns how many were deleted
+func (s *Saved) Purge() (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, err := s.load()
+	if err != nil {
+		return 0, err
+	}
+	if err := os.Remove(s.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return 0, fmt.Errorf("failed to delete saved results: %w", err)
+	}
+	return len(data.Results), nil
+}
+
+// loadCurrent reads the saved results file and deletes results past the
+// retention limits. The caller must hold s.mu.
+func (s *Saved) loadCurrent() (*savedData, error) {
+	data, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	if s.expire(data) > 0 {
+		if err := s.write(data); err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
+
+// expire removes results past the retention limits from data and returns how
+// many were removed
+func (s *Saved) expire(data *savedData) int {
+	before := len(data.Results)
+	if s.maxAge > 0 {
+		cutoff := s.now().Add(-s.maxAge)
+		kept := data.Results[:0]
+		for _, result := range data.Results {
+			if !result.SavedAt.Before(cutoff) {
+				kept = append(kept, result)
+			}
+		}
+		data.Results = kept
+	}
+	if s.maxEntries > 0 && len(data.Results) > s.maxEntries {
+		// Results are stored in the order they were first saved
+		sort.SliceStable(data.Results, func(i, j int) bool {
+			return data.Results[i].SavedAt.Before(data.Results[j].SavedAt)
+		})
+		data.Results = data.Results[len(data.Results)-s.maxEntries:]
+	}
+	return before - len(data.Results)
+}
+
+// load reads the saved results file; a missing file is an empty store.
+// The caller must hold s.mu.
+func (s *Saved) load() (*savedData, error) {
+	data := &savedData{}
+	raw, err := os.ReadFile(s.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return data, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read saved results: %w", err)
+	}
+	if raw, err = s.cipher.Open(raw); err != nil {
+		return nil, fmt.Errorf("failed to read saved results %s: %w", s.path, err)
+	}
+	if err := json.Unmarshal(raw, data); err != nil {
+		return nil, fmt.Errorf("failed to parse saved results %s: %w", s.path, err)
+	}
+	return data, nil
+}
+
+// write replaces the saved results file atomically. The caller must hold s.mu.
+func (s *Saved) write(data *savedData) error {
+	raw, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode saved results: %w", err)
+	}
+	if raw, err = s.cipher.Seal(raw); err != nil {
+		return fmt.Errorf("failed to encrypt saved results: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), SavedFile+".*")
+	if err != nil {
+		return fmt.Errorf("failed to write saved results: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(raw); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write saved results: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write saved results: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("failed to write saved results: %w", err)
+	}
+	return nil
+}
+
+// matchScore returns how often the terms occur in the result, or 0 unless
+// every term occurs. Title and tag matches count double.
+func (r Result) matchScore(terms []string) int {
+	title := strings.ToLower(r.Title)
+	tags := strings.Join(r.Tags, " ")
+	var notes []string
+	for _, note := range r.Notes {
+		notes = append(notes, note.Text)
+	}
+	text := strings.ToLower(strings.Join([]string{r.URL, r.Snippet, r.Query, strings.Join(notes, " ")}, " "))
+
+	score := 0
+	for _, term := range terms {
+		n := 2*strings.Count(title, term) + 2*strings.Count(tags, term) + strings.Count(text, term)
+		if n == 0 {
+			return 0
+		}
+		score += n
+	}
+	return score
+}
+
+// addNote appends a non-empty note unless it repeats the latest one
+func (r *Result) addNote(text string, at time.Time) {
+	text = strings.TrimSpace(text)
+	if text == "" || (len(r.Notes) > 0 && r.Notes[len(r.Notes)-1].Text == text) {
+		return
+	}
+	r.Notes = append(r.Notes, Note{Text: text, AddedAt: at})
+}
+
+// removeTag drops tag from the result
+func (r *Result) removeTag(tag string) {
+	kept := r.Tags[:0]
+	for _, t := range r.Tags {
+		if t != tag {
+			kept = append(kept, t)
+		}
+	}
+	r.Tags = kept
+}
+
+// mergeResult updates an existing entry with the fields of a new save
+func mergeResult(existing, update Result, now time.Time) Result {
+	if update.Title != "" {
+		existing.Title = update.Title
+	}
+	if update.Snippet != "" {
+		existing.Snippet = update.Snippet
+	}
+	if update.Query != "" {
+		existing.Query = update.Query
+	}
+	existing.Tags = NormalizeTags(append(existing.Tags, update.Tags...))
+	for _, note := range update.Notes {
+		existing.addNote(note.Text, now)
+	}
+	return existing
+}
+
+// NormalizeTags lower-cases and trims tags, dropping empty and duplicate ones
+func NormalizeTags(tags []string) []string {
+	var normalized []string
+	seen := make(map[string]bool)
+	for _, tag := range tags {
+		tag = normalizeTag(tag)
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	return normalized
+}
+
+// normalizeTag returns the canonical form of a tag
+func normalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimSpace(tag))
+}
+
+// hasTag reports whether the result carries tag
+func hasTag(result Result, tag string) bool {
+	for _, t := range result.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,183 @@
+package store
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSaved(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "data")
+	saved, err := OpenSaved(dir)
+	if err != nil {
+		t.Fatalf("OpenSaved returned an error: %v", err)
+	}
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	saved.now = func() time.Time { now = now.Add(time.Minute); return now }
+
+	first, err := saved.Save(Result{URL: "https://go.dev/doc", Title: "Docs", Tags: []string{"Go", " reference "}})
+	if err != nil {
+		t.Fatalf("Save returned an error: %v", err)
+	}
+	if first.ID != "1" || len(first.Tags) != 2 || first.Tags[0] != "go" {
+		t.Errorf("Unexpected saved result: %+v", first)
+	}
+	if _, err := saved.Save(Result{URL: "https://example.com", Tags: []string{"misc"}}); err != nil {
+		t.Fatalf("Save returned an error: %v", err)
+	}
+
+	// Saving a URL again updates the existing entry
+	updated, err := saved.Save(Result{URL: "https://go.dev/doc", Notes: []Note{{Text: "start here"}}, Tags: []string{"go", "tutorial"}})
+	if err != nil {
+		t.Fatalf("Save returned an error: %v", err)
+	}
+	if updated.ID != "1" || updated.Title != "Docs" || len(updated.Notes) != 1 || updated.Notes[0].Text != "start here" || len(updated.Tags) != 3 {
+		t.Errorf("Expected merged entry, got %+v", updated)
+	}
+
+	// Results persist across instances
+	reopened, err := OpenSaved(dir)
+	if err != nil {
+		t.Fatalf("OpenSaved returned an error: %v", err)
+	}
+	all, _ := reopened.List("")
+	if len(all) != 2 || all[0].URL != "https://example.com" {
+		t.Errorf("Expected 2 results newest first, got %+v", all)
+	}
+	tagged, _ := reopened.List("TUTORIAL")
+	if len(tagged) != 1 || tagged[0].ID != "1" {
+		t.Errorf("Expected 1 result tagged tutorial, got %+v", tagged)
+	}
+
+	if _, err := saved.Save(Result{}); err == nil {
+		t.Error("Expected error for a result without a URL, got nil")
+	}
+}
+
+func TestSaved_AnnotateAndSearch(t *testing.T) {
+	saved, err := OpenSaved(t.TempDir())
+	if err != nil {
+		t.Fatalf("OpenSaved returned an error: %v", err)
+	}
+	_, _ = saved.Save(Result{URL: "https://go.dev/doc/tutorial/generics", Title: "Tutorial: Getting started with generics", Tags: []string{"go"}})
+	_, _ = saved.Save(Result{URL: "https://example.com/rust", Title: "Rust traits", Snippet: "Traits are like interfaces"})
+	_, _ = saved.Save(Result{URL: "https://example.com/go-interfaces", Title: "Interfaces in Go", Tags: []string{"go"}})
+
+	annotated, err := saved.Annotate("2", Annotation{Note: "Compare with Go generics", AddTags: []string{"Compare", "go"}})
+	if err != nil {
+		t.Fatalf("Annotate returned an error: %v", err)
+	}
+	annotated, _ = saved.Annotate("2", Annotation{Note: "Compare with Go generics", RemoveTags: []string{"go"}})
+	if len(annotated.Notes) != 1 || len(annotated.Tags) != 1 || annotated.Tags[0] != "compare" {
+		t.Errorf("Expected one note and the compare tag, got %+v", annotated)
+	}
+	if _, err := saved.Annotate("99", Annotation{Note: "missing"}); err == nil {
+		t.Error("Expected error for an unknown id, got nil")
+	}
+
+	testCases := []struct {
+		query    string
+		tag      string
+		expected []string
+	}{
+		{"generics", "", []string{"1", "2"}},
+		{"GO generics", "", []string{"1", "2"}},
+		{"interfaces", "", []string{"3", "2"}},
+		{"generics", "compare", []string{"2"}},
+		{"haskell", "", nil},
+		{"", "go", []string{"3", "1"}},
+	}
+	for _, tc := range testCases {
+		results, err := saved.Search(tc.query, tc.tag)
+		if err != nil {
+			t.Fatalf("Search returned an error: %v", err)
+		}
+		var ids []string
+		for _, result := range results {
+			ids = append(ids, result.ID)
+		}
+		if strings.Join(ids, ",") != strings.Join(tc.expected, ",") {
+			t.Errorf("Search(%q, %q): expected %v, got %v", tc.query, tc.tag, tc.expected, ids)
+		}
+	}
+}
+
+func TestOpenSaved_Corrupt(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, SavedFile), []byte("{"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := OpenSaved(dir); err == nil {
+		t.Error("Expected error for a corrupt file, got nil")
+	}
+	if _, err := OpenSaved(""); err == nil {
+		t.Error("Expected error for an empty directory, got nil")
+	}
+}
+
+func TestSaved_Retention(t *testing.T) {
+	saved, err := OpenSaved(t.TempDir())
+	if err != nil {
+		t.Fatalf("OpenSaved returned an error: %v", err)
+	}
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	saved.now = func() time.Time { return now }
+
+	for _, url := range []string{"https://a.example", "https://b.example", "https://c.example"} {
+		if _, err := saved.Save(Result{URL: url}); err != nil {
+			t.Fatalf("Save returned an error: %v", err)
+		}
+		now = now.Add(time.Hour)
+	}
+
+	// Only the newest two results are kept
+	if err := saved.SetRetention(0, 2); err != nil {
+		t.Fatalf("SetRetention returned an error: %v", err)
+	}
+	results, err := saved.List("")
+	if err != nil {
+		t.Fatalf("List returned an error: %v", err)
+	}
+	if len(results) != 2 || results[1].URL != "https://b.example" {
+		t.Errorf("Expected the two newest results, got %+v", results)
+	}
+
+	// Results older than the max age are deleted as time passes
+	if err := saved.SetRetention(90*time.Minute, 0); err != nil {
+		t.Fatalf("SetRetention returned an error: %v", err)
+	}
+	results, err = saved.List("")
+	if err != nil {
+		t.Fatalf("List returned an error: %v", err)
+	}
+	if len(results) != 1 || results[0].URL != "https://c.example" {
+		t.Errorf("Expected only the recent result, got %+v", results)
+	}
+}
+
+func TestSaved_Purge(t *testing.T) {
+	dir := t.TempDir()
+	saved, err := OpenSaved(dir)
+	if err != nil {
+		t.Fatalf("OpenSaved returned an error: %v", err)
+	}
+	if _, err := saved.Save(Result{URL: "https://go.dev"}); err != nil {
+		t.Fatalf("Save returned an error: %v", err)
+	}
+
+	n, err := saved.Purge()
+	if err != nil {
+		t.Fatalf("Purge returned an error: %v", err)
+	}
+	if n != 1 {
+		t.Errorf("Expected 1 result purged, got %d", n)
+	}
+	if _, err := os.Stat(filepath.Join(dir, SavedFile)); !os.IsNotExist(err) {
+		t.Errorf("Expected the saved results file to be deleted, got %v", err)
+	}
+	if n, err := saved.Purge(); err != nil || n != 0 {
+		t.Errorf("Expected purging an empty store to succeed, got %d, %v", n, err)
+	}
+}
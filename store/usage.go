@@ -0,0 +1,210 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// UsageFile is the name of the API usage file inside the data directory
+const UsageFile = "usage.json"
+
+// usageDays is how many days of per-day counts are kept
+const usageDays = 31
+
+// usageDayFormat names the days counts are kept for
+const usageDayFormat = "2006-01-02"
+
+// UsageCounts are the calls made to a provider's API and how many failed
+type UsageCounts struct {
+	Calls  uint64 `json:"calls"`
+	Errors uint64 `json:"errors"`
+}
+
+// ProviderUsage is a provider's usage today and since counting began
+type ProviderUsage struct {
+	Provider string      `json:"provider"`
+	Today    UsageCounts `json:"today"`
+	Total    UsageCounts `json:"total"`
+}
+
+// UsageSummary is the API usage of every provider called so far
+type UsageSummary struct {
+	// Day is today's date, in the zone days are counted in
+	Day string `json:"day"`
+	// Since is when counting began
+	Since     time.Time       `json:"since"`
+	Providers []ProviderUsage `json:"providers"`
+}
+
+// usageData is the on-disk layout of the usage file
+type usageData struct {
+	Since  time.Time                         `json:"since"`
+	Totals map[string]UsageCounts            `json:"totals"`
+	Days   map[string]map[string]UsageCounts `json:"days"`
+}
+
+// Usage is a JSON file counting the calls made to each provider's API, per
+// day and in total, so daily quotas can be tracked across restarts. The file
+// is re-read before every operation so server processes sharing an API key
+// can share it.
+type Usage struct {
+	path   string
+	mu     sync.Mutex
+	now    func() time.Time
+	loc    *time.Location
+	cipher *Cipher
+}
+
+// OpenUsageEncrypted opens the usage file in dir, encrypting it with c. Days
+// start at midnight in loc, or in UTC when loc is nil.
+func OpenUsageEncrypted(dir string, c *Cipher, loc *time.Location) (*Usage, error) {
+	if dir == "" {
+		return nil, fmt.Errorf("data directory is not set")
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create data directory: %w", err)
+	}
+	if loc == nil {
+		loc = time.UTC
+	}
+	u := &Usage{
+		path:   filepath.Join(dir, UsageFile),
+		now:    time.Now,
+		loc:    loc,
+		cipher: c,
+	}
+	// Fail early on a corrupt file rather than on the first call
+	if _, err := u.load(); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+// Record counts one call to the provider's API
+func (u *Usage) Record(provider string, failed bool) error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	data, err := u.load()
+	if err != nil {
+		return err
+	}
+	day := u.today()
+	if data.Days[day] == nil {
+		data.Days[day] = make(map[string]UsageCounts)
+	}
+	for _, counts := range []map[string]UsageCounts{data.Totals, data.Days[day]} {
+		c := counts[provider]
+		c.Calls++
+		if failed {
+			c.Errors++
+		}
+		counts[provider] = c
+	}
+
+	// Days older than the history kept are dropped
+	if len(data.Days) > usageDays {
+		days := make([]string, 0, len(data.Days))
+		for d := range data.Days {
+			days = append(days, d)
+		}
+		sort.Strings(days)
+		for _, d := range days[:len(days)-usageDays] {
+			delete(data.Days, d)
+		}
+	}
+	return u.write(data)
+}
+
+// Summary returns the usage of every provider called so far, by name
+func (u *Usage) Summary() (UsageSummary, error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	data, err := u.load()
+	if err != nil {
+		return UsageSummary{}, err
+	}
+	day := u.today()
+	summary := UsageSummary{Day: day, Since: data.Since, Providers: []ProviderUsage{}}
+	for provider, total := range data.Totals {
+		summary.Providers = append(summary.Providers, ProviderUsage{
+			Provider: provider,
+			Today:    data.Days[day][provider],
+			Total:    total,
+		})
+	}
+	sort.Slice(summary.Providers, func(i, j int) bool {
+		return summary.Providers[i].Provider < summary.Providers[j].Provider
+	})
+	return summary, nil
+}
+
+// ResetsAt returns when the current day ends and daily counts start over
+func (u *Usage) ResetsAt() time.Time {
+	now := u.now().In(u.loc)
+	return time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, u.loc)
+}
+
+// today returns the current day in the zone days are counted in
+func (u *Usage) today() string {
+	return u.now().In(u.loc).Format(usageDayFormat)
+}
+
+// load reads the usage file; a missing file starts counting now.
+// The caller must hold u.mu.
+func (u *Usage) load() (*usageData, error) {
+	data := &usageData{Since: u.now()}
+	raw, err := os.ReadFile(u.path)
+	if err == nil {
+		if raw, err = u.cipher.Open(raw); err != nil {
+			return nil, fmt.Errorf("failed to read usage %s: %w", u.path, err)
+		}
+		if err := json.Unmarshal(raw, data); err != nil {
+			return nil, fmt.Errorf("failed to parse usage %s: %w", u.path, err)
+		}
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read usage: %w", err)
+	}
+	if data.Totals == nil {
+		data.Totals = make(map[string]UsageCounts)
+	}
+	if data.Days == nil {
+		data.Days = make(map[string]map[string]UsageCounts)
+	}
+	return data, nil
+}
+
+// write replaces the usage file atomically. The caller must hold u.mu.
+func (u *Usage) write(data *usageData) error {
+	raw, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode usage: %w", err)
+	}
+	if raw, err = u.cipher.Seal(raw); err != nil {
+		return fmt.Errorf("failed to encrypt usage: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(u.path), UsageFile+".*")
+	if err != nil {
+		return fmt.Errorf("failed to write usage: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(raw); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write usage: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write usage: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), u.path); err != nil {
+		return fmt.Errorf("failed to write usage: %w", err)
+	}
+	return nil
+}
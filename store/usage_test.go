@@ -0,0 +1,110 @@
+package store
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestUsage(t *testing.T) {
+	dir := t.TempDir()
+	shanghai := time.FixedZone("CST", 8*3600)
+	usage, err := OpenUsageEncrypted(dir, nil, shanghai)
+	if err != nil {
+		t.Fatalf("OpenUsageEncrypted returned an error: %v", err)
+	}
+	// 23:30 in Shanghai, on the 1st
+	now := time.Date(2025, 3, 1, 15, 30, 0, 0, time.UTC)
+	usage.now = func() time.Time { return now }
+
+	for _, call := range []struct {
+		provider string
+		failed   bool
+	}{{"bocha", false}, {"bocha", true}, {"brave", false}} {
+		if err := usage.Record(call.provider, call.failed); err != nil {
+			t.Fatalf("Record returned an error: %v", err)
+		}
+	}
+	if resets := usage.ResetsAt(); !resets.Equal(time.Date(2025, 3, 2, 0, 0, 0, 0, shanghai)) {
+		t.Errorf("Expected the day to end at midnight in Shanghai, got %v", resets)
+	}
+
+	// Counts persist across instances, and today's start over at midnight
+	reopened, err := OpenUsageEncrypted(dir, nil, shanghai)
+	if err != nil {
+		t.Fatalf("OpenUsageEncrypted returned an error: %v", err)
+	}
+	now = now.Add(time.Hour)
+	reopened.now = func() time.Time { return now }
+	if err := reopened.Record("bocha", false); err != nil {
+		t.Fatalf("Record returned an error: %v", err)
+	}
+	summary, err := reopened.Summary()
+	if err != nil {
+		t.Fatalf("Summary returned an error: %v", err)
+	}
+	if summary.Day != "2025-03-02" || len(summary.Providers) != 2 {
+		t.Fatalf("Unexpected summary: %+v", summary)
+	}
+	bocha, brave := summary.Providers[0], summary.Providers[1]
+	if bocha.Provider != "bocha" || bocha.Today != (UsageCounts{Calls: 1}) || bocha.Total != (UsageCounts{Calls: 3, Errors: 1}) {
+		t.Errorf("Unexpected bocha usage: %+v", bocha)
+	}
+	if brave.Today != (UsageCounts{}) || brave.Total != (UsageCounts{Calls: 1}) {
+		t.Errorf("Unexpected brave usage: %+v", brave)
+	}
+}
+
+func TestUsage_History(t *testing.T) {
+	usage, err := OpenUsageEncrypted(t.TempDir(), nil, nil)
+	if err != nil {
+		t.Fatalf("OpenUsageEncrypted returned an error: %v", err)
+	}
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	usage.now = func() time.Time { return now }
+	for i := 0; i < usageDays+5; i++ {
+		if err := usage.Record("bocha", false); err != nil {
+			t.Fatalf("Record returned an error: %v", err)
+		}
+		now = now.AddDate(0, 0, 1)
+	}
+
+	usage.mu.Lock()
+	data, err := usage.load()
+	usage.mu.Unlock()
+	if err != nil {
+		t.Fatalf("load returned an error: %v", err)
+	}
+	if len(data.Days) != usageDays || data.Totals["bocha"].Calls != usageDays+5 {
+		t.Errorf("Expected %d days kept and every call in the total, got %d days and %+v", usageDays, len(data.Days), data.Totals)
+	}
+	if _, ok := data.Days["2025-01-01"]; ok {
+		t.Error("Expected the oldest days dropped")
+	}
+}
+
+func TestUsage_Encrypted(t *testing.T) {
+	dir := t.TempDir()
+	cipher, err := NewCipher(make([]byte, 32))
+	if err != nil {
+		t.Fatalf("NewCipher returned an error: %v", err)
+	}
+	usage, err := OpenUsageEncrypted(dir, cipher, nil)
+	if err != nil {
+		t.Fatalf("OpenUsageEncrypted returned an error: %v", err)
+	}
+	if err := usage.Record("bocha", false); err != nil {
+		t.Fatalf("Record returned an error: %v", err)
+	}
+	if _, err := OpenUsageEncrypted(dir, nil, nil); err == nil {
+		t.Error("Expected an error opening the encrypted file without a key")
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, UsageFile), []byte("{"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := OpenUsageEncrypted(dir, cipher, nil); err == nil {
+		t.Error("Expected an error opening a corrupt file")
+	}
+}
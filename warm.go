@@ -0,0 +1,35 @@
+package main
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"com.moguyn/mcp-go-search/search"
+)
+
+// warmConnections connects to the providers' APIs in the background, now and
+// every interval until ctx is canceled, so searches don't wait for DNS lookups
+// and TLS handshakes. A failure only costs the next search the handshake, so
+// it is logged at debug level.
+func warmConnections(ctx context.Context, warmers map[string]search.Warmer, interval time.Duration, logger *Logger) {
+	if len(warmers) == 0 {
+		return
+	}
+	names := make([]string, 0, len(warmers))
+	for name := range warmers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	logger.Info("Warming provider connections", map[string]interface{}{
+		"providers": strings.Join(names, ","),
+		"interval":  interval.String(),
+	})
+	go search.KeepWarm(ctx, warmers, interval, func(name string, err error) {
+		logger.Debug("Failed to warm provider connection", map[string]interface{}{
+			"provider": name,
+			"error":    err.Error(),
+		})
+	})
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"com.moguyn/mcp-go-search/search"
+)
+
+// countingWarmer reports each warming on a channel and fails when err is set
+type countingWarmer struct {
+	warmed chan struct{}
+	err    error
+}
+
+// Warm records the call
+func (w *countingWarmer) Warm(_ context.Context) error {
+	w.warmed <- struct{}{}
+	return w.err
+}
+
+func TestWarmConnections(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Warming starts at once and repeats every interval, failures included
+	warmer := &countingWarmer{warmed: make(chan struct{}), err: errors.New("connection refused")}
+	warmConnections(ctx, map[string]search.Warmer{"brave": warmer}, 10*time.Millisecond, NewLogger("test"))
+	for i := 0; i < 2; i++ {
+		select {
+		case <-warmer.warmed:
+		case <-time.After(time.Second):
+			t.Fatalf("Expected warming %d, got none", i+1)
+		}
+	}
+
+	cancel()
+	select {
+	case <-warmer.warmed:
+		// A tick that raced the cancellation may still warm once
+	case <-time.After(50 * time.Millisecond):
+	}
+	select {
+	case <-warmer.warmed:
+		t.Error("Expected warming to stop once the context is canceled")
+	case <-time.After(50 * time.Millisecond):
+	}
+}